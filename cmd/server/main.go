@@ -5,21 +5,28 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
+	"calculator-server/internal/calculator"
 	"calculator-server/internal/config"
+	"calculator-server/internal/currency"
+	"calculator-server/internal/fetch"
 	"calculator-server/internal/handlers"
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
 	"calculator-server/pkg/mcp"
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 )
 
 func main() {
 	// Parse command line flags
-	transport := flag.String("transport", "", "Transport method (stdio, http)")
+	transport := flag.String("transport", "", "Transport method (stdio, http, or both as stdio,http)")
 	port := flag.Int("port", 0, "Port for HTTP transport")
 	host := flag.String("host", "", "Host for HTTP transport")
 	configPath := flag.String("config", "", "Path to configuration file")
@@ -50,105 +57,536 @@ func main() {
 
 	// Create MCP server
 	server := mcp.NewServer()
+	server.SetSlowCallThreshold(cfg.Logging.SlowCallThreshold)
+	server.SetSessionCallLimit(cfg.Security.SessionConcurrency.MaxCalls, cfg.Security.SessionConcurrency.QueueTimeout)
+
+	// Open the optional persistence layer for sessions and history
+	store, err := openStore(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	if store != nil {
+		defer store.Close()
+		server.SetStore(store, cfg.Storage.TTL)
+		stopGC := storage.StartGarbageCollector(store, cfg.Storage.GCInterval)
+		defer stopGC()
+	}
+
+	// Load the unit and constant tables before any calculator reads them
+	if cfg.Tools.Conversions.DataFile != "" {
+		registry, err := calculator.LoadConversionRegistry(cfg.Tools.Conversions.DataFile)
+		if err != nil {
+			log.Fatalf("Failed to load conversion data: %v", err)
+		}
+		calculator.SetDefaultConversionRegistry(registry)
+	}
 
 	// Create handlers
 	mathHandler := handlers.NewMathHandler()
 	statsHandler := handlers.NewStatsHandler()
+	statsHandler.SetParallelism(cfg.Tools.Statistics.Workers, cfg.Tools.Statistics.ParallelThreshold)
+	statsHandler.SetMaxDataPoints(cfg.Tools.Statistics.MaxDataPoints)
 	financeHandler := handlers.NewFinanceHandler()
+	var rates *currency.RateTable
+	if cfg.Currency.RatesURL != "" {
+		fetcher := fetch.New(fetch.Config{
+			AllowedHosts:      cfg.Fetcher.AllowedHosts,
+			Timeout:           cfg.Fetcher.Timeout,
+			CacheTTL:          cfg.Fetcher.CacheTTL,
+			RequestsPerMinute: cfg.Fetcher.RequestsPerMinute,
+		})
+		server.Metrics().AddSource("fetcher", func() interface{} { return fetcher.Stats() })
+		provider := currency.NewHTTPRateProvider(fetcher, cfg.Currency.RatesURL)
+		financeHandler.SetRateProvider(provider)
+		base, _ := currency.Normalize(cfg.Currency.RatesBase) // Checked by Validate
+		rates = currency.NewRateTable(provider, base)
+	}
+
+	// Session history can only be exported when it is being recorded
+	var history handlers.HistorySource
+	if store != nil {
+		history = func(ctx context.Context) ([]types.HistoryEntry, error) {
+			return server.History(mcp.SessionIDFromContext(ctx))
+		}
+	}
+	exportHandler := handlers.NewExportHandler(history)
+	formulaHandler := handlers.NewFormulaHandler(server)
+	resourceHandler := handlers.NewResourceHandler(history)
+	if rates != nil {
+		resourceHandler.SetRateTable(rates)
+	}
+	promptHandler := handlers.NewPromptHandler()
 
 	// Register tools
 	registerTools(server, mathHandler, statsHandler, financeHandler)
+	registerExportTool(server, exportHandler)
+	registerFormulaTools(server, formulaHandler)
+	registerDiagnosticsTool(server)
+	registerResources(server, resourceHandler, history != nil, rates != nil)
+	registerPrompts(server, promptHandler)
+
+	// Apply tool group feature flags
+	for group, enabled := range cfg.Tools.Groups {
+		server.SetToolGroupEnabled(group, enabled)
+	}
+	server.SetToolOrdering(mcp.ToolOrdering(cfg.Tools.Ordering))
+	server.SetStrictness(mcp.Strictness(cfg.Tools.Strictness))
+	server.SetDecodeLimits(mcp.DecodeLimits{
+		MaxDepth:       cfg.Security.MaxJSONDepth,
+		MaxArrayLength: cfg.Security.MaxArrayLength,
+	})
+	for _, tool := range cfg.Tools.Elicitation {
+		server.SetElicitation(tool, true)
+	}
+	for _, tool := range cfg.Tools.Explanations {
+		server.SetResultExplanation(tool, true)
+	}
+	server.PrewarmToolList()
+	if rates != nil {
+		go refreshRates(server, rates, cfg.Currency.RefreshInterval)
+	}
 
-	// Start server based on transport
-	switch cfg.Server.Transport {
-	case "stdio":
-		log.Println("Starting calculator server with stdio transport...")
-		if err := server.Run(); err != nil {
-			log.Fatalf("Server error: %v", err)
+	// Serve every configured transport against the one server until a
+	// shutdown signal arrives or one of them ends. The built-in transports
+	// are registered again with the configured settings.
+	mcp.RegisterTransport("stdio", func(server *mcp.Server) (mcp.Transport, error) {
+		return mcp.NewStdioTransportWithConfig(server, os.Stdin, os.Stdout, &mcp.StdioConfig{
+			Framing:        mcp.StdioFraming(cfg.Server.Stdio.Framing),
+			MaxMessageSize: cfg.Server.Stdio.MaxMessageSize,
+			MaxCalls:       cfg.Server.Stdio.MaxCalls,
+		}), nil
+	})
+	mcp.RegisterTransport("http", func(server *mcp.Server) (mcp.Transport, error) {
+		return newHTTPTransport(server, cfg, store), nil
+	})
+	transports := mcp.NewTransportManager()
+	for _, name := range cfg.Server.Transports() {
+		transport, err := mcp.NewTransport(name, server)
+		if err != nil {
+			log.Fatalf("Failed to create the %s transport: %v", name, err)
 		}
-	case "http":
-		startHTTPServerWithConfig(server, cfg)
+		transports.Add(name, transport)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := transports.Run(ctx, cfg.Server.ShutdownTimeout); err != nil {
+		log.Printf("Server error: %v", err)
+	} else {
+		log.Println("Server shut down gracefully")
+	}
+}
+
+// openStore opens the configured storage backend, or returns nil when persistence is disabled
+func openStore(cfg *config.Config) (storage.Store, error) {
+	if !cfg.Storage.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Storage.Backend {
+	case "memory":
+		return storage.NewMemoryStore(), nil
+	case "file":
+		log.Printf("Persisting sessions and history to %s", cfg.Storage.Path)
+		return storage.OpenFileStore(cfg.Storage.Path)
+	case "redis":
+		log.Printf("Sharing sessions and history through redis")
+		return storage.OpenRedisStore(cfg.Storage.Redis.URL, cfg.Storage.Redis.KeyPrefix)
 	default:
-		log.Fatalf("Unknown transport: %s", cfg.Server.Transport)
+		return nil, config.ErrInvalidStorageBackend
+	}
+}
+
+// credentials converts configured API keys or bearer tokens for the transport
+func credentials(configured []config.CredentialConfig) []mcp.Credential {
+	converted := make([]mcp.Credential, 0, len(configured))
+	for _, credential := range configured {
+		converted = append(converted, mcp.Credential{
+			Name:         credential.Name,
+			Secret:       credential.Key,
+			AllowedTools: credential.AllowedTools,
+			Quota:        mcp.Quota{Daily: credential.DailyQuota, Monthly: credential.MonthlyQuota},
+		})
 	}
+	return converted
 }
 
-func startHTTPServerWithConfig(server *mcp.Server, cfg *config.Config) {
+// newHTTPTransport creates the streamable HTTP transport from the
+// server.http configuration
+func newHTTPTransport(server *mcp.Server, cfg *config.Config, store storage.Store) *mcp.StreamableHTTPTransport {
+	// Validated when the configuration was loaded
+	maxRequestBytes, _ := config.ParseByteSize(cfg.Security.RequestSizeLimit)
+
 	// Configure MCP-compliant streamable HTTP transport from config
 	httpConfig := &mcp.StreamableHTTPConfig{
-		Host:           cfg.Server.HTTP.Host,
-		Port:           cfg.Server.HTTP.Port,
-		SessionTimeout: cfg.Server.HTTP.SessionTimeout,
-		MaxConnections: cfg.Server.HTTP.MaxConnections,
-		CORSEnabled:    cfg.Server.HTTP.CORS.Enabled,
-		CORSOrigins:    cfg.Server.HTTP.CORS.Origins,
+		Host:               cfg.Server.HTTP.Host,
+		Port:               cfg.Server.HTTP.Port,
+		SessionTimeout:     cfg.Server.HTTP.SessionTimeout,
+		MaxConnections:     cfg.Server.HTTP.MaxConnections,
+		CORSEnabled:        cfg.Server.HTTP.CORS.Enabled,
+		CORSOrigins:        cfg.Server.HTTP.CORS.Origins,
+		CORSMethods:        cfg.Server.HTTP.CORS.Methods,
+		CORSHeaders:        cfg.Server.HTTP.CORS.Headers,
+		CORSExposedHeaders: cfg.Server.HTTP.CORS.ExposedHeaders,
+		CORSCredentials:    cfg.Server.HTTP.CORS.AllowCredentials,
+		CORSMaxAge:         cfg.Server.HTTP.CORS.MaxAge,
+		MetricsEnabled:     cfg.Server.HTTP.MetricsEnabled,
+		HealthEnabled:      cfg.Server.HTTP.HealthEnabled,
+		OpsPort:            cfg.Server.HTTP.OpsPort,
+		DisableSSE:         cfg.Server.HTTP.DisableSSE,
+		Stateless:          cfg.Server.HTTP.Stateless,
+		RequireSession:     cfg.Server.HTTP.RequireSession,
+		RequestIDMeta:      cfg.Server.HTTP.RequestIDMeta,
+		MaxRequestBytes:    maxRequestBytes,
+		SSEFlushInterval:   cfg.Server.HTTP.SSEFlushInterval,
+		SSEMaxPending:      cfg.Server.HTTP.SSEMaxPending,
+		IdempotencyTTL:     cfg.Server.HTTP.IdempotencyTTL,
+		TrustedProxies:     cfg.Server.HTTP.TrustedProxies,
+		BasePath:           cfg.Server.HTTP.BasePath,
+		AllowedOrigins:     cfg.Server.HTTP.AllowedOrigins,
+		Store:              store,
+		SharedSessions:     store != nil && cfg.Storage.Backend == "redis",
 	}
 
-	// Create MCP-compliant streamable HTTP transport
-	httpTransport := mcp.NewStreamableHTTPTransport(server, httpConfig)
+	if cfg.Server.HTTP.TLS.Enabled {
+		httpConfig.TLS = &mcp.TLSConfig{
+			CertFile:     cfg.Server.HTTP.TLS.CertFile,
+			KeyFile:      cfg.Server.HTTP.TLS.KeyFile,
+			ClientCAFile: cfg.Server.HTTP.TLS.ClientCAFile,
+			MinVersion:   cfg.Server.HTTP.TLS.MinVersion,
+			CipherSuites: cfg.Server.HTTP.TLS.CipherSuites,
+		}
+	}
 
-	// Setup graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	if limit := cfg.Security.RateLimiting; limit.IsEnabled() {
+		httpConfig.RateLimit = &mcp.RateLimitConfig{
+			RequestsPerMinute: limit.RequestsPerMinute,
+			KeyBy:             limit.KeyBy,
+		}
+	}
 
-	// Channel to listen for interrupt signals
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	if oauth := cfg.Server.HTTP.OAuth; oauth.Enabled {
+		httpConfig.OAuth = &mcp.OAuthConfig{
+			ResourceURL: oauth.ResourceURL,
+			Issuer:      oauth.Issuer,
+			Audience:    oauth.Audience,
+			JWKSURL:     oauth.JWKSURL,
+		}
+		log.Printf("OAuth enabled: access tokens must be issued by %s", oauth.Issuer)
+	}
 
-	// Start server in a goroutine
-	go func() {
-		log.Printf("Starting calculator server with MCP streamable HTTP transport on %s:%d...",
-			cfg.Server.HTTP.Host, cfg.Server.HTTP.Port)
+	if cfg.Server.HTTP.Admin.Enabled {
+		httpConfig.AdminToken = cfg.Server.HTTP.Admin.Token
+	}
 
-		if err := httpTransport.Start(); err != nil {
-			log.Printf("HTTP server error: %v", err)
-			cancel()
+	if chaos := cfg.Server.HTTP.Chaos; chaos.Enabled {
+		httpConfig.Chaos = &mcp.ChaosConfig{
+			Delay:     chaos.Delay,
+			DelayRate: chaos.DelayRate,
+			ErrorRate: chaos.ErrorRate,
+			DropRate:  chaos.DropRate,
+			Seed:      chaos.Seed,
 		}
-	}()
+	}
+
+	if auth := cfg.Server.HTTP.Auth; len(auth.APIKeys) > 0 || len(auth.BearerTokens) > 0 {
+		httpConfig.Auth = mcp.NewAuthenticator(credentials(auth.APIKeys), credentials(auth.BearerTokens))
+		log.Printf("Authentication enabled with %d API keys and %d bearer tokens", len(auth.APIKeys), len(auth.BearerTokens))
+	}
 
-	// Wait for shutdown signal
-	select {
-	case <-c:
-		log.Println("Received shutdown signal...")
-	case <-ctx.Done():
-		log.Println("Server context cancelled...")
+	if len(cfg.Tenants) > 0 {
+		tenants := make([]mcp.Tenant, 0, len(cfg.Tenants))
+		for _, tenant := range cfg.Tenants {
+			settings, err := tenantSettings(cfg, tenant)
+			if err != nil {
+				log.Fatalf("Failed to configure tenant %s: %v", tenant.ID, err)
+			}
+			tenants = append(tenants, mcp.Tenant{
+				ID:                tenant.ID,
+				APIKeys:           tenant.APIKeys,
+				RequestsPerMinute: tenant.RequestsPerMinute,
+				AllowedTools:      tenant.AllowedTools,
+				ToolGroups:        tenant.ToolGroups,
+				Context:           settings,
+			})
+		}
+		httpConfig.Tenants = mcp.NewTenantRegistry(tenants)
+		log.Printf("Multi-tenant mode enabled with %d tenants", len(tenants))
 	}
 
-	// Create a timeout context for shutdown
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
+	if cfg.Server.HTTP.Jobs.Enabled {
+		var notifier mcp.JobNotifier
+		if cfg.Server.HTTP.Jobs.WebhookURL != "" {
+			notifier = mcp.NewWebhookNotifier(cfg.Server.HTTP.Jobs.WebhookURL, cfg.Server.HTTP.Jobs.WebhookSecret, cfg.Server.HTTP.Jobs.WebhookTimeout)
+		}
+		httpConfig.Jobs = mcp.NewJobManager(server, notifier)
+	}
 
-	// Graceful shutdown
-	if err := httpTransport.Stop(shutdownCtx); err != nil {
-		log.Printf("Error during shutdown: %v", err)
-	} else {
-		log.Println("Server shut down gracefully")
+	// Create MCP-compliant streamable HTTP transport
+	return mcp.NewStreamableHTTPTransport(server, httpConfig)
+}
+
+// tenantSettings loads a tenant's own unit tables and exchange rates and
+// returns the hook adding them to its requests, or nil when it has neither
+func tenantSettings(cfg *config.Config, tenant config.TenantConfig) (func(context.Context) context.Context, error) {
+	var registry *calculator.ConversionRegistry
+	if tenant.ConversionsFile != "" {
+		data, err := calculator.LoadConversionData(tenant.ConversionsFile)
+		if err != nil {
+			return nil, err
+		}
+		// The tool schemas list the server's categories, and expressions
+		// read the server's constants, so tenants may only add units
+		if len(data.Constants) > 0 {
+			return nil, fmt.Errorf("%s: constants cannot be set per tenant", tenant.ConversionsFile)
+		}
+		for category := range data.Units {
+			if !calculator.DefaultConversionRegistry().HasCategory(category) {
+				return nil, fmt.Errorf("%s: %s is not a unit category of the server; tenants can only add units to existing categories", tenant.ConversionsFile, category)
+			}
+		}
+		if cfg.Tools.Conversions.DataFile != "" {
+			serverData, err := calculator.LoadConversionData(cfg.Tools.Conversions.DataFile)
+			if err != nil {
+				return nil, err
+			}
+			data = serverData.Extend(data)
+		}
+		if registry, err = calculator.NewConversionRegistry(data); err != nil {
+			return nil, fmt.Errorf("invalid conversion data in %s: %v", tenant.ConversionsFile, err)
+		}
+	}
+
+	var rates currency.RateProvider
+	if tenant.ExchangeRates != nil {
+		provider, err := currency.NewStaticRateProvider(tenant.ExchangeRates.Base, tenant.ExchangeRates.Rates)
+		if err != nil {
+			return nil, err
+		}
+		rates = provider
+	}
+
+	if registry == nil && rates == nil {
+		return nil, nil
 	}
+	return func(ctx context.Context) context.Context {
+		if registry != nil {
+			ctx = calculator.WithConversionRegistry(ctx, registry)
+		}
+		if rates != nil {
+			ctx = currency.WithRateProvider(ctx, rates)
+		}
+		return ctx
+	}, nil
 }
 
 func registerTools(server *mcp.Server, mathHandler *handlers.MathHandler, statsHandler *handlers.StatsHandler, financeHandler *handlers.FinanceHandler) {
 	// Basic Math Operations
-	server.RegisterTool(
+	mcp.RegisterTypedTool(
+		server,
 		"basic_math",
-		"Perform basic mathematical operations (add, subtract, multiply, divide)",
+		"Perform basic mathematical operations (add, subtract, multiply, divide, mod, int_divide, power, nth_root)",
 		getBasicMathSchema(),
-		mathHandler.HandleBasicMath,
+		mathHandler.BasicMath,
+		mcp.WithGroup("math"),
+		mcp.WithOutputSchema(getBasicMathOutputSchema()),
+		mcp.WithProvenance(basicMathProvenance),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "add", "operands": []interface{}{2.0, 3.0}},
+			map[string]interface{}{"result": 5},
+		),
+		mcp.WithExample("Multiply two numbers",
+			map[string]interface{}{"operation": "multiply", "operands": []interface{}{12.0, 3.5}},
+			map[string]interface{}{"result": 42},
+		),
+		mcp.WithExample("Take the cube root of 27",
+			map[string]interface{}{"operation": "nth_root", "operands": []interface{}{27.0, 3.0}},
+			map[string]interface{}{"result": 3},
+		),
 	)
 
 	// Advanced Math Functions
-	server.RegisterTool(
+	mcp.RegisterTypedTool(
+		server,
 		"advanced_math",
 		"Perform advanced mathematical functions (trigonometry, logarithms, etc.)",
 		getAdvancedMathSchema(),
-		mathHandler.HandleAdvancedMath,
+		mathHandler.AdvancedMath,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"function": "sqrt", "value": 16.0},
+			map[string]interface{}{"result": 4},
+		),
+		mcp.WithExample("Base-10 logarithm",
+			map[string]interface{}{"function": "log10", "value": 1000.0},
+			map[string]interface{}{"result": 3},
+		),
 	)
 
 	// Expression Evaluation
-	server.RegisterTool(
+	server.RegisterContextTool(
 		"expression_eval",
-		"Evaluate mathematical expressions with variable substitution",
+		"Evaluate mathematical expressions with variable substitution, or simplify them algebraically",
 		getExpressionEvalSchema(),
 		mathHandler.HandleExpressionEval,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"expression": "2 * x + 1", "variables": map[string]interface{}{"x": 3.0}},
+			map[string]interface{}{"result": 7},
+		),
+		mcp.WithExample("Evaluate an expression with variables",
+			map[string]interface{}{"expression": "sqrt(pow(a, 2) + pow(b, 2))", "variables": map[string]interface{}{"a": 3.0, "b": 4.0}},
+			map[string]interface{}{"result": 5},
+		),
+	)
+
+	// Linear Equation Systems
+	server.RegisterTool(
+		"solve_linear_system",
+		"Solve simultaneous linear equations such as \"2x + 3y = 7\" exactly",
+		getLinearSystemSchema(),
+		mathHandler.HandleLinearSystem,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"equations": []interface{}{"x + y = 3", "x - y = 1"}},
+			map[string]interface{}{"exact": map[string]interface{}{"x": "2", "y": "1"}},
+		),
+	)
+
+	// Inequalities
+	server.RegisterTool(
+		"solve_inequality",
+		"Solve a linear or quadratic inequality in one variable, returning the solution set in interval notation",
+		getInequalitySchema(),
+		mathHandler.HandleInequality,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"inequality": "x^2 - 4 > 0"},
+			map[string]interface{}{"notation": "(-∞, -2) ∪ (2, ∞)"},
+		),
+	)
+
+	// Summation and product notation
+	server.RegisterContextTool(
+		"summation",
+		"Evaluate a finite sum (Σ) or product (Π) of an expression over an integer index range, using closed forms for arithmetic and geometric series",
+		getSummationSchema(),
+		mathHandler.HandleSummation,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "sum", "expression": "k", "from": 1.0, "to": 100.0},
+			map[string]interface{}{"result": 5050, "method": "arithmetic"},
+		),
+	)
+
+	// Prime numbers
+	server.RegisterContextTool(
+		"primes",
+		"Prime number utilities: the nth prime, primes in a range, the count of primes below n and the next or previous prime",
+		getPrimesSchema(),
+		mathHandler.HandlePrimes,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "nth", "n": 100.0},
+			map[string]interface{}{"prime": 541},
+		),
+	)
+
+	// Matrix Operations
+	server.RegisterTool(
+		"matrix",
+		"Matrix determinant, inverse, LU/QR/Cholesky decompositions and linear least squares, with condition number diagnostics",
+		getMatrixSchema(),
+		mathHandler.HandleMatrix,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "determinant", "matrix": []interface{}{[]interface{}{2.0, 1.0}, []interface{}{1.0, 3.0}}},
+			map[string]interface{}{"determinant": 5},
+		),
+	)
+
+	// Eigenvalues
+	server.RegisterTool(
+		"eigen",
+		"Compute eigenvalues and eigenvectors iteratively (Jacobi for symmetric matrices, shifted QR for general ones) with convergence reporting",
+		getEigenSchema(),
+		mathHandler.HandleEigen,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"matrix": []interface{}{[]interface{}{2.0, 1.0}, []interface{}{1.0, 2.0}}},
+			map[string]interface{}{"converged": true, "eigenvalues": []interface{}{
+				map[string]interface{}{"real": 3},
+				map[string]interface{}{"real": 1},
+			}},
+		),
+	)
+
+	// Engineering tolerances
+	server.RegisterTool(
+		"tolerance",
+		"Engineering tolerances: worst-case and RSS stack-ups, percent error, tolerance band checks and hole/shaft fits",
+		getToleranceSchema(),
+		mathHandler.HandleTolerance,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "stack_up", "dimensions": []interface{}{
+				map[string]interface{}{"nominal": 10.0, "tolerance": 0.3},
+				map[string]interface{}{"nominal": 20.0, "tolerance": 0.4},
+			}},
+			map[string]interface{}{"nominal": 30, "worst_case": map[string]interface{}{"lower": 29.3, "upper": 30.7}, "rss": map[string]interface{}{"lower": 29.5, "upper": 30.5}},
+		),
+	)
+
+	// Electronics
+	server.RegisterTool(
+		"electronics",
+		"Electronics helpers: Ohm's law, series/parallel resistors, capacitors and inductors, voltage dividers and RC time constants, with SI-prefixed results",
+		getElectronicsSchema(),
+		mathHandler.HandleElectronics,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "ohms_law", "voltage": 12.0, "resistance": 4.0},
+			map[string]interface{}{"values": map[string]interface{}{"current": 3, "power": 36}, "formatted": map[string]interface{}{"power": "36 W"}},
+		),
+	)
+
+	// Construction and DIY estimates
+	server.RegisterTool(
+		"estimator",
+		"Estimate building materials from dimensions in any length unit: paint for walls or surfaces, concrete volume, tiles with a waste allowance, and wallpaper rolls",
+		getEstimatorSchema(),
+		mathHandler.HandleEstimator,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "tiles", "length": 3.0, "width": 2.0, "tile_length": 30.0, "tile_width": 30.0, "tile_unit": "cm", "waste_percent": 10.0},
+			map[string]interface{}{"area_m2": 6, "tiles": 74},
+		),
+	)
+
+	// Screen resolutions and aspect ratios
+	server.RegisterTool(
+		"aspect_ratio",
+		"Screen resolution helpers: reduce a resolution to its aspect ratio, work out pixel density (PPI) and physical size from the diagonal, and scale a resolution keeping its ratio",
+		getAspectRatioSchema(),
+		mathHandler.HandleAspectRatio,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "reduce", "width": 1920.0, "height": 1080.0},
+			map[string]interface{}{"ratio": "16:9", "gcd": 120},
+		),
+	)
+
+	// Grades
+	server.RegisterTool(
+		"grading",
+		"Student grade calculations: weighted course grades from category scores, the final exam score needed for a target grade, and GPA from letter grades",
+		getGradingSchema(),
+		mathHandler.HandleGrading,
+		mcp.WithGroup("math"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "final_needed", "current_grade": 85.0, "final_weight": 25.0, "target_grade": 90.0},
+			map[string]interface{}{"needed": 105, "achievable": false},
+		),
 	)
 
 	// Statistics
@@ -157,28 +595,131 @@ func registerTools(server *mcp.Server, mathHandler *handlers.MathHandler, statsH
 		"Perform statistical analysis on data sets",
 		getStatisticsSchema(),
 		statsHandler.HandleStatistics,
+		mcp.WithGroup("stats"),
+		mcp.WithOutputSchema(getStatisticsOutputSchema()),
+		mcp.WithProvenance(statisticsProvenance),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"data": []interface{}{2.0, 4.0, 4.0, 4.0, 5.0, 5.0, 7.0, 9.0}, "operation": "mean"},
+			map[string]interface{}{"result": 5},
+		),
+		mcp.WithExample("Median of a data set",
+			map[string]interface{}{"data": []interface{}{7.0, 1.0, 3.0, 9.0, 5.0}, "operation": "median"},
+			map[string]interface{}{"result": 5},
+		),
 	)
 
 	// Unit Conversion
-	server.RegisterTool(
+	mcp.RegisterTypedContextTool(
+		server,
 		"unit_conversion",
 		"Convert between different units of measurement",
 		getUnitConversionSchema(),
-		mathHandler.HandleUnitConversion,
+		mathHandler.UnitConversionContext,
+		mcp.WithGroup("conversion"),
+		mcp.WithCompletion("fromUnit", completeUnits),
+		mcp.WithCompletion("toUnit", completeUnits),
+		mcp.WithValueAliases(canonicalUnit, "fromUnit", "toUnit"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"value": 1.0, "fromUnit": "km", "toUnit": "m", "category": "length"},
+			map[string]interface{}{"converted_value": 1000},
+		),
+		mcp.WithExample("Convert a temperature",
+			map[string]interface{}{"value": 100.0, "fromUnit": "C", "toUnit": "F", "category": "temperature"},
+			map[string]interface{}{"converted_value": 212, "converted_unit": "F"},
+		),
 	)
 
-	// Financial Calculations
+	// Time zone and duration conversion
+	server.RegisterTool(
+		"timezone",
+		"Convert timestamps between time zones, measure durations across DST transitions and convert between duration units",
+		getTimezoneSchema(),
+		mathHandler.HandleTimezone,
+		mcp.WithGroup("conversion"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "convert_duration", "value": 5400.0, "to_unit": "h"},
+			map[string]interface{}{"value": 1.5, "formatted": "1h30m"},
+		),
+	)
+
+	// Cooking and recipe scaling
 	server.RegisterTool(
+		"cooking",
+		"Scale recipes, convert ingredient quantities between volume and weight, and convert oven temperatures and gas marks",
+		getCookingSchema(),
+		mathHandler.HandleCooking,
+		mcp.WithGroup("conversion"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "convert", "quantity": 2.0, "from_unit": "cup", "to_unit": "g", "ingredient": "flour"},
+			map[string]interface{}{"quantity": 250, "unit": "g"},
+		),
+	)
+
+	// Financial Calculations
+	server.RegisterContextTool(
 		"financial",
 		"Perform financial calculations (interest, loans, ROI)",
 		getFinancialSchema(),
-		financeHandler.HandleFinancialCalculation,
+		financeHandler.HandleFinancialCalculationContext,
+		mcp.WithGroup("finance"),
+		mcp.WithOutputSchema(getFinancialOutputSchema()),
+		mcp.WithProvenance(financialProvenance),
+		mcp.WithRequiredArguments(financialRequiredArguments),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "simple_interest", "principal": 1000.0, "rate": 5.0, "time": 2.0},
+			map[string]interface{}{"result": 100},
+		),
 	)
 
 	// Additional specialized tools
 	registerAdditionalTools(server, statsHandler, financeHandler)
 }
 
+// basicMathProvenance reports the rounding of basic_math results, which
+// have no decimal places unless precision asks for them
+func basicMathProvenance(arguments map[string]interface{}) types.Provenance {
+	places, _ := arguments["precision"].(float64)
+	return types.Provenance{
+		Algorithm: "float64 arithmetic",
+		Precision: fmt.Sprintf("%d decimal places, rounded half away from zero", int(places)),
+	}
+}
+
+// statisticsProvenance reports the estimator behind a statistics result
+func statisticsProvenance(arguments map[string]interface{}) types.Provenance {
+	operation, _ := arguments["operation"].(string)
+	varianceType, _ := arguments["type"].(string)
+	_, weighted := arguments["weights"].([]interface{})
+	return types.Provenance{Algorithm: calculator.NewStatisticsCalculator().Method(operation, varianceType, weighted)}
+}
+
+// financialProvenance reports the formula behind a financial result
+func financialProvenance(arguments map[string]interface{}) types.Provenance {
+	operation, _ := arguments["operation"].(string)
+	dayCount, _ := arguments["dayCount"].(string)
+	_, dated := arguments["startDate"].(string)
+	return types.Provenance{Algorithm: calculator.NewFinancialCalculator().Method(operation, dayCount, dated)}
+}
+
+// financialRequiredArguments names the arguments each financial operation
+// needs, so that missing ones can be elicited from the client
+func financialRequiredArguments(arguments map[string]interface{}) []string {
+	var required []string
+	switch arguments["operation"] {
+	case "compound_interest", "simple_interest", "loan_payment", "future_value":
+		required = []string{"principal", "rate", "time"}
+	case "present_value":
+		required = []string{"futureValue", "rate", "time"}
+	case "roi":
+		required = []string{"principal", "futureValue"}
+	}
+	// Accrual dates stand in for the time period
+	if _, dated := arguments["startDate"]; dated {
+		required = slices.DeleteFunc(required, func(name string) bool { return name == "time" })
+	}
+	return required
+}
+
 func registerAdditionalTools(server *mcp.Server, statsHandler *handlers.StatsHandler, financeHandler *handlers.FinanceHandler) {
 	// Statistics Summary
 	server.RegisterTool(
@@ -186,6 +727,11 @@ func registerAdditionalTools(server *mcp.Server, statsHandler *handlers.StatsHan
 		"Get comprehensive statistical summary of a dataset",
 		getStatsSummarySchema(),
 		statsHandler.HandleStatsSummary,
+		mcp.WithGroup("stats"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"data": []interface{}{1.0, 2.0, 3.0, 4.0}},
+			map[string]interface{}{"summary": map[string]interface{}{"count": 4, "mean": 2.5, "min": 1, "max": 4}},
+		),
 	)
 
 	// Percentile Calculation
@@ -194,14 +740,54 @@ func registerAdditionalTools(server *mcp.Server, statsHandler *handlers.StatsHan
 		"Calculate specific percentile of a dataset",
 		getPercentileSchema(),
 		statsHandler.HandlePercentileCalculation,
+		mcp.WithGroup("stats"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"data": []interface{}{1.0, 2.0, 3.0, 4.0, 5.0}, "percentile": 50.0},
+			map[string]interface{}{"value": 3},
+		),
 	)
 
-	// Multiple Unit Conversions
+	// Forecasting
+	server.RegisterTool(
+		"forecast",
+		"Project a numeric series ahead with naive, moving-average or Holt-Winters forecasts and prediction intervals",
+		getForecastSchema(),
+		statsHandler.HandleForecast,
+		mcp.WithGroup("stats"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"series": []interface{}{1.0, 2.0, 3.0, 4.0}, "method": "moving_average", "window": 2.0, "horizon": 1.0},
+			map[string]interface{}{"forecasts": []interface{}{map[string]interface{}{"step": 1, "value": 3.5}}},
+		),
+	)
+
+	// Probability scenarios
 	server.RegisterTool(
+		"probability",
+		"Probability of common scenarios: independent and conditional events, binomial at-least-k successes, the birthday problem and expected value of a discrete distribution",
+		getProbabilitySchema(),
+		statsHandler.HandleProbability,
+		mcp.WithGroup("stats"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "binomial", "trials": 3.0, "successes": 2.0, "probability": 0.5},
+			map[string]interface{}{"result": 0.5, "values": map[string]interface{}{"exactly": 0.375}},
+		),
+	)
+
+	// Multiple Unit Conversions
+	server.RegisterContextTool(
 		"batch_conversion",
 		"Convert multiple values between units",
 		getBatchConversionSchema(),
-		statsHandler.HandleMultipleConversions,
+		statsHandler.HandleMultipleConversionsContext,
+		mcp.WithGroup("conversion"),
+		mcp.WithOutputSchema(getSeriesOutputSchema()),
+		mcp.WithCompletion("fromUnit", completeUnits),
+		mcp.WithCompletion("toUnit", completeUnits),
+		mcp.WithValueAliases(canonicalUnit, "fromUnit", "toUnit"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"values": []interface{}{0.0, 100.0}, "fromUnit": "C", "toUnit": "F", "category": "temperature"},
+			map[string]interface{}{"converted_values": []interface{}{32, 212}},
+		),
 	)
 
 	// NPV Calculation
@@ -210,6 +796,11 @@ func registerAdditionalTools(server *mcp.Server, statsHandler *handlers.StatsHan
 		"Calculate Net Present Value of cash flows",
 		getNPVSchema(),
 		financeHandler.HandleNPV,
+		mcp.WithGroup("finance"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"cashFlows": []interface{}{-100.0, 110.0}, "discountRate": 10.0},
+			map[string]interface{}{"npv": 0},
+		),
 	)
 
 	// IRR Calculation
@@ -218,6 +809,11 @@ func registerAdditionalTools(server *mcp.Server, statsHandler *handlers.StatsHan
 		"Calculate Internal Rate of Return of cash flows",
 		getIRRSchema(),
 		financeHandler.HandleIRR,
+		mcp.WithGroup("finance"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"cashFlows": []interface{}{-100.0, 110.0}},
+			map[string]interface{}{"irr": 10},
+		),
 	)
 
 	// Loan Comparison
@@ -226,6 +822,12 @@ func registerAdditionalTools(server *mcp.Server, statsHandler *handlers.StatsHan
 		"Compare multiple loan options",
 		getLoanComparisonSchema(),
 		financeHandler.HandleLoanComparison,
+		mcp.WithGroup("finance"),
+		mcp.WithOutputSchema(getTableOutputSchema()),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"loans": []interface{}{map[string]interface{}{"principal": 1200.0, "rate": 12.0, "time": 1.0}}},
+			map[string]interface{}{"best_value": 106.61854641401},
+		),
 	)
 
 	// Investment Scenarios
@@ -234,6 +836,267 @@ func registerAdditionalTools(server *mcp.Server, statsHandler *handlers.StatsHan
 		"Compare multiple investment scenarios",
 		getInvestmentScenariosSchema(),
 		financeHandler.HandleInvestmentScenarios,
+		mcp.WithGroup("finance"),
+		mcp.WithOutputSchema(getTableOutputSchema()),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"scenarios": []interface{}{map[string]interface{}{"principal": 1000.0, "rate": 10.0, "time": 2.0}}},
+			map[string]interface{}{"best_value": 1210},
+		),
+	)
+
+	// Amortization Schedule, streamed as partial results when requested
+	server.RegisterContextTool(
+		"amortization_schedule",
+		"Generate the payment-by-payment amortization schedule of a loan",
+		getAmortizationScheduleSchema(),
+		financeHandler.HandleAmortizationSchedule,
+		mcp.WithGroup("finance"),
+		mcp.WithOutputSchema(getScheduleOutputSchema()),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"principal": 1000.0, "rate": 12.0, "time": 1.0},
+			map[string]interface{}{"payment": 88.85, "payments": 12},
+		),
+	)
+
+	// Rule-of-thumb personal finance estimates
+	server.RegisterTool(
+		"rule_of_thumb",
+		"Quick personal-finance rules of thumb: rule of 72 doubling time, 50/30/20 budget, debt-to-income ratio and emergency fund size",
+		getRuleOfThumbSchema(),
+		financeHandler.HandleRuleOfThumb,
+		mcp.WithGroup("finance"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "rule_of_72", "rate": 8.0},
+			map[string]interface{}{"result": 9},
+		),
+	)
+
+	// Proportional splits that always sum to the total
+	server.RegisterTool(
+		"allocate",
+		"Split an amount across parties by weights or percentages, using largest-remainder rounding so the parts always sum exactly to the total",
+		getAllocateSchema(),
+		financeHandler.HandleAllocate,
+		mcp.WithGroup("finance"),
+		mcp.WithSelfCheck(
+			map[string]interface{}{"total": 100.0, "shares": []interface{}{
+				map[string]interface{}{"weight": 1.0},
+				map[string]interface{}{"weight": 1.0},
+				map[string]interface{}{"weight": 1.0},
+			}},
+			map[string]interface{}{"parts": []interface{}{
+				map[string]interface{}{"amount": 33.34},
+				map[string]interface{}{"amount": 33.33},
+				map[string]interface{}{"amount": 33.33},
+			}},
+		),
+	)
+}
+
+func registerExportTool(server *mcp.Server, exportHandler *handlers.ExportHandler) {
+	// Export history or generated tables as CSV/JSON documents
+	server.RegisterContextTool(
+		"export",
+		"Export the session's calculation history, a loan amortization schedule or a histogram as a CSV or JSON document",
+		getExportSchema(),
+		exportHandler.HandleExport,
+		mcp.WithSelfCheck(
+			map[string]interface{}{"source": "histogram", "data": []interface{}{1.0, 2.0, 3.0, 4.0}, "bins": 2.0},
+			map[string]interface{}{"content": []interface{}{
+				map[string]interface{}{"text": "Exported 2 histogram rows as csv"},
+				map[string]interface{}{"resource": map[string]interface{}{"text": "lower,upper,count\n1,2.5,2\n2.5,4,2\n"}},
+			}},
+		),
+	)
+}
+
+func registerFormulaTools(server *mcp.Server, formulaHandler *handlers.FormulaHandler) {
+	// Saved formulas, listed as formula:// resources
+	server.RegisterContextTool(
+		"save_formula",
+		"Save a named formula (expression plus default variables) for this session or persistently",
+		getSaveFormulaSchema(),
+		formulaHandler.HandleSaveFormula,
+		mcp.WithGroup("math"),
+	)
+
+	server.RegisterContextTool(
+		"run_formula",
+		"Evaluate a saved formula by name, overriding its default variables as needed",
+		getRunFormulaSchema(),
+		formulaHandler.HandleRunFormula,
+		mcp.WithGroup("math"),
+		mcp.WithCompletion("name", func(ctx context.Context, _ map[string]string) ([]string, error) {
+			formulas, err := server.Formulas(ctx)
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, len(formulas))
+			for i, formula := range formulas {
+				names[i] = formula.Name
+			}
+			return names, nil
+		}),
+	)
+}
+
+// completeUnits suggests the units of the category already chosen, or of
+// every category before one is, including the tenant's own units
+func completeUnits(ctx context.Context, arguments map[string]string) ([]string, error) {
+	registry := calculator.ConversionRegistryFromContext(ctx)
+	if registry == nil {
+		registry = calculator.DefaultConversionRegistry()
+	}
+	if category := arguments["category"]; category != "" {
+		units, ok := registry.Units(category)
+		if !ok {
+			return nil, fmt.Errorf("unsupported category: %s", category)
+		}
+		return units, nil
+	}
+
+	var units []string
+	for _, category := range registry.Categories() {
+		categoryUnits, _ := registry.Units(category)
+		units = append(units, categoryUnits...)
+	}
+	return units, nil
+}
+
+// canonicalUnit resolves spelled-out and differently cased unit names,
+// such as "Kilometers", onto the units of the conversion registry
+func canonicalUnit(unit string) (string, bool) {
+	return calculator.DefaultConversionRegistry().CanonicalUnit(unit)
+}
+
+func registerDiagnosticsTool(server *mcp.Server) {
+	// Run the known-answer test of every tool, e.g. as a deployment smoke test
+	server.RegisterContextTool(
+		"self_check",
+		"Run known-answer self-checks of the server's tools and report pass/fail per tool",
+		getSelfCheckSchema(),
+		server.HandleSelfCheck,
+	)
+}
+
+// currencyRatesURI is the resource publishing the latest exchange rates
+const currencyRatesURI = "calculator://currency/rates"
+
+// refreshRates fetches the published exchange rates now and then every
+// interval, telling subscribers of the rates resource when they change
+func refreshRates(server *mcp.Server, rates *currency.RateTable, interval time.Duration) {
+	refresh := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		changed, err := rates.Refresh(ctx)
+		if err != nil {
+			log.Printf("Failed to refresh exchange rates: %v", err)
+			return
+		}
+		if changed {
+			server.NotifyResourceUpdated(currencyRatesURI)
+		}
+	}
+
+	refresh()
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		refresh()
+	}
+}
+
+func registerResources(server *mcp.Server, resourceHandler *handlers.ResourceHandler, historyEnabled, ratesEnabled bool) {
+	// Reference data and session history readable via resources/read
+	if historyEnabled {
+		server.RegisterResource(
+			"calculator://history",
+			"Calculation history",
+			"The calculations made in this session, oldest first",
+			"application/json",
+			resourceHandler.ReadHistory,
+		)
+	}
+
+	server.RegisterResource(
+		"calculator://units",
+		"Supported units",
+		"The units accepted by unit_conversion, grouped by category",
+		"application/json",
+		resourceHandler.ReadUnitTables,
+	)
+
+	// Dynamic resources, whose subscribers are told when they change
+	server.RegisterResource(
+		mcp.ToolCatalogURI,
+		"Tool catalog",
+		"The tools available to this session; updated when tools are enabled or disabled",
+		"application/json",
+		server.ReadToolCatalog,
+	)
+	if ratesEnabled {
+		server.RegisterResource(
+			currencyRatesURI,
+			"Exchange rates",
+			"The latest exchange rates of the base currency; updated when the rates are refreshed",
+			"application/json",
+			resourceHandler.ReadCurrencyRates,
+		)
+	}
+
+	// Operators read the metrics with the admin token
+	server.RegisterAdminResource(
+		mcp.MetricsURI,
+		"Tool metrics",
+		"Invocations, errors and latency percentiles per tool, and the statistics of the server's caches and limits",
+		"application/json",
+		server.ReadMetrics,
+	)
+
+	server.RegisterResource(
+		"calculator://constants",
+		"Mathematical constants",
+		"The named constants available in expression_eval and their values",
+		"application/json",
+		resourceHandler.ReadConstants,
+	)
+}
+
+func registerPrompts(server *mcp.Server, promptHandler *handlers.PromptHandler) {
+	// Guided templates for common calculator workflows
+	server.RegisterPrompt(
+		"explain_calculation",
+		"Evaluate an expression and explain step by step how the result is obtained",
+		[]types.PromptArgument{
+			{Name: "expression", Description: "Expression to evaluate, e.g. 2 * (3 + sqrt(x))", Required: true},
+			{Name: "variables", Description: "Variable values, e.g. x=16, y=2"},
+		},
+		promptHandler.HandleExplainCalculation,
+	)
+
+	server.RegisterPrompt(
+		"loan_amortization",
+		"Build an amortization_schedule query for a loan and summarize the schedule",
+		[]types.PromptArgument{
+			{Name: "principal", Description: "Loan amount", Required: true},
+			{Name: "rate", Description: "Annual interest rate as a percentage", Required: true},
+			{Name: "years", Description: "Loan term in years", Required: true},
+			{Name: "periods", Description: "Payments per year (defaults to 12)"},
+		},
+		promptHandler.HandleLoanAmortization,
+	)
+
+	server.RegisterPrompt(
+		"summarize_data",
+		"Describe a data set in plain language using summary statistics",
+		[]types.PromptArgument{
+			{Name: "data", Description: "Numbers separated by commas or spaces", Required: true},
+			{Name: "context", Description: "What the numbers measure, e.g. daily sales in USD"},
+		},
+		promptHandler.HandleSummarizeData,
 	)
 }
 
@@ -244,8 +1107,8 @@ func getBasicMathSchema() map[string]interface{} {
 		"properties": map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"add", "subtract", "multiply", "divide"},
-				"description": "The mathematical operation to perform",
+				"enum":        []string{"add", "subtract", "multiply", "divide", "mod", "int_divide", "power", "nth_root"},
+				"description": "The mathematical operation to perform (mod, int_divide, power and nth_root take exactly 2 operands)",
 			},
 			"operands": map[string]interface{}{
 				"type": "array",
@@ -262,8 +1125,47 @@ func getBasicMathSchema() map[string]interface{} {
 				"default":     2,
 				"description": "Number of decimal places in result",
 			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"plain", "engineering"},
+				"default":     "plain",
+				"description": "Also render the result in engineering notation with an SI prefix (e.g. 47 μF)",
+			},
+			"symbol": map[string]interface{}{
+				"type":        "string",
+				"description": "Unit symbol for engineering output, e.g. F, Ω or Hz",
+			},
+			"explain": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Include the ordered computation steps in the result",
+			},
+		},
+		"required":             []string{"operation", "operands"},
+		"additionalProperties": false,
+	}
+}
+
+// getBasicMathOutputSchema describes the result of basic_math
+func getBasicMathOutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"result": map[string]interface{}{
+				"type":        "number",
+				"description": "The result, rounded to the requested precision",
+			},
+			"formatted": map[string]interface{}{
+				"type":        "string",
+				"description": "The result in engineering notation, when requested",
+			},
+			"steps": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "The computation steps, when requested",
+			},
 		},
-		"required": []string{"operation", "operands"},
+		"required": []string{"result"},
 	}
 }
 
@@ -290,8 +1192,19 @@ func getAdvancedMathSchema() map[string]interface{} {
 				"default":     "radians",
 				"description": "Unit for trigonometric functions",
 			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"plain", "engineering"},
+				"default":     "plain",
+				"description": "Also render the result in engineering notation with an SI prefix (e.g. 47 μF)",
+			},
+			"symbol": map[string]interface{}{
+				"type":        "string",
+				"description": "Unit symbol for engineering output, e.g. F, Ω or Hz",
+			},
 		},
-		"required": []string{"function", "value"},
+		"required":             []string{"function", "value"},
+		"additionalProperties": false,
 	}
 }
 
@@ -305,106 +1218,1020 @@ func getExpressionEvalSchema() map[string]interface{} {
 			},
 			"variables": map[string]interface{}{
 				"type":        "object",
-				"description": "Variables to substitute in the expression",
+				"description": "Variables to substitute in the expression. Array values (of equal length) evaluate the expression element-wise and return a results array, e.g. {\"x\": [1, 2, 3]} for 2*x + 1",
 				"patternProperties": map[string]interface{}{
 					"^[a-zA-Z][a-zA-Z0-9_]*$": map[string]interface{}{
-						"type": "number",
+						"type":     []string{"number", "array"},
+						"items":    map[string]interface{}{"type": "number"},
+						"maxItems": 10000,
 					},
 				},
 			},
-		},
-		"required": []string{"expression"},
-	}
-}
-
-func getStatisticsSchema() map[string]interface{} {
-	return map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"data": map[string]interface{}{
-				"type": "array",
-				"items": map[string]interface{}{
-					"type": "number",
-				},
-				"minItems":    1,
-				"description": "Array of numerical data",
+			"explain": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Include the substitution and evaluation steps in the result",
 			},
 			"operation": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"mean", "median", "mode", "std_dev", "variance", "percentile"},
-				"description": "Statistical operation to perform",
+				"enum":        []string{"evaluate", "simplify"},
+				"default":     "evaluate",
+				"description": "evaluate computes the value; simplify combines like terms, cancels common factors and returns the simplified, canonical (expanded) and factored forms",
 			},
 		},
-		"required": []string{"data", "operation"},
+		"required":             []string{"expression"},
+		"additionalProperties": false,
 	}
 }
 
-func getUnitConversionSchema() map[string]interface{} {
+func getSummationSchema() map[string]interface{} {
 	return map[string]interface{}{
 		"type": "object",
 		"properties": map[string]interface{}{
-			"value": map[string]interface{}{
-				"type":        "number",
-				"description": "Value to convert",
-			},
-			"fromUnit": map[string]interface{}{
+			"operation": map[string]interface{}{
 				"type":        "string",
-				"description": "Source unit",
+				"enum":        []string{"sum", "product"},
+				"description": "sum (Σ) or product (Π) of the terms",
 			},
-			"toUnit": map[string]interface{}{
+			"expression": map[string]interface{}{
 				"type":        "string",
-				"description": "Target unit",
+				"description": "Term in expression_eval syntax, with ^ as a power, e.g. \"1/k^2\" or \"3*pow(2, k)\"",
 			},
-			"category": map[string]interface{}{
+			"index": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"length", "weight", "temperature", "volume", "area"},
-				"description": "Category of measurement",
+				"default":     "k",
+				"description": "Name of the index variable",
+			},
+			"from": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     -1e15,
+				"maximum":     1e15,
+				"description": "First index value",
+			},
+			"to": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     -1e15,
+				"maximum":     1e15,
+				"description": "Last index value, inclusive; below from gives the empty sum 0 or product 1",
+			},
+			"variables": map[string]interface{}{
+				"type":                 "object",
+				"description":          "Values of the other variables in the expression",
+				"additionalProperties": map[string]interface{}{"type": "number"},
+			},
+			"closed_form": map[string]interface{}{
+				"type":        "boolean",
+				"default":     true,
+				"description": "Use closed forms for arithmetic and geometric series; false evaluates every term (at most 1,000,000)",
 			},
 		},
-		"required": []string{"value", "fromUnit", "toUnit", "category"},
+		"required":             []string{"operation", "expression", "from", "to"},
+		"additionalProperties": false,
 	}
 }
 
-func getFinancialSchema() map[string]interface{} {
+func getPrimesSchema() map[string]interface{} {
 	return map[string]interface{}{
 		"type": "object",
 		"properties": map[string]interface{}{
 			"operation": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"compound_interest", "simple_interest", "loan_payment", "roi", "present_value", "future_value"},
-				"description": "Financial operation to perform",
+				"enum":        []string{"nth", "range", "count", "next", "previous"},
+				"description": "nth prime, primes in a range, count of primes below n, or the next or previous prime after a value",
 			},
-			"principal": map[string]interface{}{
-				"type":        "number",
+			"n": map[string]interface{}{
+				"type":        "integer",
 				"minimum":     0,
-				"description": "Principal amount or initial investment",
+				"maximum":     1e9,
+				"description": "Which prime to return, from 1 to 50,000,000 (nth), or the bound to count primes below (count)",
 			},
-			"rate": map[string]interface{}{
-				"type":        "number",
+			"from": map[string]interface{}{
+				"type":        "integer",
 				"minimum":     0,
-				"description": "Interest rate (as percentage)",
+				"maximum":     1e12,
+				"description": "Start of the range, inclusive (range)",
 			},
-			"time": map[string]interface{}{
-				"type":        "number",
+			"to": map[string]interface{}{
+				"type":        "integer",
 				"minimum":     0,
-				"description": "Time period in years",
+				"maximum":     1e12,
+				"description": "End of the range, inclusive, at most 100,000,000 above from (range)",
 			},
-			"periods": map[string]interface{}{
+			"value": map[string]interface{}{
 				"type":        "integer",
-				"minimum":     1,
-				"description": "Number of compounding periods per year",
-			},
-			"futureValue": map[string]interface{}{
-				"type":        "number",
 				"minimum":     0,
-				"description": "Future value (for ROI and present value calculations)",
+				"maximum":     1e12,
+				"description": "Value to search from (next, previous)",
 			},
-		},
-		"required": []string{"operation"},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     100000,
+				"default":     1000,
+				"description": "Most primes to list (range); all are counted",
+			},
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getLinearSystemSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"equations": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+				"minItems":    1,
+				"maxItems":    50,
+				"description": "Linear equations over named variables, e.g. [\"2x + 3y = 7\", \"x - y = 1\"]. Coefficients may be decimals or fractions (3/4x, x/2)",
+			},
+		},
+		"required":             []string{"equations"},
+		"additionalProperties": false,
+	}
+}
+
+func getInequalitySchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"inequality": map[string]interface{}{
+				"type":        "string",
+				"description": "Inequality in one variable using <, <=, > or >=, e.g. \"x^2 - 5x + 6 >= 0\" or \"2x + 3 < 7\"",
+			},
+		},
+		"required":             []string{"inequality"},
+		"additionalProperties": false,
+	}
+}
+
+func getMatrixSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"determinant", "inverse", "lu", "qr", "cholesky", "least_squares"},
+				"description": "Operation to perform. lu returns P, L and U with A = P·L·U; qr returns Q and R; cholesky returns L with A = L·Lᵀ; least_squares minimises ‖A·x − vector‖",
+			},
+			"matrix": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "array",
+					"items":    map[string]interface{}{"type": "number"},
+					"minItems": 1,
+					"maxItems": 50,
+				},
+				"minItems":    1,
+				"maxItems":    50,
+				"description": "Matrix as an array of rows, e.g. [[4, 2], [2, 3]]",
+			},
+			"vector": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "number"},
+				"description": "Right-hand side b for least_squares, one value per matrix row",
+			},
+		},
+		"required":             []string{"operation", "matrix"},
+		"additionalProperties": false,
+	}
+}
+
+func getEigenSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"matrix": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "array",
+					"items":    map[string]interface{}{"type": "number"},
+					"minItems": 1,
+					"maxItems": 50,
+				},
+				"minItems":    1,
+				"maxItems":    50,
+				"description": "Square matrix as an array of rows, e.g. [[2, 1], [1, 2]]",
+			},
+			"method": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"auto", "jacobi", "qr"},
+				"description": "jacobi (symmetric matrices only), qr (any square matrix, finds complex eigenvalues) or auto (default: jacobi when symmetric)",
+			},
+			"max_iterations": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     10000,
+				"description": "Iteration limit: Jacobi sweeps (default 100) or QR steps (default 30 per eigenvalue)",
+			},
+			"tolerance": map[string]interface{}{
+				"type":             "number",
+				"exclusiveMinimum": 0,
+				"exclusiveMaximum": 1,
+				"description":      "Relative size below which off-diagonal entries count as converged (default 1e-12)",
+			},
+		},
+		"required":             []string{"matrix"},
+		"additionalProperties": false,
+	}
+}
+
+func getToleranceSchema() map[string]interface{} {
+	dimension := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "object",
+			"description": description,
+			"properties": map[string]interface{}{
+				"nominal": map[string]interface{}{
+					"type":        "number",
+					"description": "Nominal size",
+				},
+				"upper": map[string]interface{}{
+					"type":        "number",
+					"description": "Signed upper deviation from nominal, e.g. 0.021",
+				},
+				"lower": map[string]interface{}{
+					"type":        "number",
+					"description": "Signed lower deviation from nominal, e.g. -0.02",
+				},
+				"tolerance": map[string]interface{}{
+					"type":        "number",
+					"minimum":     0,
+					"description": "Symmetric ± tolerance, instead of upper and lower",
+				},
+				"subtract": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Dimension reduces the stack total (stack_up only)",
+				},
+			},
+			"required":             []string{"nominal"},
+			"additionalProperties": false,
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"stack_up", "percent_error", "within_tolerance", "fit"},
+				"description": "Tolerance calculation to perform",
+			},
+			"dimensions": map[string]interface{}{
+				"type":        "array",
+				"items":       dimension("Dimension in the stack"),
+				"minItems":    1,
+				"maxItems":    1000,
+				"description": "Chain of toleranced dimensions (stack_up)",
+			},
+			"dimension": dimension("Toleranced dimension the measurement must fall within (within_tolerance)"),
+			"measured": map[string]interface{}{
+				"type":        "number",
+				"description": "Measured value (percent_error, within_tolerance)",
+			},
+			"expected": map[string]interface{}{
+				"type":        "number",
+				"description": "Expected or true value (percent_error)",
+			},
+			"hole":  dimension("Hole size and deviations (fit)"),
+			"shaft": dimension("Shaft size and deviations (fit)"),
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getElectronicsSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"ohms_law", "series", "parallel", "voltage_divider", "rc_time_constant"},
+				"description": "Circuit calculation to perform. All values are in base SI units (V, A, Ω, W, F, H, s)",
+			},
+			"voltage": map[string]interface{}{
+				"type":        "number",
+				"description": "Voltage in volts (ohms_law)",
+			},
+			"current": map[string]interface{}{
+				"type":        "number",
+				"description": "Current in amperes (ohms_law)",
+			},
+			"resistance": map[string]interface{}{
+				"type":        "number",
+				"description": "Resistance in ohms (ohms_law, rc_time_constant)",
+			},
+			"power": map[string]interface{}{
+				"type":        "number",
+				"description": "Power in watts (ohms_law)",
+			},
+			"component": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"resistor", "capacitor", "inductor"},
+				"description": "Type of the components combined (series, parallel)",
+			},
+			"values": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "number"},
+				"minItems":    2,
+				"maxItems":    1000,
+				"description": "Component values in ohms, farads or henries (series, parallel)",
+			},
+			"input_voltage": map[string]interface{}{
+				"type":        "number",
+				"description": "Divider input voltage (voltage_divider)",
+			},
+			"r1": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Upper resistor in ohms, between the input and the output (voltage_divider)",
+			},
+			"r2": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Lower resistor in ohms, between the output and ground (voltage_divider)",
+			},
+			"load": map[string]interface{}{
+				"type":        "number",
+				"description": "Optional load resistance in ohms across r2 (voltage_divider)",
+			},
+			"capacitance": map[string]interface{}{
+				"type":        "number",
+				"description": "Capacitance in farads (rc_time_constant)",
+			},
+			"time": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Optional elapsed time in seconds for the percentage charged (rc_time_constant)",
+			},
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getTimezoneSchema() map[string]interface{} {
+	durationUnits := []string{"ns", "us", "ms", "s", "min", "h", "d", "wk"}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"convert", "duration", "convert_duration"},
+				"description": "convert a timestamp to another zone, measure the duration between two timestamps, or convert a duration between units",
+			},
+			"timestamp": map[string]interface{}{
+				"type":        "string",
+				"description": "Timestamp to convert (convert): RFC 3339 such as 2024-03-10T01:30:00-05:00, or a local time such as 2024-03-10 01:30 read in from_zone",
+			},
+			"start": map[string]interface{}{
+				"type":        "string",
+				"description": "Start timestamp (duration), read in from_zone unless it has an offset",
+			},
+			"end": map[string]interface{}{
+				"type":        "string",
+				"description": "End timestamp (duration), read in to_zone (default from_zone) unless it has an offset",
+			},
+			"from_zone": map[string]interface{}{
+				"type":        "string",
+				"description": "IANA time zone of local timestamps, e.g. America/New_York (defaults to UTC)",
+			},
+			"to_zone": map[string]interface{}{
+				"type":        "string",
+				"description": "IANA time zone to convert to (convert), or of the end timestamp (duration)",
+			},
+			"duration": map[string]interface{}{
+				"type":        "string",
+				"description": "Duration to convert (convert_duration), e.g. 1h30m, 2d12h or 90s",
+			},
+			"value": map[string]interface{}{
+				"type":        "number",
+				"description": "Duration to convert as a number of from_unit (convert_duration)",
+			},
+			"from_unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        durationUnits,
+				"description": "Unit of value (defaults to s)",
+			},
+			"to_unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        durationUnits,
+				"description": "Unit to convert the duration to (defaults to s)",
+			},
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getCookingSchema() map[string]interface{} {
+	kitchenUnits := []string{"ml", "cl", "dl", "l", "fl_oz", "cup", "pt", "qt", "gal", "tsp", "tbsp", "mg", "g", "kg", "oz", "lb"}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"scale", "convert", "oven_temperature"},
+				"description": "scale a recipe, convert a quantity between kitchen units, or convert an oven temperature",
+			},
+			"ingredients": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name":     map[string]interface{}{"type": "string"},
+						"quantity": map[string]interface{}{"type": "number", "minimum": 0},
+						"unit":     map[string]interface{}{"type": "string", "description": "Any unit, e.g. cup, g or eggs"},
+					},
+					"required":             []string{"quantity"},
+					"additionalProperties": false,
+				},
+				"description": "Recipe ingredients to scale (scale)",
+			},
+			"servings": map[string]interface{}{
+				"type":        "number",
+				"description": "Servings the recipe makes (scale)",
+			},
+			"target_servings": map[string]interface{}{
+				"type":        "number",
+				"description": "Servings wanted (scale)",
+			},
+			"factor": map[string]interface{}{
+				"type":        "number",
+				"description": "Scale factor, instead of servings and target_servings (scale)",
+			},
+			"quantity": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Quantity to convert (convert)",
+			},
+			"from_unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        append(append([]string{}, kitchenUnits...), "C", "F", "gas_mark"),
+				"description": "Unit of quantity (convert), or of temperature: C, F or gas_mark (oven_temperature)",
+			},
+			"to_unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        kitchenUnits,
+				"description": "Unit to convert to (convert)",
+			},
+			"ingredient": map[string]interface{}{
+				"type":        "string",
+				"description": "Ingredient whose density converts between volume and weight, e.g. flour, sugar, butter, milk or honey",
+			},
+			"temperature": map[string]interface{}{
+				"type":        "number",
+				"description": "Oven temperature or gas mark to convert (oven_temperature)",
+			},
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getEstimatorSchema() map[string]interface{} {
+	lengthUnits := []string{"mm", "cm", "m", "km", "in", "ft", "yd", "mi", "mil", "μm", "nm"}
+	number := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "number", "description": description}
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"paint", "concrete", "tiles", "wallpaper"},
+				"description": "Material to estimate",
+			},
+			"unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        lengthUnits,
+				"description": "Length unit of the dimensions; areas are in this unit squared (defaults to m)",
+			},
+			"length":    number("Length of the surface, room or wall"),
+			"width":     number("Width of the surface or room; for wallpaper, makes length and width the sides of a room"),
+			"height":    number("Wall height (wallpaper; paint, to cover the four walls of a length × width room)"),
+			"area":      number("Area to cover, instead of length and width (paint, concrete, tiles)"),
+			"openings":  number("Area of doors and windows to leave out (paint, concrete, tiles)"),
+			"thickness": number("Slab thickness (concrete)"),
+			"coats": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Coats of paint (defaults to 2)",
+			},
+			"coverage":      number("Paint coverage in m² per litre per coat (defaults to 10, about 400 ft² per gallon)"),
+			"waste_percent": number("Waste allowance in percent (defaults to 5 for concrete and 10 for tiles)"),
+			"tile_length":   number("Tile length, in tile_unit (tiles)"),
+			"tile_width":    number("Tile width, in tile_unit (tiles)"),
+			"tile_unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        lengthUnits,
+				"description": "Length unit of the tile size (defaults to unit)",
+			},
+			"tiles_per_box": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Tiles per box, to count boxes (tiles)",
+			},
+			"roll_width":     number("Wallpaper roll width (defaults to 0.53 m)"),
+			"roll_length":    number("Wallpaper roll length (defaults to 10.05 m)"),
+			"pattern_repeat": number("Pattern repeat added to each strip (wallpaper)"),
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getAspectRatioSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"reduce", "ppi", "scale"},
+				"description": "reduce to an aspect ratio, ppi from a diagonal, or scale to a target width or height",
+			},
+			"width": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     1000000,
+				"description": "Width in pixels",
+			},
+			"height": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     1000000,
+				"description": "Height in pixels",
+			},
+			"diagonal": map[string]interface{}{
+				"type":             "number",
+				"exclusiveMinimum": 0,
+				"description":      "Screen diagonal (ppi)",
+			},
+			"diagonal_unit": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"in", "cm"},
+				"default":     "in",
+				"description": "Unit of the diagonal",
+			},
+			"target_width": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     1000000,
+				"description": "Width to scale to (scale); give this or target_height",
+			},
+			"target_height": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     1000000,
+				"description": "Height to scale to (scale); give this or target_width",
+			},
+			"multiple_of": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     1024,
+				"default":     1,
+				"description": "Round the computed side to a multiple of this, e.g. 2 for video encoders (scale)",
+			},
+		},
+		"required":             []string{"operation", "width", "height"},
+		"additionalProperties": false,
+	}
+}
+
+func getGradingSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"weighted_grade", "final_needed", "gpa"},
+				"description": "Grade calculation to perform",
+			},
+			"categories": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Category name, e.g. homework",
+						},
+						"score": map[string]interface{}{
+							"type":        "number",
+							"minimum":     0,
+							"description": "Score as a percentage",
+						},
+						"earned": map[string]interface{}{
+							"type":        "number",
+							"minimum":     0,
+							"description": "Points earned, instead of score",
+						},
+						"possible": map[string]interface{}{
+							"type":        "number",
+							"minimum":     0,
+							"description": "Points possible, instead of score",
+						},
+						"weight": map[string]interface{}{
+							"type":        "number",
+							"minimum":     0,
+							"description": "Relative weight of the category",
+						},
+					},
+					"required":             []string{"weight"},
+					"additionalProperties": false,
+				},
+				"maxItems":    1000,
+				"description": "Graded categories (weighted_grade; for final_needed, the categories graded so far)",
+			},
+			"current_grade": map[string]interface{}{
+				"type":        "number",
+				"description": "Current grade percentage before the final (final_needed)",
+			},
+			"final_weight": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"maximum":     100,
+				"description": "Final exam weight as a percentage of the course grade (final_needed)",
+			},
+			"target_grade": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Desired course grade percentage (final_needed)",
+			},
+			"courses": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"grade": map[string]interface{}{
+							"type":        "string",
+							"description": "Letter grade, A+ to F",
+						},
+						"credits": map[string]interface{}{
+							"type":             "number",
+							"exclusiveMinimum": 0,
+							"description":      "Course credits (default 1)",
+						},
+					},
+					"required":             []string{"grade"},
+					"additionalProperties": false,
+				},
+				"maxItems":    1000,
+				"description": "Courses and letter grades (gpa)",
+			},
+			"scale": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"4.0", "4.3"},
+				"description": "GPA scale; 4.3 awards 4.3 for an A+ (default 4.0)",
+			},
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getStatisticsSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"data": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": []string{"number", "null"},
+				},
+				"minItems":    1,
+				"description": "Array of numerical data; null (or \"NaN\") entries are missing points, handled as set by missing",
+			},
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"mean", "median", "mode", "std_dev", "variance", "percentile", "regression"},
+				"description": "Statistical operation to perform. regression fits data = intercept + slope·x",
+			},
+			"weights": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":    "number",
+					"minimum": 0,
+				},
+				"description": "Optional non-negative frequency weights, one per data point, for already-summarized data",
+			},
+			"x": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "number",
+				},
+				"description": "Predictor values for regression, one per data point",
+			},
+			"type": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"sample", "population"},
+				"default":     "sample",
+				"description": "For std_dev and variance: sample divides by n − 1 (STDEV.S, VAR.S), population by n (STDEV.P, VAR.P)",
+			},
+			"missing": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"error", "drop", "mean", "median", "value"},
+				"default":     "error",
+				"description": "How to handle missing data points: fail, drop them, or impute the mean or median of the other points or fill_value",
+			},
+			"fill_value": map[string]interface{}{
+				"type":        "number",
+				"description": "Value imputed for missing points when missing is value",
+			},
+			"deduplicate": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Remove repeated data points (repeated x and data pairs for regression), keeping the first; not with weights",
+			},
+			"clip_min": map[string]interface{}{
+				"type":        "number",
+				"description": "Raise data points below this value to it",
+			},
+			"clip_max": map[string]interface{}{
+				"type":        "number",
+				"description": "Lower data points above this value to it",
+			},
+		},
+		"required":             []string{"data", "operation"},
+		"additionalProperties": false,
+	}
+}
+
+// getStatisticsOutputSchema describes the result of statistics
+func getStatisticsOutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type": "string",
+			},
+			"result": map[string]interface{}{
+				"type":        []string{"number", "string", "array", "object"},
+				"description": "A number; for mode the most frequent values, or a note that there is none; for regression {slope, intercept, r_squared, count, weighted}",
+			},
+			"count": map[string]interface{}{
+				"type":        "integer",
+				"description": "Number of data points used, after cleaning",
+			},
+			"data_preview": map[string]interface{}{
+				"type":        "object",
+				"description": "The count and the first and last few values of the data",
+			},
+			"supported_operations": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			"weighted": map[string]interface{}{
+				"type": "boolean",
+			},
+			"type": map[string]interface{}{
+				"type":        "string",
+				"description": "The variance type used by std_dev and variance",
+			},
+			"cleaning": map[string]interface{}{
+				"type":        "object",
+				"description": "Data points changed by the cleaning options, when any is set",
+			},
+		},
+		"required": []string{"operation", "result", "count"},
+	}
+}
+
+func getUnitConversionSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"value": map[string]interface{}{
+				"type":        "number",
+				"description": "Value to convert",
+			},
+			"fromUnit": map[string]interface{}{
+				"type":        "string",
+				"description": "Source unit",
+			},
+			"toUnit": map[string]interface{}{
+				"type":        "string",
+				"description": "Target unit",
+			},
+			"category": map[string]interface{}{
+				"type":        "string",
+				"enum":        calculator.DefaultConversionRegistry().Categories(),
+				"description": "Category of measurement",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"plain", "engineering"},
+				"default":     "plain",
+				"description": "Also render the result in engineering notation with an SI prefix (e.g. 0.047 km → 47 m)",
+			},
+		},
+		"required":             []string{"value", "fromUnit", "toUnit", "category"},
+		"additionalProperties": false,
+	}
+}
+
+func getFinancialSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"compound_interest", "simple_interest", "loan_payment", "roi", "present_value", "future_value", "solve_tvm"},
+				"description": "Financial operation to perform. solve_tvm solves for whichever of principal, rate, time, payment and futureValue is omitted",
+			},
+			"principal": map[string]interface{}{
+				"type":        "number",
+				"description": "Principal amount or initial investment (signed for solve_tvm: received positive, paid out negative)",
+			},
+			"rate": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Annual interest rate (as percentage)",
+			},
+			"time": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Time period in years",
+			},
+			"periods": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Number of compounding periods per year",
+			},
+			"payment": map[string]interface{}{
+				"type":        "number",
+				"description": "Payment per period for solve_tvm (received positive, paid out negative)",
+			},
+			"futureValue": map[string]interface{}{
+				"type":        "number",
+				"description": "Future value (for ROI and present value calculations; signed for solve_tvm)",
+			},
+			"startDate": map[string]interface{}{
+				"type":        "string",
+				"format":      "date",
+				"description": "Accrual start date (YYYY-MM-DD); with endDate, replaces time for interest, loan, present and future value operations",
+			},
+			"endDate": map[string]interface{}{
+				"type":        "string",
+				"format":      "date",
+				"description": "Accrual end date (YYYY-MM-DD)",
+			},
+			"dayCount": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"actual/360", "actual/365", "30/360"},
+				"description": "Day-count convention turning the dates into years (default actual/365)",
+			},
+			"currency": map[string]interface{}{
+				"type":        "string",
+				"pattern":     "^[A-Za-z]{3}$",
+				"description": "ISO 4217 code of the amounts (e.g. USD); monetary results are also returned formatted",
+			},
+			"reportingCurrency": map[string]interface{}{
+				"type":        "string",
+				"pattern":     "^[A-Za-z]{3}$",
+				"description": "ISO 4217 code to convert monetary results into; requires currency and a configured rate source",
+			},
+			"explain": map[string]interface{}{
+				"type":        "boolean",
+				"default":     false,
+				"description": "Include the formula, substituted values and intermediate results",
+			},
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+// Additional schema definitions
+// getFinancialOutputSchema describes the result of financial
+func getFinancialOutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type": "string",
+			},
+			"result": map[string]interface{}{
+				"type":        "number",
+				"description": "The computed amount, rate, time or ROI percentage",
+			},
+			"breakdown": map[string]interface{}{
+				"type":        "object",
+				"description": "Intermediate values of the calculation",
+			},
+			"description": map[string]interface{}{
+				"type": "string",
+			},
+			"supported_operations": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			"steps": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			"currency": map[string]interface{}{
+				"type": "string",
+			},
+			"formatted": map[string]interface{}{
+				"type":        "string",
+				"description": "The monetary result formatted in its currency",
+			},
+			"converted": map[string]interface{}{
+				"type":        "object",
+				"description": "The result in the reporting currency: {currency, amount, rate, formatted}",
+			},
+		},
+		"required": []string{"operation", "result"},
+	}
+}
+
+// getScheduleOutputSchema describes the result of amortization_schedule
+func getScheduleOutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"description":    map[string]interface{}{"type": "string"},
+			"payments":       map[string]interface{}{"type": "integer"},
+			"payment":        map[string]interface{}{"type": "number"},
+			"total_paid":     map[string]interface{}{"type": "number"},
+			"total_interest": map[string]interface{}{"type": "number"},
+			"schedule": map[string]interface{}{
+				"type":        "array",
+				"description": "One row per payment; omitted when the rows were streamed as partial results",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"period":    map[string]interface{}{"type": "integer"},
+						"payment":   map[string]interface{}{"type": "number"},
+						"principal": map[string]interface{}{"type": "number"},
+						"interest":  map[string]interface{}{"type": "number"},
+						"balance":   map[string]interface{}{"type": "number"},
+					},
+				},
+			},
+			"streamed": map[string]interface{}{"type": "boolean"},
+		},
+		"required": []string{"description", "payments", "payment", "total_paid", "total_interest"},
+	}
+}
+
+// getSeriesOutputSchema describes the result of batch_conversion
+func getSeriesOutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"original_values": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "number"},
+			},
+			"converted_values": map[string]interface{}{
+				"type":        "array",
+				"description": "One value per original value, in the same order",
+				"items":       map[string]interface{}{"type": "number"},
+			},
+			"from_unit": map[string]interface{}{"type": "string"},
+			"to_unit":   map[string]interface{}{"type": "string"},
+			"category":  map[string]interface{}{"type": "string"},
+			"count":     map[string]interface{}{"type": "integer"},
+		},
+		"required": []string{"original_values", "converted_values", "count"},
+	}
+}
+
+// getTableOutputSchema describes the result of loan_comparison and
+// investment_scenarios
+func getTableOutputSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"description": map[string]interface{}{"type": "string"},
+			"rows": map[string]interface{}{
+				"type":        "array",
+				"description": "One row per option, in the order given",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"index":     map[string]interface{}{"type": "integer"},
+						"value":     map[string]interface{}{"type": "number", "description": "The payment of a loan or the final amount of an investment"},
+						"breakdown": map[string]interface{}{"type": "object"},
+						"error":     map[string]interface{}{"type": "string", "description": "Why the option could not be calculated"},
+					},
+					"required": []string{"index"},
+				},
+			},
+			"best_index": map[string]interface{}{"type": "integer", "description": "The row with the lowest payment or highest final amount; -1 when none could be calculated"},
+			"best_value": map[string]interface{}{"type": "number"},
+		},
+		"required": []string{"description", "rows", "best_index"},
 	}
 }
 
-// Additional schema definitions
 func getStatsSummarySchema() map[string]interface{} {
 	return map[string]interface{}{
 		"type": "object",
@@ -417,8 +2244,17 @@ func getStatsSummarySchema() map[string]interface{} {
 				"minItems":    1,
 				"description": "Array of numerical data for summary statistics",
 			},
+			"weights": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":    "number",
+					"minimum": 0,
+				},
+				"description": "Optional non-negative frequency weights, one per data point, for already-summarized data",
+			},
 		},
-		"required": []string{"data"},
+		"required":             []string{"data"},
+		"additionalProperties": false,
 	}
 }
 
@@ -440,8 +2276,141 @@ func getPercentileSchema() map[string]interface{} {
 				"maximum":     100,
 				"description": "Percentile to calculate (0-100)",
 			},
+			"method": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"exact", "p2"},
+				"default":     "exact",
+				"description": "exact selects the data point in linear time; p2 estimates it in one pass and constant memory (unweighted data only; the result is flagged exact: false)",
+			},
+			"weights": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":    "number",
+					"minimum": 0,
+				},
+				"description": "Optional non-negative frequency weights, one per data point, for already-summarized data",
+			},
+		},
+		"required":             []string{"data", "percentile"},
+		"additionalProperties": false,
+	}
+}
+
+func getForecastSchema() map[string]interface{} {
+	smoothing := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "number",
+			"minimum":     0,
+			"maximum":     1,
+			"description": description + " (holt_winters; fitted to the series when omitted)",
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"series": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "number",
+				},
+				"minItems":    2,
+				"maxItems":    10000,
+				"description": "Observations in time order, oldest first",
+			},
+			"method": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"naive", "moving_average", "holt_winters"},
+				"description": "naive repeats the last value (or last season), moving_average projects the mean of the last window values, holt_winters applies exponential smoothing with trend and optional seasonality",
+			},
+			"horizon": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     1000,
+				"description": "Number of steps to forecast",
+			},
+			"window": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Values averaged by moving_average (default 3)",
+			},
+			"season_length": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Observations per season, e.g. 12 for monthly data with a yearly cycle; makes naive seasonal and adds seasonality to holt_winters",
+			},
+			"alpha": smoothing("Level smoothing"),
+			"beta":  smoothing("Trend smoothing"),
+			"gamma": smoothing("Seasonal smoothing"),
+			"confidence": map[string]interface{}{
+				"type":             "number",
+				"exclusiveMinimum": 0,
+				"exclusiveMaximum": 100,
+				"description":      "Prediction interval confidence in percent (default 95)",
+			},
+		},
+		"required":             []string{"series", "method", "horizon"},
+		"additionalProperties": false,
+	}
+}
+
+func getProbabilitySchema() map[string]interface{} {
+	probability := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "number",
+			"minimum":     0,
+			"maximum":     1,
+			"description": description,
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"independent_events", "conditional", "binomial", "birthday", "expected_value"},
+				"description": "Probability scenario to evaluate",
+			},
+			"probability_a":         probability("P(A) (independent_events, conditional)"),
+			"probability_b":         probability("P(B) (independent_events, conditional)"),
+			"probability_b_given_a": probability("P(B|A) (conditional)"),
+			"trials": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Number of independent trials (binomial)",
+			},
+			"successes": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Minimum number of successes k; the result is P(at least k) (binomial)",
+			},
+			"probability": probability("Probability of success in each trial (binomial)"),
+			"people": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Group size (birthday)",
+			},
+			"days": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Equally likely days or categories (birthday, default 365)",
+			},
+			"outcomes": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "number"},
+				"minItems":    1,
+				"maxItems":    10000,
+				"description": "Values of a discrete random variable (expected_value)",
+			},
+			"probabilities": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+				"description": "Probability of each outcome, summing to 1 (expected_value)",
+			},
 		},
-		"required": []string{"data", "percentile"},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
 	}
 }
 
@@ -467,11 +2436,12 @@ func getBatchConversionSchema() map[string]interface{} {
 			},
 			"category": map[string]interface{}{
 				"type":        "string",
-				"enum":        []string{"length", "weight", "temperature", "volume", "area"},
+				"enum":        calculator.DefaultConversionRegistry().Categories(),
 				"description": "Category of measurement",
 			},
 		},
-		"required": []string{"values", "fromUnit", "toUnit", "category"},
+		"required":             []string{"values", "fromUnit", "toUnit", "category"},
+		"additionalProperties": false,
 	}
 }
 
@@ -493,7 +2463,8 @@ func getNPVSchema() map[string]interface{} {
 				"description": "Discount rate as percentage",
 			},
 		},
-		"required": []string{"cashFlows", "discountRate"},
+		"required":             []string{"cashFlows", "discountRate"},
+		"additionalProperties": false,
 	}
 }
 
@@ -510,7 +2481,8 @@ func getIRRSchema() map[string]interface{} {
 				"description": "Array of cash flows (negative for outflows, positive for inflows)",
 			},
 		},
-		"required": []string{"cashFlows"},
+		"required":             []string{"cashFlows"},
+		"additionalProperties": false,
 	}
 }
 
@@ -542,7 +2514,8 @@ func getLoanComparisonSchema() map[string]interface{} {
 				"description": "Array of loan scenarios to compare",
 			},
 		},
-		"required": []string{"loans"},
+		"required":             []string{"loans"},
+		"additionalProperties": false,
 	}
 }
 
@@ -574,6 +2547,265 @@ func getInvestmentScenariosSchema() map[string]interface{} {
 				"description": "Array of investment scenarios to compare",
 			},
 		},
-		"required": []string{"scenarios"},
+		"required":             []string{"scenarios"},
+		"additionalProperties": false,
+	}
+}
+
+func getAmortizationScheduleSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"principal": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Loan amount",
+			},
+			"rate": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Annual interest rate (as percentage)",
+			},
+			"time": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Loan term in years",
+			},
+			"periods": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Payments per year (defaults to 12)",
+			},
+		},
+		"required":             []string{"principal", "rate", "time"},
+		"additionalProperties": false,
+	}
+}
+
+func getAllocateSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"total": map[string]interface{}{
+				"type":        "number",
+				"description": "Amount to split; negative amounts such as refunds are split the same way",
+			},
+			"shares": map[string]interface{}{
+				"type":        "array",
+				"minItems":    1,
+				"maxItems":    10000,
+				"description": "Parties to allocate to, all given by weight or all by percentage",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Optional label for the party",
+						},
+						"weight": map[string]interface{}{
+							"type":        "number",
+							"minimum":     0,
+							"description": "Relative weight of the party's share",
+						},
+						"percentage": map[string]interface{}{
+							"type":        "number",
+							"minimum":     0,
+							"maximum":     100,
+							"description": "Percentage of the total; percentages must add up to 100",
+						},
+					},
+					"additionalProperties": false,
+				},
+			},
+			"decimals": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"maximum":     8,
+				"description": "Decimal places of the smallest unit (defaults to 2)",
+			},
+			"currency": map[string]interface{}{
+				"type":        "string",
+				"description": "ISO 4217 code; rounds to the currency's minor unit and formats each part",
+			},
+		},
+		"required":             []string{"total", "shares"},
+		"additionalProperties": false,
+	}
+}
+
+func getRuleOfThumbSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"rule_of_72", "budget_50_30_20", "debt_to_income", "emergency_fund"},
+				"description": "Rule of thumb to apply",
+			},
+			"rate": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Annual return as percentage (rule_of_72)",
+			},
+			"income": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Monthly income: take-home for budget_50_30_20, gross for debt_to_income",
+			},
+			"debtPayments": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Total monthly debt payments (debt_to_income)",
+			},
+			"expenses": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Monthly essential expenses (emergency_fund; optional for budget_50_30_20)",
+			},
+			"savings": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Current savings (emergency_fund)",
+			},
+			"months": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Months of expenses to hold (emergency_fund, default 6)",
+			},
+		},
+		"required":             []string{"operation"},
+		"additionalProperties": false,
+	}
+}
+
+func getSaveFormulaSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":        "string",
+				"pattern":     "^[a-zA-Z][a-zA-Z0-9_-]{0,63}$",
+				"description": "Name to save the formula under, e.g. bmi",
+			},
+			"expression": map[string]interface{}{
+				"type":        "string",
+				"description": "Expression in expression_eval syntax, e.g. weight / pow(height, 2)",
+			},
+			"variables": map[string]interface{}{
+				"type":        "object",
+				"description": "Default variable values, overridable when the formula is run",
+				"patternProperties": map[string]interface{}{
+					"^[a-zA-Z][a-zA-Z0-9_]*$": map[string]interface{}{
+						"type": "number",
+					},
+				},
+			},
+			"description": map[string]interface{}{
+				"type":        "string",
+				"description": "What the formula computes",
+			},
+			"scope": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"session", "persistent"},
+				"default":     "session",
+				"description": "Keep the formula for this session only, or persistently (requires storage)",
+			},
+		},
+		"required":             []string{"name", "expression"},
+		"additionalProperties": false,
+	}
+}
+
+func getRunFormulaSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of a saved formula",
+			},
+			"variables": map[string]interface{}{
+				"type":        "object",
+				"description": "Variable values overriding the formula's defaults",
+				"patternProperties": map[string]interface{}{
+					"^[a-zA-Z][a-zA-Z0-9_]*$": map[string]interface{}{
+						"type": "number",
+					},
+				},
+			},
+		},
+		"required":             []string{"name"},
+		"additionalProperties": false,
+	}
+}
+
+func getSelfCheckSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"tools": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "string",
+				},
+				"description": "Tools to check (default: every available tool)",
+			},
+		},
+		"additionalProperties": false,
+	}
+}
+
+func getExportSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"source": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"history", "amortization", "histogram"},
+				"description": "What to export: the session's calculation history or a generated table",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"csv", "json"},
+				"default":     "csv",
+				"description": "Document format",
+			},
+			"principal": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Loan amount (amortization)",
+			},
+			"rate": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Annual interest rate as percentage (amortization)",
+			},
+			"time": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"description": "Loan term in years (amortization)",
+			},
+			"periods": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Payments per year, defaults to 12 (amortization)",
+			},
+			"data": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "number",
+				},
+				"minItems":    1,
+				"description": "Data set to bin (histogram)",
+			},
+			"bins": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     calculator.MaxHistogramBins,
+				"description": "Number of equal-width bins, defaults to 10 (histogram)",
+			},
+		},
+		"required":             []string{"source"},
+		"additionalProperties": false,
 	}
 }
@@ -0,0 +1,175 @@
+//go:build js && wasm
+
+/*
+Copyright 2025
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command wasm exposes the calculator engine to JavaScript so web MCP
+// clients can run simple calculations locally. It registers a global
+// "calculator" object whose functions return plain objects: the result on
+// success or {error: {code, message}} on failure.
+package main
+
+import (
+	"errors"
+	"syscall/js"
+
+	"calculator-server/pkg/calculator"
+)
+
+// localTools are the MCP tools this build can evaluate without the server
+var localTools = []string{"basic_math", "advanced_math", "expression_eval", "statistics", "unit_conversion"}
+
+func main() {
+	js.Global().Set("calculator", js.ValueOf(map[string]interface{}{
+		"basic":      js.FuncOf(basic),
+		"advanced":   js.FuncOf(advanced),
+		"evaluate":   js.FuncOf(evaluate),
+		"statistic":  js.FuncOf(statistic),
+		"convert":    js.FuncOf(convert),
+		"localTools": toJSArray(localTools),
+	}))
+
+	// Keep the Go runtime alive so the callbacks stay valid
+	select {}
+}
+
+// basic(operation, operands, precision?)
+func basic(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return argumentError("basic(operation, operands, precision?)")
+	}
+	precision := 2
+	if len(args) > 2 && args[2].Type() == js.TypeNumber {
+		precision = args[2].Int()
+	}
+
+	result, err := calculator.Basic(args[0].String(), toFloats(args[1]), precision)
+	if err != nil {
+		return errorObject(err)
+	}
+	return resultObject(result)
+}
+
+// advanced(function, value, {exponent, unit}?)
+func advanced(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return argumentError("advanced(function, value, options?)")
+	}
+	var opts calculator.AdvancedOptions
+	if len(args) > 2 && args[2].Type() == js.TypeObject {
+		if exponent := args[2].Get("exponent"); exponent.Type() == js.TypeNumber {
+			opts.Exponent = exponent.Float()
+		}
+		if unit := args[2].Get("unit"); unit.Type() == js.TypeString {
+			opts.Unit = unit.String()
+		}
+	}
+
+	result, err := calculator.Advanced(args[0].String(), args[1].Float(), opts)
+	if err != nil {
+		return errorObject(err)
+	}
+	return resultObject(result)
+}
+
+// evaluate(expression, variables?)
+func evaluate(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return argumentError("evaluate(expression, variables?)")
+	}
+	variables := make(map[string]float64)
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		keys := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			name := keys.Index(i).String()
+			variables[name] = args[1].Get(name).Float()
+		}
+	}
+
+	value, err := calculator.Evaluate(args[0].String(), variables)
+	if err != nil {
+		return errorObject(err)
+	}
+	return map[string]interface{}{"value": value}
+}
+
+// statistic(operation, data)
+func statistic(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return argumentError("statistic(operation, data)")
+	}
+
+	value, err := calculator.Statistic(args[0].String(), toFloats(args[1]))
+	if err != nil {
+		return errorObject(err)
+	}
+	if percentiles, ok := value.(map[string]float64); ok {
+		object := make(map[string]interface{}, len(percentiles))
+		for key, percentile := range percentiles {
+			object[key] = percentile
+		}
+		return map[string]interface{}{"value": object}
+	}
+	return map[string]interface{}{"value": value}
+}
+
+// convert(value, fromUnit, toUnit, category)
+func convert(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 4 {
+		return argumentError("convert(value, fromUnit, toUnit, category)")
+	}
+
+	value, err := calculator.Convert(args[0].Float(), args[1].String(), args[2].String(), args[3].String())
+	if err != nil {
+		return errorObject(err)
+	}
+	return map[string]interface{}{"value": value}
+}
+
+func toFloats(array js.Value) []float64 {
+	if array.Type() != js.TypeObject {
+		return nil
+	}
+	values := make([]float64, array.Length())
+	for i := range values {
+		values[i] = array.Index(i).Float()
+	}
+	return values
+}
+
+func toJSArray(values []string) []interface{} {
+	array := make([]interface{}, len(values))
+	for i, value := range values {
+		array[i] = value
+	}
+	return array
+}
+
+func resultObject(result calculator.Result) map[string]interface{} {
+	object := map[string]interface{}{"value": result.Value}
+	if result.Unit != "" {
+		object["unit"] = result.Unit
+	}
+	if result.Semantics != "" {
+		object["semantics"] = result.Semantics
+	}
+	return object
+}
+
+func errorObject(err error) map[string]interface{} {
+	detail := map[string]interface{}{"message": err.Error()}
+	var calcErr *calculator.CalculationError
+	if errors.As(err, &calcErr) {
+		detail["code"] = calcErr.Code
+		if calcErr.Field != "" {
+			detail["field"] = calcErr.Field
+		}
+	}
+	return map[string]interface{}{"error": detail}
+}
+
+func argumentError(usage string) map[string]interface{} {
+	return map[string]interface{}{"error": map[string]interface{}{"message": "usage: calculator." + usage}}
+}
@@ -32,7 +32,8 @@ func (ac *AdvancedCalculator) Calculate(req types.AdvancedMathRequest) (types.Ca
 		result = math.Tan(value)
 	case "asin":
 		if value < -1 || value > 1 {
-			return types.CalculationResult{}, fmt.Errorf("asin domain error: value must be between -1 and 1")
+			return types.CalculationResult{}, types.NewCalculationError(types.ErrCodeInverseTrigDomain, value,
+				"asin domain error: value must be between -1 and 1, got %g", value).WithField("value")
 		}
 		result = math.Asin(value)
 		if req.Unit == "degrees" {
@@ -40,7 +41,8 @@ func (ac *AdvancedCalculator) Calculate(req types.AdvancedMathRequest) (types.Ca
 		}
 	case "acos":
 		if value < -1 || value > 1 {
-			return types.CalculationResult{}, fmt.Errorf("acos domain error: value must be between -1 and 1")
+			return types.CalculationResult{}, types.NewCalculationError(types.ErrCodeInverseTrigDomain, value,
+				"acos domain error: value must be between -1 and 1, got %g", value).WithField("value")
 		}
 		result = math.Acos(value)
 		if req.Unit == "degrees" {
@@ -53,22 +55,26 @@ func (ac *AdvancedCalculator) Calculate(req types.AdvancedMathRequest) (types.Ca
 		}
 	case "log":
 		if value <= 0 {
-			return types.CalculationResult{}, fmt.Errorf("logarithm domain error: value must be positive")
+			return types.CalculationResult{}, types.NewCalculationError(types.ErrCodeLogDomain, value,
+				"logarithm domain error: value must be positive, got %g", value).WithField("value")
 		}
 		result = math.Log10(value)
 	case "log10":
 		if value <= 0 {
-			return types.CalculationResult{}, fmt.Errorf("log10 domain error: value must be positive")
+			return types.CalculationResult{}, types.NewCalculationError(types.ErrCodeLogDomain, value,
+				"log10 domain error: value must be positive, got %g", value).WithField("value")
 		}
 		result = math.Log10(value)
 	case "ln":
 		if value <= 0 {
-			return types.CalculationResult{}, fmt.Errorf("natural logarithm domain error: value must be positive")
+			return types.CalculationResult{}, types.NewCalculationError(types.ErrCodeLogDomain, value,
+				"natural logarithm domain error: value must be positive, got %g", value).WithField("value")
 		}
 		result = math.Log(value)
 	case "sqrt":
 		if value < 0 {
-			return types.CalculationResult{}, fmt.Errorf("square root domain error: value must be non-negative")
+			return types.CalculationResult{}, types.NewCalculationError(types.ErrCodeSqrtDomain, value,
+				"square root domain error: value must be non-negative, got %g", value).WithField("value")
 		}
 		result = math.Sqrt(value)
 	case "abs":
@@ -120,7 +126,8 @@ func (ac *AdvancedCalculator) Calculate(req types.AdvancedMathRequest) (types.Ca
 // Power function with two parameters
 func (ac *AdvancedCalculator) Power(base, exponent float64) (float64, error) {
 	if base == 0 && exponent < 0 {
-		return 0, fmt.Errorf("division by zero: 0 raised to negative power")
+		return 0, types.NewCalculationError(types.ErrCodeDivisionByZero, exponent,
+			"division by zero: 0 raised to negative power %g", exponent).WithField("exponent")
 	}
 	if base < 0 && exponent != math.Floor(exponent) {
 		return 0, fmt.Errorf("complex result: negative base with non-integer exponent")
@@ -171,6 +178,18 @@ func (ac *AdvancedCalculator) isTrigFunction(function string) bool {
 	return false
 }
 
+// Validate checks the function, value and unit of a request, as the
+// advanced_math tool and the library both do before calculating
+func (ac *AdvancedCalculator) Validate(req types.AdvancedMathRequest) error {
+	if err := ac.ValidateFunction(req.Function); err != nil {
+		return err
+	}
+	if err := ac.ValidateValue(req.Value); err != nil {
+		return err
+	}
+	return ac.ValidateUnit(req.Unit)
+}
+
 // Validation functions
 func (ac *AdvancedCalculator) ValidateFunction(function string) error {
 	validFunctions := []string{
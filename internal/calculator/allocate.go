@@ -0,0 +1,166 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"calculator-server/internal/currency"
+	"calculator-server/internal/types"
+)
+
+const (
+	maxAllocationShares       = 10000
+	maxAllocationDecimals     = 8
+	defaultAllocationDecimals = 2
+
+	// maxAllocationUnits keeps the total within the 15 significant digits
+	// that round-trip exactly through float64
+	maxAllocationUnits = 1e15
+
+	// quotaPrecision is the number of decimal places kept when dividing
+	// the total into exact proportional quotas
+	quotaPrecision = 24
+
+	// percentageSumSlack is the rounding allowed per share when checking
+	// that percentages add up to 100
+	percentageSumSlack = 1e-7
+)
+
+// Allocate splits a total across shares in proportion to their weights or
+// percentages. Each part is rounded down to the smallest unit and the units
+// left over go to the parts with the largest remainders (ties to the earlier
+// share), so the parts always sum exactly to the total.
+func (fc *FinancialCalculator) Allocate(req types.AllocationRequest) (types.AllocationResult, error) {
+	decimals, code := defaultAllocationDecimals, ""
+	if req.Currency != "" {
+		if req.Decimals != nil {
+			return types.AllocationResult{}, fmt.Errorf("give either decimals or currency, not both; a currency rounds to its minor unit")
+		}
+		normalized, err := currency.Normalize(req.Currency)
+		if err != nil {
+			return types.AllocationResult{}, err
+		}
+		code, decimals = normalized, currency.Decimals(normalized)
+	}
+	if req.Decimals != nil {
+		decimals = *req.Decimals
+	}
+	if decimals < 0 || decimals > maxAllocationDecimals {
+		return types.AllocationResult{}, fmt.Errorf("decimals must be between 0 and %d, got %d", maxAllocationDecimals, decimals)
+	}
+	if err := requireFinite("total", req.Total); err != nil {
+		return types.AllocationResult{}, err
+	}
+	if len(req.Shares) == 0 {
+		return types.AllocationResult{}, fmt.Errorf("at least one share is required")
+	}
+	if len(req.Shares) > maxAllocationShares {
+		return types.AllocationResult{}, fmt.Errorf("too many shares: %d (maximum %d)", len(req.Shares), maxAllocationShares)
+	}
+
+	weights, err := allocationWeights(req.Shares)
+	if err != nil {
+		return types.AllocationResult{}, err
+	}
+
+	total := decimal.NewFromFloat(req.Total)
+	if !total.Equal(total.Round(int32(decimals))) {
+		return types.AllocationResult{}, fmt.Errorf("total %g has more than %d decimal places", req.Total, decimals)
+	}
+	units := total.Shift(int32(decimals)).Abs()
+	if units.GreaterThanOrEqual(decimal.NewFromFloat(maxAllocationUnits)) {
+		return types.AllocationResult{}, fmt.Errorf("total %g has too many significant digits to allocate exactly", req.Total)
+	}
+	sign := decimal.NewFromInt(int64(total.Sign()))
+
+	weightSum := decimal.Zero
+	for _, weight := range weights {
+		weightSum = weightSum.Add(weight)
+	}
+
+	// Round every quota down, then hand out the leftover units
+	quotas := make([]decimal.Decimal, len(weights))
+	floors := make([]decimal.Decimal, len(weights))
+	remainders := make([]decimal.Decimal, len(weights))
+	order := make([]int, len(weights))
+	allocated := decimal.Zero
+	for i, weight := range weights {
+		quotas[i] = units.Mul(weight).DivRound(weightSum, quotaPrecision)
+		floors[i] = quotas[i].Floor()
+		remainders[i] = quotas[i].Sub(floors[i])
+		order[i] = i
+		allocated = allocated.Add(floors[i])
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]].GreaterThan(remainders[order[b]])
+	})
+	leftover := int(units.Sub(allocated).IntPart())
+	for _, i := range order[:leftover] {
+		floors[i] = floors[i].Add(decimal.NewFromInt(1))
+	}
+
+	result := types.AllocationResult{
+		Total:     req.Total,
+		Currency:  code,
+		Unit:      math.Pow10(-decimals),
+		Parts:     make([]types.AllocationPart, len(weights)),
+		RoundedUp: leftover,
+	}
+	for i, share := range req.Shares {
+		amount, _ := floors[i].Shift(-int32(decimals)).Mul(sign).Float64()
+		exact, _ := quotas[i].Shift(-int32(decimals)).Mul(sign).Float64()
+		percent, _ := weights[i].Div(weightSum).Shift(2).Float64()
+		part := types.AllocationPart{Name: share.Name, Share: percent, Exact: exact, Amount: amount}
+		if code != "" {
+			part.Formatted = currency.Format(amount, code)
+		}
+		result.Parts[i] = part
+	}
+	return result, nil
+}
+
+// allocationWeights validates the shares, which must all give a weight or
+// all give a percentage, with percentages adding up to 100
+func allocationWeights(shares []types.AllocationShare) ([]decimal.Decimal, error) {
+	usePercentages, kind := shares[0].Percentage != nil, "weight"
+	if usePercentages {
+		kind = "percentage"
+	}
+	weights := make([]decimal.Decimal, len(shares))
+	sum := 0.0
+	for i, share := range shares {
+		name := share.Name
+		if name == "" {
+			name = fmt.Sprintf("share %d", i)
+		}
+		value := share.Weight
+		if usePercentages {
+			value = share.Percentage
+		}
+		if share.Weight != nil && share.Percentage != nil {
+			return nil, fmt.Errorf("%s gives both a weight and a percentage", name)
+		}
+		if value == nil {
+			return nil, fmt.Errorf("%s must give a %s like the other shares", name, kind)
+		}
+		if err := requireFinite(name, *value); err != nil {
+			return nil, err
+		}
+		if *value < 0 {
+			return nil, fmt.Errorf("%s cannot be negative", name)
+		}
+		weights[i] = decimal.NewFromFloat(*value)
+		sum += *value
+	}
+
+	if sum == 0 {
+		return nil, fmt.Errorf("shares must not all be zero")
+	}
+	if usePercentages && math.Abs(sum-100) > percentageSumSlack*float64(len(shares)) {
+		return nil, fmt.Errorf("percentages must add up to 100, got %g", sum)
+	}
+	return weights, nil
+}
@@ -0,0 +1,218 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// maxResolution bounds each side of a resolution in pixels
+	maxResolution = 1000000
+
+	// maxDiagonal bounds a screen diagonal, in inches
+	maxDiagonal = 10000
+
+	cmPerInch = 2.54
+)
+
+// standardRatio is a commonly named aspect ratio, landscape
+type standardRatio struct {
+	width, height int64
+	name          string
+}
+
+// standardRatios are the named ratios results are matched against. The
+// marketing "21:9" is listed at its usual 64:27 (2560×1080) proportions.
+var standardRatios = []standardRatio{
+	{1, 1, "1:1"},
+	{5, 4, "5:4"},
+	{4, 3, "4:3"},
+	{3, 2, "3:2"},
+	{16, 10, "16:10"},
+	{5, 3, "5:3"},
+	{16, 9, "16:9"},
+	{256, 135, "17:9"},
+	{2, 1, "2:1"},
+	{64, 27, "21:9"},
+	{32, 9, "32:9"},
+}
+
+// AspectRatioCalculator reduces resolutions to aspect ratios, works out
+// pixel densities and scales resolutions while keeping their ratio
+type AspectRatioCalculator struct{}
+
+func NewAspectRatioCalculator() *AspectRatioCalculator {
+	return &AspectRatioCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (ac *AspectRatioCalculator) GetSupportedOperations() []string {
+	return []string{"reduce", "ppi", "scale"}
+}
+
+// Calculate performs the requested operation
+func (ac *AspectRatioCalculator) Calculate(req types.AspectRatioRequest) (types.AspectRatioResult, error) {
+	if err := requireResolution("width", req.Width); err != nil {
+		return types.AspectRatioResult{}, err
+	}
+	if err := requireResolution("height", req.Height); err != nil {
+		return types.AspectRatioResult{}, err
+	}
+
+	switch req.Operation {
+	case "reduce":
+		return ratioResult(req.Operation, req.Width, req.Height), nil
+	case "ppi":
+		return ac.pixelDensity(req)
+	case "scale":
+		return ac.scale(req)
+	default:
+		return types.AspectRatioResult{}, fmt.Errorf("unsupported aspect ratio operation: %s. Supported operations: %v", req.Operation, ac.GetSupportedOperations())
+	}
+}
+
+// pixelDensity works out the pixels per inch of a screen from its
+// resolution and diagonal, and its physical size
+func (ac *AspectRatioCalculator) pixelDensity(req types.AspectRatioRequest) (types.AspectRatioResult, error) {
+	if req.Diagonal == nil {
+		return types.AspectRatioResult{}, fmt.Errorf("ppi requires a diagonal")
+	}
+	inches := *req.Diagonal
+	switch req.DiagonalUnit {
+	case "", "in":
+	case "cm":
+		inches /= cmPerInch
+	default:
+		return types.AspectRatioResult{}, fmt.Errorf("unsupported diagonal_unit: %s. Supported units: [in cm]", req.DiagonalUnit)
+	}
+	if err := requirePositive("diagonal", inches); err != nil {
+		return types.AspectRatioResult{}, err
+	}
+	if inches > maxDiagonal {
+		return types.AspectRatioResult{}, fmt.Errorf("diagonal must be at most %d inches, got %g", maxDiagonal, inches)
+	}
+
+	ppi := math.Hypot(float64(req.Width), float64(req.Height)) / inches
+	dotPitch := 25.4 / ppi
+	widthIn, heightIn := float64(req.Width)/ppi, float64(req.Height)/ppi
+	widthCm, heightCm := widthIn*cmPerInch, heightIn*cmPerInch
+
+	result := ratioResult(req.Operation, req.Width, req.Height)
+	result.PPI, result.DotPitchMm = &ppi, &dotPitch
+	result.WidthIn, result.HeightIn = &widthIn, &heightIn
+	result.WidthCm, result.HeightCm = &widthCm, &heightCm
+	return result, nil
+}
+
+// scale resizes a resolution to a target width or height, keeping its
+// ratio. Sides are rounded to the nearest multiple of MultipleOf, as video
+// encoders often need even or block-aligned sizes.
+func (ac *AspectRatioCalculator) scale(req types.AspectRatioRequest) (types.AspectRatioResult, error) {
+	if (req.TargetWidth == 0) == (req.TargetHeight == 0) {
+		return types.AspectRatioResult{}, fmt.Errorf("scale requires exactly one of target_width and target_height")
+	}
+	multiple := req.MultipleOf
+	if multiple == 0 {
+		multiple = 1
+	}
+	if multiple < 1 || multiple > 1024 {
+		return types.AspectRatioResult{}, fmt.Errorf("multiple_of must be between 1 and 1024, got %d", multiple)
+	}
+
+	width, height := req.TargetWidth, req.TargetHeight
+	var scaled float64
+	if width != 0 {
+		if err := requireResolution("target_width", width); err != nil {
+			return types.AspectRatioResult{}, err
+		}
+		scaled = float64(width) * float64(req.Height) / float64(req.Width)
+		height = roundToMultiple(scaled, multiple)
+	} else {
+		if err := requireResolution("target_height", height); err != nil {
+			return types.AspectRatioResult{}, err
+		}
+		scaled = float64(height) * float64(req.Width) / float64(req.Height)
+		width = roundToMultiple(scaled, multiple)
+	}
+	if width > maxResolution || height > maxResolution {
+		return types.AspectRatioResult{}, fmt.Errorf("the scaled resolution %d×%d exceeds %d pixels a side", width, height, maxResolution)
+	}
+
+	result := ratioResult(req.Operation, width, height)
+	source := ratioResult(req.Operation, req.Width, req.Height)
+	preserved := result.Ratio == source.Ratio
+	result.SourceRatio, result.Exact = source.Ratio, &preserved
+	return result, nil
+}
+
+// ratioResult reduces width×height by their greatest common divisor and
+// matches it against the standard ratios
+func ratioResult(operation string, width, height int64) types.AspectRatioResult {
+	divisor := gcd(width, height)
+	decimal := float64(width) / float64(height)
+	result := types.AspectRatioResult{
+		Operation:   operation,
+		Width:       width,
+		Height:      height,
+		Ratio:       fmt.Sprintf("%d:%d", width/divisor, height/divisor),
+		RatioWidth:  width / divisor,
+		RatioHeight: height / divisor,
+		Decimal:     decimal,
+		GCD:         divisor,
+		Pixels:      width * height,
+		Megapixels:  float64(width*height) / 1e6,
+		Orientation: "landscape",
+	}
+	switch {
+	case width < height:
+		result.Orientation = "portrait"
+	case width == height:
+		result.Orientation = "square"
+	}
+
+	// Portrait ratios match the standard ratios turned on their side
+	landscape := math.Max(decimal, 1/decimal)
+	best, bestDifference := standardRatios[0], math.Inf(1)
+	for _, standard := range standardRatios {
+		target := float64(standard.width) / float64(standard.height)
+		if difference := math.Abs(landscape-target) / target * 100; difference < bestDifference {
+			best, bestDifference = standard, difference
+		}
+	}
+	result.NearestStandard = best.name
+	if width < height {
+		long, short, _ := strings.Cut(best.name, ":")
+		result.NearestStandard = short + ":" + long
+	}
+	result.DifferencePercent = bestDifference
+	return result
+}
+
+// gcd returns the greatest common divisor of two positive integers
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// roundToMultiple rounds a positive value to the nearest multiple, at least
+// the multiple itself
+func roundToMultiple(value float64, multiple int64) int64 {
+	rounded := int64(math.Round(value/float64(multiple))) * multiple
+	if rounded < multiple {
+		return multiple
+	}
+	return rounded
+}
+
+// requireResolution rejects sides outside 1 to maxResolution pixels
+func requireResolution(name string, pixels int64) error {
+	if pixels < 1 || pixels > maxResolution {
+		return fmt.Errorf("%s must be between 1 and %d pixels, got %d", name, maxResolution, pixels)
+	}
+	return nil
+}
@@ -10,13 +10,32 @@ import (
 
 type BasicCalculator struct{}
 
+// operandConstraint describes how many operands an operation accepts and,
+// for operations that are not associative, how additional operands combine
+type operandConstraint struct {
+	min       int
+	max       int // 0 means no upper bound
+	semantics string
+}
+
+var operandConstraints = map[string]operandConstraint{
+	"add":        {min: 2},
+	"subtract":   {min: 2, semantics: "left-to-right: operands[0] - operands[1] - ... - operands[n-1]"},
+	"multiply":   {min: 2},
+	"divide":     {min: 2, semantics: "left-to-right: operands[0] / operands[1] / ... / operands[n-1]"},
+	"mod":        {min: 2, max: 2},
+	"int_divide": {min: 2, max: 2},
+	"power":      {min: 2, max: 2},
+	"nth_root":   {min: 2, max: 2},
+}
+
 func NewBasicCalculator() *BasicCalculator {
 	return &BasicCalculator{}
 }
 
 func (bc *BasicCalculator) Calculate(req types.BasicMathRequest) (types.CalculationResult, error) {
-	if len(req.Operands) < 2 {
-		return types.CalculationResult{}, fmt.Errorf("at least 2 operands required")
+	if err := bc.ValidateOperandCount(req.Operation, req.Operands); err != nil {
+		return types.CalculationResult{}, err
 	}
 
 	precision := req.Precision
@@ -37,19 +56,108 @@ func (bc *BasicCalculator) Calculate(req types.BasicMathRequest) (types.Calculat
 		if err != nil {
 			return types.CalculationResult{}, err
 		}
+	case "mod":
+		result, err = bc.mod(req.Operands[0], req.Operands[1])
+		if err != nil {
+			return types.CalculationResult{}, err
+		}
+	case "int_divide":
+		result, err = bc.intDivide(req.Operands[0], req.Operands[1])
+		if err != nil {
+			return types.CalculationResult{}, err
+		}
+	case "power":
+		result, err = NewAdvancedCalculator().Power(req.Operands[0], req.Operands[1])
+		if err != nil {
+			return types.CalculationResult{}, err
+		}
+	case "nth_root":
+		result, err = bc.nthRoot(req.Operands[0], req.Operands[1])
+		if err != nil {
+			return types.CalculationResult{}, err
+		}
 	default:
 		return types.CalculationResult{}, fmt.Errorf("unsupported operation: %s", req.Operation)
 	}
 
 	// Round to specified precision
+	raw := result
 	result = bc.roundToPrecision(result, precision)
 
-	return types.CalculationResult{
+	calcResult := types.CalculationResult{
 		Result: result,
-	}, nil
+	}
+	if req.Explain {
+		calcResult.Steps = bc.explainBasic(req, raw, result)
+	}
+	// Document how non-associative operations folded more than two operands
+	if constraint := operandConstraints[req.Operation]; len(req.Operands) > 2 && constraint.semantics != "" {
+		calcResult.Semantics = constraint.semantics
+	}
+
+	return calcResult, nil
+}
+
+// The fast path takes operands that are multiples of 1/fastOperandScale
+// no larger than fastOperandLimit. Their exact values have at most 15
+// significant digits, so each equals the decimal the generic path would
+// parse it as.
+const (
+	fastOperandLimit = 1 << 36
+	fastOperandScale = 16
+	fastMaxOperands  = 4
+)
+
+// fastBasic adds, subtracts or multiplies up to four operands in plain
+// float64 arithmetic when that is provably exact, which covers the integers
+// and simple fractions of most calls, and reports false when the decimal
+// path is needed. Results are identical to the decimal path.
+func fastBasic(operation string, operands []float64) (float64, bool) {
+	if len(operands) < 2 || len(operands) > fastMaxOperands {
+		return 0, false
+	}
+	for _, operand := range operands {
+		scaled := operand * fastOperandScale
+		if math.Abs(operand) > fastOperandLimit || scaled != math.Trunc(scaled) {
+			return 0, false
+		}
+	}
+
+	result := operands[0]
+	switch operation {
+	case "add":
+		for _, operand := range operands[1:] {
+			result += operand
+		}
+	case "subtract":
+		for _, operand := range operands[1:] {
+			result -= operand
+		}
+	case "multiply":
+		// The scaled product must stay an integer float64 holds exactly
+		scaled := operands[0] * fastOperandScale
+		for _, operand := range operands[1:] {
+			scaled *= operand * fastOperandScale
+			if math.Abs(scaled) >= 1<<53 {
+				return 0, false
+			}
+			result *= operand
+		}
+	default:
+		return 0, false
+	}
+	if result == 0 {
+		// Decimals have no negative zero
+		result = 0
+	}
+	return result, true
 }
 
 func (bc *BasicCalculator) add(operands []float64) float64 {
+	if result, ok := fastBasic("add", operands); ok {
+		return result
+	}
+
 	// Use decimal for precise addition
 	result := decimal.NewFromFloat(operands[0])
 	for i := 1; i < len(operands); i++ {
@@ -61,6 +169,10 @@ func (bc *BasicCalculator) add(operands []float64) float64 {
 }
 
 func (bc *BasicCalculator) subtract(operands []float64) float64 {
+	if result, ok := fastBasic("subtract", operands); ok {
+		return result
+	}
+
 	// Use decimal for precise subtraction
 	result := decimal.NewFromFloat(operands[0])
 	for i := 1; i < len(operands); i++ {
@@ -72,6 +184,10 @@ func (bc *BasicCalculator) subtract(operands []float64) float64 {
 }
 
 func (bc *BasicCalculator) multiply(operands []float64) float64 {
+	if result, ok := fastBasic("multiply", operands); ok {
+		return result
+	}
+
 	// Use decimal for precise multiplication
 	result := decimal.NewFromFloat(operands[0])
 	for i := 1; i < len(operands); i++ {
@@ -86,7 +202,8 @@ func (bc *BasicCalculator) divide(operands []float64) (float64, error) {
 	// Check for division by zero
 	for i := 1; i < len(operands); i++ {
 		if operands[i] == 0 {
-			return 0, fmt.Errorf("division by zero")
+			return 0, types.NewCalculationError(types.ErrCodeDivisionByZero, operands[i],
+				"division by zero: operand %d is zero", i).WithField(fmt.Sprintf("operands[%d]", i))
 		}
 	}
 
@@ -100,11 +217,71 @@ func (bc *BasicCalculator) divide(operands []float64) (float64, error) {
 	return floatResult, nil
 }
 
+// mod returns the remainder of a / b, taking the sign of the dividend
+func (bc *BasicCalculator) mod(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, types.NewCalculationError(types.ErrCodeDivisionByZero, b,
+			"modulo by zero: operand 1 is zero").WithField("operands[1]")
+	}
+
+	result := decimal.NewFromFloat(a).Mod(decimal.NewFromFloat(b))
+	floatResult, _ := result.Float64()
+	return floatResult, nil
+}
+
+// intDivide returns floor(a / b)
+func (bc *BasicCalculator) intDivide(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, types.NewCalculationError(types.ErrCodeDivisionByZero, b,
+			"integer division by zero: operand 1 is zero").WithField("operands[1]")
+	}
+
+	result := decimal.NewFromFloat(a).Div(decimal.NewFromFloat(b)).Floor()
+	floatResult, _ := result.Float64()
+	return floatResult, nil
+}
+
+// nthRoot returns the real n-th root of value
+func (bc *BasicCalculator) nthRoot(value, n float64) (float64, error) {
+	if n == 0 {
+		return 0, types.NewCalculationError(types.ErrCodeDivisionByZero, n,
+			"zeroth root is undefined").WithField("operands[1]")
+	}
+
+	if value < 0 {
+		// Only odd integer roots of negative numbers are real
+		isOddInteger := n == math.Trunc(n) && math.Mod(math.Abs(n), 2) == 1
+		if !isOddInteger {
+			return 0, types.NewCalculationError(types.ErrCodeRootDomain, value,
+				"root domain error: %g-th root of negative value %g is not real", n, value).WithField("operands[0]")
+		}
+		return -math.Pow(-value, 1/n), nil
+	}
+
+	result := math.Pow(value, 1/n)
+	if math.IsInf(result, 0) {
+		return 0, fmt.Errorf("calculation resulted in infinity")
+	}
+	return result, nil
+}
+
 func (bc *BasicCalculator) roundToPrecision(value float64, precision int) float64 {
 	multiplier := math.Pow(10, float64(precision))
 	return math.Round(value*multiplier) / multiplier
 }
 
+// Validate checks the operation and operands of a request, as the basic_math
+// tool and the library both do before calculating
+func (bc *BasicCalculator) Validate(req types.BasicMathRequest) error {
+	if err := bc.ValidateOperation(req.Operation); err != nil {
+		return err
+	}
+	if err := bc.ValidateOperands(req.Operands); err != nil {
+		return err
+	}
+	return bc.ValidateOperandCount(req.Operation, req.Operands)
+}
+
 // Additional utility functions for validation
 func (bc *BasicCalculator) ValidateOperands(operands []float64) error {
 	if len(operands) == 0 {
@@ -127,8 +304,33 @@ func (bc *BasicCalculator) ValidateOperands(operands []float64) error {
 	return nil
 }
 
+// ValidateOperandCount checks the operand count against the constraint of the
+// given operation and reports which bound was violated
+func (bc *BasicCalculator) ValidateOperandCount(operation string, operands []float64) error {
+	constraint, exists := operandConstraints[operation]
+	if !exists {
+		return fmt.Errorf("unsupported operation: %s", operation)
+	}
+
+	count := len(operands)
+	if constraint.max > 0 && constraint.min == constraint.max && count != constraint.min {
+		return types.NewCalculationError(types.ErrCodeOperandCount, float64(count),
+			"%s requires exactly %d operands, got %d", operation, constraint.min, count).WithField("operands")
+	}
+	if count < constraint.min {
+		return types.NewCalculationError(types.ErrCodeOperandCount, float64(count),
+			"%s requires at least %d operands, got %d", operation, constraint.min, count).WithField("operands")
+	}
+	if constraint.max > 0 && count > constraint.max {
+		return types.NewCalculationError(types.ErrCodeOperandCount, float64(count),
+			"%s accepts at most %d operands, got %d", operation, constraint.max, count).WithField("operands")
+	}
+
+	return nil
+}
+
 func (bc *BasicCalculator) ValidateOperation(operation string) error {
-	validOperations := []string{"add", "subtract", "multiply", "divide"}
+	validOperations := []string{"add", "subtract", "multiply", "divide", "mod", "int_divide", "power", "nth_root"}
 	for _, validOp := range validOperations {
 		if operation == validOp {
 			return nil
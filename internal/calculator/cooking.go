@@ -0,0 +1,287 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// maxRecipeIngredients bounds the ingredients scaled in one request
+	maxRecipeIngredients = 1000
+
+	// millilitresPerCup is the US cup used by the density table
+	millilitresPerCup = 236.588
+)
+
+// gramsPerCup is the weight of one US cup of common ingredients, as given
+// in standard baking conversion charts. Dry ingredients are spooned and
+// levelled; brown sugar is packed.
+var gramsPerCup = map[string]float64{
+	"water":          236.6,
+	"milk":           242,
+	"cream":          238,
+	"yogurt":         245,
+	"oil":            218,
+	"butter":         227,
+	"honey":          340,
+	"maple_syrup":    315,
+	"flour":          125,
+	"bread_flour":    130,
+	"whole_wheat":    120,
+	"sugar":          200,
+	"brown_sugar":    220,
+	"powdered_sugar": 120,
+	"cocoa":          85,
+	"salt":           292,
+	"rice":           185,
+	"oats":           90,
+}
+
+// ingredientAliases maps other common names onto the density table
+var ingredientAliases = map[string]string{
+	"all_purpose_flour":   "flour",
+	"plain_flour":         "flour",
+	"whole_wheat_flour":   "whole_wheat",
+	"granulated_sugar":    "sugar",
+	"caster_sugar":        "sugar",
+	"icing_sugar":         "powdered_sugar",
+	"confectioners_sugar": "powdered_sugar",
+	"cocoa_powder":        "cocoa",
+	"vegetable_oil":       "oil",
+	"olive_oil":           "oil",
+	"heavy_cream":         "cream",
+	"rolled_oats":         "oats",
+	"table_salt":          "salt",
+}
+
+// gasMarks are the oven gas marks and their temperatures in °F
+var gasMarks = []struct {
+	mark       float64
+	fahrenheit float64
+}{
+	{0.25, 225}, {0.5, 250}, {1, 275}, {2, 300}, {3, 325}, {4, 350},
+	{5, 375}, {6, 400}, {7, 425}, {8, 450}, {9, 475},
+}
+
+// CookingCalculator scales recipes and converts kitchen measurements using
+// the unit converter's volume and weight tables
+type CookingCalculator struct {
+	units *UnitConverter
+}
+
+func NewCookingCalculator() *CookingCalculator {
+	return &CookingCalculator{units: NewUnitConverter()}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (cc *CookingCalculator) GetSupportedOperations() []string {
+	return []string{"scale", "convert", "oven_temperature"}
+}
+
+// GetSupportedIngredients returns the ingredients that can be converted
+// between volume and weight
+func (cc *CookingCalculator) GetSupportedIngredients() []string {
+	ingredients := make([]string, 0, len(gramsPerCup))
+	for ingredient := range gramsPerCup {
+		ingredients = append(ingredients, ingredient)
+	}
+	sort.Strings(ingredients)
+	return ingredients
+}
+
+// Calculate performs the requested cooking calculation
+func (cc *CookingCalculator) Calculate(req types.CookingRequest) (types.CookingResult, error) {
+	switch req.Operation {
+	case "scale":
+		return cc.scaleRecipe(req)
+	case "convert":
+		return cc.convertQuantity(req)
+	case "oven_temperature":
+		return cc.ovenTemperature(req)
+	default:
+		return types.CookingResult{}, fmt.Errorf("unsupported cooking operation: %s. Supported operations: %v", req.Operation, cc.GetSupportedOperations())
+	}
+}
+
+// scaleRecipe multiplies every ingredient by a factor, given directly or as
+// the ratio of target servings to the recipe's servings
+func (cc *CookingCalculator) scaleRecipe(req types.CookingRequest) (types.CookingResult, error) {
+	if len(req.Ingredients) == 0 {
+		return types.CookingResult{}, fmt.Errorf("scale requires ingredients")
+	}
+	if len(req.Ingredients) > maxRecipeIngredients {
+		return types.CookingResult{}, fmt.Errorf("too many ingredients: %d (maximum %d)", len(req.Ingredients), maxRecipeIngredients)
+	}
+
+	var factor float64
+	switch {
+	case req.Factor != nil && (req.Servings != 0 || req.TargetServings != 0):
+		return types.CookingResult{}, fmt.Errorf("give either factor or servings and target_servings, not both")
+	case req.Factor != nil:
+		factor = *req.Factor
+	case req.Servings > 0 && req.TargetServings > 0:
+		factor = req.TargetServings / req.Servings
+	default:
+		return types.CookingResult{}, fmt.Errorf("scale requires a factor or positive servings and target_servings")
+	}
+	if err := requireFinite("factor", factor); err != nil {
+		return types.CookingResult{}, err
+	}
+	if factor <= 0 {
+		return types.CookingResult{}, fmt.Errorf("factor must be positive")
+	}
+
+	scaled := make([]types.CookingIngredient, len(req.Ingredients))
+	for i, ingredient := range req.Ingredients {
+		name := ingredient.Name
+		if name == "" {
+			name = fmt.Sprintf("ingredient %d", i)
+		}
+		if err := requireFinite(name+" quantity", ingredient.Quantity); err != nil {
+			return types.CookingResult{}, err
+		}
+		if ingredient.Quantity < 0 {
+			return types.CookingResult{}, fmt.Errorf("%s quantity cannot be negative", name)
+		}
+		scaled[i] = types.CookingIngredient{Name: ingredient.Name, Quantity: ingredient.Quantity * factor, Unit: ingredient.Unit}
+	}
+	return types.CookingResult{Operation: req.Operation, Factor: &factor, Ingredients: scaled}, nil
+}
+
+// convertQuantity converts between kitchen units, going between volume and
+// weight through the ingredient's density
+func (cc *CookingCalculator) convertQuantity(req types.CookingRequest) (types.CookingResult, error) {
+	if req.Quantity == nil {
+		return types.CookingResult{}, fmt.Errorf("convert requires a quantity")
+	}
+	quantity := *req.Quantity
+	if err := requireFinite("quantity", quantity); err != nil {
+		return types.CookingResult{}, err
+	}
+	if quantity < 0 {
+		return types.CookingResult{}, fmt.Errorf("quantity cannot be negative")
+	}
+
+	fromCategory, err := cc.kitchenCategory("from_unit", req.FromUnit)
+	if err != nil {
+		return types.CookingResult{}, err
+	}
+	toCategory, err := cc.kitchenCategory("to_unit", req.ToUnit)
+	if err != nil {
+		return types.CookingResult{}, err
+	}
+
+	result := types.CookingResult{Operation: req.Operation, Unit: req.ToUnit}
+	var converted float64
+	if fromCategory == toCategory {
+		if converted, err = cc.units.convertGeneric(quantity, req.FromUnit, req.ToUnit, fromCategory); err != nil {
+			return types.CookingResult{}, err
+		}
+		result.Quantity = &converted
+		return result, nil
+	}
+
+	if req.Ingredient == "" {
+		return types.CookingResult{}, fmt.Errorf("converting between %s and %s needs an ingredient; supported: %v", fromCategory, toCategory, cc.GetSupportedIngredients())
+	}
+	density, err := ingredientDensity(req.Ingredient)
+	if err != nil {
+		return types.CookingResult{}, err
+	}
+
+	// Go through millilitres and grams, the bases of the density
+	if fromCategory == "volume" {
+		millilitres, _ := cc.units.convertGeneric(quantity, req.FromUnit, "ml", "volume")
+		converted, err = cc.units.convertGeneric(millilitres*density, "g", req.ToUnit, "weight")
+	} else {
+		grams, _ := cc.units.convertGeneric(quantity, req.FromUnit, "g", "weight")
+		converted, err = cc.units.convertGeneric(grams/density, "ml", req.ToUnit, "volume")
+	}
+	if err != nil {
+		return types.CookingResult{}, err
+	}
+	result.Quantity, result.Density = &converted, &density
+	return result, nil
+}
+
+// ovenTemperature converts an oven setting between °C, °F and gas marks;
+// the nearest gas mark is given for temperatures in the gas mark range
+func (cc *CookingCalculator) ovenTemperature(req types.CookingRequest) (types.CookingResult, error) {
+	if req.Temperature == nil {
+		return types.CookingResult{}, fmt.Errorf("oven_temperature requires a temperature")
+	}
+	temperature := *req.Temperature
+	if err := requireFinite("temperature", temperature); err != nil {
+		return types.CookingResult{}, err
+	}
+
+	var fahrenheit float64
+	switch req.FromUnit {
+	case "gas_mark":
+		found := false
+		for _, gas := range gasMarks {
+			if gas.mark == temperature {
+				fahrenheit, found = gas.fahrenheit, true
+			}
+		}
+		if !found {
+			return types.CookingResult{}, fmt.Errorf("gas mark must be 1/4, 1/2 or a whole mark from 1 to 9, got %g", temperature)
+		}
+	case "C", "F":
+		var err error
+		if fahrenheit, err = cc.units.convertTemperature(temperature, req.FromUnit, "F"); err != nil {
+			return types.CookingResult{}, err
+		}
+	default:
+		return types.CookingResult{}, fmt.Errorf("oven_temperature from_unit must be C, F or gas_mark, got %q", req.FromUnit)
+	}
+
+	celsius, err := cc.units.convertTemperature(fahrenheit, "F", "C")
+	if err != nil {
+		return types.CookingResult{}, err
+	}
+	result := types.CookingResult{Operation: req.Operation, Celsius: &celsius, Fahrenheit: &fahrenheit}
+
+	// Half a mark (12.5 °F) beyond either end still rounds to that mark
+	first, last := gasMarks[0], gasMarks[len(gasMarks)-1]
+	if fahrenheit >= first.fahrenheit-12.5 && fahrenheit <= last.fahrenheit+12.5 {
+		nearest := first
+		for _, gas := range gasMarks[1:] {
+			if math.Abs(gas.fahrenheit-fahrenheit) < math.Abs(nearest.fahrenheit-fahrenheit) {
+				nearest = gas
+			}
+		}
+		result.GasMark = &nearest.mark
+	}
+	return result, nil
+}
+
+// kitchenCategory returns whether a unit measures volume or weight
+func (cc *CookingCalculator) kitchenCategory(field, unit string) (string, error) {
+	for _, category := range []string{"volume", "weight"} {
+		if _, ok := cc.units.registry.Factor(category, unit); ok {
+			return category, nil
+		}
+	}
+	volume, _ := cc.units.GetSupportedUnits("volume")
+	weight, _ := cc.units.GetSupportedUnits("weight")
+	return "", fmt.Errorf("unsupported %s %q: use a volume unit %v or a weight unit %v", field, unit, volume, weight)
+}
+
+// ingredientDensity returns an ingredient's density in grams per millilitre
+func ingredientDensity(ingredient string) (float64, error) {
+	key := strings.ToLower(strings.TrimSpace(ingredient))
+	key = strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(key)
+	if alias, ok := ingredientAliases[key]; ok {
+		key = alias
+	}
+	grams, ok := gramsPerCup[key]
+	if !ok {
+		return 0, fmt.Errorf("unknown ingredient %q for volume/weight conversion", ingredient)
+	}
+	return grams / millilitresPerCup, nil
+}
@@ -0,0 +1,101 @@
+package calculator
+
+import (
+	"fmt"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// Day-count conventions for date-based accrual
+const (
+	DayCountActual360 = "actual/360"
+	DayCountActual365 = "actual/365"
+	DayCount30360     = "30/360"
+)
+
+// dateLayout is the format of startDate and endDate
+const dateLayout = "2006-01-02"
+
+// GetSupportedDayCounts returns the day-count conventions accepted by YearFraction
+func GetSupportedDayCounts() []string {
+	return []string{DayCountActual360, DayCountActual365, DayCount30360}
+}
+
+// YearFraction returns the accrual days between start and end and the
+// fraction of a year they make up under the given convention:
+//   - actual/360: actual days / 360
+//   - actual/365: actual days / 365 (leap days count but the year stays 365)
+//   - 30/360: every month counts 30 days (bond basis; day 31 becomes 30)
+func YearFraction(start, end time.Time, convention string) (int, float64, error) {
+	if !end.After(start) {
+		return 0, 0, fmt.Errorf("endDate must be after startDate")
+	}
+
+	switch convention {
+	case DayCountActual360, DayCountActual365:
+		// Dates are parsed in UTC, so every day is exactly 24 hours
+		days := int(end.Sub(start).Hours() / 24)
+		if convention == DayCountActual360 {
+			return days, float64(days) / 360, nil
+		}
+		return days, float64(days) / 365, nil
+	case DayCount30360:
+		y1, m1, d1 := start.Date()
+		y2, m2, d2 := end.Date()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		days := 360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1)
+		return days, float64(days) / 360, nil
+	default:
+		return 0, 0, fmt.Errorf("unsupported day count convention: %s. Supported conventions: %v", convention, GetSupportedDayCounts())
+	}
+}
+
+// applyDates replaces req.Time with the year fraction between
+// req.StartDate and req.EndDate, returning the accrual details for the
+// breakdown, or nil when the request uses time directly
+func applyDates(req *types.FinancialRequest) (map[string]interface{}, error) {
+	if req.StartDate == "" && req.EndDate == "" {
+		if req.DayCount != "" {
+			return nil, fmt.Errorf("dayCount requires startDate and endDate")
+		}
+		return nil, nil
+	}
+	if req.StartDate == "" || req.EndDate == "" {
+		return nil, fmt.Errorf("startDate and endDate must be given together")
+	}
+	if req.Time != 0 {
+		return nil, fmt.Errorf("give either time or startDate and endDate, not both")
+	}
+
+	start, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startDate %q: use YYYY-MM-DD", req.StartDate)
+	}
+	end, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endDate %q: use YYYY-MM-DD", req.EndDate)
+	}
+
+	convention := req.DayCount
+	if convention == "" {
+		convention = DayCountActual365
+	}
+	days, years, err := YearFraction(start, end, convention)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Time = years
+	return map[string]interface{}{
+		"start_date":   req.StartDate,
+		"end_date":     req.EndDate,
+		"day_count":    convention,
+		"accrual_days": days,
+	}, nil
+}
@@ -0,0 +1,415 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// defaultEigenTolerance is the relative size below which off-diagonal
+	// entries count as zero
+	defaultEigenTolerance = 1e-12
+
+	// defaultJacobiSweeps and defaultQRStepsPerEigenvalue bound the iterations
+	// when the request leaves max_iterations unset
+	defaultJacobiSweeps         = 100
+	defaultQRStepsPerEigenvalue = 30
+	maxEigenIterations          = 10000
+
+	// realEigenvalueTolerance is the relative imaginary part below which a
+	// complex QR eigenvalue is reported as real
+	realEigenvalueTolerance = 1e-9
+)
+
+// EigenSolver computes eigenvalues and eigenvectors iteratively: cyclic
+// Jacobi rotations for symmetric matrices and shifted QR iteration on the
+// Hessenberg form for general ones
+type EigenSolver struct{}
+
+func NewEigenSolver() *EigenSolver {
+	return &EigenSolver{}
+}
+
+// GetSupportedMethods returns the methods accepted by Solve
+func (es *EigenSolver) GetSupportedMethods() []string {
+	return []string{"auto", "jacobi", "qr"}
+}
+
+// Solve computes the eigenvalues of req.Matrix. "auto" uses Jacobi for
+// symmetric matrices and QR iteration otherwise.
+func (es *EigenSolver) Solve(req types.EigenRequest) (types.EigenResult, error) {
+	a, err := denseFromRows(req.Matrix)
+	if err != nil {
+		return types.EigenResult{}, err
+	}
+	n, columns := a.Dims()
+	if err := requireSquare("eigen", n, columns); err != nil {
+		return types.EigenResult{}, err
+	}
+
+	tolerance := req.Tolerance
+	if tolerance == 0 {
+		tolerance = defaultEigenTolerance
+	}
+	if tolerance < 0 || tolerance >= 1 || math.IsNaN(tolerance) {
+		return types.EigenResult{}, fmt.Errorf("tolerance must be between 0 and 1, got %g", req.Tolerance)
+	}
+	if req.MaxIterations < 0 || req.MaxIterations > maxEigenIterations {
+		return types.EigenResult{}, fmt.Errorf("max_iterations must be between 1 and %d, got %d", maxEigenIterations, req.MaxIterations)
+	}
+
+	symmetric := isSymmetric(a)
+	method := req.Method
+	switch method {
+	case "", "auto":
+		method = "qr"
+		if symmetric {
+			method = "jacobi"
+		}
+	case "jacobi":
+		if !symmetric {
+			return types.EigenResult{}, fmt.Errorf("the jacobi method requires a symmetric matrix; use qr for general matrices")
+		}
+	case "qr":
+	default:
+		return types.EigenResult{}, fmt.Errorf("unsupported eigen method: %s. Supported methods: %v", req.Method, es.GetSupportedMethods())
+	}
+
+	result := types.EigenResult{Method: method, Symmetric: symmetric, MaxIterations: req.MaxIterations}
+	if method == "jacobi" {
+		if result.MaxIterations == 0 {
+			result.MaxIterations = defaultJacobiSweeps
+		}
+		result.Eigenvalues, result.Iterations, result.Converged = jacobiEigen(a, result.MaxIterations, tolerance)
+	} else {
+		if result.MaxIterations == 0 {
+			result.MaxIterations = defaultQRStepsPerEigenvalue * n
+		}
+		var values []complex128
+		values, result.Iterations, result.Converged = qrEigenvalues(a, result.MaxIterations, tolerance)
+		result.Eigenvalues = eigenpairsFromValues(a, values)
+	}
+
+	sort.SliceStable(result.Eigenvalues, func(i, j int) bool {
+		if result.Eigenvalues[i].Real != result.Eigenvalues[j].Real {
+			return result.Eigenvalues[i].Real > result.Eigenvalues[j].Real
+		}
+		return result.Eigenvalues[i].Imaginary > result.Eigenvalues[j].Imaginary
+	})
+
+	for _, pair := range result.Eigenvalues {
+		if pair.Vector != nil {
+			result.Residual = math.Max(result.Residual, eigenResidual(a, pair))
+		}
+	}
+	if !result.Converged {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("did not converge within %d iterations; eigenvalues are estimates", result.MaxIterations))
+	}
+
+	return result, nil
+}
+
+// jacobiEigen diagonalises a symmetric matrix with cyclic Jacobi rotations.
+// Each sweep rotates away every off-diagonal entry once; iterations counts
+// sweeps.
+func jacobiEigen(a *mat.Dense, maxSweeps int, tolerance float64) ([]types.Eigenpair, int, bool) {
+	n, _ := a.Dims()
+	d := rowsFromMatrix(a)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+
+	norm := mat.Norm(a, 2)
+	sweeps, converged := 0, false
+	for {
+		off := 0.0
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += 2 * d[p][q] * d[p][q]
+			}
+		}
+		if math.Sqrt(off) <= tolerance*norm {
+			converged = true
+			break
+		}
+		if sweeps == maxSweeps {
+			break
+		}
+		sweeps++
+
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if d[p][q] == 0 {
+					continue
+				}
+				// Rotation angle that zeroes d[p][q]
+				theta := (d[q][q] - d[p][p]) / (2 * d[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < n; k++ {
+					dkp, dkq := d[k][p], d[k][q]
+					d[k][p], d[k][q] = c*dkp-s*dkq, s*dkp+c*dkq
+				}
+				for k := 0; k < n; k++ {
+					dpk, dqk := d[p][k], d[q][k]
+					d[p][k], d[q][k] = c*dpk-s*dqk, s*dpk+c*dqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	pairs := make([]types.Eigenpair, n)
+	for i := range pairs {
+		vector := make([]float64, n)
+		for k := range vector {
+			vector[k] = v[k][i]
+		}
+		pairs[i] = types.Eigenpair{Real: d[i][i], Vector: orientVector(vector)}
+	}
+	return pairs, sweeps, converged
+}
+
+// qrEigenvalues reduces a to Hessenberg form and runs Wilkinson-shifted QR
+// steps in complex arithmetic, so complex conjugate pairs converge as well,
+// deflating one eigenvalue whenever the last subdiagonal entry vanishes.
+// When the limit is hit the diagonal of the unconverged block is returned.
+func qrEigenvalues(a *mat.Dense, maxSteps int, tolerance float64) ([]complex128, int, bool) {
+	n, _ := a.Dims()
+	reduced := hessenberg(rowsFromMatrix(a))
+	h := make([][]complex128, n)
+	for i := range h {
+		h[i] = make([]complex128, n)
+		for j := range h[i] {
+			h[i][j] = complex(reduced[i][j], 0)
+		}
+	}
+
+	norm := mat.Norm(a, 1)
+	values := make([]complex128, n)
+	steps, sinceDeflation := 0, 0
+	for active := n; active > 0; {
+		m := active - 1
+		if m == 0 {
+			values[0] = h[0][0]
+			break
+		}
+
+		scale := cmplx.Abs(h[m][m]) + cmplx.Abs(h[m-1][m-1])
+		if scale == 0 {
+			scale = norm
+		}
+		if cmplx.Abs(h[m][m-1]) <= tolerance*scale {
+			values[m] = h[m][m]
+			active--
+			sinceDeflation = 0
+			continue
+		}
+
+		if steps == maxSteps {
+			for i := 0; i < active; i++ {
+				values[i] = h[i][i]
+			}
+			return values, steps, false
+		}
+
+		shift := wilkinsonShift(h[m-1][m-1], h[m-1][m], h[m][m-1], h[m][m])
+		if sinceDeflation > 0 && sinceDeflation%10 == 0 {
+			// Exceptional shift to break cycles
+			shift += complex(0.75*cmplx.Abs(h[m][m-1]), 0)
+		}
+		shiftedQRStep(h, active, shift)
+		steps++
+		sinceDeflation++
+	}
+	return values, steps, true
+}
+
+// hessenberg reduces a square matrix to upper Hessenberg form with
+// Householder reflections, preserving its eigenvalues
+func hessenberg(h [][]float64) [][]float64 {
+	n := len(h)
+	for k := 0; k < n-2; k++ {
+		alpha := 0.0
+		for i := k + 1; i < n; i++ {
+			alpha += h[i][k] * h[i][k]
+		}
+		alpha = math.Sqrt(alpha)
+		if alpha == 0 {
+			continue
+		}
+		if h[k+1][k] > 0 {
+			alpha = -alpha
+		}
+
+		v := make([]float64, n)
+		v[k+1] = h[k+1][k] - alpha
+		for i := k + 2; i < n; i++ {
+			v[i] = h[i][k]
+		}
+		vNorm := 0.0
+		for i := k + 1; i < n; i++ {
+			vNorm += v[i] * v[i]
+		}
+		vNorm = math.Sqrt(vNorm)
+		for i := k + 1; i < n; i++ {
+			v[i] /= vNorm
+		}
+
+		for j := 0; j < n; j++ {
+			dot := 0.0
+			for i := k + 1; i < n; i++ {
+				dot += v[i] * h[i][j]
+			}
+			for i := k + 1; i < n; i++ {
+				h[i][j] -= 2 * v[i] * dot
+			}
+		}
+		for i := 0; i < n; i++ {
+			dot := 0.0
+			for j := k + 1; j < n; j++ {
+				dot += h[i][j] * v[j]
+			}
+			for j := k + 1; j < n; j++ {
+				h[i][j] -= 2 * dot * v[j]
+			}
+		}
+	}
+	return h
+}
+
+// shiftedQRStep replaces the leading size×size block H of h by R·Q + μ·I
+// where Q·R = H − μ·I, using Givens rotations
+func shiftedQRStep(h [][]complex128, size int, shift complex128) {
+	for i := 0; i < size; i++ {
+		h[i][i] -= shift
+	}
+
+	cs := make([]complex128, size-1)
+	ss := make([]complex128, size-1)
+	for j := 0; j < size-1; j++ {
+		a, b := h[j][j], h[j+1][j]
+		r := math.Hypot(cmplx.Abs(a), cmplx.Abs(b))
+		c, s := complex(1, 0), complex(0, 0)
+		if r != 0 {
+			c, s = a/complex(r, 0), b/complex(r, 0)
+		}
+		cs[j], ss[j] = c, s
+		for k := j; k < size; k++ {
+			x, y := h[j][k], h[j+1][k]
+			h[j][k] = cmplx.Conj(c)*x + cmplx.Conj(s)*y
+			h[j+1][k] = -s*x + c*y
+		}
+	}
+	for j := 0; j < size-1; j++ {
+		c, s := cs[j], ss[j]
+		for i := 0; i < size; i++ {
+			x, y := h[i][j], h[i][j+1]
+			h[i][j] = x*c + y*s
+			h[i][j+1] = -x*cmplx.Conj(s) + y*cmplx.Conj(c)
+		}
+	}
+
+	for i := 0; i < size; i++ {
+		h[i][i] += shift
+	}
+}
+
+// wilkinsonShift returns the eigenvalue of [[a, b], [c, d]] closer to d
+func wilkinsonShift(a, b, c, d complex128) complex128 {
+	mean := (a + d) / 2
+	root := cmplx.Sqrt((a-d)*(a-d)/4 + b*c)
+	if cmplx.Abs(mean+root-d) < cmplx.Abs(mean-root-d) {
+		return mean + root
+	}
+	return mean - root
+}
+
+// eigenpairsFromValues snaps nearly real eigenvalues to the real line and
+// finds a unit eigenvector for each real one by inverse iteration
+func eigenpairsFromValues(a *mat.Dense, values []complex128) []types.Eigenpair {
+	n, _ := a.Dims()
+	pairs := make([]types.Eigenpair, len(values))
+	for i, value := range values {
+		pairs[i] = types.Eigenpair{Real: real(value), Imaginary: imag(value)}
+		if math.Abs(imag(value)) > realEigenvalueTolerance*math.Max(1, cmplx.Abs(value)) {
+			continue
+		}
+		pairs[i].Imaginary = 0
+
+		// Perturb the shift slightly so A − λ·I is not exactly singular
+		lambda := real(value) + realEigenvalueTolerance*math.Max(1, math.Abs(real(value)))
+		shifted := mat.NewDense(n, n, nil)
+		shifted.Copy(a)
+		for k := 0; k < n; k++ {
+			shifted.Set(k, k, shifted.At(k, k)-lambda)
+		}
+		var lu mat.LU
+		lu.Factorize(shifted)
+
+		// Start from a fixed vector unlikely to be orthogonal to the eigenvector
+		x := mat.NewVecDense(n, nil)
+		for k := 0; k < n; k++ {
+			x.SetVec(k, 1+math.Sin(float64(k+1)))
+		}
+		ok := true
+		for iteration := 0; iteration < 3 && ok; iteration++ {
+			var next mat.VecDense
+			// The system is nearly singular by construction, so the condition
+			// error is expected; an exactly singular one shows up as Inf below
+			_ = lu.SolveVecTo(&next, false, x)
+			norm := mat.Norm(&next, 2)
+			if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+				ok = false
+				break
+			}
+			x.ScaleVec(1/norm, &next)
+		}
+		if ok {
+			pairs[i].Vector = orientVector(append([]float64(nil), x.RawVector().Data...))
+		}
+	}
+	return pairs
+}
+
+// eigenResidual returns ‖A·v − λ·v‖ for a real eigenpair
+func eigenResidual(a *mat.Dense, pair types.Eigenpair) float64 {
+	v := mat.NewVecDense(len(pair.Vector), append([]float64(nil), pair.Vector...))
+	var av mat.VecDense
+	av.MulVec(a, v)
+	av.AddScaledVec(&av, -pair.Real, v)
+	return mat.Norm(&av, 2)
+}
+
+// orientVector flips v so its largest component is positive, making the
+// returned eigenvectors deterministic
+func orientVector(v []float64) []float64 {
+	largest := 0
+	for i := range v {
+		if math.Abs(v[i]) > math.Abs(v[largest]) {
+			largest = i
+		}
+	}
+	if v[largest] < 0 {
+		for i := range v {
+			v[i] = -v[i]
+		}
+	}
+	return v
+}
@@ -0,0 +1,273 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"calculator-server/internal/types"
+)
+
+// maxCircuitComponents bounds the values combined in series or parallel
+const maxCircuitComponents = 1000
+
+// quantityUnits gives the SI unit symbol of each quantity the electronics
+// calculator reports
+var quantityUnits = map[string]string{
+	"voltage":          "V",
+	"current":          "A",
+	"resistance":       "Ω",
+	"power":            "W",
+	"capacitance":      "F",
+	"inductance":       "H",
+	"total":            "",
+	"input_voltage":    "V",
+	"output_voltage":   "V",
+	"r1":               "Ω",
+	"r2":               "Ω",
+	"load":             "Ω",
+	"effective_r2":     "Ω",
+	"load_current":     "A",
+	"time_constant":    "s",
+	"cutoff_frequency": "Hz",
+	"settling_time":    "s",
+	"time":             "s",
+	"charge_percent":   "%",
+}
+
+// componentUnits gives the unit of each component type that can be
+// combined in series or parallel
+var componentUnits = map[string]string{
+	"resistor":  "Ω",
+	"capacitor": "F",
+	"inductor":  "H",
+}
+
+// ElectronicsCalculator solves common circuit problems
+type ElectronicsCalculator struct{}
+
+func NewElectronicsCalculator() *ElectronicsCalculator {
+	return &ElectronicsCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (ec *ElectronicsCalculator) GetSupportedOperations() []string {
+	return []string{"ohms_law", "series", "parallel", "voltage_divider", "rc_time_constant"}
+}
+
+// Calculate performs the requested circuit calculation
+func (ec *ElectronicsCalculator) Calculate(req types.ElectronicsRequest) (types.ElectronicsResult, error) {
+	var values map[string]float64
+	var err error
+	unit := ""
+	switch req.Operation {
+	case "ohms_law":
+		values, err = ohmsLaw(req)
+	case "series", "parallel":
+		var ok bool
+		if unit, ok = componentUnits[req.Component]; !ok {
+			return types.ElectronicsResult{}, fmt.Errorf("%s requires a component of resistor, capacitor or inductor, got %q", req.Operation, req.Component)
+		}
+		values, err = combineComponents(req.Operation, req.Component, req.Values)
+	case "voltage_divider":
+		values, err = voltageDivider(req)
+	case "rc_time_constant":
+		values, err = rcTimeConstant(req)
+	default:
+		return types.ElectronicsResult{}, fmt.Errorf("unsupported electronics operation: %s. Supported operations: %v", req.Operation, ec.GetSupportedOperations())
+	}
+	if err != nil {
+		return types.ElectronicsResult{}, err
+	}
+
+	formatted := make(map[string]string, len(values))
+	for name, value := range values {
+		symbol := quantityUnits[name]
+		if name == "total" {
+			symbol = unit
+		}
+		if symbol == "%" {
+			formatted[name] = fmt.Sprintf("%g%%", roundSignificant(value, engineeringDigits))
+			continue
+		}
+		formatted[name] = FormatEngineering(value, symbol)
+	}
+
+	return types.ElectronicsResult{Operation: req.Operation, Values: values, Formatted: formatted}, nil
+}
+
+// ohmsLaw solves V = I·R and P = V·I from any two of the four quantities
+func ohmsLaw(req types.ElectronicsRequest) (map[string]float64, error) {
+	given := map[string]*float64{"voltage": req.Voltage, "current": req.Current, "resistance": req.Resistance, "power": req.Power}
+	var known []string
+	for name, value := range given {
+		if value == nil {
+			continue
+		}
+		if err := requireFinite(name, *value); err != nil {
+			return nil, err
+		}
+		known = append(known, name)
+	}
+	if len(known) != 2 {
+		return nil, fmt.Errorf("ohms_law needs exactly two of voltage, current, resistance and power, got %d", len(known))
+	}
+	sort.Strings(known)
+	if req.Resistance != nil && *req.Resistance <= 0 {
+		return nil, fmt.Errorf("resistance must be positive")
+	}
+
+	var v, i, r, p float64
+	switch known[0] + "," + known[1] {
+	case "current,voltage":
+		v, i = *req.Voltage, *req.Current
+		if i == 0 {
+			return nil, fmt.Errorf("current cannot be zero when solving for resistance")
+		}
+		r, p = v/i, v*i
+	case "resistance,voltage":
+		v, r = *req.Voltage, *req.Resistance
+		i, p = v/r, v*v/r
+	case "power,voltage":
+		v, p = *req.Voltage, *req.Power
+		if v == 0 {
+			return nil, fmt.Errorf("voltage cannot be zero when solving from power")
+		}
+		i, r = p/v, v*v/p
+	case "current,resistance":
+		i, r = *req.Current, *req.Resistance
+		v, p = i*r, i*i*r
+	case "current,power":
+		i, p = *req.Current, *req.Power
+		if i == 0 {
+			return nil, fmt.Errorf("current cannot be zero when solving from power")
+		}
+		v, r = p/i, p/(i*i)
+	case "power,resistance":
+		r, p = *req.Resistance, *req.Power
+		if p < 0 {
+			return nil, fmt.Errorf("power dissipated in a resistance cannot be negative")
+		}
+		i, v = math.Sqrt(p/r), math.Sqrt(p*r)
+	}
+	if r <= 0 || math.IsInf(r, 0) {
+		return nil, fmt.Errorf("these values imply a non-positive or infinite resistance (%g Ω)", r)
+	}
+
+	return map[string]float64{"voltage": v, "current": i, "resistance": r, "power": p}, nil
+}
+
+// combineComponents adds resistors and inductors directly in series and
+// reciprocally in parallel; capacitors combine the other way round
+func combineComponents(operation, component string, values []float64) (map[string]float64, error) {
+	if len(values) < 2 {
+		return nil, fmt.Errorf("%s requires at least two values", operation)
+	}
+	if len(values) > maxCircuitComponents {
+		return nil, fmt.Errorf("too many values: %d (maximum %d)", len(values), maxCircuitComponents)
+	}
+
+	sum, reciprocal := 0.0, 0.0
+	for i, value := range values {
+		if err := requireFinite(fmt.Sprintf("value %d", i), value); err != nil {
+			return nil, err
+		}
+		if value <= 0 {
+			return nil, fmt.Errorf("value %d must be positive", i)
+		}
+		sum += value
+		reciprocal += 1 / value
+	}
+
+	additive := operation == "series"
+	if component == "capacitor" {
+		additive = !additive
+	}
+	total := 1 / reciprocal
+	if additive {
+		total = sum
+	}
+	return map[string]float64{"total": total}, nil
+}
+
+// voltageDivider computes the output across R2 of a two-resistor divider,
+// with an optional load in parallel with R2
+func voltageDivider(req types.ElectronicsRequest) (map[string]float64, error) {
+	if req.InputVoltage == nil || req.R1 == nil || req.R2 == nil {
+		return nil, fmt.Errorf("voltage_divider requires input_voltage, r1 and r2")
+	}
+	vin, r1, r2 := *req.InputVoltage, *req.R1, *req.R2
+	if err := requireFinite("input_voltage", vin); err != nil {
+		return nil, err
+	}
+	if err := requireFinite("r1", r1); err != nil {
+		return nil, err
+	}
+	if err := requireFinite("r2", r2); err != nil {
+		return nil, err
+	}
+	if r1 < 0 || r2 < 0 || r1+r2 == 0 {
+		return nil, fmt.Errorf("r1 and r2 cannot be negative or both zero")
+	}
+
+	values := map[string]float64{"input_voltage": vin, "r1": r1, "r2": r2}
+	lower := r2
+	if req.Load != nil {
+		load := *req.Load
+		if err := requireFinite("load", load); err != nil {
+			return nil, err
+		}
+		if load <= 0 {
+			return nil, fmt.Errorf("load must be positive")
+		}
+		lower = r2 * load / (r2 + load)
+		values["load"], values["effective_r2"] = load, lower
+	}
+
+	vout := vin * lower / (r1 + lower)
+	values["output_voltage"] = vout
+	values["current"] = vin / (r1 + lower)
+	if req.Load != nil {
+		values["load_current"] = vout / *req.Load
+	}
+	return values, nil
+}
+
+// rcTimeConstant computes τ = R·C, the −3 dB cutoff 1/(2πτ) and the 5τ
+// settling time, and with a time the percentage charged, 1 − e^(−t/τ)
+func rcTimeConstant(req types.ElectronicsRequest) (map[string]float64, error) {
+	if req.Resistance == nil || req.Capacitance == nil {
+		return nil, fmt.Errorf("rc_time_constant requires resistance and capacitance")
+	}
+	r, c := *req.Resistance, *req.Capacitance
+	if err := requireFinite("resistance", r); err != nil {
+		return nil, err
+	}
+	if err := requireFinite("capacitance", c); err != nil {
+		return nil, err
+	}
+	if r <= 0 || c <= 0 {
+		return nil, fmt.Errorf("resistance and capacitance must be positive")
+	}
+
+	tau := r * c
+	values := map[string]float64{
+		"resistance":       r,
+		"capacitance":      c,
+		"time_constant":    tau,
+		"cutoff_frequency": 1 / (2 * math.Pi * tau),
+		"settling_time":    5 * tau,
+	}
+	if req.Time != nil {
+		t := *req.Time
+		if err := requireFinite("time", t); err != nil {
+			return nil, err
+		}
+		if t < 0 {
+			return nil, fmt.Errorf("time cannot be negative")
+		}
+		values["time"] = t
+		values["charge_percent"] = -math.Expm1(-t/tau) * 100
+	}
+	return values, nil
+}
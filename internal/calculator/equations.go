@@ -0,0 +1,320 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+
+	"calculator-server/internal/types"
+)
+
+// maxEquations bounds the size of systems accepted by the solver
+const maxEquations = 50
+
+// LinearSolver solves systems of simultaneous linear equations exactly,
+// using rational arithmetic
+type LinearSolver struct{}
+
+func NewLinearSolver() *LinearSolver {
+	return &LinearSolver{}
+}
+
+// linearEquation is coefficients·variables = constant
+type linearEquation struct {
+	coefficients map[string]*big.Rat
+	constant     *big.Rat
+}
+
+// Solve parses equations such as "2x + 3y = 7" and solves for their variables
+func (ls *LinearSolver) Solve(req types.LinearSystemRequest) (types.LinearSystemResult, error) {
+	if len(req.Equations) == 0 {
+		return types.LinearSystemResult{}, fmt.Errorf("at least one equation is required")
+	}
+	if len(req.Equations) > maxEquations {
+		return types.LinearSystemResult{}, fmt.Errorf("too many equations: %d (maximum %d)", len(req.Equations), maxEquations)
+	}
+
+	equations := make([]linearEquation, len(req.Equations))
+	seen := make(map[string]bool)
+	for i, text := range req.Equations {
+		equation, err := parseLinearEquation(text)
+		if err != nil {
+			return types.LinearSystemResult{}, fmt.Errorf("equation %d (%q): %v", i+1, text, err)
+		}
+		equations[i] = equation
+		for name := range equation.coefficients {
+			seen[name] = true
+		}
+	}
+
+	variables := make([]string, 0, len(seen))
+	for name := range seen {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+	if len(variables) == 0 {
+		return types.LinearSystemResult{}, fmt.Errorf("the equations contain no variables")
+	}
+
+	solution, err := solveRational(equations, variables)
+	if err != nil {
+		return types.LinearSystemResult{}, err
+	}
+
+	result := types.LinearSystemResult{
+		Variables: variables,
+		Solution:  make(map[string]float64, len(variables)),
+		Exact:     make(map[string]string, len(variables)),
+	}
+	for i, name := range variables {
+		value, _ := solution[i].Float64()
+		result.Solution[name] = value
+		result.Exact[name] = solution[i].RatString()
+	}
+	return result, nil
+}
+
+// solveRational performs Gauss-Jordan elimination on the augmented matrix
+func solveRational(equations []linearEquation, variables []string) ([]*big.Rat, error) {
+	rows, cols := len(equations), len(variables)
+	matrix := make([][]*big.Rat, rows)
+	for i, equation := range equations {
+		matrix[i] = make([]*big.Rat, cols+1)
+		for j, name := range variables {
+			matrix[i][j] = new(big.Rat)
+			if coefficient, ok := equation.coefficients[name]; ok {
+				matrix[i][j].Set(coefficient)
+			}
+		}
+		matrix[i][cols] = new(big.Rat).Set(equation.constant)
+	}
+
+	rank := 0
+	pivotColumns := make([]int, 0, cols)
+	for col := 0; col < cols && rank < rows; col++ {
+		pivot := -1
+		for row := rank; row < rows; row++ {
+			if matrix[row][col].Sign() != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		matrix[rank], matrix[pivot] = matrix[pivot], matrix[rank]
+
+		// Normalise the pivot row, then clear the column everywhere else
+		inverse := new(big.Rat).Inv(matrix[rank][col])
+		for j := col; j <= cols; j++ {
+			matrix[rank][j].Mul(matrix[rank][j], inverse)
+		}
+		for row := 0; row < rows; row++ {
+			if row == rank || matrix[row][col].Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Set(matrix[row][col])
+			for j := col; j <= cols; j++ {
+				matrix[row][j].Sub(matrix[row][j], new(big.Rat).Mul(factor, matrix[rank][j]))
+			}
+		}
+
+		pivotColumns = append(pivotColumns, col)
+		rank++
+	}
+
+	// A zero row with a non-zero constant means 0 = c
+	for row := rank; row < rows; row++ {
+		if matrix[row][cols].Sign() != 0 {
+			return nil, fmt.Errorf("the system is inconsistent and has no solution")
+		}
+	}
+	if rank < cols {
+		return nil, fmt.Errorf("the system has infinitely many solutions: %d independent equations for %d variables %v", rank, cols, variables)
+	}
+
+	solution := make([]*big.Rat, cols)
+	for row, col := range pivotColumns {
+		solution[col] = matrix[row][cols]
+	}
+	return solution, nil
+}
+
+// parseLinearEquation parses "<side> = <side>" where each side is a sum of
+// terms like 3, 2x, -y, 0.5*z, 3/4 w or x/2
+func parseLinearEquation(text string) (linearEquation, error) {
+	sides := strings.Split(text, "=")
+	if len(sides) != 2 {
+		return linearEquation{}, fmt.Errorf("expected exactly one '='")
+	}
+
+	equation, err := parseRelation(sides[0], sides[1])
+	if err != nil {
+		return linearEquation{}, err
+	}
+	for name := range equation.coefficients {
+		if strings.Contains(name, "^") {
+			return linearEquation{}, fmt.Errorf("non-linear term %s", name)
+		}
+	}
+	return equation, nil
+}
+
+// parseRelation parses both sides of an equation or inequality, moving the
+// variable terms to the left and the constant to the right
+func parseRelation(leftText, rightText string) (linearEquation, error) {
+	left, err := parseTerms(leftText)
+	if err != nil {
+		return linearEquation{}, err
+	}
+	right, err := parseTerms(rightText)
+	if err != nil {
+		return linearEquation{}, err
+	}
+
+	equation := linearEquation{
+		coefficients: left.coefficients,
+		constant:     new(big.Rat).Sub(right.constant, left.constant),
+	}
+	for name, coefficient := range right.coefficients {
+		if existing, ok := equation.coefficients[name]; ok {
+			existing.Sub(existing, coefficient)
+		} else {
+			equation.coefficients[name] = new(big.Rat).Neg(coefficient)
+		}
+	}
+	for name, coefficient := range equation.coefficients {
+		if coefficient.Sign() == 0 {
+			delete(equation.coefficients, name)
+		}
+	}
+	return equation, nil
+}
+
+// parseTerms parses one side of an equation into coefficients keyed by
+// monomial ("x", or "x^2" for higher powers) and a constant term
+func parseTerms(text string) (linearEquation, error) {
+	side := linearEquation{
+		coefficients: make(map[string]*big.Rat),
+		constant:     new(big.Rat),
+	}
+
+	input := strings.ReplaceAll(text, " ", "")
+	if input == "" {
+		return side, fmt.Errorf("empty side")
+	}
+
+	pos := 0
+	for pos < len(input) {
+		sign := int64(1)
+		if input[pos] == '+' || input[pos] == '-' {
+			if input[pos] == '-' {
+				sign = -1
+			}
+			pos++
+		} else if pos > 0 {
+			return side, fmt.Errorf("expected '+' or '-' at %q", input[pos:])
+		}
+
+		coefficient := big.NewRat(sign, 1)
+		number, n := scanNumber(input[pos:])
+		if n > 0 {
+			coefficient.Mul(coefficient, number)
+			pos += n
+		}
+		if pos < len(input) && input[pos] == '*' {
+			pos++
+		}
+
+		name, n := scanIdentifier(input[pos:])
+		pos += n
+		if name == "" && number == nil {
+			return side, fmt.Errorf("unexpected %q", input[pos:])
+		}
+
+		// Powers of a variable, as in x^2
+		if name != "" && pos < len(input) && input[pos] == '^' {
+			end := pos + 1
+			for end < len(input) && input[end] >= '0' && input[end] <= '9' {
+				end++
+			}
+			if end == pos+1 {
+				return side, fmt.Errorf("invalid exponent at %q", input[pos:])
+			}
+			switch power := strings.TrimLeft(input[pos+1:end], "0"); power {
+			case "":
+				name = ""
+			case "1":
+			default:
+				name += "^" + power
+			}
+			pos = end
+		}
+
+		// Allow a trailing divisor, as in x/2
+		if pos < len(input) && input[pos] == '/' {
+			divisor, n := scanNumber(input[pos+1:])
+			if n == 0 || divisor.Sign() == 0 {
+				return side, fmt.Errorf("invalid divisor at %q", input[pos:])
+			}
+			coefficient.Quo(coefficient, divisor)
+			pos += 1 + n
+		}
+
+		if name == "" {
+			side.constant.Add(side.constant, coefficient)
+		} else if existing, ok := side.coefficients[name]; ok {
+			existing.Add(existing, coefficient)
+		} else {
+			side.coefficients[name] = coefficient
+		}
+	}
+	return side, nil
+}
+
+// scanNumber reads a decimal number, optionally written as a fraction (3/4),
+// and returns it with the number of bytes consumed
+func scanNumber(input string) (*big.Rat, int) {
+	end := 0
+	for end < len(input) && (input[end] >= '0' && input[end] <= '9' || input[end] == '.') {
+		end++
+	}
+	if end == 0 {
+		return nil, 0
+	}
+	number, ok := new(big.Rat).SetString(input[:end])
+	if !ok {
+		return nil, 0
+	}
+
+	// A fraction's denominator follows immediately, e.g. 3/4x
+	if end < len(input) && input[end] == '/' {
+		denominatorEnd := end + 1
+		for denominatorEnd < len(input) && input[denominatorEnd] >= '0' && input[denominatorEnd] <= '9' {
+			denominatorEnd++
+		}
+		if denominatorEnd > end+1 {
+			denominator, _ := new(big.Rat).SetString(input[end+1 : denominatorEnd])
+			if denominator.Sign() != 0 {
+				return number.Quo(number, denominator), denominatorEnd
+			}
+		}
+	}
+	return number, end
+}
+
+// scanIdentifier reads a variable name and returns it with the number of
+// bytes consumed
+func scanIdentifier(input string) (string, int) {
+	end := 0
+	for i, r := range input {
+		if unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r)) {
+			end = i + len(string(r))
+			continue
+		}
+		break
+	}
+	return input[:end], end
+}
@@ -0,0 +1,333 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// defaultPaintCoverage is the area in m² a litre of paint covers in one
+	// coat, about 400 ft² per US gallon
+	defaultPaintCoverage = 10.0
+	defaultPaintCoats    = 2
+	maxPaintCoats        = 20
+
+	defaultConcreteWaste = 5.0
+	defaultTileWaste     = 10.0
+
+	// Standard wallpaper roll, 53 cm by 10.05 m
+	defaultRollWidth  = 0.53
+	defaultRollLength = 10.05
+
+	// maxEstimateCount bounds the number of tiles, strips or rolls
+	maxEstimateCount = 1e9
+
+	// countSlack stops float noise from rounding an exact count up
+	countSlack = 1e-9
+
+	litresPerGallon = 3.785411784
+	squareFeetPerM2 = 10.763910416709722
+	cubicMPerYard   = 0.764554857984
+	cubicMPerFoot   = 0.028316846592
+)
+
+// EstimatorCalculator estimates material quantities for building and DIY
+// jobs from dimensions in any length unit of the unit converter
+type EstimatorCalculator struct {
+	units *UnitConverter
+}
+
+func NewEstimatorCalculator() *EstimatorCalculator {
+	return &EstimatorCalculator{units: NewUnitConverter()}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (ec *EstimatorCalculator) GetSupportedOperations() []string {
+	return []string{"paint", "concrete", "tiles", "wallpaper"}
+}
+
+// Calculate performs the requested estimate
+func (ec *EstimatorCalculator) Calculate(req types.EstimatorRequest) (types.EstimatorResult, error) {
+	unit := req.Unit
+	if unit == "" {
+		unit = "m"
+	}
+	metres, err := ec.metresPer("unit", unit)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if err := requireFinite("openings", req.Openings); err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if req.Openings < 0 {
+		return types.EstimatorResult{}, fmt.Errorf("openings cannot be negative")
+	}
+
+	switch req.Operation {
+	case "paint":
+		return ec.estimatePaint(req, metres)
+	case "concrete":
+		return ec.estimateConcrete(req, metres)
+	case "tiles":
+		return ec.estimateTiles(req, unit, metres)
+	case "wallpaper":
+		return ec.estimateWallpaper(req, metres)
+	default:
+		return types.EstimatorResult{}, fmt.Errorf("unsupported estimator operation: %s. Supported operations: %v", req.Operation, ec.GetSupportedOperations())
+	}
+}
+
+// estimatePaint works out the litres of paint for a surface, or for the
+// four walls of a room when a height is given
+func (ec *EstimatorCalculator) estimatePaint(req types.EstimatorRequest, metres float64) (types.EstimatorResult, error) {
+	area, err := surfaceArea(req, metres, true)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+
+	coats := req.Coats
+	if coats == 0 {
+		coats = defaultPaintCoats
+	}
+	if coats < 1 || coats > maxPaintCoats {
+		return types.EstimatorResult{}, fmt.Errorf("coats must be between 1 and %d, got %d", maxPaintCoats, coats)
+	}
+	coverage := defaultPaintCoverage
+	if req.Coverage != nil {
+		coverage = *req.Coverage
+	}
+	if err := requirePositive("coverage", coverage); err != nil {
+		return types.EstimatorResult{}, err
+	}
+
+	litres := area * float64(coats) / coverage
+	gallons := litres / litresPerGallon
+	result := areaResult(req.Operation, area)
+	result.Litres, result.Gallons = &litres, &gallons
+	return result, nil
+}
+
+// estimateConcrete works out the volume of a slab, with a waste allowance
+// for spillage and uneven ground
+func (ec *EstimatorCalculator) estimateConcrete(req types.EstimatorRequest, metres float64) (types.EstimatorResult, error) {
+	area, err := surfaceArea(req, metres, false)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if req.Thickness == nil {
+		return types.EstimatorResult{}, fmt.Errorf("concrete requires a thickness")
+	}
+	if err := requirePositive("thickness", *req.Thickness); err != nil {
+		return types.EstimatorResult{}, err
+	}
+	waste, err := wastePercent(req.WastePercent, defaultConcreteWaste)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+
+	cubicMetres := area * *req.Thickness * metres * (1 + waste/100)
+	cubicYards, cubicFeet := cubicMetres/cubicMPerYard, cubicMetres/cubicMPerFoot
+	result := areaResult(req.Operation, area)
+	result.WastePercent = &waste
+	result.VolumeM3, result.VolumeYd3, result.VolumeFt3 = &cubicMetres, &cubicYards, &cubicFeet
+	return result, nil
+}
+
+// estimateTiles counts the tiles, and boxes if given, to cover a floor or
+// wall with a waste allowance for cuts and breakage
+func (ec *EstimatorCalculator) estimateTiles(req types.EstimatorRequest, unit string, metres float64) (types.EstimatorResult, error) {
+	area, err := surfaceArea(req, metres, false)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if req.TileLength == nil || req.TileWidth == nil {
+		return types.EstimatorResult{}, fmt.Errorf("tiles requires tile_length and tile_width")
+	}
+	tileUnit := req.TileUnit
+	if tileUnit == "" {
+		tileUnit = unit
+	}
+	tileMetres, err := ec.metresPer("tile_unit", tileUnit)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if err := requirePositive("tile_length", *req.TileLength); err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if err := requirePositive("tile_width", *req.TileWidth); err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if req.TilesPerBox < 0 {
+		return types.EstimatorResult{}, fmt.Errorf("tiles_per_box cannot be negative")
+	}
+	waste, err := wastePercent(req.WastePercent, defaultTileWaste)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+
+	tileArea := *req.TileLength * *req.TileWidth * tileMetres * tileMetres
+	tiles, err := wholeCount("tiles", area*(1+waste/100)/tileArea)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+	result := areaResult(req.Operation, area)
+	result.WastePercent, result.Tiles = &waste, &tiles
+	if req.TilesPerBox > 0 {
+		boxes := (tiles + req.TilesPerBox - 1) / req.TilesPerBox
+		result.Boxes = &boxes
+	}
+	return result, nil
+}
+
+// estimateWallpaper counts the drops (strips) needed to go round a wall or
+// room and the rolls they are cut from. Openings are not deducted, as
+// strips are cut full length.
+func (ec *EstimatorCalculator) estimateWallpaper(req types.EstimatorRequest, metres float64) (types.EstimatorResult, error) {
+	if req.Area != nil {
+		return types.EstimatorResult{}, fmt.Errorf("wallpaper needs the wall length and height rather than an area")
+	}
+	if req.Length == nil || req.Height == nil {
+		return types.EstimatorResult{}, fmt.Errorf("wallpaper requires length and height")
+	}
+	if err := requirePositive("length", *req.Length); err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if err := requirePositive("height", *req.Height); err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if req.Width != nil {
+		if err := requirePositive("width", *req.Width); err != nil {
+			return types.EstimatorResult{}, err
+		}
+	}
+	if err := requireFinite("pattern_repeat", req.PatternRepeat); err != nil {
+		return types.EstimatorResult{}, err
+	}
+	if req.PatternRepeat < 0 {
+		return types.EstimatorResult{}, fmt.Errorf("pattern_repeat cannot be negative")
+	}
+
+	// The default roll is metric; given roll sizes are in the request's unit
+	rollWidth, rollLength := defaultRollWidth, defaultRollLength
+	if req.RollWidth != nil {
+		if err := requirePositive("roll_width", *req.RollWidth); err != nil {
+			return types.EstimatorResult{}, err
+		}
+		rollWidth = *req.RollWidth * metres
+	}
+	if req.RollLength != nil {
+		if err := requirePositive("roll_length", *req.RollLength); err != nil {
+			return types.EstimatorResult{}, err
+		}
+		rollLength = *req.RollLength * metres
+	}
+
+	perimeter := *req.Length * metres
+	if req.Width != nil {
+		perimeter = 2 * (*req.Length + *req.Width) * metres
+	}
+	height := *req.Height * metres
+
+	// Each strip is cut a pattern repeat longer so the pattern lines up
+	stripsPerRoll := int(math.Floor(rollLength/(height+req.PatternRepeat*metres) + countSlack))
+	if stripsPerRoll == 0 {
+		return types.EstimatorResult{}, fmt.Errorf("a roll is too short for one strip of the wall height plus the pattern repeat")
+	}
+	strips, err := wholeCount("strips", perimeter/rollWidth)
+	if err != nil {
+		return types.EstimatorResult{}, err
+	}
+	rolls := (strips + stripsPerRoll - 1) / stripsPerRoll
+
+	result := areaResult(req.Operation, perimeter*height)
+	result.Strips, result.StripsPerRoll, result.Rolls = &strips, &stripsPerRoll, &rolls
+	return result, nil
+}
+
+// metresPer returns the length in metres of one unit
+func (ec *EstimatorCalculator) metresPer(field, unit string) (float64, error) {
+	metres, ok := ec.units.registry.Factor("length", unit)
+	if !ok {
+		units, _ := ec.units.GetSupportedUnits("length")
+		return 0, fmt.Errorf("unsupported %s: %s. Supported units: %v", field, unit, units)
+	}
+	return metres, nil
+}
+
+// surfaceArea returns the area in m² less any openings, from the area or
+// the length and width. With walls set, a height turns the length and width
+// into the four walls of a room.
+func surfaceArea(req types.EstimatorRequest, metres float64, walls bool) (float64, error) {
+	var area float64
+	switch {
+	case req.Area != nil && (req.Length != nil || req.Width != nil):
+		return 0, fmt.Errorf("give either area or length and width, not both")
+	case req.Area != nil:
+		if err := requirePositive("area", *req.Area); err != nil {
+			return 0, err
+		}
+		area = *req.Area
+	case req.Length != nil && req.Width != nil:
+		if err := requirePositive("length", *req.Length); err != nil {
+			return 0, err
+		}
+		if err := requirePositive("width", *req.Width); err != nil {
+			return 0, err
+		}
+		area = *req.Length * *req.Width
+		if walls && req.Height != nil {
+			if err := requirePositive("height", *req.Height); err != nil {
+				return 0, err
+			}
+			area = 2 * (*req.Length + *req.Width) * *req.Height
+		}
+	default:
+		return 0, fmt.Errorf("%s requires an area or a length and width", req.Operation)
+	}
+
+	if req.Openings >= area {
+		return 0, fmt.Errorf("openings (%g) must be smaller than the area (%g)", req.Openings, area)
+	}
+	return (area - req.Openings) * metres * metres, nil
+}
+
+// areaResult starts a result with the area covered in m² and ft²
+func areaResult(operation string, areaM2 float64) types.EstimatorResult {
+	return types.EstimatorResult{Operation: operation, AreaM2: areaM2, AreaFt2: areaM2 * squareFeetPerM2}
+}
+
+// wastePercent returns the waste allowance, or the default when not given
+func wastePercent(waste *float64, fallback float64) (float64, error) {
+	if waste == nil {
+		return fallback, nil
+	}
+	if err := requireFinite("waste_percent", *waste); err != nil {
+		return 0, err
+	}
+	if *waste < 0 || *waste > 100 {
+		return 0, fmt.Errorf("waste_percent must be between 0 and 100, got %g", *waste)
+	}
+	return *waste, nil
+}
+
+// wholeCount rounds a quantity up to whole items
+func wholeCount(name string, quantity float64) (int, error) {
+	count := math.Ceil(quantity - countSlack)
+	if count > maxEstimateCount {
+		return 0, fmt.Errorf("too many %s: %g (maximum %g)", name, count, float64(maxEstimateCount))
+	}
+	return int(count), nil
+}
+
+// requirePositive rejects values that are not finite and greater than zero
+func requirePositive(name string, value float64) error {
+	if err := requireFinite(name, value); err != nil {
+		return err
+	}
+	if value <= 0 {
+		return fmt.Errorf("%s must be positive, got %g", name, value)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package calculator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+// operationSymbols are the infix symbols used when explaining folded operations
+var operationSymbols = map[string]string{
+	"add":      "+",
+	"subtract": "−",
+	"multiply": "×",
+	"divide":   "÷",
+}
+
+// stepNumber renders a value for a human-readable computation step
+func stepNumber(value float64) string {
+	return strconv.FormatFloat(value, 'g', 10, 64)
+}
+
+// explainBasic lists the steps that produced raw, the unrounded result of req
+func (bc *BasicCalculator) explainBasic(req types.BasicMathRequest, raw, rounded float64) []string {
+	a, b := req.Operands[0], req.Operands[1]
+	var steps []string
+
+	switch req.Operation {
+	case "add", "subtract", "multiply", "divide":
+		symbol := operationSymbols[req.Operation]
+		acc := a
+		for i := 1; i < len(req.Operands); i++ {
+			next := raw
+			if i < len(req.Operands)-1 {
+				next = bc.fold(req.Operation, acc, req.Operands[i])
+			}
+			steps = append(steps, fmt.Sprintf("%s %s %s = %s", stepNumber(acc), symbol, stepNumber(req.Operands[i]), stepNumber(next)))
+			acc = next
+		}
+	case "mod":
+		steps = append(steps,
+			fmt.Sprintf("%s ÷ %s leaves a remainder (sign follows the dividend)", stepNumber(a), stepNumber(b)),
+			fmt.Sprintf("%s mod %s = %s", stepNumber(a), stepNumber(b), stepNumber(raw)))
+	case "int_divide":
+		steps = append(steps,
+			fmt.Sprintf("%s ÷ %s = %s", stepNumber(a), stepNumber(b), stepNumber(a/b)),
+			fmt.Sprintf("Round down to an integer: floor(%s) = %s", stepNumber(a/b), stepNumber(raw)))
+	case "power":
+		steps = append(steps, fmt.Sprintf("%s ^ %s = %s", stepNumber(a), stepNumber(b), stepNumber(raw)))
+	case "nth_root":
+		steps = append(steps,
+			fmt.Sprintf("Root %s of %s is %s ^ (1/%s)", stepNumber(b), stepNumber(a), stepNumber(a), stepNumber(b)),
+			fmt.Sprintf("%s ^ (1/%s) = %s", stepNumber(a), stepNumber(b), stepNumber(raw)))
+	}
+
+	if rounded != raw {
+		steps = append(steps, fmt.Sprintf("Round to %d decimal places: %s", req.Precision, stepNumber(rounded)))
+	}
+	return steps
+}
+
+// fold applies a left-to-right operation to a single pair of operands
+func (bc *BasicCalculator) fold(operation string, a, b float64) float64 {
+	pair := []float64{a, b}
+	switch operation {
+	case "add":
+		return bc.add(pair)
+	case "subtract":
+		return bc.subtract(pair)
+	case "multiply":
+		return bc.multiply(pair)
+	default:
+		// Divisors were checked for zero before the steps are explained
+		result, _ := bc.divide(pair)
+		return result
+	}
+}
+
+// explainExpression lists the substitution and evaluation of an expression
+func (ec *ExpressionCalculator) explainExpression(req types.ExpressionRequest, result float64) []string {
+	steps := []string{fmt.Sprintf("Evaluate %s", strings.TrimSpace(req.Expression))}
+
+	if len(req.Variables) > 0 {
+		names := make([]string, 0, len(req.Variables))
+		for name := range req.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		substituted := req.Expression
+		assignments := make([]string, 0, len(names))
+		for _, name := range names {
+			value := stepNumber(req.Variables[name])
+			pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+			substituted = pattern.ReplaceAllString(substituted, value)
+			assignments = append(assignments, fmt.Sprintf("%s = %s", name, value))
+		}
+		steps = append(steps, fmt.Sprintf("Substitute %s: %s", strings.Join(assignments, ", "), strings.TrimSpace(substituted)))
+	}
+
+	return append(steps, fmt.Sprintf("Result: %s", stepNumber(result)))
+}
+
+// explainFinancial lists the formula, substituted values and result of a
+// financial calculation using the figures recorded in its breakdown
+func (fc *FinancialCalculator) explainFinancial(req types.FinancialRequest, result float64, breakdown map[string]interface{}) []string {
+	switch req.Operation {
+	case "compound_interest", "future_value":
+		n := breakdown["compounds_per_year"].(int)
+		factor := result / req.Principal
+		return []string{
+			"Formula: A = P(1 + r/n)^(n·t)",
+			fmt.Sprintf("Substitute P = %s, r = %s%%, n = %d, t = %s: A = %s × (1 + %s/%d)^(%d × %s)",
+				stepNumber(req.Principal), stepNumber(req.Rate), n, stepNumber(req.Time),
+				stepNumber(req.Principal), stepNumber(req.Rate/100), n, n, stepNumber(req.Time)),
+			fmt.Sprintf("Growth factor (1 + %s)^%s = %s", stepNumber(req.Rate/100/float64(n)), stepNumber(float64(n)*req.Time), stepNumber(factor)),
+			fmt.Sprintf("A = %s × %s = %s", stepNumber(req.Principal), stepNumber(factor), stepNumber(result)),
+			fmt.Sprintf("Interest earned: A − P = %s", stepNumber(result-req.Principal)),
+		}
+	case "simple_interest":
+		return []string{
+			"Formula: I = P·r·t",
+			fmt.Sprintf("I = %s × %s × %s = %s", stepNumber(req.Principal), stepNumber(req.Rate/100), stepNumber(req.Time), stepNumber(result)),
+			fmt.Sprintf("Final amount: P + I = %s", stepNumber(req.Principal+result)),
+		}
+	case "loan_payment":
+		n := breakdown["payments_per_year"].(int)
+		periodRate := req.Rate / 100 / float64(n)
+		payments := req.Time * float64(n)
+		return []string{
+			"Formula: PMT = P·r(1 + r)^n / ((1 + r)^n − 1)",
+			fmt.Sprintf("Periodic rate r = %s%% / 100 / %d = %s", stepNumber(req.Rate), n, stepNumber(periodRate)),
+			fmt.Sprintf("Number of payments n = %s × %d = %s", stepNumber(req.Time), n, stepNumber(payments)),
+			fmt.Sprintf("PMT = %s × %s × (1 + %s)^%s / ((1 + %s)^%s − 1) = %s",
+				stepNumber(req.Principal), stepNumber(periodRate), stepNumber(periodRate), stepNumber(payments),
+				stepNumber(periodRate), stepNumber(payments), stepNumber(result)),
+			fmt.Sprintf("Total paid: PMT × n = %s", stepNumber(breakdown["total_paid"].(float64))),
+		}
+	case "roi":
+		steps := []string{
+			"Formula: ROI = (FV − P) / P × 100",
+			fmt.Sprintf("ROI = (%s − %s) / %s × 100 = %s%%", stepNumber(req.FutureValue), stepNumber(req.Principal), stepNumber(req.Principal), stepNumber(result)),
+		}
+		if annualized, ok := breakdown["annualized_roi_percent"].(float64); ok {
+			steps = append(steps, fmt.Sprintf("Annualized: ((%s / %s)^(1/%s) − 1) × 100 = %s%%",
+				stepNumber(req.FutureValue), stepNumber(req.Principal), stepNumber(req.Time), stepNumber(annualized)))
+		}
+		return steps
+	case "present_value":
+		n := breakdown["compounds_per_year"].(int)
+		factor := breakdown["discount_factor"].(float64)
+		return []string{
+			"Formula: PV = FV / (1 + r/n)^(n·t)",
+			fmt.Sprintf("Discount factor (1 + %s/%d)^(%d × %s) = %s", stepNumber(req.Rate/100), n, n, stepNumber(req.Time), stepNumber(factor)),
+			fmt.Sprintf("PV = %s / %s = %s", stepNumber(req.FutureValue), stepNumber(factor), stepNumber(result)),
+		}
+	}
+	return nil
+}
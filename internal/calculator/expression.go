@@ -20,18 +20,115 @@ const (
 	// MaxFactorialArgument is the maximum argument for factorial function to prevent overflow
 	// 20! = 2.43e+18, which fits in float64, but 21! would exceed practical limits
 	MaxFactorialArgument = 20
+
+	// MaxArrayLength is the maximum number of elements of an array variable
+	MaxArrayLength = 10000
 )
 
-type ExpressionCalculator struct{}
+// mathFunctionNames are the functions expressions can call, which cannot be
+// used as variable or constant names
+var mathFunctionNames = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "asin": true, "acos": true, "atan": true,
+	"log": true, "ln": true, "abs": true, "sqrt": true, "pow": true, "exp": true, "factorial": true,
+}
+
+// ExpressionCalculator evaluates expressions. Named constants come from the
+// conversion registry current when it was created.
+type ExpressionCalculator struct {
+	registry *ConversionRegistry
+}
 
 func NewExpressionCalculator() *ExpressionCalculator {
-	return &ExpressionCalculator{}
+	return &ExpressionCalculator{registry: DefaultConversionRegistry()}
 }
 
 func (ec *ExpressionCalculator) Evaluate(req types.ExpressionRequest) (types.CalculationResult, error) {
+	expr, parameters, err := ec.prepare(req)
+	if err != nil {
+		return types.CalculationResult{}, err
+	}
+
+	floatResult, err := evaluatePrepared(expr, parameters)
+	if err != nil {
+		return types.CalculationResult{}, err
+	}
+
+	calcResult := types.CalculationResult{
+		Result: floatResult,
+	}
+	if req.Explain {
+		calcResult.Steps = ec.explainExpression(req, floatResult)
+	}
+
+	return calcResult, nil
+}
+
+// EvaluateArray evaluates the expression element-wise over the array
+// variables of the request, which must all have the same length. Scalar
+// variables and constants take the same value for every element.
+func (ec *ExpressionCalculator) EvaluateArray(req types.ExpressionRequest) ([]float64, error) {
+	if req.Explain {
+		return nil, fmt.Errorf("explain is not available with array variables")
+	}
+	if len(req.ArrayVariables) == 0 {
+		return nil, fmt.Errorf("at least one array variable is required")
+	}
+
+	// Iterate in name order so the first error reported is deterministic
+	names := make([]string, 0, len(req.ArrayVariables))
+	length := -1
+	for name, values := range req.ArrayVariables {
+		names = append(names, name)
+		if _, scalar := req.Variables[name]; scalar {
+			return nil, fmt.Errorf("variable %s is given both as a number and as an array", name)
+		}
+		if length >= 0 && len(values) != length {
+			return nil, fmt.Errorf("array variables must have the same length, got %d and %d", length, len(values))
+		}
+		length = len(values)
+	}
+	sort.Strings(names)
+	if length == 0 {
+		return nil, fmt.Errorf("array variables cannot be empty")
+	}
+	if length > MaxArrayLength {
+		return nil, fmt.Errorf("array variables have %d elements (maximum %d)", length, MaxArrayLength)
+	}
+
+	expr, parameters, err := ec.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if !ec.isValidVariableName(name) {
+			return nil, fmt.Errorf("invalid variable name: %s", name)
+		}
+		for i, value := range req.ArrayVariables[name] {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("invalid variable value for %s[%d]: %f", name, i, value)
+			}
+		}
+	}
+
+	// The compiled expression is reused; only the array elements change
+	results := make([]float64, length)
+	for i := range results {
+		for _, name := range names {
+			parameters[name] = req.ArrayVariables[name][i]
+		}
+		if results[i], err = evaluatePrepared(expr, parameters); err != nil {
+			return nil, fmt.Errorf("element %d (%s): %w", i, describeElement(names, req.ArrayVariables, i), err)
+		}
+	}
+	return results, nil
+}
+
+// prepare compiles the expression and collects its parameters: the
+// constants and the validated scalar variables
+func (ec *ExpressionCalculator) prepare(req types.ExpressionRequest) (*govaluate.EvaluableExpression, map[string]interface{}, error) {
 	// Validate expression
 	if strings.TrimSpace(req.Expression) == "" {
-		return types.CalculationResult{}, fmt.Errorf("expression cannot be empty")
+		return nil, nil, fmt.Errorf("expression cannot be empty")
 	}
 
 	// Prepare the expression with mathematical constants
@@ -40,37 +137,42 @@ func (ec *ExpressionCalculator) Evaluate(req types.ExpressionRequest) (types.Cal
 	// Create evaluable expression with custom functions
 	expr, err := govaluate.NewEvaluableExpressionWithFunctions(expression, ec.getMathFunctions())
 	if err != nil {
-		return types.CalculationResult{}, fmt.Errorf("invalid expression: %v", err)
+		return nil, nil, fmt.Errorf("invalid expression: %v", err)
 	}
 
 	// Prepare parameters (variables + constants)
 	parameters := make(map[string]interface{})
 
 	// Add mathematical constants
-	parameters["pi"] = math.Pi
-	parameters["e"] = math.E
-	parameters["PI"] = math.Pi
-	parameters["E"] = math.E
+	for name, value := range ec.registry.constants {
+		parameters[name] = value
+	}
 
 	// Add user-provided variables
 	if req.Variables != nil {
 		for key, value := range req.Variables {
 			// Validate variable names
 			if !ec.isValidVariableName(key) {
-				return types.CalculationResult{}, fmt.Errorf("invalid variable name: %s", key)
+				return nil, nil, fmt.Errorf("invalid variable name: %s", key)
 			}
 			// Validate variable values
 			if math.IsNaN(value) || math.IsInf(value, 0) {
-				return types.CalculationResult{}, fmt.Errorf("invalid variable value for %s: %f", key, value)
+				return nil, nil, fmt.Errorf("invalid variable value for %s: %f", key, value)
 			}
 			parameters[key] = value
 		}
 	}
 
+	return expr, parameters, nil
+}
+
+// evaluatePrepared evaluates a compiled expression and checks that the
+// result is a finite number
+func evaluatePrepared(expr *govaluate.EvaluableExpression, parameters map[string]interface{}) (float64, error) {
 	// Evaluate the expression
 	result, err := expr.Evaluate(parameters)
 	if err != nil {
-		return types.CalculationResult{}, fmt.Errorf("evaluation error: %v", err)
+		return 0, fmt.Errorf("evaluation error: %w", err)
 	}
 
 	// Convert result to float64
@@ -83,20 +185,26 @@ func (ec *ExpressionCalculator) Evaluate(req types.ExpressionRequest) (types.Cal
 	case int64:
 		floatResult = float64(v)
 	default:
-		return types.CalculationResult{}, fmt.Errorf("unexpected result type: %T", result)
+		return 0, fmt.Errorf("unexpected result type: %T", result)
 	}
 
 	// Validate result
 	if math.IsNaN(floatResult) {
-		return types.CalculationResult{}, fmt.Errorf("expression evaluation resulted in NaN")
+		return 0, fmt.Errorf("expression evaluation resulted in NaN")
 	}
 	if math.IsInf(floatResult, 0) {
-		return types.CalculationResult{}, fmt.Errorf("expression evaluation resulted in infinity")
+		return 0, fmt.Errorf("expression evaluation resulted in infinity")
 	}
+	return floatResult, nil
+}
 
-	return types.CalculationResult{
-		Result: floatResult,
-	}, nil
+// describeElement lists the array values of element i, e.g. "x=0, y=2"
+func describeElement(names []string, arrays map[string][]float64, i int) string {
+	parts := make([]string, len(names))
+	for j, name := range names {
+		parts[j] = fmt.Sprintf("%s=%g", name, arrays[name][i])
+	}
+	return strings.Join(parts, ", ")
 }
 
 // getMathFunctions returns a map of custom mathematical functions for govaluate
@@ -147,7 +255,8 @@ func (ec *ExpressionCalculator) getMathFunctions() map[string]govaluate.Expressi
 			return nil, fmt.Errorf("log function expects numeric argument")
 		}
 		if val <= 0 {
-			return nil, fmt.Errorf("log function domain error: argument must be positive")
+			return nil, types.NewCalculationError(types.ErrCodeLogDomain, val,
+				"log function domain error: argument must be positive, got %g", val)
 		}
 		return math.Log10(val), nil
 	}
@@ -161,7 +270,8 @@ func (ec *ExpressionCalculator) getMathFunctions() map[string]govaluate.Expressi
 			return nil, fmt.Errorf("ln function expects numeric argument")
 		}
 		if val <= 0 {
-			return nil, fmt.Errorf("ln function domain error: argument must be positive")
+			return nil, types.NewCalculationError(types.ErrCodeLogDomain, val,
+				"ln function domain error: argument must be positive, got %g", val)
 		}
 		return math.Log(val), nil
 	}
@@ -176,7 +286,8 @@ func (ec *ExpressionCalculator) getMathFunctions() map[string]govaluate.Expressi
 			return nil, fmt.Errorf("sqrt function expects numeric argument")
 		}
 		if val < 0 {
-			return nil, fmt.Errorf("sqrt function domain error: argument must be non-negative")
+			return nil, types.NewCalculationError(types.ErrCodeSqrtDomain, val,
+				"sqrt function domain error: argument must be non-negative, got %g", val)
 		}
 		return math.Sqrt(val), nil
 	}
@@ -192,7 +303,8 @@ func (ec *ExpressionCalculator) getMathFunctions() map[string]govaluate.Expressi
 			return nil, fmt.Errorf("pow function expects numeric arguments")
 		}
 		if base == 0 && exponent < 0 {
-			return nil, fmt.Errorf("pow function domain error: 0 raised to negative power")
+			return nil, types.NewCalculationError(types.ErrCodeDivisionByZero, base,
+				"pow function domain error: 0 raised to negative power %g", exponent)
 		}
 		result := math.Pow(base, exponent)
 		if math.IsNaN(result) || math.IsInf(result, 0) {
@@ -273,7 +385,8 @@ func (ec *ExpressionCalculator) getMathFunctions() map[string]govaluate.Expressi
 			return nil, fmt.Errorf("asin function expects numeric argument")
 		}
 		if val < -1 || val > 1 {
-			return nil, fmt.Errorf("asin function domain error: argument must be between -1 and 1")
+			return nil, types.NewCalculationError(types.ErrCodeInverseTrigDomain, val,
+				"asin function domain error: argument must be between -1 and 1, got %g", val)
 		}
 		return math.Asin(val), nil
 	}
@@ -287,7 +400,8 @@ func (ec *ExpressionCalculator) getMathFunctions() map[string]govaluate.Expressi
 			return nil, fmt.Errorf("acos function expects numeric argument")
 		}
 		if val < -1 || val > 1 {
-			return nil, fmt.Errorf("acos function domain error: argument must be between -1 and 1")
+			return nil, types.NewCalculationError(types.ErrCodeInverseTrigDomain, val,
+				"acos function domain error: argument must be between -1 and 1, got %g", val)
 		}
 		return math.Acos(val), nil
 	}
@@ -315,6 +429,27 @@ func (ec *ExpressionCalculator) preprocessExpression(expr string) string {
 
 // isValidVariableName checks if a variable name is valid
 func (ec *ExpressionCalculator) isValidVariableName(name string) bool {
+	if !isIdentifier(name) {
+		return false
+	}
+
+	// Check against reserved words
+	lower := strings.ToLower(name)
+	if mathFunctionNames[lower] {
+		return false
+	}
+	for constant := range ec.registry.constants {
+		if strings.ToLower(constant) == lower {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isIdentifier reports whether name is a letter followed by letters, digits
+// or underscores
+func isIdentifier(name string) bool {
 	if len(name) == 0 {
 		return false
 	}
@@ -333,14 +468,6 @@ func (ec *ExpressionCalculator) isValidVariableName(name string) bool {
 		}
 	}
 
-	// Check against reserved words
-	reserved := []string{"pi", "e", "PI", "E", "sin", "cos", "tan", "asin", "acos", "atan", "log", "ln", "abs", "pow", "exp", "sqrt", "factorial"}
-	for _, res := range reserved {
-		if strings.ToLower(name) == strings.ToLower(res) {
-			return false
-		}
-	}
-
 	return true
 }
 
@@ -355,6 +482,11 @@ func (ec *ExpressionCalculator) GetSupportedFunctions() []string {
 	}
 }
 
+// GetConstants returns the named constants available in expressions
+func (ec *ExpressionCalculator) GetConstants() map[string]float64 {
+	return ec.registry.Constants()
+}
+
 // GetSupportedOperators returns a list of supported operators
 func (ec *ExpressionCalculator) GetSupportedOperators() []string {
 	return []string{
@@ -409,13 +541,6 @@ func (ec *ExpressionCalculator) ExtractVariables(expression string) ([]string, e
 		return nil, fmt.Errorf("invalid expression: %v", err)
 	}
 
-	// Define built-in constants and function names that should be excluded
-	builtInIdentifiers := map[string]bool{
-		"pi": true, "PI": true, "e": true, "E": true,
-		"sin": true, "cos": true, "tan": true, "asin": true, "acos": true, "atan": true,
-		"log": true, "ln": true, "abs": true, "sqrt": true, "pow": true, "exp": true, "factorial": true,
-	}
-
 	// Regular expression to match variable names
 	// Variables must start with a letter or underscore, followed by letters, numbers, or underscores
 	variablePattern := regexp.MustCompile(`\b[a-zA-Z_][a-zA-Z0-9_]*\b`)
@@ -428,7 +553,7 @@ func (ec *ExpressionCalculator) ExtractVariables(expression string) ([]string, e
 
 	for _, match := range matches {
 		// Skip built-in identifiers
-		if builtInIdentifiers[match] {
+		if _, constant := ec.registry.constants[match]; constant || mathFunctionNames[match] {
 			continue
 		}
 
@@ -24,6 +24,18 @@ func (fc *FinancialCalculator) Calculate(req types.FinancialRequest) (types.Fina
 	var description string
 	var err error
 
+	var accrual map[string]interface{}
+	switch req.Operation {
+	case "compound_interest", "simple_interest", "loan_payment", "present_value", "future_value":
+		if accrual, err = applyDates(&req); err != nil {
+			return types.FinancialResult{}, err
+		}
+	default:
+		if req.StartDate != "" || req.EndDate != "" || req.DayCount != "" {
+			return types.FinancialResult{}, fmt.Errorf("startDate, endDate and dayCount apply only to interest, loan, present_value and future_value operations")
+		}
+	}
+
 	switch req.Operation {
 	case "compound_interest":
 		result, breakdown, err = fc.compoundInterest(req)
@@ -50,12 +62,20 @@ func (fc *FinancialCalculator) Calculate(req types.FinancialRequest) (types.Fina
 	if err != nil {
 		return types.FinancialResult{}, err
 	}
+	for key, value := range accrual {
+		breakdown[key] = value
+	}
 
-	return types.FinancialResult{
+	financialResult := types.FinancialResult{
 		Result:      result,
 		Breakdown:   breakdown,
 		Description: description,
-	}, nil
+	}
+	if req.Explain {
+		financialResult.Steps = fc.explainFinancial(req, result, breakdown)
+	}
+
+	return financialResult, nil
 }
 
 func (fc *FinancialCalculator) compoundInterest(req types.FinancialRequest) (float64, map[string]interface{}, error) {
@@ -204,6 +224,54 @@ func (fc *FinancialCalculator) loanPayment(req types.FinancialRequest) (float64,
 	return monthlyPayment, breakdown, nil
 }
 
+// MaxAmortizationPayments is the most rows an amortization schedule lists,
+// 50 years of weekly payments
+const MaxAmortizationPayments = 2600
+
+// AmortizationSchedule returns the per-period breakdown of a fixed-payment loan
+func (fc *FinancialCalculator) AmortizationSchedule(req types.FinancialRequest) ([]types.AmortizationRow, error) {
+	payment, breakdown, err := fc.loanPayment(req)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := breakdown["payments_per_year"].(int)
+	periodRate := (req.Rate / 100) / float64(periods)
+	totalPayments := int(math.Round(req.Time * float64(periods)))
+	if totalPayments < 1 {
+		return nil, fmt.Errorf("time must cover at least one payment: %g years at %d payments per year rounds to none", req.Time, periods)
+	}
+	if totalPayments > MaxAmortizationPayments {
+		return nil, fmt.Errorf("amortization schedule too long: %g years at %d payments per year is %d payments, at most %d are listed", req.Time, periods, totalPayments, MaxAmortizationPayments)
+	}
+
+	schedule := make([]types.AmortizationRow, 0, totalPayments)
+	balance := req.Principal
+	for period := 1; period <= totalPayments; period++ {
+		interest := balance * periodRate
+		principal := payment - interest
+		// Absorb rounding drift so the loan is fully repaid on the last payment
+		if period == totalPayments {
+			principal = balance
+		}
+		balance -= principal
+
+		schedule = append(schedule, types.AmortizationRow{
+			Period:    period,
+			Payment:   roundToCents(principal + interest),
+			Principal: roundToCents(principal),
+			Interest:  roundToCents(interest),
+			Balance:   roundToCents(math.Max(balance, 0)),
+		})
+	}
+
+	return schedule, nil
+}
+
+func roundToCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
 func (fc *FinancialCalculator) returnOnInvestment(req types.FinancialRequest) (float64, map[string]interface{}, error) {
 	if req.Principal <= 0 {
 		return 0, nil, fmt.Errorf("initial investment must be positive")
@@ -389,11 +457,39 @@ func (fc *FinancialCalculator) validateRequest(req types.FinancialRequest) error
 	return nil
 }
 
+// Method describes how Calculate computes an operation, for the provenance
+// of its results. dated reports whether the term comes from startDate and
+// endDate, measured by the dayCount convention.
+func (fc *FinancialCalculator) Method(operation, dayCount string, dated bool) string {
+	var method string
+	switch operation {
+	case "compound_interest", "future_value":
+		method = "discrete compounding, A = P(1 + r/n)^(nt)"
+	case "simple_interest":
+		method = "simple interest, I = P·r·t"
+	case "loan_payment":
+		method = "level annuity payment, PMT = P·r(1 + r)^n / ((1 + r)^n − 1)"
+	case "roi":
+		method = "(final − initial) / initial, annualized geometrically"
+	case "present_value":
+		method = "discounting, PV = FV / (1 + r/n)^(nt)"
+	default:
+		return ""
+	}
+	if dated {
+		if dayCount == "" {
+			dayCount = DayCountActual365
+		}
+		method += ", term by " + dayCount + " day count"
+	}
+	return method
+}
+
 // GetSupportedOperations returns a list of supported financial operations
 func (fc *FinancialCalculator) GetSupportedOperations() []string {
 	return []string{
 		"compound_interest", "simple_interest", "loan_payment",
-		"roi", "present_value", "future_value",
+		"roi", "present_value", "future_value", "solve_tvm",
 		"npv", "irr", // Additional operations
 	}
 }
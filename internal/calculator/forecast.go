@@ -0,0 +1,281 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	maxForecastSeries  = 10000
+	maxForecastHorizon = 1000
+
+	defaultForecastWindow     = 3
+	defaultForecastConfidence = 95
+)
+
+// smoothingGrid holds the candidate values tried when a Holt–Winters
+// smoothing parameter is not given
+var smoothingGrid = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+
+// Forecaster projects numeric series with naive, moving-average and
+// Holt–Winters exponential smoothing forecasts
+type Forecaster struct{}
+
+func NewForecaster() *Forecaster {
+	return &Forecaster{}
+}
+
+// GetSupportedMethods returns the methods accepted by Forecast
+func (f *Forecaster) GetSupportedMethods() []string {
+	return []string{"naive", "moving_average", "holt_winters"}
+}
+
+// Forecast returns req.Horizon point forecasts with prediction intervals of
+// ±z·RMSE·√h around the h-step forecast, where RMSE comes from the in-sample
+// one-step-ahead errors
+func (f *Forecaster) Forecast(req types.ForecastRequest) (types.ForecastResult, error) {
+	if len(req.Series) < 2 {
+		return types.ForecastResult{}, fmt.Errorf("series must have at least 2 values")
+	}
+	if len(req.Series) > maxForecastSeries {
+		return types.ForecastResult{}, fmt.Errorf("series too long: %d values (maximum %d)", len(req.Series), maxForecastSeries)
+	}
+	for i, value := range req.Series {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return types.ForecastResult{}, fmt.Errorf("series value %d must be a finite number", i)
+		}
+	}
+	if req.Horizon < 1 || req.Horizon > maxForecastHorizon {
+		return types.ForecastResult{}, fmt.Errorf("horizon must be between 1 and %d, got %d", maxForecastHorizon, req.Horizon)
+	}
+
+	confidence := req.Confidence
+	if confidence == 0 {
+		confidence = defaultForecastConfidence
+	}
+	if confidence <= 0 || confidence >= 100 {
+		return types.ForecastResult{}, fmt.Errorf("confidence must be between 0 and 100 (exclusive), got %g", req.Confidence)
+	}
+
+	season := req.SeasonLength
+	if season < 0 {
+		return types.ForecastResult{}, fmt.Errorf("season_length cannot be negative")
+	}
+	if season == 1 {
+		season = 0
+	}
+
+	var forecasts, residuals []float64
+	parameters := make(map[string]float64)
+	switch req.Method {
+	case "naive":
+		if season > 0 {
+			if len(req.Series) <= season {
+				return types.ForecastResult{}, fmt.Errorf("seasonal naive forecasts need more than one season of data (%d values)", season)
+			}
+			parameters["season_length"] = float64(season)
+		}
+		forecasts, residuals = naiveForecast(req.Series, season, req.Horizon)
+	case "moving_average":
+		window := req.Window
+		if window == 0 {
+			window = defaultForecastWindow
+		}
+		if window < 1 || window >= len(req.Series) {
+			return types.ForecastResult{}, fmt.Errorf("window must be between 1 and %d (one less than the series length), got %d", len(req.Series)-1, window)
+		}
+		parameters["window"] = float64(window)
+		forecasts, residuals = movingAverageForecast(req.Series, window, req.Horizon)
+	case "holt_winters":
+		alpha, beta, gamma, err := fitHoltWinters(req, season)
+		if err != nil {
+			return types.ForecastResult{}, err
+		}
+		parameters["alpha"], parameters["beta"] = alpha, beta
+		if season > 0 {
+			parameters["gamma"] = gamma
+			parameters["season_length"] = float64(season)
+		}
+		forecasts, residuals = holtWinters(req.Series, season, alpha, beta, gamma, req.Horizon)
+	default:
+		return types.ForecastResult{}, fmt.Errorf("unsupported forecast method: %s. Supported methods: %v", req.Method, f.GetSupportedMethods())
+	}
+
+	rmse := 0.0
+	for _, residual := range residuals {
+		rmse += residual * residual
+	}
+	rmse = math.Sqrt(rmse / float64(len(residuals)))
+
+	z := distuv.UnitNormal.Quantile(0.5 + confidence/200)
+	points := make([]types.ForecastPoint, len(forecasts))
+	for i, value := range forecasts {
+		margin := z * rmse * math.Sqrt(float64(i+1))
+		points[i] = types.ForecastPoint{Step: i + 1, Value: value, Lower: value - margin, Upper: value + margin}
+	}
+
+	result := types.ForecastResult{
+		Method:     req.Method,
+		Horizon:    req.Horizon,
+		Confidence: confidence,
+		Forecasts:  points,
+		RMSE:       rmse,
+	}
+	if len(parameters) > 0 {
+		result.Parameters = parameters
+	}
+	return result, nil
+}
+
+// naiveForecast repeats the last value, or the last season when season > 0
+func naiveForecast(series []float64, season, horizon int) ([]float64, []float64) {
+	lag := 1
+	if season > 0 {
+		lag = season
+	}
+
+	residuals := make([]float64, 0, len(series)-lag)
+	for t := lag; t < len(series); t++ {
+		residuals = append(residuals, series[t]-series[t-lag])
+	}
+
+	n := len(series)
+	forecasts := make([]float64, horizon)
+	for h := range forecasts {
+		forecasts[h] = series[n-lag+h%lag]
+	}
+	return forecasts, residuals
+}
+
+// movingAverageForecast projects the mean of the last window values
+func movingAverageForecast(series []float64, window, horizon int) ([]float64, []float64) {
+	sum := 0.0
+	for _, value := range series[:window] {
+		sum += value
+	}
+
+	residuals := make([]float64, 0, len(series)-window)
+	for t := window; t < len(series); t++ {
+		residuals = append(residuals, series[t]-sum/float64(window))
+		sum += series[t] - series[t-window]
+	}
+
+	forecasts := make([]float64, horizon)
+	for h := range forecasts {
+		forecasts[h] = sum / float64(window)
+	}
+	return forecasts, residuals
+}
+
+// fitHoltWinters validates the given smoothing parameters and chooses the
+// missing ones from smoothingGrid by least one-step-ahead squared error
+func fitHoltWinters(req types.ForecastRequest, season int) (alpha, beta, gamma float64, err error) {
+	if season > 0 && len(req.Series) < 2*season {
+		return 0, 0, 0, fmt.Errorf("seasonal holt_winters needs at least two seasons of data (%d values)", 2*season)
+	}
+	if season == 0 && len(req.Series) < 3 {
+		return 0, 0, 0, fmt.Errorf("holt_winters needs at least 3 values")
+	}
+	if season == 0 && req.Gamma != nil {
+		return 0, 0, 0, fmt.Errorf("gamma applies only to seasonal forecasts; set season_length")
+	}
+
+	candidates := func(name string, value *float64) ([]float64, error) {
+		if value == nil {
+			return smoothingGrid, nil
+		}
+		if *value < 0 || *value > 1 {
+			return nil, fmt.Errorf("%s must be between 0 and 1, got %g", name, *value)
+		}
+		return []float64{*value}, nil
+	}
+	alphas, err := candidates("alpha", req.Alpha)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	betas, err := candidates("beta", req.Beta)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	gammas := []float64{0}
+	if season > 0 {
+		if gammas, err = candidates("gamma", req.Gamma); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+
+	best := math.Inf(1)
+	for _, a := range alphas {
+		for _, b := range betas {
+			for _, g := range gammas {
+				_, residuals := holtWinters(req.Series, season, a, b, g, 0)
+				sse := 0.0
+				for _, residual := range residuals {
+					sse += residual * residual
+				}
+				if sse < best {
+					best, alpha, beta, gamma = sse, a, b, g
+				}
+			}
+		}
+	}
+	return alpha, beta, gamma, nil
+}
+
+// holtWinters runs additive Holt–Winters smoothing, or Holt's linear trend
+// method when season is 0, returning the forecasts and the one-step-ahead
+// errors. The level and trend start from the first season (or the first two
+// values) and the seasonal terms from the first season's deviations.
+func holtWinters(series []float64, season int, alpha, beta, gamma float64, horizon int) ([]float64, []float64) {
+	var level, trend float64
+	seasonal := make([]float64, len(series))
+	start := 1
+	if season > 0 {
+		first, second := 0.0, 0.0
+		for i := 0; i < season; i++ {
+			first += series[i]
+			second += series[season+i]
+		}
+		level = first / float64(season)
+		trend = (second - first) / float64(season*season)
+		for i := 0; i < season; i++ {
+			seasonal[i] = series[i] - level
+		}
+		start = season
+	} else {
+		level, trend = series[0], series[1]-series[0]
+	}
+
+	seasonalAt := func(t int) float64 {
+		if season == 0 {
+			return 0
+		}
+		return seasonal[t-season]
+	}
+
+	residuals := make([]float64, 0, len(series)-start)
+	for t := start; t < len(series); t++ {
+		residuals = append(residuals, series[t]-(level+trend+seasonalAt(t)))
+
+		previous := level
+		level = alpha*(series[t]-seasonalAt(t)) + (1-alpha)*(level+trend)
+		trend = beta*(level-previous) + (1-beta)*trend
+		if season > 0 {
+			seasonal[t] = gamma*(series[t]-level) + (1-gamma)*seasonalAt(t)
+		}
+	}
+
+	n := len(series)
+	forecasts := make([]float64, horizon)
+	for h := range forecasts {
+		forecasts[h] = level + float64(h+1)*trend
+		if season > 0 {
+			forecasts[h] += seasonal[n-season+h%season]
+		}
+	}
+	return forecasts, residuals
+}
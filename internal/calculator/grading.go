@@ -0,0 +1,211 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+// maxGradeItems bounds the categories or courses in one request
+const maxGradeItems = 1000
+
+// letterCutoffs are the lowest percentages earning each letter on the
+// common US plus/minus scale, highest first
+var letterCutoffs = []struct {
+	letter string
+	cutoff float64
+}{
+	{"A", 93}, {"A-", 90},
+	{"B+", 87}, {"B", 83}, {"B-", 80},
+	{"C+", 77}, {"C", 73}, {"C-", 70},
+	{"D+", 67}, {"D", 63}, {"D-", 60},
+}
+
+// gradePoints are the grade points of each letter on the 4.0 scale; the
+// 4.3 scale only differs in awarding 4.3 for an A+
+var gradePoints = map[string]float64{
+	"A+": 4.0, "A": 4.0, "A-": 3.7,
+	"B+": 3.3, "B": 3.0, "B-": 2.7,
+	"C+": 2.3, "C": 2.0, "C-": 1.7,
+	"D+": 1.3, "D": 1.0, "D-": 0.7,
+	"F": 0,
+}
+
+// GradingCalculator computes course grades, required final exam scores and
+// grade point averages
+type GradingCalculator struct{}
+
+func NewGradingCalculator() *GradingCalculator {
+	return &GradingCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (gc *GradingCalculator) GetSupportedOperations() []string {
+	return []string{"weighted_grade", "final_needed", "gpa"}
+}
+
+// Calculate performs the requested grade calculation
+func (gc *GradingCalculator) Calculate(req types.GradingRequest) (types.GradingResult, error) {
+	result := types.GradingResult{Operation: req.Operation}
+	switch req.Operation {
+	case "weighted_grade":
+		grade, contributions, err := weightedGrade(req.Categories)
+		if err != nil {
+			return types.GradingResult{}, err
+		}
+		result.Grade, result.Letter, result.Contributions = &grade, letterGrade(grade), contributions
+	case "final_needed":
+		return finalNeeded(req, result)
+	case "gpa":
+		return gradePointAverage(req, result)
+	default:
+		return types.GradingResult{}, fmt.Errorf("unsupported grading operation: %s. Supported operations: %v", req.Operation, gc.GetSupportedOperations())
+	}
+	return result, nil
+}
+
+// letterGrade returns the letter earned by a percentage grade
+func letterGrade(grade float64) string {
+	for _, cutoff := range letterCutoffs {
+		if grade >= cutoff.cutoff {
+			return cutoff.letter
+		}
+	}
+	return "F"
+}
+
+// weightedGrade averages the category scores by their relative weights
+func weightedGrade(categories []types.GradeCategory) (float64, []types.GradeContribution, error) {
+	if len(categories) == 0 {
+		return 0, nil, fmt.Errorf("at least one category is required")
+	}
+	if len(categories) > maxGradeItems {
+		return 0, nil, fmt.Errorf("too many categories: %d (maximum %d)", len(categories), maxGradeItems)
+	}
+
+	scores := make([]float64, len(categories))
+	totalWeight := 0.0
+	for i, category := range categories {
+		name := category.Name
+		if name == "" {
+			name = fmt.Sprintf("category %d", i)
+		}
+		switch {
+		case category.Score != nil:
+			scores[i] = *category.Score
+		case category.Possible > 0:
+			scores[i] = category.Earned / category.Possible * 100
+		default:
+			return 0, nil, fmt.Errorf("%s needs a score or earned and possible points", name)
+		}
+		if err := requireFinite(name+" score", scores[i]); err != nil {
+			return 0, nil, err
+		}
+		if scores[i] < 0 {
+			return 0, nil, fmt.Errorf("%s score cannot be negative", name)
+		}
+		if err := requireFinite(name+" weight", category.Weight); err != nil {
+			return 0, nil, err
+		}
+		if category.Weight < 0 {
+			return 0, nil, fmt.Errorf("%s weight cannot be negative", name)
+		}
+		totalWeight += category.Weight
+	}
+	if totalWeight == 0 {
+		return 0, nil, fmt.Errorf("category weights must not all be zero")
+	}
+
+	grade := 0.0
+	contributions := make([]types.GradeContribution, len(categories))
+	for i, category := range categories {
+		weight := category.Weight / totalWeight
+		contributions[i] = types.GradeContribution{
+			Name:         category.Name,
+			Score:        scores[i],
+			Weight:       weight * 100,
+			Contribution: scores[i] * weight,
+		}
+		grade += scores[i] * weight
+	}
+	return grade, contributions, nil
+}
+
+// finalNeeded solves target = current·(1 − w) + final·w for the final exam
+// score, where w is the final's share of the course grade
+func finalNeeded(req types.GradingRequest, result types.GradingResult) (types.GradingResult, error) {
+	var current float64
+	switch {
+	case req.CurrentGrade != nil && len(req.Categories) > 0:
+		return types.GradingResult{}, fmt.Errorf("give either current_grade or categories, not both")
+	case req.CurrentGrade != nil:
+		current = *req.CurrentGrade
+		if err := requireFinite("current_grade", current); err != nil {
+			return types.GradingResult{}, err
+		}
+	case len(req.Categories) > 0:
+		var err error
+		if current, _, err = weightedGrade(req.Categories); err != nil {
+			return types.GradingResult{}, err
+		}
+	default:
+		return types.GradingResult{}, fmt.Errorf("final_needed requires current_grade or the categories graded so far")
+	}
+	if req.FinalWeight <= 0 || req.FinalWeight > 100 {
+		return types.GradingResult{}, fmt.Errorf("final_weight must be a percentage above 0 and at most 100, got %g", req.FinalWeight)
+	}
+	if err := requireFinite("target_grade", req.TargetGrade); err != nil {
+		return types.GradingResult{}, err
+	}
+	if req.TargetGrade <= 0 {
+		return types.GradingResult{}, fmt.Errorf("target_grade must be positive")
+	}
+
+	weight := req.FinalWeight / 100
+	needed := (req.TargetGrade - current*(1-weight)) / weight
+	achievable := needed <= 100
+	result.CurrentGrade, result.Needed, result.Achievable = &current, &needed, &achievable
+	return result, nil
+}
+
+// gradePointAverage averages the grade points of letter grades weighted by
+// course credits
+func gradePointAverage(req types.GradingRequest, result types.GradingResult) (types.GradingResult, error) {
+	if len(req.Courses) == 0 {
+		return types.GradingResult{}, fmt.Errorf("gpa requires at least one course")
+	}
+	if len(req.Courses) > maxGradeItems {
+		return types.GradingResult{}, fmt.Errorf("too many courses: %d (maximum %d)", len(req.Courses), maxGradeItems)
+	}
+	if req.Scale != "" && req.Scale != "4.0" && req.Scale != "4.3" {
+		return types.GradingResult{}, fmt.Errorf("unsupported scale: %s. Supported scales: [4.0 4.3]", req.Scale)
+	}
+
+	totalPoints, totalCredits := 0.0, 0.0
+	for i, course := range req.Courses {
+		letter := strings.ToUpper(strings.TrimSpace(course.Grade))
+		points, ok := gradePoints[letter]
+		if !ok {
+			return types.GradingResult{}, fmt.Errorf("course %d has unknown grade %q; use A+ to F", i, course.Grade)
+		}
+		if letter == "A+" && req.Scale == "4.3" {
+			points = 4.3
+		}
+
+		credits := 1.0
+		if course.Credits != nil {
+			credits = *course.Credits
+		}
+		if credits <= 0 || math.IsNaN(credits) || math.IsInf(credits, 0) {
+			return types.GradingResult{}, fmt.Errorf("course %d credits must be a positive number; leave out courses that carry no credit", i)
+		}
+		totalPoints += points * credits
+		totalCredits += credits
+	}
+
+	gpa := totalPoints / totalCredits
+	result.GPA, result.Credits, result.GradePoints = &gpa, &totalCredits, &totalPoints
+	return result, nil
+}
@@ -0,0 +1,252 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+// inequalityOperators are matched longest first so "<=" is not read as "<"
+var inequalityOperators = []string{"<=", ">=", "≤", "≥", "<", ">"}
+
+// InequalitySolver solves linear and quadratic inequalities in one variable
+type InequalitySolver struct{}
+
+func NewInequalitySolver() *InequalitySolver {
+	return &InequalitySolver{}
+}
+
+// interval is a piece of the real line used while assembling the solution
+type interval struct {
+	lower, upper             float64 // ±Inf when unbounded
+	lowerClosed, upperClosed bool
+}
+
+// Solve solves an inequality such as "x^2 - 5x + 6 >= 0"
+func (is *InequalitySolver) Solve(req types.InequalityRequest) (types.InequalityResult, error) {
+	operator, left, right, err := splitInequality(req.Inequality)
+	if err != nil {
+		return types.InequalityResult{}, err
+	}
+
+	relation, err := parseRelation(left, right)
+	if err != nil {
+		return types.InequalityResult{}, err
+	}
+
+	variable, a, b, err := quadraticCoefficients(relation)
+	if err != nil {
+		return types.InequalityResult{}, err
+	}
+	c, _ := relation.constant.Float64()
+	c = -c // a·x² + b·x + c compared with 0
+
+	polynomial := func(x float64) float64 { return (a*x+b)*x + c }
+	satisfies := func(value float64) bool {
+		switch operator {
+		case "<":
+			return value < 0
+		case "<=":
+			return value <= 0
+		case ">":
+			return value > 0
+		default:
+			return value >= 0
+		}
+	}
+
+	roots := polynomialRoots(a, b, c)
+	intervals := assembleIntervals(roots, func(x float64) bool { return satisfies(polynomial(x)) }, strings.Contains(operator, "="))
+
+	result := types.InequalityResult{
+		Variable:  variable,
+		Notation:  intervalNotation(intervals),
+		Intervals: make([]types.Interval, len(intervals)),
+	}
+	for i, iv := range intervals {
+		result.Intervals[i] = types.Interval{
+			LowerClosed: iv.lowerClosed,
+			UpperClosed: iv.upperClosed,
+		}
+		if !math.IsInf(iv.lower, 0) {
+			lower := iv.lower
+			result.Intervals[i].Lower = &lower
+		}
+		if !math.IsInf(iv.upper, 0) {
+			upper := iv.upper
+			result.Intervals[i].Upper = &upper
+		}
+	}
+	return result, nil
+}
+
+// splitInequality returns the normalised operator and both sides
+func splitInequality(text string) (string, string, string, error) {
+	for _, operator := range inequalityOperators {
+		index := strings.Index(text, operator)
+		if index < 0 {
+			continue
+		}
+		left, right := text[:index], text[index+len(operator):]
+		for _, other := range inequalityOperators {
+			if strings.Contains(right, other) {
+				return "", "", "", fmt.Errorf("chained inequalities are not supported")
+			}
+		}
+		switch operator {
+		case "≤":
+			operator = "<="
+		case "≥":
+			operator = ">="
+		}
+		return operator, left, right, nil
+	}
+	return "", "", "", fmt.Errorf("expected one of <, <=, >, >= in %q", text)
+}
+
+// quadraticCoefficients extracts a and b of a·x² + b·x from the relation's
+// terms, rejecting other powers and more than one variable
+func quadraticCoefficients(relation linearEquation) (string, float64, float64, error) {
+	variable := ""
+	var a, b float64
+	for term, coefficient := range relation.coefficients {
+		name, power := term, "1"
+		if index := strings.Index(term, "^"); index >= 0 {
+			name, power = term[:index], term[index+1:]
+		}
+		if variable != "" && name != variable {
+			return "", 0, 0, fmt.Errorf("only inequalities in one variable are supported, found %s and %s", variable, name)
+		}
+		variable = name
+
+		value, _ := coefficient.Float64()
+		switch power {
+		case "1":
+			b = value
+		case "2":
+			a = value
+		default:
+			return "", 0, 0, fmt.Errorf("only linear and quadratic inequalities are supported, found %s", term)
+		}
+	}
+	if variable == "" {
+		variable = "x"
+	}
+	return variable, a, b, nil
+}
+
+// polynomialRoots returns the distinct real roots of a·x² + b·x + c in
+// ascending order
+func polynomialRoots(a, b, c float64) []float64 {
+	switch {
+	case a == 0 && b == 0:
+		return nil
+	case a == 0:
+		return []float64{-c/b + 0}
+	}
+
+	discriminant := b*b - 4*a*c
+	switch {
+	case discriminant < 0:
+		return nil
+	case discriminant == 0:
+		// Adding zero turns -0 into 0
+		return []float64{-b/(2*a) + 0}
+	}
+
+	// Numerically stable form avoiding cancellation between -b and √Δ
+	q := -0.5 * (b + math.Copysign(math.Sqrt(discriminant), b))
+	roots := []float64{q/a + 0, c/q + 0}
+	sort.Float64s(roots)
+	return roots
+}
+
+// assembleIntervals tests each gap between consecutive roots and the roots
+// themselves, then merges neighbouring pieces that satisfy the inequality
+func assembleIntervals(roots []float64, satisfied func(float64) bool, includeRoots bool) []interval {
+	var pieces []interval
+	var included []bool
+
+	lower := math.Inf(-1)
+	for _, root := range roots {
+		pieces = append(pieces, interval{lower: lower, upper: root})
+		included = append(included, satisfied(samplePoint(lower, root)))
+		pieces = append(pieces, interval{lower: root, upper: root, lowerClosed: true, upperClosed: true})
+		included = append(included, includeRoots)
+		lower = root
+	}
+	pieces = append(pieces, interval{lower: lower, upper: math.Inf(1)})
+	included = append(included, satisfied(samplePoint(lower, math.Inf(1))))
+
+	var intervals []interval
+	var current *interval
+	for i, piece := range pieces {
+		if !included[i] {
+			if current != nil {
+				intervals = append(intervals, *current)
+				current = nil
+			}
+			continue
+		}
+		if current == nil {
+			current = &interval{lower: piece.lower, lowerClosed: piece.lowerClosed}
+		}
+		current.upper, current.upperClosed = piece.upper, piece.upperClosed
+	}
+	if current != nil {
+		intervals = append(intervals, *current)
+	}
+	return intervals
+}
+
+func samplePoint(lower, upper float64) float64 {
+	switch {
+	case math.IsInf(lower, -1) && math.IsInf(upper, 1):
+		return 0
+	case math.IsInf(lower, -1):
+		return upper - 1
+	case math.IsInf(upper, 1):
+		return lower + 1
+	default:
+		return (lower + upper) / 2
+	}
+}
+
+// intervalNotation renders intervals such as "(-∞, 2) ∪ [3, ∞)"
+func intervalNotation(intervals []interval) string {
+	if len(intervals) == 0 {
+		return "∅"
+	}
+
+	parts := make([]string, len(intervals))
+	for i, iv := range intervals {
+		if iv.lower == iv.upper {
+			parts[i] = "{" + formatEndpoint(iv.lower) + "}"
+			continue
+		}
+		opening, closing := "(", ")"
+		if iv.lowerClosed {
+			opening = "["
+		}
+		if iv.upperClosed {
+			closing = "]"
+		}
+		parts[i] = opening + formatEndpoint(iv.lower) + ", " + formatEndpoint(iv.upper) + closing
+	}
+	return strings.Join(parts, " ∪ ")
+}
+
+func formatEndpoint(value float64) string {
+	switch {
+	case math.IsInf(value, -1):
+		return "-∞"
+	case math.IsInf(value, 1):
+		return "∞"
+	default:
+		return strconv.FormatFloat(value, 'g', 12, 64)
+	}
+}
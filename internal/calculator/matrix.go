@@ -0,0 +1,248 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// maxMatrixDimension bounds the rows and columns accepted by the matrix tool
+	maxMatrixDimension = 50
+
+	// illConditioned is the condition number above which results are flagged
+	// as inaccurate; beyond numericallySingular they are meaningless
+	illConditioned      = 1e12
+	numericallySingular = 1 / 2.220446049250313e-16
+
+	// symmetryTolerance is the relative difference allowed between A[i][j]
+	// and A[j][i] for a matrix to count as symmetric
+	symmetryTolerance = 1e-10
+)
+
+// MatrixCalculator performs dense matrix operations and decompositions
+type MatrixCalculator struct{}
+
+func NewMatrixCalculator() *MatrixCalculator {
+	return &MatrixCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (mc *MatrixCalculator) GetSupportedOperations() []string {
+	return []string{"determinant", "inverse", "lu", "qr", "cholesky", "least_squares"}
+}
+
+// Calculate applies the requested operation to req.Matrix
+func (mc *MatrixCalculator) Calculate(req types.MatrixRequest) (types.MatrixResult, error) {
+	a, err := denseFromRows(req.Matrix)
+	if err != nil {
+		return types.MatrixResult{}, err
+	}
+	rows, columns := a.Dims()
+
+	result := types.MatrixResult{Operation: req.Operation, Rows: rows, Columns: columns}
+	switch req.Operation {
+	case "determinant":
+		if err := requireSquare(req.Operation, rows, columns); err != nil {
+			return types.MatrixResult{}, err
+		}
+		determinant := mat.Det(a)
+		result.Determinant = &determinant
+	case "inverse":
+		if err := requireSquare(req.Operation, rows, columns); err != nil {
+			return types.MatrixResult{}, err
+		}
+		var inverse mat.Dense
+		if err := inverse.Inverse(a); err != nil && !isConditionWarning(err) {
+			return types.MatrixResult{}, fmt.Errorf("matrix is singular and has no inverse")
+		}
+		result.Inverse = rowsFromMatrix(&inverse)
+	case "lu":
+		if err := requireSquare(req.Operation, rows, columns); err != nil {
+			return types.MatrixResult{}, err
+		}
+		var lu mat.LU
+		lu.Factorize(a)
+		result.Factors = luFactors(&lu, rows)
+		determinant := lu.Det()
+		result.Determinant = &determinant
+	case "qr":
+		if rows < columns {
+			return types.MatrixResult{}, fmt.Errorf("qr requires at least as many rows as columns, got %dx%d", rows, columns)
+		}
+		var qr mat.QR
+		qr.Factorize(a)
+		var q, r mat.Dense
+		qr.QTo(&q)
+		qr.RTo(&r)
+		result.Factors = map[string][][]float64{"Q": rowsFromMatrix(&q), "R": rowsFromMatrix(&r)}
+	case "cholesky":
+		if err := requireSquare(req.Operation, rows, columns); err != nil {
+			return types.MatrixResult{}, err
+		}
+		if !isSymmetric(a) {
+			return types.MatrixResult{}, fmt.Errorf("cholesky requires a symmetric matrix")
+		}
+		var cholesky mat.Cholesky
+		if ok := cholesky.Factorize(symmetricFrom(a)); !ok {
+			return types.MatrixResult{}, fmt.Errorf("cholesky requires a positive definite matrix")
+		}
+		var l mat.TriDense
+		cholesky.LTo(&l)
+		result.Factors = map[string][][]float64{"L": rowsFromMatrix(&l)}
+		determinant := cholesky.Det()
+		result.Determinant = &determinant
+	case "least_squares":
+		solution, residual, err := leastSquares(a, req.Vector)
+		if err != nil {
+			return types.MatrixResult{}, err
+		}
+		result.Solution = solution
+		result.ResidualNorm = &residual
+	default:
+		return types.MatrixResult{}, fmt.Errorf("unsupported matrix operation: %s. Supported operations: %v", req.Operation, mc.GetSupportedOperations())
+	}
+
+	// Every operation reports how well conditioned its input was
+	condition := mat.Cond(a, 2)
+	switch {
+	case math.IsInf(condition, 0) || math.IsNaN(condition):
+		result.Warnings = append(result.Warnings, "matrix is singular; results are not reliable")
+	case condition > numericallySingular:
+		result.ConditionNumber = &condition
+		result.Warnings = append(result.Warnings, fmt.Sprintf("matrix is numerically singular (condition number %.3g); results are not reliable", condition))
+	case condition > illConditioned:
+		result.ConditionNumber = &condition
+		result.Warnings = append(result.Warnings, fmt.Sprintf("matrix is ill-conditioned (condition number %.3g); results may be inaccurate", condition))
+	default:
+		result.ConditionNumber = &condition
+	}
+
+	return result, nil
+}
+
+// leastSquares minimises ‖Ax − b‖ through a QR factorization of A
+func leastSquares(a *mat.Dense, b []float64) ([]float64, float64, error) {
+	rows, columns := a.Dims()
+	if rows < columns {
+		return nil, 0, fmt.Errorf("least_squares requires at least as many rows (equations) as columns (unknowns), got %dx%d", rows, columns)
+	}
+	if len(b) != rows {
+		return nil, 0, fmt.Errorf("least_squares requires a vector of length %d to match the matrix rows, got %d", rows, len(b))
+	}
+	for i, value := range b {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, 0, fmt.Errorf("vector element %d must be a finite number", i)
+		}
+	}
+
+	var qr mat.QR
+	qr.Factorize(a)
+	bVec := mat.NewVecDense(rows, append([]float64(nil), b...))
+	var x mat.VecDense
+	if err := qr.SolveVecTo(&x, false, bVec); err != nil && !isConditionWarning(err) {
+		return nil, 0, fmt.Errorf("least_squares requires linearly independent columns: %v", err)
+	}
+
+	var residual mat.VecDense
+	residual.MulVec(a, &x)
+	residual.SubVec(&residual, bVec)
+
+	return append([]float64(nil), x.RawVector().Data...), mat.Norm(&residual, 2), nil
+}
+
+// luFactors returns the factors of A = P·L·U
+func luFactors(lu *mat.LU, n int) map[string][][]float64 {
+	var l, u mat.TriDense
+	lu.LTo(&l)
+	lu.UTo(&u)
+
+	p := make([][]float64, n)
+	for i := range p {
+		p[i] = make([]float64, n)
+	}
+	for row, column := range lu.Pivot(nil) {
+		p[row][column] = 1
+	}
+
+	return map[string][][]float64{"P": p, "L": rowsFromMatrix(&l), "U": rowsFromMatrix(&u)}
+}
+
+// isConditionWarning reports whether err only flags an ill-conditioned matrix;
+// gonum still returns a result in that case
+func isConditionWarning(err error) bool {
+	var condition mat.Condition
+	return errors.As(err, &condition) && !math.IsInf(float64(condition), 1)
+}
+
+func requireSquare(operation string, rows, columns int) error {
+	if rows != columns {
+		return fmt.Errorf("%s requires a square matrix, got %dx%d", operation, rows, columns)
+	}
+	return nil
+}
+
+func isSymmetric(a *mat.Dense) bool {
+	n, _ := a.Dims()
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			x, y := a.At(i, j), a.At(j, i)
+			if math.Abs(x-y) > symmetryTolerance*math.Max(1, math.Max(math.Abs(x), math.Abs(y))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func symmetricFrom(a *mat.Dense) *mat.SymDense {
+	n, _ := a.Dims()
+	sym := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			sym.SetSym(i, j, a.At(i, j))
+		}
+	}
+	return sym
+}
+
+// denseFromRows validates a row-major matrix and copies it into a mat.Dense
+func denseFromRows(rows [][]float64) (*mat.Dense, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("matrix must have at least one row and one column")
+	}
+	columns := len(rows[0])
+	if len(rows) > maxMatrixDimension || columns > maxMatrixDimension {
+		return nil, fmt.Errorf("matrix too large: %dx%d (maximum %dx%d)", len(rows), columns, maxMatrixDimension, maxMatrixDimension)
+	}
+
+	data := make([]float64, 0, len(rows)*columns)
+	for i, row := range rows {
+		if len(row) != columns {
+			return nil, fmt.Errorf("matrix row %d has %d columns, expected %d", i, len(row), columns)
+		}
+		for j, value := range row {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("matrix element [%d][%d] must be a finite number", i, j)
+			}
+		}
+		data = append(data, row...)
+	}
+	return mat.NewDense(len(rows), columns, data), nil
+}
+
+func rowsFromMatrix(m mat.Matrix) [][]float64 {
+	r, c := m.Dims()
+	rows := make([][]float64, r)
+	for i := range rows {
+		rows[i] = make([]float64, c)
+		for j := range rows[i] {
+			rows[i][j] = m.At(i, j)
+		}
+	}
+	return rows
+}
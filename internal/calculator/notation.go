@@ -0,0 +1,94 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Output formats for numeric results
+const (
+	OutputPlain       = "plain"
+	OutputEngineering = "engineering"
+)
+
+// siPrefixes maps engineering exponents to SI prefixes. The micro sign
+// matches the one used by the unit registry ("μm").
+var siPrefixes = map[int]string{
+	-24: "y", -21: "z", -18: "a", -15: "f", -12: "p", -9: "n", -6: "μ", -3: "m",
+	0: "", 3: "k", 6: "M", 9: "G", 12: "T", 15: "P", 18: "E", 21: "Z", 24: "Y",
+}
+
+// engineeringDigits is the number of significant digits in engineering output
+const engineeringDigits = 4
+
+// baseUnits are the unprefixed units of categories whose units take SI prefixes
+var baseUnits = map[string]string{
+	"length": "m",
+	"weight": "g",
+	"volume": "l",
+}
+
+// ValidateFormat checks that format names a supported output format
+func ValidateFormat(format string) error {
+	if format == "" || format == OutputPlain || format == OutputEngineering {
+		return nil
+	}
+	return fmt.Errorf("unsupported format: %s. Supported formats: [%s %s]", format, OutputPlain, OutputEngineering)
+}
+
+// FormatEngineering renders value in engineering notation, with an exponent
+// that is a multiple of three expressed as an SI prefix on symbol, e.g.
+// 0.000047 with symbol "F" becomes "47 μF". Values beyond the SI prefix
+// range fall back to an explicit exponent.
+func FormatEngineering(value float64, symbol string) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return joinSymbol(strconv.FormatFloat(value, 'f', -1, 64), symbol)
+	}
+	if value == 0 {
+		return joinSymbol("0", symbol)
+	}
+
+	exponent := int(math.Floor(math.Log10(math.Abs(value))/3)) * 3
+	mantissa := roundSignificant(value/math.Pow10(exponent), engineeringDigits)
+	// Rounding can carry the mantissa to 1000, e.g. 999.99 → 1000
+	if math.Abs(mantissa) >= 1000 {
+		exponent += 3
+		mantissa /= 1000
+	}
+
+	digits := strconv.FormatFloat(mantissa, 'f', -1, 64)
+	prefix, ok := siPrefixes[exponent]
+	if !ok {
+		return joinSymbol(fmt.Sprintf("%se%d", digits, exponent), symbol)
+	}
+	return joinSymbol(digits, prefix+symbol)
+}
+
+// EngineeringString renders a value of a unit from the registry in
+// engineering notation. Prefixable units are first reduced to their base
+// unit, so 0.047 km is shown as "47 m"; other units keep their symbol.
+func (uc *UnitConverter) EngineeringString(value float64, unit, category string) string {
+	base, prefixable := baseUnits[category]
+	if !prefixable {
+		return FormatEngineering(value, unit)
+	}
+
+	baseValue, err := uc.convertGeneric(value, unit, base, category)
+	if err != nil {
+		return FormatEngineering(value, unit)
+	}
+	return FormatEngineering(baseValue, base)
+}
+
+func roundSignificant(value float64, digits int) float64 {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'g', digits, 64), 64)
+	return rounded
+}
+
+func joinSymbol(number, symbol string) string {
+	if symbol == "" {
+		return number
+	}
+	return number + " " + symbol
+}
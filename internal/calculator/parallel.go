@@ -0,0 +1,216 @@
+package calculator
+
+import (
+	"math"
+	"runtime"
+	"sync"
+)
+
+const (
+	// defaultParallelThreshold is the dataset size from which statistics
+	// are computed in parallel; below it the goroutines cost more than
+	// they save
+	defaultParallelThreshold = 100000
+
+	// quantileBuckets is the number of value buckets parallelQuantiles
+	// counts points into
+	quantileBuckets = 4096
+)
+
+// SetParallelism sets the number of goroutines that compute the mean,
+// variance and unweighted percentiles of datasets of at least threshold
+// points. Zero workers uses every CPU and one disables parallelism; a zero
+// threshold keeps the default of 100,000 points. Parallel sums add the
+// points in a different order, so results may differ from serial ones in
+// the last digits.
+func (sc *StatisticsCalculator) SetParallelism(workers, threshold int) {
+	sc.workers, sc.parallelThreshold = workers, threshold
+}
+
+// workersFor returns the number of goroutines to use for n points, 1 when
+// they are computed serially
+func (sc *StatisticsCalculator) workersFor(n int) int {
+	threshold := sc.parallelThreshold
+	if threshold <= 0 {
+		threshold = defaultParallelThreshold
+	}
+	if n < threshold {
+		return 1
+	}
+	workers := sc.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	return workers
+}
+
+// inChunks splits [0, n) into one contiguous chunk per worker and runs fn on
+// each concurrently, passing the chunk's index
+func inChunks(n, workers int, fn func(chunk, lo, hi int)) {
+	var wg sync.WaitGroup
+	for chunk := 0; chunk < workers; chunk++ {
+		lo, hi := chunk*n/workers, (chunk+1)*n/workers
+		wg.Add(1)
+		go func(chunk, lo, hi int) {
+			defer wg.Done()
+			fn(chunk, lo, hi)
+		}(chunk, lo, hi)
+	}
+	wg.Wait()
+}
+
+// parallelMean is stat.Mean summed by several goroutines
+func parallelMean(data, weights []float64, workers int) float64 {
+	sums := make([]float64, workers)
+	totals := make([]float64, workers)
+	inChunks(len(data), workers, func(chunk, lo, hi int) {
+		var sum, total float64
+		for i := lo; i < hi; i++ {
+			if weights == nil {
+				sum += data[i]
+			} else {
+				sum += weights[i] * data[i]
+				total += weights[i]
+			}
+		}
+		sums[chunk], totals[chunk] = sum, total
+	})
+
+	var sum, total float64
+	for chunk := range sums {
+		sum += sums[chunk]
+		total += totals[chunk]
+	}
+	if weights == nil {
+		total = float64(len(data))
+	}
+	return sum / total
+}
+
+// parallelVariance is stat.Variance, or stat.PopVariance for a population,
+// computed by several goroutines with the same corrected two-pass algorithm
+func parallelVariance(data, weights []float64, population bool, workers int) float64 {
+	mean := parallelMean(data, weights, workers)
+
+	squares := make([]float64, workers)
+	compensations := make([]float64, workers)
+	totals := make([]float64, workers)
+	inChunks(len(data), workers, func(chunk, lo, hi int) {
+		var ss, compensation, total float64
+		for i := lo; i < hi; i++ {
+			d := data[i] - mean
+			w := 1.0
+			if weights != nil {
+				w = weights[i]
+			}
+			ss += w * d * d
+			compensation += w * d
+			total += w
+		}
+		squares[chunk], compensations[chunk], totals[chunk] = ss, compensation, total
+	})
+
+	var ss, compensation, total float64
+	for chunk := range squares {
+		ss += squares[chunk]
+		compensation += compensations[chunk]
+		total += totals[chunk]
+	}
+	unnormalised := ss - compensation*compensation/total
+	if population {
+		return unnormalised / total
+	}
+	return unnormalised / (total - 1)
+}
+
+// parallelQuantiles returns the empirical quantiles of unweighted data, as
+// unweightedQuantiles does, without one goroutine walking all of it. The
+// workers count their points into buckets of equal width between the
+// minimum and maximum, which orders the buckets; only the points of the
+// bucket holding each quantile are then gathered and searched.
+func parallelQuantiles(data []float64, workers int, ps ...float64) []float64 {
+	minimums := make([]float64, workers)
+	maximums := make([]float64, workers)
+	inChunks(len(data), workers, func(chunk, lo, hi int) {
+		minimum, maximum := data[lo], data[lo]
+		for _, value := range data[lo:hi] {
+			if value < minimum {
+				minimum = value
+			}
+			if value > maximum {
+				maximum = value
+			}
+		}
+		minimums[chunk], maximums[chunk] = minimum, maximum
+	})
+	minimum, maximum := minimums[0], maximums[0]
+	for chunk := range minimums {
+		if minimums[chunk] < minimum {
+			minimum = minimums[chunk]
+		}
+		if maximums[chunk] > maximum {
+			maximum = maximums[chunk]
+		}
+	}
+
+	if math.IsInf(maximum-minimum, 0) {
+		return unweightedQuantiles(data, ps...)
+	}
+	quantiles := make([]float64, len(ps))
+	if minimum == maximum {
+		for i := range quantiles {
+			quantiles[i] = minimum
+		}
+		return quantiles
+	}
+
+	// The bucket of a value never decreases as the value grows
+	scale := quantileBuckets / (maximum - minimum)
+	bucketOf := func(value float64) int {
+		if bucket := int((value - minimum) * scale); bucket < quantileBuckets {
+			return bucket
+		}
+		return quantileBuckets - 1
+	}
+	counts := make([][]int, workers)
+	inChunks(len(data), workers, func(chunk, lo, hi int) {
+		counts[chunk] = make([]int, quantileBuckets)
+		for _, value := range data[lo:hi] {
+			counts[chunk][bucketOf(value)]++
+		}
+	})
+	totals := make([]int, quantileBuckets)
+	for _, chunkCounts := range counts {
+		for bucket, count := range chunkCounts {
+			totals[bucket] += count
+		}
+	}
+
+	for i, p := range ps {
+		// Find the bucket holding the kth smallest point and its rank there
+		k := empiricalIndex(p, len(data))
+		bucket := 0
+		for k >= totals[bucket] {
+			k -= totals[bucket]
+			bucket++
+		}
+
+		gathered := make([][]float64, workers)
+		inChunks(len(data), workers, func(chunk, lo, hi int) {
+			for _, value := range data[lo:hi] {
+				if bucketOf(value) == bucket {
+					gathered[chunk] = append(gathered[chunk], value)
+				}
+			}
+		})
+		candidates := make([]float64, 0, totals[bucket])
+		for _, values := range gathered {
+			candidates = append(candidates, values...)
+		}
+		quantiles[i] = selectKth(candidates, k)
+	}
+	return quantiles
+}
@@ -0,0 +1,903 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxPolynomialTerms bounds the terms of an expanded polynomial
+	maxPolynomialTerms = 1000
+
+	// maxPolynomialDegree bounds the power of any one symbol
+	maxPolynomialDegree = 1000
+
+	// maxExpandedDegree bounds the total degree of an expanded polynomial,
+	// checked before multiplying
+	maxExpandedDegree = 200
+
+	// maxPolynomialProducts bounds the term products of one multiplication
+	maxPolynomialProducts = 100000
+
+	// maxGCDWork bounds the work of one greatest common divisor, estimated
+	// from the coefficient products and their size in words
+	maxGCDWork = 50000000
+
+	// maxRootCoefficient bounds the constant and leading coefficients whose
+	// divisors are tried as rational roots
+	maxRootCoefficient = 1e12
+
+	// maxRootCandidates bounds the rational roots tried per polynomial
+	maxRootCandidates = 10000
+)
+
+// symbolPower is a symbol raised to a positive power
+type symbolPower struct {
+	name  string
+	power int
+}
+
+// monomial is a product of symbol powers, sorted by symbol name
+type monomial []symbolPower
+
+// polyTerm is a coefficient times a monomial
+type polyTerm struct {
+	mono  monomial
+	coeff *big.Rat
+}
+
+// polynomial is a sum of terms with exact rational coefficients, keyed by
+// monomial. Coefficients are never zero and are not shared between
+// polynomials.
+type polynomial map[string]polyTerm
+
+func (m monomial) String() string {
+	parts := make([]string, len(m))
+	for i, factor := range m {
+		parts[i] = factor.name
+		if factor.power != 1 {
+			parts[i] += fmt.Sprintf("^%d", factor.power)
+		}
+	}
+	return strings.Join(parts, "*")
+}
+
+func (m monomial) degree() int {
+	degree := 0
+	for _, factor := range m {
+		degree += factor.power
+	}
+	return degree
+}
+
+// times multiplies two monomials
+func (m monomial) times(other monomial) (monomial, error) {
+	product := make(monomial, 0, len(m)+len(other))
+	i, j := 0, 0
+	for i < len(m) || j < len(other) {
+		switch {
+		case j == len(other) || (i < len(m) && m[i].name < other[j].name):
+			product = append(product, m[i])
+			i++
+		case i == len(m) || other[j].name < m[i].name:
+			product = append(product, other[j])
+			j++
+		default:
+			power := m[i].power + other[j].power
+			if power > maxPolynomialDegree {
+				return nil, fmt.Errorf("power of %s is too large to expand (maximum %d)", m[i].name, maxPolynomialDegree)
+			}
+			product = append(product, symbolPower{m[i].name, power})
+			i++
+			j++
+		}
+	}
+	return product, nil
+}
+
+// over divides m by other, reporting false when other does not divide m
+func (m monomial) over(other monomial) (monomial, bool) {
+	quotient := make(monomial, 0, len(m))
+	j := 0
+	for _, factor := range m {
+		if j < len(other) && other[j].name == factor.name {
+			if other[j].power > factor.power {
+				return nil, false
+			}
+			if factor.power > other[j].power {
+				quotient = append(quotient, symbolPower{factor.name, factor.power - other[j].power})
+			}
+			j++
+			continue
+		}
+		if j < len(other) && other[j].name < factor.name {
+			return nil, false
+		}
+		quotient = append(quotient, factor)
+	}
+	if j < len(other) {
+		return nil, false
+	}
+	return quotient, true
+}
+
+// compareMonomials orders monomials by descending total degree, then by
+// descending power of the symbols in name order (graded lexicographic)
+func compareMonomials(a, b monomial) int {
+	if da, db := a.degree(), b.degree(); da != db {
+		if da > db {
+			return -1
+		}
+		return 1
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i].name < b[j].name):
+			return -1
+		case i == len(a) || b[j].name < a[i].name:
+			return 1
+		case a[i].power != b[j].power:
+			if a[i].power > b[j].power {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	return 0
+}
+
+func constantPolynomial(value *big.Rat) polynomial {
+	p := polynomial{}
+	p.addTerm(nil, value)
+	return p
+}
+
+func symbolPolynomial(name string) polynomial {
+	return polynomial{name: {mono: monomial{{name, 1}}, coeff: big.NewRat(1, 1)}}
+}
+
+// addTerm adds coeff·mono to p in place
+func (p polynomial) addTerm(mono monomial, coeff *big.Rat) {
+	key := mono.String()
+	sum := new(big.Rat).Set(coeff)
+	if existing, ok := p[key]; ok {
+		sum.Add(sum, existing.coeff)
+	}
+	if sum.Sign() == 0 {
+		delete(p, key)
+		return
+	}
+	p[key] = polyTerm{mono: mono, coeff: sum}
+}
+
+func (p polynomial) plus(q polynomial) polynomial {
+	sum := polynomial{}
+	for _, term := range p {
+		sum.addTerm(term.mono, term.coeff)
+	}
+	for _, term := range q {
+		sum.addTerm(term.mono, term.coeff)
+	}
+	return sum
+}
+
+func (p polynomial) scaled(factor *big.Rat) polynomial {
+	scaled := polynomial{}
+	for _, term := range p {
+		scaled.addTerm(term.mono, new(big.Rat).Mul(term.coeff, factor))
+	}
+	return scaled
+}
+
+func (p polynomial) minus(q polynomial) polynomial {
+	return p.plus(q.scaled(big.NewRat(-1, 1)))
+}
+
+func (p polynomial) times(q polynomial) (polynomial, error) {
+	if len(p)*len(q) > maxPolynomialProducts {
+		return nil, fmt.Errorf("expression is too large to expand (maximum %d terms)", maxPolynomialTerms)
+	}
+	if p.degree()+q.degree() > maxExpandedDegree {
+		return nil, fmt.Errorf("expression is too large to expand (maximum degree %d)", maxExpandedDegree)
+	}
+	product := polynomial{}
+	for _, a := range p {
+		for _, b := range q {
+			mono, err := a.mono.times(b.mono)
+			if err != nil {
+				return nil, err
+			}
+			product.addTerm(mono, new(big.Rat).Mul(a.coeff, b.coeff))
+		}
+	}
+	if len(product) > maxPolynomialTerms {
+		return nil, fmt.Errorf("expression is too large to expand (maximum %d terms)", maxPolynomialTerms)
+	}
+	return product, nil
+}
+
+// power raises p to a non-negative power by repeated squaring
+func (p polynomial) power(n int) (polynomial, error) {
+	if n > 0 && p.degree() > maxExpandedDegree/n {
+		return nil, fmt.Errorf("expression is too large to expand (maximum degree %d)", maxExpandedDegree)
+	}
+	result := constantPolynomial(big.NewRat(1, 1))
+	base := p
+	for ; n > 0; n >>= 1 {
+		var err error
+		if n&1 == 1 {
+			if result, err = result.times(base); err != nil {
+				return nil, err
+			}
+		}
+		if n > 1 {
+			if base, err = base.times(base); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// degree returns the highest total degree of p's terms
+func (p polynomial) degree() int {
+	degree := 0
+	for _, term := range p {
+		if d := term.mono.degree(); d > degree {
+			degree = d
+		}
+	}
+	return degree
+}
+
+// constant returns the value of a polynomial without symbols
+func (p polynomial) constant() (*big.Rat, bool) {
+	switch len(p) {
+	case 0:
+		return new(big.Rat), true
+	case 1:
+		if term, ok := p[""]; ok {
+			return new(big.Rat).Set(term.coeff), true
+		}
+	}
+	return nil, false
+}
+
+func (p polynomial) equals(q polynomial) bool {
+	if len(p) != len(q) {
+		return false
+	}
+	for key, term := range p {
+		other, ok := q[key]
+		if !ok || term.coeff.Cmp(other.coeff) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedTerms returns the terms in graded lexicographic order
+func (p polynomial) sortedTerms() []polyTerm {
+	terms := make([]polyTerm, 0, len(p))
+	for _, term := range p {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		return compareMonomials(terms[i].mono, terms[j].mono) < 0
+	})
+	return terms
+}
+
+func (p polynomial) leading() polyTerm {
+	var lead polyTerm
+	for _, term := range p {
+		if lead.coeff == nil || compareMonomials(term.mono, lead.mono) < 0 {
+			lead = term
+		}
+	}
+	return lead
+}
+
+// symbols returns the sorted names of the symbols in p
+func (p polynomial) symbols() []string {
+	seen := make(map[string]bool)
+	for _, term := range p {
+		for _, factor := range term.mono {
+			seen[factor.name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p polynomial) isHomogeneous() bool {
+	degree := -1
+	for _, term := range p {
+		if degree >= 0 && term.mono.degree() != degree {
+			return false
+		}
+		degree = term.mono.degree()
+	}
+	return true
+}
+
+// dividedBy divides p by d, reporting false unless d divides p exactly
+func (p polynomial) dividedBy(d polynomial) (polynomial, bool) {
+	lead := d.leading()
+	quotient, remainder := polynomial{}, p.plus(nil)
+	for steps := 0; len(remainder) > 0; steps++ {
+		if steps > maxPolynomialTerms {
+			return nil, false
+		}
+		top := remainder.leading()
+		mono, ok := top.mono.over(lead.mono)
+		if !ok {
+			return nil, false
+		}
+		term := polynomial{}
+		term.addTerm(mono, new(big.Rat).Quo(top.coeff, lead.coeff))
+		product, err := d.times(term)
+		if err != nil {
+			return nil, false
+		}
+		quotient.addTerm(mono, term[mono.String()].coeff)
+		remainder = remainder.minus(product)
+	}
+	return quotient, true
+}
+
+// overMonomial divides every term of p by mono, which must divide them all
+func (p polynomial) overMonomial(mono monomial) polynomial {
+	quotient := polynomial{}
+	for _, term := range p {
+		reduced, _ := term.mono.over(mono)
+		quotient.addTerm(reduced, term.coeff)
+	}
+	return quotient
+}
+
+// String writes p with its terms in graded lexicographic order, e.g.
+// "3*x^2/4 - x*y + 1"
+func (p polynomial) String() string {
+	terms := p.sortedTerms()
+	if len(terms) == 0 {
+		return "0"
+	}
+	var b strings.Builder
+	for i, term := range terms {
+		negative := term.coeff.Sign() < 0
+		switch {
+		case i == 0 && negative:
+			b.WriteString("-")
+		case i > 0 && negative:
+			b.WriteString(" - ")
+		case i > 0:
+			b.WriteString(" + ")
+		}
+		b.WriteString(formatTerm(new(big.Rat).Abs(term.coeff), term.mono))
+	}
+	return b.String()
+}
+
+// formatTerm writes a positive coefficient and a monomial, e.g. "3*x^2/4"
+func formatTerm(coeff *big.Rat, mono monomial) string {
+	if len(mono) == 0 {
+		return coeff.RatString()
+	}
+	text := mono.String()
+	if coeff.Num().Cmp(big.NewInt(1)) != 0 {
+		text = coeff.Num().String() + "*" + text
+	}
+	if !coeff.IsInt() {
+		text += "/" + coeff.Denom().String()
+	}
+	return text
+}
+
+// primitivePart splits p into a rational content and a polynomial with
+// coprime integer coefficients and a positive leading coefficient
+func primitivePart(p polynomial) (*big.Rat, polynomial) {
+	if len(p) == 0 {
+		return big.NewRat(1, 1), p
+	}
+	lcm, gcd := big.NewInt(1), new(big.Int)
+	for _, term := range p {
+		denominator := term.coeff.Denom()
+		lcm.Mul(lcm, new(big.Int).Quo(denominator, new(big.Int).GCD(nil, nil, lcm, denominator)))
+	}
+	for _, term := range p {
+		numerator := new(big.Int).Mul(term.coeff.Num(), new(big.Int).Quo(lcm, term.coeff.Denom()))
+		gcd.GCD(nil, nil, gcd, numerator.Abs(numerator))
+	}
+	content := new(big.Rat).SetFrac(gcd, lcm)
+	if p.leading().coeff.Sign() < 0 {
+		content.Neg(content)
+	}
+	return content, p.scaled(new(big.Rat).Inv(content))
+}
+
+// commonMonomial returns the largest monomial dividing every term
+func commonMonomial(p polynomial) monomial {
+	var common monomial
+	first := true
+	for _, term := range p {
+		if first {
+			common, first = term.mono, false
+			continue
+		}
+		common = sharedMonomial(common, term.mono)
+	}
+	return common
+}
+
+// sharedMonomial returns the largest monomial dividing both a and b
+func sharedMonomial(a, b monomial) monomial {
+	var shared monomial
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].name < b[j].name:
+			i++
+		case b[j].name < a[i].name:
+			j++
+		default:
+			power := a[i].power
+			if b[j].power < power {
+				power = b[j].power
+			}
+			shared = append(shared, symbolPower{a[i].name, power})
+			i++
+			j++
+		}
+	}
+	return shared
+}
+
+// polyFactor is a factor of a polynomial and its multiplicity
+type polyFactor struct {
+	poly  polynomial
+	power int
+}
+
+// factorization is content · mono · the product of the factors, each of
+// which has coprime integer coefficients and a positive leading coefficient
+type factorization struct {
+	content *big.Rat
+	mono    monomial
+	factors []polyFactor
+}
+
+// factorPolynomial factors out the content and common monomial of p, then
+// splits a polynomial in one symbol, or a homogeneous one in two, into
+// repeated factors and linear factors with rational roots
+func factorPolynomial(ctx context.Context, p polynomial) (factorization, error) {
+	content, primitive := primitivePart(p)
+	mono := commonMonomial(primitive)
+	primitive = primitive.overMonomial(mono)
+
+	var factors []polyFactor
+	var err error
+	symbols := primitive.symbols()
+	switch {
+	case len(symbols) == 1:
+		if factors, err = factorUnivariate(ctx, primitive, symbols[0]); err != nil {
+			return factorization{}, err
+		}
+	case len(symbols) == 2 && primitive.isHomogeneous():
+		// Setting the second symbol to 1 gives a polynomial in the first,
+		// whose factors are made homogeneous again
+		x, y := symbols[0], symbols[1]
+		univariate, err := factorUnivariate(ctx, dehomogenize(primitive, x), x)
+		if err != nil {
+			return factorization{}, err
+		}
+		for _, factor := range univariate {
+			factors = append(factors, polyFactor{homogenize(factor.poly, x, y), factor.power})
+		}
+	case len(symbols) > 1:
+		factors = []polyFactor{{primitive, 1}}
+	}
+
+	sort.SliceStable(factors, func(i, j int) bool {
+		di, dj := factors[i].poly.leading().mono.degree(), factors[j].poly.leading().mono.degree()
+		if di != dj {
+			return di < dj
+		}
+		return factors[i].poly.String() < factors[j].poly.String()
+	})
+	return factorization{content: content, mono: mono, factors: factors}, nil
+}
+
+// expand multiplies a factorization back out
+func (f factorization) expand() (polynomial, error) {
+	product := polynomial{}
+	product.addTerm(f.mono, f.content)
+	for _, factor := range f.factors {
+		power, err := factor.poly.power(factor.power)
+		if err != nil {
+			return nil, err
+		}
+		if product, err = product.times(power); err != nil {
+			return nil, err
+		}
+	}
+	return product, nil
+}
+
+// String writes the factorization as a product, e.g. "2*x*(x + 1)^2" or
+// "(x - 1)*(x + 1)/2"
+func (f factorization) String() string {
+	return f.format(false)
+}
+
+// format writes the factorization; as an operand of a quotient, a lone
+// factor with several terms is put in parentheses
+func (f factorization) format(operand bool) string {
+	var parts []string
+	if len(f.mono) > 0 {
+		parts = append(parts, f.mono.String())
+	}
+	single := !operand && len(f.mono) == 0 && len(f.factors) == 1 && f.content.Cmp(big.NewRat(1, 1)) == 0
+	for _, factor := range f.factors {
+		text := factor.poly.String()
+		if factor.power > 1 || !single && len(factor.poly) > 1 {
+			text = "(" + text + ")"
+		}
+		if factor.power > 1 {
+			text += fmt.Sprintf("^%d", factor.power)
+		}
+		parts = append(parts, text)
+	}
+
+	magnitude := new(big.Rat).Abs(f.content)
+	if len(parts) == 0 || magnitude.Num().Cmp(big.NewInt(1)) != 0 {
+		parts = append([]string{magnitude.Num().String()}, parts...)
+	}
+	text := strings.Join(parts, "*")
+	if !magnitude.IsInt() {
+		text += "/" + magnitude.Denom().String()
+	}
+	if f.content.Sign() < 0 {
+		text = "-" + text
+	}
+	return text
+}
+
+// factorUnivariate splits a primitive polynomial in one symbol into
+// square-free parts (Yun's algorithm), then splits off the linear factors of
+// each part's rational roots
+func factorUnivariate(ctx context.Context, p polynomial, symbol string) ([]polyFactor, error) {
+	parts, err := squareFreeParts(ctx, denseCoefficients(p, symbol))
+	if err != nil {
+		return nil, err
+	}
+	var factors []polyFactor
+	for _, part := range parts {
+		linear, rest := rationalRootFactors(part.coeffs)
+		for _, factor := range linear {
+			_, primitive := primitivePart(sparsePolynomial(factor, symbol))
+			factors = append(factors, polyFactor{primitive, part.power})
+		}
+		if len(rest) > 1 {
+			_, primitive := primitivePart(sparsePolynomial(rest, symbol))
+			factors = append(factors, polyFactor{primitive, part.power})
+		}
+	}
+	return factors, nil
+}
+
+// denseCoefficients returns the coefficients of a polynomial in one symbol,
+// indexed by power
+func denseCoefficients(p polynomial, symbol string) []*big.Rat {
+	degree := 0
+	for _, term := range p {
+		if d := term.mono.degree(); d > degree {
+			degree = d
+		}
+	}
+	coeffs := make([]*big.Rat, degree+1)
+	for i := range coeffs {
+		coeffs[i] = new(big.Rat)
+	}
+	for _, term := range p {
+		coeffs[term.mono.degree()].Set(term.coeff)
+	}
+	return coeffs
+}
+
+// sparsePolynomial turns dense coefficients back into a polynomial
+func sparsePolynomial(coeffs []*big.Rat, symbol string) polynomial {
+	p := polynomial{}
+	for power, coeff := range coeffs {
+		var mono monomial
+		if power > 0 {
+			mono = monomial{{symbol, power}}
+		}
+		p.addTerm(mono, coeff)
+	}
+	return p
+}
+
+// dehomogenize sets every symbol but x to 1 in a polynomial of two symbols
+func dehomogenize(p polynomial, x string) polynomial {
+	result := polynomial{}
+	for _, term := range p {
+		var mono monomial
+		for _, factor := range term.mono {
+			if factor.name == x {
+				mono = monomial{factor}
+			}
+		}
+		result.addTerm(mono, term.coeff)
+	}
+	return result
+}
+
+// homogenize multiplies each term of a polynomial in x by the power of y
+// that brings it up to the polynomial's degree
+func homogenize(p polynomial, x, y string) polynomial {
+	coeffs := denseCoefficients(p, x)
+	degree := len(coeffs) - 1
+	result := polynomial{}
+	for power, coeff := range coeffs {
+		var mono monomial
+		if power > 0 {
+			mono = append(mono, symbolPower{x, power})
+		}
+		if degree > power {
+			mono = append(mono, symbolPower{y, degree - power})
+		}
+		result.addTerm(mono, coeff)
+	}
+	return result
+}
+
+// trimDense drops zero leading coefficients, keeping at least one
+func trimDense(coeffs []*big.Rat) []*big.Rat {
+	for len(coeffs) > 1 && coeffs[len(coeffs)-1].Sign() == 0 {
+		coeffs = coeffs[:len(coeffs)-1]
+	}
+	return coeffs
+}
+
+// divideDense divides dense polynomials, returning quotient and remainder
+func divideDense(a, b []*big.Rat) ([]*big.Rat, []*big.Rat) {
+	remainder := make([]*big.Rat, len(a))
+	for i, coeff := range a {
+		remainder[i] = new(big.Rat).Set(coeff)
+	}
+	remainder, b = trimDense(remainder), trimDense(b)
+	if len(remainder) < len(b) {
+		return []*big.Rat{new(big.Rat)}, remainder
+	}
+	quotient := make([]*big.Rat, len(remainder)-len(b)+1)
+	lead := b[len(b)-1]
+	for i := len(quotient) - 1; i >= 0; i-- {
+		quotient[i] = new(big.Rat).Quo(remainder[i+len(b)-1], lead)
+		for j, coeff := range b {
+			remainder[i+j].Sub(remainder[i+j], new(big.Rat).Mul(quotient[i], coeff))
+		}
+	}
+	if len(b) == 1 {
+		return quotient, []*big.Rat{new(big.Rat)}
+	}
+	return quotient, trimDense(remainder[:len(b)-1])
+}
+
+// isZeroDense reports whether every coefficient is zero
+func isZeroDense(coeffs []*big.Rat) bool {
+	coeffs = trimDense(coeffs)
+	return len(coeffs) == 1 && coeffs[0].Sign() == 0
+}
+
+// primitiveIntegers scales dense coefficients to coprime integers
+func primitiveIntegers(coeffs []*big.Rat) []*big.Int {
+	lcm := big.NewInt(1)
+	for _, coeff := range coeffs {
+		lcm.Mul(lcm, new(big.Int).Quo(coeff.Denom(), new(big.Int).GCD(nil, nil, lcm, coeff.Denom())))
+	}
+	integers := make([]*big.Int, len(coeffs))
+	for i, coeff := range coeffs {
+		integers[i] = new(big.Int).Mul(coeff.Num(), new(big.Int).Quo(lcm, coeff.Denom()))
+	}
+	return primitiveInts(integers)
+}
+
+// primitiveInts divides integer coefficients by their greatest common
+// divisor, dropping zero leading coefficients
+func primitiveInts(coeffs []*big.Int) []*big.Int {
+	for len(coeffs) > 1 && coeffs[len(coeffs)-1].Sign() == 0 {
+		coeffs = coeffs[:len(coeffs)-1]
+	}
+	gcd := new(big.Int)
+	for _, coeff := range coeffs {
+		gcd.GCD(nil, nil, gcd, new(big.Int).Abs(coeff))
+	}
+	if gcd.Sign() != 0 && gcd.Cmp(big.NewInt(1)) != 0 {
+		for _, coeff := range coeffs {
+			coeff.Quo(coeff, gcd)
+		}
+	}
+	return coeffs
+}
+
+// pseudoRemainder returns the remainder of lead(b)^k·a divided by b, which
+// stays in the integers
+func pseudoRemainder(a, b []*big.Int) []*big.Int {
+	remainder := make([]*big.Int, len(a))
+	for i, coeff := range a {
+		remainder[i] = new(big.Int).Set(coeff)
+	}
+	lead := b[len(b)-1]
+	for top := len(remainder) - 1; top >= len(b)-1; top-- {
+		factor := new(big.Int).Set(remainder[top])
+		for i := 0; i < top; i++ {
+			remainder[i].Mul(remainder[i], lead)
+		}
+		shift := top - (len(b) - 1)
+		for j, coeff := range b[:len(b)-1] {
+			remainder[shift+j].Sub(remainder[shift+j], new(big.Int).Mul(factor, coeff))
+		}
+	}
+	if len(b) == 1 {
+		return []*big.Int{new(big.Int)}
+	}
+	return remainder[:len(b)-1]
+}
+
+// gcdDense returns the monic greatest common divisor of two dense
+// polynomials by Euclid's algorithm. It works on integer pseudo-remainders
+// reduced to their primitive part, which keeps the coefficients small;
+// should the work still exceed maxGCDWork, or ctx end, it is abandoned.
+func gcdDense(ctx context.Context, a, b []*big.Rat) ([]*big.Rat, error) {
+	x, y := primitiveIntegers(trimDense(a)), primitiveIntegers(trimDense(b))
+	if len(x) < len(y) {
+		x, y = y, x
+	}
+	work := 0
+	for len(y) > 1 || y[0].Sign() != 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		words := 1
+		for _, coeff := range x {
+			if n := len(coeff.Bits()); n > words {
+				words = n
+			}
+		}
+		if work += (len(x) - len(y) + 1) * len(x) * words * words; work > maxGCDWork {
+			return nil, fmt.Errorf("the common factors are too costly to find")
+		}
+		x, y = y, primitiveInts(pseudoRemainder(x, y))
+	}
+	lead := new(big.Rat).SetInt(x[len(x)-1])
+	monic := make([]*big.Rat, len(x))
+	for i, coeff := range x {
+		monic[i] = new(big.Rat).Quo(new(big.Rat).SetInt(coeff), lead)
+	}
+	return monic, nil
+}
+
+// derivativeDense differentiates a dense polynomial
+func derivativeDense(coeffs []*big.Rat) []*big.Rat {
+	if len(coeffs) < 2 {
+		return []*big.Rat{new(big.Rat)}
+	}
+	derivative := make([]*big.Rat, len(coeffs)-1)
+	for i := range derivative {
+		derivative[i] = new(big.Rat).Mul(coeffs[i+1], big.NewRat(int64(i+1), 1))
+	}
+	return derivative
+}
+
+// squareFreePart is a square-free polynomial and the power it is raised to
+type squareFreePart struct {
+	coeffs []*big.Rat
+	power  int
+}
+
+// squareFreeParts splits a polynomial into square-free parts by Yun's
+// algorithm, so that it is the product of each part to its power
+func squareFreeParts(ctx context.Context, coeffs []*big.Rat) ([]squareFreePart, error) {
+	coeffs = trimDense(coeffs)
+	if len(coeffs) < 2 {
+		return nil, nil
+	}
+	var parts []squareFreePart
+	repeated, err := gcdDense(ctx, coeffs, derivativeDense(coeffs))
+	if err != nil {
+		return nil, err
+	}
+	remaining, _ := divideDense(coeffs, repeated)
+	power := 1
+	for ; len(repeated) > 1; power++ {
+		shared, err := gcdDense(ctx, remaining, repeated)
+		if err != nil {
+			return nil, err
+		}
+		if part, _ := divideDense(remaining, shared); len(trimDense(part)) > 1 {
+			parts = append(parts, squareFreePart{part, power})
+		}
+		remaining = shared
+		repeated, _ = divideDense(repeated, shared)
+		repeated = trimDense(repeated)
+	}
+	if remaining = trimDense(remaining); len(remaining) > 1 {
+		parts = append(parts, squareFreePart{remaining, power})
+	}
+	return parts, nil
+}
+
+// rationalRootFactors splits the linear factors q·x - p of a square-free
+// polynomial's rational roots p/q off, returning them and what is left
+func rationalRootFactors(coeffs []*big.Rat) ([][]*big.Rat, []*big.Rat) {
+	coeffs = trimDense(coeffs)
+	var linear [][]*big.Rat
+	if len(coeffs) > 1 && coeffs[0].Sign() == 0 {
+		linear = append(linear, []*big.Rat{new(big.Rat), big.NewRat(1, 1)})
+		coeffs = coeffs[1:]
+	}
+	if len(coeffs) < 2 {
+		return linear, coeffs
+	}
+
+	// Candidates come from the integer form of the polynomial
+	lcm := big.NewInt(1)
+	for _, coeff := range coeffs {
+		lcm.Mul(lcm, new(big.Int).Quo(coeff.Denom(), new(big.Int).GCD(nil, nil, lcm, coeff.Denom())))
+	}
+	integer := func(coeff *big.Rat) *big.Int {
+		value := new(big.Int).Mul(coeff.Num(), new(big.Int).Quo(lcm, coeff.Denom()))
+		return value.Abs(value)
+	}
+	constant, lead := integer(coeffs[0]), integer(coeffs[len(coeffs)-1])
+	limit := big.NewInt(int64(maxRootCoefficient))
+	if constant.Cmp(limit) > 0 || lead.Cmp(limit) > 0 {
+		return linear, coeffs
+	}
+	numerators, denominators := divisors(constant.Int64()), divisors(lead.Int64())
+	if 2*len(numerators)*len(denominators) > maxRootCandidates {
+		return linear, coeffs
+	}
+
+	for _, q := range denominators {
+		for _, p := range numerators {
+			for _, sign := range []int64{1, -1} {
+				if len(coeffs) < 2 || new(big.Int).GCD(nil, nil, big.NewInt(p), big.NewInt(q)).Int64() != 1 {
+					continue
+				}
+				factor := []*big.Rat{big.NewRat(-sign*p, 1), big.NewRat(q, 1)}
+				quotient, remainder := divideDense(coeffs, factor)
+				if isZeroDense(remainder) {
+					linear = append(linear, factor)
+					coeffs = trimDense(quotient)
+				}
+			}
+		}
+	}
+	return linear, coeffs
+}
+
+// divisors returns the positive divisors of n in ascending order
+func divisors(n int64) []int64 {
+	var small, large []int64
+	for d := int64(1); d*d <= n; d++ {
+		if n%d == 0 {
+			small = append(small, d)
+			if d*d != n {
+				large = append([]int64{n / d}, large...)
+			}
+		}
+	}
+	return append(small, large...)
+}
@@ -0,0 +1,295 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// maxPrimeValue bounds the numbers searched by range, next and previous;
+	// sieving them needs base primes up to its square root
+	maxPrimeValue = 1e12
+
+	// maxPrimeSieve bounds sieving from 2, as count and nth do
+	maxPrimeSieve = 1e9
+
+	// maxPrimeSpan bounds the width of a range
+	maxPrimeSpan = 1e8
+
+	// maxPrimeIndex is the largest n for nth; the 50,000,000th prime is
+	// 982,451,653, just under maxPrimeSieve
+	maxPrimeIndex = 50000000
+
+	defaultPrimeLimit = 1000
+	maxPrimeLimit     = 100000
+
+	// primeSegment is the number of odd values sieved at a time, small
+	// enough to stay in cache
+	primeSegment = 1 << 16
+)
+
+// PrimeCalculator answers questions about prime numbers with a segmented
+// sieve of Eratosthenes
+type PrimeCalculator struct{}
+
+func NewPrimeCalculator() *PrimeCalculator {
+	return &PrimeCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (pc *PrimeCalculator) GetSupportedOperations() []string {
+	return []string{"nth", "range", "count", "next", "previous"}
+}
+
+// Calculate performs the requested prime operation, stopping once ctx is
+// done
+func (pc *PrimeCalculator) Calculate(ctx context.Context, req types.PrimeRequest) (types.PrimeResult, error) {
+	switch req.Operation {
+	case "nth":
+		return pc.nth(ctx, req)
+	case "range":
+		return pc.primeRange(ctx, req)
+	case "count":
+		return pc.count(ctx, req)
+	case "next":
+		return pc.next(ctx, req)
+	case "previous":
+		return pc.previous(ctx, req)
+	default:
+		return types.PrimeResult{}, fmt.Errorf("unsupported prime operation: %s. Supported operations: %v", req.Operation, pc.GetSupportedOperations())
+	}
+}
+
+// nth returns the nth prime, counting 2 as the first
+func (pc *PrimeCalculator) nth(ctx context.Context, req types.PrimeRequest) (types.PrimeResult, error) {
+	if req.N < 1 || req.N > maxPrimeIndex {
+		return types.PrimeResult{}, fmt.Errorf("n must be between 1 and %d, got %d", maxPrimeIndex, req.N)
+	}
+
+	// p(n) < n·(ln n + ln ln n) for n ≥ 6 (Rosser's theorem)
+	upper := int64(13)
+	if n := float64(req.N); req.N >= 6 {
+		upper = int64(n * (math.Log(n) + math.Log(math.Log(n))))
+	}
+	var prime, seen int64
+	err := sievePrimes(ctx, 2, upper, func(p int64) bool {
+		seen++
+		prime = p
+		return seen < req.N
+	})
+	if err != nil {
+		return types.PrimeResult{}, err
+	}
+	return types.PrimeResult{Operation: req.Operation, Prime: &prime}, nil
+}
+
+// primeRange lists the primes from From to To inclusive, up to Limit of
+// them, and counts them all
+func (pc *PrimeCalculator) primeRange(ctx context.Context, req types.PrimeRequest) (types.PrimeResult, error) {
+	if err := requirePrimeValue("from", req.From, 0); err != nil {
+		return types.PrimeResult{}, err
+	}
+	if err := requirePrimeValue("to", req.To, 0); err != nil {
+		return types.PrimeResult{}, err
+	}
+	if req.To < req.From {
+		return types.PrimeResult{}, fmt.Errorf("to (%d) must not be less than from (%d)", req.To, req.From)
+	}
+	if req.To-req.From > maxPrimeSpan {
+		return types.PrimeResult{}, fmt.Errorf("the range may span at most %d numbers, got %d", int64(maxPrimeSpan), req.To-req.From)
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultPrimeLimit
+	}
+	if limit < 1 || limit > maxPrimeLimit {
+		return types.PrimeResult{}, fmt.Errorf("limit must be between 1 and %d, got %d", maxPrimeLimit, limit)
+	}
+
+	primes := []int64{}
+	var count int64
+	err := sievePrimes(ctx, req.From, req.To, func(p int64) bool {
+		if count < int64(limit) {
+			primes = append(primes, p)
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		return types.PrimeResult{}, err
+	}
+	return types.PrimeResult{
+		Operation: req.Operation,
+		Primes:    primes,
+		Count:     &count,
+		Truncated: count > int64(limit),
+	}, nil
+}
+
+// count returns the number of primes below N, π(N − 1)
+func (pc *PrimeCalculator) count(ctx context.Context, req types.PrimeRequest) (types.PrimeResult, error) {
+	if req.N < 0 || req.N > maxPrimeSieve {
+		return types.PrimeResult{}, fmt.Errorf("n must be between 0 and %d, got %d", int64(maxPrimeSieve), req.N)
+	}
+	var count int64
+	err := sievePrimes(ctx, 2, req.N-1, func(int64) bool {
+		count++
+		return true
+	})
+	if err != nil {
+		return types.PrimeResult{}, err
+	}
+	return types.PrimeResult{Operation: req.Operation, Count: &count}, nil
+}
+
+// next returns the smallest prime greater than Value
+func (pc *PrimeCalculator) next(ctx context.Context, req types.PrimeRequest) (types.PrimeResult, error) {
+	if err := requirePrimeValue("value", req.Value, 0); err != nil {
+		return types.PrimeResult{}, err
+	}
+	var prime int64
+	// Prime gaps below 10^12 are under 600, so the first window nearly
+	// always holds the answer
+	for width := int64(1024); prime == 0; width *= 2 {
+		err := sievePrimes(ctx, req.Value+1, req.Value+width, func(p int64) bool {
+			prime = p
+			return false
+		})
+		if err != nil {
+			return types.PrimeResult{}, err
+		}
+	}
+	return types.PrimeResult{Operation: req.Operation, Prime: &prime}, nil
+}
+
+// previous returns the largest prime less than Value
+func (pc *PrimeCalculator) previous(ctx context.Context, req types.PrimeRequest) (types.PrimeResult, error) {
+	if err := requirePrimeValue("value", req.Value, 0); err != nil {
+		return types.PrimeResult{}, err
+	}
+	if req.Value <= 2 {
+		return types.PrimeResult{}, fmt.Errorf("there is no prime less than %d", req.Value)
+	}
+	var prime int64
+	for width := int64(1024); prime == 0; width *= 2 {
+		upper := req.Value - 1
+		lower := upper - width
+		if lower < 2 {
+			lower = 2
+		}
+		err := sievePrimes(ctx, lower, upper, func(p int64) bool {
+			prime = p
+			return true
+		})
+		if err != nil {
+			return types.PrimeResult{}, err
+		}
+	}
+	return types.PrimeResult{Operation: req.Operation, Prime: &prime}, nil
+}
+
+// sievePrimes calls visit with each prime from lower to upper inclusive in
+// ascending order, until visit returns false. The odd numbers are sieved a
+// segment at a time by the primes up to √upper, and sieving stops with
+// ctx.Err() between segments once ctx is done.
+func sievePrimes(ctx context.Context, lower, upper int64, visit func(int64) bool) error {
+	if lower < 2 {
+		lower = 2
+	}
+	if upper < lower {
+		return nil
+	}
+	if lower == 2 {
+		if !visit(2) {
+			return nil
+		}
+		lower = 3
+	}
+	if lower%2 == 0 {
+		lower++
+	}
+	if upper < lower {
+		return nil
+	}
+
+	base := basePrimes(isqrt(upper))
+	composite := make([]bool, primeSegment)
+	// Each segment covers the odd numbers start, start + 2, ...
+	for start := lower; start <= upper; start += 2 * primeSegment {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		size := int64(primeSegment)
+		if last := start + 2*(size-1); last > upper {
+			size = (upper-start)/2 + 1
+		}
+		segment := composite[:size]
+		for i := range segment {
+			segment[i] = false
+		}
+		for _, p := range base {
+			if p*p > start+2*(size-1) {
+				break
+			}
+			// First odd multiple of p in the segment, at least p²
+			first := (start + p - 1) / p * p
+			if first < p*p {
+				first = p * p
+			}
+			if first%2 == 0 {
+				first += p
+			}
+			for m := (first - start) / 2; m < size; m += p {
+				segment[m] = true
+			}
+		}
+		for i, isComposite := range segment {
+			if !isComposite && !visit(start+2*int64(i)) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// basePrimes returns the odd primes up to limit with a simple sieve
+func basePrimes(limit int64) []int64 {
+	if limit < 3 {
+		return nil
+	}
+	composite := make([]bool, limit+1)
+	primes := []int64{}
+	for n := int64(3); n <= limit; n += 2 {
+		if composite[n] {
+			continue
+		}
+		primes = append(primes, n)
+		for m := n * n; m <= limit; m += 2 * n {
+			composite[m] = true
+		}
+	}
+	return primes
+}
+
+// isqrt returns ⌊√n⌋
+func isqrt(n int64) int64 {
+	root := int64(math.Sqrt(float64(n)))
+	for root*root > n {
+		root--
+	}
+	for (root+1)*(root+1) <= n {
+		root++
+	}
+	return root
+}
+
+// requirePrimeValue rejects values below minimum or above maxPrimeValue
+func requirePrimeValue(name string, value, minimum int64) error {
+	if value < minimum || value > maxPrimeValue {
+		return fmt.Errorf("%s must be between %d and %d, got %d", name, minimum, int64(maxPrimeValue), value)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mathext"
+	"gonum.org/v1/gonum/stat/distuv"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	maxBinomialTrials   = 1000000
+	maxBirthdayDays     = 1000000
+	maxDistributionSize = 10000
+	defaultBirthdayDays = 365
+
+	// probabilitySumSlack is the rounding allowed per probability when
+	// checking that a distribution sums to 1
+	probabilitySumSlack = 1e-9
+
+	// eventConsistencySlack is the rounding allowed when checking that
+	// conditional probabilities are possible together
+	eventConsistencySlack = 1e-12
+)
+
+// ProbabilityCalculator answers common probability questions
+type ProbabilityCalculator struct{}
+
+func NewProbabilityCalculator() *ProbabilityCalculator {
+	return &ProbabilityCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (pc *ProbabilityCalculator) GetSupportedOperations() []string {
+	return []string{"independent_events", "conditional", "binomial", "birthday", "expected_value"}
+}
+
+// Calculate evaluates the requested probability scenario
+func (pc *ProbabilityCalculator) Calculate(req types.ProbabilityRequest) (types.ProbabilityResult, error) {
+	var result float64
+	var values map[string]float64
+	var err error
+	switch req.Operation {
+	case "independent_events", "conditional":
+		result, values, err = combineEvents(req)
+	case "binomial":
+		result, values, err = binomialAtLeast(req)
+	case "birthday":
+		result, values, err = birthdayProblem(req)
+	case "expected_value":
+		result, values, err = discreteMoments(req)
+	default:
+		return types.ProbabilityResult{}, fmt.Errorf("unsupported probability operation: %s. Supported operations: %v", req.Operation, pc.GetSupportedOperations())
+	}
+	if err != nil {
+		return types.ProbabilityResult{}, err
+	}
+	return types.ProbabilityResult{Operation: req.Operation, Result: result, Values: values}, nil
+}
+
+// combineEvents combines two events A and B, either independent or with
+// P(B|A) given, into P(A and B), P(A or B) and the other combinations.
+// The result is P(A and B).
+func combineEvents(req types.ProbabilityRequest) (float64, map[string]float64, error) {
+	if req.ProbabilityA == nil || req.ProbabilityB == nil {
+		return 0, nil, fmt.Errorf("%s requires probability_a and probability_b", req.Operation)
+	}
+	a, b := *req.ProbabilityA, *req.ProbabilityB
+	if err := requireProbability("probability_a", a); err != nil {
+		return 0, nil, err
+	}
+	if err := requireProbability("probability_b", b); err != nil {
+		return 0, nil, err
+	}
+
+	and := a * b
+	if req.Operation == "conditional" {
+		if req.ProbabilityBGivenA == nil {
+			return 0, nil, fmt.Errorf("conditional requires probability_b_given_a")
+		}
+		if err := requireProbability("probability_b_given_a", *req.ProbabilityBGivenA); err != nil {
+			return 0, nil, err
+		}
+		and = a * *req.ProbabilityBGivenA
+		// P(A and B) cannot exceed P(B), and P(A or B) cannot exceed 1
+		if and > b+eventConsistencySlack || a+b-and > 1+eventConsistencySlack {
+			return 0, nil, fmt.Errorf("inconsistent probabilities: P(A and B) = %g is impossible with P(A) = %g and P(B) = %g", and, a, b)
+		}
+	} else if req.ProbabilityBGivenA != nil {
+		return 0, nil, fmt.Errorf("probability_b_given_a applies only to conditional; independent events have P(B|A) = P(B)")
+	}
+
+	or := a + b - and
+	values := map[string]float64{
+		"a_and_b":     and,
+		"a_or_b":      or,
+		"neither":     1 - or,
+		"exactly_one": or - and,
+		"a_not_b":     a - and,
+		"b_not_a":     b - and,
+	}
+	if a > 0 {
+		values["b_given_a"] = and / a
+	}
+	if b > 0 {
+		values["a_given_b"] = and / b
+	}
+	return and, values, nil
+}
+
+// binomialAtLeast returns P(X ≥ k) for X ~ Binomial(n, p), computed as the
+// regularized incomplete beta I_p(k, n − k + 1) so that small tails keep
+// their precision
+func binomialAtLeast(req types.ProbabilityRequest) (float64, map[string]float64, error) {
+	n, k := req.Trials, req.Successes
+	if n < 1 || n > maxBinomialTrials {
+		return 0, nil, fmt.Errorf("trials must be between 1 and %d, got %d", maxBinomialTrials, n)
+	}
+	if k < 0 || k > n {
+		return 0, nil, fmt.Errorf("successes must be between 0 and trials (%d), got %d", n, k)
+	}
+	if req.Probability == nil {
+		return 0, nil, fmt.Errorf("binomial requires the probability of success in each trial")
+	}
+	p := *req.Probability
+	if err := requireProbability("probability", p); err != nil {
+		return 0, nil, err
+	}
+
+	distribution := distuv.Binomial{N: float64(n), P: p}
+	atLeast := 1.0
+	if k > 0 {
+		switch p {
+		case 0:
+			atLeast = 0
+		case 1:
+			atLeast = 1
+		default:
+			atLeast = mathext.RegIncBeta(float64(k), float64(n-k+1), p)
+		}
+	}
+	exactly := 0.0
+	switch {
+	case p == 0:
+		if k == 0 {
+			exactly = 1
+		}
+	case p == 1:
+		if k == n {
+			exactly = 1
+		}
+	default:
+		exactly = distribution.Prob(float64(k))
+	}
+
+	values := map[string]float64{
+		"at_least":  atLeast,
+		"exactly":   exactly,
+		"at_most":   1 - atLeast + exactly,
+		"more_than": atLeast - exactly,
+		"mean":      distribution.Mean(),
+		"variance":  distribution.Variance(),
+	}
+	return atLeast, values, nil
+}
+
+// birthdayProblem returns the probability that at least two of People share
+// a birthday among Days equally likely days, and how many people make that
+// more likely than not
+func birthdayProblem(req types.ProbabilityRequest) (float64, map[string]float64, error) {
+	days := req.Days
+	if days == 0 {
+		days = defaultBirthdayDays
+	}
+	if days < 1 || days > maxBirthdayDays {
+		return 0, nil, fmt.Errorf("days must be between 1 and %d, got %d", maxBirthdayDays, days)
+	}
+	if req.People < 0 {
+		return 0, nil, fmt.Errorf("people cannot be negative")
+	}
+
+	// log P(all distinct) = Σ log(1 − i/days), accumulated until it reaches
+	// both the requested group size and the median group size
+	logDistinct, shared, median := 0.0, 1.0, 0
+	for i := 0; i <= days; i++ {
+		if i == req.People {
+			shared = -math.Expm1(logDistinct)
+		}
+		if median == 0 && -math.Expm1(logDistinct) >= 0.5 {
+			median = i
+		}
+		if i >= req.People && median != 0 {
+			break
+		}
+		logDistinct += math.Log1p(-float64(i) / float64(days))
+	}
+	if median == 0 {
+		median = days + 1
+	}
+
+	values := map[string]float64{
+		"people":                   float64(req.People),
+		"days":                     float64(days),
+		"probability_shared":       shared,
+		"probability_all_distinct": 1 - shared,
+		"people_for_even_odds":     float64(median),
+	}
+	return shared, values, nil
+}
+
+// discreteMoments returns the expected value, variance and standard
+// deviation of a discrete distribution
+func discreteMoments(req types.ProbabilityRequest) (float64, map[string]float64, error) {
+	if len(req.Outcomes) == 0 {
+		return 0, nil, fmt.Errorf("expected_value requires outcomes")
+	}
+	if len(req.Outcomes) > maxDistributionSize {
+		return 0, nil, fmt.Errorf("too many outcomes: %d (maximum %d)", len(req.Outcomes), maxDistributionSize)
+	}
+	if len(req.Probabilities) != len(req.Outcomes) {
+		return 0, nil, fmt.Errorf("probabilities must have one value per outcome: got %d for %d outcomes", len(req.Probabilities), len(req.Outcomes))
+	}
+
+	total, mean := 0.0, 0.0
+	for i, outcome := range req.Outcomes {
+		if err := requireFinite(fmt.Sprintf("outcome %d", i), outcome); err != nil {
+			return 0, nil, err
+		}
+		if err := requireProbability(fmt.Sprintf("probability %d", i), req.Probabilities[i]); err != nil {
+			return 0, nil, err
+		}
+		total += req.Probabilities[i]
+		mean += outcome * req.Probabilities[i]
+	}
+	if math.Abs(total-1) > probabilitySumSlack*float64(len(req.Outcomes)) {
+		return 0, nil, fmt.Errorf("probabilities must sum to 1, got %g", total)
+	}
+
+	variance := 0.0
+	for i, outcome := range req.Outcomes {
+		variance += req.Probabilities[i] * (outcome - mean) * (outcome - mean)
+	}
+
+	values := map[string]float64{
+		"expected_value":     mean,
+		"variance":           variance,
+		"standard_deviation": math.Sqrt(variance),
+	}
+	return mean, values, nil
+}
+
+func requireProbability(name string, value float64) error {
+	if math.IsNaN(value) || value < 0 || value > 1 {
+		return fmt.Errorf("%s must be a probability between 0 and 1, got %g", name, value)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package calculator
+
+import "context"
+
+// ProgressFunc receives how far a long-running calculation has got: done
+// out of total units of work, with total 0 when it is not known
+type ProgressFunc func(ctx context.Context, done, total float64, message string)
+
+// progressKey is the context key of the progress callback of a request
+type progressKey struct{}
+
+// WithProgress returns a context whose long-running calculations, such as
+// summations, simplifications and batch conversions, report to progress
+func WithProgress(ctx context.Context, progress ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+// reportProgress calls the progress callback of ctx, if it carries one
+func reportProgress(ctx context.Context, done, total float64, message string) {
+	if progress, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && progress != nil {
+		progress(ctx, done, total, message)
+	}
+}
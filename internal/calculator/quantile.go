@@ -0,0 +1,183 @@
+package calculator
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// empiricalIndex is the index in sorted data of the empirical p-quantile,
+// the first point at which the cumulative count reaches p·n. It matches
+// stat.Quantile with stat.Empirical.
+func empiricalIndex(p float64, n int) int {
+	k := int(math.Ceil(p*float64(n))) - 1
+	if k < 0 {
+		return 0
+	}
+	if k >= n {
+		return n - 1
+	}
+	return k
+}
+
+// unweightedQuantiles returns the empirical quantiles of unweighted data
+// without sorting it. Each is found by quickselect on a copy of the data in
+// expected linear time, so a few quantiles of a large dataset cost less than
+// one sort.
+func unweightedQuantiles(data []float64, ps ...float64) []float64 {
+	work := make([]float64, len(data))
+	copy(work, data)
+	quantiles := make([]float64, len(ps))
+	for i, p := range ps {
+		quantiles[i] = selectKth(work, empiricalIndex(p, len(work)))
+	}
+	return quantiles
+}
+
+// selectKth returns the kth smallest value of data, 0-based, reordering data
+// in place. A three-way partition keeps repeated values linear, and after
+// too many unbalanced partitions the remaining range is sorted instead, so
+// the worst case is O(n log n).
+func selectKth(data []float64, k int) float64 {
+	lo, hi := 0, len(data)-1
+	for depth := 2 * bits.Len(uint(len(data))); lo < hi; depth-- {
+		if depth == 0 {
+			sort.Float64s(data[lo : hi+1])
+			return data[k]
+		}
+
+		pivot := medianOfThree(data[lo], data[lo+(hi-lo)/2], data[hi])
+		// data[lo:lt] < pivot, data[lt:i] == pivot and data[gt+1:hi+1] > pivot
+		lt, i, gt := lo, lo, hi
+		for i <= gt {
+			switch {
+			case data[i] < pivot:
+				data[lt], data[i] = data[i], data[lt]
+				lt++
+				i++
+			case data[i] > pivot:
+				data[i], data[gt] = data[gt], data[i]
+				gt--
+			default:
+				i++
+			}
+		}
+
+		switch {
+		case k < lt:
+			hi = lt - 1
+		case k > gt:
+			lo = gt + 1
+		default:
+			return data[k]
+		}
+	}
+	return data[k]
+}
+
+func medianOfThree(a, b, c float64) float64 {
+	if a > b {
+		a, b = b, a
+	}
+	if b > c {
+		b = c
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// p2Quantile estimates a quantile of a stream in constant memory with the
+// P² algorithm (Jain and Chlamtac, 1985). Five markers track the minimum,
+// the maximum, the quantile and the quantiles halfway to either side; their
+// heights are adjusted with a piecewise-parabolic fit as points arrive. The
+// estimate is exact for the minimum and maximum and for up to five points.
+type p2Quantile struct {
+	p         float64
+	count     int
+	heights   [5]float64
+	positions [5]float64
+	desired   [5]float64
+	increment [5]float64
+}
+
+func newP2Quantile(p float64) *p2Quantile {
+	return &p2Quantile{
+		p:         p,
+		positions: [5]float64{0, 1, 2, 3, 4},
+		desired:   [5]float64{0, 2 * p, 4 * p, 2 + 2*p, 4},
+		increment: [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+// add observes one value
+func (q *p2Quantile) add(x float64) {
+	if q.count < 5 {
+		q.heights[q.count] = x
+		q.count++
+		if q.count == 5 {
+			sort.Float64s(q.heights[:])
+		}
+		return
+	}
+	q.count++
+
+	// Find the cell holding x, stretching the extreme markers if needed
+	var cell int
+	switch {
+	case x < q.heights[0]:
+		q.heights[0] = x
+	case x >= q.heights[4]:
+		q.heights[4] = x
+		cell = 3
+	default:
+		for cell = 0; x >= q.heights[cell+1]; cell++ {
+		}
+	}
+	for i := cell + 1; i < 5; i++ {
+		q.positions[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.increment[i]
+	}
+
+	// Move the middle markers that are a position or more from where they should be
+	for i := 1; i <= 3; i++ {
+		d := q.desired[i] - q.positions[i]
+		if (d >= 1 && q.positions[i+1]-q.positions[i] > 1) || (d <= -1 && q.positions[i-1]-q.positions[i] < -1) {
+			step := math.Copysign(1, d)
+			if height := q.parabolic(i, step); q.heights[i-1] < height && height < q.heights[i+1] {
+				q.heights[i] = height
+			} else {
+				j := i + int(step)
+				q.heights[i] += step * (q.heights[j] - q.heights[i]) / (q.positions[j] - q.positions[i])
+			}
+			q.positions[i] += step
+		}
+	}
+}
+
+// parabolic is the P² prediction of marker i's height moved by step
+func (q *p2Quantile) parabolic(i int, step float64) float64 {
+	h, n := q.heights, q.positions
+	return h[i] + step/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+step)*(h[i+1]-h[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-step)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+// value returns the current estimate
+func (q *p2Quantile) value() float64 {
+	if q.count <= 5 {
+		observed := append([]float64(nil), q.heights[:q.count]...)
+		sort.Float64s(observed)
+		return observed[empiricalIndex(q.p, len(observed))]
+	}
+	switch q.p {
+	case 0:
+		return q.heights[0]
+	case 1:
+		return q.heights[4]
+	}
+	return q.heights[2]
+}
@@ -0,0 +1,400 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// temperatureCategory is converted by formula; its units have no factors
+const temperatureCategory = "temperature"
+
+// unitFactor is a unit and the number of base units in one of it
+type unitFactor struct {
+	unit   string
+	toBase float64
+}
+
+// builtinUnits are the unit tables, in listing order. The base units are
+// metres, grams, litres and square metres.
+var builtinUnits = []struct {
+	category string
+	units    []unitFactor
+}{
+	{"length", []unitFactor{
+		{"mm", 0.001},
+		{"cm", 0.01},
+		{"m", 1.0},
+		{"km", 1000.0},
+		{"in", 0.0254},
+		{"ft", 0.3048},
+		{"yd", 0.9144},
+		{"mi", 1609.344},
+		{"mil", 0.0000254},
+		{"μm", 0.000001},
+		{"nm", 0.000000001},
+	}},
+	{"weight", []unitFactor{
+		{"mg", 0.001},
+		{"g", 1.0},
+		{"kg", 1000.0},
+		{"t", 1000000.0},
+		{"oz", 28.3495},
+		{"lb", 453.592},
+		{"st", 6350.29}, // stone
+		{"ton", 907185}, // US ton
+	}},
+	{temperatureCategory, []unitFactor{{"C", 0}, {"F", 0}, {"K", 0}, {"R", 0}}},
+	{"volume", []unitFactor{
+		{"ml", 0.001},
+		{"cl", 0.01},
+		{"dl", 0.1},
+		{"l", 1.0},
+		{"kl", 1000.0},
+		{"fl_oz", 0.0295735}, // US fluid ounce
+		{"cup", 0.236588},    // US cup
+		{"pt", 0.473176},     // US pint
+		{"qt", 0.946353},     // US quart
+		{"gal", 3.78541},     // US gallon
+		{"tsp", 0.00492892},  // US teaspoon
+		{"tbsp", 0.0147868},  // US tablespoon
+		{"bbl", 158.987},     // barrel
+	}},
+	{"area", []unitFactor{
+		{"mm2", 0.000001},
+		{"cm2", 0.0001},
+		{"m2", 1.0},
+		{"km2", 1000000.0},
+		{"in2", 0.00064516},
+		{"ft2", 0.092903},
+		{"yd2", 0.836127},
+		{"mi2", 2589988.11},
+		{"acre", 4046.86},
+		{"ha", 10000.0}, // hectare
+	}},
+}
+
+// unitAliases maps spelled-out and alternative unit names, in lower case,
+// onto the units of the tables
+var unitAliases = map[string]string{
+	"millimeter": "mm", "millimeters": "mm", "millimetre": "mm", "millimetres": "mm",
+	"centimeter": "cm", "centimeters": "cm", "centimetre": "cm", "centimetres": "cm",
+	"meter": "m", "meters": "m", "metre": "m", "metres": "m",
+	"kilometer": "km", "kilometers": "km", "kilometre": "km", "kilometres": "km",
+	"inch": "in", "inches": "in", "\"": "in",
+	"foot": "ft", "feet": "ft", "'": "ft",
+	"yard": "yd", "yards": "yd",
+	"mile": "mi", "miles": "mi",
+	"um": "μm", "µm": "μm", "micrometer": "μm", "micrometers": "μm", "micron": "μm", "microns": "μm",
+	"nanometer": "nm", "nanometers": "nm",
+	"milligram": "mg", "milligrams": "mg",
+	"gram": "g", "grams": "g",
+	"kilogram": "kg", "kilograms": "kg", "kgs": "kg",
+	"tonne": "t", "tonnes": "t", "metric ton": "t",
+	"ounce": "oz", "ounces": "oz",
+	"pound": "lb", "pounds": "lb", "lbs": "lb",
+	"stone":   "st",
+	"celsius": "C", "°c": "C", "centigrade": "C",
+	"fahrenheit": "F", "°f": "F",
+	"kelvin":  "K",
+	"rankine": "R", "°r": "R",
+	"milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"fluid ounce": "fl_oz", "fluid ounces": "fl_oz", "fl oz": "fl_oz",
+	"cups": "cup",
+	"pint": "pt", "pints": "pt",
+	"quart": "qt", "quarts": "qt",
+	"gallon": "gal", "gallons": "gal",
+	"teaspoon": "tsp", "teaspoons": "tsp",
+	"tablespoon": "tbsp", "tablespoons": "tbsp",
+	"barrel": "bbl", "barrels": "bbl",
+	"mm²": "mm2", "cm²": "cm2", "m²": "m2", "km²": "km2", "in²": "in2", "ft²": "ft2", "yd²": "yd2", "mi²": "mi2",
+	"sq m": "m2", "sq ft": "ft2", "sq mi": "mi2", "square meters": "m2", "square feet": "ft2",
+	"acres": "acre", "hectare": "ha", "hectares": "ha",
+}
+
+// builtinConstants are the named constants available in expressions
+var builtinConstants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+	"PI": math.Pi,
+	"E":  math.E,
+}
+
+// ConversionRegistry is an indexed table of unit conversion factors and
+// named constants. A registry is built once and never modified, so any
+// number of calculators can share and read it without locking.
+type ConversionRegistry struct {
+	categories []string
+	units      map[string][]string           // Units of each category, in listing order
+	toBase     map[string]map[string]float64 // Base units in one of each unit
+	constants  map[string]float64
+}
+
+// ConversionData extends the built-in tables, e.g. from a data file.
+// Units maps categories to units and the number of base units in one of
+// each; units of a new category define its base unit implicitly. Existing
+// units and constants are overridden.
+type ConversionData struct {
+	Units     map[string]map[string]float64 `yaml:"units" json:"units"`
+	Constants map[string]float64            `yaml:"constants" json:"constants"`
+}
+
+// Extend returns the data with the units and constants of more added, those
+// of more replacing any of the same name
+func (d ConversionData) Extend(more ConversionData) ConversionData {
+	extended := ConversionData{
+		Units:     make(map[string]map[string]float64, len(d.Units)+len(more.Units)),
+		Constants: make(map[string]float64, len(d.Constants)+len(more.Constants)),
+	}
+	for _, data := range []ConversionData{d, more} {
+		for category, units := range data.Units {
+			if extended.Units[category] == nil {
+				extended.Units[category] = make(map[string]float64, len(units))
+			}
+			for unit, factor := range units {
+				extended.Units[category][unit] = factor
+			}
+		}
+		for name, value := range data.Constants {
+			extended.Constants[name] = value
+		}
+	}
+	return extended
+}
+
+var defaultRegistry atomic.Pointer[ConversionRegistry]
+
+// registryKey is the context key of the conversion registry of a request
+type registryKey struct{}
+
+// WithConversionRegistry returns a context whose unit conversions use
+// registry instead of the default one, e.g. the unit tables of one tenant
+func WithConversionRegistry(ctx context.Context, registry *ConversionRegistry) context.Context {
+	return context.WithValue(ctx, registryKey{}, registry)
+}
+
+// ConversionRegistryFromContext returns the registry set with
+// WithConversionRegistry, or nil when the context carries none
+func ConversionRegistryFromContext(ctx context.Context) *ConversionRegistry {
+	registry, _ := ctx.Value(registryKey{}).(*ConversionRegistry)
+	return registry
+}
+
+func init() {
+	registry, err := NewConversionRegistry(ConversionData{})
+	if err != nil {
+		panic(err)
+	}
+	defaultRegistry.Store(registry)
+}
+
+// DefaultConversionRegistry returns the registry used by new calculators
+func DefaultConversionRegistry() *ConversionRegistry {
+	return defaultRegistry.Load()
+}
+
+// SetDefaultConversionRegistry replaces the registry used by calculators
+// created from now on, typically once at startup
+func SetDefaultConversionRegistry(registry *ConversionRegistry) {
+	defaultRegistry.Store(registry)
+}
+
+// NewConversionRegistry builds a registry of the built-in tables extended by data
+func NewConversionRegistry(data ConversionData) (*ConversionRegistry, error) {
+	registry := &ConversionRegistry{
+		units:     make(map[string][]string),
+		toBase:    make(map[string]map[string]float64),
+		constants: make(map[string]float64, len(builtinConstants)+len(data.Constants)),
+	}
+	for _, table := range builtinUnits {
+		registry.categories = append(registry.categories, table.category)
+		for _, unit := range table.units {
+			registry.units[table.category] = append(registry.units[table.category], unit.unit)
+			if table.category != temperatureCategory {
+				registry.addFactor(table.category, unit.unit, unit.toBase)
+			}
+		}
+	}
+	for name, value := range builtinConstants {
+		registry.constants[name] = value
+	}
+
+	// Added categories and units are listed alphabetically after the built-in ones
+	categories := make([]string, 0, len(data.Units))
+	for category := range data.Units {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		if category == "" {
+			return nil, fmt.Errorf("unit categories cannot be empty")
+		}
+		if category == temperatureCategory {
+			return nil, fmt.Errorf("temperature units are converted by formula and cannot be added")
+		}
+		if _, exists := registry.units[category]; !exists {
+			registry.categories = append(registry.categories, category)
+		}
+		units := make([]string, 0, len(data.Units[category]))
+		for unit := range data.Units[category] {
+			units = append(units, unit)
+		}
+		sort.Strings(units)
+		for _, unit := range units {
+			factor := data.Units[category][unit]
+			if unit == "" {
+				return nil, fmt.Errorf("%s: unit names cannot be empty", category)
+			}
+			if math.IsNaN(factor) || math.IsInf(factor, 0) || factor <= 0 {
+				return nil, fmt.Errorf("%s unit %s: the factor must be a positive number, got %g", category, unit, factor)
+			}
+			if _, exists := registry.toBase[category][unit]; !exists {
+				registry.units[category] = append(registry.units[category], unit)
+			}
+			registry.addFactor(category, unit, factor)
+		}
+	}
+
+	for name, value := range data.Constants {
+		if !isIdentifier(name) || mathFunctionNames[strings.ToLower(name)] {
+			return nil, fmt.Errorf("invalid constant name: %s", name)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("constant %s must be finite, got %g", name, value)
+		}
+		registry.constants[name] = value
+	}
+	return registry, nil
+}
+
+// LoadConversionData reads a YAML or JSON data file of units and constants
+func LoadConversionData(path string) (ConversionData, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ConversionData{}, fmt.Errorf("failed to read conversion data: %v", err)
+	}
+	// YAML is a superset of JSON, so one decoder reads both
+	var data ConversionData
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return ConversionData{}, fmt.Errorf("invalid conversion data in %s: %v", path, err)
+	}
+	return data, nil
+}
+
+// LoadConversionRegistry builds a registry of the built-in tables extended
+// by a YAML or JSON data file
+func LoadConversionRegistry(path string) (*ConversionRegistry, error) {
+	data, err := LoadConversionData(path)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := NewConversionRegistry(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid conversion data in %s: %v", path, err)
+	}
+	return registry, nil
+}
+
+func (r *ConversionRegistry) addFactor(category, unit string, toBase float64) {
+	if r.toBase[category] == nil {
+		r.toBase[category] = make(map[string]float64)
+	}
+	r.toBase[category][unit] = toBase
+}
+
+// Categories returns the unit categories in listing order
+func (r *ConversionRegistry) Categories() []string {
+	return append([]string(nil), r.categories...)
+}
+
+// HasCategory reports whether the category exists
+func (r *ConversionRegistry) HasCategory(category string) bool {
+	_, exists := r.units[category]
+	return exists
+}
+
+// Units returns the units of a category in listing order
+func (r *ConversionRegistry) Units(category string) ([]string, bool) {
+	units, exists := r.units[category]
+	return append([]string(nil), units...), exists
+}
+
+// CanonicalUnit returns the unit of the tables that unit names: the unit
+// itself, a unit spelled out or in another case ("Kilometers", "KM"), or a
+// common symbol ("°C"). Names that match units of more than one category
+// are left alone, as they are ambiguous.
+func (r *ConversionRegistry) CanonicalUnit(unit string) (string, bool) {
+	name := strings.TrimSpace(unit)
+	lower := strings.ToLower(name)
+	if alias, ok := unitAliases[lower]; ok {
+		lower = strings.ToLower(alias)
+	}
+
+	match, ambiguous := "", false
+	for _, category := range r.categories {
+		for _, candidate := range r.units[category] {
+			if candidate == name {
+				return candidate, true
+			}
+			if strings.ToLower(candidate) == lower {
+				ambiguous = ambiguous || (match != "" && match != candidate)
+				match = candidate
+			}
+		}
+	}
+	if match == "" || ambiguous {
+		return unit, false
+	}
+	return match, true
+}
+
+// Factor returns the number of base units of its category in one unit.
+// Temperatures have no factor.
+func (r *ConversionRegistry) Factor(category, unit string) (float64, bool) {
+	factor, exists := r.toBase[category][unit]
+	return factor, exists
+}
+
+// Convert converts a value between two units of a category with factors
+func (r *ConversionRegistry) Convert(value float64, fromUnit, toUnit, category string) (float64, error) {
+	toBase, exists := r.toBase[category]
+	if !exists {
+		return 0, fmt.Errorf("category not supported: %s", category)
+	}
+	if fromUnit == toUnit {
+		return value, nil
+	}
+	fromFactor, fromExists := toBase[fromUnit]
+	toFactor, toExists := toBase[toUnit]
+	if !fromExists {
+		return 0, fmt.Errorf("unsupported unit: %s", fromUnit)
+	}
+	if !toExists {
+		return 0, fmt.Errorf("unsupported unit: %s", toUnit)
+	}
+
+	// Convert to base unit, then to target unit
+	return value * fromFactor / toFactor, nil
+}
+
+// Constant returns the value of a named constant
+func (r *ConversionRegistry) Constant(name string) (float64, bool) {
+	value, exists := r.constants[name]
+	return value, exists
+}
+
+// Constants returns a copy of the named constants
+func (r *ConversionRegistry) Constants() map[string]float64 {
+	constants := make(map[string]float64, len(r.constants))
+	for name, value := range r.constants {
+		constants[name] = value
+	}
+	return constants
+}
@@ -0,0 +1,165 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// defaultEmergencyFundMonths is the usual advice of three to six months
+	// of essential expenses, taking the cautious end
+	defaultEmergencyFundMonths = 6
+
+	// Debt-to-income thresholds in percent: 36% is the common guideline and
+	// 43% the usual ceiling for a qualified mortgage
+	healthyDebtToIncome = 36
+	maxDebtToIncome     = 43
+)
+
+// GetSupportedRules returns the operations accepted by RuleOfThumb
+func (fc *FinancialCalculator) GetSupportedRules() []string {
+	return []string{"rule_of_72", "budget_50_30_20", "debt_to_income", "emergency_fund"}
+}
+
+// RuleOfThumb evaluates a quick personal-finance rule of thumb. Amounts are
+// per month except where noted.
+func (fc *FinancialCalculator) RuleOfThumb(req types.RuleOfThumbRequest) (types.FinancialResult, error) {
+	inputs := []struct {
+		name  string
+		value float64
+	}{
+		{"rate", req.Rate}, {"income", req.Income}, {"debtPayments", req.DebtPayments},
+		{"expenses", req.Expenses}, {"savings", req.Savings}, {"months", req.Months},
+	}
+	for _, input := range inputs {
+		if math.IsNaN(input.value) || math.IsInf(input.value, 0) {
+			return types.FinancialResult{}, fmt.Errorf("%s must be a finite number", input.name)
+		}
+		if input.value < 0 {
+			return types.FinancialResult{}, fmt.Errorf("%s cannot be negative", input.name)
+		}
+	}
+
+	switch req.Operation {
+	case "rule_of_72":
+		return ruleOf72(req)
+	case "budget_50_30_20":
+		return budget503020(req)
+	case "debt_to_income":
+		return debtToIncome(req)
+	case "emergency_fund":
+		return emergencyFund(req)
+	default:
+		return types.FinancialResult{}, fmt.Errorf("unsupported rule: %s. Supported rules: %v", req.Operation, fc.GetSupportedRules())
+	}
+}
+
+// ruleOf72 estimates the years needed to double money as 72 / rate and
+// compares it with the exact ln 2 / ln(1 + rate)
+func ruleOf72(req types.RuleOfThumbRequest) (types.FinancialResult, error) {
+	if req.Rate <= 0 {
+		return types.FinancialResult{}, fmt.Errorf("rate must be positive")
+	}
+
+	estimate := 72 / req.Rate
+	exact := math.Ln2 / math.Log1p(req.Rate/100)
+
+	return types.FinancialResult{
+		Result: estimate,
+		Breakdown: map[string]interface{}{
+			"rate_percent":     req.Rate,
+			"estimated_years":  estimate,
+			"exact_years":      exact,
+			"estimate_error":   estimate - exact,
+			"rule_of_70_years": 70 / req.Rate,
+		},
+		Description: fmt.Sprintf("Money doubles in about %.1f years at %g%% a year", estimate, req.Rate),
+	}, nil
+}
+
+// budget503020 splits take-home income into 50% needs, 30% wants and 20%
+// savings; the result is the savings target
+func budget503020(req types.RuleOfThumbRequest) (types.FinancialResult, error) {
+	if req.Income <= 0 {
+		return types.FinancialResult{}, fmt.Errorf("income must be positive")
+	}
+
+	needs, wants, savings := req.Income*0.5, req.Income*0.3, req.Income*0.2
+	breakdown := map[string]interface{}{
+		"income":  req.Income,
+		"needs":   needs,
+		"wants":   wants,
+		"savings": savings,
+	}
+	if req.Expenses > 0 {
+		breakdown["expenses"] = req.Expenses
+		breakdown["needs_percent"] = req.Expenses / req.Income * 100
+		breakdown["within_needs"] = req.Expenses <= needs
+	}
+
+	return types.FinancialResult{
+		Result:      savings,
+		Breakdown:   breakdown,
+		Description: "50/30/20 budget: 50% needs, 30% wants, 20% savings",
+	}, nil
+}
+
+// debtToIncome returns monthly debt payments as a percentage of gross
+// monthly income
+func debtToIncome(req types.RuleOfThumbRequest) (types.FinancialResult, error) {
+	if req.Income <= 0 {
+		return types.FinancialResult{}, fmt.Errorf("income must be positive")
+	}
+
+	ratio := req.DebtPayments / req.Income * 100
+	assessment := "healthy"
+	switch {
+	case ratio > maxDebtToIncome:
+		assessment = "high"
+	case ratio > healthyDebtToIncome:
+		assessment = "manageable"
+	}
+
+	return types.FinancialResult{
+		Result: ratio,
+		Breakdown: map[string]interface{}{
+			"income":                 req.Income,
+			"debt_payments":          req.DebtPayments,
+			"ratio_percent":          ratio,
+			"assessment":             assessment,
+			"healthy_max_payments":   req.Income * healthyDebtToIncome / 100,
+			"qualified_mortgage_max": req.Income * maxDebtToIncome / 100,
+			"healthy_limit_percent":  healthyDebtToIncome,
+			"mortgage_limit_percent": maxDebtToIncome,
+		},
+		Description: fmt.Sprintf("Debt-to-income ratio of %.1f%% (%s)", ratio, assessment),
+	}, nil
+}
+
+// emergencyFund sizes a fund as months of essential expenses and, given
+// current savings, the shortfall
+func emergencyFund(req types.RuleOfThumbRequest) (types.FinancialResult, error) {
+	if req.Expenses <= 0 {
+		return types.FinancialResult{}, fmt.Errorf("expenses must be positive")
+	}
+	months := req.Months
+	if months == 0 {
+		months = defaultEmergencyFundMonths
+	}
+
+	target := req.Expenses * months
+	return types.FinancialResult{
+		Result: target,
+		Breakdown: map[string]interface{}{
+			"monthly_expenses": req.Expenses,
+			"months":           months,
+			"target":           target,
+			"savings":          req.Savings,
+			"shortfall":        math.Max(0, target-req.Savings),
+			"months_covered":   req.Savings / req.Expenses,
+		},
+		Description: fmt.Sprintf("Emergency fund of %g months of expenses", months),
+	}, nil
+}
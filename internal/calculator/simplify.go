@@ -0,0 +1,471 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// maxSimplifyPower bounds the integer powers that are expanded
+	maxSimplifyPower = 100
+
+	// maxSimplifyDepth bounds the nesting of parentheses and function calls
+	maxSimplifyDepth = 100
+)
+
+// simplifyFunctions are the functions accepted by Simplify and their
+// number of arguments. Calls are kept as symbols, except pow, which is a
+// power, and abs of a number.
+var simplifyFunctions = map[string]int{
+	"sin": 1, "cos": 1, "tan": 1, "asin": 1, "acos": 1, "atan": 1,
+	"log": 1, "ln": 1, "abs": 1, "sqrt": 1, "exp": 1, "factorial": 1,
+	"pow": 2,
+}
+
+// rationalFunction is a quotient of polynomials. Normalized, it has no
+// common factors found by cancel, and the denominator is 1 or has coprime
+// integer coefficients and a positive leading coefficient.
+type rationalFunction struct {
+	num, den polynomial
+}
+
+func rationalConstant(value *big.Rat) rationalFunction {
+	return rationalFunction{num: constantPolynomial(value), den: constantPolynomial(big.NewRat(1, 1))}
+}
+
+func rationalSymbol(name string) rationalFunction {
+	return rationalFunction{num: symbolPolynomial(name), den: constantPolynomial(big.NewRat(1, 1))}
+}
+
+// constant returns the value of a rational function without symbols
+func (r rationalFunction) constant() (*big.Rat, bool) {
+	num, ok := r.num.constant()
+	if !ok {
+		return nil, false
+	}
+	den, ok := r.den.constant()
+	if !ok {
+		return nil, false
+	}
+	return num.Quo(num, den), true
+}
+
+func (r rationalFunction) plus(ctx context.Context, other rationalFunction) (rationalFunction, error) {
+	if r.den.equals(other.den) {
+		return rationalFunction{num: r.num.plus(other.num), den: r.den}.normalize(ctx)
+	}
+	left, err := r.num.times(other.den)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	right, err := other.num.times(r.den)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	den, err := r.den.times(other.den)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	return rationalFunction{num: left.plus(right), den: den}.normalize(ctx)
+}
+
+func (r rationalFunction) negated() rationalFunction {
+	return rationalFunction{num: r.num.scaled(big.NewRat(-1, 1)), den: r.den}
+}
+
+func (r rationalFunction) times(ctx context.Context, other rationalFunction) (rationalFunction, error) {
+	num, err := r.num.times(other.num)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	den, err := r.den.times(other.den)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	return rationalFunction{num: num, den: den}.normalize(ctx)
+}
+
+func (r rationalFunction) over(ctx context.Context, other rationalFunction) (rationalFunction, error) {
+	if len(other.num) == 0 {
+		return rationalFunction{}, fmt.Errorf("division by zero")
+	}
+	return r.times(ctx, rationalFunction{num: other.den, den: other.num})
+}
+
+// power raises r to an integer power
+func (r rationalFunction) power(ctx context.Context, n int) (rationalFunction, error) {
+	if n < 0 {
+		if len(r.num) == 0 {
+			return rationalFunction{}, fmt.Errorf("division by zero: 0 raised to negative power %d", n)
+		}
+		r, n = rationalFunction{num: r.den, den: r.num}, -n
+	}
+	num, err := r.num.power(n)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	den, err := r.den.power(n)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	return rationalFunction{num: num, den: den}.normalize(ctx)
+}
+
+// normalize cancels common factors and scales the numerator and
+// denominator to their normal form
+func (r rationalFunction) normalize(ctx context.Context) (rationalFunction, error) {
+	if len(r.num) == 0 {
+		return rationalConstant(new(big.Rat)), nil
+	}
+	if value, ok := r.den.constant(); ok {
+		return rationalFunction{num: r.num.scaled(value.Inv(value)), den: constantPolynomial(big.NewRat(1, 1))}, nil
+	}
+
+	symbols := r.num.plus(r.den).symbols()
+	if len(symbols) == 1 {
+		// Euclid's algorithm finds the greatest common divisor in one symbol
+		num, den := denseCoefficients(r.num, symbols[0]), denseCoefficients(r.den, symbols[0])
+		gcd, err := gcdDense(ctx, num, den)
+		if err != nil {
+			return rationalFunction{}, err
+		}
+		if len(gcd) > 1 {
+			num, _ = divideDense(num, gcd)
+			den, _ = divideDense(den, gcd)
+			r = rationalFunction{num: sparsePolynomial(num, symbols[0]), den: sparsePolynomial(den, symbols[0])}
+		}
+	} else {
+		var err error
+		if r, err = cancelFactors(ctx, r); err != nil {
+			return rationalFunction{}, err
+		}
+	}
+
+	if value, ok := r.den.constant(); ok {
+		return rationalFunction{num: r.num.scaled(value.Inv(value)), den: constantPolynomial(big.NewRat(1, 1))}, nil
+	}
+
+	// Scale both to coprime integer coefficients, with the denominator's
+	// leading coefficient positive
+	numContent, _ := primitivePart(r.num)
+	denContent, _ := primitivePart(r.den)
+	scale := new(big.Rat).SetFrac(
+		new(big.Int).GCD(nil, nil, numContent.Num(), denContent.Num()),
+		new(big.Int).Mul(numContent.Denom(), new(big.Int).Quo(denContent.Denom(), new(big.Int).GCD(nil, nil, numContent.Denom(), denContent.Denom()))),
+	)
+	if denContent.Sign() < 0 {
+		scale.Neg(scale)
+	}
+	scale.Inv(scale)
+	return rationalFunction{num: r.num.scaled(scale), den: r.den.scaled(scale)}, nil
+}
+
+// cancelFactors cancels a numerator and denominator in several symbols that
+// divide one another, or else the common monomial and factors that
+// factorPolynomial finds in both
+func cancelFactors(ctx context.Context, r rationalFunction) (rationalFunction, error) {
+	if quotient, ok := r.num.dividedBy(r.den); ok {
+		return rationalFunction{num: quotient, den: constantPolynomial(big.NewRat(1, 1))}, nil
+	}
+	if quotient, ok := r.den.dividedBy(r.num); ok {
+		return rationalFunction{num: constantPolynomial(big.NewRat(1, 1)), den: quotient}, nil
+	}
+
+	num, err := factorPolynomial(ctx, r.num)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	den, err := factorPolynomial(ctx, r.den)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	common := sharedMonomial(num.mono, den.mono)
+	cancelled := len(common) > 0
+	num.mono, _ = num.mono.over(common)
+	den.mono, _ = den.mono.over(common)
+
+	for i := range num.factors {
+		for j := range den.factors {
+			if num.factors[i].power > 0 && den.factors[j].power > 0 && num.factors[i].poly.equals(den.factors[j].poly) {
+				shared := num.factors[i].power
+				if den.factors[j].power < shared {
+					shared = den.factors[j].power
+				}
+				num.factors[i].power -= shared
+				den.factors[j].power -= shared
+				cancelled = true
+			}
+		}
+	}
+	if !cancelled {
+		return r, nil
+	}
+
+	numerator, err := num.expand()
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	denominator, err := den.expand()
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	return rationalFunction{num: numerator, den: denominator}, nil
+}
+
+// String writes the expanded numerator over the expanded denominator
+func (r rationalFunction) String() string {
+	if _, ok := r.den.constant(); ok {
+		return r.num.String()
+	}
+	num, den := r.num.String(), r.den.String()
+	if len(r.num) > 1 {
+		num = "(" + num + ")"
+	}
+	if len(r.den) > 1 || strings.ContainsAny(den, "*/") {
+		den = "(" + den + ")"
+	}
+	return num + "/" + den
+}
+
+// factored writes the factored numerator over the factored denominator
+func (r rationalFunction) factored(ctx context.Context) (string, error) {
+	numerator, err := factorPolynomial(ctx, r.num)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := r.den.constant(); ok {
+		return numerator.String(), nil
+	}
+	denominator, err := factorPolynomial(ctx, r.den)
+	if err != nil {
+		return "", err
+	}
+	num, den := numerator.format(true), denominator.format(true)
+	if strings.Contains(num, "/") {
+		num = "(" + num + ")"
+	}
+	if strings.ContainsAny(den, "*/") {
+		den = "(" + den + ")"
+	}
+	return num + "/" + den, nil
+}
+
+// Simplify expands the expression, combines like terms and cancels common
+// factors of the numerator and denominator. Given variables are substituted
+// first. Function calls such as sin(x) are kept as symbols, and powers must
+// have integer exponents to be expanded. Expansion is bounded in degree and
+// size, progress is reported to the ProgressFunc of ctx after the expansion
+// and the factoring, and the work stops early once ctx is done.
+func (ec *ExpressionCalculator) Simplify(ctx context.Context, req types.ExpressionRequest) (types.SimplifyResult, error) {
+	if strings.TrimSpace(req.Expression) == "" {
+		return types.SimplifyResult{}, fmt.Errorf("expression cannot be empty")
+	}
+	if req.Explain {
+		return types.SimplifyResult{}, fmt.Errorf("explain is not available with simplify")
+	}
+	values := make(map[string]*big.Rat, len(req.Variables))
+	for name, value := range req.Variables {
+		if !ec.isValidVariableName(name) {
+			return types.SimplifyResult{}, fmt.Errorf("invalid variable name: %s", name)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return types.SimplifyResult{}, fmt.Errorf("invalid variable value for %s: %f", name, value)
+		}
+		values[name] = exactRational(value)
+	}
+
+	parser := newSimplifyParser(ctx, req.Expression, values)
+	expression, err := parser.parse()
+	if err != nil {
+		if ctx.Err() != nil {
+			return types.SimplifyResult{}, ctx.Err()
+		}
+		return types.SimplifyResult{}, fmt.Errorf("cannot simplify: %v", err)
+	}
+
+	reportProgress(ctx, 1, 2, "expanded")
+
+	result := types.SimplifyResult{
+		Expression: req.Expression,
+		Canonical:  expression.String(),
+		Variables:  make([]string, 0, len(parser.variables)),
+	}
+	result.Simplified = result.Canonical
+	factored, err := expression.factored(ctx)
+	if err != nil {
+		return types.SimplifyResult{}, err
+	}
+	reportProgress(ctx, 2, 2, "factored")
+	if factored != result.Canonical {
+		result.Factored = factored
+		if len(factored) < len(result.Canonical) {
+			result.Simplified = factored
+		}
+	}
+	for name := range parser.variables {
+		result.Variables = append(result.Variables, name)
+	}
+	sort.Strings(result.Variables)
+	if value, ok := expression.constant(); ok {
+		number, _ := value.Float64()
+		result.Value = &number
+	}
+	return result, nil
+}
+
+// exactRational converts a finite float64 through its shortest decimal
+// form, so that 0.1 becomes exactly 1/10
+func exactRational(value float64) *big.Rat {
+	exact, _ := new(big.Rat).SetString(strconv.FormatFloat(value, 'g', -1, 64))
+	return exact
+}
+
+// simplifyParser folds an expression, read by parseSyntax, into a rational
+// function
+type simplifyParser struct {
+	ctx       context.Context
+	input     string
+	values    map[string]*big.Rat
+	variables map[string]bool
+
+	// powers records the base and exponent of each pow symbol
+	powers map[string]powerSymbol
+
+	// dividesBySymbol is set when the expression divides by an expression
+	// with symbols, so cancelling may hide a zero denominator
+	dividesBySymbol bool
+}
+
+// powerSymbol is a power kept as a symbol, as its exponent is not an integer
+type powerSymbol struct {
+	base, exponent rationalFunction
+}
+
+func newSimplifyParser(ctx context.Context, input string, values map[string]*big.Rat) *simplifyParser {
+	return &simplifyParser{ctx: ctx, input: input, values: values, variables: make(map[string]bool), powers: make(map[string]powerSymbol)}
+}
+
+func (p *simplifyParser) parse() (rationalFunction, error) {
+	expression, err := parseSyntax(p.input)
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	return p.fold(expression)
+}
+
+// fold computes the rational function of a syntax node
+func (p *simplifyParser) fold(n syntaxNode) (rationalFunction, error) {
+	switch n.kind {
+	case nodeNumber:
+		value, _ := new(big.Rat).SetString(n.text)
+		return rationalConstant(value), nil
+	case nodeSymbol:
+		return p.symbol(n.text), nil
+	case nodeCall:
+		args := make([]rationalFunction, len(n.args))
+		for i, arg := range n.args {
+			var err error
+			if args[i], err = p.fold(arg); err != nil {
+				return rationalFunction{}, err
+			}
+		}
+		return p.call(n.text, args)
+	case nodeNegate:
+		operand, err := p.fold(n.args[0])
+		if err != nil {
+			return rationalFunction{}, err
+		}
+		return operand.negated(), nil
+	}
+
+	left, err := p.fold(n.args[0])
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	right, err := p.fold(n.args[1])
+	if err != nil {
+		return rationalFunction{}, err
+	}
+	switch n.kind {
+	case nodeAdd:
+		return left.plus(p.ctx, right)
+	case nodeSubtract:
+		return left.plus(p.ctx, right.negated())
+	case nodeMultiply:
+		return left.times(p.ctx, right)
+	case nodeDivide:
+		if _, ok := right.constant(); !ok {
+			p.dividesBySymbol = true
+		}
+		return left.over(p.ctx, right)
+	default:
+		return p.raise(left, right)
+	}
+}
+
+// symbol resolves a name to a constant, a given value or a variable
+func (p *simplifyParser) symbol(name string) rationalFunction {
+	switch name {
+	case "pi", "PI":
+		return rationalSymbol("pi")
+	case "e", "E":
+		return rationalSymbol("e")
+	}
+	if value, ok := p.values[name]; ok {
+		return rationalConstant(value)
+	}
+	p.variables[name] = true
+	return rationalSymbol(name)
+}
+
+// call keeps a function call, with its arguments simplified, as a symbol
+// such as "sin(2*x)"
+func (p *simplifyParser) call(name string, args []rationalFunction) (rationalFunction, error) {
+	switch name {
+	case "pow":
+		return p.raise(args[0], args[1])
+	case "exp":
+		return p.raise(rationalSymbol("e"), args[0])
+	case "abs":
+		if value, ok := args[0].constant(); ok {
+			return rationalConstant(value.Abs(value)), nil
+		}
+	}
+	return rationalSymbol(fmt.Sprintf("%s(%s)", name, args[0])), nil
+}
+
+// raise expands a power with a small integer exponent; any other power is
+// kept as a pow symbol
+func (p *simplifyParser) raise(base, exponent rationalFunction) (rationalFunction, error) {
+	value, ok := exponent.constant()
+	if !ok || !value.IsInt() {
+		name := fmt.Sprintf("pow(%s, %s)", base, exponent)
+		if base.String() == "e" {
+			name = fmt.Sprintf("exp(%s)", exponent)
+		}
+		p.powers[name] = powerSymbol{base: base, exponent: exponent}
+		return rationalSymbol(name), nil
+	}
+	if !value.Num().IsInt64() || value.Num().Int64() > maxSimplifyPower || value.Num().Int64() < -maxSimplifyPower {
+		return rationalFunction{}, fmt.Errorf("exponent %s is too large to expand (maximum %d)", value.RatString(), maxSimplifyPower)
+	}
+	if _, constant := base.constant(); value.Sign() < 0 && !constant {
+		p.dividesBySymbol = true
+	}
+	return base.power(p.ctx, int(value.Num().Int64()))
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentifierStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
@@ -6,10 +6,14 @@ import (
 	"sort"
 
 	"calculator-server/internal/types"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/stat"
 )
 
-type StatisticsCalculator struct{}
+type StatisticsCalculator struct {
+	workers           int // Goroutines for large datasets; 0 uses every CPU
+	parallelThreshold int // Dataset size from which work is parallel; 0 is the default
+}
 
 func NewStatisticsCalculator() *StatisticsCalculator {
 	return &StatisticsCalculator{}
@@ -24,29 +28,54 @@ func (sc *StatisticsCalculator) Calculate(req types.StatisticsRequest) (types.St
 	if err := sc.validateData(req.Data); err != nil {
 		return types.StatisticsResult{}, err
 	}
+	if err := sc.ValidateWeights(req.Data, req.Weights); err != nil {
+		return types.StatisticsResult{}, err
+	}
+
+	population, err := sc.varianceType(req)
+	if err != nil {
+		return types.StatisticsResult{}, err
+	}
 
 	var result interface{}
-	var err error
 
 	switch req.Operation {
 	case "mean":
-		result = sc.mean(req.Data)
+		result = sc.mean(req.Data, req.Weights)
 	case "median":
-		result = sc.median(req.Data)
+		result = sc.median(req.Data, req.Weights)
 	case "mode":
+		if req.Weights != nil {
+			return types.StatisticsResult{}, fmt.Errorf("weights are not supported for mode")
+		}
 		result, err = sc.mode(req.Data)
 		if err != nil {
 			return types.StatisticsResult{}, err
 		}
 	case "std_dev":
-		result = sc.standardDeviation(req.Data)
+		if !population {
+			if err := sc.validateSample(req.Data, req.Weights); err != nil {
+				return types.StatisticsResult{}, err
+			}
+		}
+		result = sc.standardDeviation(req.Data, req.Weights, population)
 	case "variance":
-		result = sc.variance(req.Data)
+		if !population {
+			if err := sc.validateSample(req.Data, req.Weights); err != nil {
+				return types.StatisticsResult{}, err
+			}
+		}
+		result = sc.variance(req.Data, req.Weights, population)
 	case "percentile":
 		// For percentile, we need an additional parameter
 		// For now, we'll calculate common percentiles
-		percentiles := sc.percentiles(req.Data, []float64{25, 50, 75, 90, 95, 99})
+		percentiles := sc.percentiles(req.Data, req.Weights, []float64{25, 50, 75, 90, 95, 99})
 		result = percentiles
+	case "regression":
+		result, err = sc.Regression(req.X, req.Data, req.Weights)
+		if err != nil {
+			return types.StatisticsResult{}, err
+		}
 	default:
 		return types.StatisticsResult{}, fmt.Errorf("unsupported operation: %s", req.Operation)
 	}
@@ -57,8 +86,148 @@ func (sc *StatisticsCalculator) Calculate(req types.StatisticsRequest) (types.St
 	}, nil
 }
 
+// Clean applies the request's cleaning options to its data ahead of
+// Calculate, in order: missing (NaN) points are dropped or imputed,
+// repeated points removed and the rest clipped to [ClipMin, ClipMax]. The x
+// values and weights of dropped points are dropped with them. The report is
+// nil when no option is set.
+func (sc *StatisticsCalculator) Clean(req *types.StatisticsRequest) (*types.CleaningReport, error) {
+	missing := req.Missing
+	if missing == "" {
+		missing = "error"
+	}
+	switch missing {
+	case "error", "drop", "mean", "median":
+		if req.FillValue != nil {
+			return nil, fmt.Errorf("fill_value is only used with missing \"value\"")
+		}
+	case "value":
+		if req.FillValue == nil {
+			return nil, fmt.Errorf("missing \"value\" requires a fill_value")
+		}
+		if err := requireFinite("fill_value", *req.FillValue); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported missing option: %s. Supported options: [error drop mean median value]", missing)
+	}
+	if req.ClipMin != nil {
+		if err := requireFinite("clip_min", *req.ClipMin); err != nil {
+			return nil, err
+		}
+	}
+	if req.ClipMax != nil {
+		if err := requireFinite("clip_max", *req.ClipMax); err != nil {
+			return nil, err
+		}
+	}
+	if req.ClipMin != nil && req.ClipMax != nil && *req.ClipMin > *req.ClipMax {
+		return nil, fmt.Errorf("clip_min (%g) must not exceed clip_max (%g)", *req.ClipMin, *req.ClipMax)
+	}
+	if req.Deduplicate && req.Weights != nil {
+		return nil, fmt.Errorf("deduplicate cannot be combined with weights, which already count repeated points")
+	}
+	// Points are matched with their x values and weights when dropped
+	if req.X != nil && len(req.X) != len(req.Data) {
+		return nil, fmt.Errorf("regression requires one x value per data point: got %d x values for %d data points", len(req.X), len(req.Data))
+	}
+	if err := sc.ValidateWeights(req.Data, req.Weights); err != nil {
+		return nil, err
+	}
+
+	report := &types.CleaningReport{OriginalCount: len(req.Data)}
+	var present, presentWeights []float64
+	for i, value := range req.Data {
+		switch {
+		case math.IsNaN(value):
+			report.Missing++
+		case req.Weights != nil:
+			present, presentWeights = append(present, value), append(presentWeights, req.Weights[i])
+		default:
+			present = append(present, value)
+		}
+	}
+
+	// Imputed values are the (weighted) mean or median of the present points
+	fill := math.NaN()
+	if report.Missing > 0 {
+		switch missing {
+		case "mean", "median":
+			if len(present) == 0 || (presentWeights != nil && floats.Sum(presentWeights) == 0) {
+				return nil, fmt.Errorf("cannot impute the %s of data with no values", missing)
+			}
+			if missing == "mean" {
+				fill = sc.mean(present, presentWeights)
+			} else {
+				fill = sc.median(present, presentWeights)
+			}
+			report.FillValue = &fill
+		case "value":
+			fill = *req.FillValue
+			report.FillValue = &fill
+		}
+	}
+
+	seen := make(map[[2]float64]bool)
+	data, x, weights := make([]float64, 0, len(req.Data)), []float64(nil), []float64(nil)
+	for i, value := range req.Data {
+		if math.IsNaN(value) {
+			switch missing {
+			case "error":
+				return nil, fmt.Errorf("data point %d is missing; set missing to drop or impute it", i)
+			case "drop":
+				report.Dropped++
+				continue
+			default:
+				value = fill
+				report.Imputed++
+			}
+		}
+		if req.Deduplicate {
+			key := [2]float64{value, 0}
+			if req.X != nil {
+				key[1] = req.X[i]
+			}
+			if seen[key] {
+				report.DuplicatesRemoved++
+				continue
+			}
+			seen[key] = true
+		}
+		if req.ClipMin != nil && value < *req.ClipMin {
+			value = *req.ClipMin
+			report.Clipped++
+		} else if req.ClipMax != nil && value > *req.ClipMax {
+			value = *req.ClipMax
+			report.Clipped++
+		}
+		data = append(data, value)
+		if req.X != nil {
+			x = append(x, req.X[i])
+		}
+		if req.Weights != nil {
+			weights = append(weights, req.Weights[i])
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no data points are left after cleaning")
+	}
+
+	req.Data, req.X, req.Weights = data, x, weights
+	if missing == "error" && !req.Deduplicate && req.ClipMin == nil && req.ClipMax == nil {
+		return nil, nil
+	}
+	return report, nil
+}
+
 // CalculatePercentile calculates a specific percentile
 func (sc *StatisticsCalculator) CalculatePercentile(data []float64, percentile float64) (float64, error) {
+	return sc.CalculateWeightedPercentile(data, nil, percentile)
+}
+
+// CalculateWeightedPercentile calculates a specific percentile of data
+// whose points carry frequency weights; nil weights count every point once
+func (sc *StatisticsCalculator) CalculateWeightedPercentile(data, weights []float64, percentile float64) (float64, error) {
 	if len(data) == 0 {
 		return 0, fmt.Errorf("data set cannot be empty")
 	}
@@ -69,26 +238,230 @@ func (sc *StatisticsCalculator) CalculatePercentile(data []float64, percentile f
 	if err := sc.validateData(data); err != nil {
 		return 0, err
 	}
+	if err := sc.ValidateWeights(data, weights); err != nil {
+		return 0, err
+	}
 
-	// Create a copy and sort it
-	sortedData := make([]float64, len(data))
-	copy(sortedData, data)
-	sort.Float64s(sortedData)
+	if weights == nil {
+		return sc.quantiles(data, percentile/100.0)[0], nil
+	}
+	sortedData, sortedWeights := sortWeighted(data, weights)
+	return stat.Quantile(percentile/100.0, stat.Empirical, sortedData, sortedWeights), nil
+}
+
+// PercentileByMethod calculates a percentile "exact"ly, with optional
+// weights, or estimates it with the "p2" algorithm of ApproximatePercentile
+func (sc *StatisticsCalculator) PercentileByMethod(data, weights []float64, percentile float64, method string) (float64, error) {
+	switch method {
+	case "exact":
+		return sc.CalculateWeightedPercentile(data, weights, percentile)
+	case "p2":
+		if weights != nil {
+			return 0, fmt.Errorf("method p2 does not support weights")
+		}
+		return sc.ApproximatePercentile(data, percentile)
+	default:
+		return 0, fmt.Errorf("unsupported percentile method: %s. Supported methods: [exact p2]", method)
+	}
+}
+
+// ApproximatePercentile estimates a percentile in one pass and constant
+// memory with the P² algorithm, for datasets too large to copy. The estimate
+// is exact for the 0th and 100th percentiles and up to five points; for
+// smooth distributions it is usually within a fraction of a percent of the
+// data's range of the exact value, but it is not guaranteed to be a data
+// point.
+func (sc *StatisticsCalculator) ApproximatePercentile(data []float64, percentile float64) (float64, error) {
+	if len(data) == 0 {
+		return 0, fmt.Errorf("data set cannot be empty")
+	}
+	if percentile < 0 || percentile > 100 {
+		return 0, fmt.Errorf("percentile must be between 0 and 100")
+	}
+	if err := sc.validateData(data); err != nil {
+		return 0, err
+	}
 
-	return stat.Quantile(percentile/100.0, stat.Empirical, sortedData, nil), nil
+	estimator := newP2Quantile(percentile / 100.0)
+	for _, value := range data {
+		estimator.add(value)
+	}
+	return estimator.value(), nil
 }
 
-func (sc *StatisticsCalculator) mean(data []float64) float64 {
-	return stat.Mean(data, nil)
+// Regression fits y = intercept + slope·x by (weighted) least squares
+func (sc *StatisticsCalculator) Regression(x, y, weights []float64) (types.RegressionResult, error) {
+	if len(x) != len(y) {
+		return types.RegressionResult{}, fmt.Errorf("regression requires one x value per data point: got %d x values for %d data points", len(x), len(y))
+	}
+	if len(y) < 2 {
+		return types.RegressionResult{}, fmt.Errorf("regression requires at least 2 data points")
+	}
+	if err := sc.validateData(x); err != nil {
+		return types.RegressionResult{}, fmt.Errorf("x: %v", err)
+	}
+	if err := sc.validateData(y); err != nil {
+		return types.RegressionResult{}, err
+	}
+	if err := sc.ValidateWeights(y, weights); err != nil {
+		return types.RegressionResult{}, err
+	}
+	if sc.variance(x, weights, false) == 0 {
+		return types.RegressionResult{}, fmt.Errorf("regression requires at least two distinct x values")
+	}
+
+	intercept, slope := stat.LinearRegression(x, y, weights, false)
+	return types.RegressionResult{
+		Slope:     slope,
+		Intercept: intercept,
+		RSquared:  stat.RSquared(x, y, weights, intercept, slope),
+		Count:     len(y),
+		Weighted:  weights != nil,
+	}, nil
 }
 
-func (sc *StatisticsCalculator) median(data []float64) float64 {
-	// Create a copy and sort it
+// ValidateWeights checks that weights, when given, hold one finite,
+// non-negative weight per data point and are not all zero
+func (sc *StatisticsCalculator) ValidateWeights(data, weights []float64) error {
+	if weights == nil {
+		return nil
+	}
+	if len(weights) != len(data) {
+		return fmt.Errorf("weights must have one entry per data point: got %d weights for %d data points", len(weights), len(data))
+	}
+
+	total := 0.0
+	for i, weight := range weights {
+		if math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return fmt.Errorf("weight %d must be a finite number", i)
+		}
+		if weight < 0 {
+			return fmt.Errorf("weight %d is negative: %g", i, weight)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("weights cannot all be zero")
+	}
+	return nil
+}
+
+// varianceType reports whether std_dev and variance divide by the number of
+// points (population) rather than by one less (sample, the default)
+func (sc *StatisticsCalculator) varianceType(req types.StatisticsRequest) (bool, error) {
+	switch req.Type {
+	case "", "sample", "population":
+	default:
+		return false, fmt.Errorf("unsupported variance type: %s. Supported types: [sample population]", req.Type)
+	}
+	if req.Type != "" && req.Operation != "std_dev" && req.Operation != "variance" {
+		return false, fmt.Errorf("type applies only to std_dev and variance, not to %s", req.Operation)
+	}
+	return req.Type == "population", nil
+}
+
+// Method describes how Calculate computes an operation, for the provenance
+// of its results. varianceType is that of std_dev and variance, and
+// weighted whether the data carries frequency weights.
+func (sc *StatisticsCalculator) Method(operation, varianceType string, weighted bool) string {
+	var method string
+	switch operation {
+	case "mean":
+		method = "arithmetic mean"
+	case "median":
+		method = "empirical median (lower middle value, no interpolation)"
+	case "mode":
+		method = "most frequent values"
+	case "std_dev", "variance":
+		if varianceType == "population" {
+			method = "population variance (divides by n)"
+		} else {
+			method = "sample variance (divides by n − 1)"
+		}
+		if operation == "std_dev" {
+			method = "square root of " + method
+		}
+	case "percentile":
+		method = "empirical quantile (smallest value with cumulative share ≥ p, no interpolation)"
+	case "regression":
+		method = "ordinary least squares"
+	default:
+		return ""
+	}
+	if weighted {
+		method += ", frequency weighted"
+	}
+	return method
+}
+
+// validateSample rejects data too small for the unbiased sample variance,
+// which divides by the number of points less one
+func (sc *StatisticsCalculator) validateSample(data, weights []float64) error {
+	if weights == nil && len(data) < 2 {
+		return fmt.Errorf("sample variance requires at least 2 data points; use type \"population\" for a single point")
+	}
+	return sc.validateVarianceWeights(weights)
+}
+
+// validateVarianceWeights rejects weights too small for the unbiased
+// variance, which treats them as frequencies and divides by their sum less one
+func (sc *StatisticsCalculator) validateVarianceWeights(weights []float64) error {
+	if weights != nil && floats.Sum(weights) <= 1 {
+		return fmt.Errorf("weighted variance requires weights summing to more than 1 (weights are frequencies)")
+	}
+	return nil
+}
+
+func (sc *StatisticsCalculator) mean(data, weights []float64) float64 {
+	if workers := sc.workersFor(len(data)); workers > 1 {
+		return parallelMean(data, weights, workers)
+	}
+	return stat.Mean(data, weights)
+}
+
+// quantiles returns the empirical quantiles of unweighted data, in
+// parallel for large datasets
+func (sc *StatisticsCalculator) quantiles(data []float64, ps ...float64) []float64 {
+	if workers := sc.workersFor(len(data)); workers > 1 {
+		return parallelQuantiles(data, workers, ps...)
+	}
+	return unweightedQuantiles(data, ps...)
+}
+
+func (sc *StatisticsCalculator) median(data, weights []float64) float64 {
+	if weights == nil {
+		return sc.quantiles(data, 0.5)[0]
+	}
+	sortedData, sortedWeights := sortWeighted(data, weights)
+	return stat.Quantile(0.5, stat.Empirical, sortedData, sortedWeights)
+}
+
+// sortWeighted returns sorted copies of data and its weights, keeping each
+// weight with its data point; weights may be nil
+func sortWeighted(data, weights []float64) ([]float64, []float64) {
 	sortedData := make([]float64, len(data))
 	copy(sortedData, data)
-	sort.Float64s(sortedData)
+	if weights == nil {
+		sort.Float64s(sortedData)
+		return sortedData, nil
+	}
 
-	return stat.Quantile(0.5, stat.Empirical, sortedData, nil)
+	sortedWeights := make([]float64, len(weights))
+	copy(sortedWeights, weights)
+	sort.Sort(weightedPoints{sortedData, sortedWeights})
+	return sortedData, sortedWeights
+}
+
+// weightedPoints sorts data points together with their weights
+type weightedPoints struct {
+	data, weights []float64
+}
+
+func (wp weightedPoints) Len() int           { return len(wp.data) }
+func (wp weightedPoints) Less(i, j int) bool { return wp.data[i] < wp.data[j] }
+func (wp weightedPoints) Swap(i, j int) {
+	wp.data[i], wp.data[j] = wp.data[j], wp.data[i]
+	wp.weights[i], wp.weights[j] = wp.weights[j], wp.weights[i]
 }
 
 func (sc *StatisticsCalculator) mode(data []float64) (interface{}, error) {
@@ -138,24 +511,36 @@ func (sc *StatisticsCalculator) mode(data []float64) (interface{}, error) {
 	}, nil
 }
 
-func (sc *StatisticsCalculator) standardDeviation(data []float64) float64 {
-	return stat.StdDev(data, nil)
+func (sc *StatisticsCalculator) standardDeviation(data, weights []float64, population bool) float64 {
+	return math.Sqrt(sc.variance(data, weights, population))
 }
 
-func (sc *StatisticsCalculator) variance(data []float64) float64 {
-	return stat.Variance(data, nil)
+func (sc *StatisticsCalculator) variance(data, weights []float64, population bool) float64 {
+	if workers := sc.workersFor(len(data)); workers > 1 {
+		return parallelVariance(data, weights, population, workers)
+	}
+	if population {
+		return stat.PopVariance(data, weights)
+	}
+	return stat.Variance(data, weights)
 }
 
-func (sc *StatisticsCalculator) percentiles(data []float64, percentiles []float64) map[string]float64 {
+func (sc *StatisticsCalculator) percentiles(data, weights []float64, percentiles []float64) map[string]float64 {
 	result := make(map[string]float64)
 
-	// Create a copy and sort it
-	sortedData := make([]float64, len(data))
-	copy(sortedData, data)
-	sort.Float64s(sortedData)
-
+	if weights == nil {
+		ps := make([]float64, len(percentiles))
+		for i, p := range percentiles {
+			ps[i] = p / 100.0
+		}
+		for i, value := range sc.quantiles(data, ps...) {
+			result[fmt.Sprintf("P%.0f", percentiles[i])] = value
+		}
+		return result
+	}
+	sortedData, sortedWeights := sortWeighted(data, weights)
 	for _, p := range percentiles {
-		result[fmt.Sprintf("P%.0f", p)] = stat.Quantile(p/100.0, stat.Empirical, sortedData, nil)
+		result[fmt.Sprintf("P%.0f", p)] = stat.Quantile(p/100.0, stat.Empirical, sortedData, sortedWeights)
 	}
 
 	return result
@@ -187,6 +572,51 @@ func (sc *StatisticsCalculator) Range(data []float64) (float64, error) {
 	return max - min, nil
 }
 
+// MaxHistogramBins is the most bins a histogram may have
+const MaxHistogramBins = 1000
+
+// Histogram groups the data into equal-width bins spanning its range.
+// Each bin includes its lower bound; the last bin also includes the maximum.
+func (sc *StatisticsCalculator) Histogram(data []float64, bins int) ([]types.HistogramBin, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data set cannot be empty")
+	}
+	if bins < 1 || bins > MaxHistogramBins {
+		return nil, fmt.Errorf("number of bins must be between 1 and %d", MaxHistogramBins)
+	}
+
+	if err := sc.validateData(data); err != nil {
+		return nil, err
+	}
+
+	min, max := data[0], data[0]
+	for _, value := range data {
+		min = math.Min(min, value)
+		max = math.Max(max, value)
+	}
+
+	width := (max - min) / float64(bins)
+	histogram := make([]types.HistogramBin, bins)
+	for i := range histogram {
+		histogram[i].Lower = min + float64(i)*width
+		histogram[i].Upper = min + float64(i+1)*width
+	}
+	histogram[bins-1].Upper = max
+
+	for _, value := range data {
+		index := bins - 1
+		if width > 0 {
+			index = int((value - min) / width)
+			if index >= bins {
+				index = bins - 1
+			}
+		}
+		histogram[index].Count++
+	}
+
+	return histogram, nil
+}
+
 func (sc *StatisticsCalculator) Skewness(data []float64) (float64, error) {
 	if len(data) < 3 {
 		return 0, fmt.Errorf("skewness requires at least 3 data points")
@@ -197,8 +627,8 @@ func (sc *StatisticsCalculator) Skewness(data []float64) (float64, error) {
 	}
 
 	n := float64(len(data))
-	mean := sc.mean(data)
-	stdDev := sc.standardDeviation(data)
+	mean := sc.mean(data, nil)
+	stdDev := sc.standardDeviation(data, nil, false)
 
 	if stdDev == 0 {
 		return 0, fmt.Errorf("cannot calculate skewness: standard deviation is zero")
@@ -222,8 +652,8 @@ func (sc *StatisticsCalculator) Kurtosis(data []float64) (float64, error) {
 	}
 
 	n := float64(len(data))
-	mean := sc.mean(data)
-	stdDev := sc.standardDeviation(data)
+	mean := sc.mean(data, nil)
+	stdDev := sc.standardDeviation(data, nil, false)
 
 	if stdDev == 0 {
 		return 0, fmt.Errorf("cannot calculate kurtosis: standard deviation is zero")
@@ -240,30 +670,48 @@ func (sc *StatisticsCalculator) Kurtosis(data []float64) (float64, error) {
 }
 
 func (sc *StatisticsCalculator) Summary(data []float64) (map[string]interface{}, error) {
+	return sc.WeightedSummary(data, nil)
+}
+
+// WeightedSummary is Summary with frequency weights; nil weights count every
+// point once. Points of zero weight do not affect min, max and range.
+func (sc *StatisticsCalculator) WeightedSummary(data, weights []float64) (map[string]interface{}, error) {
 	if err := sc.validateData(data); err != nil {
 		return nil, err
 	}
+	if err := sc.ValidateWeights(data, weights); err != nil {
+		return nil, err
+	}
+	if err := sc.validateVarianceWeights(weights); err != nil {
+		return nil, err
+	}
 
 	summary := make(map[string]interface{})
 
 	summary["count"] = len(data)
-	summary["mean"] = sc.mean(data)
-	summary["median"] = sc.median(data)
-	summary["std_dev"] = sc.standardDeviation(data)
-	summary["variance"] = sc.variance(data)
-
-	dataRange, _ := sc.Range(data)
-	summary["range"] = dataRange
+	summary["mean"] = sc.mean(data, weights)
+	summary["median"] = sc.median(data, weights)
+	summary["std_dev"] = sc.standardDeviation(data, weights, false)
+	summary["variance"] = sc.variance(data, weights, false)
 
 	// Min and Max
-	sortedData := make([]float64, len(data))
-	copy(sortedData, data)
-	sort.Float64s(sortedData)
-	summary["min"] = sortedData[0]
-	summary["max"] = sortedData[len(sortedData)-1]
+	sortedData, sortedWeights := sortWeighted(data, weights)
+	first, last := 0, len(sortedData)-1
+	if sortedWeights != nil {
+		for sortedWeights[first] == 0 {
+			first++
+		}
+		for sortedWeights[last] == 0 {
+			last--
+		}
+		summary["total_weight"] = floats.Sum(weights)
+	}
+	summary["min"] = sortedData[first]
+	summary["max"] = sortedData[last]
+	summary["range"] = sortedData[last] - sortedData[first]
 
 	// Common percentiles
-	summary["percentiles"] = sc.percentiles(data, []float64{25, 50, 75})
+	summary["percentiles"] = sc.percentiles(data, weights, []float64{25, 50, 75})
 
 	return summary, nil
 }
@@ -284,6 +732,6 @@ func (sc *StatisticsCalculator) validateData(data []float64) error {
 func (sc *StatisticsCalculator) GetSupportedOperations() []string {
 	return []string{
 		"mean", "median", "mode", "std_dev", "variance",
-		"percentile", "range", "skewness", "kurtosis", "summary",
+		"percentile", "range", "skewness", "kurtosis", "summary", "regression",
 	}
 }
@@ -0,0 +1,302 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// syntaxKind is the kind of a syntaxNode
+type syntaxKind int
+
+const (
+	nodeNumber   syntaxKind = iota // A number as written
+	nodeSymbol                     // A variable or constant
+	nodeCall                       // A function call
+	nodeNegate                     // A sign, -x
+	nodeAdd                        // x + y
+	nodeSubtract                   // x - y
+	nodeMultiply                   // x * y, or juxtaposition such as 2x
+	nodeDivide                     // x / y
+	nodePower                      // x ^ y
+)
+
+// syntaxNode is an expression as parsed, before it is simplified or
+// evaluated. Simplify and summation both read expressions into syntax
+// nodes, so they share one grammar and one precedence.
+type syntaxNode struct {
+	kind syntaxKind
+	text string       // The number, or the name of the symbol or function
+	args []syntaxNode // The operands, or the arguments of a call
+}
+
+// syntaxParser parses an expression by recursive descent. Besides the
+// expression_eval syntax it accepts ^ for powers and implicit
+// multiplication such as 2x or 3(x + 1). ^ is right-associative and binds
+// tighter than a sign, so -x^2 is -(x^2) and 2^-k is 2^(-k).
+type syntaxParser struct {
+	input string
+	pos   int
+	depth int
+}
+
+// parseSyntax parses the whole of input
+func parseSyntax(input string) (syntaxNode, error) {
+	p := &syntaxParser{input: input}
+	expression, err := p.parseSum()
+	if err != nil {
+		return syntaxNode{}, err
+	}
+	if p.skipSpaces(); p.pos < len(p.input) {
+		return syntaxNode{}, fmt.Errorf("unexpected %q", p.input[p.pos:])
+	}
+	return expression, nil
+}
+
+func (p *syntaxParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+// peek returns the next character, or 0 at the end
+func (p *syntaxParser) peek() byte {
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+// parseSum parses terms joined by + and -
+func (p *syntaxParser) parseSum() (syntaxNode, error) {
+	sum, err := p.parseProduct()
+	if err != nil {
+		return syntaxNode{}, err
+	}
+	for {
+		kind := nodeAdd
+		switch p.peek() {
+		case '+':
+		case '-':
+			kind = nodeSubtract
+		default:
+			return sum, nil
+		}
+		p.pos++
+		term, err := p.parseProduct()
+		if err != nil {
+			return syntaxNode{}, err
+		}
+		sum = syntaxNode{kind: kind, args: []syntaxNode{sum, term}}
+	}
+}
+
+// parseProduct parses factors joined by *, / or juxtaposition
+func (p *syntaxParser) parseProduct() (syntaxNode, error) {
+	product, err := p.parseUnary()
+	if err != nil {
+		return syntaxNode{}, err
+	}
+	for {
+		var factor syntaxNode
+		kind := nodeMultiply
+		switch next := p.peek(); {
+		case next == '*' || next == '/':
+			p.pos++
+			if next == '/' {
+				kind = nodeDivide
+			}
+			factor, err = p.parseUnary()
+		case next == '(' || next == '.' || isDigit(next) || isIdentifierStart(next):
+			factor, err = p.parsePower()
+		default:
+			return product, nil
+		}
+		if err != nil {
+			return syntaxNode{}, err
+		}
+		product = syntaxNode{kind: kind, args: []syntaxNode{product, factor}}
+	}
+}
+
+// parseUnary parses a signed power; -x^2 is -(x^2)
+func (p *syntaxParser) parseUnary() (syntaxNode, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return syntaxNode{}, err
+		}
+		return syntaxNode{kind: nodeNegate, args: []syntaxNode{operand}}, nil
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+// parsePower parses a base with an optional right-associative exponent,
+// which may be signed
+func (p *syntaxParser) parsePower() (syntaxNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return syntaxNode{}, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return syntaxNode{}, err
+	}
+	return syntaxNode{kind: nodePower, args: []syntaxNode{base, exponent}}, nil
+}
+
+func (p *syntaxParser) parsePrimary() (syntaxNode, error) {
+	next := p.peek()
+	switch {
+	case next == '(':
+		if p.depth++; p.depth > maxSimplifyDepth {
+			return syntaxNode{}, fmt.Errorf("expression is nested too deeply (maximum %d levels)", maxSimplifyDepth)
+		}
+		p.pos++
+		inner, err := p.parseSum()
+		if err != nil {
+			return syntaxNode{}, err
+		}
+		if p.peek() != ')' {
+			return syntaxNode{}, fmt.Errorf("expected ')' at position %d", p.pos)
+		}
+		p.pos++
+		p.depth--
+		return inner, nil
+	case next == '.' || isDigit(next):
+		return p.parseNumber()
+	case isIdentifierStart(next):
+		start := p.pos
+		for p.pos < len(p.input) && (isIdentifierStart(p.input[p.pos]) || isDigit(p.input[p.pos])) {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		if p.peek() == '(' {
+			return p.parseCall(name)
+		}
+		if _, ok := simplifyFunctions[name]; ok {
+			return syntaxNode{}, fmt.Errorf("function %s needs arguments in parentheses", name)
+		}
+		return syntaxNode{kind: nodeSymbol, text: name}, nil
+	case next == 0:
+		return syntaxNode{}, fmt.Errorf("unexpected end of expression")
+	default:
+		return syntaxNode{}, fmt.Errorf("unexpected %q at position %d; expressions support numbers, variables, functions, + - * / ^ and parentheses", p.input[p.pos:], p.pos)
+	}
+}
+
+// parseNumber reads a decimal number, with an optional exponent as in 1e-3
+func (p *syntaxParser) parseNumber() (syntaxNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+		end := p.pos + 1
+		if end < len(p.input) && (p.input[end] == '+' || p.input[end] == '-') {
+			end++
+		}
+		if end < len(p.input) && isDigit(p.input[end]) {
+			for end < len(p.input) && isDigit(p.input[end]) {
+				end++
+			}
+			p.pos = end
+		}
+	}
+	text := p.input[start:p.pos]
+	if _, ok := new(big.Rat).SetString(text); !ok {
+		return syntaxNode{}, fmt.Errorf("invalid number %q", text)
+	}
+	return syntaxNode{kind: nodeNumber, text: text}, nil
+}
+
+// parseCall parses the arguments of a call to one of simplifyFunctions,
+// which are also the functions of expression_eval
+func (p *syntaxParser) parseCall(name string) (syntaxNode, error) {
+	arity, ok := simplifyFunctions[name]
+	if !ok {
+		return syntaxNode{}, fmt.Errorf("unknown function %s", name)
+	}
+	if p.depth++; p.depth > maxSimplifyDepth {
+		return syntaxNode{}, fmt.Errorf("expression is nested too deeply (maximum %d levels)", maxSimplifyDepth)
+	}
+	p.pos++
+
+	call := syntaxNode{kind: nodeCall, text: name}
+	for {
+		arg, err := p.parseSum()
+		if err != nil {
+			return syntaxNode{}, err
+		}
+		call.args = append(call.args, arg)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if p.peek() != ')' {
+		return syntaxNode{}, fmt.Errorf("expected ')' after the arguments of %s", name)
+	}
+	p.pos++
+	p.depth--
+	if len(call.args) != arity {
+		return syntaxNode{}, fmt.Errorf("%s expects %d argument(s), got %d", name, arity, len(call.args))
+	}
+	return call, nil
+}
+
+// evaluable writes the expression in the syntax expression_eval evaluates,
+// fully parenthesized and with powers as pow calls, so that it keeps the
+// precedence it was parsed with
+func (n syntaxNode) evaluable() string {
+	var b strings.Builder
+	n.writeEvaluable(&b)
+	return b.String()
+}
+
+func (n syntaxNode) writeEvaluable(b *strings.Builder) {
+	switch n.kind {
+	case nodeNumber:
+		// expression_eval reads no exponents, so numbers are written out
+		value, _ := new(big.Rat).SetString(n.text)
+		number, _ := value.Float64()
+		b.WriteString(strconv.FormatFloat(number, 'f', -1, 64))
+	case nodeSymbol:
+		b.WriteString(n.text)
+	case nodeCall, nodePower:
+		name := n.text
+		if n.kind == nodePower {
+			name = "pow"
+		}
+		b.WriteString(name + "(")
+		for i, arg := range n.args {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			arg.writeEvaluable(b)
+		}
+		b.WriteString(")")
+	case nodeNegate:
+		b.WriteString("(-")
+		n.args[0].writeEvaluable(b)
+		b.WriteString(")")
+	default:
+		operator := map[syntaxKind]string{nodeAdd: " + ", nodeSubtract: " - ", nodeMultiply: " * ", nodeDivide: " / "}[n.kind]
+		b.WriteString("(")
+		n.args[0].writeEvaluable(b)
+		b.WriteString(operator)
+		n.args[1].writeEvaluable(b)
+		b.WriteString(")")
+	}
+}
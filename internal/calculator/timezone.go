@@ -0,0 +1,377 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embedded zone rules so conversions work on hosts without a zoneinfo database
+	_ "time/tzdata"
+
+	"calculator-server/internal/types"
+)
+
+// maxDurationSeconds bounds durations to about 31,700 years
+const maxDurationSeconds = 1e12
+
+// durationUnitSeconds gives the length in seconds of each duration unit and
+// suffix accepted in duration strings
+var durationUnitSeconds = map[string]float64{
+	"ns": 1e-9, "us": 1e-6, "µs": 1e-6, "ms": 1e-3,
+	"s": 1, "m": 60, "min": 60, "h": 3600,
+	"d": 86400, "w": 604800, "wk": 604800,
+}
+
+// durationUnits lists the canonical duration units for error messages
+var durationUnits = []string{"ns", "us", "ms", "s", "min", "h", "d", "wk"}
+
+// durationComponent matches the leading component of a duration such as
+// "1h30m" or "2d12h"; longer suffixes come first so "ms" is not read as "m"
+var durationComponent = regexp.MustCompile(`^(\d+(?:\.\d*)?|\.\d+)(ns|us|µs|ms|min|s|m|h|d|wk|w)`)
+
+// localLayouts are the accepted timestamps without a UTC offset; fractional
+// seconds are accepted after the seconds field
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// TimezoneCalculator converts timestamps between time zones and durations
+// between units
+type TimezoneCalculator struct{}
+
+func NewTimezoneCalculator() *TimezoneCalculator {
+	return &TimezoneCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (tc *TimezoneCalculator) GetSupportedOperations() []string {
+	return []string{"convert", "duration", "convert_duration"}
+}
+
+// Calculate performs the requested time conversion
+func (tc *TimezoneCalculator) Calculate(req types.TimezoneRequest) (types.TimezoneResult, error) {
+	switch req.Operation {
+	case "convert":
+		return convertTimezone(req)
+	case "duration":
+		return zonedDuration(req)
+	case "convert_duration":
+		return convertDuration(req)
+	default:
+		return types.TimezoneResult{}, fmt.Errorf("unsupported timezone operation: %s. Supported operations: %v", req.Operation, tc.GetSupportedOperations())
+	}
+}
+
+// convertTimezone shows the instant of a timestamp in another zone
+func convertTimezone(req types.TimezoneRequest) (types.TimezoneResult, error) {
+	if req.ToZone == "" {
+		return types.TimezoneResult{}, fmt.Errorf("convert requires to_zone")
+	}
+	from, err := loadZone("from_zone", req.FromZone)
+	if err != nil {
+		return types.TimezoneResult{}, err
+	}
+	to, err := loadZone("to_zone", req.ToZone)
+	if err != nil {
+		return types.TimezoneResult{}, err
+	}
+
+	instant, ambiguous, err := parseTimestamp("timestamp", req.Timestamp, from)
+	if err != nil {
+		return types.TimezoneResult{}, err
+	}
+	fromTime, toTime := zonedTime(instant), zonedTime(instant.In(to))
+	return types.TimezoneResult{Operation: req.Operation, From: &fromTime, To: &toTime, Ambiguous: ambiguous}, nil
+}
+
+// zonedDuration measures the elapsed time between two timestamps. Within one
+// zone it also reports the wall-clock difference, which differs from the
+// elapsed time by any DST shift in between.
+func zonedDuration(req types.TimezoneRequest) (types.TimezoneResult, error) {
+	from, err := loadZone("from_zone", req.FromZone)
+	if err != nil {
+		return types.TimezoneResult{}, err
+	}
+	to := from
+	if req.ToZone != "" {
+		if to, err = loadZone("to_zone", req.ToZone); err != nil {
+			return types.TimezoneResult{}, err
+		}
+	}
+
+	start, startAmbiguous, err := parseTimestamp("start", req.Start, from)
+	if err != nil {
+		return types.TimezoneResult{}, err
+	}
+	end, endAmbiguous, err := parseTimestamp("end", req.End, to)
+	if err != nil {
+		return types.TimezoneResult{}, err
+	}
+
+	elapsed := secondsBetween(start, end)
+	if math.Abs(elapsed) > maxDurationSeconds {
+		return types.TimezoneResult{}, fmt.Errorf("the timestamps are too far apart")
+	}
+	startTime, endTime := zonedTime(start), zonedTime(end)
+	result := types.TimezoneResult{
+		Operation: req.Operation,
+		From:      &startTime,
+		To:        &endTime,
+		Ambiguous: startAmbiguous || endAmbiguous,
+		Seconds:   &elapsed,
+	}
+	result.Formatted, result.Breakdown = formatDuration(elapsed)
+
+	if start.Location().String() == end.Location().String() {
+		wall := secondsBetween(wallClock(start), wallClock(end))
+		shift := wall - elapsed
+		result.WallClockSeconds, result.DSTShiftSeconds = &wall, &shift
+	}
+	return result, nil
+}
+
+// convertDuration expresses a duration in another unit and as days, hours,
+// minutes and seconds
+func convertDuration(req types.TimezoneRequest) (types.TimezoneResult, error) {
+	var seconds float64
+	switch {
+	case req.Duration != "" && req.Value != nil:
+		return types.TimezoneResult{}, fmt.Errorf("give either duration or value, not both")
+	case req.Duration != "":
+		var err error
+		if seconds, err = parseDuration(req.Duration); err != nil {
+			return types.TimezoneResult{}, err
+		}
+	case req.Value != nil:
+		if err := requireFinite("value", *req.Value); err != nil {
+			return types.TimezoneResult{}, err
+		}
+		unit := req.FromUnit
+		if unit == "" {
+			unit = "s"
+		}
+		factor, ok := durationUnitSeconds[unit]
+		if !ok {
+			return types.TimezoneResult{}, fmt.Errorf("unsupported from_unit: %s. Supported units: %v", unit, durationUnits)
+		}
+		seconds = *req.Value * factor
+	default:
+		return types.TimezoneResult{}, fmt.Errorf("convert_duration requires a duration such as 1h30m or a value with from_unit")
+	}
+	if math.Abs(seconds) > maxDurationSeconds {
+		return types.TimezoneResult{}, fmt.Errorf("duration exceeds the maximum of %g seconds", float64(maxDurationSeconds))
+	}
+
+	unit := req.ToUnit
+	if unit == "" {
+		unit = "s"
+	}
+	factor, ok := durationUnitSeconds[unit]
+	if !ok {
+		return types.TimezoneResult{}, fmt.Errorf("unsupported to_unit: %s. Supported units: %v", unit, durationUnits)
+	}
+
+	value := seconds / factor
+	result := types.TimezoneResult{Operation: req.Operation, Seconds: &seconds, Value: &value, Unit: unit}
+	result.Formatted, result.Breakdown = formatDuration(seconds)
+	return result, nil
+}
+
+// loadZone looks up an IANA zone such as Europe/Berlin; an empty name
+// returns nil so that the caller can apply its default
+func loadZone(field, name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	// "Local" would silently use the zone of the server's host
+	if strings.EqualFold(name, "local") {
+		return nil, fmt.Errorf("%s must name a zone such as America/New_York, not Local", field)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown %s %q: use an IANA zone such as America/New_York or UTC", field, name)
+	}
+	return location, nil
+}
+
+// parseTimestamp reads an RFC 3339 timestamp, or a local time in location
+// (UTC when nil). It reports whether a local time was ambiguous.
+func parseTimestamp(field, value string, location *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false, fmt.Errorf("%s is required", field)
+	}
+
+	if instant, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		if location != nil {
+			return instant.In(location), false, nil
+		}
+		// Pin the parsed offset; time.Parse may otherwise attach the
+		// server's local zone when the offsets happen to match
+		_, offset := instant.Zone()
+		if offset == 0 {
+			return instant.UTC(), false, nil
+		}
+		return instant.In(time.FixedZone("UTC"+formatUTCOffset(offset), offset)), false, nil
+	}
+
+	if location == nil {
+		location = time.UTC
+	}
+	for _, layout := range localLayouts {
+		if wall, err := time.Parse(layout, value); err == nil {
+			return resolveLocalTime(field, wall, location)
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%s %q is not a timestamp: use RFC 3339 such as 2024-03-10T01:30:00-05:00 or a local time such as 2024-03-10 01:30", field, value)
+}
+
+// resolveLocalTime finds the instant whose wall clock in location matches
+// wall (read as UTC). Local times skipped when clocks go forward are
+// rejected; local times repeated when clocks go back resolve to the earlier
+// instant and are reported as ambiguous.
+func resolveLocalTime(field string, wall time.Time, location *time.Location) (time.Time, bool, error) {
+	// The offsets in force within a day either side cover any transition
+	approximate := wall.In(location)
+	offsets := make(map[int]bool)
+	for _, probe := range []time.Duration{-24 * time.Hour, 0, 24 * time.Hour} {
+		_, offset := approximate.Add(probe).Zone()
+		offsets[offset] = true
+	}
+
+	var matches []time.Time
+	for offset := range offsets {
+		candidate := wall.Add(-time.Duration(offset) * time.Second).In(location)
+		if _, actual := candidate.Zone(); actual == offset {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return time.Time{}, false, fmt.Errorf("%s %s does not exist in %s: the clocks skip it for daylight saving time", field, wall.Format("2006-01-02 15:04:05"), location)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Before(matches[j]) })
+	return matches[0], len(matches) > 1, nil
+}
+
+// zonedTime describes an instant in its location
+func zonedTime(instant time.Time) types.ZonedTime {
+	abbreviation, offset := instant.Zone()
+	return types.ZonedTime{
+		Time:         instant.Format(time.RFC3339Nano),
+		Zone:         instant.Location().String(),
+		Abbreviation: abbreviation,
+		UTCOffset:    formatUTCOffset(offset),
+		IsDST:        instant.IsDST(),
+	}
+}
+
+// formatUTCOffset writes an offset in seconds as ±hh:mm
+func formatUTCOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, offset/60%60)
+}
+
+// wallClock returns the wall-clock reading of an instant as a UTC time, so
+// that differences between readings ignore offset changes
+func wallClock(instant time.Time) time.Time {
+	year, month, day := instant.Date()
+	hour, minute, second := instant.Clock()
+	return time.Date(year, month, day, hour, minute, second, instant.Nanosecond(), time.UTC)
+}
+
+// secondsBetween returns end − start in seconds without the ±292 year limit
+// of time.Duration
+func secondsBetween(start, end time.Time) float64 {
+	return float64(end.Unix()-start.Unix()) + float64(end.Nanosecond()-start.Nanosecond())/1e9
+}
+
+// parseDuration reads durations such as "1h30m", "2d12h", "90s" or
+// "-1.5h" into seconds
+func parseDuration(value string) (float64, error) {
+	remaining := strings.TrimSpace(value)
+	sign := 1.0
+	if strings.HasPrefix(remaining, "-") || strings.HasPrefix(remaining, "+") {
+		if remaining[0] == '-' {
+			sign = -1
+		}
+		remaining = remaining[1:]
+	}
+	if remaining == "0" {
+		return 0, nil
+	}
+	if remaining == "" {
+		return 0, fmt.Errorf("invalid duration %q: use components such as 1h30m, 2d or 90s", value)
+	}
+
+	seconds := 0.0
+	for remaining != "" {
+		match := durationComponent.FindStringSubmatch(remaining)
+		if match == nil {
+			return 0, fmt.Errorf("invalid duration %q: use components such as 1h30m, 2d or 90s", value)
+		}
+		amount, _ := strconv.ParseFloat(match[1], 64)
+		seconds += amount * durationUnitSeconds[match[2]]
+		remaining = remaining[len(match[0]):]
+	}
+	return sign * seconds, nil
+}
+
+// formatDuration writes seconds compactly as days, hours, minutes and
+// seconds, e.g. 5400 as "1h30m", and returns the same breakdown
+func formatDuration(seconds float64) (string, map[string]float64) {
+	sign := ""
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+
+	// Round the fraction to the nanosecond so float noise does not show up
+	whole := math.Floor(seconds)
+	fraction := math.Round((seconds-whole)*1e9) / 1e9
+	if fraction >= 1 {
+		whole, fraction = whole+1, 0
+	}
+	total := int64(whole)
+	days, hours, minutes, secs := total/86400, total/3600%24, total/60%60, total%60
+
+	var formatted strings.Builder
+	formatted.WriteString(sign)
+	for _, part := range []struct {
+		value  int64
+		suffix string
+	}{{days, "d"}, {hours, "h"}, {minutes, "m"}} {
+		if part.value != 0 {
+			fmt.Fprintf(&formatted, "%d%s", part.value, part.suffix)
+		}
+	}
+	if secs != 0 || fraction != 0 || total == 0 {
+		formatted.WriteString(strconv.FormatInt(secs, 10))
+		if fraction != 0 {
+			digits := strconv.FormatFloat(fraction, 'f', 9, 64)
+			formatted.WriteString(strings.TrimRight(digits[1:], "0"))
+		}
+		formatted.WriteString("s")
+	}
+	if formatted.String() == "-0s" {
+		return "0s", map[string]float64{"days": 0, "hours": 0, "minutes": 0, "seconds": 0}
+	}
+
+	breakdown := map[string]float64{
+		"days":    float64(days),
+		"hours":   float64(hours),
+		"minutes": float64(minutes),
+		"seconds": float64(secs) + fraction,
+	}
+	return formatted.String(), breakdown
+}
@@ -0,0 +1,171 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// maxStackDimensions bounds the dimensions of a stack-up
+	maxStackDimensions = 1000
+
+	// limitTolerance is the relative slack allowed when comparing a
+	// measurement with a limit, so that 25 + 0.021 still accepts 25.021
+	limitTolerance = 1e-9
+)
+
+// ToleranceCalculator performs engineering tolerance calculations
+type ToleranceCalculator struct{}
+
+func NewToleranceCalculator() *ToleranceCalculator {
+	return &ToleranceCalculator{}
+}
+
+// GetSupportedOperations returns the operations accepted by Calculate
+func (tc *ToleranceCalculator) GetSupportedOperations() []string {
+	return []string{"stack_up", "percent_error", "within_tolerance", "fit"}
+}
+
+// Calculate performs the requested tolerance calculation
+func (tc *ToleranceCalculator) Calculate(req types.ToleranceRequest) (types.ToleranceResult, error) {
+	result := types.ToleranceResult{Operation: req.Operation}
+	switch req.Operation {
+	case "stack_up":
+		return tc.stackUp(req.Dimensions, result)
+	case "percent_error":
+		if req.Measured == nil || req.Expected == nil {
+			return types.ToleranceResult{}, fmt.Errorf("percent_error requires measured and expected values")
+		}
+		measured, expected := *req.Measured, *req.Expected
+		if err := requireFinite("measured", measured); err != nil {
+			return types.ToleranceResult{}, err
+		}
+		if err := requireFinite("expected", expected); err != nil {
+			return types.ToleranceResult{}, err
+		}
+		if expected == 0 {
+			return types.ToleranceResult{}, fmt.Errorf("percent error is undefined for an expected value of 0")
+		}
+		absolute := measured - expected
+		percent := math.Abs(absolute) / math.Abs(expected) * 100
+		result.AbsoluteError, result.PercentError = &absolute, &percent
+	case "within_tolerance":
+		if req.Measured == nil || req.Dimension == nil {
+			return types.ToleranceResult{}, fmt.Errorf("within_tolerance requires a measured value and a dimension")
+		}
+		if err := requireFinite("measured", *req.Measured); err != nil {
+			return types.ToleranceResult{}, err
+		}
+		limits, err := toleranceLimits("dimension", *req.Dimension)
+		if err != nil {
+			return types.ToleranceResult{}, err
+		}
+		measured := *req.Measured
+		deviation := measured - req.Dimension.Nominal
+		margin := math.Min(measured-limits.Lower, limits.Upper-measured)
+		slack := limitTolerance * math.Max(1, math.Max(math.Abs(limits.Lower), math.Abs(limits.Upper)))
+		within := margin >= -slack
+		result.Nominal, result.Limits = &req.Dimension.Nominal, &limits
+		result.Deviation, result.Margin, result.WithinTolerance = &deviation, &margin, &within
+	case "fit":
+		if req.Hole == nil || req.Shaft == nil {
+			return types.ToleranceResult{}, fmt.Errorf("fit requires a hole and a shaft")
+		}
+		hole, err := toleranceLimits("hole", *req.Hole)
+		if err != nil {
+			return types.ToleranceResult{}, err
+		}
+		shaft, err := toleranceLimits("shaft", *req.Shaft)
+		if err != nil {
+			return types.ToleranceResult{}, err
+		}
+		clearance := types.ToleranceRange{Lower: hole.Lower - shaft.Upper, Upper: hole.Upper - shaft.Lower}
+		switch {
+		case clearance.Lower >= 0:
+			result.Fit = "clearance"
+		case clearance.Upper <= 0:
+			result.Fit = "interference"
+		default:
+			result.Fit = "transition"
+		}
+		result.Clearance = &clearance
+	default:
+		return types.ToleranceResult{}, fmt.Errorf("unsupported tolerance operation: %s. Supported operations: %v", req.Operation, tc.GetSupportedOperations())
+	}
+	return result, nil
+}
+
+// stackUp combines the dimensions of a chain. The worst case adds every
+// deviation at its extreme; the root-sum-square (RSS) estimate centres the
+// stack on the mean of each band and adds the half-bands in quadrature,
+// which is the ±3σ range when each band is ±3σ of an independent process.
+func (tc *ToleranceCalculator) stackUp(dimensions []types.ToleranceDimension, result types.ToleranceResult) (types.ToleranceResult, error) {
+	if len(dimensions) == 0 {
+		return types.ToleranceResult{}, fmt.Errorf("stack_up requires at least one dimension")
+	}
+	if len(dimensions) > maxStackDimensions {
+		return types.ToleranceResult{}, fmt.Errorf("too many dimensions: %d (maximum %d)", len(dimensions), maxStackDimensions)
+	}
+
+	var nominal, worstLower, worstUpper, center, squares float64
+	for i, dimension := range dimensions {
+		limits, err := toleranceLimits(fmt.Sprintf("dimension %d", i), dimension)
+		if err != nil {
+			return types.ToleranceResult{}, err
+		}
+		sign := 1.0
+		if dimension.Subtract {
+			sign = -1
+		}
+		low, high := sign*limits.Lower, sign*limits.Upper
+		if dimension.Subtract {
+			low, high = high, low
+		}
+
+		nominal += sign * dimension.Nominal
+		worstLower += low
+		worstUpper += high
+		center += (low + high) / 2
+		halfBand := (high - low) / 2
+		squares += halfBand * halfBand
+	}
+
+	rss := math.Sqrt(squares)
+	result.Nominal = &nominal
+	result.WorstCase = &types.ToleranceRange{Lower: worstLower, Upper: worstUpper}
+	result.RSS = &types.ToleranceRange{Lower: center - rss, Upper: center + rss}
+	return result, nil
+}
+
+// toleranceLimits returns the lower and upper limits of a dimension
+func toleranceLimits(name string, dimension types.ToleranceDimension) (types.ToleranceRange, error) {
+	for _, value := range []float64{dimension.Nominal, dimension.Upper, dimension.Lower, dimension.Tolerance} {
+		if err := requireFinite(name, value); err != nil {
+			return types.ToleranceRange{}, err
+		}
+	}
+
+	upper, lower := dimension.Upper, dimension.Lower
+	if dimension.Tolerance != 0 {
+		if upper != 0 || lower != 0 {
+			return types.ToleranceRange{}, fmt.Errorf("%s: give either tolerance or upper and lower deviations, not both", name)
+		}
+		if dimension.Tolerance < 0 {
+			return types.ToleranceRange{}, fmt.Errorf("%s: tolerance cannot be negative", name)
+		}
+		upper, lower = dimension.Tolerance, -dimension.Tolerance
+	}
+	if upper < lower {
+		return types.ToleranceRange{}, fmt.Errorf("%s: upper deviation %g is below lower deviation %g", name, upper, lower)
+	}
+	return types.ToleranceRange{Lower: dimension.Nominal + lower, Upper: dimension.Nominal + upper}, nil
+}
+
+func requireFinite(name string, value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("%s must be a finite number", name)
+	}
+	return nil
+}
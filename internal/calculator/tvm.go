@@ -0,0 +1,221 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+
+	"calculator-server/internal/types"
+)
+
+// tvmVariables are the values solve_tvm can solve for, in request order
+var tvmVariables = []string{"principal", "rate", "time", "payment", "futureValue"}
+
+// SolveTVM solves the time-value-of-money equation
+//
+//	PV·(1 + i)^n + PMT·((1 + i)^n − 1)/i + FV = 0
+//
+// for whichever of principal (PV), rate, time, payment (PMT) and future value
+// (FV) was omitted, where i is the periodic rate and n the number of
+// periods. The rate has no closed form and is found by bisection.
+func (fc *FinancialCalculator) SolveTVM(req types.TVMRequest) (types.FinancialResult, error) {
+	given := map[string]*float64{
+		"principal":   req.Principal,
+		"rate":        req.Rate,
+		"time":        req.Time,
+		"payment":     req.Payment,
+		"futureValue": req.FutureValue,
+	}
+
+	unknown := ""
+	for _, name := range tvmVariables {
+		value := given[name]
+		if value == nil {
+			if unknown != "" {
+				return types.FinancialResult{}, fmt.Errorf("solve_tvm needs all but one of %v; both %s and %s are missing", tvmVariables, unknown, name)
+			}
+			unknown = name
+			continue
+		}
+		if math.IsNaN(*value) || math.IsInf(*value, 0) {
+			return types.FinancialResult{}, fmt.Errorf("%s must be a finite number", name)
+		}
+	}
+	if unknown == "" {
+		return types.FinancialResult{}, fmt.Errorf("solve_tvm solves for the one omitted value of %v; omit the one to solve for", tvmVariables)
+	}
+
+	periods := req.Periods
+	if periods < 0 {
+		return types.FinancialResult{}, fmt.Errorf("periods cannot be negative")
+	}
+	if periods == 0 {
+		periods = 12 // Default to monthly payments
+	}
+	perYear := float64(periods)
+
+	var pv, rate, years, pmt, fv float64
+	if req.Principal != nil {
+		pv = *req.Principal
+	}
+	if req.Rate != nil {
+		rate = *req.Rate
+		if rate/100/perYear <= -1 {
+			return types.FinancialResult{}, fmt.Errorf("rate must be greater than -%g%%", 100*perYear)
+		}
+	}
+	if req.Time != nil {
+		years = *req.Time
+		if years <= 0 {
+			return types.FinancialResult{}, fmt.Errorf("time must be positive")
+		}
+	}
+	if req.Payment != nil {
+		pmt = *req.Payment
+	}
+	if req.FutureValue != nil {
+		fv = *req.FutureValue
+	}
+
+	i, n := rate/100/perYear, years*perYear
+	var result float64
+	switch unknown {
+	case "futureValue":
+		growth, annuity := tvmFactors(i, n)
+		fv = -(pv*growth + pmt*annuity)
+		result = fv
+	case "principal":
+		growth, annuity := tvmFactors(i, n)
+		pv = -(fv + pmt*annuity) / growth
+		result = pv
+	case "payment":
+		growth, annuity := tvmFactors(i, n)
+		pmt = -(fv + pv*growth) / annuity
+		result = pmt
+	case "time":
+		var err error
+		if n, err = solveTVMPeriods(pv, i, pmt, fv); err != nil {
+			return types.FinancialResult{}, err
+		}
+		years = n / perYear
+		result = years
+	case "rate":
+		var err error
+		if i, err = solveTVMRate(pv, n, pmt, fv); err != nil {
+			return types.FinancialResult{}, err
+		}
+		rate = i * perYear * 100
+		result = rate
+	}
+
+	breakdown := map[string]interface{}{
+		"solved_for":       unknown,
+		"principal":        pv,
+		"rate_percent":     rate,
+		"time_years":       years,
+		"payment":          pmt,
+		"future_value":     fv,
+		"periods_per_year": periods,
+		"total_periods":    n,
+	}
+
+	financialResult := types.FinancialResult{
+		Result:      result,
+		Breakdown:   breakdown,
+		Description: fmt.Sprintf("Time value of money solved for %s", unknown),
+	}
+	if req.Explain {
+		financialResult.Steps = []string{
+			"Equation: PV·(1 + i)^n + PMT·((1 + i)^n − 1)/i + FV = 0",
+			fmt.Sprintf("Periodic rate i = %s%% / 100 / %d = %s", stepNumber(rate), periods, stepNumber(i)),
+			fmt.Sprintf("Number of periods n = %s × %d = %s", stepNumber(years), periods, stepNumber(n)),
+			fmt.Sprintf("PV = %s, PMT = %s, FV = %s", stepNumber(pv), stepNumber(pmt), stepNumber(fv)),
+			fmt.Sprintf("Solved for %s = %s", unknown, stepNumber(result)),
+		}
+	}
+
+	return financialResult, nil
+}
+
+// tvmFactors returns the growth factor (1 + i)^n and the annuity factor
+// ((1 + i)^n − 1)/i, which is n when i is 0
+func tvmFactors(i, n float64) (growth, annuity float64) {
+	if i == 0 {
+		return 1, n
+	}
+	exponent := n * math.Log1p(i)
+	return math.Exp(exponent), math.Expm1(exponent) / i
+}
+
+// tvmBalance is the left-hand side of the TVM equation
+func tvmBalance(pv, i, n, pmt, fv float64) float64 {
+	growth, annuity := tvmFactors(i, n)
+	return pv*growth + pmt*annuity + fv
+}
+
+// solveTVMPeriods solves the TVM equation for n in closed form
+func solveTVMPeriods(pv, i, pmt, fv float64) (float64, error) {
+	noSolution := fmt.Errorf("no positive time satisfies these values; check the cash-flow signs (money received positive, paid out negative)")
+	if i == 0 {
+		if pmt == 0 {
+			return 0, fmt.Errorf("cannot solve for time with neither interest nor payments")
+		}
+		n := -(pv + fv) / pmt
+		if n <= 0 {
+			return 0, noSolution
+		}
+		return n, nil
+	}
+
+	// (1 + i)^n · (PV + PMT/i) = PMT/i − FV
+	perpetuity := pmt / i
+	ratio := (perpetuity - fv) / (pv + perpetuity)
+	if pv+perpetuity == 0 || ratio <= 0 || math.IsInf(ratio, 0) {
+		return 0, noSolution
+	}
+	n := math.Log(ratio) / math.Log1p(i)
+	if n <= 0 || math.IsNaN(n) {
+		return 0, noSolution
+	}
+	return n, nil
+}
+
+// solveTVMRate finds the periodic rate solving the TVM equation. It scans
+// outwards from zero for a sign change, so the root nearest zero is found,
+// and then bisects.
+func solveTVMRate(pv, n, pmt, fv float64) (float64, error) {
+	balance := func(i float64) float64 { return tvmBalance(pv, i, n, pmt, fv) }
+	if balance(0) == 0 {
+		return 0, nil
+	}
+
+	lower, upper, found := 0.0, 0.0, false
+	for step := 1e-6; !found && step < 10; step *= 1.2 {
+		for _, candidate := range []float64{step, -step} {
+			if candidate <= -1 {
+				continue
+			}
+			previous := candidate / 1.2
+			if step == 1e-6 {
+				previous = 0
+			}
+			if math.Signbit(balance(previous)) != math.Signbit(balance(candidate)) {
+				lower, upper, found = math.Min(previous, candidate), math.Max(previous, candidate), true
+				break
+			}
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no interest rate satisfies these values; check the cash-flow signs (money received positive, paid out negative)")
+	}
+
+	lowerSign := math.Signbit(balance(lower))
+	for iteration := 0; iteration < 200 && upper-lower > 1e-15; iteration++ {
+		middle := (lower + upper) / 2
+		if math.Signbit(balance(middle)) == lowerSign {
+			lower = middle
+		} else {
+			upper = middle
+		}
+	}
+	return (lower + upper) / 2, nil
+}
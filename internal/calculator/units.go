@@ -1,22 +1,43 @@
 package calculator
 
 import (
+	"context"
 	"fmt"
 	"math"
 
 	"calculator-server/internal/types"
 )
 
+// UnitConverter converts values between the units of a category. Its
+// factors come from a shared ConversionRegistry, so creating one is cheap.
 type UnitConverter struct {
-	conversions map[string]map[string]map[string]float64
+	registry *ConversionRegistry
 }
 
+// NewUnitConverter returns a converter over the default conversion registry
 func NewUnitConverter() *UnitConverter {
-	uc := &UnitConverter{}
-	uc.initializeConversions()
+	return NewUnitConverterWithRegistry(DefaultConversionRegistry())
+}
+
+// NewUnitConverterWithRegistry returns a converter over the given registry
+func NewUnitConverterWithRegistry(registry *ConversionRegistry) *UnitConverter {
+	return &UnitConverter{registry: registry}
+}
+
+// ForContext returns a converter over the conversion registry of ctx, or
+// the converter itself when the context carries none
+func (uc *UnitConverter) ForContext(ctx context.Context) *UnitConverter {
+	if registry := ConversionRegistryFromContext(ctx); registry != nil && registry != uc.registry {
+		return NewUnitConverterWithRegistry(registry)
+	}
 	return uc
 }
 
+// Registry returns the conversion registry the converter reads
+func (uc *UnitConverter) Registry() *ConversionRegistry {
+	return uc.registry
+}
+
 func (uc *UnitConverter) Convert(req types.UnitConversionRequest) (types.CalculationResult, error) {
 	if err := uc.validateRequest(req); err != nil {
 		return types.CalculationResult{}, err
@@ -25,19 +46,10 @@ func (uc *UnitConverter) Convert(req types.UnitConversionRequest) (types.Calcula
 	var result float64
 	var err error
 
-	switch req.Category {
-	case "length":
-		result, err = uc.convertLength(req.Value, req.FromUnit, req.ToUnit)
-	case "weight":
-		result, err = uc.convertWeight(req.Value, req.FromUnit, req.ToUnit)
-	case "temperature":
+	if req.Category == temperatureCategory {
 		result, err = uc.convertTemperature(req.Value, req.FromUnit, req.ToUnit)
-	case "volume":
-		result, err = uc.convertVolume(req.Value, req.FromUnit, req.ToUnit)
-	case "area":
-		result, err = uc.convertArea(req.Value, req.FromUnit, req.ToUnit)
-	default:
-		return types.CalculationResult{}, fmt.Errorf("unsupported category: %s", req.Category)
+	} else {
+		result, err = uc.convertGeneric(req.Value, req.FromUnit, req.ToUnit, req.Category)
 	}
 
 	if err != nil {
@@ -50,119 +62,8 @@ func (uc *UnitConverter) Convert(req types.UnitConversionRequest) (types.Calcula
 	}, nil
 }
 
-func (uc *UnitConverter) initializeConversions() {
-	uc.conversions = make(map[string]map[string]map[string]float64)
-
-	// Length conversions (to meters)
-	uc.conversions["length"] = map[string]map[string]float64{
-		"to_base": {
-			"mm":  0.001,
-			"cm":  0.01,
-			"m":   1.0,
-			"km":  1000.0,
-			"in":  0.0254,
-			"ft":  0.3048,
-			"yd":  0.9144,
-			"mi":  1609.344,
-			"mil": 0.0000254,
-			"μm":  0.000001,
-			"nm":  0.000000001,
-		},
-	}
-
-	// Weight conversions (to grams)
-	uc.conversions["weight"] = map[string]map[string]float64{
-		"to_base": {
-			"mg":  0.001,
-			"g":   1.0,
-			"kg":  1000.0,
-			"t":   1000000.0,
-			"oz":  28.3495,
-			"lb":  453.592,
-			"st":  6350.29, // stone
-			"ton": 907185,  // US ton
-		},
-	}
-
-	// Volume conversions (to liters)
-	uc.conversions["volume"] = map[string]map[string]float64{
-		"to_base": {
-			"ml":    0.001,
-			"cl":    0.01,
-			"dl":    0.1,
-			"l":     1.0,
-			"kl":    1000.0,
-			"fl_oz": 0.0295735,  // US fluid ounce
-			"cup":   0.236588,   // US cup
-			"pt":    0.473176,   // US pint
-			"qt":    0.946353,   // US quart
-			"gal":   3.78541,    // US gallon
-			"tsp":   0.00492892, // US teaspoon
-			"tbsp":  0.0147868,  // US tablespoon
-			"bbl":   158.987,    // barrel
-		},
-	}
-
-	// Area conversions (to square meters)
-	uc.conversions["area"] = map[string]map[string]float64{
-		"to_base": {
-			"mm2":  0.000001,
-			"cm2":  0.0001,
-			"m2":   1.0,
-			"km2":  1000000.0,
-			"in2":  0.00064516,
-			"ft2":  0.092903,
-			"yd2":  0.836127,
-			"mi2":  2589988.11,
-			"acre": 4046.86,
-			"ha":   10000.0, // hectare
-		},
-	}
-}
-
-func (uc *UnitConverter) convertLength(value float64, fromUnit, toUnit string) (float64, error) {
-	return uc.convertGeneric(value, fromUnit, toUnit, "length")
-}
-
-func (uc *UnitConverter) convertWeight(value float64, fromUnit, toUnit string) (float64, error) {
-	return uc.convertGeneric(value, fromUnit, toUnit, "weight")
-}
-
-func (uc *UnitConverter) convertVolume(value float64, fromUnit, toUnit string) (float64, error) {
-	return uc.convertGeneric(value, fromUnit, toUnit, "volume")
-}
-
-func (uc *UnitConverter) convertArea(value float64, fromUnit, toUnit string) (float64, error) {
-	return uc.convertGeneric(value, fromUnit, toUnit, "area")
-}
-
 func (uc *UnitConverter) convertGeneric(value float64, fromUnit, toUnit string, category string) (float64, error) {
-	if fromUnit == toUnit {
-		return value, nil
-	}
-
-	conversions, exists := uc.conversions[category]
-	if !exists {
-		return 0, fmt.Errorf("category not supported: %s", category)
-	}
-
-	toBase := conversions["to_base"]
-
-	fromFactor, fromExists := toBase[fromUnit]
-	toFactor, toExists := toBase[toUnit]
-
-	if !fromExists {
-		return 0, fmt.Errorf("unsupported unit: %s", fromUnit)
-	}
-	if !toExists {
-		return 0, fmt.Errorf("unsupported unit: %s", toUnit)
-	}
-
-	// Convert to base unit, then to target unit
-	baseValue := value * fromFactor
-	result := baseValue / toFactor
-
-	return result, nil
+	return uc.registry.Convert(value, fromUnit, toUnit, category)
 }
 
 func (uc *UnitConverter) convertTemperature(value float64, fromUnit, toUnit string) (float64, error) {
@@ -227,16 +128,7 @@ func (uc *UnitConverter) validateRequest(req types.UnitConversionRequest) error
 		return fmt.Errorf("category cannot be empty")
 	}
 
-	// Validate category
-	supportedCategories := []string{"length", "weight", "temperature", "volume", "area"}
-	categoryValid := false
-	for _, cat := range supportedCategories {
-		if req.Category == cat {
-			categoryValid = true
-			break
-		}
-	}
-	if !categoryValid {
+	if !uc.registry.HasCategory(req.Category) {
 		return fmt.Errorf("unsupported category: %s", req.Category)
 	}
 
@@ -245,32 +137,40 @@ func (uc *UnitConverter) validateRequest(req types.UnitConversionRequest) error
 
 // GetSupportedUnits returns supported units for a given category
 func (uc *UnitConverter) GetSupportedUnits(category string) ([]string, error) {
-	switch category {
-	case "length":
-		return []string{"mm", "cm", "m", "km", "in", "ft", "yd", "mi", "mil", "μm", "nm"}, nil
-	case "weight":
-		return []string{"mg", "g", "kg", "t", "oz", "lb", "st", "ton"}, nil
-	case "temperature":
-		return []string{"C", "F", "K", "R"}, nil
-	case "volume":
-		return []string{"ml", "cl", "dl", "l", "kl", "fl_oz", "cup", "pt", "qt", "gal", "tsp", "tbsp", "bbl"}, nil
-	case "area":
-		return []string{"mm2", "cm2", "m2", "km2", "in2", "ft2", "yd2", "mi2", "acre", "ha"}, nil
-	default:
+	units, exists := uc.registry.Units(category)
+	if !exists {
 		return nil, fmt.Errorf("unsupported category: %s", category)
 	}
+	return units, nil
 }
 
 // GetSupportedCategories returns all supported conversion categories
 func (uc *UnitConverter) GetSupportedCategories() []string {
-	return []string{"length", "weight", "temperature", "volume", "area"}
+	return uc.registry.Categories()
 }
 
 // ConvertMultiple converts multiple values at once
 func (uc *UnitConverter) ConvertMultiple(values []float64, fromUnit, toUnit, category string) ([]float64, error) {
+	return uc.ConvertMultipleContext(context.Background(), values, fromUnit, toUnit, category)
+}
+
+// conversionProgressInterval is the number of values converted between
+// progress reports and checks for cancellation
+const conversionProgressInterval = 10000
+
+// ConvertMultipleContext converts every value, reporting progress to the
+// ProgressFunc of ctx and stopping once ctx is done
+func (uc *UnitConverter) ConvertMultipleContext(ctx context.Context, values []float64, fromUnit, toUnit, category string) ([]float64, error) {
 	results := make([]float64, len(values))
 
 	for i, value := range values {
+		if i > 0 && i%conversionProgressInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			reportProgress(ctx, float64(i), float64(len(values)), "")
+		}
+
 		req := types.UnitConversionRequest{
 			Value:    value,
 			FromUnit: fromUnit,
@@ -294,7 +194,7 @@ func (uc *UnitConverter) GetConversionFactor(fromUnit, toUnit, category string)
 	result, err := uc.convertGeneric(1.0, fromUnit, toUnit, category)
 	if err != nil {
 		// Try temperature conversion if generic conversion fails
-		if category == "temperature" {
+		if category == temperatureCategory {
 			// Temperature conversion is not linear, so we can't provide a simple factor
 			return 0, fmt.Errorf("temperature conversions don't have linear conversion factors")
 		}
@@ -1,7 +1,16 @@
 package config
 
 import (
+	"crypto/tls"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"calculator-server/internal/currency"
 )
 
 // Config represents the complete server configuration
@@ -10,12 +19,42 @@ type Config struct {
 	Logging  LoggingConfig  `yaml:"logging" json:"logging"`
 	Tools    ToolsConfig    `yaml:"tools" json:"tools"`
 	Security SecurityConfig `yaml:"security" json:"security"`
+	Storage  StorageConfig  `yaml:"storage" json:"storage"`
+	Fetcher  FetcherConfig  `yaml:"fetcher" json:"fetcher"`
+	Currency CurrencyConfig `yaml:"currency" json:"currency"`
+	Tenants  []TenantConfig `yaml:"tenants" json:"tenants"`
 }
 
 // ServerConfig contains server-specific configuration
 type ServerConfig struct {
-	Transport string     `yaml:"transport" json:"transport"`
-	HTTP      HTTPConfig `yaml:"http" json:"http"`
+	// Transport is "stdio", "http", or a comma-separated list of both to
+	// serve them at once
+	Transport string      `yaml:"transport" json:"transport"`
+	Stdio     StdioConfig `yaml:"stdio" json:"stdio"`
+	HTTP      HTTPConfig  `yaml:"http" json:"http"`
+	// ShutdownTimeout is how long running tool calls and jobs are waited
+	// for on shutdown before they are cancelled
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" json:"shutdown_timeout"`
+}
+
+// Transports returns the names of the transports to run
+func (c *ServerConfig) Transports() []string {
+	var transports []string
+	for _, name := range strings.Split(c.Transport, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			transports = append(transports, name)
+		}
+	}
+	return transports
+}
+
+// StdioConfig contains stdio transport configuration
+type StdioConfig struct {
+	// Framing is "line" for newline delimited JSON or "content-length"
+	// for LSP-style Content-Length headers
+	Framing        string `yaml:"framing" json:"framing"`
+	MaxMessageSize int    `yaml:"max_message_size" json:"max_message_size"` // In bytes
+	MaxCalls       int    `yaml:"max_calls" json:"max_calls"`               // Tool calls and batches run at once
 }
 
 // HTTPConfig contains MCP-compliant HTTP transport configuration
@@ -25,12 +64,109 @@ type HTTPConfig struct {
 	SessionTimeout time.Duration `yaml:"session_timeout" json:"session_timeout"`
 	MaxConnections int           `yaml:"max_connections" json:"max_connections"`
 	CORS           CORSConfig    `yaml:"cors" json:"cors"`
+	MetricsEnabled bool          `yaml:"metrics_enabled" json:"metrics_enabled"`
+	HealthEnabled  bool          `yaml:"health_enabled" json:"health_enabled"`
+	OpsPort        int           `yaml:"ops_port" json:"ops_port"` // 0 serves /health and /metrics on Port
+	DisableSSE     bool          `yaml:"disable_sse" json:"disable_sse"`
+	// SSEFlushInterval batches the events of SSE streams and writes them
+	// this often, coalescing progress updates; 0 writes each event at once
+	SSEFlushInterval time.Duration `yaml:"sse_flush_interval" json:"sse_flush_interval"`
+	SSEMaxPending    int           `yaml:"sse_max_pending" json:"sse_max_pending"` // Events queued per stream before it is closed
+	// IdempotencyTTL is how long responses to job submissions and admin
+	// changes carrying an Idempotency-Key are replayed to retries
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" json:"idempotency_ttl"`
+	Stateless      bool          `yaml:"stateless" json:"stateless"`
+	RequireSession bool          `yaml:"require_session" json:"require_session"`
+	RequestIDMeta  bool          `yaml:"request_id_meta" json:"request_id_meta"`
+	Jobs           JobsConfig    `yaml:"jobs" json:"jobs"`
+	Admin          AdminConfig   `yaml:"admin" json:"admin"`
+	TLS            TLSConfig     `yaml:"tls" json:"tls"`
+	Auth           AuthConfig    `yaml:"auth" json:"auth"`
+	OAuth          OAuthConfig   `yaml:"oauth" json:"oauth"`
+	Chaos          ChaosConfig   `yaml:"chaos" json:"chaos"`
+	// TrustedProxies are the IPs and CIDR ranges of reverse proxies whose
+	// X-Forwarded-For and X-Forwarded-Host are believed
+	TrustedProxies []string `yaml:"trusted_proxies" json:"trusted_proxies"`
+	BasePath       string   `yaml:"base_path" json:"base_path"` // Serve the routes under this prefix, e.g. "/calc"
+	// AllowedOrigins are the origins browsers may send requests from, such
+	// as "https://app.example.com"; others get 403 to prevent DNS
+	// rebinding. An origin without a port allows every port of its host.
+	AllowedOrigins []string `yaml:"allowed_origins" json:"allowed_origins"`
+}
+
+// ChaosConfig injects faults into the HTTP transport so that client
+// developers can test retries and reconnects. Never enable it in production.
+type ChaosConfig struct {
+	Enabled   bool          `yaml:"enabled" json:"enabled"`
+	Delay     time.Duration `yaml:"delay" json:"delay"`           // Longest delay added to a delayed request
+	DelayRate float64       `yaml:"delay_rate" json:"delay_rate"` // Fractions between 0 and 1
+	ErrorRate float64       `yaml:"error_rate" json:"error_rate"`
+	DropRate  float64       `yaml:"drop_rate" json:"drop_rate"`
+	Seed      int64         `yaml:"seed" json:"seed"` // 0 picks a random seed
+}
+
+// OAuthConfig makes the HTTP server an OAuth 2.1 resource server: requests
+// must carry a JWT access token the authorization server issued for it
+type OAuthConfig struct {
+	Enabled     bool   `yaml:"enabled" json:"enabled"`
+	ResourceURL string `yaml:"resource_url" json:"resource_url"` // Public URL of the /mcp endpoint
+	Issuer      string `yaml:"issuer" json:"issuer"`
+	Audience    string `yaml:"audience" json:"audience"` // Defaults to resource_url
+	JWKSURL     string `yaml:"jwks_url" json:"jwks_url"` // Discovered from the issuer when empty
+}
+
+// AuthConfig lists the static credentials every request must carry, sent
+// in the X-API-Key header or as an Authorization bearer token. Without any,
+// requests are not authenticated.
+type AuthConfig struct {
+	APIKeys      []CredentialConfig `yaml:"api_keys" json:"api_keys"`
+	BearerTokens []CredentialConfig `yaml:"bearer_tokens" json:"bearer_tokens"`
+}
+
+// CredentialConfig is one API key or bearer token
+type CredentialConfig struct {
+	Name         string   `yaml:"name" json:"name"`
+	Key          string   `yaml:"key" json:"key"`
+	AllowedTools []string `yaml:"allowed_tools" json:"allowed_tools"` // Empty allows every tool
+	DailyQuota   int      `yaml:"daily_quota" json:"daily_quota"`     // Tool calls per UTC day; 0 is unlimited
+	MonthlyQuota int      `yaml:"monthly_quota" json:"monthly_quota"` // Tool calls per UTC month; 0 is unlimited
+}
+
+// TLSConfig contains HTTPS configuration. With a client CA file, clients
+// must present a certificate it signed (mutual TLS).
+type TLSConfig struct {
+	Enabled      bool     `yaml:"enabled" json:"enabled"`
+	CertFile     string   `yaml:"cert_file" json:"cert_file"`
+	KeyFile      string   `yaml:"key_file" json:"key_file"`
+	ClientCAFile string   `yaml:"client_ca_file" json:"client_ca_file"`
+	MinVersion   string   `yaml:"min_version" json:"min_version"` // "1.2" or "1.3"
+	CipherSuites []string `yaml:"cipher_suites" json:"cipher_suites"`
 }
 
-// CORSConfig contains CORS configuration
+// AdminConfig contains the administrative HTTP API configuration
+type AdminConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Token   string `yaml:"token" json:"token"`
+}
+
+// JobsConfig contains the asynchronous job API configuration
+type JobsConfig struct {
+	Enabled        bool          `yaml:"enabled" json:"enabled"`
+	WebhookURL     string        `yaml:"webhook_url" json:"webhook_url"`
+	WebhookSecret  string        `yaml:"webhook_secret" json:"webhook_secret"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" json:"webhook_timeout"`
+}
+
+// CORSConfig contains CORS configuration. Empty lists and a zero max age
+// fall back to the transport defaults.
 type CORSConfig struct {
-	Enabled bool     `yaml:"enabled" json:"enabled"`
-	Origins []string `yaml:"origins" json:"origins"`
+	Enabled          bool          `yaml:"enabled" json:"enabled"`
+	Origins          []string      `yaml:"origins" json:"origins"`
+	Methods          []string      `yaml:"methods" json:"methods"`
+	Headers          []string      `yaml:"headers" json:"headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" json:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials" json:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age" json:"max_age"`
 }
 
 // LoggingConfig contains logging configuration
@@ -38,6 +174,9 @@ type LoggingConfig struct {
 	Level  string `yaml:"level" json:"level"`
 	Format string `yaml:"format" json:"format"`
 	Output string `yaml:"output" json:"output"`
+	// SlowCallThreshold is how long a tool call runs before clients that
+	// enabled MCP logging are sent a slow calculation warning
+	SlowCallThreshold time.Duration `yaml:"slow_call_threshold" json:"slow_call_threshold"`
 }
 
 // ToolsConfig contains tools-specific configuration
@@ -46,6 +185,19 @@ type ToolsConfig struct {
 	ExpressionEval ExpressionEvalConfig `yaml:"expression_eval" json:"expression_eval"`
 	Statistics     StatisticsConfig     `yaml:"statistics" json:"statistics"`
 	Financial      FinancialConfig      `yaml:"financial" json:"financial"`
+	Conversions    ConversionsConfig    `yaml:"conversions" json:"conversions"`
+	Groups         map[string]bool      `yaml:"groups" json:"groups"`
+	// Ordering is the order of tools/list: "registration" or "name"
+	Ordering string `yaml:"ordering" json:"ordering"`
+	// Strictness is "lenient", to canonicalize arguments before they are
+	// validated, or "strict", to take them exactly as sent
+	Strictness string `yaml:"strictness" json:"strictness"`
+	// Elicitation lists the tools that ask the client for missing required
+	// arguments instead of failing
+	Elicitation []string `yaml:"elicitation" json:"elicitation"`
+	// Explanations lists the tools whose results the client's model is
+	// asked to explain through sampling
+	Explanations []string `yaml:"explanations" json:"explanations"`
 }
 
 // PrecisionConfig contains precision configuration
@@ -63,6 +215,10 @@ type ExpressionEvalConfig struct {
 // StatisticsConfig contains statistics configuration
 type StatisticsConfig struct {
 	MaxDataPoints int `yaml:"max_data_points" json:"max_data_points"`
+	// Workers is the number of goroutines for datasets of at least
+	// ParallelThreshold points; 0 uses every CPU and 1 disables parallelism
+	Workers           int `yaml:"workers" json:"workers"`
+	ParallelThreshold int `yaml:"parallel_threshold" json:"parallel_threshold"`
 }
 
 // FinancialConfig contains financial calculations configuration
@@ -70,38 +226,146 @@ type FinancialConfig struct {
 	CurrencyDefault string `yaml:"currency_default" json:"currency_default"`
 }
 
+// ConversionsConfig contains unit conversion configuration
+type ConversionsConfig struct {
+	// DataFile is an optional YAML or JSON file of units and constants
+	// added to the built-in tables at startup
+	DataFile string `yaml:"data_file" json:"data_file"`
+}
+
 // SecurityConfig contains security configuration
 type SecurityConfig struct {
-	RateLimiting     RateLimitingConfig `yaml:"rate_limiting" json:"rate_limiting"`
-	RequestSizeLimit string             `yaml:"request_size_limit" json:"request_size_limit"`
+	RateLimiting       RateLimitingConfig       `yaml:"rate_limiting" json:"rate_limiting"`
+	RequestSizeLimit   string                   `yaml:"request_size_limit" json:"request_size_limit"` // Largest HTTP request body, e.g. "1MB"
+	MaxJSONDepth       int                      `yaml:"max_json_depth" json:"max_json_depth"`         // Deepest nesting of arrays and objects in a message
+	MaxArrayLength     int                      `yaml:"max_array_length" json:"max_array_length"`     // Most items in one array of a message
+	SessionConcurrency SessionConcurrencyConfig `yaml:"session_concurrency" json:"session_concurrency"`
+}
+
+// SessionConcurrencyConfig caps the tool calls a session runs at once
+type SessionConcurrencyConfig struct {
+	MaxCalls     int           `yaml:"max_calls" json:"max_calls"`         // 0 is unlimited
+	QueueTimeout time.Duration `yaml:"queue_timeout" json:"queue_timeout"` // 0 rejects extra calls at once
 }
 
 // RateLimitingConfig contains rate limiting configuration
 type RateLimitingConfig struct {
-	Enabled           bool `yaml:"enabled" json:"enabled"`
-	RequestsPerMinute int  `yaml:"requests_per_minute" json:"requests_per_minute"`
+	Enabled           *bool  `yaml:"enabled" json:"enabled"` // nil means enabled
+	RequestsPerMinute int    `yaml:"requests_per_minute" json:"requests_per_minute"`
+	KeyBy             string `yaml:"key_by" json:"key_by"` // ip, session or api_key
+}
+
+// IsEnabled reports whether requests are rate limited, which they are
+// unless turned off explicitly
+func (c RateLimitingConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// StorageConfig contains the optional persistence configuration for
+// sessions and calculation history
+type StorageConfig struct {
+	Enabled    bool          `yaml:"enabled" json:"enabled"`
+	Backend    string        `yaml:"backend" json:"backend"`
+	Path       string        `yaml:"path" json:"path"`
+	TTL        time.Duration `yaml:"ttl" json:"ttl"`
+	GCInterval time.Duration `yaml:"gc_interval" json:"gc_interval"`
+	Redis      RedisConfig   `yaml:"redis" json:"redis"`
+}
+
+// RedisConfig locates the Redis server of the redis storage backend, which
+// replicas behind a load balancer share
+type RedisConfig struct {
+	URL       string `yaml:"url" json:"url"`               // redis://[:password@]host:port[/db]
+	KeyPrefix string `yaml:"key_prefix" json:"key_prefix"` // Prepended to every key
+}
+
+// FetcherConfig controls the outbound HTTP made by tools that look up
+// external data. Only the listed hosts can be reached.
+type FetcherConfig struct {
+	AllowedHosts      []string      `yaml:"allowed_hosts" json:"allowed_hosts"`
+	Timeout           time.Duration `yaml:"timeout" json:"timeout"`
+	CacheTTL          time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+	RequestsPerMinute int           `yaml:"requests_per_minute" json:"requests_per_minute"`
+}
+
+// CurrencyConfig configures the exchange rates used to convert financial
+// results into a reporting currency. Rates are fetched through the fetcher,
+// so the host must also be in fetcher.allowed_hosts.
+type CurrencyConfig struct {
+	// RatesURL returns {"rates": {...}} for the currency substituted for {base}
+	RatesURL string `yaml:"rates_url" json:"rates_url"`
+	// RatesBase is the currency whose rates are published as the
+	// calculator://currency/rates resource
+	RatesBase string `yaml:"rates_base" json:"rates_base"`
+	// RefreshInterval is how often the published rates are fetched again;
+	// 0 fetches them once at startup
+	RefreshInterval time.Duration `yaml:"refresh_interval" json:"refresh_interval"`
+}
+
+// TenantConfig describes one tenant of a multi-tenant HTTP server.
+// Tenants are identified by the API key sent in the X-API-Key header.
+type TenantConfig struct {
+	ID                string   `yaml:"id" json:"id"`
+	APIKeys           []string `yaml:"api_keys" json:"api_keys"`
+	RequestsPerMinute int      `yaml:"requests_per_minute" json:"requests_per_minute"`
+	AllowedTools      []string `yaml:"allowed_tools" json:"allowed_tools"`
+	ToolGroups        []string `yaml:"tool_groups" json:"tool_groups"`
+	// ConversionsFile is an optional YAML or JSON file of units added to
+	// the server's tables for this tenant only
+	ConversionsFile string `yaml:"conversions_file" json:"conversions_file"`
+	// ExchangeRates, when set, replaces the fetched exchange rates for
+	// this tenant's reporting-currency conversions
+	ExchangeRates *ExchangeRatesConfig `yaml:"exchange_rates" json:"exchange_rates"`
+}
+
+// ExchangeRatesConfig is a fixed table of exchange rates: one unit of Base
+// buys Rates[code] units of each listed currency
+type ExchangeRatesConfig struct {
+	Base  string             `yaml:"base" json:"base"`
+	Rates map[string]float64 `yaml:"rates" json:"rates"`
 }
 
 // Default returns a configuration with default values
 func Default() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Transport: "stdio",
+			Transport:       "stdio",
+			ShutdownTimeout: 30 * time.Second,
+			Stdio: StdioConfig{
+				Framing:        "line",
+				MaxMessageSize: 1 << 20,
+				MaxCalls:       64,
+			},
 			HTTP: HTTPConfig{
 				Host:           "127.0.0.1", // Default to localhost for security
 				Port:           8080,
 				SessionTimeout: 5 * time.Minute,
 				MaxConnections: 100,
+				SSEMaxPending:  256,
+				IdempotencyTTL: 24 * time.Hour,
+				AllowedOrigins: []string{
+					"http://localhost", "https://localhost",
+					"http://127.0.0.1", "https://127.0.0.1",
+					"http://[::1]", "https://[::1]",
+				},
 				CORS: CORSConfig{
 					Enabled: true,
 					Origins: []string{"http://localhost:3000", "http://127.0.0.1:3000"},
 				},
+				Jobs: JobsConfig{
+					Enabled:        false,
+					WebhookTimeout: 10 * time.Second,
+				},
+				TLS: TLSConfig{
+					MinVersion: "1.2",
+				},
 			},
 		},
 		Logging: LoggingConfig{
-			Level:  "info",
-			Format: "json",
-			Output: "stdout",
+			Level:             "info",
+			Format:            "json",
+			Output:            "stdout",
+			SlowCallThreshold: time.Second,
 		},
 		Tools: ToolsConfig{
 			Precision: PrecisionConfig{
@@ -113,31 +377,121 @@ func Default() *Config {
 				MaxVariables: 100,
 			},
 			Statistics: StatisticsConfig{
-				MaxDataPoints: 10000,
+				MaxDataPoints:     10000,
+				ParallelThreshold: 100000,
 			},
 			Financial: FinancialConfig{
 				CurrencyDefault: "USD",
 			},
+			Groups: map[string]bool{
+				"math":         true,
+				"stats":        true,
+				"finance":      true,
+				"conversion":   true,
+				"experimental": false,
+			},
+			Ordering:   "registration",
+			Strictness: "lenient",
 		},
 		Security: SecurityConfig{
 			RateLimiting: RateLimitingConfig{
-				Enabled:           true,
 				RequestsPerMinute: 100,
+				KeyBy:             "ip",
 			},
 			RequestSizeLimit: "1MB",
+			MaxJSONDepth:     64,
+			MaxArrayLength:   100000,
+		},
+		Storage: StorageConfig{
+			Enabled:    false,
+			Backend:    "file",
+			Path:       "./data/calculator-server.json",
+			TTL:        24 * time.Hour,
+			GCInterval: 10 * time.Minute,
+			Redis: RedisConfig{
+				KeyPrefix: "calculator:",
+			},
+		},
+		Fetcher: FetcherConfig{
+			Timeout:           10 * time.Second,
+			CacheTTL:          15 * time.Minute,
+			RequestsPerMinute: 60,
+		},
+		Currency: CurrencyConfig{
+			RatesBase:       "USD",
+			RefreshInterval: time.Hour,
 		},
 	}
 }
 
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	if c.Server.Transport != "stdio" && c.Server.Transport != "http" {
+	transports := c.Server.Transports()
+	if len(transports) == 0 {
 		return ErrInvalidTransport
 	}
+	seen := make(map[string]bool)
+	for _, transport := range transports {
+		if (transport != "stdio" && transport != "http") || seen[transport] {
+			return ErrInvalidTransport
+		}
+		seen[transport] = true
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return ErrInvalidShutdownTimeout
+	}
+	if c.Server.Stdio.Framing != "line" && c.Server.Stdio.Framing != "content-length" {
+		return ErrInvalidStdioFraming
+	}
+	if c.Server.Stdio.MaxMessageSize < 1 {
+		return ErrInvalidMaxMessageSize
+	}
+	if c.Server.Stdio.MaxCalls < 1 {
+		return ErrInvalidStdioMaxCalls
+	}
 
 	if c.Server.HTTP.Port < 1 || c.Server.HTTP.Port > 65535 {
 		return ErrInvalidPort
 	}
+	if c.Server.HTTP.OpsPort < 0 || c.Server.HTTP.OpsPort > 65535 || c.Server.HTTP.OpsPort == c.Server.HTTP.Port {
+		return ErrInvalidOpsPort
+	}
+	if c.Server.HTTP.MaxConnections < 0 {
+		return ErrInvalidMaxConnections
+	}
+	if c.Server.HTTP.SSEFlushInterval < 0 || c.Server.HTTP.SSEMaxPending < 0 {
+		return ErrInvalidSSEBatching
+	}
+	if c.Server.HTTP.IdempotencyTTL < 0 {
+		return ErrInvalidIdempotencyTTL
+	}
+	for _, proxy := range c.Server.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return ErrInvalidTrustedProxy
+		}
+	}
+	for _, origin := range c.Server.HTTP.AllowedOrigins {
+		if origin == "*" || origin == "null" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || strings.TrimSuffix(u.Path, "/") != "" || u.RawQuery != "" {
+			return ErrInvalidAllowedOrigin
+		}
+	}
+	if basePath := c.Server.HTTP.BasePath; basePath != "" && (!strings.HasPrefix(basePath, "/") || strings.ContainsAny(basePath, "?#")) {
+		return ErrInvalidBasePath
+	}
+	if chaos := c.Server.HTTP.Chaos; chaos.Enabled {
+		for _, rate := range []float64{chaos.DelayRate, chaos.ErrorRate, chaos.DropRate} {
+			if rate < 0 || rate > 1 {
+				return ErrInvalidChaos
+			}
+		}
+		if chaos.Delay < 0 {
+			return ErrInvalidChaos
+		}
+	}
 
 	if c.Tools.Precision.MaxDecimalPlaces < 0 || c.Tools.Precision.MaxDecimalPlaces > 15 {
 		return ErrInvalidPrecision
@@ -155,9 +509,237 @@ func (c *Config) Validate() error {
 		return ErrInvalidMaxDataPoints
 	}
 
+	if c.Tools.Statistics.Workers < 0 || c.Tools.Statistics.ParallelThreshold < 1 {
+		return ErrInvalidParallelism
+	}
+	if c.Tools.Ordering != "registration" && c.Tools.Ordering != "name" {
+		return ErrInvalidToolOrdering
+	}
+	if c.Tools.Strictness != "lenient" && c.Tools.Strictness != "strict" {
+		return ErrInvalidToolStrictness
+	}
+
+	if c.Logging.SlowCallThreshold < 0 {
+		return ErrInvalidSlowCallThreshold
+	}
+
+	if c.Security.SessionConcurrency.MaxCalls < 0 || c.Security.SessionConcurrency.QueueTimeout < 0 {
+		return ErrInvalidSessionConcurrency
+	}
+	if size, err := ParseByteSize(c.Security.RequestSizeLimit); err != nil || size < 1 {
+		return ErrInvalidRequestSizeLimit
+	}
+	if c.Security.MaxJSONDepth < 1 || c.Security.MaxArrayLength < 1 {
+		return ErrInvalidDecodeLimits
+	}
 	if c.Security.RateLimiting.RequestsPerMinute < 1 {
 		return ErrInvalidRateLimit
 	}
+	switch c.Security.RateLimiting.KeyBy {
+	case "ip", "session", "api_key":
+	default:
+		return ErrInvalidRateLimitKey
+	}
+
+	if c.Server.HTTP.Jobs.WebhookURL != "" {
+		u, err := url.Parse(c.Server.HTTP.Jobs.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidWebhookURL
+		}
+	}
+
+	if c.Fetcher.Timeout < 0 || c.Fetcher.CacheTTL < 0 || c.Fetcher.RequestsPerMinute < 0 {
+		return ErrInvalidFetcher
+	}
+
+	if c.Currency.RatesURL != "" {
+		u, err := url.Parse(c.Currency.RatesURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidRatesURL
+		}
+		if _, err := currency.Normalize(c.Currency.RatesBase); err != nil || c.Currency.RefreshInterval < 0 {
+			return ErrInvalidRatesRefresh
+		}
+	}
+
+	if c.Server.HTTP.Stateless && c.Server.HTTP.RequireSession {
+		return ErrInvalidSessionMode
+	}
+
+	if c.Server.HTTP.CORS.AllowCredentials {
+		for _, origin := range c.Server.HTTP.CORS.Origins {
+			if origin == "*" {
+				return ErrInvalidCORSCredentials
+			}
+		}
+	}
+
+	if c.Server.HTTP.Admin.Enabled && c.Server.HTTP.Admin.Token == "" {
+		return ErrMissingAdminToken
+	}
+
+	if err := c.validateTLS(); err != nil {
+		return err
+	}
+
+	if err := c.validateTenants(); err != nil {
+		return err
+	}
+
+	if err := c.validateAuth(); err != nil {
+		return err
+	}
+
+	if err := c.validateOAuth(); err != nil {
+		return err
+	}
+
+	if c.Storage.Enabled {
+		if c.Storage.Backend != "memory" && c.Storage.Backend != "file" && c.Storage.Backend != "redis" {
+			return ErrInvalidStorageBackend
+		}
+		if c.Storage.Backend == "file" && c.Storage.Path == "" {
+			return ErrInvalidStoragePath
+		}
+		if c.Storage.Backend == "redis" {
+			if u, err := url.Parse(c.Storage.Redis.URL); err != nil || u.Scheme != "redis" || u.Host == "" {
+				return ErrInvalidRedisURL
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTLS checks that HTTPS has a certificate, a supported minimum
+// version and known cipher suites
+func (c *Config) validateTLS() error {
+	tlsConfig := c.Server.HTTP.TLS
+	if !tlsConfig.Enabled {
+		return nil
+	}
+	if tlsConfig.CertFile == "" || tlsConfig.KeyFile == "" {
+		return fmt.Errorf("%w: cert_file and key_file are required", ErrInvalidTLS)
+	}
+	if tlsConfig.MinVersion != "" && tlsConfig.MinVersion != "1.2" && tlsConfig.MinVersion != "1.3" {
+		return fmt.Errorf("%w: min_version must be 1.2 or 1.3", ErrInvalidTLS)
+	}
+	known := make(map[string]bool)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = true
+	}
+	for _, name := range tlsConfig.CipherSuites {
+		if !known[name] {
+			return fmt.Errorf("%w: unknown or insecure cipher suite %s", ErrInvalidTLS, name)
+		}
+	}
+	return nil
+}
 
+// validateAuth checks that credentials have a name, a unique key and
+// non-negative quotas, and that API keys do not compete with tenants for
+// the X-API-Key header
+func (c *Config) validateAuth() error {
+	auth := c.Server.HTTP.Auth
+	if len(auth.APIKeys) > 0 && len(c.Tenants) > 0 {
+		return fmt.Errorf("%w: tenants use the X-API-Key header, so authenticate with bearer tokens", ErrInvalidAuth)
+	}
+	keys := make(map[string]bool)
+	for _, credential := range append(append([]CredentialConfig{}, auth.APIKeys...), auth.BearerTokens...) {
+		if credential.Name == "" {
+			return fmt.Errorf("%w: every API key and bearer token needs a name", ErrInvalidAuth)
+		}
+		if credential.Key == "" || keys[credential.Key] {
+			return fmt.Errorf("%w: key of %s must be non-empty and unique", ErrInvalidAuth, credential.Name)
+		}
+		if credential.DailyQuota < 0 || credential.MonthlyQuota < 0 {
+			return fmt.Errorf("%w: quotas of %s cannot be negative", ErrInvalidAuth, credential.Name)
+		}
+		keys[credential.Key] = true
+	}
 	return nil
 }
+
+// validateOAuth checks that the resource and its authorization server are
+// absolute URLs, and that no static bearer tokens compete with access tokens
+func (c *Config) validateOAuth() error {
+	oauth := c.Server.HTTP.OAuth
+	if !oauth.Enabled {
+		return nil
+	}
+	urls := [][2]string{{"resource_url", oauth.ResourceURL}, {"issuer", oauth.Issuer}}
+	if oauth.JWKSURL != "" {
+		urls = append(urls, [2]string{"jwks_url", oauth.JWKSURL})
+	}
+	for _, setting := range urls {
+		if u, err := url.Parse(setting[1]); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %s must be an absolute http or https URL", ErrInvalidOAuth, setting[0])
+		}
+	}
+	if len(c.Server.HTTP.Auth.BearerTokens) > 0 {
+		return fmt.Errorf("%w: static bearer tokens cannot be used alongside OAuth access tokens", ErrInvalidOAuth)
+	}
+	return nil
+}
+
+// validateTenants checks that tenant IDs and API keys are present and unique
+func (c *Config) validateTenants() error {
+	ids := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, tenant := range c.Tenants {
+		if tenant.ID == "" || ids[tenant.ID] {
+			return fmt.Errorf("%w: tenant IDs must be non-empty and unique", ErrInvalidTenant)
+		}
+		ids[tenant.ID] = true
+
+		if len(tenant.APIKeys) == 0 {
+			return fmt.Errorf("%w: tenant %s has no API keys", ErrInvalidTenant, tenant.ID)
+		}
+		for _, key := range tenant.APIKeys {
+			if key == "" || keys[key] {
+				return fmt.Errorf("%w: API keys of tenant %s must be non-empty and unique across tenants", ErrInvalidTenant, tenant.ID)
+			}
+			keys[key] = true
+		}
+
+		if tenant.RequestsPerMinute < 0 {
+			return fmt.Errorf("%w: tenant %s has a negative rate limit", ErrInvalidTenant, tenant.ID)
+		}
+		if rates := tenant.ExchangeRates; rates != nil {
+			if _, err := currency.NewStaticRateProvider(rates.Base, rates.Rates); err != nil {
+				return fmt.Errorf("%w: exchange rates of tenant %s: %v", ErrInvalidTenant, tenant.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// byteUnits are the suffixes of ParseByteSize, longest first so that "MB"
+// is not read as "B"
+var byteUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size such as "512KB", "1MB" or "1048576" into
+// bytes. Units are binary and case-insensitive.
+func ParseByteSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range byteUnits {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(number), unit.bytes
+			break
+		}
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number < 0 || number > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return number * multiplier, nil
+}
@@ -4,13 +4,47 @@ import "errors"
 
 // Configuration validation errors
 var (
-	ErrInvalidTransport        = errors.New("transport must be 'stdio' or 'http'")
-	ErrInvalidPort             = errors.New("port must be between 1 and 65535")
-	ErrInvalidPrecision        = errors.New("max decimal places must be between 0 and 15")
-	ErrInvalidDefaultPrecision = errors.New("default decimal places must be between 0 and max decimal places")
-	ErrInvalidMaxVariables     = errors.New("max variables must be at least 1")
-	ErrInvalidMaxDataPoints    = errors.New("max data points must be at least 1")
-	ErrInvalidRateLimit        = errors.New("requests per minute must be at least 1")
-	ErrConfigFileNotFound      = errors.New("configuration file not found")
-	ErrInvalidConfigFormat     = errors.New("invalid configuration file format")
+	ErrInvalidTransport          = errors.New("transport must be 'stdio', 'http' or both, e.g. 'stdio,http'")
+	ErrInvalidShutdownTimeout    = errors.New("shutdown timeout must be positive")
+	ErrInvalidStdioFraming       = errors.New("stdio framing must be 'line' or 'content-length'")
+	ErrInvalidMaxMessageSize     = errors.New("stdio max message size must be at least 1 byte")
+	ErrInvalidStdioMaxCalls      = errors.New("stdio max calls must be at least 1")
+	ErrInvalidPort               = errors.New("port must be between 1 and 65535")
+	ErrInvalidOpsPort            = errors.New("ops port must be 0 or a port between 1 and 65535 other than the MCP port")
+	ErrInvalidPrecision          = errors.New("max decimal places must be between 0 and 15")
+	ErrInvalidDefaultPrecision   = errors.New("default decimal places must be between 0 and max decimal places")
+	ErrInvalidMaxVariables       = errors.New("max variables must be at least 1")
+	ErrInvalidMaxDataPoints      = errors.New("max data points must be at least 1")
+	ErrInvalidParallelism        = errors.New("statistics workers cannot be negative and the parallel threshold must be at least 1")
+	ErrInvalidToolOrdering       = errors.New("tool ordering must be 'registration' or 'name'")
+	ErrInvalidToolStrictness     = errors.New("tool strictness must be 'lenient' or 'strict'")
+	ErrInvalidMaxConnections     = errors.New("max connections cannot be negative")
+	ErrInvalidSSEBatching        = errors.New("SSE flush interval and max pending events cannot be negative")
+	ErrInvalidIdempotencyTTL     = errors.New("idempotency TTL cannot be negative")
+	ErrInvalidChaos              = errors.New("chaos rates must be between 0 and 1 and the delay cannot be negative")
+	ErrInvalidTrustedProxy       = errors.New("trusted proxies must be IP addresses or CIDR ranges such as 10.0.0.0/8")
+	ErrInvalidBasePath           = errors.New("base path must start with / and contain no ? or #")
+	ErrInvalidAllowedOrigin      = errors.New("allowed origins must be *, null or an http or https scheme and host such as https://app.example.com")
+	ErrInvalidRateLimit          = errors.New("requests per minute must be at least 1")
+	ErrInvalidRequestSizeLimit   = errors.New("request size limit must be a positive size such as 1MB")
+	ErrInvalidDecodeLimits       = errors.New("max JSON depth and max array length must be at least 1")
+	ErrInvalidRateLimitKey       = errors.New("rate limiting key_by must be ip, session or api_key")
+	ErrInvalidSessionConcurrency = errors.New("session max calls and queue timeout cannot be negative")
+	ErrInvalidSlowCallThreshold  = errors.New("slow call threshold cannot be negative")
+	ErrInvalidWebhookURL         = errors.New("job webhook URL must be an absolute http or https URL")
+	ErrInvalidStorageBackend     = errors.New("storage backend must be 'memory', 'file' or 'redis'")
+	ErrInvalidTenant             = errors.New("invalid tenant configuration")
+	ErrInvalidAuth               = errors.New("invalid authentication configuration")
+	ErrInvalidOAuth              = errors.New("invalid OAuth configuration")
+	ErrInvalidTLS                = errors.New("invalid TLS configuration")
+	ErrMissingAdminToken         = errors.New("admin API requires a token")
+	ErrInvalidStoragePath        = errors.New("storage path is required for the file backend")
+	ErrInvalidRedisURL           = errors.New("redis URL of the form redis://host:port is required for the redis backend")
+	ErrInvalidSessionMode        = errors.New("sessions cannot be required by a stateless server")
+	ErrInvalidCORSCredentials    = errors.New("CORS credentials cannot be allowed for the \"*\" origin")
+	ErrInvalidFetcher            = errors.New("fetcher timeout, cache TTL and rate limit cannot be negative")
+	ErrInvalidRatesURL           = errors.New("currency rates URL must be an absolute http or https URL")
+	ErrInvalidRatesRefresh       = errors.New("currency rates base must be a three-letter code and the refresh interval cannot be negative")
+	ErrConfigFileNotFound        = errors.New("configuration file not found")
+	ErrInvalidConfigFormat       = errors.New("invalid configuration file format")
 )
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -143,6 +144,24 @@ func (l *Loader) loadFromEnvironment(config *Config) {
 	if val := os.Getenv("CALCULATOR_TRANSPORT"); val != "" {
 		config.Server.Transport = val
 	}
+	if val := os.Getenv("CALCULATOR_SHUTDOWN_TIMEOUT"); val != "" {
+		if timeout := parseDuration(val, config.Server.ShutdownTimeout); timeout > 0 {
+			config.Server.ShutdownTimeout = timeout
+		}
+	}
+	if val := os.Getenv("CALCULATOR_STDIO_FRAMING"); val != "" {
+		config.Server.Stdio.Framing = val
+	}
+	if val := os.Getenv("CALCULATOR_STDIO_MAX_MESSAGE_SIZE"); val != "" {
+		if size := parseInt(val, config.Server.Stdio.MaxMessageSize); size > 0 {
+			config.Server.Stdio.MaxMessageSize = size
+		}
+	}
+	if val := os.Getenv("CALCULATOR_STDIO_MAX_CALLS"); val != "" {
+		if calls := parseInt(val, config.Server.Stdio.MaxCalls); calls > 0 {
+			config.Server.Stdio.MaxCalls = calls
+		}
+	}
 	if val := os.Getenv("CALCULATOR_HTTP_HOST"); val != "" {
 		config.Server.HTTP.Host = val
 	}
@@ -151,6 +170,53 @@ func (l *Loader) loadFromEnvironment(config *Config) {
 			config.Server.HTTP.Port = port
 		}
 	}
+	if val := os.Getenv("CALCULATOR_HTTP_MAX_CONNECTIONS"); val != "" {
+		if connections := parseInt(val, config.Server.HTTP.MaxConnections); connections >= 0 {
+			config.Server.HTTP.MaxConnections = connections
+		}
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_OPS_PORT"); val != "" {
+		if port := parseInt(val, config.Server.HTTP.OpsPort); port >= 0 {
+			config.Server.HTTP.OpsPort = port
+		}
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_SSE_FLUSH_INTERVAL"); val != "" {
+		if interval := parseDuration(val, config.Server.HTTP.SSEFlushInterval); interval >= 0 {
+			config.Server.HTTP.SSEFlushInterval = interval
+		}
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_SSE_MAX_PENDING"); val != "" {
+		if pending := parseInt(val, config.Server.HTTP.SSEMaxPending); pending >= 0 {
+			config.Server.HTTP.SSEMaxPending = pending
+		}
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_TRUSTED_PROXIES"); val != "" {
+		config.Server.HTTP.TrustedProxies = nil
+		for _, proxy := range strings.Split(val, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				config.Server.HTTP.TrustedProxies = append(config.Server.HTTP.TrustedProxies, proxy)
+			}
+		}
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_ALLOWED_ORIGINS"); val != "" {
+		config.Server.HTTP.AllowedOrigins = nil
+		for _, origin := range strings.Split(val, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				config.Server.HTTP.AllowedOrigins = append(config.Server.HTTP.AllowedOrigins, origin)
+			}
+		}
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_BASE_PATH"); val != "" {
+		config.Server.HTTP.BasePath = val
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_CHAOS_ENABLED"); val != "" {
+		config.Server.HTTP.Chaos.Enabled = parseBool(val, config.Server.HTTP.Chaos.Enabled)
+	}
+	if val := os.Getenv("CALCULATOR_HTTP_IDEMPOTENCY_TTL"); val != "" {
+		if ttl := parseDuration(val, config.Server.HTTP.IdempotencyTTL); ttl > 0 {
+			config.Server.HTTP.IdempotencyTTL = ttl
+		}
+	}
 
 	// Logging configuration
 	if val := os.Getenv("CALCULATOR_LOG_LEVEL"); val != "" {
@@ -174,16 +240,107 @@ func (l *Loader) loadFromEnvironment(config *Config) {
 			config.Tools.Precision.DefaultDecimalPlaces = precision
 		}
 	}
+	if val := os.Getenv("CALCULATOR_STATS_WORKERS"); val != "" {
+		if workers := parseInt(val, config.Tools.Statistics.Workers); workers >= 0 {
+			config.Tools.Statistics.Workers = workers
+		}
+	}
+	if val := os.Getenv("CALCULATOR_CONVERSIONS_FILE"); val != "" {
+		config.Tools.Conversions.DataFile = val
+	}
+	if val := os.Getenv("CALCULATOR_TOOL_ORDERING"); val != "" {
+		config.Tools.Ordering = val
+	}
+	if val := os.Getenv("CALCULATOR_TOOL_STRICTNESS"); val != "" {
+		config.Tools.Strictness = val
+	}
+
+	// TLS configuration
+	if val := os.Getenv("CALCULATOR_TLS_ENABLED"); val != "" {
+		config.Server.HTTP.TLS.Enabled = parseBool(val, config.Server.HTTP.TLS.Enabled)
+	}
+	if val := os.Getenv("CALCULATOR_TLS_CERT_FILE"); val != "" {
+		config.Server.HTTP.TLS.CertFile = val
+	}
+	if val := os.Getenv("CALCULATOR_TLS_KEY_FILE"); val != "" {
+		config.Server.HTTP.TLS.KeyFile = val
+	}
+	if val := os.Getenv("CALCULATOR_TLS_CLIENT_CA_FILE"); val != "" {
+		config.Server.HTTP.TLS.ClientCAFile = val
+	}
+
+	// OAuth configuration
+	if val := os.Getenv("CALCULATOR_OAUTH_ENABLED"); val != "" {
+		config.Server.HTTP.OAuth.Enabled = parseBool(val, config.Server.HTTP.OAuth.Enabled)
+	}
+	if val := os.Getenv("CALCULATOR_OAUTH_RESOURCE_URL"); val != "" {
+		config.Server.HTTP.OAuth.ResourceURL = val
+	}
+	if val := os.Getenv("CALCULATOR_OAUTH_ISSUER"); val != "" {
+		config.Server.HTTP.OAuth.Issuer = val
+	}
+
+	// Admin token is best kept out of configuration files
+	if val := os.Getenv("CALCULATOR_ADMIN_TOKEN"); val != "" {
+		config.Server.HTTP.Admin.Token = val
+	}
+
+	// Credentials are best kept out of configuration files. Those given
+	// in the environment may call every tool.
+	if val := os.Getenv("CALCULATOR_AUTH_API_KEYS"); val != "" {
+		config.Server.HTTP.Auth.APIKeys = append(config.Server.HTTP.Auth.APIKeys, envCredentials("env-api-key", val)...)
+	}
+	if val := os.Getenv("CALCULATOR_AUTH_BEARER_TOKENS"); val != "" {
+		config.Server.HTTP.Auth.BearerTokens = append(config.Server.HTTP.Auth.BearerTokens, envCredentials("env-bearer-token", val)...)
+	}
+
+	// Job webhook secret is best kept out of configuration files
+	if val := os.Getenv("CALCULATOR_JOBS_WEBHOOK_SECRET"); val != "" {
+		config.Server.HTTP.Jobs.WebhookSecret = val
+	}
+
+	// Storage configuration
+	if val := os.Getenv("CALCULATOR_STORAGE_ENABLED"); val != "" {
+		config.Storage.Enabled = parseBool(val, config.Storage.Enabled)
+	}
+	if val := os.Getenv("CALCULATOR_STORAGE_PATH"); val != "" {
+		config.Storage.Path = val
+	}
+	if val := os.Getenv("CALCULATOR_REDIS_URL"); val != "" {
+		config.Storage.Redis.URL = val
+	}
 
 	// Security configuration
 	if val := os.Getenv("CALCULATOR_RATE_LIMIT_ENABLED"); val != "" {
-		config.Security.RateLimiting.Enabled = parseBool(val, config.Security.RateLimiting.Enabled)
+		enabled := parseBool(val, config.Security.RateLimiting.IsEnabled())
+		config.Security.RateLimiting.Enabled = &enabled
 	}
 	if val := os.Getenv("CALCULATOR_REQUESTS_PER_MINUTE"); val != "" {
 		if rpm := parseInt(val, config.Security.RateLimiting.RequestsPerMinute); rpm > 0 {
 			config.Security.RateLimiting.RequestsPerMinute = rpm
 		}
 	}
+	if val := os.Getenv("CALCULATOR_REQUEST_SIZE_LIMIT"); val != "" {
+		config.Security.RequestSizeLimit = val
+	}
+	if val := os.Getenv("CALCULATOR_MAX_JSON_DEPTH"); val != "" {
+		if depth := parseInt(val, config.Security.MaxJSONDepth); depth > 0 {
+			config.Security.MaxJSONDepth = depth
+		}
+	}
+	if val := os.Getenv("CALCULATOR_MAX_ARRAY_LENGTH"); val != "" {
+		if length := parseInt(val, config.Security.MaxArrayLength); length > 0 {
+			config.Security.MaxArrayLength = length
+		}
+	}
+	if val := os.Getenv("CALCULATOR_RATE_LIMIT_KEY_BY"); val != "" {
+		config.Security.RateLimiting.KeyBy = val
+	}
+	if val := os.Getenv("CALCULATOR_SESSION_MAX_CALLS"); val != "" {
+		if calls := parseInt(val, config.Security.SessionConcurrency.MaxCalls); calls >= 0 {
+			config.Security.SessionConcurrency.MaxCalls = calls
+		}
+	}
 }
 
 // mergeConfig merges source configuration into destination
@@ -194,6 +351,18 @@ func mergeConfig(dest, src *Config) error {
 	if src.Server.Transport != "" {
 		dest.Server.Transport = src.Server.Transport
 	}
+	if src.Server.ShutdownTimeout != 0 {
+		dest.Server.ShutdownTimeout = src.Server.ShutdownTimeout
+	}
+	if src.Server.Stdio.Framing != "" {
+		dest.Server.Stdio.Framing = src.Server.Stdio.Framing
+	}
+	if src.Server.Stdio.MaxMessageSize != 0 {
+		dest.Server.Stdio.MaxMessageSize = src.Server.Stdio.MaxMessageSize
+	}
+	if src.Server.Stdio.MaxCalls != 0 {
+		dest.Server.Stdio.MaxCalls = src.Server.Stdio.MaxCalls
+	}
 	if src.Server.HTTP.Host != "" {
 		dest.Server.HTTP.Host = src.Server.HTTP.Host
 	}
@@ -207,6 +376,21 @@ func mergeConfig(dest, src *Config) error {
 	if len(src.Server.HTTP.CORS.Origins) > 0 {
 		dest.Server.HTTP.CORS.Origins = src.Server.HTTP.CORS.Origins
 	}
+	if len(src.Server.HTTP.CORS.Methods) > 0 {
+		dest.Server.HTTP.CORS.Methods = src.Server.HTTP.CORS.Methods
+	}
+	if len(src.Server.HTTP.CORS.Headers) > 0 {
+		dest.Server.HTTP.CORS.Headers = src.Server.HTTP.CORS.Headers
+	}
+	if len(src.Server.HTTP.CORS.ExposedHeaders) > 0 {
+		dest.Server.HTTP.CORS.ExposedHeaders = src.Server.HTTP.CORS.ExposedHeaders
+	}
+	if src.Server.HTTP.CORS.AllowCredentials {
+		dest.Server.HTTP.CORS.AllowCredentials = true
+	}
+	if src.Server.HTTP.CORS.MaxAge != 0 {
+		dest.Server.HTTP.CORS.MaxAge = src.Server.HTTP.CORS.MaxAge
+	}
 
 	// Merge session settings
 	if src.Server.HTTP.SessionTimeout != 0 {
@@ -215,6 +399,99 @@ func mergeConfig(dest, src *Config) error {
 	if src.Server.HTTP.MaxConnections != 0 {
 		dest.Server.HTTP.MaxConnections = src.Server.HTTP.MaxConnections
 	}
+	if src.Server.HTTP.MetricsEnabled {
+		dest.Server.HTTP.MetricsEnabled = true
+	}
+	if src.Server.HTTP.HealthEnabled {
+		dest.Server.HTTP.HealthEnabled = true
+	}
+	if src.Server.HTTP.OpsPort != 0 {
+		dest.Server.HTTP.OpsPort = src.Server.HTTP.OpsPort
+	}
+	if src.Server.HTTP.DisableSSE {
+		dest.Server.HTTP.DisableSSE = true
+	}
+	if src.Server.HTTP.SSEFlushInterval != 0 {
+		dest.Server.HTTP.SSEFlushInterval = src.Server.HTTP.SSEFlushInterval
+	}
+	if src.Server.HTTP.SSEMaxPending != 0 {
+		dest.Server.HTTP.SSEMaxPending = src.Server.HTTP.SSEMaxPending
+	}
+	if src.Server.HTTP.IdempotencyTTL != 0 {
+		dest.Server.HTTP.IdempotencyTTL = src.Server.HTTP.IdempotencyTTL
+	}
+	if src.Server.HTTP.Stateless {
+		dest.Server.HTTP.Stateless = true
+	}
+	if src.Server.HTTP.RequireSession {
+		dest.Server.HTTP.RequireSession = true
+	}
+	if src.Server.HTTP.RequestIDMeta {
+		dest.Server.HTTP.RequestIDMeta = true
+	}
+	if src.Server.HTTP.TLS.Enabled {
+		dest.Server.HTTP.TLS.Enabled = true
+	}
+	if src.Server.HTTP.TLS.CertFile != "" {
+		dest.Server.HTTP.TLS.CertFile = src.Server.HTTP.TLS.CertFile
+	}
+	if src.Server.HTTP.TLS.KeyFile != "" {
+		dest.Server.HTTP.TLS.KeyFile = src.Server.HTTP.TLS.KeyFile
+	}
+	if src.Server.HTTP.TLS.ClientCAFile != "" {
+		dest.Server.HTTP.TLS.ClientCAFile = src.Server.HTTP.TLS.ClientCAFile
+	}
+	if src.Server.HTTP.TLS.MinVersion != "" {
+		dest.Server.HTTP.TLS.MinVersion = src.Server.HTTP.TLS.MinVersion
+	}
+	if len(src.Server.HTTP.TLS.CipherSuites) > 0 {
+		dest.Server.HTTP.TLS.CipherSuites = src.Server.HTTP.TLS.CipherSuites
+	}
+	if src.Server.HTTP.OAuth.Enabled {
+		dest.Server.HTTP.OAuth.Enabled = true
+	}
+	if src.Server.HTTP.OAuth.ResourceURL != "" {
+		dest.Server.HTTP.OAuth.ResourceURL = src.Server.HTTP.OAuth.ResourceURL
+	}
+	if src.Server.HTTP.OAuth.Issuer != "" {
+		dest.Server.HTTP.OAuth.Issuer = src.Server.HTTP.OAuth.Issuer
+	}
+	if src.Server.HTTP.OAuth.Audience != "" {
+		dest.Server.HTTP.OAuth.Audience = src.Server.HTTP.OAuth.Audience
+	}
+	if src.Server.HTTP.OAuth.JWKSURL != "" {
+		dest.Server.HTTP.OAuth.JWKSURL = src.Server.HTTP.OAuth.JWKSURL
+	}
+	if src.Server.HTTP.Admin.Enabled {
+		dest.Server.HTTP.Admin.Enabled = true
+	}
+	if src.Server.HTTP.Admin.Token != "" {
+		dest.Server.HTTP.Admin.Token = src.Server.HTTP.Admin.Token
+	}
+	if src.Server.HTTP.Jobs.Enabled {
+		dest.Server.HTTP.Jobs.Enabled = true
+	}
+	if src.Server.HTTP.Jobs.WebhookURL != "" {
+		dest.Server.HTTP.Jobs.WebhookURL = src.Server.HTTP.Jobs.WebhookURL
+	}
+	if src.Server.HTTP.Jobs.WebhookSecret != "" {
+		dest.Server.HTTP.Jobs.WebhookSecret = src.Server.HTTP.Jobs.WebhookSecret
+	}
+	if src.Server.HTTP.Jobs.WebhookTimeout != 0 {
+		dest.Server.HTTP.Jobs.WebhookTimeout = src.Server.HTTP.Jobs.WebhookTimeout
+	}
+	if len(src.Server.HTTP.TrustedProxies) > 0 {
+		dest.Server.HTTP.TrustedProxies = src.Server.HTTP.TrustedProxies
+	}
+	if len(src.Server.HTTP.AllowedOrigins) > 0 {
+		dest.Server.HTTP.AllowedOrigins = src.Server.HTTP.AllowedOrigins
+	}
+	if src.Server.HTTP.BasePath != "" {
+		dest.Server.HTTP.BasePath = src.Server.HTTP.BasePath
+	}
+	if src.Server.HTTP.Chaos.Enabled {
+		dest.Server.HTTP.Chaos = src.Server.HTTP.Chaos
+	}
 
 	// Merge logging settings
 	if src.Logging.Level != "" {
@@ -226,6 +503,9 @@ func mergeConfig(dest, src *Config) error {
 	if src.Logging.Output != "" {
 		dest.Logging.Output = src.Logging.Output
 	}
+	if src.Logging.SlowCallThreshold != 0 {
+		dest.Logging.SlowCallThreshold = src.Logging.SlowCallThreshold
+	}
 
 	// Merge tools settings
 	if src.Tools.Precision.MaxDecimalPlaces != 0 {
@@ -245,18 +525,125 @@ func mergeConfig(dest, src *Config) error {
 	if src.Tools.Statistics.MaxDataPoints != 0 {
 		dest.Tools.Statistics.MaxDataPoints = src.Tools.Statistics.MaxDataPoints
 	}
+	if src.Tools.Statistics.Workers != 0 {
+		dest.Tools.Statistics.Workers = src.Tools.Statistics.Workers
+	}
+	if src.Tools.Statistics.ParallelThreshold != 0 {
+		dest.Tools.Statistics.ParallelThreshold = src.Tools.Statistics.ParallelThreshold
+	}
 
 	if src.Tools.Financial.CurrencyDefault != "" {
 		dest.Tools.Financial.CurrencyDefault = src.Tools.Financial.CurrencyDefault
 	}
+	if src.Tools.Conversions.DataFile != "" {
+		dest.Tools.Conversions.DataFile = src.Tools.Conversions.DataFile
+	}
+	if len(src.Tools.Groups) > 0 && dest.Tools.Groups == nil {
+		dest.Tools.Groups = make(map[string]bool)
+	}
+	for group, enabled := range src.Tools.Groups {
+		dest.Tools.Groups[group] = enabled
+	}
+	if src.Tools.Ordering != "" {
+		dest.Tools.Ordering = src.Tools.Ordering
+	}
+	if src.Tools.Strictness != "" {
+		dest.Tools.Strictness = src.Tools.Strictness
+	}
+	if src.Tools.Elicitation != nil {
+		dest.Tools.Elicitation = src.Tools.Elicitation
+	}
+	if src.Tools.Explanations != nil {
+		dest.Tools.Explanations = src.Tools.Explanations
+	}
 
 	// Merge security settings
+	// Note: Always merge rate limiting Enabled since false is a valid override value
+	if src.Security.RateLimiting.Enabled != nil {
+		dest.Security.RateLimiting.Enabled = src.Security.RateLimiting.Enabled
+	}
 	if src.Security.RateLimiting.RequestsPerMinute != 0 {
 		dest.Security.RateLimiting.RequestsPerMinute = src.Security.RateLimiting.RequestsPerMinute
 	}
+	if src.Security.RateLimiting.KeyBy != "" {
+		dest.Security.RateLimiting.KeyBy = src.Security.RateLimiting.KeyBy
+	}
 	if src.Security.RequestSizeLimit != "" {
 		dest.Security.RequestSizeLimit = src.Security.RequestSizeLimit
 	}
+	if src.Security.MaxJSONDepth != 0 {
+		dest.Security.MaxJSONDepth = src.Security.MaxJSONDepth
+	}
+	if src.Security.MaxArrayLength != 0 {
+		dest.Security.MaxArrayLength = src.Security.MaxArrayLength
+	}
+	if src.Security.SessionConcurrency.MaxCalls != 0 {
+		dest.Security.SessionConcurrency.MaxCalls = src.Security.SessionConcurrency.MaxCalls
+	}
+	if src.Security.SessionConcurrency.QueueTimeout != 0 {
+		dest.Security.SessionConcurrency.QueueTimeout = src.Security.SessionConcurrency.QueueTimeout
+	}
+
+	// Credentials are replaced as a whole
+	if len(src.Server.HTTP.Auth.APIKeys) > 0 {
+		dest.Server.HTTP.Auth.APIKeys = src.Server.HTTP.Auth.APIKeys
+	}
+	if len(src.Server.HTTP.Auth.BearerTokens) > 0 {
+		dest.Server.HTTP.Auth.BearerTokens = src.Server.HTTP.Auth.BearerTokens
+	}
+
+	// Tenants are replaced as a whole
+	if len(src.Tenants) > 0 {
+		dest.Tenants = src.Tenants
+	}
+
+	// Merge storage settings
+	if src.Storage.Enabled {
+		dest.Storage.Enabled = true
+	}
+	if src.Storage.Backend != "" {
+		dest.Storage.Backend = src.Storage.Backend
+	}
+	if src.Storage.Path != "" {
+		dest.Storage.Path = src.Storage.Path
+	}
+	if src.Storage.TTL != 0 {
+		dest.Storage.TTL = src.Storage.TTL
+	}
+	if src.Storage.GCInterval != 0 {
+		dest.Storage.GCInterval = src.Storage.GCInterval
+	}
+	if src.Storage.Redis.URL != "" {
+		dest.Storage.Redis.URL = src.Storage.Redis.URL
+	}
+	if src.Storage.Redis.KeyPrefix != "" {
+		dest.Storage.Redis.KeyPrefix = src.Storage.Redis.KeyPrefix
+	}
+
+	// Merge fetcher settings
+	if len(src.Fetcher.AllowedHosts) > 0 {
+		dest.Fetcher.AllowedHosts = src.Fetcher.AllowedHosts
+	}
+	if src.Fetcher.Timeout != 0 {
+		dest.Fetcher.Timeout = src.Fetcher.Timeout
+	}
+	if src.Fetcher.CacheTTL != 0 {
+		dest.Fetcher.CacheTTL = src.Fetcher.CacheTTL
+	}
+	if src.Fetcher.RequestsPerMinute != 0 {
+		dest.Fetcher.RequestsPerMinute = src.Fetcher.RequestsPerMinute
+	}
+
+	// Merge currency settings
+	if src.Currency.RatesURL != "" {
+		dest.Currency.RatesURL = src.Currency.RatesURL
+	}
+	if src.Currency.RatesBase != "" {
+		dest.Currency.RatesBase = src.Currency.RatesBase
+	}
+	if src.Currency.RefreshInterval != 0 {
+		dest.Currency.RefreshInterval = src.Currency.RefreshInterval
+	}
 
 	return nil
 }
@@ -270,6 +657,14 @@ func parseInt(s string, defaultVal int) int {
 	return result
 }
 
+func parseDuration(s string, defaultVal time.Duration) time.Duration {
+	result, err := time.ParseDuration(s)
+	if err != nil {
+		return defaultVal
+	}
+	return result
+}
+
 func parseBool(s string, defaultVal bool) bool {
 	switch strings.ToLower(s) {
 	case "true", "1", "yes", "on":
@@ -280,3 +675,15 @@ func parseBool(s string, defaultVal bool) bool {
 		return defaultVal
 	}
 }
+
+// envCredentials turns a comma-separated list of keys into credentials
+// named prefix-1, prefix-2 and so on
+func envCredentials(prefix, list string) []CredentialConfig {
+	var credentials []CredentialConfig
+	for _, key := range strings.Split(list, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			credentials = append(credentials, CredentialConfig{Name: fmt.Sprintf("%s-%d", prefix, len(credentials)+1), Key: key})
+		}
+	}
+	return credentials
+}
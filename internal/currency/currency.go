@@ -0,0 +1,142 @@
+// Package currency validates ISO 4217 currency codes, formats amounts in a
+// currency and looks up exchange rates for reporting-currency conversion.
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"calculator-server/internal/fetch"
+)
+
+// style describes how amounts in a currency are written
+type style struct {
+	symbol   string
+	decimals int
+}
+
+// styles covers common currencies; other valid codes are written with the
+// code as a prefix and two decimals
+var styles = map[string]style{
+	"USD": {"$", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"JPY": {"¥", 0},
+	"CNY": {"CN¥", 2},
+	"INR": {"₹", 2},
+	"KRW": {"₩", 0},
+	"CAD": {"CA$", 2},
+	"AUD": {"A$", 2},
+	"NZD": {"NZ$", 2},
+	"CHF": {"CHF ", 2},
+	"BRL": {"R$", 2},
+	"MXN": {"MX$", 2},
+}
+
+// Normalize upper-cases a currency code and checks that it is three letters
+func Normalize(code string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	if len(normalized) != 3 {
+		return "", fmt.Errorf("invalid currency code %q: expected a three-letter ISO 4217 code", code)
+	}
+	for _, letter := range normalized {
+		if letter < 'A' || letter > 'Z' {
+			return "", fmt.Errorf("invalid currency code %q: expected a three-letter ISO 4217 code", code)
+		}
+	}
+	return normalized, nil
+}
+
+// Decimals returns the number of minor-unit digits used for the currency
+func Decimals(code string) int {
+	if s, ok := styles[code]; ok {
+		return s.decimals
+	}
+	return 2
+}
+
+// Format writes amount in the currency with its symbol, minor-unit rounding
+// and thousands separators, e.g. "$1,234.56" or "-¥1,235"
+func Format(amount float64, code string) string {
+	s, ok := styles[code]
+	if !ok {
+		s = style{symbol: code + " ", decimals: 2}
+	}
+
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', s.decimals, 64)
+	whole, fraction := digits, ""
+	if dot := strings.IndexByte(digits, '.'); dot >= 0 {
+		whole, fraction = digits[:dot], digits[dot:]
+	}
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	sign := ""
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		sign = "-"
+	}
+	return sign + s.symbol + grouped.String() + fraction
+}
+
+// RateProvider returns the number of units of to that one unit of from buys
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// HTTPRateProvider reads exchange rates from a JSON endpoint through the
+// allowlisted fetcher. The URL may contain {base}, which is replaced with
+// the source currency, and the response must look like
+//
+//	{"rates": {"EUR": 0.92, "GBP": 0.79}}
+type HTTPRateProvider struct {
+	fetcher     *fetch.Fetcher
+	urlTemplate string
+}
+
+// NewHTTPRateProvider creates a provider fetching rates from urlTemplate
+func NewHTTPRateProvider(fetcher *fetch.Fetcher, urlTemplate string) *HTTPRateProvider {
+	return &HTTPRateProvider{fetcher: fetcher, urlTemplate: urlTemplate}
+}
+
+// Rates fetches every rate published for base
+func (p *HTTPRateProvider) Rates(ctx context.Context, base string) (map[string]float64, error) {
+	body, err := p.fetcher.Get(ctx, strings.ReplaceAll(p.urlTemplate, "{base}", base))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exchange rates for %s: %v", base, err)
+	}
+
+	var response struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate response for %s: %v", base, err)
+	}
+	return response.Rates, nil
+}
+
+// Rate fetches the rates for from and picks out to
+func (p *HTTPRateProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	rates, err := p.Rates(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, ok := rates[to]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate from %s to %s", from, to)
+	}
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return 0, fmt.Errorf("invalid exchange rate from %s to %s: %v", from, to, rate)
+	}
+	return rate, nil
+}
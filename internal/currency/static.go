@@ -0,0 +1,62 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// StaticRateProvider converts with a fixed table of rates against one base
+// currency, such as the rates a tenant negotiated with its bank. Rates
+// between two other currencies are crossed through the base.
+type StaticRateProvider struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticRateProvider creates a provider in which one unit of base buys
+// rates[code] units of each listed currency
+func NewStaticRateProvider(base string, rates map[string]float64) (*StaticRateProvider, error) {
+	normalizedBase, err := Normalize(base)
+	if err != nil {
+		return nil, err
+	}
+	provider := &StaticRateProvider{base: normalizedBase, rates: map[string]float64{normalizedBase: 1}}
+	for code, rate := range rates {
+		normalized, err := Normalize(code)
+		if err != nil {
+			return nil, err
+		}
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return nil, fmt.Errorf("invalid exchange rate from %s to %s: %v", normalizedBase, normalized, rate)
+		}
+		provider.rates[normalized] = rate
+	}
+	return provider, nil
+}
+
+// Rate returns the number of units of to that one unit of from buys
+func (p *StaticRateProvider) Rate(_ context.Context, from, to string) (float64, error) {
+	fromRate, fromListed := p.rates[from]
+	toRate, toListed := p.rates[to]
+	if !fromListed || !toListed {
+		return 0, fmt.Errorf("no exchange rate from %s to %s", from, to)
+	}
+	return toRate / fromRate, nil
+}
+
+// providerKey is the context key of the rate provider of a request
+type providerKey struct{}
+
+// WithRateProvider returns a context whose reporting-currency conversions
+// use provider instead of the server's, e.g. the rate table of one tenant
+func WithRateProvider(ctx context.Context, provider RateProvider) context.Context {
+	return context.WithValue(ctx, providerKey{}, provider)
+}
+
+// RateProviderFromContext returns the provider set with WithRateProvider, or
+// nil when the context carries none
+func RateProviderFromContext(ctx context.Context) RateProvider {
+	provider, _ := ctx.Value(providerKey{}).(RateProvider)
+	return provider
+}
@@ -0,0 +1,69 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// RateTable keeps the latest exchange rates of one base currency, so they
+// can be published as a resource and refreshed on a schedule
+type RateTable struct {
+	provider *HTTPRateProvider
+	base     string
+
+	mu      sync.RWMutex
+	rates   map[string]float64
+	updated time.Time
+}
+
+// RateSnapshot is the content of a rate table at one point in time
+type RateSnapshot struct {
+	Base    string             `json:"base"`
+	Updated time.Time          `json:"updated"` // Zero until the first successful refresh
+	Rates   map[string]float64 `json:"rates"`
+}
+
+// NewRateTable creates an empty table of the rates of base
+func NewRateTable(provider *HTTPRateProvider, base string) *RateTable {
+	return &RateTable{provider: provider, base: base}
+}
+
+// Refresh fetches the rates again, reporting whether any of them changed.
+// Invalid rates are left out. On failure the previous rates are kept.
+func (t *RateTable) Refresh(ctx context.Context) (bool, error) {
+	fetched, err := t.provider.Rates(ctx, t.base)
+	if err != nil {
+		return false, err
+	}
+	rates := make(map[string]float64, len(fetched))
+	for code, rate := range fetched {
+		if rate > 0 && !math.IsInf(rate, 0) && !math.IsNaN(rate) {
+			rates[code] = rate
+		}
+	}
+	if len(rates) == 0 {
+		return false, fmt.Errorf("no valid exchange rates for %s", t.base)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := !reflect.DeepEqual(rates, t.rates)
+	t.rates = rates
+	t.updated = time.Now().UTC()
+	return changed, nil
+}
+
+// Snapshot returns the current rates
+func (t *RateTable) Snapshot() RateSnapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	rates := make(map[string]float64, len(t.rates))
+	for code, rate := range t.rates {
+		rates[code] = rate
+	}
+	return RateSnapshot{Base: t.base, Updated: t.updated, Rates: rates}
+}
@@ -0,0 +1,135 @@
+// Package export renders calculation history and generated tables as
+// CSV or JSON documents
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// Supported export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Table is a named set of rows sharing the same columns
+type Table struct {
+	Name    string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// MimeType returns the content type of the given export format
+func MimeType(format string) string {
+	if format == FormatCSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+// Encode renders the table in the given format. JSON output is an array
+// of objects keyed by column name.
+func Encode(table Table, format string) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return encodeCSV(table)
+	case FormatJSON:
+		records := make([]map[string]interface{}, 0, len(table.Rows))
+		for _, row := range table.Rows {
+			record := make(map[string]interface{}, len(table.Columns))
+			for i, column := range table.Columns {
+				record[column] = row[i]
+			}
+			records = append(records, record)
+		}
+		return json.MarshalIndent(records, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s. Supported formats: [%s %s]", format, FormatCSV, FormatJSON)
+	}
+}
+
+func encodeCSV(table Table) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(table.Columns); err != nil {
+		return nil, err
+	}
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = formatCell(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// formatCell renders scalars directly and anything structured as compact JSON
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// HistoryTable converts calculation history entries into a table
+func HistoryTable(entries []types.HistoryEntry) Table {
+	table := Table{
+		Name:    "history",
+		Columns: []string{"timestamp", "tool", "arguments", "result"},
+	}
+	for _, entry := range entries {
+		table.Rows = append(table.Rows, []interface{}{entry.Timestamp, entry.Tool, entry.Arguments, entry.Result})
+	}
+	return table
+}
+
+// AmortizationTable converts a loan amortization schedule into a table
+func AmortizationTable(schedule []types.AmortizationRow) Table {
+	table := Table{
+		Name:    "amortization",
+		Columns: []string{"period", "payment", "principal", "interest", "balance"},
+	}
+	for _, row := range schedule {
+		table.Rows = append(table.Rows, []interface{}{row.Period, row.Payment, row.Principal, row.Interest, row.Balance})
+	}
+	return table
+}
+
+// HistogramTable converts histogram bins into a table
+func HistogramTable(bins []types.HistogramBin) Table {
+	table := Table{
+		Name:    "histogram",
+		Columns: []string{"lower", "upper", "count"},
+	}
+	for _, bin := range bins {
+		table.Rows = append(table.Rows, []interface{}{bin.Lower, bin.Upper, bin.Count})
+	}
+	return table
+}
@@ -0,0 +1,292 @@
+// Package fetch is the single path for outbound HTTP made by tool handlers.
+// It only contacts allowlisted hosts, rate-limits requests per host, bounds
+// every request with a timeout and caches successful responses. Redirects
+// must stay on allowlisted hosts, and private, loopback and link-local
+// addresses are only dialed when the allowlist names them as IP literals,
+// so that an allowlisted name cannot be pointed at internal services.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultMaxBodyBytes bounds response bodies when Config.MaxBodyBytes is zero
+const DefaultMaxBodyBytes = 1 << 20
+
+var (
+	// ErrHostNotAllowed is returned for URLs whose host is not allowlisted
+	ErrHostNotAllowed = errors.New("host is not in the fetcher allowlist")
+	// ErrRateLimited is returned when a host's request budget is exhausted
+	ErrRateLimited = errors.New("outbound request rate limit exceeded")
+	// ErrBodyTooLarge is returned for responses larger than the body limit
+	ErrBodyTooLarge = errors.New("response body exceeds the size limit")
+	// ErrAddressNotAllowed is returned when a host resolves to a private,
+	// loopback or link-local address that is not allowlisted
+	ErrAddressNotAllowed = errors.New("host resolves to an address that is not allowed")
+)
+
+// maxRedirects bounds the redirects followed by one request
+const maxRedirects = 5
+
+// Config controls which hosts may be fetched and how
+type Config struct {
+	AllowedHosts      []string      // Exact host names, or "*.example.com" for subdomains
+	Timeout           time.Duration // Per-request timeout; zero means no timeout
+	CacheTTL          time.Duration // Zero disables caching
+	RequestsPerMinute int           // Per host; zero disables rate limiting
+	MaxBodyBytes      int64         // Zero means DefaultMaxBodyBytes
+}
+
+// Stats counts fetcher activity for monitoring
+type Stats struct {
+	Requests    int64 `json:"requests"`
+	CacheHits   int64 `json:"cache_hits"`
+	Errors      int64 `json:"errors"`
+	RateLimited int64 `json:"rate_limited"`
+	Denied      int64 `json:"denied"`
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+type hostBucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// Fetcher performs allowlisted, rate-limited and cached GET requests
+type Fetcher struct {
+	config  Config
+	client  *http.Client
+	mu      sync.Mutex
+	cache   map[string]cacheEntry
+	buckets map[string]*hostBucket
+
+	requests    int64
+	cacheHits   int64
+	errors      int64
+	rateLimited int64
+	denied      int64
+}
+
+// New creates a fetcher with the given configuration
+func New(config Config) *Fetcher {
+	if config.MaxBodyBytes == 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	f := &Fetcher{
+		config:  config,
+		cache:   make(map[string]cacheEntry),
+		buckets: make(map[string]*hostBucket),
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, Control: f.checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would dial on our behalf, unchecked
+	transport.DialContext = dialer.DialContext
+	f.client = &http.Client{
+		Timeout:       config.Timeout,
+		Transport:     transport,
+		CheckRedirect: f.checkRedirect,
+	}
+	return f
+}
+
+// checkRedirect follows redirects only to allowlisted http and https hosts
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to a non-HTTP URL %q", req.URL)
+	}
+	if host := strings.ToLower(req.URL.Hostname()); !f.hostAllowed(host) {
+		atomic.AddInt64(&f.denied, 1)
+		return fmt.Errorf("redirect %w: %s", ErrHostNotAllowed, host)
+	}
+	return nil
+}
+
+// checkAddress runs before every connection, once the host name has been
+// resolved, and refuses internal addresses the allowlist does not name
+func (f *Fetcher) checkAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, host)
+	}
+	if !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsUnspecified() && !ip.IsMulticast() && !ip.IsInterfaceLocalMulticast() {
+		return nil
+	}
+	for _, allowed := range f.config.AllowedHosts {
+		if allowedIP := net.ParseIP(strings.Trim(allowed, "[]")); allowedIP != nil && allowedIP.Equal(ip) {
+			return nil
+		}
+	}
+	atomic.AddInt64(&f.denied, 1)
+	return fmt.Errorf("%w: %s", ErrAddressNotAllowed, ip)
+}
+
+// Get returns the body of a successful GET request for rawURL, from the
+// cache when a fresh copy is available
+func (f *Fetcher) Get(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: must be an absolute http or https URL", rawURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if !f.hostAllowed(host) {
+		atomic.AddInt64(&f.denied, 1)
+		return nil, fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+	}
+
+	if body, ok := f.cached(rawURL); ok {
+		atomic.AddInt64(&f.cacheHits, 1)
+		return body, nil
+	}
+
+	if !f.allow(host) {
+		atomic.AddInt64(&f.rateLimited, 1)
+		return nil, fmt.Errorf("%w for %s", ErrRateLimited, host)
+	}
+
+	atomic.AddInt64(&f.requests, 1)
+	body, err := f.do(ctx, rawURL)
+	if err != nil {
+		atomic.AddInt64(&f.errors, 1)
+		log.Printf("Outbound fetch of %s failed: %v", rawURL, err)
+		return nil, err
+	}
+
+	f.store(rawURL, body)
+	return body, nil
+}
+
+// Stats returns a snapshot of the fetcher's counters
+func (f *Fetcher) Stats() Stats {
+	return Stats{
+		Requests:    atomic.LoadInt64(&f.requests),
+		CacheHits:   atomic.LoadInt64(&f.cacheHits),
+		Errors:      atomic.LoadInt64(&f.errors),
+		RateLimited: atomic.LoadInt64(&f.rateLimited),
+		Denied:      atomic.LoadInt64(&f.denied),
+	}
+}
+
+func (f *Fetcher) do(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s responded with status %d", req.URL.Host, resp.StatusCode)
+	}
+
+	// Read one byte past the limit to tell a full body from a truncated one
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.config.MaxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.config.MaxBodyBytes {
+		return nil, ErrBodyTooLarge
+	}
+	return body, nil
+}
+
+func (f *Fetcher) hostAllowed(host string) bool {
+	for _, allowed := range f.config.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+		if suffix := strings.TrimPrefix(allowed, "*"); suffix != allowed {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fetcher) cached(rawURL string) ([]byte, bool) {
+	if f.config.CacheTTL <= 0 {
+		return nil, false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry, exists := f.cache[rawURL]
+	if !exists || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (f *Fetcher) store(rawURL string, body []byte) {
+	if f.config.CacheTTL <= 0 {
+		return
+	}
+
+	now := time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, entry := range f.cache {
+		if now.After(entry.expires) {
+			delete(f.cache, key)
+		}
+	}
+	f.cache[rawURL] = cacheEntry{body: body, expires: now.Add(f.config.CacheTTL)}
+}
+
+// allow takes a token from the host's bucket, which holds up to
+// RequestsPerMinute tokens and refills continuously
+func (f *Fetcher) allow(host string) bool {
+	if f.config.RequestsPerMinute <= 0 {
+		return true
+	}
+	capacity := float64(f.config.RequestsPerMinute)
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, exists := f.buckets[host]
+	if !exists {
+		bucket = &hostBucket{tokens: capacity, lastFill: now}
+		f.buckets[host] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.lastFill).Seconds()*capacity/60)
+	bucket.lastFill = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
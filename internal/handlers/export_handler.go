@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/export"
+	"calculator-server/internal/types"
+)
+
+// HistorySource returns the calculation history of the session in ctx
+type HistorySource func(ctx context.Context) ([]types.HistoryEntry, error)
+
+type ExportHandler struct {
+	financeCalc *calculator.FinancialCalculator
+	statsCalc   *calculator.StatisticsCalculator
+	history     HistorySource
+}
+
+// NewExportHandler creates an export handler. history may be nil when
+// calculation history is not being recorded.
+func NewExportHandler(history HistorySource) *ExportHandler {
+	return &ExportHandler{
+		financeCalc: calculator.NewFinancialCalculator(),
+		statsCalc:   calculator.NewStatisticsCalculator(),
+		history:     history,
+	}
+}
+
+// HandleExport renders the requested table as a CSV or JSON document and
+// returns it as an embedded resource content block
+func (eh *ExportHandler) HandleExport(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.ExportRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for export: %v", err)
+	}
+
+	if req.Format == "" {
+		req.Format = export.FormatCSV
+	}
+
+	var table export.Table
+	switch req.Source {
+	case "history":
+		if eh.history == nil {
+			return nil, fmt.Errorf("calculation history is not enabled")
+		}
+		entries, err := eh.history(ctx)
+		if err != nil {
+			return nil, err
+		}
+		table = export.HistoryTable(entries)
+	case "amortization":
+		schedule, err := eh.financeCalc.AmortizationSchedule(types.FinancialRequest{
+			Principal: req.Principal,
+			Rate:      req.Rate,
+			Time:      req.Time,
+			Periods:   req.Periods,
+		})
+		if err != nil {
+			return nil, err
+		}
+		table = export.AmortizationTable(schedule)
+	case "histogram":
+		bins := req.Bins
+		if bins == 0 {
+			bins = 10
+		}
+		histogram, err := eh.statsCalc.Histogram(req.Data, bins)
+		if err != nil {
+			return nil, err
+		}
+		table = export.HistogramTable(histogram)
+	default:
+		return nil, fmt.Errorf("unsupported export source: %s. Supported sources: %v", req.Source, eh.GetSupportedSources())
+	}
+
+	document, err := export.Encode(table, req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.CallToolResult{
+		Content: []types.ContentBlock{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("Exported %d %s rows as %s", len(table.Rows), table.Name, req.Format),
+			},
+			{
+				Type: "resource",
+				Resource: &types.EmbeddedResource{
+					URI:      fmt.Sprintf("export://%s.%s", table.Name, req.Format),
+					MimeType: export.MimeType(req.Format),
+					Text:     string(document),
+				},
+			},
+		},
+	}, nil
+}
+
+func (eh *ExportHandler) GetSupportedSources() []string {
+	return []string{"history", "amortization", "histogram"}
+}
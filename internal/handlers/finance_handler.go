@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 
 	"calculator-server/internal/calculator"
+	"calculator-server/internal/currency"
 	"calculator-server/internal/types"
+	library "calculator-server/pkg/calculator"
+	"calculator-server/pkg/mcp"
 )
 
 type FinanceHandler struct {
-	financeCalc *calculator.FinancialCalculator
+	financeCalc  *calculator.FinancialCalculator
+	rateProvider currency.RateProvider
 }
 
 func NewFinanceHandler() *FinanceHandler {
@@ -18,7 +24,20 @@ func NewFinanceHandler() *FinanceHandler {
 	}
 }
 
+// SetRateProvider enables conversion of financial results into a
+// reportingCurrency. Without a provider only same-currency reports work.
+func (fh *FinanceHandler) SetRateProvider(provider currency.RateProvider) {
+	fh.rateProvider = provider
+}
+
 func (fh *FinanceHandler) HandleFinancialCalculation(params map[string]interface{}) (interface{}, error) {
+	return fh.HandleFinancialCalculationContext(context.Background(), params)
+}
+
+// HandleFinancialCalculationContext performs a financial calculation,
+// converting it into a reporting currency with the rate provider of ctx
+// when it carries one
+func (fh *FinanceHandler) HandleFinancialCalculationContext(ctx context.Context, params map[string]interface{}) (interface{}, error) {
 	// Convert params to FinancialRequest
 	paramsJSON, err := json.Marshal(params)
 	if err != nil {
@@ -44,7 +63,17 @@ func (fh *FinanceHandler) HandleFinancialCalculation(params map[string]interface
 	}
 
 	// Perform calculation
-	result, err := fh.financeCalc.Calculate(req)
+	var result types.FinancialResult
+	if req.Operation == "solve_tvm" {
+		// solve_tvm needs to know which values were omitted
+		var tvm types.TVMRequest
+		if err := json.Unmarshal(paramsJSON, &tvm); err != nil {
+			return nil, fmt.Errorf("invalid parameters for solve_tvm: %v", err)
+		}
+		result, err = fh.financeCalc.SolveTVM(tvm)
+	} else {
+		result, err = fh.financeCalc.Calculate(req)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +86,112 @@ func (fh *FinanceHandler) HandleFinancialCalculation(params map[string]interface
 		"description":          result.Description,
 		"supported_operations": supportedOps,
 	}
+	if req.Explain {
+		response["steps"] = result.Steps
+	}
+	if req.Currency != "" || req.ReportingCurrency != "" {
+		if err := fh.addCurrency(ctx, response, req, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// addCurrency tags a monetary result with its currency, a formatted amount
+// and, when a reporting currency is requested, the converted equivalent.
+// Rates, times and ROI percentages are not amounts and are only tagged.
+func (fh *FinanceHandler) addCurrency(ctx context.Context, response map[string]interface{}, req types.FinancialRequest, result types.FinancialResult) error {
+	if req.Currency == "" {
+		return fmt.Errorf("reportingCurrency requires the currency of the amounts")
+	}
+	code, err := currency.Normalize(req.Currency)
+	if err != nil {
+		return err
+	}
+	response["currency"] = code
+
+	monetary := req.Operation != "roi"
+	if solvedFor, ok := result.Breakdown["solved_for"]; ok {
+		monetary = solvedFor != "rate" && solvedFor != "time"
+	}
+	if !monetary {
+		if req.ReportingCurrency != "" {
+			return fmt.Errorf("reportingCurrency applies only to monetary results, not to the %s result", req.Operation)
+		}
+		return nil
+	}
+	response["formatted"] = currency.Format(result.Result, code)
+
+	if req.ReportingCurrency == "" {
+		return nil
+	}
+	reporting, err := currency.Normalize(req.ReportingCurrency)
+	if err != nil {
+		return err
+	}
+	rate := 1.0
+	if reporting != code {
+		provider := currency.RateProviderFromContext(ctx)
+		if provider == nil {
+			provider = fh.rateProvider
+		}
+		if provider == nil {
+			return fmt.Errorf("currency conversion is not configured on this server")
+		}
+		if rate, err = provider.Rate(ctx, code, reporting); err != nil {
+			return err
+		}
+	}
+	amount := result.Result * rate
+	response["converted"] = map[string]interface{}{
+		"currency":  reporting,
+		"amount":    amount,
+		"rate":      rate,
+		"formatted": currency.Format(amount, reporting),
+	}
+	return nil
+}
+
+// HandleAllocate splits an amount across shares so the rounded parts sum
+// exactly to the total
+func (fh *FinanceHandler) HandleAllocate(params map[string]interface{}) (interface{}, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.AllocationRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for allocate: %v", err)
+	}
+
+	return fh.financeCalc.Allocate(req)
+}
+
+// HandleRuleOfThumb evaluates a rule-of-thumb personal-finance estimate
+func (fh *FinanceHandler) HandleRuleOfThumb(params map[string]interface{}) (interface{}, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.RuleOfThumbRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for rule of thumb: %v", err)
+	}
+
+	result, err := fh.financeCalc.RuleOfThumb(req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"operation":   req.Operation,
+		"result":      result.Result,
+		"breakdown":   result.Breakdown,
+		"description": result.Description,
+	}
 
 	return response, nil
 }
@@ -120,7 +255,7 @@ func (fh *FinanceHandler) HandleNPV(params map[string]interface{}) (interface{},
 	}
 
 	// Calculate NPV
-	npv, err := fh.financeCalc.NetPresentValue(cashFlows, discountRate)
+	npv, err := library.NPV(cashFlows, discountRate)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +266,7 @@ func (fh *FinanceHandler) HandleNPV(params map[string]interface{}) (interface{},
 		"discountRate":   discountRate,
 		"periods":        len(cashFlows),
 		"description":    "Net Present Value calculation",
-		"interpretation": fh.interpretNPV(npv),
+		"interpretation": library.InterpretNPV(npv),
 	}
 
 	return response, nil
@@ -151,7 +286,7 @@ func (fh *FinanceHandler) HandleIRR(params map[string]interface{}) (interface{},
 	}
 
 	// Calculate IRR
-	irr, err := fh.financeCalc.InternalRateOfReturn(cashFlows)
+	irr, err := library.IRR(cashFlows)
 	if err != nil {
 		return nil, err
 	}
@@ -161,75 +296,90 @@ func (fh *FinanceHandler) HandleIRR(params map[string]interface{}) (interface{},
 		"cashFlows":      cashFlows,
 		"periods":        len(cashFlows),
 		"description":    "Internal Rate of Return calculation",
-		"interpretation": fh.interpretIRR(irr),
+		"interpretation": library.InterpretIRR(irr),
 	}
 
 	return response, nil
 }
 
-// Batch operations and comparisons
+// amortizationChunkSize is the number of schedule rows per streamed chunk
+const amortizationChunkSize = 12
 
-func (fh *FinanceHandler) HandleLoanComparison(params map[string]interface{}) (interface{}, error) {
-	// Extract loan scenarios
-	loansInterface, exists := params["loans"]
-	if !exists {
-		return nil, fmt.Errorf("loans parameter is required (array of loan objects)")
+// HandleAmortizationSchedule returns the payment-by-payment schedule of a loan.
+// When the request is streamed, rows are sent as partial results, with
+// progress reported per chunk, and the final result only carries the totals.
+func (fh *FinanceHandler) HandleAmortizationSchedule(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
 	}
 
-	loans, ok := loansInterface.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("loans must be an array")
+	var req types.FinancialRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for amortization schedule: %v", err)
 	}
 
-	results := make([]map[string]interface{}, len(loans))
+	schedule, err := fh.financeCalc.AmortizationSchedule(req)
+	if err != nil {
+		return nil, err
+	}
 
-	for i, loanInterface := range loans {
-		loanMap, ok := loanInterface.(map[string]interface{})
-		if !ok {
-			return nil, fmt.Errorf("loan at index %d must be an object", i)
-		}
+	totalPaid, totalInterest := 0.0, 0.0
+	for _, row := range schedule {
+		totalPaid += row.Payment
+		totalInterest += row.Interest
+	}
 
-		// Add operation type
-		loanMap["operation"] = "loan_payment"
+	response := types.ScheduleResult{
+		Description:   "Loan amortization schedule",
+		Payments:      len(schedule),
+		Payment:       schedule[0].Payment,
+		TotalPaid:     math.Round(totalPaid*100) / 100,
+		TotalInterest: math.Round(totalInterest*100) / 100,
+	}
 
-		// Calculate loan payment
-		result, err := fh.HandleFinancialCalculation(loanMap)
-		if err != nil {
-			results[i] = map[string]interface{}{
-				"error":      err.Error(),
-				"loan_index": i,
-			}
-		} else {
-			resultMap := result.(map[string]interface{})
-			results[i] = map[string]interface{}{
-				"loan_index": i,
-				"result":     resultMap["result"],
-				"breakdown":  resultMap["breakdown"],
-			}
+	if !mcp.StreamsPartialResults(ctx) {
+		response.Schedule = schedule
+		return response, nil
+	}
+
+	for start := 0; start < len(schedule); start += amortizationChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		end := start + amortizationChunkSize
+		if end > len(schedule) {
+			end = len(schedule)
+		}
+		mcp.EmitPartialResult(ctx, map[string]interface{}{"schedule": schedule[start:end]})
+		mcp.ReportProgress(ctx, float64(end), float64(len(schedule)), "")
 	}
+	response.Streamed = true
 
-	// Find best loan (lowest monthly payment)
-	bestLoanIndex := -1
-	lowestPayment := float64(999999999)
+	return response, nil
+}
 
-	for i, result := range results {
-		if payment, ok := result["result"].(float64); ok {
-			if payment < lowestPayment {
-				lowestPayment = payment
-				bestLoanIndex = i
-			}
-		}
+// Batch operations and comparisons
+
+func (fh *FinanceHandler) HandleLoanComparison(params map[string]interface{}) (interface{}, error) {
+	// Extract loan scenarios
+	loansInterface, exists := params["loans"]
+	if !exists {
+		return nil, fmt.Errorf("loans parameter is required (array of loan objects)")
 	}
 
-	response := map[string]interface{}{
-		"loan_comparisons": results,
-		"best_loan_index":  bestLoanIndex,
-		"lowest_payment":   lowestPayment,
-		"description":      "Loan comparison analysis",
+	requests, err := fh.convertToRequests(loansInterface, "loans", "loan")
+	if err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	loans := make([]library.Loan, len(requests))
+	for i, req := range requests {
+		loans[i] = library.Loan{Principal: req.Principal, Rate: req.Rate, Years: req.Time, PaymentsPerYear: req.Periods}
+	}
+	comparison := library.CompareLoans(loans)
+
+	return comparisonTable("Loan comparison analysis", comparison), nil
 }
 
 func (fh *FinanceHandler) HandleInvestmentScenarios(params map[string]interface{}) (interface{}, error) {
@@ -239,62 +389,51 @@ func (fh *FinanceHandler) HandleInvestmentScenarios(params map[string]interface{
 		return nil, fmt.Errorf("scenarios parameter is required (array of investment objects)")
 	}
 
-	scenarios, ok := scenariosInterface.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("scenarios must be an array")
+	requests, err := fh.convertToRequests(scenariosInterface, "scenarios", "scenario")
+	if err != nil {
+		return nil, err
 	}
 
-	results := make([]map[string]interface{}, len(scenarios))
-
-	for i, scenarioInterface := range scenarios {
-		scenarioMap, ok := scenarioInterface.(map[string]interface{})
-		if !ok {
-			return nil, fmt.Errorf("scenario at index %d must be an object", i)
-		}
-
-		// Default to compound interest if no operation specified
-		if _, exists := scenarioMap["operation"]; !exists {
-			scenarioMap["operation"] = "compound_interest"
-		}
-
-		// Calculate scenario
-		result, err := fh.HandleFinancialCalculation(scenarioMap)
-		if err != nil {
-			results[i] = map[string]interface{}{
-				"error":          err.Error(),
-				"scenario_index": i,
-			}
-		} else {
-			resultMap := result.(map[string]interface{})
-			results[i] = map[string]interface{}{
-				"scenario_index": i,
-				"final_amount":   resultMap["result"],
-				"breakdown":      resultMap["breakdown"],
-			}
+	investments := make([]library.Investment, len(requests))
+	for i, req := range requests {
+		investments[i] = library.Investment{
+			Operation: req.Operation,
+			FinancialInput: library.FinancialInput{
+				Principal:      req.Principal,
+				Rate:           req.Rate,
+				Years:          req.Time,
+				PeriodsPerYear: req.Periods,
+				FutureValue:    req.FutureValue,
+			},
 		}
 	}
+	comparison := library.CompareInvestments(investments)
 
-	// Find best scenario (highest final amount)
-	bestScenarioIndex := -1
-	highestAmount := float64(-1)
+	return comparisonTable("Investment scenario analysis", comparison), nil
+}
 
-	for i, result := range results {
-		if amount, ok := result["final_amount"].(float64); ok {
-			if amount > highestAmount {
-				highestAmount = amount
-				bestScenarioIndex = i
-			}
+// comparisonTable lists the options of a comparison as table rows
+func comparisonTable(description string, comparison library.Comparison) types.TableResult {
+	table := types.TableResult{
+		Description: description,
+		Rows:        make([]types.TableRow, len(comparison.Scenarios)),
+		BestIndex:   comparison.Best,
+	}
+	for i, scenario := range comparison.Scenarios {
+		table.Rows[i].Index = i
+		if scenario.Err != nil {
+			table.Rows[i].Error = scenario.Err.Error()
+			continue
 		}
+		value := scenario.Result.Value
+		table.Rows[i].Value = &value
+		table.Rows[i].Breakdown = scenario.Result.Breakdown
 	}
-
-	response := map[string]interface{}{
-		"investment_scenarios": results,
-		"best_scenario_index":  bestScenarioIndex,
-		"highest_return":       highestAmount,
-		"description":          "Investment scenario analysis",
+	if comparison.Best >= 0 {
+		best := comparison.BestValue
+		table.BestValue = &best
 	}
-
-	return response, nil
+	return table
 }
 
 // Helper methods
@@ -318,26 +457,30 @@ func (fh *FinanceHandler) convertToFloatSlice(data interface{}) ([]float64, erro
 	}
 }
 
-func (fh *FinanceHandler) interpretNPV(npv float64) string {
-	if npv > 0 {
-		return "Positive NPV indicates the investment is profitable"
-	} else if npv < 0 {
-		return "Negative NPV indicates the investment is not profitable"
-	} else {
-		return "Zero NPV indicates the investment breaks even"
+// convertToRequests decodes an array of scenario objects into financial requests
+func (fh *FinanceHandler) convertToRequests(data interface{}, field, item string) ([]types.FinancialRequest, error) {
+	items, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array", field)
 	}
-}
 
-func (fh *FinanceHandler) interpretIRR(irr float64) string {
-	if irr > 15 {
-		return "High IRR indicates excellent investment return"
-	} else if irr > 10 {
-		return "Good IRR indicates strong investment return"
-	} else if irr > 5 {
-		return "Moderate IRR indicates acceptable investment return"
-	} else {
-		return "Low IRR indicates poor investment return"
+	requests := make([]types.FinancialRequest, len(items))
+	for i, itemInterface := range items {
+		itemMap, ok := itemInterface.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s at index %d must be an object", item, i)
+		}
+
+		itemJSON, err := json.Marshal(itemMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s at index %d: %v", item, i, err)
+		}
+		if err := json.Unmarshal(itemJSON, &requests[i]); err != nil {
+			return nil, fmt.Errorf("invalid %s at index %d: %v", item, i, err)
+		}
 	}
+
+	return requests, nil
 }
 
 func (fh *FinanceHandler) GetSupportedOperations() []string {
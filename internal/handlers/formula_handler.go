@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+// formulaNamePattern keeps formula names usable in formula:// URIs
+var formulaNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,63}$`)
+
+// FormulaBook saves and looks up named formulas for the caller in ctx
+type FormulaBook interface {
+	SaveFormula(ctx context.Context, formula types.Formula) error
+	Formula(ctx context.Context, name string) (types.Formula, bool, error)
+}
+
+type FormulaHandler struct {
+	exprCalc *calculator.ExpressionCalculator
+	book     FormulaBook
+}
+
+func NewFormulaHandler(book FormulaBook) *FormulaHandler {
+	return &FormulaHandler{
+		exprCalc: calculator.NewExpressionCalculator(),
+		book:     book,
+	}
+}
+
+// HandleSaveFormula validates and saves a named formula
+func (fh *FormulaHandler) HandleSaveFormula(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var formula types.Formula
+	if err := json.Unmarshal(paramsJSON, &formula); err != nil {
+		return nil, fmt.Errorf("invalid parameters for save_formula: %v", err)
+	}
+
+	if !formulaNamePattern.MatchString(formula.Name) {
+		return nil, fmt.Errorf("invalid formula name %q: use up to 64 letters, digits, '_' or '-', starting with a letter", formula.Name)
+	}
+	if err := fh.exprCalc.ValidateExpression(formula.Expression); err != nil {
+		return nil, err
+	}
+	if formula.Scope == "" {
+		formula.Scope = types.FormulaScopeSession
+	}
+	formula.Expression = strings.TrimSpace(formula.Expression)
+	formula.CreatedAt = time.Now().UTC()
+
+	if err := fh.book.SaveFormula(ctx, formula); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"saved":   formula,
+		"uri":     "formula://" + formula.Name,
+		"message": fmt.Sprintf("Saved %s formula %s; run it with run_formula", formula.Scope, formula.Name),
+	}, nil
+}
+
+// HandleRunFormula evaluates a saved formula. Variables given in the call
+// override the formula's defaults.
+func (fh *FormulaHandler) HandleRunFormula(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.RunFormulaRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for run_formula: %v", err)
+	}
+
+	formula, found, err := fh.book.Formula(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no saved formula named %q", req.Name)
+	}
+
+	variables := make(map[string]float64, len(formula.Variables)+len(req.Variables))
+	for name, value := range formula.Variables {
+		variables[name] = value
+	}
+	for name, value := range req.Variables {
+		variables[name] = value
+	}
+
+	result, err := fh.exprCalc.Evaluate(types.ExpressionRequest{
+		Expression: formula.Expression,
+		Variables:  variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"name":       formula.Name,
+		"expression": formula.Expression,
+		"variables":  variables,
+		"result":     result.Result,
+	}, nil
+}
@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"calculator-server/internal/calculator"
 	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
 )
 
 type MathHandler struct {
@@ -13,6 +15,19 @@ type MathHandler struct {
 	advancedCalc  *calculator.AdvancedCalculator
 	exprCalc      *calculator.ExpressionCalculator
 	unitConverter *calculator.UnitConverter
+	linearSolver  *calculator.LinearSolver
+	inequalities  *calculator.InequalitySolver
+	matrixCalc    *calculator.MatrixCalculator
+	eigenSolver   *calculator.EigenSolver
+	toleranceCalc *calculator.ToleranceCalculator
+	electronics   *calculator.ElectronicsCalculator
+	gradingCalc   *calculator.GradingCalculator
+	timezoneCalc  *calculator.TimezoneCalculator
+	cookingCalc   *calculator.CookingCalculator
+	estimator     *calculator.EstimatorCalculator
+	summationCalc *calculator.SummationCalculator
+	primeCalc     *calculator.PrimeCalculator
+	aspectCalc    *calculator.AspectRatioCalculator
 }
 
 func NewMathHandler() *MathHandler {
@@ -21,6 +36,19 @@ func NewMathHandler() *MathHandler {
 		advancedCalc:  calculator.NewAdvancedCalculator(),
 		exprCalc:      calculator.NewExpressionCalculator(),
 		unitConverter: calculator.NewUnitConverter(),
+		linearSolver:  calculator.NewLinearSolver(),
+		inequalities:  calculator.NewInequalitySolver(),
+		matrixCalc:    calculator.NewMatrixCalculator(),
+		eigenSolver:   calculator.NewEigenSolver(),
+		toleranceCalc: calculator.NewToleranceCalculator(),
+		electronics:   calculator.NewElectronicsCalculator(),
+		gradingCalc:   calculator.NewGradingCalculator(),
+		timezoneCalc:  calculator.NewTimezoneCalculator(),
+		cookingCalc:   calculator.NewCookingCalculator(),
+		estimator:     calculator.NewEstimatorCalculator(),
+		summationCalc: calculator.NewSummationCalculator(),
+		primeCalc:     calculator.NewPrimeCalculator(),
+		aspectCalc:    calculator.NewAspectRatioCalculator(),
 	}
 }
 
@@ -36,11 +64,16 @@ func (mh *MathHandler) HandleBasicMath(params map[string]interface{}) (interface
 		return nil, fmt.Errorf("invalid parameters for basic math: %v", err)
 	}
 
+	return mh.BasicMath(req)
+}
+
+// BasicMath performs a decoded basic math request
+func (mh *MathHandler) BasicMath(req types.BasicMathRequest) (interface{}, error) {
 	// Validate input
-	if err := mh.basicCalc.ValidateOperation(req.Operation); err != nil {
+	if err := mh.basicCalc.Validate(req); err != nil {
 		return nil, err
 	}
-	if err := mh.basicCalc.ValidateOperands(req.Operands); err != nil {
+	if err := calculator.ValidateFormat(req.Format); err != nil {
 		return nil, err
 	}
 
@@ -50,6 +83,10 @@ func (mh *MathHandler) HandleBasicMath(params map[string]interface{}) (interface
 		return nil, err
 	}
 
+	if req.Format == calculator.OutputEngineering {
+		result.Formatted = calculator.FormatEngineering(result.Result, req.Symbol)
+	}
+
 	return result, nil
 }
 
@@ -65,23 +102,18 @@ func (mh *MathHandler) HandleAdvancedMath(params map[string]interface{}) (interf
 		return nil, fmt.Errorf("invalid parameters for advanced math: %v", err)
 	}
 
+	return mh.AdvancedMath(req)
+}
+
+// AdvancedMath performs a decoded advanced math request
+func (mh *MathHandler) AdvancedMath(req types.AdvancedMathRequest) (interface{}, error) {
 	// Validate input
-	if err := mh.advancedCalc.ValidateFunction(req.Function); err != nil {
+	if err := mh.advancedCalc.Validate(req); err != nil {
 		return nil, err
 	}
-	if err := mh.advancedCalc.ValidateValue(req.Value); err != nil {
+	if err := calculator.ValidateFormat(req.Format); err != nil {
 		return nil, err
 	}
-	if err := mh.advancedCalc.ValidateUnit(req.Unit); err != nil {
-		return nil, err
-	}
-
-	// Special validation for pow function
-	if req.Function == "pow" && req.Exponent == 0 {
-		// Note: we allow exponent=0 as a valid mathematical operation (result=1 for non-zero base)
-		// But provide helpful info about the function
-		_ = req.Exponent // Just acknowledge the parameter exists
-	}
 
 	// Perform calculation
 	result, err := mh.advancedCalc.Calculate(req)
@@ -89,12 +121,24 @@ func (mh *MathHandler) HandleAdvancedMath(params map[string]interface{}) (interf
 		return nil, err
 	}
 
+	if req.Format == calculator.OutputEngineering {
+		result.Formatted = calculator.FormatEngineering(result.Result, req.Symbol)
+	}
+
 	return result, nil
 }
 
-func (mh *MathHandler) HandleExpressionEval(params map[string]interface{}) (interface{}, error) {
+func (mh *MathHandler) HandleExpressionEval(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	// Variables are decoded separately, as they may be numbers or arrays
+	fields := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if key != "variables" {
+			fields[key] = value
+		}
+	}
+
 	// Convert params to ExpressionRequest
-	paramsJSON, err := json.Marshal(params)
+	paramsJSON, err := json.Marshal(fields)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
 	}
@@ -103,12 +147,39 @@ func (mh *MathHandler) HandleExpressionEval(params map[string]interface{}) (inte
 	if err := json.Unmarshal(paramsJSON, &req); err != nil {
 		return nil, fmt.Errorf("invalid parameters for expression evaluation: %v", err)
 	}
+	if err := decodeExpressionVariables(params["variables"], &req); err != nil {
+		return nil, err
+	}
 
 	// Validate expression
 	if err := mh.exprCalc.ValidateExpression(req.Expression); err != nil {
 		return nil, err
 	}
 
+	switch req.Operation {
+	case "", "evaluate":
+	case "simplify":
+		if len(req.ArrayVariables) > 0 {
+			return nil, fmt.Errorf("simplify does not take array variables")
+		}
+		return mh.exprCalc.Simplify(calculator.WithProgress(ctx, mcp.ReportProgress), req)
+	default:
+		return nil, fmt.Errorf("unsupported expression operation: %s. Supported operations: [evaluate simplify]", req.Operation)
+	}
+
+	// Array-valued variables evaluate the expression element-wise
+	if len(req.ArrayVariables) > 0 {
+		results, err := mh.exprCalc.EvaluateArray(req)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"results":    results,
+			"count":      len(results),
+			"expression": req.Expression,
+		}, nil
+	}
+
 	// Evaluate expression
 	result, err := mh.exprCalc.Evaluate(req)
 	if err != nil {
@@ -123,14 +194,248 @@ func (mh *MathHandler) HandleExpressionEval(params map[string]interface{}) (inte
 		"supported_functions": mh.exprCalc.GetSupportedFunctions(),
 		"supported_operators": mh.exprCalc.GetSupportedOperators(),
 	}
+	if req.Explain {
+		response["steps"] = result.Steps
+	}
 
 	return response, nil
 }
 
+func (mh *MathHandler) HandleLinearSystem(params map[string]interface{}) (interface{}, error) {
+	// Convert params to LinearSystemRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.LinearSystemRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for linear system: %v", err)
+	}
+
+	return mh.linearSolver.Solve(req)
+}
+
+func (mh *MathHandler) HandleInequality(params map[string]interface{}) (interface{}, error) {
+	// Convert params to InequalityRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.InequalityRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for inequality: %v", err)
+	}
+
+	return mh.inequalities.Solve(req)
+}
+
+func (mh *MathHandler) HandleMatrix(params map[string]interface{}) (interface{}, error) {
+	// Convert params to MatrixRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.MatrixRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for matrix: %v", err)
+	}
+
+	return mh.matrixCalc.Calculate(req)
+}
+
+func (mh *MathHandler) HandleEigen(params map[string]interface{}) (interface{}, error) {
+	// Convert params to EigenRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.EigenRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for eigen: %v", err)
+	}
+
+	return mh.eigenSolver.Solve(req)
+}
+
+func (mh *MathHandler) HandleTolerance(params map[string]interface{}) (interface{}, error) {
+	// Convert params to ToleranceRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.ToleranceRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for tolerance: %v", err)
+	}
+
+	return mh.toleranceCalc.Calculate(req)
+}
+
+func (mh *MathHandler) HandleElectronics(params map[string]interface{}) (interface{}, error) {
+	// Convert params to ElectronicsRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.ElectronicsRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for electronics: %v", err)
+	}
+
+	return mh.electronics.Calculate(req)
+}
+
+func (mh *MathHandler) HandleGrading(params map[string]interface{}) (interface{}, error) {
+	// Convert params to GradingRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.GradingRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for grading: %v", err)
+	}
+
+	return mh.gradingCalc.Calculate(req)
+}
+
+func (mh *MathHandler) HandleTimezone(params map[string]interface{}) (interface{}, error) {
+	// Convert params to TimezoneRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.TimezoneRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for timezone: %v", err)
+	}
+
+	return mh.timezoneCalc.Calculate(req)
+}
+
+func (mh *MathHandler) HandleCooking(params map[string]interface{}) (interface{}, error) {
+	// Convert params to CookingRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.CookingRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for cooking: %v", err)
+	}
+
+	return mh.cookingCalc.Calculate(req)
+}
+
+func (mh *MathHandler) HandleEstimator(params map[string]interface{}) (interface{}, error) {
+	// Convert params to EstimatorRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.EstimatorRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for estimator: %v", err)
+	}
+
+	return mh.estimator.Calculate(req)
+}
+
+func (mh *MathHandler) HandleSummation(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	// Convert params to SummationRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.SummationRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for summation: %v", err)
+	}
+
+	return mh.summationCalc.Calculate(calculator.WithProgress(ctx, mcp.ReportProgress), req)
+}
+
+func (mh *MathHandler) HandlePrimes(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	// Convert params to PrimeRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.PrimeRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for primes: %v", err)
+	}
+
+	return mh.primeCalc.Calculate(ctx, req)
+}
+
+func (mh *MathHandler) HandleAspectRatio(params map[string]interface{}) (interface{}, error) {
+	// Convert params to AspectRatioRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.AspectRatioRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for aspect ratio: %v", err)
+	}
+
+	return mh.aspectCalc.Calculate(req)
+}
+
+// decodeExpressionVariables splits the variables of an expression_eval call
+// into numbers and arrays of numbers
+func decodeExpressionVariables(variables interface{}, req *types.ExpressionRequest) error {
+	if variables == nil {
+		return nil
+	}
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(variablesJSON, &raw); err != nil {
+		return fmt.Errorf("invalid parameters for expression evaluation: variables must be an object")
+	}
+
+	for name, value := range raw {
+		var number float64
+		if err := json.Unmarshal(value, &number); err == nil {
+			if req.Variables == nil {
+				req.Variables = make(map[string]float64)
+			}
+			req.Variables[name] = number
+			continue
+		}
+		var values []float64
+		if err := json.Unmarshal(value, &values); err != nil {
+			return fmt.Errorf("invalid parameters for expression evaluation: variable %s must be a number or an array of numbers", name)
+		}
+		if req.ArrayVariables == nil {
+			req.ArrayVariables = make(map[string][]float64)
+		}
+		req.ArrayVariables[name] = values
+	}
+	return nil
+}
+
 // Additional helper methods
 
 func (mh *MathHandler) GetBasicMathOperations() []string {
-	return []string{"add", "subtract", "multiply", "divide"}
+	return []string{"add", "subtract", "multiply", "divide", "mod", "int_divide", "power", "nth_root"}
 }
 
 func (mh *MathHandler) GetAdvancedMathFunctions() []string {
@@ -198,8 +503,21 @@ func (mh *MathHandler) HandleUnitConversion(params map[string]interface{}) (inte
 		return nil, fmt.Errorf("invalid parameters for unit conversion: %v", err)
 	}
 
+	return mh.UnitConversion(req)
+}
+
+// UnitConversion performs a decoded unit conversion request
+func (mh *MathHandler) UnitConversion(req types.UnitConversionRequest) (interface{}, error) {
+	return mh.UnitConversionContext(context.Background(), req)
+}
+
+// UnitConversionContext performs a decoded unit conversion request with the
+// unit tables of ctx, when it carries its own
+func (mh *MathHandler) UnitConversionContext(ctx context.Context, req types.UnitConversionRequest) (interface{}, error) {
+	unitConverter := mh.unitConverter.ForContext(ctx)
+
 	// Validate category
-	supportedCategories := mh.unitConverter.GetSupportedCategories()
+	supportedCategories := unitConverter.GetSupportedCategories()
 	isCategorySupported := false
 	for _, cat := range supportedCategories {
 		if req.Category == cat {
@@ -212,7 +530,7 @@ func (mh *MathHandler) HandleUnitConversion(params map[string]interface{}) (inte
 	}
 
 	// Validate units for the category
-	supportedUnits, err := mh.unitConverter.GetSupportedUnits(req.Category)
+	supportedUnits, err := unitConverter.GetSupportedUnits(req.Category)
 	if err != nil {
 		return nil, err
 	}
@@ -235,8 +553,12 @@ func (mh *MathHandler) HandleUnitConversion(params map[string]interface{}) (inte
 		return nil, fmt.Errorf("unsupported to unit: %s. Supported units for %s: %v", req.ToUnit, req.Category, supportedUnits)
 	}
 
+	if err := calculator.ValidateFormat(req.Format); err != nil {
+		return nil, err
+	}
+
 	// Perform conversion
-	result, err := mh.unitConverter.Convert(req)
+	result, err := unitConverter.Convert(req)
 	if err != nil {
 		return nil, err
 	}
@@ -252,9 +574,13 @@ func (mh *MathHandler) HandleUnitConversion(params map[string]interface{}) (inte
 		"supported_categories": supportedCategories,
 	}
 
+	if req.Format == calculator.OutputEngineering {
+		response["formatted"] = unitConverter.EngineeringString(result.Result, result.Unit, req.Category)
+	}
+
 	// Add conversion factor if possible
 	if req.Category != "temperature" { // Temperature conversions are not linear
-		factor, err := mh.unitConverter.GetConversionFactor(req.FromUnit, req.ToUnit, req.Category)
+		factor, err := unitConverter.GetConversionFactor(req.FromUnit, req.ToUnit, req.Category)
 		if err == nil {
 			response["conversion_factor"] = factor
 		}
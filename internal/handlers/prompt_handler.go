@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+// PromptHandler renders the built-in prompt templates that guide a client
+// through common calculator workflows
+type PromptHandler struct{}
+
+func NewPromptHandler() *PromptHandler {
+	return &PromptHandler{}
+}
+
+// HandleExplainCalculation asks the model to evaluate an expression with
+// expression_eval and explain how the result comes about
+func (ph *PromptHandler) HandleExplainCalculation(_ context.Context, arguments map[string]string) (types.GetPromptResult, error) {
+	expression := strings.TrimSpace(arguments["expression"])
+	text := fmt.Sprintf("Evaluate the expression `%s` with the expression_eval tool", expression)
+	if variables := strings.TrimSpace(arguments["variables"]); variables != "" {
+		text += fmt.Sprintf(" using the variables %s", variables)
+	}
+	text += ". Then explain step by step how the result is obtained, following the order of operations, and point out any functions or constants involved."
+
+	return promptResult("Explain a calculation step by step", text), nil
+}
+
+// HandleLoanAmortization asks the model to build an amortization_schedule
+// call for a loan and summarize the schedule
+func (ph *PromptHandler) HandleLoanAmortization(_ context.Context, arguments map[string]string) (types.GetPromptResult, error) {
+	call := map[string]interface{}{}
+	for _, name := range []string{"principal", "rate", "years"} {
+		value, err := parsePromptNumber(name, arguments[name])
+		if err != nil {
+			return types.GetPromptResult{}, err
+		}
+		if value < 0 {
+			return types.GetPromptResult{}, fmt.Errorf("%s cannot be negative", name)
+		}
+		if name == "years" {
+			name = "time"
+		}
+		call[name] = value
+	}
+	if periods := strings.TrimSpace(arguments["periods"]); periods != "" {
+		value, err := strconv.Atoi(periods)
+		if err != nil || value < 1 {
+			return types.GetPromptResult{}, fmt.Errorf("periods must be a positive whole number of payments per year, got %q", periods)
+		}
+		call["periods"] = value
+	}
+
+	callJSON, _ := json.Marshal(call)
+	text := fmt.Sprintf("Call the amortization_schedule tool with the arguments %s. "+
+		"Summarize the periodic payment, the total interest paid and how the split between principal and interest changes over the term, "+
+		"and mention when half of the principal has been repaid.", callJSON)
+
+	return promptResult("Build and summarize a loan amortization schedule", text), nil
+}
+
+// HandleSummarizeData asks the model to describe a data set using
+// stats_summary
+func (ph *PromptHandler) HandleSummarizeData(_ context.Context, arguments map[string]string) (types.GetPromptResult, error) {
+	fields := strings.FieldsFunc(arguments["data"], func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\t'
+	})
+	data := make([]float64, 0, len(fields))
+	for i, field := range fields {
+		value, err := parsePromptNumber(fmt.Sprintf("data value %d", i), field)
+		if err != nil {
+			return types.GetPromptResult{}, err
+		}
+		data = append(data, value)
+	}
+	if len(data) == 0 {
+		return types.GetPromptResult{}, fmt.Errorf("data must contain at least one number")
+	}
+
+	dataJSON, _ := json.Marshal(map[string]interface{}{"data": data})
+	text := fmt.Sprintf("Call the stats_summary tool with the arguments %s", dataJSON)
+	if subject := strings.TrimSpace(arguments["context"]); subject != "" {
+		text += fmt.Sprintf(". The numbers are %s", subject)
+	}
+	text += ". Describe the center, spread and shape of the data in plain language and call out any outliers."
+
+	return promptResult("Summarize a data set", text), nil
+}
+
+// promptResult wraps a single user message as a rendered prompt
+func promptResult(description, text string) types.GetPromptResult {
+	return types.GetPromptResult{
+		Description: description,
+		Messages: []types.PromptMessage{
+			{Role: "user", Content: types.ContentBlock{Type: "text", Text: text}},
+		},
+	}
+}
+
+// parsePromptNumber parses a finite numeric prompt argument
+func parsePromptNumber(name, value string) (float64, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("%s must be a number, got %q", name, value)
+	}
+	return number, nil
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/currency"
+)
+
+// ResourceHandler renders the server's reference data and session history
+// as JSON resource documents
+type ResourceHandler struct {
+	unitConverter  *calculator.UnitConverter
+	expressionCalc *calculator.ExpressionCalculator
+	history        HistorySource
+	rates          *currency.RateTable
+}
+
+// NewResourceHandler creates a resource handler. history may be nil when
+// calculation history is not being recorded.
+func NewResourceHandler(history HistorySource) *ResourceHandler {
+	return &ResourceHandler{
+		unitConverter:  calculator.NewUnitConverter(),
+		expressionCalc: calculator.NewExpressionCalculator(),
+		history:        history,
+	}
+}
+
+// ReadHistory returns the calculation history of the session in ctx
+func (rh *ResourceHandler) ReadHistory(ctx context.Context) (string, error) {
+	if rh.history == nil {
+		return "", fmt.Errorf("calculation history is not enabled")
+	}
+	entries, err := rh.history(ctx)
+	if err != nil {
+		return "", err
+	}
+	return marshalResource(entries)
+}
+
+// SetRateTable publishes the exchange rates of table through ReadCurrencyRates
+func (rh *ResourceHandler) SetRateTable(table *currency.RateTable) {
+	rh.rates = table
+}
+
+// ReadCurrencyRates returns the latest exchange rates of the rate table
+func (rh *ResourceHandler) ReadCurrencyRates(_ context.Context) (string, error) {
+	if rh.rates == nil {
+		return "", fmt.Errorf("currency rates are not configured")
+	}
+	return marshalResource(rh.rates.Snapshot())
+}
+
+// ReadUnitTables returns the units supported by unit_conversion, by
+// category, including those of the reader's own unit tables
+func (rh *ResourceHandler) ReadUnitTables(ctx context.Context) (string, error) {
+	unitConverter := rh.unitConverter.ForContext(ctx)
+	tables := make(map[string][]string)
+	for _, category := range unitConverter.GetSupportedCategories() {
+		units, err := unitConverter.GetSupportedUnits(category)
+		if err != nil {
+			return "", err
+		}
+		tables[category] = units
+	}
+	return marshalResource(tables)
+}
+
+// ReadConstants returns the named constants available in expression_eval
+func (rh *ResourceHandler) ReadConstants(_ context.Context) (string, error) {
+	return marshalResource(rh.expressionCalc.GetConstants())
+}
+
+func marshalResource(value interface{}) (string, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resource: %v", err)
+	}
+	return string(data), nil
+}
@@ -1,28 +1,61 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
 
 	"calculator-server/internal/calculator"
 	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
 )
 
 type StatsHandler struct {
 	statsCalc     *calculator.StatisticsCalculator
 	unitConverter *calculator.UnitConverter
+	forecaster    *calculator.Forecaster
+	probability   *calculator.ProbabilityCalculator
+	maxDataPoints int
 }
 
 func NewStatsHandler() *StatsHandler {
 	return &StatsHandler{
 		statsCalc:     calculator.NewStatisticsCalculator(),
 		unitConverter: calculator.NewUnitConverter(),
+		forecaster:    calculator.NewForecaster(),
+		probability:   calculator.NewProbabilityCalculator(),
 	}
 }
 
+// SetParallelism sets the number of goroutines that compute statistics of
+// datasets of at least threshold points; see StatisticsCalculator.SetParallelism
+func (sh *StatsHandler) SetParallelism(workers, threshold int) {
+	sh.statsCalc.SetParallelism(workers, threshold)
+}
+
+// SetMaxDataPoints caps the points of one dataset or series; 0 is unlimited
+func (sh *StatsHandler) SetMaxDataPoints(n int) {
+	sh.maxDataPoints = n
+}
+
 func (sh *StatsHandler) HandleStatistics(params map[string]interface{}) (interface{}, error) {
+	// Missing data points become NaN, which JSON cannot carry, so the data
+	// is converted separately
+	data, err := sh.convertToDataWithMissing(params["data"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid data format: %v", err)
+	}
+	rest := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if key != "data" {
+			rest[key] = value
+		}
+	}
+
 	// Convert params to StatisticsRequest
-	paramsJSON, err := json.Marshal(params)
+	paramsJSON, err := json.Marshal(rest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
 	}
@@ -31,11 +64,15 @@ func (sh *StatsHandler) HandleStatistics(params map[string]interface{}) (interfa
 	if err := json.Unmarshal(paramsJSON, &req); err != nil {
 		return nil, fmt.Errorf("invalid parameters for statistics: %v", err)
 	}
+	req.Data = data
 
 	// Validate input
 	if len(req.Data) == 0 {
 		return nil, fmt.Errorf("data cannot be empty")
 	}
+	if err := sh.checkDataPoints("data", len(req.Data)); err != nil {
+		return nil, err
+	}
 
 	// Check if operation is supported
 	supportedOps := sh.statsCalc.GetSupportedOperations()
@@ -50,7 +87,11 @@ func (sh *StatsHandler) HandleStatistics(params map[string]interface{}) (interfa
 		return nil, fmt.Errorf("unsupported operation: %s. Supported operations: %v", req.Operation, supportedOps)
 	}
 
-	// Perform calculation
+	// Clean the data, then perform the calculation
+	cleaning, err := sh.statsCalc.Clean(&req)
+	if err != nil {
+		return nil, err
+	}
 	result, err := sh.statsCalc.Calculate(req)
 	if err != nil {
 		return nil, err
@@ -64,6 +105,18 @@ func (sh *StatsHandler) HandleStatistics(params map[string]interface{}) (interfa
 		"data_preview":         sh.getDataPreview(req.Data),
 		"supported_operations": supportedOps,
 	}
+	if req.Weights != nil {
+		response["weighted"] = true
+	}
+	if req.Operation == "std_dev" || req.Operation == "variance" {
+		response["type"] = "sample"
+		if req.Type != "" {
+			response["type"] = req.Type
+		}
+	}
+	if cleaning != nil {
+		response["cleaning"] = cleaning
+	}
 
 	return response, nil
 }
@@ -86,9 +139,17 @@ func (sh *StatsHandler) HandleStatsSummary(params map[string]interface{}) (inter
 	if len(data) == 0 {
 		return nil, fmt.Errorf("data cannot be empty")
 	}
+	if err := sh.checkDataPoints("data", len(data)); err != nil {
+		return nil, err
+	}
+
+	weights, err := sh.optionalWeights(params)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get comprehensive summary
-	summary, err := sh.statsCalc.Summary(data)
+	summary, err := sh.statsCalc.WeightedSummary(data, weights)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +181,9 @@ func (sh *StatsHandler) HandlePercentileCalculation(params map[string]interface{
 	if err != nil {
 		return nil, fmt.Errorf("invalid data format: %v", err)
 	}
+	if err := sh.checkDataPoints("data", len(data)); err != nil {
+		return nil, err
+	}
 
 	// Convert percentile
 	percentile, ok := percentileInterface.(float64)
@@ -127,8 +191,20 @@ func (sh *StatsHandler) HandlePercentileCalculation(params map[string]interface{
 		return nil, fmt.Errorf("percentile must be a number")
 	}
 
+	weights, err := sh.optionalWeights(params)
+	if err != nil {
+		return nil, err
+	}
+
+	method := "exact"
+	if methodInterface, exists := params["method"]; exists {
+		if method, ok = methodInterface.(string); !ok {
+			return nil, fmt.Errorf("method must be a string")
+		}
+	}
+
 	// Calculate percentile
-	result, err := sh.statsCalc.CalculatePercentile(data, percentile)
+	result, err := sh.statsCalc.PercentileByMethod(data, weights, percentile, method)
 	if err != nil {
 		return nil, err
 	}
@@ -136,16 +212,61 @@ func (sh *StatsHandler) HandlePercentileCalculation(params map[string]interface{
 	response := map[string]interface{}{
 		"percentile":   percentile,
 		"value":        result,
+		"method":       method,
+		"exact":        method == "exact",
 		"data_count":   len(data),
 		"data_preview": sh.getDataPreview(data),
 	}
+	if weights != nil {
+		response["weighted"] = true
+	}
 
 	return response, nil
 }
 
+func (sh *StatsHandler) HandleForecast(params map[string]interface{}) (interface{}, error) {
+	// Convert params to ForecastRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.ForecastRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for forecast: %v", err)
+	}
+	if err := sh.checkDataPoints("series", len(req.Series)); err != nil {
+		return nil, err
+	}
+
+	return sh.forecaster.Forecast(req)
+}
+
+func (sh *StatsHandler) HandleProbability(params map[string]interface{}) (interface{}, error) {
+	// Convert params to ProbabilityRequest
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+	}
+
+	var req types.ProbabilityRequest
+	if err := json.Unmarshal(paramsJSON, &req); err != nil {
+		return nil, fmt.Errorf("invalid parameters for probability: %v", err)
+	}
+
+	return sh.probability.Calculate(req)
+}
+
 // Batch operations
 
 func (sh *StatsHandler) HandleMultipleConversions(params map[string]interface{}) (interface{}, error) {
+	return sh.HandleMultipleConversionsContext(context.Background(), params)
+}
+
+// HandleMultipleConversionsContext converts a series of values with the
+// unit tables of ctx, when it carries its own, reporting progress on long
+// series
+func (sh *StatsHandler) HandleMultipleConversionsContext(ctx context.Context, params map[string]interface{}) (interface{}, error) {
 	// Extract parameters
 	valuesInterface, exists := params["values"]
 	if !exists {
@@ -174,18 +295,19 @@ func (sh *StatsHandler) HandleMultipleConversions(params map[string]interface{})
 	}
 
 	// Perform conversions
-	results, err := sh.unitConverter.ConvertMultiple(values, fromUnit.(string), toUnit.(string), category.(string))
+	ctx = calculator.WithProgress(ctx, mcp.ReportProgress)
+	results, err := sh.unitConverter.ForContext(ctx).ConvertMultipleContext(ctx, values, fromUnit.(string), toUnit.(string), category.(string))
 	if err != nil {
 		return nil, err
 	}
 
-	response := map[string]interface{}{
-		"original_values":  values,
-		"converted_values": results,
-		"fromUnit":         fromUnit,
-		"toUnit":           toUnit,
-		"category":         category,
-		"count":            len(values),
+	response := types.SeriesResult{
+		OriginalValues:  values,
+		ConvertedValues: results,
+		FromUnit:        fromUnit.(string),
+		ToUnit:          toUnit.(string),
+		Category:        category.(string),
+		Count:           len(values),
 	}
 
 	return response, nil
@@ -193,6 +315,15 @@ func (sh *StatsHandler) HandleMultipleConversions(params map[string]interface{})
 
 // Helper methods
 
+// checkDataPoints rejects a dataset or series longer than the configured
+// maximum
+func (sh *StatsHandler) checkDataPoints(name string, count int) error {
+	if sh.maxDataPoints > 0 && count > sh.maxDataPoints {
+		return fmt.Errorf("%s has %d points (maximum %d)", name, count, sh.maxDataPoints)
+	}
+	return nil
+}
+
 func (sh *StatsHandler) convertToFloatSlice(data interface{}) ([]float64, error) {
 	switch v := data.(type) {
 	case []interface{}:
@@ -212,6 +343,50 @@ func (sh *StatsHandler) convertToFloatSlice(data interface{}) ([]float64, error)
 	}
 }
 
+// convertToDataWithMissing converts a data array in which null and "NaN"
+// entries are missing points, returned as NaN
+func (sh *StatsHandler) convertToDataWithMissing(data interface{}) ([]float64, error) {
+	if data == nil {
+		return nil, nil
+	}
+	items, ok := data.([]interface{})
+	if !ok {
+		return sh.convertToFloatSlice(data)
+	}
+
+	result := make([]float64, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case float64:
+			result[i] = v
+		case nil:
+			result[i] = math.NaN()
+		case string:
+			if !strings.EqualFold(strings.TrimSpace(v), "nan") {
+				return nil, fmt.Errorf("item at index %d is not a number", i)
+			}
+			result[i] = math.NaN()
+		default:
+			return nil, fmt.Errorf("item at index %d is not a number", i)
+		}
+	}
+	return result, nil
+}
+
+// optionalWeights returns the weights parameter, or nil when it is absent
+func (sh *StatsHandler) optionalWeights(params map[string]interface{}) ([]float64, error) {
+	weightsInterface, exists := params["weights"]
+	if !exists || weightsInterface == nil {
+		return nil, nil
+	}
+
+	weights, err := sh.convertToFloatSlice(weightsInterface)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weights format: %v", err)
+	}
+	return weights, nil
+}
+
 func (sh *StatsHandler) getDataPreview(data []float64) map[string]interface{} {
 	preview := make(map[string]interface{})
 
@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore persists entries to a JSON snapshot file plus a log beside it,
+// "<path>.log", of the writes made since. Each write appends one line to the
+// log; once the log holds more records than the store holds entries, the
+// snapshot is rewritten atomically (temp file + rename) and the log emptied.
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	buckets map[string]map[string]entry
+	log     *os.File
+	logged  int // Records in the log since the snapshot was written
+	compact int // How many records the log may hold before compaction
+	closed  bool
+}
+
+// fileLogMinRecords is how many records the log may always hold, so small
+// stores are not rewritten on nearly every write
+const fileLogMinRecords = 1000
+
+// logRecord is a line of the log: a write of entry, or a delete when entry is nil
+type logRecord struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Entry  *entry `json:"entry,omitempty"`
+}
+
+// OpenFileStore opens the store at path, loading existing entries if the file exists
+func OpenFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{
+		path:    path,
+		buckets: make(map[string]map[string]entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read store file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &fs.buckets); err != nil {
+			return nil, fmt.Errorf("failed to parse store file: %w", err)
+		}
+	}
+	if err := fs.replay(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	// Drop anything that expired while the server was down, and start
+	// from a fresh snapshot
+	collectExpired(fs.buckets, time.Now())
+	if err := fs.flush(); err != nil {
+		return nil, err
+	}
+	fs.log, err = os.OpenFile(fs.logPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store log: %w", err)
+	}
+	return fs, nil
+}
+
+func (f *FileStore) logPath() string {
+	return f.path + ".log"
+}
+
+// replay applies the log left by the previous run to the loaded snapshot.
+// A last line without a newline was cut short by a crash and is ignored.
+func (f *FileStore) replay() error {
+	data, err := os.ReadFile(f.logPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read store log: %w", err)
+	}
+
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			return nil
+		}
+		var record logRecord
+		if err := json.Unmarshal(data[:end], &record); err != nil {
+			return fmt.Errorf("failed to parse store log: %w", err)
+		}
+		data = data[end+1:]
+
+		if record.Entry == nil {
+			delete(f.buckets[record.Bucket], record.Key)
+			continue
+		}
+		b, exists := f.buckets[record.Bucket]
+		if !exists {
+			b = make(map[string]entry)
+			f.buckets[record.Bucket] = b
+		}
+		b[record.Key] = *record.Entry
+	}
+	return nil
+}
+
+func (f *FileStore) Put(bucket, key string, value []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrStoreClosed
+	}
+
+	b, exists := f.buckets[bucket]
+	if !exists {
+		b = make(map[string]entry)
+		f.buckets[bucket] = b
+	}
+	e := newEntry(value, ttl)
+	b[key] = e
+	return f.append(bucket, key, &e)
+}
+
+func (f *FileStore) Get(bucket, key string) ([]byte, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, false, ErrStoreClosed
+	}
+
+	e, exists := f.buckets[bucket][key]
+	if !exists || e.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return e.Value, true, nil
+}
+
+func (f *FileStore) Increment(bucket, key string, delta int64, ttl time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, ErrStoreClosed
+	}
+
+	b, exists := f.buckets[bucket]
+	if !exists {
+		b = make(map[string]entry)
+		f.buckets[bucket] = b
+	}
+	e, exists := b[key]
+	e, value, err := increment(e, exists, delta, ttl)
+	if err != nil {
+		return 0, err
+	}
+	b[key] = e
+	return value, f.append(bucket, key, &e)
+}
+
+func (f *FileStore) Delete(bucket, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrStoreClosed
+	}
+
+	if _, exists := f.buckets[bucket][key]; !exists {
+		return nil
+	}
+	delete(f.buckets[bucket], key)
+	return f.append(bucket, key, nil)
+}
+
+func (f *FileStore) List(bucket string) (map[string][]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, ErrStoreClosed
+	}
+
+	now := time.Now()
+	result := make(map[string][]byte)
+	for key, e := range f.buckets[bucket] {
+		if !e.expired(now) {
+			result[key] = e.Value
+		}
+	}
+	return result, nil
+}
+
+func (f *FileStore) CollectGarbage() (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, ErrStoreClosed
+	}
+
+	removed := collectExpired(f.buckets, time.Now())
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, f.flushAndTruncate()
+}
+
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	err := f.flushAndTruncate()
+	if closeErr := f.log.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// append logs a write, compacting the log once it has grown past the
+// store's size; callers must hold the lock
+func (f *FileStore) append(bucket, key string, e *entry) error {
+	line, err := json.Marshal(logRecord{Bucket: bucket, Key: key, Entry: e})
+	if err != nil {
+		return fmt.Errorf("failed to encode store record: %w", err)
+	}
+	if _, err := f.log.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write store log: %w", err)
+	}
+	f.logged++
+	if f.logged < f.compact {
+		return nil
+	}
+	return f.flushAndTruncate()
+}
+
+// flushAndTruncate writes a new snapshot and empties the log it replaces;
+// callers must hold the lock
+func (f *FileStore) flushAndTruncate() error {
+	if err := f.flush(); err != nil {
+		return err
+	}
+	if err := f.log.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate store log: %w", err)
+	}
+	return nil
+}
+
+// flush writes all buckets to the snapshot file, and sets how far the log
+// may grow before the next one; callers must hold the lock. Records logged
+// before the snapshot are in it, so a crash before the log is emptied only
+// replays them again.
+func (f *FileStore) flush() error {
+	data, err := json.Marshal(f.buckets)
+	if err != nil {
+		return fmt.Errorf("failed to encode store: %w", err)
+	}
+
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write store file: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to replace store file: %w", err)
+	}
+
+	entries := 0
+	for _, b := range f.buckets {
+		entries += len(b)
+	}
+	f.logged = 0
+	f.compact = max(entries, fileLogMinRecords)
+	return nil
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps entries in process memory; data is lost on restart
+type MemoryStore struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string]entry
+	closed  bool
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]map[string]entry),
+	}
+}
+
+func (m *MemoryStore) Put(bucket, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrStoreClosed
+	}
+
+	b, exists := m.buckets[bucket]
+	if !exists {
+		b = make(map[string]entry)
+		m.buckets[bucket] = b
+	}
+	b[key] = newEntry(value, ttl)
+	return nil
+}
+
+func (m *MemoryStore) Get(bucket, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, false, ErrStoreClosed
+	}
+
+	e, exists := m.buckets[bucket][key]
+	if !exists || e.expired(time.Now()) {
+		return nil, false, nil
+	}
+	return e.Value, true, nil
+}
+
+func (m *MemoryStore) Increment(bucket, key string, delta int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return 0, ErrStoreClosed
+	}
+
+	b, exists := m.buckets[bucket]
+	if !exists {
+		b = make(map[string]entry)
+		m.buckets[bucket] = b
+	}
+	e, exists := b[key]
+	e, value, err := increment(e, exists, delta, ttl)
+	if err != nil {
+		return 0, err
+	}
+	b[key] = e
+	return value, nil
+}
+
+func (m *MemoryStore) Delete(bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrStoreClosed
+	}
+
+	delete(m.buckets[bucket], key)
+	return nil
+}
+
+func (m *MemoryStore) List(bucket string) (map[string][]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, ErrStoreClosed
+	}
+
+	now := time.Now()
+	result := make(map[string][]byte)
+	for key, e := range m.buckets[bucket] {
+		if !e.expired(now) {
+			result[key] = e.Value
+		}
+	}
+	return result, nil
+}
+
+func (m *MemoryStore) CollectGarbage() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return 0, ErrStoreClosed
+	}
+
+	return collectExpired(m.buckets, time.Now()), nil
+}
+
+func (m *MemoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	return nil
+}
+
+// collectExpired removes expired entries and empty buckets in place
+func collectExpired(buckets map[string]map[string]entry, now time.Time) int {
+	removed := 0
+	for name, b := range buckets {
+		for key, e := range b {
+			if e.expired(now) {
+				delete(b, key)
+				removed++
+			}
+		}
+		if len(b) == 0 {
+			delete(buckets, name)
+		}
+	}
+	return removed
+}
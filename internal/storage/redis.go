@@ -0,0 +1,302 @@
+package storage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisTimeout bounds dialing and each command round trip
+const redisTimeout = 5 * time.Second
+
+// redisIncrementScript adds to a counter and sets the expiry of a new one
+// in a single step, so that no counter is left without one
+const redisIncrementScript = `local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value`
+
+// RedisStore keeps entries in Redis, so several server replicas can share
+// sessions and history. Keys are "<prefix><bucket>:<key>" and expire in
+// Redis itself, so CollectGarbage has nothing to do. It speaks RESP over a
+// single connection, redialled after a failure.
+type RedisStore struct {
+	mu       sync.Mutex
+	address  string
+	password string
+	db       int
+	prefix   string
+	conn     net.Conn
+	reader   *bufio.Reader
+	closed   bool
+}
+
+// OpenRedisStore connects to the Redis server at rawURL, of the form
+// redis://[:password@]host:port[/db]. Keys are prefixed with prefix.
+func OpenRedisStore(rawURL, prefix string) (*RedisStore, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "redis" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid redis URL: %s", rawURL)
+	}
+
+	rs := &RedisStore{address: parsed.Host, prefix: prefix}
+	if !strings.Contains(rs.address, ":") {
+		rs.address += ":6379"
+	}
+	if parsed.User != nil {
+		rs.password, _ = parsed.User.Password()
+	}
+	if db := strings.Trim(parsed.Path, "/"); db != "" {
+		if rs.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database: %s", db)
+		}
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if err := rs.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return rs, nil
+}
+
+func (r *RedisStore) Put(bucket, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", r.key(bucket, key), string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := r.do(args...)
+	return err
+}
+
+func (r *RedisStore) Get(bucket, key string) ([]byte, bool, error) {
+	reply, err := r.do("GET", r.key(bucket, key))
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected redis reply %T", reply)
+	}
+	return value, true, nil
+}
+
+func (r *RedisStore) Increment(bucket, key string, delta int64, ttl time.Duration) (int64, error) {
+	reply, err := r.do("EVAL", redisIncrementScript, "1", r.key(bucket, key), strconv.FormatInt(delta, 10), strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return 0, err
+	}
+	value, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected redis reply %T", reply)
+	}
+	return value, nil
+}
+
+func (r *RedisStore) Delete(bucket, key string) error {
+	_, err := r.do("DEL", r.key(bucket, key))
+	return err
+}
+
+func (r *RedisStore) List(bucket string) (map[string][]byte, error) {
+	pattern := r.key(bucket, "*")
+	result := make(map[string][]byte)
+
+	cursor := "0"
+	for {
+		reply, err := r.do("SCAN", cursor, "MATCH", pattern, "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, fmt.Errorf("unexpected redis SCAN reply")
+		}
+		next, _ := page[0].([]byte)
+		keys, _ := page[1].([]interface{})
+
+		if len(keys) > 0 {
+			args := make([]string, 0, len(keys)+1)
+			args = append(args, "MGET")
+			for _, k := range keys {
+				b, _ := k.([]byte)
+				args = append(args, string(b))
+			}
+			values, err := r.do(args...)
+			if err != nil {
+				return nil, err
+			}
+			list, _ := values.([]interface{})
+			for i, value := range list {
+				// Keys that expired between SCAN and MGET come back nil
+				if b, ok := value.([]byte); ok && i+1 < len(args) {
+					result[strings.TrimPrefix(args[i+1], r.key(bucket, ""))] = b
+				}
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return result, nil
+		}
+	}
+}
+
+// CollectGarbage does nothing, as Redis expires entries itself
+func (r *RedisStore) CollectGarbage() (int, error) {
+	return 0, nil
+}
+
+func (r *RedisStore) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.conn == nil {
+		return nil
+	}
+	return r.conn.Close()
+}
+
+// key returns the Redis key of an entry
+func (r *RedisStore) key(bucket, key string) string {
+	return r.prefix + bucket + ":" + key
+}
+
+// connect dials the server, authenticating and selecting the database.
+// Callers must hold mu.
+func (r *RedisStore) connect() error {
+	conn, err := net.DialTimeout("tcp", r.address, redisTimeout)
+	if err != nil {
+		return err
+	}
+	r.conn, r.reader = conn, bufio.NewReader(conn)
+
+	if r.password != "" {
+		if _, err := r.roundTrip("AUTH", r.password); err != nil {
+			r.drop()
+			return err
+		}
+	}
+	if r.db != 0 {
+		if _, err := r.roundTrip("SELECT", strconv.Itoa(r.db)); err != nil {
+			r.drop()
+			return err
+		}
+	}
+	return nil
+}
+
+// drop closes a connection that failed. Callers must hold mu.
+func (r *RedisStore) drop() {
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	r.conn, r.reader = nil, nil
+}
+
+// do sends a command, redialling once when the connection has failed
+func (r *RedisStore) do(args ...string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, ErrStoreClosed
+	}
+	for attempt := 0; ; attempt++ {
+		if r.conn == nil {
+			if err := r.connect(); err != nil {
+				return nil, fmt.Errorf("failed to connect to redis: %w", err)
+			}
+		}
+		reply, err := r.roundTrip(args...)
+		var redisErr redisError
+		if err == nil || errors.As(err, &redisErr) || attempt > 0 {
+			return reply, err
+		}
+		r.drop()
+	}
+}
+
+// roundTrip writes one command and reads its reply. Callers must hold mu.
+func (r *RedisStore) roundTrip(args ...string) (interface{}, error) {
+	r.conn.SetDeadline(time.Now().Add(redisTimeout))
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(r.conn, command.String()); err != nil {
+		return nil, err
+	}
+	return readRedisReply(r.reader)
+}
+
+// redisError is an error reply sent by the server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readRedisReply reads one RESP reply. Bulk strings come back as []byte,
+// nil bulk strings and arrays as nil, and arrays as []interface{}.
+func readRedisReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil || length < 0 {
+			return nil, err
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data[:length], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readRedisReply(reader); err != nil {
+				var redisErr redisError
+				if !errors.As(err, &redisErr) {
+					return nil, err
+				}
+				items[i] = err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply: %q", line)
+	}
+}
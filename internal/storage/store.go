@@ -0,0 +1,87 @@
+// Package storage provides the optional persistence layer used to keep
+// sessions and calculation history across server restarts
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrStoreClosed is returned when a store is used after Close
+var ErrStoreClosed = errors.New("store is closed")
+
+// Store is a bucketed key-value store with per-entry expiry.
+// A ttl of zero means the entry never expires.
+type Store interface {
+	Put(bucket, key string, value []byte, ttl time.Duration) error
+	Get(bucket, key string) ([]byte, bool, error)
+	Delete(bucket, key string) error
+	// Increment atomically adds delta to the integer counter at key and
+	// returns the new value. A missing counter starts at zero and expires
+	// after ttl; incrementing keeps its expiry. Get reads counters as
+	// decimal text.
+	Increment(bucket, key string, delta int64, ttl time.Duration) (int64, error)
+	// List returns all live entries of a bucket keyed by entry key
+	List(bucket string) (map[string][]byte, error)
+	// CollectGarbage removes expired entries and returns how many were removed
+	CollectGarbage() (int, error)
+	Close() error
+}
+
+// StartGarbageCollector periodically removes expired entries from the store
+// until the returned stop function is called
+func StartGarbageCollector(store Store, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				store.CollectGarbage()
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// entry is a stored value together with its expiry time
+type entry struct {
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+func newEntry(value []byte, ttl time.Duration) entry {
+	e := entry{Value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.ExpiresAt = time.Now().Add(ttl)
+	}
+	return e
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// increment returns the counter entry e, if it exists, plus delta; the
+// caller holds the store's lock
+func increment(e entry, exists bool, delta int64, ttl time.Duration) (entry, int64, error) {
+	var value int64
+	if exists && !e.expired(time.Now()) {
+		var err error
+		if value, err = strconv.ParseInt(string(e.Value), 10, 64); err != nil {
+			return entry{}, 0, fmt.Errorf("value is not an integer")
+		}
+	} else {
+		e = newEntry(nil, ttl)
+	}
+	value += delta
+	e.Value = []byte(strconv.FormatInt(value, 10))
+	return e, value, nil
+}
@@ -0,0 +1,216 @@
+package types
+
+import "strconv"
+
+// maxFastOperands is the most operands DecodeFast accepts; longer lists
+// are rare and take the generic decoder
+const maxFastOperands = 4
+
+// basicMathFields are the fields of BasicMathRequest as DecodeFast sees them
+const (
+	fieldOperation = 1 << iota
+	fieldOperands
+	fieldPrecision
+	fieldFormat
+	fieldSymbol
+	fieldExplain
+)
+
+// DecodeFast decodes the arguments of a typical basic_math call, a flat
+// object with up to four operands, without reflection. It reports false
+// for anything else, such as escaped strings, null values, unknown or
+// repeated keys and numbers out of range, which the generic decoder then
+// handles. Whatever it accepts decodes exactly as json.Unmarshal would.
+func (r *BasicMathRequest) DecodeFast(data []byte) bool {
+	d := fastDecoder{data: data}
+	if !d.consume('{') {
+		return false
+	}
+	var seen int
+	if d.consume('}') {
+		return d.end()
+	}
+	for {
+		key, ok := d.rawString()
+		if !ok || !d.consume(':') {
+			return false
+		}
+		var field int
+		switch string(key) {
+		case "operation":
+			field = fieldOperation
+			r.Operation, ok = d.string()
+		case "operands":
+			field = fieldOperands
+			r.Operands, ok = d.numbers()
+		case "precision":
+			field = fieldPrecision
+			r.Precision, ok = d.integer()
+		case "format":
+			field = fieldFormat
+			r.Format, ok = d.string()
+		case "symbol":
+			field = fieldSymbol
+			r.Symbol, ok = d.string()
+		case "explain":
+			field = fieldExplain
+			r.Explain, ok = d.boolean()
+		}
+		if field == 0 || !ok || seen&field != 0 {
+			return false
+		}
+		seen |= field
+		if d.consume('}') {
+			return d.end()
+		}
+		if !d.consume(',') {
+			return false
+		}
+	}
+}
+
+// fastDecoder scans the small subset of JSON that DecodeFast accepts
+type fastDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *fastDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// consume skips the next token if it is the given delimiter
+func (d *fastDecoder) consume(delimiter byte) bool {
+	d.skipSpace()
+	if d.pos < len(d.data) && d.data[d.pos] == delimiter {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// end reports whether nothing but space follows
+func (d *fastDecoder) end() bool {
+	d.skipSpace()
+	return d.pos == len(d.data)
+}
+
+// rawString reads a string of printable ASCII without escapes
+func (d *fastDecoder) rawString() ([]byte, bool) {
+	if !d.consume('"') {
+		return nil, false
+	}
+	start := d.pos
+	for ; d.pos < len(d.data); d.pos++ {
+		switch c := d.data[d.pos]; {
+		case c == '"':
+			d.pos++
+			return d.data[start : d.pos-1], true
+		case c == '\\' || c < 0x20 || c >= 0x80:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func (d *fastDecoder) string() (string, bool) {
+	value, ok := d.rawString()
+	return string(value), ok
+}
+
+// number reads a JSON number token
+func (d *fastDecoder) number() ([]byte, bool) {
+	d.skipSpace()
+	start := d.pos
+	digits := func() bool {
+		from := d.pos
+		for d.pos < len(d.data) && d.data[d.pos] >= '0' && d.data[d.pos] <= '9' {
+			d.pos++
+		}
+		return d.pos > from
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '-' {
+		d.pos++
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '0' {
+		d.pos++
+	} else if !digits() {
+		return nil, false
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		d.pos++
+		if !digits() {
+			return nil, false
+		}
+	}
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if !digits() {
+			return nil, false
+		}
+	}
+	return d.data[start:d.pos], true
+}
+
+// numbers reads an array of up to maxFastOperands numbers
+func (d *fastDecoder) numbers() ([]float64, bool) {
+	if !d.consume('[') {
+		return nil, false
+	}
+	values := make([]float64, 0, maxFastOperands)
+	if d.consume(']') {
+		return values, true
+	}
+	for len(values) < maxFastOperands {
+		token, ok := d.number()
+		if !ok {
+			return nil, false
+		}
+		value, err := strconv.ParseFloat(string(token), 64)
+		if err != nil {
+			return nil, false
+		}
+		values = append(values, value)
+		if d.consume(']') {
+			return values, true
+		}
+		if !d.consume(',') {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// integer reads a number written without a fraction or exponent
+func (d *fastDecoder) integer() (int, bool) {
+	token, ok := d.number()
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.Atoi(string(token))
+	return value, err == nil
+}
+
+func (d *fastDecoder) boolean() (bool, bool) {
+	d.skipSpace()
+	rest := d.data[d.pos:]
+	switch {
+	case len(rest) >= 4 && string(rest[:4]) == "true":
+		d.pos += 4
+		return true, true
+	case len(rest) >= 5 && string(rest[:5]) == "false":
+		d.pos += 5
+		return false, true
+	}
+	return false, false
+}
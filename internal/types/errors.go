@@ -0,0 +1,42 @@
+package types
+
+import "fmt"
+
+// Calculation error codes returned to clients so they can present
+// targeted guidance for common mathematical domain failures
+const (
+	ErrCodeDivisionByZero    = "DIVISION_BY_ZERO"
+	ErrCodeLogDomain         = "LOG_NON_POSITIVE"
+	ErrCodeSqrtDomain        = "SQRT_NEGATIVE"
+	ErrCodeInverseTrigDomain = "INVERSE_TRIG_OUT_OF_RANGE"
+	ErrCodeOperandCount      = "INVALID_OPERAND_COUNT"
+	ErrCodeRootDomain        = "ROOT_OF_NEGATIVE"
+)
+
+// CalculationError describes a domain failure of a calculation together
+// with the offending value and, when known, the argument it came from
+type CalculationError struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Value   float64 `json:"value"`
+	Field   string  `json:"field,omitempty"`
+}
+
+// NewCalculationError creates a calculation error with a formatted message
+func NewCalculationError(code string, value float64, format string, args ...interface{}) *CalculationError {
+	return &CalculationError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Value:   value,
+	}
+}
+
+// WithField records the name of the argument holding the offending value
+func (e *CalculationError) WithField(field string) *CalculationError {
+	e.Field = field
+	return e
+}
+
+func (e *CalculationError) Error() string {
+	return e.Message
+}
@@ -13,6 +13,13 @@ type MCPRequest struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// IsNotification reports whether the request is a notification, which has
+// no ID and must not be answered. MCP does not allow null IDs, so a null ID
+// counts as none.
+func (r MCPRequest) IsNotification() bool {
+	return r.ID == nil
+}
+
 type MCPResponse struct {
 	JSONRPC string      `json:"jsonrpc"`
 	ID      interface{} `json:"id"`
@@ -26,29 +33,241 @@ type MCPError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// MCPNotification is a server-initiated JSON-RPC message without an id
+type MCPNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // Tool Types
 type Tool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+	Meta         *ToolMeta              `json:"_meta,omitempty"`
+}
+
+// ToolMeta carries the extra information listed with a tool
+type ToolMeta struct {
+	Examples []ToolExample `json:"examples,omitempty"`
+}
+
+// ToolExample is an example invocation of a tool, given to clients so their
+// models can learn from it how to call the tool
+type ToolExample struct {
+	Description string                 `json:"description,omitempty"`
+	Arguments   map[string]interface{} `json:"arguments"`
+	Result      interface{}            `json:"result"` // The fields of the result that matter
 }
 
 type ListToolsResult struct {
 	Tools []Tool `json:"tools"`
+	// Marshaled is the JSON encoding of the result when it was computed
+	// ahead of time; it is written out as is
+	Marshaled json.RawMessage `json:"-"`
+}
+
+// MarshalJSON reuses the precomputed encoding when there is one
+func (r ListToolsResult) MarshalJSON() ([]byte, error) {
+	if len(r.Marshaled) > 0 {
+		return r.Marshaled, nil
+	}
+	type plain ListToolsResult
+	return json.Marshal(plain(r))
 }
 
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
+	// RawArguments holds the arguments as sent when they have not been
+	// decoded into Arguments yet
+	RawArguments json.RawMessage `json:"-"`
+}
+
+// RequestMeta holds the protocol-level metadata of a request
+type RequestMeta struct {
+	ProgressToken interface{} `json:"progressToken,omitempty"`
+}
+
+// PartialResultParams are the params of a notifications/partial_result message
+type PartialResultParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Index         int         `json:"index"`
+	Data          interface{} `json:"data"`
+}
+
+// CancelledParams are the params of a notifications/cancelled message, or
+// of an LSP-style $/cancelRequest, which names the request as ID
+type CancelledParams struct {
+	RequestID interface{} `json:"requestId,omitempty"`
+	ID        interface{} `json:"id,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+}
+
+// ProgressParams are the params of a notifications/progress message. Total
+// is omitted when the amount of work is not known in advance.
+type ProgressParams struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         *float64    `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
 }
 
 type CallToolResult struct {
 	Content []ContentBlock `json:"content"`
+	// StructuredContent is the result as a JSON object, set for tools that
+	// declare an output schema
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	// IsError marks a tool that ran but failed, e.g. on a division by zero;
+	// Content then describes the failure
+	IsError bool        `json:"isError,omitempty"`
+	Meta    *ResultMeta `json:"_meta,omitempty"`
+}
+
+// ResultMeta is metadata attached to a tool result
+type ResultMeta struct {
+	RequestID  string      `json:"requestId,omitempty"`
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance records how a result was calculated, so that it can be
+// reproduced and audited
+type Provenance struct {
+	Tool      string `json:"tool"`
+	Version   string `json:"version"`             // Version of the tool's implementation
+	Algorithm string `json:"algorithm,omitempty"` // Method used, e.g. "empirical quantile (no interpolation)"
+	Precision string `json:"precision"`           // Arithmetic and rounding, e.g. "float64" or "2 decimal places"
+	Seed      *int64 `json:"seed,omitempty"`      // Seed of randomized calculations
 }
 
 type ContentBlock struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Resource *EmbeddedResource `json:"resource,omitempty"`
+}
+
+// EmbeddedResource is the payload of a "resource" content block
+type EmbeddedResource struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// Resource Types
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+type ReadResourceResult struct {
+	Contents []EmbeddedResource `json:"contents"`
+}
+
+// Prompt Types
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+type ListPromptsResult struct {
+	Prompts []Prompt `json:"prompts"`
+}
+
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptMessage is one message of a rendered prompt
+type PromptMessage struct {
+	Role    string       `json:"role"`
+	Content ContentBlock `json:"content"`
+}
+
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// SetLevelParams are the params of logging/setLevel
+type SetLevelParams struct {
+	Level string `json:"level"`
+}
+
+// LoggingMessageParams are the params of a notifications/message log event
+type LoggingMessageParams struct {
+	Level  string      `json:"level"`
+	Logger string      `json:"logger,omitempty"`
+	Data   interface{} `json:"data"`
+}
+
+// CompleteParams are the params of completion/complete. Ref names a prompt
+// ("ref/prompt"), a resource ("ref/resource") or, as an extension of this
+// server, a tool ("ref/tool").
+type CompleteParams struct {
+	Ref      CompletionReference `json:"ref"`
+	Argument CompletionArgument  `json:"argument"`
+	Context  *CompletionContext  `json:"context,omitempty"`
+}
+
+// CompletionReference is what the completed argument belongs to
+type CompletionReference struct {
 	Type string `json:"type"`
-	Text string `json:"text"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument being completed and its partial value
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompletionContext holds the values of arguments already filled in
+type CompletionContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+// Completion lists up to 100 values; Total counts every match
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total"`
+	HasMore bool     `json:"hasMore"`
 }
 
 // Calculator Request Types
@@ -56,6 +275,9 @@ type BasicMathRequest struct {
 	Operation string    `json:"operation"`
 	Operands  []float64 `json:"operands"`
 	Precision int       `json:"precision,omitempty"`
+	Format    string    `json:"format,omitempty"`  // "plain" (default) or "engineering"
+	Symbol    string    `json:"symbol,omitempty"`  // Unit symbol for engineering output, e.g. "F"
+	Explain   bool      `json:"explain,omitempty"` // Include the computation steps in the result
 }
 
 type AdvancedMathRequest struct {
@@ -63,16 +285,302 @@ type AdvancedMathRequest struct {
 	Value    float64 `json:"value"`
 	Exponent float64 `json:"exponent,omitempty"`
 	Unit     string  `json:"unit,omitempty"`
+	Format   string  `json:"format,omitempty"`
+	Symbol   string  `json:"symbol,omitempty"`
 }
 
 type ExpressionRequest struct {
 	Expression string             `json:"expression"`
 	Variables  map[string]float64 `json:"variables,omitempty"`
+	Explain    bool               `json:"explain,omitempty"`
+	Operation  string             `json:"operation,omitempty"` // "evaluate" (default) or "simplify"
+
+	// ArrayVariables are evaluated element-wise by EvaluateArray. The
+	// expression_eval handler fills them from array-valued variables.
+	ArrayVariables map[string][]float64 `json:"-"`
+}
+
+// SummationRequest is a finite sum or product of an expression over an
+// integer index running from From to To inclusive
+type SummationRequest struct {
+	Operation  string             `json:"operation"` // "sum" or "product"
+	Expression string             `json:"expression"`
+	Index      string             `json:"index,omitempty"` // Defaults to "k"
+	From       int64              `json:"from"`
+	To         int64              `json:"to"`
+	Variables  map[string]float64 `json:"variables,omitempty"`
+	ClosedForm *bool              `json:"closed_form,omitempty"` // Defaults to true
+}
+
+// PrimeRequest is a question about primes. N is used by nth and count, From
+// and To by range, and Value by next and previous.
+type PrimeRequest struct {
+	Operation string `json:"operation"` // "nth", "range", "count", "next" or "previous"
+	N         int64  `json:"n,omitempty"`
+	From      int64  `json:"from,omitempty"`
+	To        int64  `json:"to,omitempty"`
+	Value     int64  `json:"value,omitempty"`
+	Limit     int    `json:"limit,omitempty"` // Most primes listed by range, default 1000
+}
+
+type LinearSystemRequest struct {
+	Equations []string `json:"equations"`
+}
+
+type InequalityRequest struct {
+	Inequality string `json:"inequality"`
+}
+
+// MatrixRequest applies a matrix operation to a row-major matrix. Vector is
+// the right-hand side b of least_squares.
+type MatrixRequest struct {
+	Operation string      `json:"operation"`
+	Matrix    [][]float64 `json:"matrix"`
+	Vector    []float64   `json:"vector,omitempty"`
+}
+
+// EigenRequest asks for the eigenvalues and eigenvectors of a square matrix.
+// Method is "auto", "jacobi" or "qr"; zero MaxIterations and Tolerance
+// select the defaults.
+type EigenRequest struct {
+	Matrix        [][]float64 `json:"matrix"`
+	Method        string      `json:"method,omitempty"`
+	MaxIterations int         `json:"max_iterations,omitempty"`
+	Tolerance     float64     `json:"tolerance,omitempty"`
 }
 
+// ToleranceDimension is a nominal size with signed upper and lower
+// deviations, e.g. 25 +0.021/0 or 25 −0.007/−0.020. Tolerance is shorthand
+// for a symmetric ±tolerance band. Subtract marks a dimension that reduces
+// the total of a stack-up, such as a part inside a housing.
+type ToleranceDimension struct {
+	Nominal   float64 `json:"nominal"`
+	Upper     float64 `json:"upper,omitempty"`
+	Lower     float64 `json:"lower,omitempty"`
+	Tolerance float64 `json:"tolerance,omitempty"`
+	Subtract  bool    `json:"subtract,omitempty"`
+}
+
+// ToleranceRequest is an engineering tolerance calculation. stack_up uses
+// Dimensions; percent_error uses Measured and Expected; within_tolerance
+// uses Measured and Dimension; fit uses Hole and Shaft.
+type ToleranceRequest struct {
+	Operation  string               `json:"operation"`
+	Dimensions []ToleranceDimension `json:"dimensions,omitempty"`
+	Dimension  *ToleranceDimension  `json:"dimension,omitempty"`
+	Measured   *float64             `json:"measured,omitempty"`
+	Expected   *float64             `json:"expected,omitempty"`
+	Hole       *ToleranceDimension  `json:"hole,omitempty"`
+	Shaft      *ToleranceDimension  `json:"shaft,omitempty"`
+}
+
+// ElectronicsRequest is a circuit calculation in SI units (volts, amperes,
+// ohms, watts, farads, henries, seconds). ohms_law takes exactly two of
+// Voltage, Current, Resistance and Power; series and parallel combine
+// Values of one Component type; voltage_divider uses InputVoltage, R1, R2
+// and an optional Load across R2; rc_time_constant uses Resistance,
+// Capacitance and an optional Time.
+type ElectronicsRequest struct {
+	Operation    string    `json:"operation"`
+	Voltage      *float64  `json:"voltage,omitempty"`
+	Current      *float64  `json:"current,omitempty"`
+	Resistance   *float64  `json:"resistance,omitempty"`
+	Power        *float64  `json:"power,omitempty"`
+	Component    string    `json:"component,omitempty"`
+	Values       []float64 `json:"values,omitempty"`
+	InputVoltage *float64  `json:"input_voltage,omitempty"`
+	R1           *float64  `json:"r1,omitempty"`
+	R2           *float64  `json:"r2,omitempty"`
+	Load         *float64  `json:"load,omitempty"`
+	Capacitance  *float64  `json:"capacitance,omitempty"`
+	Time         *float64  `json:"time,omitempty"`
+}
+
+// Formula scopes
+const (
+	FormulaScopeSession    = "session"
+	FormulaScopePersistent = "persistent"
+)
+
+// Formula is a saved, named expression with default variable values
+type Formula struct {
+	Name        string             `json:"name"`
+	Expression  string             `json:"expression"`
+	Variables   map[string]float64 `json:"variables,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Scope       string             `json:"scope"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type RunFormulaRequest struct {
+	Name      string             `json:"name"`
+	Variables map[string]float64 `json:"variables,omitempty"`
+}
+
+// StatisticsRequest applies an operation to data. Weights, when given, are
+// non-negative frequency weights, one per data point. X holds the predictor
+// values of a regression, for which Data holds the responses. Missing data
+// points are NaN; Missing, Deduplicate, ClipMin and ClipMax clean the data
+// before the statistic is computed.
 type StatisticsRequest struct {
-	Data      []float64 `json:"data"`
-	Operation string    `json:"operation"`
+	Data        []float64 `json:"data"`
+	Operation   string    `json:"operation"`
+	Weights     []float64 `json:"weights,omitempty"`
+	X           []float64 `json:"x,omitempty"`
+	Type        string    `json:"type,omitempty"`    // "sample" (default, n − 1) or "population" (n), for std_dev and variance
+	Missing     string    `json:"missing,omitempty"` // "error" (default), "drop", "mean", "median" or "value"
+	FillValue   *float64  `json:"fill_value,omitempty"`
+	Deduplicate bool      `json:"deduplicate,omitempty"`
+	ClipMin     *float64  `json:"clip_min,omitempty"`
+	ClipMax     *float64  `json:"clip_max,omitempty"`
+}
+
+// ForecastRequest projects a series Horizon steps ahead. SeasonLength turns
+// naive into seasonal naive and adds seasonality to Holt–Winters. Nil
+// smoothing parameters are fitted to the series.
+type ForecastRequest struct {
+	Series       []float64 `json:"series"`
+	Method       string    `json:"method"`
+	Horizon      int       `json:"horizon"`
+	Window       int       `json:"window,omitempty"`
+	SeasonLength int       `json:"season_length,omitempty"`
+	Alpha        *float64  `json:"alpha,omitempty"`
+	Beta         *float64  `json:"beta,omitempty"`
+	Gamma        *float64  `json:"gamma,omitempty"`
+	Confidence   float64   `json:"confidence,omitempty"`
+}
+
+// ProbabilityRequest is a common probability scenario. Event
+// probabilities are between 0 and 1. independent_events and conditional use
+// ProbabilityA and ProbabilityB (and ProbabilityBGivenA for conditional);
+// binomial uses Trials, Successes and Probability; birthday uses People and
+// Days; expected_value uses Outcomes and their Probabilities.
+type ProbabilityRequest struct {
+	Operation          string    `json:"operation"`
+	ProbabilityA       *float64  `json:"probability_a,omitempty"`
+	ProbabilityB       *float64  `json:"probability_b,omitempty"`
+	ProbabilityBGivenA *float64  `json:"probability_b_given_a,omitempty"`
+	Trials             int       `json:"trials,omitempty"`
+	Successes          int       `json:"successes,omitempty"`
+	Probability        *float64  `json:"probability,omitempty"`
+	People             int       `json:"people,omitempty"`
+	Days               int       `json:"days,omitempty"`
+	Outcomes           []float64 `json:"outcomes,omitempty"`
+	Probabilities      []float64 `json:"probabilities,omitempty"`
+}
+
+// CookingIngredient is a recipe line; Unit is free text for counted items
+// such as eggs
+type CookingIngredient struct {
+	Name     string  `json:"name,omitempty"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit,omitempty"`
+}
+
+// CookingRequest scales recipes and converts kitchen quantities. scale uses
+// Ingredients with Factor or Servings and TargetServings; convert uses
+// Quantity, FromUnit, ToUnit and, between volume and weight, Ingredient;
+// oven_temperature uses Temperature in FromUnit (C, F or gas_mark).
+type CookingRequest struct {
+	Operation      string              `json:"operation"`
+	Ingredients    []CookingIngredient `json:"ingredients,omitempty"`
+	Servings       float64             `json:"servings,omitempty"`
+	TargetServings float64             `json:"target_servings,omitempty"`
+	Factor         *float64            `json:"factor,omitempty"`
+	Quantity       *float64            `json:"quantity,omitempty"`
+	FromUnit       string              `json:"from_unit,omitempty"`
+	ToUnit         string              `json:"to_unit,omitempty"`
+	Ingredient     string              `json:"ingredient,omitempty"`
+	Temperature    *float64            `json:"temperature,omitempty"`
+}
+
+// EstimatorRequest estimates building material quantities. Lengths are in
+// Unit and areas in Unit squared, except the tile size, which is in
+// TileUnit. paint uses Area or Length and Width (with Height, the four walls
+// of a room), Coats and Coverage; concrete uses Area or Length and Width,
+// and Thickness; tiles uses Area or Length and Width, and the tile size;
+// wallpaper uses Length (with Width, a room's perimeter), Height and the
+// roll size.
+type EstimatorRequest struct {
+	Operation     string   `json:"operation"`
+	Unit          string   `json:"unit,omitempty"`
+	Length        *float64 `json:"length,omitempty"`
+	Width         *float64 `json:"width,omitempty"`
+	Height        *float64 `json:"height,omitempty"`
+	Area          *float64 `json:"area,omitempty"`
+	Openings      float64  `json:"openings,omitempty"`
+	Thickness     *float64 `json:"thickness,omitempty"`
+	Coats         int      `json:"coats,omitempty"`
+	Coverage      *float64 `json:"coverage,omitempty"`
+	WastePercent  *float64 `json:"waste_percent,omitempty"`
+	TileLength    *float64 `json:"tile_length,omitempty"`
+	TileWidth     *float64 `json:"tile_width,omitempty"`
+	TileUnit      string   `json:"tile_unit,omitempty"`
+	TilesPerBox   int      `json:"tiles_per_box,omitempty"`
+	RollWidth     *float64 `json:"roll_width,omitempty"`
+	RollLength    *float64 `json:"roll_length,omitempty"`
+	PatternRepeat float64  `json:"pattern_repeat,omitempty"`
+}
+
+// AspectRatioRequest describes a resolution in pixels. ppi also uses the
+// Diagonal, and scale one of TargetWidth and TargetHeight.
+type AspectRatioRequest struct {
+	Operation    string   `json:"operation"` // "reduce", "ppi" or "scale"
+	Width        int64    `json:"width"`
+	Height       int64    `json:"height"`
+	Diagonal     *float64 `json:"diagonal,omitempty"`
+	DiagonalUnit string   `json:"diagonal_unit,omitempty"` // "in" (default) or "cm"
+	TargetWidth  int64    `json:"target_width,omitempty"`
+	TargetHeight int64    `json:"target_height,omitempty"`
+	MultipleOf   int64    `json:"multiple_of,omitempty"` // Round scaled sides to a multiple, default 1
+}
+
+// TimezoneRequest converts times between zones and durations between units.
+// convert uses Timestamp, FromZone and ToZone; duration uses Start and End,
+// read in FromZone and ToZone; convert_duration uses Duration or Value and
+// FromUnit, and ToUnit. Timestamps without an offset are local to their zone.
+type TimezoneRequest struct {
+	Operation string   `json:"operation"`
+	Timestamp string   `json:"timestamp,omitempty"`
+	Start     string   `json:"start,omitempty"`
+	End       string   `json:"end,omitempty"`
+	FromZone  string   `json:"from_zone,omitempty"`
+	ToZone    string   `json:"to_zone,omitempty"`
+	Duration  string   `json:"duration,omitempty"`
+	Value     *float64 `json:"value,omitempty"`
+	FromUnit  string   `json:"from_unit,omitempty"`
+	ToUnit    string   `json:"to_unit,omitempty"`
+}
+
+// GradeCategory is a graded part of a course, such as homework or exams.
+// Score is a percentage; alternatively Earned and Possible give the points.
+// Weights are relative and need not sum to 100.
+type GradeCategory struct {
+	Name     string   `json:"name,omitempty"`
+	Score    *float64 `json:"score,omitempty"`
+	Earned   float64  `json:"earned,omitempty"`
+	Possible float64  `json:"possible,omitempty"`
+	Weight   float64  `json:"weight"`
+}
+
+// CourseGrade is a letter grade earned in a course worth Credits, 1 when
+// omitted
+type CourseGrade struct {
+	Grade   string   `json:"grade"`
+	Credits *float64 `json:"credits,omitempty"`
+}
+
+// GradingRequest is a grade calculation. weighted_grade uses Categories;
+// final_needed uses the current grade (given directly or from Categories),
+// FinalWeight and TargetGrade; gpa uses Courses on Scale ("4.0" or "4.3").
+type GradingRequest struct {
+	Operation    string          `json:"operation"`
+	Categories   []GradeCategory `json:"categories,omitempty"`
+	CurrentGrade *float64        `json:"current_grade,omitempty"`
+	FinalWeight  float64         `json:"final_weight,omitempty"`
+	TargetGrade  float64         `json:"target_grade,omitempty"`
+	Courses      []CourseGrade   `json:"courses,omitempty"`
+	Scale        string          `json:"scale,omitempty"`
 }
 
 type UnitConversionRequest struct {
@@ -80,6 +588,7 @@ type UnitConversionRequest struct {
 	FromUnit string  `json:"fromUnit"`
 	ToUnit   string  `json:"toUnit"`
 	Category string  `json:"category"`
+	Format   string  `json:"format,omitempty"`
 }
 
 type FinancialRequest struct {
@@ -89,12 +598,386 @@ type FinancialRequest struct {
 	Time        float64 `json:"time,omitempty"`
 	Periods     int     `json:"periods,omitempty"`
 	FutureValue float64 `json:"futureValue,omitempty"`
+	Explain     bool    `json:"explain,omitempty"`
+
+	// StartDate and EndDate (YYYY-MM-DD) replace Time for interest and loan
+	// operations, counting the days between them under DayCount
+	StartDate string `json:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
+	DayCount  string `json:"dayCount,omitempty"`
+
+	// Currency tags monetary results with an ISO 4217 code; with
+	// ReportingCurrency they are also converted at the current rate
+	Currency          string `json:"currency,omitempty"`
+	ReportingCurrency string `json:"reportingCurrency,omitempty"`
+}
+
+// TVMRequest is a time-value-of-money problem for the solve_tvm operation.
+// Exactly one of Principal, Rate, Time, Payment and FutureValue is omitted
+// and solved for. Amounts follow the cash-flow sign convention: money
+// received is positive and money paid out is negative.
+type TVMRequest struct {
+	Principal   *float64 `json:"principal"`
+	Rate        *float64 `json:"rate"`
+	Time        *float64 `json:"time"`
+	Payment     *float64 `json:"payment"`
+	FutureValue *float64 `json:"futureValue"`
+	Periods     int      `json:"periods,omitempty"`
+	Explain     bool     `json:"explain,omitempty"`
+}
+
+// RuleOfThumbRequest is a quick personal-finance estimate. Income, debt
+// payments and expenses are monthly amounts.
+type RuleOfThumbRequest struct {
+	Operation    string  `json:"operation"`
+	Rate         float64 `json:"rate,omitempty"`
+	Income       float64 `json:"income,omitempty"`
+	DebtPayments float64 `json:"debtPayments,omitempty"`
+	Expenses     float64 `json:"expenses,omitempty"`
+	Savings      float64 `json:"savings,omitempty"`
+	Months       float64 `json:"months,omitempty"`
+}
+
+// AllocationShare is one party's claim on an allocated amount, given as a
+// relative weight or a percentage of the total
+type AllocationShare struct {
+	Name       string   `json:"name,omitempty"`
+	Weight     *float64 `json:"weight,omitempty"`
+	Percentage *float64 `json:"percentage,omitempty"`
+}
+
+// AllocationRequest splits Total across Shares, rounded to Decimals places
+// or to the minor unit of Currency
+type AllocationRequest struct {
+	Total    float64           `json:"total"`
+	Shares   []AllocationShare `json:"shares"`
+	Decimals *int              `json:"decimals,omitempty"`
+	Currency string            `json:"currency,omitempty"`
+}
+
+type ExportRequest struct {
+	Source    string    `json:"source"`
+	Format    string    `json:"format,omitempty"`
+	Principal float64   `json:"principal,omitempty"`
+	Rate      float64   `json:"rate,omitempty"`
+	Time      float64   `json:"time,omitempty"`
+	Periods   int       `json:"periods,omitempty"`
+	Data      []float64 `json:"data,omitempty"`
+	Bins      int       `json:"bins,omitempty"`
 }
 
 // Response Types
 type CalculationResult struct {
-	Result float64 `json:"result"`
-	Unit   string  `json:"unit,omitempty"`
+	Result    float64  `json:"result"`
+	Unit      string   `json:"unit,omitempty"`
+	Semantics string   `json:"semantics,omitempty"`
+	Formatted string   `json:"formatted,omitempty"` // Set when engineering output is requested
+	Steps     []string `json:"steps,omitempty"`     // Set when an explanation is requested
+}
+
+// LinearSystemResult holds the solution of a linear system both as
+// floating-point values and as exact rationals such as "7/5"
+type LinearSystemResult struct {
+	Variables []string           `json:"variables"`
+	Solution  map[string]float64 `json:"solution"`
+	Exact     map[string]string  `json:"exact"`
+}
+
+// SimplifyResult is an expression after algebraic simplification. Canonical
+// is the fully expanded form with a fixed term order, so equal expressions
+// give equal strings; Factored is set when factoring changes it. Value is
+// set when the expression simplifies to a number.
+type SimplifyResult struct {
+	Expression string   `json:"expression"`
+	Simplified string   `json:"simplified"`
+	Canonical  string   `json:"canonical"`
+	Factored   string   `json:"factored,omitempty"`
+	Variables  []string `json:"variables"`
+	Value      *float64 `json:"value,omitempty"`
+}
+
+// SummationResult is the value of a finite sum or product. Method is
+// "direct" when every term was evaluated, or "arithmetic" or "geometric"
+// when a closed form was used; Exact is set for exact closed forms.
+type SummationResult struct {
+	Operation  string  `json:"operation"`
+	Expression string  `json:"expression"`
+	Index      string  `json:"index"`
+	From       int64   `json:"from"`
+	To         int64   `json:"to"`
+	Terms      int64   `json:"terms"`
+	Result     float64 `json:"result"`
+	Method     string  `json:"method"`
+	Exact      string  `json:"exact,omitempty"`
+}
+
+// PrimeResult holds the prime found by nth, next and previous, or the
+// primes listed and counted by range and count. Truncated is set when range
+// found more primes than its limit.
+type PrimeResult struct {
+	Operation string  `json:"operation"`
+	Prime     *int64  `json:"prime,omitempty"`
+	Primes    []int64 `json:"primes,omitempty"`
+	Count     *int64  `json:"count,omitempty"`
+	Truncated bool    `json:"truncated,omitempty"`
+}
+
+// Interval is a connected part of a solution set. A nil bound is unbounded.
+type Interval struct {
+	Lower       *float64 `json:"lower"`
+	Upper       *float64 `json:"upper"`
+	LowerClosed bool     `json:"lower_closed"`
+	UpperClosed bool     `json:"upper_closed"`
+}
+
+// InequalityResult is the solution set of an inequality, in interval
+// notation such as "(-∞, 2) ∪ [3, ∞)" and as a list of intervals
+type InequalityResult struct {
+	Variable  string     `json:"variable"`
+	Notation  string     `json:"notation"`
+	Intervals []Interval `json:"intervals"`
+}
+
+// MatrixResult holds the output of a matrix operation; only the fields
+// produced by the operation are set. Factors maps names such as "L", "U" and
+// "P" to factor matrices. ConditionNumber is the 2-norm condition number and
+// is omitted when the matrix is singular.
+type MatrixResult struct {
+	Operation       string                 `json:"operation"`
+	Rows            int                    `json:"rows"`
+	Columns         int                    `json:"columns"`
+	Determinant     *float64               `json:"determinant,omitempty"`
+	Inverse         [][]float64            `json:"inverse,omitempty"`
+	Factors         map[string][][]float64 `json:"factors,omitempty"`
+	Solution        []float64              `json:"solution,omitempty"`
+	ResidualNorm    *float64               `json:"residual_norm,omitempty"`
+	ConditionNumber *float64               `json:"condition_number,omitempty"`
+	Warnings        []string               `json:"warnings,omitempty"`
+}
+
+// ToleranceRange is the interval between a lower and an upper limit
+type ToleranceRange struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// ToleranceResult holds the outcome of a tolerance calculation; only the
+// fields produced by the operation are set. For a fit, Clearance runs from
+// the minimum to the maximum clearance, negative values being interference.
+type ToleranceResult struct {
+	Operation       string          `json:"operation"`
+	Nominal         *float64        `json:"nominal,omitempty"`
+	WorstCase       *ToleranceRange `json:"worst_case,omitempty"`
+	RSS             *ToleranceRange `json:"rss,omitempty"`
+	AbsoluteError   *float64        `json:"absolute_error,omitempty"`
+	PercentError    *float64        `json:"percent_error,omitempty"`
+	Limits          *ToleranceRange `json:"limits,omitempty"`
+	Deviation       *float64        `json:"deviation,omitempty"`
+	WithinTolerance *bool           `json:"within_tolerance,omitempty"`
+	Margin          *float64        `json:"margin,omitempty"`
+	Fit             string          `json:"fit,omitempty"`
+	Clearance       *ToleranceRange `json:"clearance,omitempty"`
+}
+
+// ElectronicsResult holds the quantities of a circuit calculation in SI
+// units, keyed by name, and the same values with SI prefixes, e.g. "4.7 kΩ"
+// AspectRatioResult describes a resolution: the source for reduce and ppi,
+// the scaled one for scale. Exact reports whether scaling kept the source
+// ratio despite rounding.
+type AspectRatioResult struct {
+	Operation         string   `json:"operation"`
+	Width             int64    `json:"width"`
+	Height            int64    `json:"height"`
+	Ratio             string   `json:"ratio"`
+	RatioWidth        int64    `json:"ratio_width"`
+	RatioHeight       int64    `json:"ratio_height"`
+	Decimal           float64  `json:"decimal"`
+	GCD               int64    `json:"gcd"`
+	Orientation       string   `json:"orientation"`
+	NearestStandard   string   `json:"nearest_standard"`
+	DifferencePercent float64  `json:"difference_percent"`
+	Pixels            int64    `json:"pixels"`
+	Megapixels        float64  `json:"megapixels"`
+	PPI               *float64 `json:"ppi,omitempty"`
+	DotPitchMm        *float64 `json:"dot_pitch_mm,omitempty"`
+	WidthIn           *float64 `json:"width_in,omitempty"`
+	HeightIn          *float64 `json:"height_in,omitempty"`
+	WidthCm           *float64 `json:"width_cm,omitempty"`
+	HeightCm          *float64 `json:"height_cm,omitempty"`
+	SourceRatio       string   `json:"source_ratio,omitempty"`
+	Exact             *bool    `json:"exact,omitempty"`
+}
+
+type ElectronicsResult struct {
+	Operation string             `json:"operation"`
+	Values    map[string]float64 `json:"values"`
+	Formatted map[string]string  `json:"formatted"`
+}
+
+// Eigenpair is an eigenvalue and, when it is real, a unit eigenvector
+type Eigenpair struct {
+	Real      float64   `json:"real"`
+	Imaginary float64   `json:"imaginary"`
+	Vector    []float64 `json:"vector,omitempty"`
+}
+
+// EigenResult reports the eigenvalues of a matrix, largest real part first,
+// and whether the iteration converged within its limit. Residual is the
+// largest ‖A·v − λ·v‖ over the returned eigenvectors.
+type EigenResult struct {
+	Method        string      `json:"method"`
+	Symmetric     bool        `json:"symmetric"`
+	Eigenvalues   []Eigenpair `json:"eigenvalues"`
+	Iterations    int         `json:"iterations"`
+	MaxIterations int         `json:"max_iterations"`
+	Converged     bool        `json:"converged"`
+	Residual      float64     `json:"residual"`
+	Warnings      []string    `json:"warnings,omitempty"`
+}
+
+// ForecastPoint is the forecast for one step ahead with its prediction interval
+type ForecastPoint struct {
+	Step  int     `json:"step"`
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// ForecastResult holds the point forecasts of a series. RMSE is the root
+// mean square of the in-sample one-step-ahead errors the intervals are built
+// from; Parameters lists the window, season length or smoothing parameters used.
+type ForecastResult struct {
+	Method     string             `json:"method"`
+	Horizon    int                `json:"horizon"`
+	Confidence float64            `json:"confidence"`
+	Forecasts  []ForecastPoint    `json:"forecasts"`
+	RMSE       float64            `json:"rmse"`
+	Parameters map[string]float64 `json:"parameters,omitempty"`
+}
+
+// ProbabilityResult holds the headline answer of a probability scenario,
+// such as P(at least k successes) or the expected value, and the related
+// quantities by name
+type ProbabilityResult struct {
+	Operation string             `json:"operation"`
+	Result    float64            `json:"result"`
+	Values    map[string]float64 `json:"values"`
+}
+
+// GradeContribution is one category's share of a weighted grade, with the
+// weight normalized to a percentage of the course
+type GradeContribution struct {
+	Name         string  `json:"name,omitempty"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// GradingResult holds the outcome of a grade calculation; only the fields
+// produced by the operation are set. Grades are percentages.
+type GradingResult struct {
+	Operation     string              `json:"operation"`
+	Grade         *float64            `json:"grade,omitempty"`
+	Letter        string              `json:"letter,omitempty"`
+	Contributions []GradeContribution `json:"contributions,omitempty"`
+	CurrentGrade  *float64            `json:"current_grade,omitempty"`
+	Needed        *float64            `json:"needed,omitempty"`
+	Achievable    *bool               `json:"achievable,omitempty"`
+	GPA           *float64            `json:"gpa,omitempty"`
+	Credits       *float64            `json:"credits,omitempty"`
+	GradePoints   *float64            `json:"grade_points,omitempty"`
+}
+
+// EstimatorResult holds a material estimate; only the fields produced by
+// the operation are set. Areas and volumes are given in metric and US units
+// and include any waste allowance.
+type EstimatorResult struct {
+	Operation     string   `json:"operation"`
+	AreaM2        float64  `json:"area_m2"`
+	AreaFt2       float64  `json:"area_ft2"`
+	WastePercent  *float64 `json:"waste_percent,omitempty"`
+	Litres        *float64 `json:"litres,omitempty"`
+	Gallons       *float64 `json:"gallons,omitempty"`
+	VolumeM3      *float64 `json:"volume_m3,omitempty"`
+	VolumeYd3     *float64 `json:"volume_yd3,omitempty"`
+	VolumeFt3     *float64 `json:"volume_ft3,omitempty"`
+	Tiles         *int     `json:"tiles,omitempty"`
+	Boxes         *int     `json:"boxes,omitempty"`
+	Strips        *int     `json:"strips,omitempty"`
+	StripsPerRoll *int     `json:"strips_per_roll,omitempty"`
+	Rolls         *int     `json:"rolls,omitempty"`
+}
+
+// CookingResult holds the outcome of a cooking calculation; only the fields
+// produced by the operation are set. Density is in grams per millilitre.
+type CookingResult struct {
+	Operation   string              `json:"operation"`
+	Factor      *float64            `json:"factor,omitempty"`
+	Ingredients []CookingIngredient `json:"ingredients,omitempty"`
+	Quantity    *float64            `json:"quantity,omitempty"`
+	Unit        string              `json:"unit,omitempty"`
+	Density     *float64            `json:"density,omitempty"`
+	Celsius     *float64            `json:"celsius,omitempty"`
+	Fahrenheit  *float64            `json:"fahrenheit,omitempty"`
+	GasMark     *float64            `json:"gas_mark,omitempty"`
+}
+
+// ZonedTime is an instant as seen in one time zone
+type ZonedTime struct {
+	Time         string `json:"time"`
+	Zone         string `json:"zone"`
+	Abbreviation string `json:"abbreviation"`
+	UTCOffset    string `json:"utc_offset"`
+	IsDST        bool   `json:"is_dst"`
+}
+
+// TimezoneResult holds the outcome of a time zone or duration conversion;
+// only the fields produced by the operation are set. Ambiguous is set when
+// a local time occurred twice because clocks went back, in which case the
+// earlier instant was used. DSTShiftSeconds is the wall-clock difference
+// minus the elapsed time, e.g. 3600 across a spring-forward transition.
+type TimezoneResult struct {
+	Operation        string             `json:"operation"`
+	From             *ZonedTime         `json:"from,omitempty"`
+	To               *ZonedTime         `json:"to,omitempty"`
+	Ambiguous        bool               `json:"ambiguous,omitempty"`
+	Seconds          *float64           `json:"seconds,omitempty"`
+	WallClockSeconds *float64           `json:"wall_clock_seconds,omitempty"`
+	DSTShiftSeconds  *float64           `json:"dst_shift_seconds,omitempty"`
+	Value            *float64           `json:"value,omitempty"`
+	Unit             string             `json:"unit,omitempty"`
+	Formatted        string             `json:"formatted,omitempty"`
+	Breakdown        map[string]float64 `json:"breakdown,omitempty"`
+}
+
+// AllocationPart is one party's rounded portion of an allocation. Exact is
+// the unrounded proportional amount.
+type AllocationPart struct {
+	Name      string  `json:"name,omitempty"`
+	Share     float64 `json:"share"`
+	Exact     float64 `json:"exact"`
+	Amount    float64 `json:"amount"`
+	Formatted string  `json:"formatted,omitempty"`
+}
+
+// AllocationResult holds the parts of an allocation, which sum exactly to
+// Total. RoundedUp counts the parts given an extra smallest unit.
+type AllocationResult struct {
+	Total     float64          `json:"total"`
+	Currency  string           `json:"currency,omitempty"`
+	Unit      float64          `json:"unit"`
+	Parts     []AllocationPart `json:"parts"`
+	RoundedUp int              `json:"roundedUp"`
+}
+
+// RegressionResult is the least-squares line y = intercept + slope·x,
+// weighted when weights were given
+type RegressionResult struct {
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"r_squared"`
+	Count     int     `json:"count"`
+	Weighted  bool    `json:"weighted"`
 }
 
 type StatisticsResult struct {
@@ -102,18 +985,187 @@ type StatisticsResult struct {
 	Count  int         `json:"count"`
 }
 
+// CleaningReport counts the data points changed by the cleaning options of
+// a statistics request
+type CleaningReport struct {
+	OriginalCount     int      `json:"original_count"`
+	Missing           int      `json:"missing"`
+	Dropped           int      `json:"dropped"`
+	Imputed           int      `json:"imputed"`
+	FillValue         *float64 `json:"fill_value,omitempty"` // The value imputed
+	DuplicatesRemoved int      `json:"duplicates_removed"`
+	Clipped           int      `json:"clipped"`
+}
+
 type FinancialResult struct {
 	Result      float64                `json:"result"`
 	Breakdown   map[string]interface{} `json:"breakdown,omitempty"`
 	Description string                 `json:"description,omitempty"`
+	Steps       []string               `json:"steps,omitempty"`
+}
+
+// ScheduleResult is a payment-by-payment schedule with its totals. Schedule
+// is omitted when the rows were streamed as partial results.
+type ScheduleResult struct {
+	Description   string            `json:"description"`
+	Payments      int               `json:"payments"`
+	Payment       float64           `json:"payment"`
+	TotalPaid     float64           `json:"total_paid"`
+	TotalInterest float64           `json:"total_interest"`
+	Schedule      []AmortizationRow `json:"schedule,omitempty"`
+	Streamed      bool              `json:"streamed,omitempty"`
+}
+
+// SeriesResult is a series of values computed one for one from the input
+// values, in the same order
+type SeriesResult struct {
+	OriginalValues  []float64 `json:"original_values"`
+	ConvertedValues []float64 `json:"converted_values"`
+	FromUnit        string    `json:"from_unit,omitempty"`
+	ToUnit          string    `json:"to_unit,omitempty"`
+	Category        string    `json:"category,omitempty"`
+	Count           int       `json:"count"`
+}
+
+// TableRow is one option of a comparison. Error is set instead of Value
+// when the option could not be calculated.
+type TableRow struct {
+	Index     int                    `json:"index"`
+	Value     *float64               `json:"value,omitempty"`
+	Breakdown map[string]interface{} `json:"breakdown,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// TableResult compares several options row by row. BestIndex is the row
+// with the best value, or -1 when no option could be calculated.
+type TableResult struct {
+	Description string     `json:"description"`
+	Rows        []TableRow `json:"rows"`
+	BestIndex   int        `json:"best_index"`
+	BestValue   *float64   `json:"best_value,omitempty"`
+}
+
+// History Types
+type HistoryEntry struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Result    interface{}            `json:"result"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// Export Types
+type AmortizationRow struct {
+	Period    int     `json:"period"`
+	Payment   float64 `json:"payment"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Balance   float64 `json:"balance"`
+}
+
+type HistogramBin struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Count int     `json:"count"`
+}
+
+// Job Types
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+type Job struct {
+	ID          string                 `json:"id"`
+	TenantID    string                 `json:"tenant_id,omitempty"`
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	Status      string                 `json:"status"`
+	Result      *CallToolResult        `json:"result,omitempty"`
+	Error       *MCPError              `json:"error,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+}
+
+// Metrics Types
+type ToolMetrics struct {
+	Tool         string    `json:"tool"`
+	Invocations  int64     `json:"invocations"`
+	Errors       int64     `json:"errors"`
+	LatencyP50Ms float64   `json:"latency_p50_ms"`
+	LatencyP95Ms float64   `json:"latency_p95_ms"`
+	LatencyP99Ms float64   `json:"latency_p99_ms"`
+	LastInvoked  time.Time `json:"last_invoked"`
+}
+
+// Self-Check Types
+
+// Self-check statuses
+const (
+	SelfCheckPassed  = "pass"
+	SelfCheckFailed  = "fail"
+	SelfCheckSkipped = "skipped"
+)
+
+type SelfCheckResult struct {
+	Tool       string  `json:"tool"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+type SelfCheckReport struct {
+	Passed  bool              `json:"passed"`
+	Counts  map[string]int    `json:"counts"`
+	Results []SelfCheckResult `json:"results"`
 }
 
 // MCP Session Management Types
 type Session struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	LastSeen  time.Time `json:"last_seen"`
-	Active    bool      `json:"active"`
+	ID           string      `json:"id"`
+	TenantID     string      `json:"tenant_id,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+	LastSeen     time.Time   `json:"last_seen"`
+	Active       bool        `json:"active"`
+	Transport    string      `json:"transport,omitempty"`
+	RequestCount int         `json:"request_count"`
+	ToolCalls    int         `json:"tool_calls"`
+	LastTool     string      `json:"last_tool,omitempty"`
+	ClientInfo   *ClientInfo `json:"client_info,omitempty"`
+	Initialized  bool        `json:"initialized"` // The client sent notifications/initialized
+
+	// Negotiated in initialize
+	ProtocolVersion string              `json:"protocol_version,omitempty"`
+	Capabilities    *ClientCapabilities `json:"capabilities,omitempty"`
+}
+
+// ClientInfo identifies the MCP client, as sent in the initialize request
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// InitializeParams are the params of the initialize request
+type InitializeParams struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      *ClientInfo        `json:"clientInfo,omitempty"`
+}
+
+// ClientCapabilities are the optional features a client declares in
+// initialize. A capability is supported when its key is present, even with
+// an empty object as its value.
+type ClientCapabilities struct {
+	Roots        *RootsCapability       `json:"roots,omitempty"`
+	Sampling     map[string]interface{} `json:"sampling,omitempty"`
+	Elicitation  map[string]interface{} `json:"elicitation,omitempty"`
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
+}
+
+// RootsCapability declares that the client can list filesystem roots
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
 }
 
 type SessionError struct {
@@ -0,0 +1,105 @@
+// Package calculator is the embeddable calculation engine of the calculator
+// server. It exposes the math, statistics, unit conversion and finance
+// engines through plain Go functions, independent of the MCP protocol, so
+// other Go programs can use them directly:
+//
+//	sum, err := calculator.Basic("add", []float64{1, 2, 3}, 2)
+//	payment, err := calculator.LoanPayment(calculator.Loan{Principal: 250000, Rate: 6, Years: 30})
+//
+// Domain failures such as division by zero are returned as *CalculationError
+// carrying a machine-readable code.
+package calculator
+
+import (
+	engine "calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+// CalculationError describes a domain failure together with the offending value
+type CalculationError = types.CalculationError
+
+// Calculation error codes
+const (
+	ErrCodeDivisionByZero    = types.ErrCodeDivisionByZero
+	ErrCodeLogDomain         = types.ErrCodeLogDomain
+	ErrCodeSqrtDomain        = types.ErrCodeSqrtDomain
+	ErrCodeInverseTrigDomain = types.ErrCodeInverseTrigDomain
+	ErrCodeOperandCount      = types.ErrCodeOperandCount
+	ErrCodeRootDomain        = types.ErrCodeRootDomain
+)
+
+// Result is the outcome of a scalar calculation
+type Result struct {
+	Value float64
+	// Unit is set for results with a unit, e.g. trigonometric functions
+	Unit string
+	// Semantics explains how operations over more than two operands are applied
+	Semantics string
+}
+
+func fromCalculationResult(result types.CalculationResult) Result {
+	return Result{Value: result.Result, Unit: result.Unit, Semantics: result.Semantics}
+}
+
+// Basic performs add, subtract, multiply, divide, mod, int_divide, power or
+// nth_root on the operands, rounding to precision decimal places
+func Basic(operation string, operands []float64, precision int) (Result, error) {
+	calc := engine.NewBasicCalculator()
+	req := types.BasicMathRequest{
+		Operation: operation,
+		Operands:  operands,
+		Precision: precision,
+	}
+	if err := calc.Validate(req); err != nil {
+		return Result{}, err
+	}
+
+	result, err := calc.Calculate(req)
+	if err != nil {
+		return Result{}, err
+	}
+	return fromCalculationResult(result), nil
+}
+
+// BasicOperations lists the operations accepted by Basic
+func BasicOperations() []string {
+	return []string{"add", "subtract", "multiply", "divide", "mod", "int_divide", "power", "nth_root"}
+}
+
+// AdvancedOptions are the optional inputs of Advanced
+type AdvancedOptions struct {
+	Exponent float64 // Exponent for "pow"
+	Unit     string  // "degrees" or "radians" for trigonometric functions
+}
+
+// Advanced applies a scientific function such as sin, log10, sqrt or factorial
+func Advanced(function string, value float64, opts AdvancedOptions) (Result, error) {
+	calc := engine.NewAdvancedCalculator()
+	req := types.AdvancedMathRequest{
+		Function: function,
+		Value:    value,
+		Exponent: opts.Exponent,
+		Unit:     opts.Unit,
+	}
+	if err := calc.Validate(req); err != nil {
+		return Result{}, err
+	}
+
+	result, err := calc.Calculate(req)
+	if err != nil {
+		return Result{}, err
+	}
+	return fromCalculationResult(result), nil
+}
+
+// Evaluate evaluates a mathematical expression with optional variables
+func Evaluate(expression string, variables map[string]float64) (float64, error) {
+	result, err := engine.NewExpressionCalculator().Evaluate(types.ExpressionRequest{
+		Expression: expression,
+		Variables:  variables,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.Result, nil
+}
@@ -0,0 +1,154 @@
+package calculator
+
+import (
+	engine "calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+// AmortizationRow is one payment of an amortization schedule
+type AmortizationRow = types.AmortizationRow
+
+// FinancialInput holds the inputs of the time-value-of-money operations.
+// Rates are annual percentages.
+type FinancialInput struct {
+	Principal      float64
+	Rate           float64
+	Years          float64
+	PeriodsPerYear int // Compounding or payment periods per year
+	FutureValue    float64
+}
+
+func (in FinancialInput) request(operation string) types.FinancialRequest {
+	return types.FinancialRequest{
+		Operation:   operation,
+		Principal:   in.Principal,
+		Rate:        in.Rate,
+		Time:        in.Years,
+		Periods:     in.PeriodsPerYear,
+		FutureValue: in.FutureValue,
+	}
+}
+
+// FinancialResult is the outcome of a financial operation with its breakdown
+type FinancialResult struct {
+	Value       float64
+	Breakdown   map[string]interface{}
+	Description string
+}
+
+// Financial performs compound_interest, simple_interest, loan_payment, roi,
+// present_value or future_value
+func Financial(operation string, in FinancialInput) (FinancialResult, error) {
+	result, err := engine.NewFinancialCalculator().Calculate(in.request(operation))
+	if err != nil {
+		return FinancialResult{}, err
+	}
+	return FinancialResult{Value: result.Result, Breakdown: result.Breakdown, Description: result.Description}, nil
+}
+
+// NPV returns the net present value of cash flows at a discount rate (percentage)
+func NPV(cashFlows []float64, discountRate float64) (float64, error) {
+	return engine.NewFinancialCalculator().NetPresentValue(cashFlows, discountRate)
+}
+
+// IRR returns the internal rate of return (percentage) of cash flows
+func IRR(cashFlows []float64) (float64, error) {
+	return engine.NewFinancialCalculator().InternalRateOfReturn(cashFlows)
+}
+
+// InterpretNPV describes whether an NPV indicates a profitable investment
+func InterpretNPV(npv float64) string {
+	if npv > 0 {
+		return "Positive NPV indicates the investment is profitable"
+	} else if npv < 0 {
+		return "Negative NPV indicates the investment is not profitable"
+	}
+	return "Zero NPV indicates the investment breaks even"
+}
+
+// InterpretIRR rates an IRR (percentage) from poor to excellent
+func InterpretIRR(irr float64) string {
+	if irr > 15 {
+		return "High IRR indicates excellent investment return"
+	} else if irr > 10 {
+		return "Good IRR indicates strong investment return"
+	} else if irr > 5 {
+		return "Moderate IRR indicates acceptable investment return"
+	}
+	return "Low IRR indicates poor investment return"
+}
+
+// Loan describes a fixed-payment loan. PaymentsPerYear defaults to 12.
+type Loan struct {
+	Principal       float64
+	Rate            float64
+	Years           float64
+	PaymentsPerYear int
+}
+
+func (l Loan) input() FinancialInput {
+	return FinancialInput{Principal: l.Principal, Rate: l.Rate, Years: l.Years, PeriodsPerYear: l.PaymentsPerYear}
+}
+
+// LoanPayment returns the periodic payment of the loan
+func LoanPayment(loan Loan) (FinancialResult, error) {
+	return Financial("loan_payment", loan.input())
+}
+
+// AmortizationSchedule returns the payment-by-payment breakdown of the loan
+func AmortizationSchedule(loan Loan) ([]AmortizationRow, error) {
+	return engine.NewFinancialCalculator().AmortizationSchedule(loan.input().request("loan_payment"))
+}
+
+// Scenario is the outcome of one option in a comparison. Err is set when
+// the option could not be calculated; such options never win.
+type Scenario struct {
+	Result FinancialResult
+	Err    error
+}
+
+// Comparison is the outcome of comparing several options. Best is -1 when
+// no option could be calculated.
+type Comparison struct {
+	Scenarios []Scenario
+	Best      int
+	BestValue float64
+}
+
+// CompareLoans calculates every loan's payment and picks the lowest
+func CompareLoans(loans []Loan) Comparison {
+	return compare(len(loans), func(i int) (FinancialResult, error) {
+		return LoanPayment(loans[i])
+	}, func(candidate, best float64) bool { return candidate < best }, 999999999)
+}
+
+// Investment is one scenario of an investment comparison. Operation
+// defaults to compound_interest.
+type Investment struct {
+	Operation string
+	FinancialInput
+}
+
+// CompareInvestments calculates every scenario and picks the highest final amount
+func CompareInvestments(investments []Investment) Comparison {
+	return compare(len(investments), func(i int) (FinancialResult, error) {
+		operation := investments[i].Operation
+		if operation == "" {
+			operation = "compound_interest"
+		}
+		return Financial(operation, investments[i].FinancialInput)
+	}, func(candidate, best float64) bool { return candidate > best }, -1)
+}
+
+func compare(n int, calculate func(i int) (FinancialResult, error), better func(candidate, best float64) bool, initial float64) Comparison {
+	comparison := Comparison{Scenarios: make([]Scenario, n), Best: -1, BestValue: initial}
+	for i := 0; i < n; i++ {
+		result, err := calculate(i)
+		comparison.Scenarios[i] = Scenario{Result: result, Err: err}
+		if err == nil && better(result.Value, comparison.BestValue) {
+			comparison.Best = i
+			comparison.BestValue = result.Value
+		}
+	}
+	return comparison
+}
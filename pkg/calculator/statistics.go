@@ -0,0 +1,59 @@
+package calculator
+
+import (
+	engine "calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+// HistogramBin is one equal-width bin of a histogram
+type HistogramBin = types.HistogramBin
+
+// Statistic computes mean, median, mode, std_dev, variance or percentile
+// (the common quartiles and tail percentiles) of the data
+func Statistic(operation string, data []float64) (interface{}, error) {
+	result, err := engine.NewStatisticsCalculator().Calculate(types.StatisticsRequest{
+		Data:      data,
+		Operation: operation,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// WeightedStatistic is Statistic with non-negative frequency weights, one
+// per data point. It also supports "regression" of data on x.
+func WeightedStatistic(operation string, data, weights, x []float64) (interface{}, error) {
+	result, err := engine.NewStatisticsCalculator().Calculate(types.StatisticsRequest{
+		Data:      data,
+		Operation: operation,
+		Weights:   weights,
+		X:         x,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// Summary returns the descriptive statistics of the data in one pass
+func Summary(data []float64) (map[string]interface{}, error) {
+	return engine.NewStatisticsCalculator().Summary(data)
+}
+
+// Percentile returns the p-th percentile (0-100) of the data
+func Percentile(data []float64, p float64) (float64, error) {
+	return engine.NewStatisticsCalculator().CalculatePercentile(data, p)
+}
+
+// WeightedPercentile returns the p-th percentile (0-100) of data with
+// optional frequency weights. method is "exact", or "p2" for a one-pass
+// estimate of unweighted data.
+func WeightedPercentile(data, weights []float64, p float64, method string) (float64, error) {
+	return engine.NewStatisticsCalculator().PercentileByMethod(data, weights, p, method)
+}
+
+// Histogram groups the data into the given number of equal-width bins
+func Histogram(data []float64, bins int) ([]HistogramBin, error) {
+	return engine.NewStatisticsCalculator().Histogram(data, bins)
+}
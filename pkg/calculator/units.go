@@ -0,0 +1,42 @@
+package calculator
+
+import (
+	engine "calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+// Convert converts value between two units of a category
+// (length, weight, temperature, volume or area)
+func Convert(value float64, fromUnit, toUnit, category string) (float64, error) {
+	result, err := engine.NewUnitConverter().Convert(types.UnitConversionRequest{
+		Value:    value,
+		FromUnit: fromUnit,
+		ToUnit:   toUnit,
+		Category: category,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.Result, nil
+}
+
+// ConvertAll converts every value between two units of a category
+func ConvertAll(values []float64, fromUnit, toUnit, category string) ([]float64, error) {
+	return engine.NewUnitConverter().ConvertMultiple(values, fromUnit, toUnit, category)
+}
+
+// Units lists the units of a category
+func Units(category string) ([]string, error) {
+	return engine.NewUnitConverter().GetSupportedUnits(category)
+}
+
+// UnitCategories lists the supported unit categories
+func UnitCategories() []string {
+	return engine.NewUnitConverter().GetSupportedCategories()
+}
+
+// Engineering renders value in engineering notation with an SI prefix on
+// symbol, e.g. Engineering(0.000047, "F") == "47 μF"
+func Engineering(value float64, symbol string) string {
+	return engine.FormatEngineering(value, symbol)
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// adminPathPrefix is the root of the administrative HTTP API
+const adminPathPrefix = "/admin/"
+
+// adminMiddleware only lets requests through that carry the configured
+// admin token as a bearer token
+func (t *StreamableHTTPTransport) adminMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !t.presentsAdminToken(r) {
+			t.writeErrorResponse(w, nil, ErrorCodeInvalidCredentials, "Invalid admin token", "")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// presentsAdminToken reports whether the request carries the configured
+// admin token as its bearer token
+func (t *StreamableHTTPTransport) presentsAdminToken(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && t.config.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(t.config.AdminToken)) == 1
+}
+
+// adminTokenMiddleware marks MCP requests presenting the admin token as
+// made by an administrator, who may read administrative resources such as
+// calculator://metrics. On the MCP endpoint the token stands in for the
+// client credentials, OAuth access token and tenant key checked after it;
+// other endpoints, such as /usage, still need those.
+func (t *StreamableHTTPTransport) adminTokenMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mcp" && t.presentsAdminToken(r) {
+			r = r.WithContext(WithAdmin(r.Context()))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// handleAdminToolGroups lists tool groups (GET) or enables/disables one
+// (POST with {"group": "...", "enabled": true|false})
+func (t *StreamableHTTPTransport) handleAdminToolGroups(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var update struct {
+			Group   string `json:"group"`
+			Enabled *bool  `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil || update.Group == "" || update.Enabled == nil {
+			http.Error(w, `Body must be {"group": "<name>", "enabled": true|false}`, http.StatusBadRequest)
+			return
+		}
+		t.mcpServer.SetToolGroupEnabled(update.Group, *update.Enabled)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"groups": t.mcpServer.ToolGroups(),
+	})
+}
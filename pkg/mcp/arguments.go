@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+// UnknownArgument describes an argument key that is not declared in a tool's
+// input schema, along with the closest declared property when one is similar
+type UnknownArgument struct {
+	Argument   string `json:"argument"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+func (u UnknownArgument) String() string {
+	if u.Suggestion == "" {
+		return fmt.Sprintf("%q", u.Argument)
+	}
+	return fmt.Sprintf("%q (did you mean %q?)", u.Argument, u.Suggestion)
+}
+
+// unknownArguments returns the argument keys missing from the schema's
+// properties, sorted by name. Schemas without properties accept anything.
+func unknownArguments[V any](schema map[string]interface{}, args map[string]V) []UnknownArgument {
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok || len(properties) == 0 {
+		return nil
+	}
+
+	var unknown []UnknownArgument
+	for name := range args {
+		if _, declared := properties[name]; declared {
+			continue
+		}
+		unknown = append(unknown, UnknownArgument{
+			Argument:   name,
+			Suggestion: closestProperty(name, properties),
+		})
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Argument < unknown[j].Argument })
+	return unknown
+}
+
+// allowsAdditionalProperties reports whether the schema tolerates undeclared
+// arguments. Following JSON Schema, only an explicit false forbids them.
+func allowsAdditionalProperties(schema map[string]interface{}) bool {
+	allowed, ok := schema["additionalProperties"].(bool)
+	return !ok || allowed
+}
+
+// describeUnknownArguments renders unknown arguments for error messages and warnings
+func describeUnknownArguments(unknown []UnknownArgument) string {
+	descriptions := make([]string, len(unknown))
+	for i, argument := range unknown {
+		descriptions[i] = argument.String()
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// closestProperty returns the declared property nearest to name by edit
+// distance, ignoring case, or "" when none is close enough to be a typo
+func closestProperty(name string, properties map[string]interface{}) string {
+	best, bestDistance := "", 0
+	for property := range properties {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(property))
+		if best == "" || distance < bestDistance || (distance == bestDistance && property < best) {
+			best, bestDistance = property, distance
+		}
+	}
+
+	// Allow roughly one edit per three characters, and always at least two
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	if bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// normalizeArguments converts json.Number values left by the request decoder
+// into float64, as handlers expect. A literal beyond the float64 range (such
+// as 1e400) is reported as a value-out-of-range error naming the argument
+// instead of silently becoming ±Inf.
+func normalizeArguments(args map[string]interface{}) (map[string]interface{}, *types.MCPError) {
+	normalized, err := normalizeValue(args, "")
+	if err != nil {
+		return nil, err
+	}
+	result, _ := normalized.(map[string]interface{})
+	return result, nil
+}
+
+func normalizeValue(value interface{}, path string) (interface{}, *types.MCPError) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, &types.MCPError{
+				Code:    ErrorCodeValueOutOfRange,
+				Message: fmt.Sprintf("Value out of range: %s", path),
+				Data: map[string]interface{}{
+					"argument": path,
+					"value":    string(v),
+					"reason":   "number exceeds the float64 range (about ±1.8e308)",
+				},
+			}
+		}
+		if err != nil {
+			return nil, &types.MCPError{Code: ErrorCodeInvalidFormat, Message: "Invalid number", Data: path}
+		}
+		return f, nil
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			itemPath := key
+			if path != "" {
+				itemPath = path + "." + key
+			}
+			n, err := normalizeValue(item, itemPath)
+			if err != nil {
+				return nil, err
+			}
+			normalized[key] = n
+		}
+		return normalized, nil
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			n, err := normalizeValue(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			normalized[i] = n
+		}
+		return normalized, nil
+	default:
+		return value, nil
+	}
+}
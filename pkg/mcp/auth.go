@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Credential is a static secret a client presents to the HTTP transport,
+// either as an API key or as a bearer token
+type Credential struct {
+	Name         string   // Identifies the client in logs; the secret never is
+	Secret       string   // The API key or bearer token itself
+	AllowedTools []string // Empty allows every registered tool
+	Quota        Quota    // Tool calls allowed per day and month
+}
+
+// allowsTool reports whether the credential may list and call the named tool
+func (c *Credential) allowsTool(name string) bool {
+	if len(c.AllowedTools) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedTools {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator checks the API key (X-API-Key header) or bearer token
+// (Authorization header) of requests against static credentials
+type Authenticator struct {
+	apiKeys      []*Credential
+	bearerTokens []*Credential
+}
+
+// NewAuthenticator creates an authenticator accepting the given API keys
+// and bearer tokens
+func NewAuthenticator(apiKeys, bearerTokens []Credential) *Authenticator {
+	auth := &Authenticator{}
+	for i := range apiKeys {
+		credential := apiKeys[i]
+		auth.apiKeys = append(auth.apiKeys, &credential)
+	}
+	for i := range bearerTokens {
+		credential := bearerTokens[i]
+		auth.bearerTokens = append(auth.bearerTokens, &credential)
+	}
+	return auth
+}
+
+// matchCredential returns the credential whose secret is presented.
+// Every candidate is compared so the time taken does not reveal which
+// one matched.
+func matchCredential(credentials []*Credential, presented string) *Credential {
+	var match *Credential
+	for _, credential := range credentials {
+		if subtle.ConstantTimeCompare([]byte(credential.Secret), []byte(presented)) == 1 {
+			match = credential
+		}
+	}
+	return match
+}
+
+// Authenticate returns the credential presented by the request. presented
+// reports whether the request carried any, telling a missing credential
+// apart from a wrong one.
+func (a *Authenticator) Authenticate(r *http.Request) (credential *Credential, presented bool) {
+	if key := r.Header.Get(TenantAPIKeyHeader); key != "" && len(a.apiKeys) > 0 {
+		return matchCredential(a.apiKeys, key), true
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" && len(a.bearerTokens) > 0 {
+		return matchCredential(a.bearerTokens, token), true
+	}
+	return nil, false
+}
+
+// WithCredential returns a context carrying the credential the request
+// was authenticated with
+func WithCredential(ctx context.Context, credential *Credential) context.Context {
+	return context.WithValue(ctx, credentialKey, credential)
+}
+
+// CredentialFromContext returns the credential of the request, or nil when
+// the transport does not authenticate
+func CredentialFromContext(ctx context.Context) *Credential {
+	credential, _ := ctx.Value(credentialKey).(*Credential)
+	return credential
+}
+
+// authMiddleware rejects requests without a valid API key or bearer token
+// with 401 Unauthorized and a JSON-RPC error body
+func (t *StreamableHTTPTransport) authMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The admin API authenticates with its own token, and load
+		// balancers checking health carry no credentials
+		if strings.HasPrefix(r.URL.Path, adminPathPrefix) || r.URL.Path == healthPath || IsAdmin(r.Context()) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		credential, presented := t.config.Auth.Authenticate(r)
+		if credential == nil {
+			if len(t.config.Auth.bearerTokens) > 0 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+			}
+			if !presented {
+				t.writeErrorResponse(w, nil, ErrorCodeAuthenticationRequired, "Authentication required", "send an "+TenantAPIKeyHeader+" header or a bearer token")
+			} else {
+				t.writeErrorResponse(w, nil, ErrorCodeInvalidCredentials, "Invalid credentials", "")
+			}
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(WithCredential(r.Context(), credential)))
+	})
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"calculator-server/internal/types"
+)
+
+// Cancellation notification methods. $/cancelRequest is the Language Server
+// Protocol spelling, accepted for clients built on LSP libraries.
+const (
+	NotificationCancelled = "notifications/cancelled"
+	MethodCancelRequest   = "$/cancelRequest"
+)
+
+// inFlightRequest is a request that can still be cancelled
+type inFlightRequest struct {
+	cancel context.CancelFunc
+}
+
+// inFlightKey identifies a request within its session. IDs are compared in
+// their JSON form, so the string "1" and the number 1 stay distinct.
+func inFlightKey(sessionID string, id interface{}) string {
+	idJSON, _ := json.Marshal(id)
+	return sessionID + "\x00" + string(idJSON)
+}
+
+// trackRequest makes a request cancellable by ID until done is called
+func (s *Server) trackRequest(ctx context.Context, id interface{}) (_ context.Context, done func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := inFlightKey(SessionIDFromContext(ctx), id)
+	request := &inFlightRequest{cancel: cancel}
+
+	s.inFlightMu.Lock()
+	s.inFlight[key] = request
+	s.inFlightMu.Unlock()
+
+	return ctx, func() {
+		s.inFlightMu.Lock()
+		// A later request may have reused the ID
+		if s.inFlight[key] == request {
+			delete(s.inFlight, key)
+		}
+		s.inFlightMu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest cancels the in-flight request named by a cancellation
+// notification. Unknown and already finished requests are ignored, since
+// the cancellation may cross the response on the wire.
+func (s *Server) cancelRequest(ctx context.Context, params json.RawMessage) {
+	var cancelled types.CancelledParams
+	if len(params) == 0 || decodeJSON(params, &cancelled) != nil {
+		return
+	}
+	id := cancelled.RequestID
+	if id == nil {
+		id = cancelled.ID
+	}
+	if id == nil {
+		return
+	}
+
+	key := inFlightKey(SessionIDFromContext(ctx), id)
+	s.inFlightMu.Lock()
+	request, exists := s.inFlight[key]
+	s.inFlightMu.Unlock()
+	if exists {
+		if cancelled.Reason != "" {
+			logf(ctx, "Cancelling request %v: %s", id, cancelled.Reason)
+		}
+		request.cancel()
+	}
+}
+
+// InFlightRequests returns the number of requests currently being handled
+func (s *Server) InFlightRequests() int {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+	return len(s.inFlight)
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosConfig injects faults into the HTTP transport so that client
+// developers can test their retry and reconnect logic. Rates are
+// fractions between 0 and 1 of the requests or events affected. It must
+// never be enabled in production.
+type ChaosConfig struct {
+	Delay     time.Duration // Longest delay added to a delayed request; each waits a random time up to it
+	DelayRate float64       // Fraction of requests delayed
+	ErrorRate float64       // Fraction of requests answered 503 with a transient error instead of being served
+	DropRate  float64       // Fraction of SSE notifications and heartbeats silently dropped
+	Seed      int64         // Seed of the fault sequence, to reproduce a run; 0 picks one at random
+}
+
+// chaosInjector decides which requests and events get a fault
+type chaosInjector struct {
+	config ChaosConfig
+	mu     sync.Mutex
+	rng    *rand.Rand
+}
+
+func newChaosInjector(config ChaosConfig) *chaosInjector {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &chaosInjector{config: config, rng: rand.New(rand.NewSource(seed))}
+}
+
+// hit reports whether a fault occurring at rate happens this time
+func (c *chaosInjector) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < rate
+}
+
+// delay returns how long to hold a request, which is 0 for most
+func (c *chaosInjector) delay() time.Duration {
+	if c.config.Delay <= 0 || !c.hit(c.config.DelayRate) {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Duration(c.rng.Int63n(int64(c.config.Delay))) + 1
+}
+
+// dropEvent reports whether an SSE event should be lost
+func (c *chaosInjector) dropEvent() bool {
+	return c != nil && c.hit(c.config.DropRate)
+}
+
+// chaosMiddleware delays requests to /mcp and /jobs or fails them with a
+// transient error, at the configured rates. Operational and admin
+// endpoints are never affected.
+func (t *StreamableHTTPTransport) chaosMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mcp" && !strings.HasPrefix(r.URL.Path, "/jobs") {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if delay := t.chaos.delay(); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
+		if t.chaos.hit(t.chaos.config.ErrorRate) {
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(errorResponse(nil, ErrorCodeServiceUnavailable, "Server busy", "injected fault"))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
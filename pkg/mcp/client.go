@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"context"
+
+	"calculator-server/internal/types"
+)
+
+// Protocol versions the server speaks, oldest first. initialize answers with
+// the client's version when it is one of these, and the latest otherwise.
+var supportedProtocolVersions = []string{"2024-11-05", "2025-03-26"}
+
+// ExperimentalPartialResults is the experimental client capability that
+// opts in to notifications/partial_result
+const ExperimentalPartialResults = "partialResults"
+
+// ClientState is what a client declared in initialize, and whether it has
+// since sent notifications/initialized
+type ClientState struct {
+	ProtocolVersion string
+	Capabilities    types.ClientCapabilities
+	ClientInfo      *types.ClientInfo
+	Initialized     bool
+}
+
+// negotiateProtocolVersion picks the protocol version to answer initialize with
+func negotiateProtocolVersion(requested string) string {
+	for _, version := range supportedProtocolVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return supportedProtocolVersions[len(supportedProtocolVersions)-1]
+}
+
+// recordClient stores the initialize params of the session's client,
+// replacing any earlier ones. Requests outside a session are not recorded,
+// since nothing ties later requests to them.
+func (s *Server) recordClient(ctx context.Context, params types.InitializeParams, version string) {
+	sessionID := SessionIDFromContext(ctx)
+	if sessionID == "" {
+		return
+	}
+
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	s.clients[sessionID] = &ClientState{
+		ProtocolVersion: version,
+		Capabilities:    params.Capabilities,
+		ClientInfo:      params.ClientInfo,
+	}
+}
+
+// markInitialized records that the session's client sent notifications/initialized
+func (s *Server) markInitialized(ctx context.Context) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	if client, exists := s.clients[SessionIDFromContext(ctx)]; exists {
+		client.Initialized = true
+	}
+}
+
+// Client returns what the client of a session declared in initialize
+func (s *Server) Client(sessionID string) (ClientState, bool) {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+
+	client, exists := s.clients[sessionID]
+	if !exists {
+		return ClientState{}, false
+	}
+	return *client, true
+}
+
+// RestoreClient reinstates the client state of a session, e.g. one reloaded
+// from a persistent store after a restart
+func (s *Server) RestoreClient(sessionID string, client ClientState) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+	s.clients[sessionID] = &client
+}
+
+// ForgetClient drops the client state of a session that has ended,
+// including its log level, resource subscriptions and session formulas
+func (s *Server) ForgetClient(sessionID string) {
+	s.forgetClientState(sessionID)
+	s.forgetFormulas(sessionID)
+}
+
+// forgetClientState drops the state this server keeps in memory for a
+// session, leaving its formulas in the store
+func (s *Server) forgetClientState(sessionID string) {
+	s.clientsMu.Lock()
+	delete(s.clients, sessionID)
+	s.clientsMu.Unlock()
+
+	s.logLevelsMu.Lock()
+	delete(s.logLevels, sessionID)
+	s.logLevelsMu.Unlock()
+
+	s.forgetSubscriptions(sessionID)
+}
+
+// supportsPartialResults reports whether the calling client may be sent
+// notifications/partial_result, which are not part of the MCP
+// specification. Clients that never sent initialize keep the earlier
+// behaviour and receive them; clients that did must opt in through the
+// experimental partialResults capability.
+func (s *Server) supportsPartialResults(ctx context.Context) bool {
+	client, known := s.Client(SessionIDFromContext(ctx))
+	if !known {
+		return true
+	}
+	_, ok := client.Capabilities.Experimental[ExperimentalPartialResults]
+	return ok
+}
+
+// acceptsNotifications reports whether server-initiated notifications may
+// be sent to a session. A client that has sent initialize is ready for them
+// only once it has sent notifications/initialized.
+func (s *Server) acceptsNotifications(sessionID string) bool {
+	client, known := s.Client(sessionID)
+	return !known || client.Initialized
+}
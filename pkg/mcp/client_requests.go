@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// ClientRequestTimeout bounds how long the server waits for the client to
+// answer a request it sent, such as an elicitation a user has to fill in
+const ClientRequestTimeout = 5 * time.Minute
+
+// RequestFunc delivers a server-initiated request to the client of the request
+type RequestFunc func(request types.MCPRequest)
+
+// WithRequester returns a context whose server-initiated requests are
+// delivered by send. Transports set it where the client can answer them.
+func WithRequester(ctx context.Context, send RequestFunc) context.Context {
+	return context.WithValue(ctx, requesterKey, send)
+}
+
+// RequesterFromContext returns the request sink of the request, or nil if
+// the transport cannot deliver requests to its client
+func RequesterFromContext(ctx context.Context) RequestFunc {
+	send, _ := ctx.Value(requesterKey).(RequestFunc)
+	return send
+}
+
+// clientResponse is the client's answer to a server-initiated request
+type clientResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Method  string          `json:"method"`
+	Result  json.RawMessage `json:"result"`
+	Error   *types.MCPError `json:"error"`
+}
+
+// clientRequestTracker matches the client's responses to the requests the
+// server is waiting on, per session
+type clientRequestTracker struct {
+	mu      sync.Mutex
+	next    uint64
+	pending map[string]chan clientResponse
+}
+
+// pendingKey identifies a request among those of every session. A client
+// can only answer requests sent to its own session.
+func pendingKey(sessionID string, id interface{}) string {
+	return fmt.Sprintf("%s\x00%v", sessionID, id)
+}
+
+// requestClient sends a request to the client of ctx and waits for its
+// result, the context to end or ClientRequestTimeout to pass
+func (s *Server) requestClient(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	send := RequesterFromContext(ctx)
+	if send == nil {
+		return nil, fmt.Errorf("the transport cannot send %s to this client", method)
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+	}
+
+	tracker := &s.clientRequests
+	tracker.mu.Lock()
+	tracker.next++
+	id := fmt.Sprintf("server-%d", tracker.next)
+	key := pendingKey(SessionIDFromContext(ctx), id)
+	if tracker.pending == nil {
+		tracker.pending = make(map[string]chan clientResponse)
+	}
+	answer := make(chan clientResponse, 1)
+	tracker.pending[key] = answer
+	tracker.mu.Unlock()
+
+	defer func() {
+		tracker.mu.Lock()
+		delete(tracker.pending, key)
+		tracker.mu.Unlock()
+	}()
+
+	send(types.MCPRequest{JSONRPC: "2.0", ID: id, Method: method, Params: paramsJSON})
+
+	timer := time.NewTimer(ClientRequestTimeout)
+	defer timer.Stop()
+	select {
+	case response := <-answer:
+		if response.Error != nil {
+			return nil, fmt.Errorf("client answered %s with error %d: %s", method, response.Error.Code, response.Error.Message)
+		}
+		return response.Result, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("client did not answer %s within %v", method, ClientRequestTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// handleClientResponse reports whether data is a JSON-RPC response rather
+// than a request, handing it to the request of its session waiting for it.
+// Responses nobody waits for, e.g. after a timeout, are dropped.
+func (s *Server) handleClientResponse(ctx context.Context, data []byte) bool {
+	// Requests carry neither member, so most messages are told apart
+	// without decoding them twice
+	if !bytes.Contains(data, []byte(`"result"`)) && !bytes.Contains(data, []byte(`"error"`)) {
+		return false
+	}
+	var response clientResponse
+	if err := json.Unmarshal(data, &response); err != nil || response.Method != "" || (response.Result == nil && response.Error == nil) {
+		return false
+	}
+
+	tracker := &s.clientRequests
+	tracker.mu.Lock()
+	answer, waiting := tracker.pending[pendingKey(SessionIDFromContext(ctx), response.ID)]
+	delete(tracker.pending, pendingKey(SessionIDFromContext(ctx), response.ID))
+	tracker.mu.Unlock()
+
+	if waiting {
+		answer <- response
+	} else {
+		logf(ctx, "Dropped response to unknown server request %v", response.ID)
+	}
+	return true
+}
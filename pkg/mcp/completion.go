@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+// maxCompletionValues is the most values one completion/complete answer lists
+const maxCompletionValues = 100
+
+// CompletionHandler returns the candidate values of an argument, given the
+// values of the arguments already filled in. The server keeps the candidates
+// that start with what the client has typed.
+type CompletionHandler func(ctx context.Context, arguments map[string]string) ([]string, error)
+
+// WithCompletion completes an argument of the tool with the handler's values
+// instead of the enum of its input schema
+func WithCompletion(argument string, handler CompletionHandler) ToolOption {
+	return func(schema *ToolSchema) {
+		if schema.Completions == nil {
+			schema.Completions = make(map[string]CompletionHandler)
+		}
+		schema.Completions[argument] = handler
+	}
+}
+
+// complete answers completion/complete for an argument of a tool, a prompt
+// or the saved formula resource template
+func (s *Server) complete(ctx context.Context, params types.CompleteParams) (types.CompleteResult, *types.MCPError) {
+	var arguments map[string]string
+	if params.Context != nil {
+		arguments = params.Context.Arguments
+	}
+
+	var candidates []string
+	var mcpErr *types.MCPError
+	switch params.Ref.Type {
+	case "ref/tool":
+		candidates, mcpErr = s.toolCompletions(ctx, params.Ref.Name, params.Argument.Name, arguments)
+	case "ref/prompt":
+		candidates, mcpErr = s.promptCompletions(params.Ref.Name, params.Argument.Name)
+	case "ref/resource":
+		candidates, mcpErr = s.resourceCompletions(ctx, params.Ref.URI, params.Argument.Name)
+	default:
+		return types.CompleteResult{}, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Unsupported reference type", Data: params.Ref.Type}
+	}
+	if mcpErr != nil {
+		return types.CompleteResult{}, mcpErr
+	}
+	return types.CompleteResult{Completion: matchCompletions(candidates, params.Argument.Value)}, nil
+}
+
+// toolCompletions returns the candidates of a tool argument, from its
+// completion handler or its schema enum. Arguments with neither, such as
+// numbers, have no candidates.
+func (s *Server) toolCompletions(ctx context.Context, tool, argument string, arguments map[string]string) ([]string, *types.MCPError) {
+	_, schema, exists := s.lookupTool(tool)
+	if !exists || !s.toolAvailable(ctx, schema) {
+		return nil, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Unknown tool", Data: tool}
+	}
+	if handler, ok := schema.Completions[argument]; ok {
+		candidates, err := handler(ctx, arguments)
+		if err != nil {
+			return nil, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Invalid completion arguments", Data: err.Error()}
+		}
+		return candidates, nil
+	}
+
+	properties, _ := schema.InputSchema["properties"].(map[string]interface{})
+	property, ok := properties[argument].(map[string]interface{})
+	if !ok {
+		return nil, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Unknown argument", Data: argument}
+	}
+	return enumValues(property["enum"]), nil
+}
+
+// promptCompletions checks the prompt argument exists. Prompt arguments are
+// free text, so they have no candidates.
+func (s *Server) promptCompletions(prompt, argument string) ([]string, *types.MCPError) {
+	registered, ok := s.prompts[prompt]
+	if !ok {
+		return nil, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Unknown prompt", Data: prompt}
+	}
+	for _, declared := range registered.prompt.Arguments {
+		if declared.Name == argument {
+			return nil, nil
+		}
+	}
+	return nil, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Unknown argument", Data: argument}
+}
+
+// resourceCompletions completes the name of the saved formula template with
+// the formulas visible to the session, in name order
+func (s *Server) resourceCompletions(ctx context.Context, uri, argument string) ([]string, *types.MCPError) {
+	if uri != FormulaURIPrefix+"{name}" {
+		return nil, &types.MCPError{Code: ErrorCodeResourceNotFound, Message: "Resource not found", Data: uri}
+	}
+	if argument != "name" {
+		return nil, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Unknown argument", Data: argument}
+	}
+
+	formulas, err := s.Formulas(ctx)
+	if err != nil {
+		return nil, &types.MCPError{Code: ErrorCodeInternalError, Message: "Internal error", Data: err.Error()}
+	}
+	names := make([]string, len(formulas))
+	for i, formula := range formulas {
+		names[i] = formula.Name
+	}
+	return names, nil
+}
+
+// enumValues returns the string values of a schema enum
+func enumValues(enum interface{}) []string {
+	switch values := enum.(type) {
+	case []string:
+		return values
+	case []interface{}:
+		strs := make([]string, 0, len(values))
+		for _, value := range values {
+			if str, ok := value.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+		return strs
+	}
+	return nil
+}
+
+// matchCompletions keeps the candidates starting with the typed value,
+// ignoring case, in their original order and without duplicates
+func matchCompletions(candidates []string, value string) types.Completion {
+	prefix := strings.ToLower(value)
+	seen := make(map[string]bool, len(candidates))
+	values := []string{}
+	for _, candidate := range candidates {
+		if seen[candidate] || !strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			continue
+		}
+		seen[candidate] = true
+		values = append(values, candidate)
+	}
+
+	completion := types.Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		completion.Values, completion.HasMore = values[:maxCompletionValues], true
+	}
+	return completion
+}
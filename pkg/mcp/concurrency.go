@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// sessionSlots counts the calls of one session holding or waiting for a slot
+type sessionSlots struct {
+	slots chan struct{}
+	users int
+}
+
+// sessionLimiter caps the tool calls each session runs at once, so one
+// misbehaving session cannot starve the others on a shared server
+type sessionLimiter struct {
+	mu           sync.Mutex
+	limit        int           // Calls a session may run at once; 0 is unlimited
+	queueTimeout time.Duration // How long an extra call waits for a slot; 0 rejects it at once
+	sessions     map[string]*sessionSlots
+}
+
+// acquire takes one of the session's slots, waiting up to the queue timeout
+// for one to free up. It returns the function that gives the slot back, or
+// false when the call must be rejected.
+func (l *sessionLimiter) acquire(ctx context.Context, sessionID string) (func(), bool) {
+	l.mu.Lock()
+	if l.limit <= 0 || sessionID == "" {
+		l.mu.Unlock()
+		return func() {}, true
+	}
+	if l.sessions == nil {
+		l.sessions = make(map[string]*sessionSlots)
+	}
+	session, exists := l.sessions[sessionID]
+	if !exists {
+		session = &sessionSlots{slots: make(chan struct{}, l.limit)}
+		l.sessions[sessionID] = session
+	}
+	session.users++
+	queueTimeout := l.queueTimeout
+	l.mu.Unlock()
+
+	acquired := false
+	select {
+	case session.slots <- struct{}{}:
+		acquired = true
+	default:
+		if queueTimeout > 0 {
+			timer := time.NewTimer(queueTimeout)
+			select {
+			case session.slots <- struct{}{}:
+				acquired = true
+			case <-timer.C:
+			case <-ctx.Done():
+			}
+			timer.Stop()
+		}
+	}
+
+	if !acquired {
+		l.leave(sessionID, session)
+		return nil, false
+	}
+	return func() {
+		<-session.slots
+		l.leave(sessionID, session)
+	}, true
+}
+
+// callSlot is a session slot shared by a tool call and its handler, which
+// may outlive the call when the call is cancelled. The slot is given back
+// once both are done, so abandoned handlers still count against the limit.
+type callSlot struct {
+	holders int32
+	release func()
+}
+
+func newCallSlot(release func()) *callSlot {
+	return &callSlot{holders: 1, release: release}
+}
+
+// retain adds a holder of the slot
+func (c *callSlot) retain() {
+	if c != nil {
+		atomic.AddInt32(&c.holders, 1)
+	}
+}
+
+// done drops a holder, giving the slot back after the last
+func (c *callSlot) done() {
+	if c != nil && atomic.AddInt32(&c.holders, -1) == 0 {
+		c.release()
+	}
+}
+
+// leave drops a call from the session's count, forgetting sessions with none
+func (l *sessionLimiter) leave(sessionID string, session *sessionSlots) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	session.users--
+	if session.users == 0 && l.sessions[sessionID] == session {
+		delete(l.sessions, sessionID)
+	}
+}
+
+// SetSessionCallLimit caps the tool calls a session runs at once. Extra
+// calls wait up to queueTimeout for a running one to finish and are then
+// rejected; a zero queueTimeout rejects them at once. A limit of zero
+// removes the cap. Requests outside a session are not limited.
+func (s *Server) SetSessionCallLimit(limit int, queueTimeout time.Duration) {
+	s.sessionCalls.mu.Lock()
+	defer s.sessionCalls.mu.Unlock()
+	s.sessionCalls.limit = limit
+	s.sessionCalls.queueTimeout = queueTimeout
+}
+
+// sessionCallLimitError reports a call rejected by the session call limit
+func (s *Server) sessionCallLimitError(name string) *types.MCPError {
+	s.sessionCalls.mu.Lock()
+	limit := s.sessionCalls.limit
+	s.sessionCalls.mu.Unlock()
+
+	return &types.MCPError{
+		Code:    ErrorCodeTooManyRequests,
+		Message: fmt.Sprintf("Too many concurrent tool calls in this session (limit %d)", limit),
+		Data:    map[string]interface{}{"tool": name, "limit": limit},
+	}
+}
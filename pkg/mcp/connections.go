@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// connectionTracker counts the requests the transport is serving, including
+// open SSE streams, and those it turned away
+type connectionTracker struct {
+	active   atomic.Int64
+	rejected atomic.Int64
+}
+
+// ConnectionStats reports the load of the HTTP transport
+type ConnectionStats struct {
+	Active   int64 `json:"active"`   // Requests in progress, including open SSE streams
+	Max      int   `json:"max"`      // MaxConnections; 0 is unlimited
+	Streams  int   `json:"streams"`  // Open standalone SSE streams (GET /mcp)
+	Rejected int64 `json:"rejected"` // Requests refused since the transport started
+}
+
+// ConnectionStats returns the current connection counts
+func (t *StreamableHTTPTransport) ConnectionStats() ConnectionStats {
+	return ConnectionStats{
+		Active:   t.connections.active.Load(),
+		Max:      t.config.MaxConnections,
+		Streams:  t.streams.count(),
+		Rejected: t.connections.rejected.Load(),
+	}
+}
+
+// connectionLimitMiddleware refuses requests beyond MaxConnections with
+// 503 Service Unavailable, so a burst of clients or long-lived streams
+// cannot exhaust the server. Health checks and metrics are always served,
+// so the backpressure stays observable.
+func (t *StreamableHTTPTransport) connectionLimitMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthPath || r.URL.Path == metricsPath || strings.HasPrefix(r.URL.Path, adminPathPrefix) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		active := t.connections.active.Add(1)
+		defer t.connections.active.Add(-1)
+		if limit := t.config.MaxConnections; limit > 0 && active > int64(limit) {
+			t.connections.rejected.Add(1)
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(errorResponse(nil, ErrorCodeServiceUnavailable, "Server busy", fmt.Sprintf("all %d connections are in use", limit)))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"calculator-server/internal/types"
+)
+
+// contextKey is the type for request-scoped values stored by the MCP server
+type contextKey int
+
+const (
+	sessionIDKey contextKey = iota
+	notifierKey
+	partialResultKey
+	tenantKey
+	requestIDKey
+	progressKey
+	requesterKey
+	credentialKey
+	adminKey
+)
+
+// StdioSessionID is the session identifier used for the single stdio client
+const StdioSessionID = "stdio"
+
+// WithSessionID returns a context carrying the session the request belongs to
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey, sessionID)
+}
+
+// SessionIDFromContext returns the session of the request, or "" if none
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDKey).(string)
+	return sessionID
+}
+
+// WithRequestID returns a context carrying the ID the transport assigned to the request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the transport-assigned request ID, or "" if none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithAdmin returns a context marking the request as made by an
+// administrator, who may read administrative resources
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey, true)
+}
+
+// IsAdmin reports whether the request was made by an administrator
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey).(bool)
+	return admin
+}
+
+// logf logs a message prefixed with the request ID of ctx, if any, so log
+// lines can be correlated with the X-Request-Id the client received
+func logf(ctx context.Context, format string, args ...interface{}) {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		format = fmt.Sprintf("[request %s] %s", requestID, format)
+	}
+	log.Printf(format, args...)
+}
+
+// NotifyFunc delivers a server-initiated notification to the client of the request
+type NotifyFunc func(notification types.MCPNotification)
+
+// WithNotifier returns a context whose notifications are delivered by notify
+func WithNotifier(ctx context.Context, notify NotifyFunc) context.Context {
+	return context.WithValue(ctx, notifierKey, notify)
+}
+
+// NotifierFromContext returns the notification sink of the request, or nil if
+// the transport cannot deliver notifications for it
+func NotifierFromContext(ctx context.Context) NotifyFunc {
+	notify, _ := ctx.Value(notifierKey).(NotifyFunc)
+	return notify
+}
+
+// partialResultStream numbers the chunks of one streamed tool result
+type partialResultStream struct {
+	mu    sync.Mutex
+	token interface{}
+	next  int
+	emit  NotifyFunc
+}
+
+// withPartialResults enables partial results for a tool call carrying a
+// progress token, if the transport can deliver notifications
+func withPartialResults(ctx context.Context, meta *types.RequestMeta) context.Context {
+	notify := NotifierFromContext(ctx)
+	if meta == nil || meta.ProgressToken == nil || notify == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, partialResultKey, &partialResultStream{token: meta.ProgressToken, emit: notify})
+}
+
+// StreamsPartialResults reports whether EmitPartialResult will reach the client
+func StreamsPartialResults(ctx context.Context) bool {
+	_, ok := ctx.Value(partialResultKey).(*partialResultStream)
+	return ok
+}
+
+// EmitPartialResult sends one chunk of a tool's output ahead of the final
+// result as a notifications/partial_result message tied to the request's
+// progress token. It is a no-op when the request is not being streamed.
+func EmitPartialResult(ctx context.Context, data interface{}) {
+	stream, ok := ctx.Value(partialResultKey).(*partialResultStream)
+	if !ok {
+		return
+	}
+
+	stream.mu.Lock()
+	index := stream.next
+	stream.next++
+	stream.mu.Unlock()
+
+	stream.emit(types.MCPNotification{
+		JSONRPC: "2.0",
+		Method:  "notifications/partial_result",
+		Params: types.PartialResultParams{
+			ProgressToken: stream.token,
+			Index:         index,
+			Data:          data,
+		},
+	})
+}
+
+// progressReporter sends the progress notifications of one tool call
+type progressReporter struct {
+	mu    sync.Mutex
+	token interface{}
+	last  float64
+	sent  bool
+	emit  NotifyFunc
+}
+
+// withProgress enables progress reporting for a tool call carrying a
+// progress token, if the transport can deliver notifications
+func withProgress(ctx context.Context, meta *types.RequestMeta) context.Context {
+	notify := NotifierFromContext(ctx)
+	if meta == nil || meta.ProgressToken == nil || notify == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey, &progressReporter{token: meta.ProgressToken, emit: notify})
+}
+
+// withoutStreams detaches the progress and partial result streams of ctx,
+// so that tools run on behalf of another tool do not report to its client
+func withoutStreams(ctx context.Context) context.Context {
+	return context.WithValue(context.WithValue(ctx, progressKey, nil), partialResultKey, nil)
+}
+
+// ReportsProgress reports whether ReportProgress will reach the client
+func ReportsProgress(ctx context.Context) bool {
+	_, ok := ctx.Value(progressKey).(*progressReporter)
+	return ok
+}
+
+// ReportProgress tells the client how far a long-running tool call has got
+// with a notifications/progress message tied to the request's progress
+// token. total is the amount of work when known, or 0. Progress must
+// increase between reports, so reports that do not are dropped. It is a
+// no-op when the request did not ask for progress.
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	reporter, ok := ctx.Value(progressKey).(*progressReporter)
+	if !ok {
+		return
+	}
+
+	reporter.mu.Lock()
+	if reporter.sent && progress <= reporter.last {
+		reporter.mu.Unlock()
+		return
+	}
+	reporter.last, reporter.sent = progress, true
+	reporter.mu.Unlock()
+
+	params := types.ProgressParams{ProgressToken: reporter.token, Progress: progress, Message: message}
+	if total > 0 {
+		params.Total = &total
+	}
+	reporter.emit(types.MCPNotification{JSONRPC: "2.0", Method: NotificationProgress, Params: params})
+}
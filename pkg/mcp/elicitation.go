@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"calculator-server/internal/types"
+)
+
+// MethodElicitationCreate asks the client to have its user fill in a form
+const MethodElicitationCreate = "elicitation/create"
+
+// WithRequiredArguments declares the arguments a call needs beyond the
+// schema's required list, e.g. those only some operations use. With
+// elicitation enabled for the tool, missing ones are asked of the client.
+func WithRequiredArguments(required func(arguments map[string]interface{}) []string) ToolOption {
+	return func(schema *ToolSchema) {
+		schema.RequiredArguments = required
+	}
+}
+
+// elicitationSettings records the tools that elicit missing arguments
+type elicitationSettings struct {
+	mu    sync.RWMutex
+	tools map[string]bool
+}
+
+// SetElicitation enables or disables elicitation for a tool. A call to an
+// enabled tool that lacks required arguments asks the client for them with
+// elicitation/create, when the client declared the elicitation capability
+// and the transport can carry the request, instead of failing.
+func (s *Server) SetElicitation(tool string, enabled bool) {
+	s.elicitation.mu.Lock()
+	defer s.elicitation.mu.Unlock()
+	if s.elicitation.tools == nil {
+		s.elicitation.tools = make(map[string]bool)
+	}
+	if enabled {
+		s.elicitation.tools[tool] = true
+	} else {
+		delete(s.elicitation.tools, tool)
+	}
+}
+
+// elicitationEnabled reports whether the tool elicits missing arguments
+func (s *Server) elicitationEnabled(tool string) bool {
+	s.elicitation.mu.RLock()
+	defer s.elicitation.mu.RUnlock()
+	return s.elicitation.tools[tool]
+}
+
+// canElicit reports whether the client of ctx can be sent elicitation/create
+func (s *Server) canElicit(ctx context.Context) bool {
+	if RequesterFromContext(ctx) == nil {
+		return false
+	}
+	client, known := s.Client(SessionIDFromContext(ctx))
+	return known && client.Capabilities.Elicitation != nil
+}
+
+// missingArguments lists the required arguments absent from a call, sorted
+func missingArguments(schema ToolSchema, arguments map[string]interface{}) []string {
+	var required []string
+	switch list := schema.InputSchema["required"].(type) {
+	case []string:
+		required = append(required, list...)
+	case []interface{}:
+		for _, name := range list {
+			if name, ok := name.(string); ok {
+				required = append(required, name)
+			}
+		}
+	}
+	if schema.RequiredArguments != nil {
+		required = append(required, schema.RequiredArguments(arguments)...)
+	}
+
+	seen := make(map[string]bool)
+	var missing []string
+	for _, name := range required {
+		if _, present := arguments[name]; !present && !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// elicitationSchemaKeys are the property keywords an elicitation form may use
+var elicitationSchemaKeys = []string{"type", "title", "description", "enum", "minimum", "maximum", "minLength", "maxLength", "format", "default"}
+
+// elicitationSchema builds the requestedSchema of an elicitation for the
+// missing arguments. Elicitation forms only hold flat primitive values, so
+// it fails when an argument is an array or an object.
+func elicitationSchema(schema ToolSchema, missing []string) (map[string]interface{}, bool) {
+	properties, _ := schema.InputSchema["properties"].(map[string]interface{})
+	requested := make(map[string]interface{}, len(missing))
+	for _, name := range missing {
+		property, _ := properties[name].(map[string]interface{})
+		switch property["type"] {
+		case "string", "number", "integer", "boolean":
+		default:
+			return nil, false
+		}
+		field := make(map[string]interface{})
+		for _, key := range elicitationSchemaKeys {
+			if value, ok := property[key]; ok {
+				field[key] = value
+			}
+		}
+		requested[name] = field
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": requested,
+		"required":   missing,
+	}, true
+}
+
+// elicitResult is the client's answer to elicitation/create
+type elicitResult struct {
+	Action  string                     `json:"action"` // accept, decline or cancel
+	Content map[string]json.RawMessage `json:"content"`
+}
+
+// elicitArguments asks the client for the missing arguments of a call. It
+// returns the values given, nil when elicitation does not apply so the call
+// goes ahead as it is, or an error when the user declined.
+func (s *Server) elicitArguments(ctx context.Context, schema ToolSchema, arguments map[string]interface{}) (map[string]json.RawMessage, error) {
+	if !s.elicitationEnabled(schema.Name) || !s.canElicit(ctx) {
+		return nil, nil
+	}
+	missing := missingArguments(schema, arguments)
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	requested, ok := elicitationSchema(schema, missing)
+	if !ok {
+		return nil, nil
+	}
+
+	s.logToolEvent(ctx, LogInfo, "elicitation_requested", schema.Name, map[string]interface{}{"arguments": missing})
+	resultJSON, err := s.requestClient(ctx, MethodElicitationCreate, map[string]interface{}{
+		"message":         fmt.Sprintf("%s needs %s to continue.", schema.Name, strings.Join(missing, ", ")),
+		"requestedSchema": requested,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// A client that cannot answer leaves the call to fail as it would have
+		logf(ctx, "Elicitation for %s failed: %v", schema.Name, err)
+		return nil, nil
+	}
+
+	var result elicitResult
+	if err := json.Unmarshal(resultJSON, &result); err != nil {
+		return nil, fmt.Errorf("invalid elicitation result: %v", err)
+	}
+	if result.Action != "accept" {
+		return nil, fmt.Errorf("%s needs %s; the user chose to %s", schema.Name, strings.Join(missing, ", "), result.Action)
+	}
+	// Only the arguments asked for are taken
+	values := make(map[string]json.RawMessage, len(missing))
+	for _, name := range missing {
+		if value, ok := result.Content[name]; ok {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// mergeElicited adds elicited values to the arguments of a call: to its raw
+// fields for typed tools, which have no argument map yet, and otherwise to
+// the normalized map
+func mergeElicited(params *types.CallToolParams, typed bool, fields map[string]json.RawMessage, elicited map[string]json.RawMessage) *types.MCPError {
+	if typed {
+		if fields == nil {
+			fields = make(map[string]json.RawMessage, len(elicited))
+		}
+		for name, value := range elicited {
+			fields[name] = value
+		}
+		params.RawArguments, _ = json.Marshal(fields)
+		return nil
+	}
+
+	elicitedJSON, _ := json.Marshal(elicited)
+	values, mcpErr := decodeArguments(elicitedJSON)
+	if mcpErr != nil {
+		return mcpErr
+	}
+	if params.Arguments == nil {
+		params.Arguments = make(map[string]interface{}, len(values))
+	}
+	for name, value := range values {
+		params.Arguments[name] = value
+	}
+	return nil
+}
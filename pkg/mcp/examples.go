@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"calculator-server/internal/types"
+)
+
+// toolExamplesPath serves the example invocations of every available tool
+const toolExamplesPath = "/tools/examples"
+
+// WithExample adds an example invocation to the tool: calling it with
+// arguments returns a result containing result. Examples are listed in the
+// tool's _meta in tools/list, in the order they were added.
+func WithExample(description string, arguments map[string]interface{}, result interface{}) ToolOption {
+	return func(schema *ToolSchema) {
+		schema.Examples = append(schema.Examples, types.ToolExample{
+			Description: description,
+			Arguments:   arguments,
+			Result:      result,
+		})
+	}
+}
+
+// ToolExamples returns the examples of the tools available in ctx, by tool
+// name. Tools without examples are left out.
+func (s *Server) ToolExamples(ctx context.Context) map[string][]types.ToolExample {
+	examples := make(map[string][]types.ToolExample)
+	for _, schema := range s.toolSchemas() {
+		if len(schema.Examples) > 0 && s.toolAvailable(ctx, schema) {
+			examples[schema.Name] = schema.Examples
+		}
+	}
+	return examples
+}
+
+// handleToolExamples serves the examples of every available tool, or of
+// the tool named by ?tool=
+func (t *StreamableHTTPTransport) handleToolExamples(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	examples := t.mcpServer.ToolExamples(r.Context())
+	if name := r.URL.Query().Get("tool"); name != "" {
+		_, schema, exists := t.mcpServer.lookupTool(name)
+		if !exists || !t.mcpServer.toolAvailable(r.Context(), schema) {
+			http.Error(w, "Unknown tool: "+name, http.StatusNotFound)
+			return
+		}
+		examples = map[string][]types.ToolExample{name: append([]types.ToolExample{}, schema.Examples...)}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tools": examples})
+}
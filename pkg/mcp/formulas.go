@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+)
+
+const (
+	// FormulaURIPrefix prefixes the resource URI of a saved formula
+	FormulaURIPrefix = "formula://"
+
+	// Session formulas are bucketed per session, persistent ones per tenant
+	formulaSessionBucketPrefix = "formulas:session:"
+	formulaSharedBucketPrefix  = "formulas:shared:"
+
+	// maxSessionFormulas and maxTenantFormulas cap how many formulas a
+	// session, or a tenant's persistent scope, may hold
+	maxSessionFormulas = 100
+	maxTenantFormulas  = 1000
+)
+
+// formulaBook holds session-scoped formulas when no store is configured
+type formulaBook struct {
+	once  sync.Once
+	store storage.Store
+	mu    sync.Mutex // Serializes saves so the caps hold
+}
+
+// formulaStore returns the configured store, falling back to a private
+// in-memory store so session formulas work without persistence
+func (s *Server) formulaStore() storage.Store {
+	if s.store != nil {
+		return s.store
+	}
+	s.formulas.once.Do(func() {
+		s.formulas.store = storage.NewMemoryStore()
+	})
+	return s.formulas.store
+}
+
+// formulaBucket returns the bucket holding formulas of the given scope for ctx
+func (s *Server) formulaBucket(ctx context.Context, scope string) (string, error) {
+	switch scope {
+	case types.FormulaScopeSession:
+		sessionID := SessionIDFromContext(ctx)
+		if sessionID == "" {
+			return "", fmt.Errorf("session formulas require a session; send the Mcp-Session-Id header or use the persistent scope")
+		}
+		return formulaSessionBucketPrefix + sessionID, nil
+	case types.FormulaScopePersistent:
+		if s.store == nil {
+			return "", fmt.Errorf("persistent formulas require storage to be enabled")
+		}
+		return formulaSharedBucketPrefix + tenantID(ctx), nil
+	default:
+		return "", fmt.Errorf("unsupported formula scope: %s. Supported scopes: [%s %s]", scope, types.FormulaScopeSession, types.FormulaScopePersistent)
+	}
+}
+
+// SaveFormula stores the formula in its scope, replacing any formula of the same name
+func (s *Server) SaveFormula(ctx context.Context, formula types.Formula) error {
+	bucket, err := s.formulaBucket(ctx, formula.Scope)
+	if err != nil {
+		return err
+	}
+
+	// Session formulas are deleted when their session ends, and expire with
+	// session history in case no replica sees it end; persistent ones never expire
+	var ttl time.Duration
+	limit := maxTenantFormulas
+	if formula.Scope == types.FormulaScopeSession {
+		ttl = s.historyTTL
+		limit = maxSessionFormulas
+	}
+
+	data, err := json.Marshal(formula)
+	if err != nil {
+		return fmt.Errorf("failed to encode formula: %w", err)
+	}
+
+	s.formulas.mu.Lock()
+	saved, err := s.formulaStore().List(bucket)
+	if err == nil {
+		if _, replacing := saved[formula.Name]; !replacing && len(saved) >= limit {
+			err = fmt.Errorf("the %s scope already holds %d formulas; overwrite one of them instead", formula.Scope, limit)
+		}
+	}
+	if err == nil {
+		err = s.formulaStore().Put(bucket, formula.Name, data, ttl)
+	}
+	s.formulas.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.notifyFormulaUpdated(ctx, formula)
+	return nil
+}
+
+// forgetFormulas deletes the formulas of a session that has ended
+func (s *Server) forgetFormulas(sessionID string) {
+	bucket := formulaSessionBucketPrefix + sessionID
+	store := s.formulaStore()
+	saved, err := store.List(bucket)
+	if err != nil {
+		log.Printf("Failed to list formulas of session %s: %v", sessionID, err)
+		return
+	}
+	for name := range saved {
+		if err := store.Delete(bucket, name); err != nil {
+			log.Printf("Failed to delete formula %s of session %s: %v", name, sessionID, err)
+		}
+	}
+}
+
+// Formula looks up a saved formula by name, preferring the session's own
+// formulas over persistent ones
+func (s *Server) Formula(ctx context.Context, name string) (types.Formula, bool, error) {
+	for _, scope := range []string{types.FormulaScopeSession, types.FormulaScopePersistent} {
+		bucket, err := s.formulaBucket(ctx, scope)
+		if err != nil {
+			continue
+		}
+
+		data, found, err := s.formulaStore().Get(bucket, name)
+		if err != nil {
+			return types.Formula{}, false, err
+		}
+		if found {
+			var formula types.Formula
+			if err := json.Unmarshal(data, &formula); err != nil {
+				return types.Formula{}, false, fmt.Errorf("corrupt formula %s: %w", name, err)
+			}
+			return formula, true, nil
+		}
+	}
+	return types.Formula{}, false, nil
+}
+
+// Formulas returns the formulas visible to ctx sorted by name. A session
+// formula hides a persistent formula of the same name.
+func (s *Server) Formulas(ctx context.Context) ([]types.Formula, error) {
+	visible := make(map[string]types.Formula)
+	for _, scope := range []string{types.FormulaScopePersistent, types.FormulaScopeSession} {
+		bucket, err := s.formulaBucket(ctx, scope)
+		if err != nil {
+			continue
+		}
+
+		entries, err := s.formulaStore().List(bucket)
+		if err != nil {
+			return nil, err
+		}
+		for name, data := range entries {
+			var formula types.Formula
+			if err := json.Unmarshal(data, &formula); err != nil {
+				return nil, fmt.Errorf("corrupt formula %s: %w", name, err)
+			}
+			visible[name] = formula
+		}
+	}
+
+	formulas := make([]types.Formula, 0, len(visible))
+	for _, formula := range visible {
+		formulas = append(formulas, formula)
+	}
+	sort.Slice(formulas, func(i, j int) bool { return formulas[i].Name < formulas[j].Name })
+	return formulas, nil
+}
+
+// listResources lists the registered resources and the saved formulas of
+// ctx
+func (s *Server) listResources(ctx context.Context) (types.ListResourcesResult, *types.MCPError) {
+	formulas, err := s.Formulas(ctx)
+	if err != nil {
+		return types.ListResourcesResult{}, &types.MCPError{Code: ErrorCodeInternalError, Message: "Internal error", Data: err.Error()}
+	}
+
+	resources := s.registeredResourceList(ctx)
+	for _, formula := range formulas {
+		description := formula.Description
+		if description == "" {
+			description = formula.Expression
+		}
+		resources = append(resources, types.Resource{
+			URI:         FormulaURIPrefix + formula.Name,
+			Name:        formula.Name,
+			Description: description,
+			MimeType:    "application/json",
+		})
+	}
+	return types.ListResourcesResult{Resources: resources}, nil
+}
+
+// listResourceTemplates describes the URIs under which resources can be read
+func (s *Server) listResourceTemplates() types.ListResourceTemplatesResult {
+	return types.ListResourceTemplatesResult{
+		ResourceTemplates: []types.ResourceTemplate{
+			{
+				URITemplate: FormulaURIPrefix + "{name}",
+				Name:        "Saved formula",
+				Description: "A formula saved with save_formula; run it with run_formula",
+				MimeType:    "application/json",
+			},
+		},
+	}
+}
+
+// readResource returns a registered resource or the saved formula named by
+// a formula:// URI
+func (s *Server) readResource(ctx context.Context, params types.ReadResourceParams) (types.ReadResourceResult, *types.MCPError) {
+	if result, found, mcpErr := s.readRegisteredResource(ctx, params.URI); found {
+		return result, mcpErr
+	}
+
+	name := strings.TrimPrefix(params.URI, FormulaURIPrefix)
+	if name == params.URI || name == "" {
+		return types.ReadResourceResult{}, &types.MCPError{Code: ErrorCodeResourceNotFound, Message: "Resource not found", Data: params.URI}
+	}
+
+	formula, found, err := s.Formula(ctx, name)
+	if err != nil {
+		return types.ReadResourceResult{}, &types.MCPError{Code: ErrorCodeInternalError, Message: "Internal error", Data: err.Error()}
+	}
+	if !found {
+		return types.ReadResourceResult{}, &types.MCPError{Code: ErrorCodeResourceNotFound, Message: "Resource not found", Data: params.URI}
+	}
+
+	data, _ := json.Marshal(formula)
+	return types.ReadResourceResult{
+		Contents: []types.EmbeddedResource{
+			{URI: params.URI, MimeType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// healthPath is where load balancers check the transport
+const healthPath = "/health"
+
+// metricsPath serves per-tool usage and connection counts
+const metricsPath = "/metrics"
+
+// setupOpsRoutes adds the operational endpoints, /health and /metrics, to mux
+func (t *StreamableHTTPTransport) setupOpsRoutes(mux *http.ServeMux) {
+	if t.config.HealthEnabled {
+		mux.HandleFunc(healthPath, t.handleHealth)
+	}
+	// Operational metrics endpoint, disabled by default to keep the single-endpoint surface
+	if t.config.MetricsEnabled {
+		mux.HandleFunc(metricsPath, t.handleMetrics)
+	}
+}
+
+// newOpsServer returns the listener serving the operational endpoints on
+// their own port, or nil when they share the MCP port
+func (t *StreamableHTTPTransport) newOpsServer() *http.Server {
+	if t.config.OpsPort == 0 {
+		return nil
+	}
+	mux := http.NewServeMux()
+	t.setupOpsRoutes(mux)
+	return &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", t.config.Host, t.config.OpsPort),
+		Handler: mux,
+	}
+}
+
+// handleHealth reports whether the transport is serving. It answers 200
+// while the transport runs and 503 once it is shutting down, so load
+// balancers stop routing to it before connections are closed.
+func (t *StreamableHTTPTransport) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, statusCode := "ok", http.StatusOK
+	if t.stopping.Load() {
+		status, statusCode = "stopping", http.StatusServiceUnavailable
+	}
+
+	t.sessionsMux.RLock()
+	sessions := len(t.sessions)
+	t.sessionsMux.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	if r.Method == http.MethodHead {
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         status,
+		"sessions":       sessions,
+		"tools":          len(t.mcpServer.toolSchemas()),
+		"uptime_seconds": int(time.Since(t.started).Seconds()),
+		"connections":    t.ConnectionStats(),
+	})
+}
@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+)
+
+// historyBucketPrefix namespaces calculation history per session in the store
+const historyBucketPrefix = "history:"
+
+// SetStore enables calculation history recording into the given store.
+// Entries expire after ttl (zero keeps them until deleted).
+func (s *Server) SetStore(store storage.Store, ttl time.Duration) {
+	s.store = store
+	s.historyTTL = ttl
+}
+
+// recordHistory stores a successful tool call for the session, if persistence is enabled
+func (s *Server) recordHistory(sessionID, tool string, arguments map[string]interface{}, result interface{}) {
+	if s.store == nil || sessionID == "" {
+		return
+	}
+
+	now := time.Now()
+	data, err := json.Marshal(types.HistoryEntry{
+		Tool:      tool,
+		Arguments: arguments,
+		Result:    result,
+		Timestamp: now,
+	})
+	if err != nil {
+		log.Printf("Failed to encode history entry for session %s: %v", sessionID, err)
+		return
+	}
+
+	// Zero-padded nanosecond keys keep entries in chronological order
+	key := fmt.Sprintf("%020d", now.UnixNano())
+	if err := s.store.Put(historyBucketPrefix+sessionID, key, data, s.historyTTL); err != nil {
+		log.Printf("Failed to store history entry for session %s: %v", sessionID, err)
+	}
+}
+
+// History returns the recorded calculations of a session in chronological order
+func (s *Server) History(sessionID string) ([]types.HistoryEntry, error) {
+	if s.store == nil {
+		return nil, fmt.Errorf("calculation history is not enabled")
+	}
+
+	entries, err := s.store.List(historyBucketPrefix + sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	history := make([]types.HistoryEntry, 0, len(keys))
+	for _, key := range keys {
+		var entry types.HistoryEntry
+		if err := json.Unmarshal(entries[key], &entry); err != nil {
+			return nil, fmt.Errorf("corrupt history entry %s: %w", key, err)
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
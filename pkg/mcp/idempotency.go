@@ -0,0 +1,302 @@
+package mcp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for a request that must
+	// not take effect twice when it is retried
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed for a retried request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// DefaultIdempotencyTTL is how long responses are kept for replay when
+	// the transport sets no retention
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// idempotencyBucket holds the responses of keyed requests in the store
+	idempotencyBucket = "idempotency"
+	// maxIdempotencyKeyLength bounds the keys clients may send
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentResponses bounds the responses kept in memory without a
+	// store, and maxIdempotentResponsesPerScope those of one tenant and
+	// credential, so that no client can crowd out the others. The oldest
+	// responses are forgotten first.
+	maxIdempotentResponses         = 10000
+	maxIdempotentResponsesPerScope = 1000
+)
+
+// idempotentResponse is the recorded response of a keyed request
+type idempotentResponse struct {
+	Fingerprint string    `json:"fingerprint"` // Method, path and body of the request
+	Status      int       `json:"status"`
+	ContentType string    `json:"content_type,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Body        []byte    `json:"body"`
+	Expires     time.Time `json:"expires"`
+	scope       string    // Tenant and credential of the request, in memory only
+}
+
+// idempotencyCache remembers the responses of keyed requests, and the
+// fingerprints of those still being served. Responses live in the store
+// when the transport has one, so that retries reaching a restarted server
+// or another replica are recognised.
+type idempotencyCache struct {
+	mu        sync.Mutex
+	inFlight  map[string]string
+	responses map[string]*idempotentResponse
+	order     []recordedKey  // Responses in the order recorded, which is also the order they expire
+	scopes    map[string]int // Responses kept per scope
+}
+
+// recordedKey is a response in the order of idempotencyCache. Responses
+// forgotten early stay in the order until they reach its front.
+type recordedKey struct {
+	key      string
+	response *idempotentResponse
+}
+
+// idempotencyRecorder captures a response while writing it through
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotencyRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// idempotent lets clients retry a state-changing request safely by sending
+// an Idempotency-Key header: the response to the first request with a key
+// is recorded, and a retry with the same key and request gets it replayed
+// instead of running the handler again. A retry arriving while the first
+// request is still served is answered 409 Conflict, and reusing a key for a
+// different request 422 Unprocessable Entity. Keys are scoped to the tenant
+// and credential of the request. Requests without a key, reads and server
+// errors are not recorded.
+func (t *StreamableHTTPTransport) idempotent(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			handler(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key must be at most 255 characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				t.writeBodyTooLarge(w, err)
+				return
+			}
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := sha256.Sum256([]byte(r.Method + " " + r.URL.Path + "\n" + string(body)))
+		request := hex.EncodeToString(fingerprint[:])
+		scope := idempotencyScope(r)
+		cacheKey := idempotencyCacheKey(scope, key)
+
+		// Claim the key before looking for a recorded response, so that
+		// of two concurrent retries only one runs the handler
+		cache := &t.idempotency
+		cache.mu.Lock()
+		if claimed, busy := cache.inFlight[cacheKey]; busy {
+			cache.mu.Unlock()
+			if claimed != request {
+				http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+				return
+			}
+			http.Error(w, "A request with this Idempotency-Key is still being processed", http.StatusConflict)
+			return
+		}
+		if cache.inFlight == nil {
+			cache.inFlight = make(map[string]string)
+		}
+		cache.inFlight[cacheKey] = request
+		cache.mu.Unlock()
+		defer func() {
+			cache.mu.Lock()
+			delete(cache.inFlight, cacheKey)
+			cache.mu.Unlock()
+		}()
+
+		if recorded, found := t.recordedResponse(cacheKey); found {
+			if recorded.Fingerprint != request {
+				http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+				return
+			}
+			if recorded.ContentType != "" {
+				w.Header().Set("Content-Type", recorded.ContentType)
+			}
+			if recorded.Location != "" {
+				w.Header().Set("Location", recorded.Location)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(recorded.Status)
+			w.Write(recorded.Body)
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: w}
+		handler(recorder, r)
+		if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+			// Let the client retry failures of the server for real
+			return
+		}
+		t.recordResponse(cacheKey, &idempotentResponse{
+			Fingerprint: request,
+			Status:      recorder.status,
+			ContentType: w.Header().Get("Content-Type"),
+			Location:    w.Header().Get("Location"),
+			Body:        recorder.body.Bytes(),
+			Expires:     time.Now().Add(t.idempotencyTTL()),
+			scope:       scope,
+		})
+	}
+}
+
+// idempotencyScope is the tenant and credential of the request
+func idempotencyScope(r *http.Request) string {
+	scope := tenantID(r.Context())
+	if credential := CredentialFromContext(r.Context()); credential != nil {
+		scope += "\x00" + credential.Name
+	}
+	return scope
+}
+
+// idempotencyCacheKey scopes a client's key to the tenant and credential
+// of the request, so that clients cannot replay each other's responses
+func idempotencyCacheKey(scope, key string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyTTL is how long recorded responses are replayed
+func (t *StreamableHTTPTransport) idempotencyTTL() time.Duration {
+	if t.config.IdempotencyTTL > 0 {
+		return t.config.IdempotencyTTL
+	}
+	return DefaultIdempotencyTTL
+}
+
+// recordedResponse returns the unexpired response recorded under cacheKey
+func (t *StreamableHTTPTransport) recordedResponse(cacheKey string) (*idempotentResponse, bool) {
+	if t.config.Store != nil {
+		data, found, err := t.config.Store.Get(idempotencyBucket, cacheKey)
+		if err != nil {
+			log.Printf("Failed to load idempotent response: %v", err)
+			return nil, false
+		}
+		if !found {
+			return nil, false
+		}
+		var recorded idempotentResponse
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			return nil, false
+		}
+		return &recorded, true
+	}
+
+	cache := &t.idempotency
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	recorded, found := cache.responses[cacheKey]
+	if !found || time.Now().After(recorded.Expires) {
+		return nil, false
+	}
+	return recorded, true
+}
+
+// recordResponse keeps a response for replay until it expires
+func (t *StreamableHTTPTransport) recordResponse(cacheKey string, recorded *idempotentResponse) {
+	if t.config.Store != nil {
+		data, _ := json.Marshal(recorded)
+		if err := t.config.Store.Put(idempotencyBucket, cacheKey, data, time.Until(recorded.Expires)); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+		return
+	}
+
+	cache := &t.idempotency
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.responses == nil {
+		cache.responses = make(map[string]*idempotentResponse)
+		cache.scopes = make(map[string]int)
+	}
+
+	// Forget expired responses, and make room in the scope and overall
+	now := time.Now()
+	for len(cache.order) > 0 {
+		oldest := cache.order[0]
+		if cache.responses[oldest.key] == oldest.response {
+			if now.Before(oldest.response.Expires) && len(cache.responses) < maxIdempotentResponses {
+				break
+			}
+			cache.forget(oldest.key)
+		}
+		cache.order = cache.order[1:]
+	}
+	if cache.responses[cacheKey] != nil {
+		cache.forget(cacheKey)
+	}
+	if cache.scopes[recorded.scope] >= maxIdempotentResponsesPerScope {
+		for _, entry := range cache.order {
+			if cache.responses[entry.key] == entry.response && entry.response.scope == recorded.scope {
+				cache.forget(entry.key)
+				break
+			}
+		}
+	}
+
+	cache.responses[cacheKey] = recorded
+	cache.scopes[recorded.scope]++
+	cache.order = append(cache.order, recordedKey{key: cacheKey, response: recorded})
+	if len(cache.order) > 2*len(cache.responses) {
+		cache.compact()
+	}
+}
+
+// forget drops the response recorded under key; the caller holds mu
+func (c *idempotencyCache) forget(key string) {
+	response := c.responses[key]
+	delete(c.responses, key)
+	if c.scopes[response.scope]--; c.scopes[response.scope] == 0 {
+		delete(c.scopes, response.scope)
+	}
+}
+
+// compact drops forgotten responses from the order; the caller holds mu
+func (c *idempotencyCache) compact() {
+	order := make([]recordedKey, 0, len(c.responses))
+	for _, entry := range c.order {
+		if c.responses[entry.key] == entry.response {
+			order = append(order, entry)
+		}
+	}
+	c.order = order
+}
@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// jobRetention is how long finished jobs remain available for polling
+const jobRetention = time.Hour
+
+const (
+	// DefaultMaxJobs is how many jobs a job manager keeps, running or
+	// finished, unless SetLimits says otherwise
+	DefaultMaxJobs = 1000
+	// DefaultMaxRunningJobs is how many jobs may be pending or running at once
+	DefaultMaxRunningJobs = 32
+)
+
+// JobNotifier is informed when an asynchronous job finishes
+type JobNotifier interface {
+	Notify(job types.Job) error
+}
+
+// JobManager runs tool calls in the background so clients can submit long
+// computations and poll for, or be notified of, their outcome
+type JobManager struct {
+	server   *Server
+	notifier JobNotifier
+	jobs     map[string]*types.Job
+	mu       sync.RWMutex
+
+	// maxJobs caps the jobs kept, evicting the oldest finished ones first;
+	// maxRunning caps the unfinished jobs, counted by active
+	maxJobs    int
+	maxRunning int
+	active     int
+
+	// Running jobs derive from ctx, cancelled when Shutdown gives up on
+	// them; once closed is set under mu, no more are submitted
+	running sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closed  bool
+}
+
+// NewJobManager creates a job manager for the server's tools.
+// notifier may be nil when no completion notifications are wanted.
+func NewJobManager(server *Server, notifier JobNotifier) *JobManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobManager{
+		server:     server,
+		notifier:   notifier,
+		jobs:       make(map[string]*types.Job),
+		maxJobs:    DefaultMaxJobs,
+		maxRunning: DefaultMaxRunningJobs,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// SetLimits sets how many jobs are kept and how many may run at once.
+// maxJobs is raised to maxRunning if lower, so a running job is never evicted.
+func (m *JobManager) SetLimits(maxJobs, maxRunning int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxRunning = max(maxRunning, 1)
+	m.maxJobs = max(maxJobs, m.maxRunning)
+}
+
+// Submit starts the tool call in the background and returns the pending job.
+// The job keeps the session, tenant, credential and request ID of ctx but not
+// its cancellation.
+func (m *JobManager) Submit(ctx context.Context, params types.CallToolParams) (types.Job, *types.MCPError) {
+	_, schema, exists := m.server.lookupTool(params.Name)
+	if !exists {
+		return types.Job{}, &types.MCPError{
+			Code:    ErrorCodeToolNotFound,
+			Message: "Tool not found",
+			Data:    params.Name,
+		}
+	}
+	// Refuse tools the caller may not call now rather than in a failed job
+	if !m.server.toolAvailable(ctx, schema) {
+		return types.Job{}, &types.MCPError{
+			Code:    ErrorCodeAccessDenied,
+			Message: "Tool is not enabled",
+			Data:    params.Name,
+		}
+	}
+
+	// Reject out-of-range literals now rather than in a failed job
+	arguments, mcpErr := normalizeArguments(params.Arguments)
+	if mcpErr != nil {
+		return types.Job{}, mcpErr
+	}
+	params.Arguments = arguments
+
+	idBytes := make([]byte, 16)
+	rand.Read(idBytes)
+
+	job := &types.Job{
+		ID:        hex.EncodeToString(idBytes),
+		TenantID:  tenantID(ctx),
+		Tool:      params.Name,
+		Arguments: params.Arguments,
+		Status:    types.JobStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return types.Job{}, &types.MCPError{
+			Code:    ErrorCodeServiceUnavailable,
+			Message: "Server shutting down",
+			Data:    "no new jobs are accepted",
+		}
+	}
+	if m.active >= m.maxRunning {
+		m.mu.Unlock()
+		return types.Job{}, &types.MCPError{
+			Code:    ErrorCodeTooManyRequests,
+			Message: "Too many jobs",
+			Data:    fmt.Sprintf("at most %d jobs may run at once; retry once one finishes", m.maxRunning),
+		}
+	}
+	m.pruneLocked()
+	m.jobs[job.ID] = job
+	m.active++
+	snapshot := *job
+	m.running.Add(1)
+	m.mu.Unlock()
+
+	jobCtx := WithTenant(WithSessionID(m.ctx, SessionIDFromContext(ctx)), TenantFromContext(ctx))
+	jobCtx = WithRequestID(WithCredential(jobCtx, CredentialFromContext(ctx)), RequestIDFromContext(ctx))
+	go m.run(jobCtx, job.ID, params)
+
+	return snapshot, nil
+}
+
+// Get returns a snapshot of the job with the given ID
+func (m *JobManager) Get(id string) (types.Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, exists := m.jobs[id]
+	if !exists {
+		return types.Job{}, false
+	}
+	return *job, true
+}
+
+func (m *JobManager) run(ctx context.Context, id string, params types.CallToolParams) {
+	defer m.running.Done()
+	m.setStatus(id, types.JobStatusRunning)
+
+	result, mcpErr := m.server.callTool(ctx, params)
+	completedAt := time.Now()
+
+	m.mu.Lock()
+	job := m.jobs[id]
+	job.CompletedAt = &completedAt
+	switch {
+	case mcpErr != nil:
+		job.Status = types.JobStatusFailed
+		job.Error = mcpErr
+	case result.IsError:
+		job.Status = types.JobStatusFailed
+		job.Result = &result
+	default:
+		job.Status = types.JobStatusCompleted
+		job.Result = &result
+	}
+	m.active--
+	snapshot := *job
+	m.mu.Unlock()
+
+	if m.notifier != nil {
+		if err := m.notifier.Notify(snapshot); err != nil {
+			logf(ctx, "Failed to notify completion of job %s: %v", id, err)
+		}
+	}
+}
+
+// Shutdown stops accepting jobs and waits for the running ones to finish.
+// When ctx is done first, the running jobs are cancelled, failing unless
+// they finish regardless, and ctx's error is returned.
+func (m *JobManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		return ctx.Err()
+	}
+}
+
+func (m *JobManager) setStatus(id, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[id].Status = status
+}
+
+// pruneLocked drops finished jobs older than jobRetention, then the oldest
+// finished jobs until there is room for one more. Unfinished jobs number
+// fewer than maxJobs, so there always is. Callers must hold mu.
+func (m *JobManager) pruneLocked() {
+	cutoff := time.Now().Add(-jobRetention)
+	var finished []*types.Job
+	for id, job := range m.jobs {
+		switch {
+		case job.CompletedAt == nil:
+		case job.CompletedAt.Before(cutoff):
+			delete(m.jobs, id)
+		default:
+			finished = append(finished, job)
+		}
+	}
+
+	excess := len(m.jobs) + 1 - m.maxJobs
+	if excess <= 0 {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].CompletedAt.Before(*finished[j].CompletedAt) })
+	for _, job := range finished[:excess] {
+		delete(m.jobs, job.ID)
+	}
+}
@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"calculator-server/internal/types"
+)
+
+// maxBatchSize is the maximum number of requests in a JSON-RPC batch
+const maxBatchSize = 100
+
+// idPattern finds an "id" member in bodies too malformed to decode
+var idPattern = regexp.MustCompile(`"id"\s*:\s*("(?:[^"\\]|\\.)*"|-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?|null)`)
+
+// parseRequest decodes a JSON-RPC request. On failure it returns the error
+// response to send instead: -32700 for invalid JSON and -32600 for valid JSON
+// that is not a request object, including one with members other than
+// jsonrpc, id, method and params, echoing the request ID whenever it can be
+// found.
+//
+// Numeric IDs are kept as json.Number so they are echoed exactly as sent;
+// decoding them as float64 would turn 1.0 into 1 and lose precision on
+// large integers.
+func parseRequest(data []byte) (types.MCPRequest, *types.MCPResponse) {
+	var req types.MCPRequest
+	if !json.Valid(data) {
+		var probe interface{}
+		err := json.Unmarshal(data, &probe)
+		response := errorResponse(extractRequestID(data), ErrorCodeParseError, "Parse error", err.Error())
+		return req, &response
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		response := errorResponse(extractRequestID(data), ErrorCodeInvalidRequest, "Invalid Request", err.Error())
+		return req, &response
+	}
+
+	// Like any invalid request, one without an ID is answered, with a null ID
+	if mcpErr := validateRequest(req); mcpErr != nil {
+		response := invalidRequestResponse(req, mcpErr)
+		return req, &response
+	}
+	return req, nil
+}
+
+// invalidRequestResponse answers a request that failed validateRequest,
+// echoing its ID only when it is a valid one
+func invalidRequestResponse(req types.MCPRequest, mcpErr *types.MCPError) types.MCPResponse {
+	id := req.ID
+	if !validRequestID(id) {
+		id = nil
+	}
+	return types.MCPResponse{JSONRPC: "2.0", ID: id, Error: mcpErr}
+}
+
+// validateRequest checks the JSON-RPC 2.0 envelope of a request, returning
+// the -32600 error for one that is not a valid request object. Problems with
+// the params of a particular method are left to the method, which answers
+// -32602. A null params is taken as absent.
+func validateRequest(req types.MCPRequest) *types.MCPError {
+	invalid := func(reason string) *types.MCPError {
+		return &types.MCPError{Code: ErrorCodeInvalidRequest, Message: "Invalid Request", Data: reason}
+	}
+	if req.JSONRPC != "2.0" {
+		return invalid(`jsonrpc must be "2.0"`)
+	}
+	if !validRequestID(req.ID) {
+		return invalid("id must be a string or a number")
+	}
+	if req.Method == "" {
+		return invalid("method is required")
+	}
+	if params := bytes.TrimSpace(req.Params); len(params) > 0 && params[0] != '{' && params[0] != '[' && !bytes.Equal(params, []byte("null")) {
+		return invalid("params must be an object or an array")
+	}
+	return nil
+}
+
+// validRequestID reports whether id is absent or a string or number, the
+// only IDs JSON-RPC allows
+func validRequestID(id interface{}) bool {
+	switch id.(type) {
+	case nil, string, json.Number, float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// decodeJSON unmarshals data, decoding numbers as json.Number
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// extractRequestID makes a best-effort attempt to recover the request ID,
+// first from well-formed JSON and then by scanning the raw body
+func extractRequestID(data []byte) interface{} {
+	var envelope map[string]interface{}
+	if json.Valid(data) && decodeJSON(data, &envelope) == nil {
+		return envelope["id"]
+	}
+
+	match := idPattern.FindSubmatch(data)
+	if match == nil {
+		return nil
+	}
+	var id interface{}
+	if decodeJSON(match[1], &id) != nil {
+		return nil
+	}
+	return id
+}
+
+// errorResponse builds a JSON-RPC 2.0 error response
+func errorResponse(id interface{}, code int, message string, data interface{}) types.MCPResponse {
+	return types.MCPResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &types.MCPError{
+			Code:    code,
+			Message: message,
+			Data:    data,
+		},
+	}
+}
+
+// splitBatch reports whether data is a JSON-RPC batch (an array of requests)
+// and returns its members. For an invalid, empty or oversized batch it
+// returns the single error response to send instead.
+func splitBatch(data []byte) ([]json.RawMessage, bool, *types.MCPResponse) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, false, nil
+	}
+
+	var members []json.RawMessage
+	if err := json.Unmarshal(trimmed, &members); err != nil {
+		response := errorResponse(nil, ErrorCodeParseError, "Parse error", err.Error())
+		return nil, true, &response
+	}
+	if len(members) == 0 {
+		response := errorResponse(nil, ErrorCodeInvalidRequest, "Invalid Request", "empty batch")
+		return nil, true, &response
+	}
+	if len(members) > maxBatchSize {
+		response := errorResponse(nil, ErrorCodeInvalidRequest, "Invalid Request", fmt.Sprintf("batch has %d requests (maximum %d)", len(members), maxBatchSize))
+		return nil, true, &response
+	}
+	return members, true, nil
+}
+
+// answerBatch answers the members of a batch in order. Invalid members get
+// their error response; members answer reports as notifications are left
+// out, so the result may be empty.
+func answerBatch(members []json.RawMessage, answer func(types.MCPRequest) (types.MCPResponse, bool)) []types.MCPResponse {
+	responses := make([]types.MCPResponse, 0, len(members))
+	for _, member := range members {
+		req, errResponse := parseRequest(member)
+		if errResponse != nil {
+			responses = append(responses, *errResponse)
+			continue
+		}
+		if response, ok := answer(req); ok {
+			responses = append(responses, response)
+		}
+	}
+	return responses
+}
@@ -0,0 +1,124 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"calculator-server/internal/types"
+)
+
+// DefaultMaxRequestBytes is the largest HTTP request body read when the
+// transport sets no limit
+const DefaultMaxRequestBytes = 1 << 20
+
+// Default shape limits of incoming messages
+const (
+	DefaultMaxJSONDepth   = 64
+	DefaultMaxArrayLength = 100000
+)
+
+// DecodeLimits bound the shape of the JSON messages the server accepts, so
+// a message small enough to be read cannot still exhaust memory or stack
+// when it is decoded
+type DecodeLimits struct {
+	MaxDepth       int // Deepest nesting of arrays and objects; 0 is DefaultMaxJSONDepth
+	MaxArrayLength int // Most items in one array, such as operands or data; 0 is DefaultMaxArrayLength
+}
+
+// SetDecodeLimits sets the shape limits of incoming messages, typically
+// once at startup
+func (s *Server) SetDecodeLimits(limits DecodeLimits) {
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = DefaultMaxJSONDepth
+	}
+	if limits.MaxArrayLength <= 0 {
+		limits.MaxArrayLength = DefaultMaxArrayLength
+	}
+	s.decodeLimits = limits
+}
+
+// checkMessage returns the error response for a message nested too deeply
+// or holding too long an array, or nil when it is within the limits. It
+// scans the raw bytes once, before anything is decoded; malformed JSON is
+// left for the decoder to report.
+func (s *Server) checkMessage(data []byte) *types.MCPResponse {
+	// Items counts the separators seen at each open level; objects are
+	// tracked with -1 as their members are not limited
+	var items []int
+	inString, escaped := false, false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			if len(items) == s.decodeLimits.MaxDepth {
+				return messageLimitResponse(fmt.Sprintf("JSON nested deeper than %d levels", s.decodeLimits.MaxDepth))
+			}
+			if c == '[' {
+				items = append(items, 0)
+			} else {
+				items = append(items, -1)
+			}
+		case ']', '}':
+			if len(items) > 0 {
+				items = items[:len(items)-1]
+			}
+		case ',':
+			if len(items) > 0 && items[len(items)-1] >= 0 {
+				items[len(items)-1]++
+				if items[len(items)-1] >= s.decodeLimits.MaxArrayLength {
+					return messageLimitResponse(fmt.Sprintf("array longer than %d items", s.decodeLimits.MaxArrayLength))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// messageLimitResponse rejects a message exceeding the decode limits as an
+// invalid request. Its ID is not looked for, as that means decoding it.
+func messageLimitResponse(reason string) *types.MCPResponse {
+	response := errorResponse(nil, ErrorCodeInvalidRequest, "Invalid Request", reason)
+	return &response
+}
+
+// bodyLimitMiddleware caps the bytes read from request bodies, so that no
+// endpoint reads an unbounded body into memory
+func (t *StreamableHTTPTransport) bodyLimitMiddleware(handler http.Handler) http.Handler {
+	limit := t.config.MaxRequestBytes
+	if limit <= 0 {
+		limit = DefaultMaxRequestBytes
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// isBodyTooLarge reports whether err came from reading past the body limit
+func isBodyTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
+
+// writeBodyTooLarge answers a request whose body exceeds the limit with
+// 413 Request Entity Too Large and a JSON-RPC error body
+func (t *StreamableHTTPTransport) writeBodyTooLarge(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(errorResponse(nil, ErrorCodeInvalidRequest, "Invalid Request", err.Error()))
+}
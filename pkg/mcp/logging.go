@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// NotificationMessage carries a log event to a client that enabled logging
+const NotificationMessage = "notifications/message"
+
+// DefaultSlowCallThreshold is how long a tool call runs before it is logged
+// as a slow calculation
+const DefaultSlowCallThreshold = time.Second
+
+// LogLevel is a syslog severity (RFC 5424), as used by MCP logging
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogNotice
+	LogWarning
+	LogError
+	LogCritical
+	LogAlert
+	LogEmergency
+)
+
+var logLevelNames = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+func (l LogLevel) String() string {
+	if l < LogDebug || l > LogEmergency {
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+// ParseLogLevel returns the level with the given MCP name
+func ParseLogLevel(name string) (LogLevel, error) {
+	for level, levelName := range logLevelNames {
+		if levelName == name {
+			return LogLevel(level), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported log level: %s. Supported levels: %v", name, logLevelNames)
+}
+
+// setLogLevel answers logging/setLevel. The level is kept for the session,
+// so requests outside one are accepted but change nothing.
+func (s *Server) setLogLevel(ctx context.Context, params types.SetLevelParams) *types.MCPError {
+	level, err := ParseLogLevel(params.Level)
+	if err != nil {
+		return &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Invalid log level", Data: err.Error()}
+	}
+	sessionID := SessionIDFromContext(ctx)
+	if sessionID == "" {
+		return nil
+	}
+
+	s.logLevelsMu.Lock()
+	defer s.logLevelsMu.Unlock()
+	s.logLevels[sessionID] = level
+	return nil
+}
+
+// LogLevel returns the minimum level a session asked to be sent, and false
+// when it has not enabled logging with logging/setLevel
+func (s *Server) LogLevel(sessionID string) (LogLevel, bool) {
+	s.logLevelsMu.RLock()
+	defer s.logLevelsMu.RUnlock()
+	level, ok := s.logLevels[sessionID]
+	return level, ok
+}
+
+// SetSlowCallThreshold sets how long a tool call runs before it is logged as
+// a slow calculation; zero restores DefaultSlowCallThreshold
+func (s *Server) SetSlowCallThreshold(threshold time.Duration) {
+	if threshold <= 0 {
+		threshold = DefaultSlowCallThreshold
+	}
+	s.slowCallThreshold = threshold
+}
+
+// Log sends a notifications/message to the client of the request when its
+// session enabled logging at level or below. The message travels with the
+// request's notifications when it has any, such as on the SSE stream of a
+// streamed call or over stdio, and otherwise on the session's standalone
+// stream. Sessions that never sent logging/setLevel are sent nothing.
+func (s *Server) Log(ctx context.Context, level LogLevel, logger string, data interface{}) {
+	sessionID := SessionIDFromContext(ctx)
+	if sessionID == "" {
+		return
+	}
+	minimum, enabled := s.LogLevel(sessionID)
+	if !enabled || level < minimum || !s.acceptsNotifications(sessionID) {
+		return
+	}
+
+	notification := types.MCPNotification{
+		JSONRPC: "2.0",
+		Method:  NotificationMessage,
+		Params:  types.LoggingMessageParams{Level: level.String(), Logger: logger, Data: data},
+	}
+	if notify := NotifierFromContext(ctx); notify != nil {
+		notify(notification)
+		return
+	}
+
+	s.subscribersMu.Lock()
+	var sinks []NotifyFunc
+	for _, sub := range s.subscribers {
+		if sub.sessionID == sessionID {
+			sinks = append(sinks, sub.notify)
+		}
+	}
+	s.subscribersMu.Unlock()
+	for _, notify := range sinks {
+		notify(notification)
+	}
+}
+
+// logToolEvent logs an event of a tool call under the "tools" logger
+func (s *Server) logToolEvent(ctx context.Context, level LogLevel, event, tool string, details map[string]interface{}) {
+	data := map[string]interface{}{"event": event, "tool": tool}
+	for key, value := range details {
+		data[key] = value
+	}
+	s.Log(ctx, level, "tools", data)
+}
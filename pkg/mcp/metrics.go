@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// MetricsURI is the administrative resource holding the metrics served on
+// the metrics endpoint
+const MetricsURI = "calculator://metrics"
+
+// latencySampleSize is the number of most recent latencies kept per tool
+// for percentile estimation
+const latencySampleSize = 1024
+
+// MetricsRegistry tracks per-tool invocation counts, error counts and
+// latency percentiles, and gathers the statistics of other components
+type MetricsRegistry struct {
+	mu      sync.Mutex
+	tools   map[string]*toolStats
+	sources map[string]func() interface{}
+}
+
+type toolStats struct {
+	invocations int64
+	errors      int64
+	latencies   []time.Duration // ring buffer of recent latencies
+	next        int
+	lastInvoked time.Time
+}
+
+// NewMetricsRegistry creates an empty metrics registry
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{
+		tools:   make(map[string]*toolStats),
+		sources: make(map[string]func() interface{}),
+	}
+}
+
+// AddSource publishes the statistics returned by source on the metrics
+// endpoint under name, next to the tool metrics
+func (m *MetricsRegistry) AddSource(name string, source func() interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sources[name] = source
+}
+
+// Sources returns the current statistics of every added source by name
+func (m *MetricsRegistry) Sources() map[string]interface{} {
+	m.mu.Lock()
+	sources := make(map[string]func() interface{}, len(m.sources))
+	for name, source := range m.sources {
+		sources[name] = source
+	}
+	m.mu.Unlock()
+
+	stats := make(map[string]interface{}, len(sources))
+	for name, source := range sources {
+		stats[name] = source()
+	}
+	return stats
+}
+
+// Record registers a single tool invocation with its latency and outcome
+func (m *MetricsRegistry) Record(tool string, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, exists := m.tools[tool]
+	if !exists {
+		stats = &toolStats{latencies: make([]time.Duration, 0, latencySampleSize)}
+		m.tools[tool] = stats
+	}
+
+	stats.invocations++
+	if err != nil {
+		stats.errors++
+	}
+	stats.lastInvoked = time.Now()
+
+	if len(stats.latencies) < latencySampleSize {
+		stats.latencies = append(stats.latencies, latency)
+	} else {
+		stats.latencies[stats.next] = latency
+		stats.next = (stats.next + 1) % latencySampleSize
+	}
+}
+
+// Snapshot returns the current metrics for every invoked tool, sorted by name
+func (m *MetricsRegistry) Snapshot() []types.ToolMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make([]types.ToolMetrics, 0, len(m.tools))
+	for name, stats := range m.tools {
+		sorted := make([]time.Duration, len(stats.latencies))
+		copy(sorted, stats.latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		snapshot = append(snapshot, types.ToolMetrics{
+			Tool:         name,
+			Invocations:  stats.invocations,
+			Errors:       stats.errors,
+			LatencyP50Ms: latencyPercentile(sorted, 0.50),
+			LatencyP95Ms: latencyPercentile(sorted, 0.95),
+			LatencyP99Ms: latencyPercentile(sorted, 0.99),
+			LastInvoked:  stats.lastInvoked,
+		})
+	}
+
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Tool < snapshot[j].Tool })
+	return snapshot
+}
+
+// latencyPercentile returns the nearest-rank percentile of sorted latencies in milliseconds
+func latencyPercentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(p*float64(len(sorted)) + 0.5)
+	if index > 0 {
+		index--
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return float64(sorted[index]) / float64(time.Millisecond)
+}
+
+// ReadMetrics returns the tool metrics and the statistics of every added
+// source, as the metrics endpoint serves them
+func (s *Server) ReadMetrics(ctx context.Context) (string, error) {
+	metrics := s.metrics.Sources()
+	metrics["tools"] = s.metrics.Snapshot()
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Strictness decides how literally tool arguments are taken
+type Strictness string
+
+const (
+	// StrictnessLenient canonicalizes arguments before they are validated:
+	// strings are trimmed, enum values and aliased values are matched
+	// regardless of case, and numbers sent as strings become numbers
+	StrictnessLenient Strictness = "lenient"
+	// StrictnessStrict passes arguments to the tools exactly as sent
+	StrictnessStrict Strictness = "strict"
+)
+
+// ValueResolver maps a value of an argument onto its canonical spelling,
+// reporting whether it knows the value
+type ValueResolver func(value string) (string, bool)
+
+// WithValueAliases canonicalizes the named string arguments with resolve in
+// lenient mode, e.g. to accept unit names spelled out
+func WithValueAliases(resolve ValueResolver, arguments ...string) ToolOption {
+	return func(schema *ToolSchema) {
+		if schema.Aliases == nil {
+			schema.Aliases = make(map[string]ValueResolver)
+		}
+		for _, argument := range arguments {
+			schema.Aliases[argument] = resolve
+		}
+	}
+}
+
+// argumentStrictness holds the server's Strictness
+type argumentStrictness struct {
+	strict atomic.Bool
+}
+
+// SetStrictness sets how literally tool arguments are taken. Servers are
+// lenient until set otherwise.
+func (s *Server) SetStrictness(strictness Strictness) {
+	s.strictness.strict.Store(strictness == StrictnessStrict)
+}
+
+// lenient reports whether arguments are canonicalized
+func (s *Server) lenient() bool {
+	return !s.strictness.strict.Load()
+}
+
+// logArgumentsCanonicalized records which arguments of a call were rewritten
+func (s *Server) logArgumentsCanonicalized(ctx context.Context, tool string, arguments []string) {
+	s.logToolEvent(ctx, LogDebug, "arguments_canonicalized", tool, map[string]interface{}{"arguments": arguments})
+}
+
+// canonicalizeArguments rewrites the arguments in place to the spelling the
+// schema declares, returning the names of those it changed, sorted
+func canonicalizeArguments(schema ToolSchema, arguments map[string]interface{}) []string {
+	properties, _ := schema.InputSchema["properties"].(map[string]interface{})
+	var changed []string
+	for name, value := range arguments {
+		property, _ := properties[name].(map[string]interface{})
+		canonical, ok := canonicalValue(property, schema.Aliases[name], value)
+		if ok {
+			arguments[name] = canonical
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// canonicalizeFields does what canonicalizeArguments does for arguments
+// still encoded as JSON, as typed tools receive them. Only fields holding
+// strings can change, so the others are not decoded.
+func canonicalizeFields(schema ToolSchema, fields map[string]json.RawMessage) []string {
+	properties, _ := schema.InputSchema["properties"].(map[string]interface{})
+	var changed []string
+	for name, field := range fields {
+		if bytes.IndexByte(field, '"') < 0 {
+			continue
+		}
+		var value interface{}
+		if err := decodeJSON(field, &value); err != nil {
+			continue
+		}
+		normalized, err := normalizeValue(value, name)
+		if err != nil {
+			continue
+		}
+		property, _ := properties[name].(map[string]interface{})
+		if canonical, ok := canonicalValue(property, schema.Aliases[name], normalized); ok {
+			if encoded, err := json.Marshal(canonical); err == nil {
+				fields[name] = encoded
+				changed = append(changed, name)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// canonicalValue returns the canonical form of value under its property
+// schema, and whether that differs from value
+func canonicalValue(property map[string]interface{}, resolve ValueResolver, value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		return canonicalString(property, resolve, v)
+	case []interface{}:
+		items, _ := property["items"].(map[string]interface{})
+		var canonical []interface{}
+		for i, item := range v {
+			if c, ok := canonicalValue(items, resolve, item); ok {
+				if canonical == nil {
+					canonical = append([]interface{}(nil), v...)
+				}
+				canonical[i] = c
+			}
+		}
+		return canonical, canonical != nil
+	case map[string]interface{}:
+		properties, _ := property["properties"].(map[string]interface{})
+		additional, _ := property["additionalProperties"].(map[string]interface{})
+		var canonical map[string]interface{}
+		for key, item := range v {
+			nested, declared := properties[key].(map[string]interface{})
+			if !declared {
+				nested = additional
+			}
+			if c, ok := canonicalValue(nested, nil, item); ok {
+				if canonical == nil {
+					canonical = make(map[string]interface{}, len(v))
+					for k, original := range v {
+						canonical[k] = original
+					}
+				}
+				canonical[key] = c
+			}
+		}
+		return canonical, canonical != nil
+	}
+	return value, false
+}
+
+// canonicalString trims a string and turns it into a number, an enum value
+// or an aliased value when the property asks for one
+func canonicalString(property map[string]interface{}, resolve ValueResolver, value string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(value)
+
+	if acceptsNumber(property) {
+		if number, err := strconv.ParseFloat(trimmed, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+			return number, true
+		}
+	}
+	for _, allowed := range stringList(property["enum"]) {
+		if strings.EqualFold(allowed, trimmed) {
+			return allowed, allowed != value
+		}
+	}
+	if resolve != nil {
+		if canonical, ok := resolve(trimmed); ok {
+			return canonical, canonical != value
+		}
+	}
+	return trimmed, trimmed != value
+}
+
+// acceptsNumber reports whether the property is a number but not a string
+func acceptsNumber(property map[string]interface{}) bool {
+	if name, ok := property["type"].(string); ok {
+		return name == "number" || name == "integer"
+	}
+	number, text := false, false
+	for _, name := range stringList(property["type"]) {
+		number = number || name == "number" || name == "integer"
+		text = text || name == "string"
+	}
+	return number && !text
+}
+
+// stringList returns the strings of a schema keyword's list, which is a
+// []string in schemas built in Go and a []interface{} in decoded ones
+func stringList(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		strs := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	}
+	return nil
+}
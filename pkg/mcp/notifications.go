@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+
+	"calculator-server/internal/types"
+)
+
+// Server-initiated notification methods
+const (
+	NotificationToolListChanged = "notifications/tools/list_changed"
+	NotificationResourceUpdated = "notifications/resources/updated"
+	NotificationProgress        = "notifications/progress"
+)
+
+// NotificationInitialized is sent by the client once it has processed the
+// initialize response
+const NotificationInitialized = "notifications/initialized"
+
+// handleNotification routes a notification from the client. Unknown
+// notifications are ignored, as JSON-RPC gives no way to report them.
+func (s *Server) handleNotification(ctx context.Context, req types.MCPRequest) {
+	switch req.Method {
+	case NotificationInitialized:
+		s.markInitialized(ctx)
+	case NotificationCancelled, MethodCancelRequest:
+		s.cancelRequest(ctx, req.Params)
+	}
+}
+
+// subscriber is a sink for broadcast notifications and the session it
+// delivers to, or "" for one outside any session
+type subscriber struct {
+	sessionID string
+	notify    NotifyFunc
+}
+
+// Subscribe registers a sink for notifications broadcast with Notify. The
+// returned function removes the subscription. A broadcast calls its sinks
+// concurrently, so a sink must not touch state it shares with another
+// without synchronization.
+func (s *Server) Subscribe(notify NotifyFunc) (unsubscribe func()) {
+	return s.SubscribeSession("", notify)
+}
+
+// SubscribeSession registers the notification sink of a session, such as a
+// stdio connection or a standalone SSE stream. Broadcasts skip it while its
+// client has sent initialize but not yet notifications/initialized. As with
+// Subscribe, the sink may be called concurrently with the other sinks of a
+// broadcast, though never with itself for the same broadcast.
+func (s *Server) SubscribeSession(sessionID string, notify NotifyFunc) (unsubscribe func()) {
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+
+	id := s.nextSubscriber
+	s.nextSubscriber++
+	s.subscribers[id] = subscriber{sessionID: sessionID, notify: notify}
+
+	return func() {
+		s.subscribersMu.Lock()
+		defer s.subscribersMu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+// Notify broadcasts a notification to every subscribed client ready for
+// it. It is a no-op when no client is connected.
+func (s *Server) Notify(method string, params interface{}) {
+	s.notifySessions(method, params, func(string) bool { return true })
+}
+
+// notifySessions sends a notification to the subscribed clients ready for
+// it whose session is selected
+func (s *Server) notifySessions(method string, params interface{}, selected func(sessionID string) bool) {
+	s.subscribersMu.Lock()
+	sinks := make([]NotifyFunc, 0, len(s.subscribers))
+	for _, sub := range s.subscribers {
+		if selected(sub.sessionID) && s.acceptsNotifications(sub.sessionID) {
+			sinks = append(sinks, sub.notify)
+		}
+	}
+	s.subscribersMu.Unlock()
+
+	notification := types.MCPNotification{JSONRPC: "2.0", Method: method, Params: params}
+	if len(sinks) == 1 {
+		sinks[0](notification)
+		return
+	}
+	// Sinks are called side by side, so a client slow to take the
+	// notification delays the others by one write timeout at most, not one
+	// per slow client. Waiting for all keeps successive notifications in
+	// order.
+	var delivered sync.WaitGroup
+	for _, notify := range sinks {
+		delivered.Add(1)
+		go func(notify NotifyFunc) {
+			defer delivered.Done()
+			notify(notification)
+		}(notify)
+	}
+	delivered.Wait()
+}
+
+// notifyToolListChanged tells clients to fetch tools/list again
+func (s *Server) notifyToolListChanged() {
+	s.Notify(NotificationToolListChanged, nil)
+}
@@ -0,0 +1,437 @@
+package mcp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// protectedResourcePath serves the OAuth protected resource metadata (RFC 9728)
+const protectedResourcePath = "/.well-known/oauth-protected-resource"
+
+const (
+	// oauthClockSkew is the tolerance for the exp and nbf claims of tokens
+	oauthClockSkew = time.Minute
+	// jwksRefreshInterval limits how often unknown key IDs refetch the keys
+	jwksRefreshInterval = time.Minute
+	// jwksRetryInterval is the wait after a failed fetch of the keys, doubled
+	// for each further failure up to jwksRefreshInterval
+	jwksRetryInterval = 5 * time.Second
+	// oauthFetchTimeout bounds requests to the authorization server
+	oauthFetchTimeout = 10 * time.Second
+)
+
+// OAuthConfig makes the streamable HTTP transport an OAuth 2.1 resource
+// server as the MCP authorization specification describes: requests must
+// carry a JWT access token issued for it by the authorization server
+type OAuthConfig struct {
+	ResourceURL string // Canonical URI of the MCP endpoint, e.g. https://calc.example.com/mcp
+	Issuer      string // Authorization server, which tokens must name as iss
+	Audience    string // aud tokens must contain; empty uses ResourceURL
+	JWKSURL     string // Signing keys of the issuer; empty discovers them from its metadata
+}
+
+// audience returns the aud that tokens must contain
+func (c *OAuthConfig) audience() string {
+	if c.Audience != "" {
+		return c.Audience
+	}
+	return c.ResourceURL
+}
+
+// metadataURL returns where clients find the protected resource metadata:
+// the well-known path inserted between the host and the path of the resource
+func (c *OAuthConfig) metadataURL() string {
+	resource, err := url.Parse(c.ResourceURL)
+	if err != nil {
+		return protectedResourcePath
+	}
+	return resource.Scheme + "://" + resource.Host + protectedResourcePath + strings.TrimSuffix(resource.Path, "/")
+}
+
+var (
+	errTokenExpired = errors.New("token has expired")
+	errTokenInvalid = errors.New("invalid token")
+)
+
+// tokenClaims are the registered claims checked on access tokens
+type tokenClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  json.RawMessage `json:"aud"` // A string or an array of strings
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+}
+
+// hasAudience reports whether aud names the audience
+func (c *tokenClaims) hasAudience(audience string) bool {
+	var single string
+	if json.Unmarshal(c.Audience, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(c.Audience, &list) == nil {
+		for _, aud := range list {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenValidator checks JWT access tokens against the signing keys of the
+// authorization server, which are fetched when first needed and again when
+// a token names a key that is not known yet
+type tokenValidator struct {
+	config *OAuthConfig
+	client *http.Client
+
+	mu         sync.Mutex
+	jwksURL    string
+	keys       map[string]crypto.PublicKey // Last good key set, kept when a refetch fails
+	fetched    time.Time                   // When the last fetch ended
+	failures   int                         // Fetches that failed since the last success
+	fetchErr   error                       // Why the last fetch failed
+	refreshing chan struct{}               // Closed when the fetch in progress ends; nil when none is
+}
+
+func newTokenValidator(config *OAuthConfig) *tokenValidator {
+	return &tokenValidator{
+		config:  config,
+		client:  &http.Client{Timeout: oauthFetchTimeout},
+		jwksURL: config.JWKSURL,
+	}
+}
+
+// validate checks the signature, issuer, audience and lifetime of a token
+func (v *tokenValidator) validate(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", errTokenInvalid)
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", errTokenInvalid)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", errTokenInvalid)
+	}
+	key, err := v.key(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", errTokenInvalid)
+	}
+	if claims.Issuer != v.config.Issuer {
+		return nil, fmt.Errorf("%w: issued by %q", errTokenInvalid, claims.Issuer)
+	}
+	if !claims.hasAudience(v.config.audience()) {
+		return nil, fmt.Errorf("%w: not issued for this resource", errTokenInvalid)
+	}
+	now := time.Now()
+	if claims.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: no expiry", errTokenInvalid)
+	}
+	if now.After(time.Unix(int64(*claims.ExpiresAt), 0).Add(oauthClockSkew)) {
+		return nil, errTokenExpired
+	}
+	if claims.NotBefore != nil && now.Add(oauthClockSkew).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return nil, fmt.Errorf("%w: not valid yet", errTokenInvalid)
+	}
+	return &claims, nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// verifySignature checks a JWS signature. Only asymmetric algorithms are
+// accepted, since the resource server holds no shared secret.
+func verifySignature(algorithm string, key crypto.PublicKey, signed string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch algorithm {
+	case "RS256", "PS256":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s needs an RSA key", errTokenInvalid, algorithm)
+		}
+		var err error
+		if algorithm == "RS256" {
+			err = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature)
+		} else {
+			err = rsa.VerifyPSS(publicKey, crypto.SHA256, digest[:], signature, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: bad signature", errTokenInvalid)
+		}
+	case "ES256":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve != elliptic.P256() || len(signature) != 64 {
+			return fmt.Errorf("%w: ES256 needs a P-256 key", errTokenInvalid)
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, digest[:], r, s) {
+			return fmt.Errorf("%w: bad signature", errTokenInvalid)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", errTokenInvalid, algorithm)
+	}
+	return nil
+}
+
+// key returns the signing key with the ID, refetching the keys when it is
+// unknown. A token without a key ID is checked with the only key there is.
+// One fetch runs at a time, without holding v.mu, and requests arriving
+// meanwhile wait for its result instead of fetching again. Fetches are
+// spaced by fetchBackoff, so tokens with made-up key IDs or an unreachable
+// authorization server do not cause a fetch per request.
+func (v *tokenValidator) key(keyID string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	if key, ok := v.lookup(keyID); ok {
+		v.mu.Unlock()
+		return key, nil
+	}
+	done := v.refreshing
+	if done == nil {
+		if !v.fetched.IsZero() && time.Since(v.fetched) < v.fetchBackoff() {
+			defer v.mu.Unlock()
+			return nil, v.unknownKey(keyID)
+		}
+		done = make(chan struct{})
+		v.refreshing = done
+		jwksURL := v.jwksURL
+		v.mu.Unlock()
+
+		jwksURL, keys, err := v.fetchKeys(jwksURL)
+
+		v.mu.Lock()
+		v.fetched = time.Now()
+		if err != nil {
+			v.failures++
+			v.fetchErr = err
+		} else {
+			v.jwksURL, v.keys = jwksURL, keys
+			v.failures, v.fetchErr = 0, nil
+		}
+		v.refreshing = nil
+		close(done)
+	} else {
+		v.mu.Unlock()
+		<-done
+		v.mu.Lock()
+	}
+	defer v.mu.Unlock()
+
+	if key, ok := v.lookup(keyID); ok {
+		return key, nil
+	}
+	return nil, v.unknownKey(keyID)
+}
+
+// fetchBackoff is how long after a fetch the next may start: a minute
+// after a success, and after failures a few seconds, doubling with each
+// one up to that minute; the caller holds v.mu
+func (v *tokenValidator) fetchBackoff() time.Duration {
+	if v.failures == 0 || v.failures > 8 {
+		return jwksRefreshInterval
+	}
+	return min(jwksRetryInterval<<(v.failures-1), jwksRefreshInterval)
+}
+
+// unknownKey reports a key ID that is not among the keys. Without any
+// keys, because they could not be fetched, the fetch error is reported
+// instead, as the token may well be valid; the caller holds v.mu.
+func (v *tokenValidator) unknownKey(keyID string) error {
+	if v.keys == nil && v.fetchErr != nil {
+		return v.fetchErr
+	}
+	return fmt.Errorf("%w: unknown signing key %q", errTokenInvalid, keyID)
+}
+
+// lookup finds a cached key; the caller holds v.mu
+func (v *tokenValidator) lookup(keyID string) (crypto.PublicKey, bool) {
+	if keyID == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[keyID]
+	return key, ok && keyID != ""
+}
+
+// fetchKeys fetches the issuer's JWKS from jwksURL, discovering its
+// location from the authorization server metadata (RFC 8414, then OpenID
+// Connect discovery) when it is not known yet. It returns the location
+// along with the keys.
+func (v *tokenValidator) fetchKeys(jwksURL string) (string, map[string]crypto.PublicKey, error) {
+	if jwksURL == "" {
+		issuer := strings.TrimSuffix(v.config.Issuer, "/")
+		for _, metadataURL := range []string{issuer + "/.well-known/oauth-authorization-server", issuer + "/.well-known/openid-configuration"} {
+			var metadata struct {
+				JWKSURI string `json:"jwks_uri"`
+			}
+			if v.getJSON(metadataURL, &metadata) == nil && metadata.JWKSURI != "" {
+				jwksURL = metadata.JWKSURI
+				break
+			}
+		}
+		if jwksURL == "" {
+			return "", nil, fmt.Errorf("no jwks_uri in the metadata of authorization server %s", v.config.Issuer)
+		}
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(jwksURL, &jwks); err != nil {
+		return "", nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if key, err := jwk.publicKey(); err == nil && (jwk.Use == "" || jwk.Use == "sig") {
+			keys[jwk.KeyID] = key
+		}
+	}
+	return jwksURL, keys, nil
+}
+
+// getJSON fetches and decodes a JSON document of the authorization server
+func (v *tokenValidator) getJSON(url string, target interface{}) error {
+	resp, err := v.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// jsonWebKey is a public key of a JWKS (RFC 7517)
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// publicKey decodes an RSA or P-256 key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := func(value string) (*big.Int, error) {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(data) == 0 {
+			return nil, fmt.Errorf("malformed key parameter")
+		}
+		return new(big.Int).SetBytes(data), nil
+	}
+	switch {
+	case k.KeyType == "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, fmt.Errorf("malformed RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case k.KeyType == "EC" && k.Curve == "P-256":
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on P-256")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.KeyType)
+}
+
+// handleProtectedResourceMetadata tells clients which authorization server
+// issues tokens for this resource
+func (t *StreamableHTTPTransport) handleProtectedResourceMetadata(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"resource":                 t.config.OAuth.ResourceURL,
+		"authorization_servers":    []string{t.config.OAuth.Issuer},
+		"bearer_methods_supported": []string{"header"},
+	})
+}
+
+// oauthMiddleware rejects requests without a valid access token with 401
+// Unauthorized, a WWW-Authenticate challenge pointing to the protected
+// resource metadata and a JSON-RPC error body
+func (t *StreamableHTTPTransport) oauthMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The metadata is how clients learn to authenticate, the admin API
+		// has its own token, and load balancers carry no credentials
+		if strings.HasPrefix(r.URL.Path, protectedResourcePath) || strings.HasPrefix(r.URL.Path, adminPathPrefix) || r.URL.Path == healthPath || IsAdmin(r.Context()) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		challenge := fmt.Sprintf(`Bearer resource_metadata=%q`, t.config.OAuth.metadataURL())
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", challenge)
+			t.writeErrorResponse(w, nil, ErrorCodeAuthenticationRequired, "Authentication required", "send an OAuth access token as a bearer token")
+			return
+		}
+
+		if _, err := t.tokens.validate(token); err != nil {
+			code := ErrorCodeTokenInvalid
+			if errors.Is(err, errTokenExpired) {
+				code = ErrorCodeTokenExpired
+			} else if !errors.Is(err, errTokenInvalid) {
+				// The authorization server could not be reached
+				logf(r.Context(), "Access token validation failed: %v", err)
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`%s, error="invalid_token", error_description=%q`, challenge, err.Error()))
+			t.writeErrorResponse(w, nil, code, "Invalid access token", err.Error())
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
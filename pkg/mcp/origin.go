@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultAllowedOrigins are the origins browsers may send requests from
+// when the transport sets none: pages served from this machine, on any port
+var DefaultAllowedOrigins = []string{
+	"http://localhost", "https://localhost",
+	"http://127.0.0.1", "https://127.0.0.1",
+	"http://[::1]", "https://[::1]",
+}
+
+// allowedOrigin is a parsed entry of the origin allow-list
+type allowedOrigin struct {
+	scheme string
+	host   string
+	port   string // "" matches any port
+}
+
+// parseAllowedOrigins parses origins such as "https://app.example.com" or
+// "http://localhost:3000". An origin without a port matches every port of
+// its host, "null" matches the opaque origin of sandboxed pages and files,
+// and "*" allows every origin.
+func parseAllowedOrigins(origins []string) (all bool, allowed []allowedOrigin, err error) {
+	for _, origin := range origins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			all = true
+			continue
+		}
+		parsed, ok := parseOrigin(origin)
+		if !ok {
+			return false, nil, fmt.Errorf("invalid allowed origin %q", origin)
+		}
+		allowed = append(allowed, parsed)
+	}
+	return all, allowed, nil
+}
+
+// parseOrigin parses an origin, a scheme and host with an optional port and
+// nothing else
+func parseOrigin(origin string) (allowedOrigin, bool) {
+	if origin == "null" {
+		return allowedOrigin{scheme: "null"}, true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" ||
+		u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return allowedOrigin{}, false
+	}
+	return allowedOrigin{scheme: u.Scheme, host: strings.ToLower(u.Hostname()), port: u.Port()}, true
+}
+
+// originAllowed reports whether requests from the origin may be served
+func (t *StreamableHTTPTransport) originAllowed(origin string) bool {
+	if t.allOrigins {
+		return true
+	}
+	parsed, ok := parseOrigin(origin)
+	if !ok {
+		return false
+	}
+	if parsed.port == "" {
+		// Browsers leave out the default port
+		parsed.port = map[string]string{"http": "80", "https": "443"}[parsed.scheme]
+	}
+	for _, allowed := range t.allowedOrigins {
+		if allowed.scheme == parsed.scheme && allowed.host == parsed.host && (allowed.port == "" || allowed.port == parsed.port) {
+			return true
+		}
+	}
+	return false
+}
+
+// originMiddleware protects local servers from DNS rebinding, where a
+// malicious page has its host name resolve to 127.0.0.1 and then reaches
+// the server as a same-origin page. Requests carrying an Origin that is
+// not allowed are rejected with 403 Forbidden. Requests without an Origin
+// come from clients other than browsers and pass.
+func (t *StreamableHTTPTransport) originMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && !t.originAllowed(origin) {
+			log.Printf("Rejected request from origin %q", origin)
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
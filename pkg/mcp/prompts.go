@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+
+	"calculator-server/internal/types"
+)
+
+// PromptHandler renders a prompt from the client's argument values. Required
+// arguments have already been checked to be present.
+type PromptHandler func(ctx context.Context, arguments map[string]string) (types.GetPromptResult, error)
+
+// registeredPrompt is a prompt template exposed by RegisterPrompt
+type registeredPrompt struct {
+	prompt  types.Prompt
+	handler PromptHandler
+}
+
+// RegisterPrompt exposes a prompt template in prompts/list and prompts/get
+func (s *Server) RegisterPrompt(name, description string, arguments []types.PromptArgument, handler PromptHandler) {
+	s.prompts[name] = registeredPrompt{
+		prompt: types.Prompt{
+			Name:        name,
+			Description: description,
+			Arguments:   arguments,
+		},
+		handler: handler,
+	}
+}
+
+// listPrompts returns the registered prompts sorted by name
+func (s *Server) listPrompts() types.ListPromptsResult {
+	prompts := make([]types.Prompt, 0, len(s.prompts))
+	for _, registered := range s.prompts {
+		prompts = append(prompts, registered.prompt)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+	return types.ListPromptsResult{Prompts: prompts}
+}
+
+// getPrompt renders the named prompt with the client's arguments
+func (s *Server) getPrompt(ctx context.Context, params types.GetPromptParams) (types.GetPromptResult, *types.MCPError) {
+	registered, ok := s.prompts[params.Name]
+	if !ok {
+		return types.GetPromptResult{}, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Unknown prompt", Data: params.Name}
+	}
+
+	for _, argument := range registered.prompt.Arguments {
+		if argument.Required && params.Arguments[argument.Name] == "" {
+			return types.GetPromptResult{}, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Missing required argument", Data: argument.Name}
+		}
+	}
+
+	arguments := params.Arguments
+	if arguments == nil {
+		arguments = map[string]string{}
+	}
+	result, err := registered.handler(ctx, arguments)
+	if err != nil {
+		return types.GetPromptResult{}, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Invalid prompt arguments", Data: err.Error()}
+	}
+	return result, nil
+}
@@ -1,22 +1,34 @@
 package mcp
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"calculator-server/internal/storage"
 	"calculator-server/internal/types"
 )
 
 const (
 	// Standard JSON-RPC 2.0 error codes
+	ErrorCodeParseError     = -32700
 	ErrorCodeInvalidRequest = -32600
 	ErrorCodeMethodNotFound = -32601
 	ErrorCodeInvalidParams  = -32602
 	ErrorCodeInternalError  = -32603
 
+	// ErrorCodeRequestCancelled answers a request the client cancelled, where
+	// the transport still owes it a response
+	ErrorCodeRequestCancelled = -32800
+
 	// Application-specific error code ranges for semantic HTTP status mapping
 	// Authentication errors (-1000 to -1099) → HTTP 401 Unauthorized
 	ErrorCodeAuthenticationRequired = -1000
@@ -63,82 +75,464 @@ const (
 )
 
 type Server struct {
-	tools   map[string]ToolHandler
-	schemas map[string]ToolSchema
+	tools          map[string]ContextToolHandler
+	rawTools       map[string]RawToolHandler // Tools registered with RegisterTypedTool
+	toolOrder      []string                  // Tool names in registration order
+	toolOrdering   ToolOrdering
+	schemas        map[string]ToolSchema
+	toolsMu        sync.RWMutex
+	metrics        *MetricsRegistry
+	store          storage.Store
+	historyTTL     time.Duration
+	disabledGroups map[string]bool
+	groupsMu       sync.RWMutex
+	formulas       formulaBook
+	resources      map[string]registeredResource
+	prompts        map[string]registeredPrompt
+	subscribers    map[int]subscriber
+	nextSubscriber int
+	subscribersMu  sync.Mutex
+	inFlight       map[string]*inFlightRequest
+	inFlightMu     sync.Mutex
+	clients        map[string]*ClientState // Keyed by session ID
+	clientsMu      sync.RWMutex
+	logLevels      map[string]LogLevel // Set by logging/setLevel, keyed by session ID
+	logLevelsMu    sync.RWMutex
+	toolList       toolListCache
+	sessionCalls   sessionLimiter
+	clientRequests clientRequestTracker // Server-initiated requests awaiting the client's answer
+	elicitation    elicitationSettings
+	explanations   explanationSettings
+	resourceSubs   resourceSubscriptions
+	strictness     argumentStrictness
+	decodeLimits   DecodeLimits
+	usage          usageBook     // Tool calls per credential, against their quotas
+	detached       chan struct{} // One token per handler still running after its call was cancelled
+	// slowCallThreshold is how long a tool call runs before it is logged
+	slowCallThreshold time.Duration
 }
 
 type ToolSchema struct {
 	Name        string
 	Description string
 	InputSchema map[string]interface{}
+	// OutputSchema describes the tool's result; tools that declare one also
+	// return it as structuredContent
+	OutputSchema map[string]interface{}
+	Group        string              // Feature-flag group; tools without a group are always enabled
+	SelfCheck    *SelfCheck          // Known-answer test run by the self-check diagnostics
+	Examples     []types.ToolExample // Example invocations listed in tools/list _meta
+	Version      string              // Reported in result provenance; empty is ServerVersion
+	Provenance   ProvenanceFunc      // Describes the algorithm behind each result
+	// Aliases canonicalize the values of string arguments in lenient mode
+	Aliases map[string]ValueResolver
+	// Completions suggest values of arguments in completion/complete;
+	// arguments without one are completed from their schema enum
+	Completions map[string]CompletionHandler
+	// RequiredArguments names the arguments a call needs beyond the
+	// schema's required list, given those it has, so missing ones can be
+	// elicited from the client
+	RequiredArguments func(arguments map[string]interface{}) []string
+}
+
+// ToolOption configures optional properties of a registered tool
+type ToolOption func(*ToolSchema)
+
+// WithGroup places the tool in a group that can be enabled or disabled as a whole
+func WithGroup(group string) ToolOption {
+	return func(schema *ToolSchema) {
+		schema.Group = group
+	}
+}
+
+// WithOutputSchema declares the JSON Schema of the tool's result. The result
+// is then returned as structuredContent as well as in a text block. The
+// schema is extended to allow the error object that failed calls carry
+// instead, so that every structuredContent of the tool conforms to it.
+func WithOutputSchema(outputSchema map[string]interface{}) ToolOption {
+	return func(schema *ToolSchema) {
+		schema.OutputSchema = withToolErrorSchema(outputSchema)
+	}
+}
+
+// toolErrorSchema describes the structuredContent.error of failed calls
+var toolErrorSchema = map[string]interface{}{
+	"type":        "object",
+	"description": "Why the call failed, in results with isError set",
+	"properties": map[string]interface{}{
+		"code":    map[string]interface{}{"type": "string", "description": "Machine-readable error code, e.g. DIVISION_BY_ZERO"},
+		"message": map[string]interface{}{"type": "string"},
+		"value":   map[string]interface{}{"type": "number", "description": "The offending value"},
+		"field":   map[string]interface{}{"type": "string", "description": "The argument the value came from"},
+	},
+	"required": []string{"code", "message", "value"},
+}
+
+// withToolErrorSchema returns a copy of an output schema that also accepts
+// {"error": {...}}: the error property is added, and the result's required
+// properties become one of two alternatives
+func withToolErrorSchema(outputSchema map[string]interface{}) map[string]interface{} {
+	extended := make(map[string]interface{}, len(outputSchema)+1)
+	for key, value := range outputSchema {
+		extended[key] = value
+	}
+	properties := make(map[string]interface{})
+	if declared, ok := outputSchema["properties"].(map[string]interface{}); ok {
+		for name, property := range declared {
+			properties[name] = property
+		}
+	}
+	properties["error"] = toolErrorSchema
+	extended["properties"] = properties
+	if required, ok := outputSchema["required"]; ok {
+		delete(extended, "required")
+		extended["anyOf"] = []interface{}{
+			map[string]interface{}{"required": required},
+			map[string]interface{}{"required": []string{"error"}},
+		}
+	}
+	return extended
 }
 
 type ToolHandler func(params map[string]interface{}) (interface{}, error)
 
-// Transport defines the interface for different transport mechanisms
+// ContextToolHandler is a tool handler that also receives the request
+// context, e.g. to look up the calling session
+type ContextToolHandler func(ctx context.Context, params map[string]interface{}) (interface{}, error)
+
+// Transport carries MCP messages between a Server and its clients. Start
+// serves until the transport ends or fails; Stop ends it, waiting up to
+// ctx for the requests being served. Send delivers a server-initiated
+// notification to the transport's connected clients, and Addr names where
+// the transport listens. Custom transports are plugged in with
+// RegisterTransport.
 type Transport interface {
 	Start() error
 	Stop(ctx context.Context) error
+	Send(notification types.MCPNotification) error
+	Addr() string
 }
 
-// StdioTransport implements stdio transport for MCP protocol
+// StdioTransport implements stdio transport for MCP protocol: JSON-RPC
+// messages read from one byte stream and written to another, by default the
+// process's stdin and stdout. Messages are newline delimited unless the
+// transport is configured for Content-Length framing.
 type StdioTransport struct {
-	server *Server
+	server  *Server
+	in      io.Reader
+	out     io.Writer
+	config  StdioConfig
+	writeMu sync.Mutex // Keeps notifications from interleaving with responses
+
+	// Calls tracks the tool calls and batches running in the background;
+	// once stopping is set under callsMu, no more are started
+	calls    sync.WaitGroup
+	callsMu  sync.Mutex
+	stopping bool
+	slots    chan struct{} // One token per call running in the background
 }
 
 // NewStdioTransport creates a new stdio transport instance
 func NewStdioTransport(server *Server) *StdioTransport {
-	return &StdioTransport{server: server}
+	return NewStdioTransportWithIO(server, nil, nil)
+}
+
+// NewStdioTransportWithIO creates a stdio transport over any pair of byte
+// streams, such as a pipe, a socket or an in-memory buffer. A nil in or out
+// stands for os.Stdin or os.Stdout.
+func NewStdioTransportWithIO(server *Server, in io.Reader, out io.Writer) *StdioTransport {
+	return NewStdioTransportWithConfig(server, in, out, nil)
+}
+
+// NewStdioTransportWithConfig creates a stdio transport over in and out
+// with the given framing and message size limit. A nil config uses
+// newline delimited messages of up to DefaultStdioMaxMessageSize bytes.
+func NewStdioTransportWithConfig(server *Server, in io.Reader, out io.Writer, config *StdioConfig) *StdioTransport {
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	st := &StdioTransport{server: server, in: in, out: out}
+	if config != nil {
+		st.config = *config
+	}
+	if st.config.Framing == "" {
+		st.config.Framing = StdioFramingLine
+	}
+	if st.config.MaxMessageSize <= 0 {
+		st.config.MaxMessageSize = DefaultStdioMaxMessageSize
+	}
+	if st.config.MaxCalls <= 0 {
+		st.config.MaxCalls = DefaultStdioMaxCalls
+	}
+	st.slots = make(chan struct{}, st.config.MaxCalls)
+	return st
 }
 
 func NewServer() *Server {
 	return &Server{
-		tools:   make(map[string]ToolHandler),
-		schemas: make(map[string]ToolSchema),
+		tools:             make(map[string]ContextToolHandler),
+		rawTools:          make(map[string]RawToolHandler),
+		toolOrdering:      ToolOrderRegistration,
+		schemas:           make(map[string]ToolSchema),
+		metrics:           NewMetricsRegistry(),
+		disabledGroups:    make(map[string]bool),
+		resources:         make(map[string]registeredResource),
+		prompts:           make(map[string]registeredPrompt),
+		subscribers:       make(map[int]subscriber),
+		inFlight:          make(map[string]*inFlightRequest),
+		clients:           make(map[string]*ClientState),
+		logLevels:         make(map[string]LogLevel),
+		slowCallThreshold: DefaultSlowCallThreshold,
+		decodeLimits:      DecodeLimits{MaxDepth: DefaultMaxJSONDepth, MaxArrayLength: DefaultMaxArrayLength},
+		detached:          make(chan struct{}, maxDetachedHandlers),
 	}
 }
 
-func (s *Server) RegisterTool(name string, description string, inputSchema map[string]interface{}, handler ToolHandler) {
-	s.tools[name] = handler
-	s.schemas[name] = ToolSchema{
+// Metrics returns the per-tool usage metrics registry
+func (s *Server) Metrics() *MetricsRegistry {
+	return s.metrics
+}
+
+func (s *Server) RegisterTool(name string, description string, inputSchema map[string]interface{}, handler ToolHandler, opts ...ToolOption) {
+	s.RegisterContextTool(name, description, inputSchema, func(_ context.Context, params map[string]interface{}) (interface{}, error) {
+		return handler(params)
+	}, opts...)
+}
+
+// RegisterContextTool registers a tool whose handler receives the request
+// context. Connected clients are told the tool list changed.
+func (s *Server) RegisterContextTool(name string, description string, inputSchema map[string]interface{}, handler ContextToolHandler, opts ...ToolOption) {
+	schema := ToolSchema{
 		Name:        name,
 		Description: description,
 		InputSchema: inputSchema,
 	}
+	for _, opt := range opts {
+		opt(&schema)
+	}
+	s.registerTool(schema, handler, nil)
+}
+
+// registerTool adds or replaces a tool. A replaced tool keeps its place in
+// the registration order.
+func (s *Server) registerTool(schema ToolSchema, handler ContextToolHandler, raw RawToolHandler) {
+	s.toolsMu.Lock()
+	if _, exists := s.tools[schema.Name]; !exists {
+		s.toolOrder = append(s.toolOrder, schema.Name)
+	}
+	s.tools[schema.Name] = handler
+	s.schemas[schema.Name] = schema
+	if raw != nil {
+		s.rawTools[schema.Name] = raw
+	} else {
+		delete(s.rawTools, schema.Name)
+	}
+	s.toolsMu.Unlock()
+
+	s.toolsChanged()
+}
+
+// UnregisterTool removes a tool, reporting whether it was registered.
+// Connected clients are told the tool list changed.
+func (s *Server) UnregisterTool(name string) bool {
+	s.toolsMu.Lock()
+	_, exists := s.tools[name]
+	delete(s.tools, name)
+	delete(s.rawTools, name)
+	delete(s.schemas, name)
+	if exists {
+		s.toolOrder = slices.DeleteFunc(s.toolOrder, func(tool string) bool { return tool == name })
+	}
+	s.toolsMu.Unlock()
+
+	if exists {
+		s.toolsChanged()
+	}
+	return exists
+}
+
+// lookupTool returns the handler and schema of a registered tool
+func (s *Server) lookupTool(name string) (ContextToolHandler, ToolSchema, bool) {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+
+	handler, exists := s.tools[name]
+	return handler, s.schemas[name], exists
+}
+
+// toolSchemas returns a snapshot of the registered tool schemas, in the
+// configured tool ordering
+func (s *Server) toolSchemas() []ToolSchema {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+
+	schemas := make([]ToolSchema, 0, len(s.toolOrder))
+	for _, name := range s.toolOrder {
+		schemas = append(schemas, s.schemas[name])
+	}
+	if s.toolOrdering == ToolOrderName {
+		sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+	}
+	return schemas
+}
+
+// SetToolGroupEnabled enables or disables every tool in the group.
+// Connected clients are told when the tool list changed.
+func (s *Server) SetToolGroupEnabled(group string, enabled bool) {
+	s.groupsMu.Lock()
+	changed := s.disabledGroups[group] == enabled
+	if enabled {
+		delete(s.disabledGroups, group)
+	} else {
+		s.disabledGroups[group] = true
+	}
+	s.groupsMu.Unlock()
+
+	if changed {
+		s.toolsChanged()
+	}
+}
+
+// ToolGroups returns every known tool group and whether it is enabled
+func (s *Server) ToolGroups() map[string]bool {
+	schemas := s.toolSchemas()
+
+	s.groupsMu.RLock()
+	defer s.groupsMu.RUnlock()
+
+	groups := make(map[string]bool)
+	for _, schema := range schemas {
+		if schema.Group != "" {
+			groups[schema.Group] = !s.disabledGroups[schema.Group]
+		}
+	}
+	for group := range s.disabledGroups {
+		groups[group] = false
+	}
+	return groups
+}
+
+// toolAvailable reports whether the tool may be listed and called in ctx,
+// honouring disabled groups, the allowed tools of the credential and the
+// tenant's allowed tools and groups
+func (s *Server) toolAvailable(ctx context.Context, schema ToolSchema) bool {
+	if schema.Group != "" {
+		s.groupsMu.RLock()
+		disabled := s.disabledGroups[schema.Group]
+		s.groupsMu.RUnlock()
+		if disabled {
+			return false
+		}
+	}
+
+	if credential := CredentialFromContext(ctx); credential != nil && !credential.allowsTool(schema.Name) {
+		return false
+	}
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		return tenant.allowsTool(schema.Name) && tenant.allowsGroup(schema.Group)
+	}
+	return true
 }
 
 func (s *Server) HandleRequest(req types.MCPRequest) types.MCPResponse {
+	return s.HandleRequestContext(context.Background(), req)
+}
+
+// HandleRequestContext processes a request in the context of the calling
+// transport, which carries request-scoped values such as the session ID.
+// Notifications (requests without an ID) are handled and return a zero
+// MCPResponse, which must not be sent.
+func (s *Server) HandleRequestContext(ctx context.Context, req types.MCPRequest) types.MCPResponse {
+	// Requests from the wire were validated by parseRequest; invalid
+	// notifications from embedding code have no one to answer and are dropped
+	if mcpErr := validateRequest(req); mcpErr != nil {
+		if req.IsNotification() {
+			return types.MCPResponse{}
+		}
+		return invalidRequestResponse(req, mcpErr)
+	}
+	if req.IsNotification() {
+		s.handleNotification(ctx, req)
+		return types.MCPResponse{}
+	}
+
 	response := types.MCPResponse{
 		JSONRPC: "2.0",
 		ID:      req.ID,
 	}
 
+	// Requests can be cancelled until they are answered
+	ctx, done := s.trackRequest(ctx, req.ID)
+	defer done()
+
 	switch req.Method {
 	case "initialize":
+		var params types.InitializeParams
+		if len(req.Params) > 0 {
+			if err := decodeJSON(req.Params, &params); err != nil {
+				response.Error = &types.MCPError{
+					Code:    ErrorCodeInvalidParams,
+					Message: "Invalid parameters",
+					Data:    err.Error(),
+				}
+				return response
+			}
+		}
+		version := negotiateProtocolVersion(params.ProtocolVersion)
+		s.recordClient(ctx, params, version)
+
+		capabilities := map[string]interface{}{
+			"tools":       map[string]interface{}{"listChanged": true},
+			"resources":   map[string]interface{}{"subscribe": true},
+			"prompts":     map[string]interface{}{},
+			"completions": map[string]interface{}{},
+			"logging":     map[string]interface{}{},
+		}
+		if sampling := s.samplingCapability(); sampling != nil {
+			capabilities["experimental"] = map[string]interface{}{"sampling": sampling}
+		}
 		response.Result = map[string]interface{}{
-			"protocolVersion": "2024-11-05",
-			"capabilities": map[string]interface{}{
-				"tools": map[string]interface{}{},
-			},
+			"protocolVersion": version,
+			"capabilities":    capabilities,
 			"serverInfo": map[string]interface{}{
 				"name":    "calculator-server",
-				"version": "1.0.0",
+				"version": ServerVersion,
 			},
 		}
 	case "tools/list":
-		tools := []types.Tool{}
-		for _, schema := range s.schemas {
-			tool := types.Tool{
-				Name:        schema.Name,
-				Description: schema.Description,
-				InputSchema: schema.InputSchema,
+		response.Result = s.listTools(ctx)
+	case "tools/call":
+		params, err := decodeCallToolParams(req.Params)
+		if err != nil {
+			response.Error = &types.MCPError{
+				Code:    ErrorCodeInvalidParams,
+				Message: "Invalid parameters",
+				Data:    err.Error(),
 			}
-			tools = append(tools, tool)
+			return response
 		}
-		response.Result = types.ListToolsResult{Tools: tools}
-	case "tools/call":
-		var params types.CallToolParams
-		if err := json.Unmarshal(req.Params, &params); err != nil {
+
+		result, mcpErr := s.callTool(ctx, params)
+		if mcpErr != nil {
+			response.Error = mcpErr
+			return response
+		}
+		response.Result = result
+	case "resources/list":
+		result, mcpErr := s.listResources(ctx)
+		if mcpErr != nil {
+			response.Error = mcpErr
+			return response
+		}
+		response.Result = result
+	case "resources/templates/list":
+		response.Result = s.listResourceTemplates()
+	case "resources/read":
+		var params types.ReadResourceParams
+		if err := decodeJSON(req.Params, &params); err != nil {
 			response.Error = &types.MCPError{
 				Code:    ErrorCodeInvalidParams,
 				Message: "Invalid parameters",
@@ -147,28 +541,262 @@ func (s *Server) HandleRequest(req types.MCPRequest) types.MCPResponse {
 			return response
 		}
 
-		handler, exists := s.tools[params.Name]
-		if !exists {
+		result, mcpErr := s.readResource(ctx, params)
+		if mcpErr != nil {
+			response.Error = mcpErr
+			return response
+		}
+		response.Result = result
+	case "resources/subscribe", "resources/unsubscribe":
+		var params types.ReadResourceParams
+		if err := decodeJSON(req.Params, &params); err != nil || params.URI == "" {
+			data := "uri is required"
+			if err != nil {
+				data = err.Error()
+			}
 			response.Error = &types.MCPError{
-				Code:    ErrorCodeMethodNotFound,
-				Message: "Tool not found",
-				Data:    params.Name,
+				Code:    ErrorCodeInvalidParams,
+				Message: "Invalid parameters",
+				Data:    data,
 			}
 			return response
 		}
 
-		result, err := handler(params.Arguments)
-		if err != nil {
+		if req.Method == "resources/unsubscribe" {
+			s.unsubscribeResource(ctx, params.URI)
+		} else if mcpErr := s.subscribeResource(ctx, params.URI); mcpErr != nil {
+			response.Error = mcpErr
+			return response
+		}
+		response.Result = map[string]interface{}{}
+	case "prompts/list":
+		response.Result = s.listPrompts()
+	case "prompts/get":
+		var params types.GetPromptParams
+		if err := decodeJSON(req.Params, &params); err != nil {
+			response.Error = &types.MCPError{
+				Code:    ErrorCodeInvalidParams,
+				Message: "Invalid parameters",
+				Data:    err.Error(),
+			}
+			return response
+		}
+
+		result, mcpErr := s.getPrompt(ctx, params)
+		if mcpErr != nil {
+			response.Error = mcpErr
+			return response
+		}
+		response.Result = result
+	case "logging/setLevel":
+		var params types.SetLevelParams
+		if err := decodeJSON(req.Params, &params); err != nil {
+			response.Error = &types.MCPError{
+				Code:    ErrorCodeInvalidParams,
+				Message: "Invalid parameters",
+				Data:    err.Error(),
+			}
+			return response
+		}
+
+		if mcpErr := s.setLogLevel(ctx, params); mcpErr != nil {
+			response.Error = mcpErr
+			return response
+		}
+		response.Result = map[string]interface{}{}
+	case "completion/complete":
+		var params types.CompleteParams
+		if err := decodeJSON(req.Params, &params); err != nil {
 			response.Error = &types.MCPError{
-				Code:    ErrorCodeInternalError,
-				Message: "Tool execution failed",
+				Code:    ErrorCodeInvalidParams,
+				Message: "Invalid parameters",
 				Data:    err.Error(),
 			}
 			return response
 		}
 
+		result, mcpErr := s.complete(ctx, params)
+		if mcpErr != nil {
+			response.Error = mcpErr
+			return response
+		}
+		response.Result = result
+	default:
+		response.Error = &types.MCPError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: "Method not found",
+			Data:    req.Method,
+		}
+	}
+
+	return response
+}
+
+// callTool runs the named tool, records its metrics and history, and wraps
+// the handler's result in a CallToolResult
+func (s *Server) callTool(ctx context.Context, params types.CallToolParams) (types.CallToolResult, *types.MCPError) {
+	handler, schema, exists := s.lookupTool(params.Name)
+	if !exists {
+		return types.CallToolResult{}, &types.MCPError{
+			Code:    ErrorCodeMethodNotFound,
+			Message: "Tool not found",
+			Data:    params.Name,
+		}
+	}
+	if !s.toolAvailable(ctx, schema) {
+		return types.CallToolResult{}, &types.MCPError{
+			Code:    ErrorCodeAccessDenied,
+			Message: "Tool is not enabled",
+			Data:    params.Name,
+		}
+	}
+
+	s.logToolEvent(ctx, LogDebug, "tool_invoked", params.Name, nil)
+
+	// Typed tools decode arguments that arrived as JSON straight into their
+	// request; everything else goes through the normalized map
+	raw := s.lookupRawTool(params.Name)
+	if params.Arguments != nil {
+		raw = nil
+	}
+	var unknown []UnknownArgument
+	var fields map[string]json.RawMessage
+	if raw != nil {
+		if len(params.RawArguments) == 0 {
+			params.RawArguments = emptyArguments
+		}
+		if err := json.Unmarshal(params.RawArguments, &fields); err != nil {
+			mcpErr := &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Invalid parameters", Data: err.Error()}
+			s.logToolEvent(ctx, LogWarning, "validation_failed", params.Name, map[string]interface{}{"error": mcpErr.Message})
+			return types.CallToolResult{}, mcpErr
+		}
+		if s.lenient() {
+			if changed := canonicalizeFields(schema, fields); len(changed) > 0 {
+				params.RawArguments, _ = json.Marshal(fields)
+				s.logArgumentsCanonicalized(ctx, params.Name, changed)
+			}
+		}
+		unknown = unknownArguments(schema.InputSchema, fields)
+	} else {
+		if mcpErr := s.prepareArguments(ctx, &params); mcpErr != nil {
+			return types.CallToolResult{}, mcpErr
+		}
+		if s.lenient() {
+			if changed := canonicalizeArguments(schema, params.Arguments); len(changed) > 0 {
+				s.logArgumentsCanonicalized(ctx, params.Name, changed)
+			}
+		}
+		unknown = unknownArguments(schema.InputSchema, params.Arguments)
+	}
+
+	// Misspelled argument keys would otherwise be silently ignored
+	if len(unknown) > 0 && !allowsAdditionalProperties(schema.InputSchema) {
+		s.logToolEvent(ctx, LogWarning, "validation_failed", params.Name, map[string]interface{}{"error": "unknown arguments", "arguments": unknown})
+		return types.CallToolResult{}, &types.MCPError{
+			Code:    ErrorCodeInvalidParams,
+			Message: "Unknown arguments: " + describeUnknownArguments(unknown),
+			Data:    unknown,
+		}
+	}
+
+	// Tools with elicitation enabled ask the client for missing arguments
+	// instead of failing
+	if s.elicitationEnabled(params.Name) {
+		arguments := params.Arguments
+		if raw != nil {
+			arguments, _ = decodeArguments(params.RawArguments)
+		}
+		elicited, err := s.elicitArguments(ctx, schema, arguments)
+		if err != nil {
+			if ctx.Err() != nil {
+				return types.CallToolResult{}, &types.MCPError{Code: ErrorCodeRequestCancelled, Message: "Request cancelled", Data: params.Name}
+			}
+			s.logToolEvent(ctx, LogWarning, "elicitation_declined", params.Name, map[string]interface{}{"error": err.Error()})
+			return toolErrorResult(err), nil
+		}
+		if len(elicited) > 0 {
+			if mcpErr := mergeElicited(&params, raw != nil, fields, elicited); mcpErr != nil {
+				return types.CallToolResult{}, mcpErr
+			}
+		}
+	}
+
+	release, admitted := s.sessionCalls.acquire(ctx, SessionIDFromContext(ctx))
+	if !admitted {
+		s.logToolEvent(ctx, LogWarning, "call_rejected", params.Name, map[string]interface{}{"error": "session call limit reached"})
+		return types.CallToolResult{}, s.sessionCallLimitError(params.Name)
+	}
+	slot := newCallSlot(release)
+	releaseSlot := sync.OnceFunc(slot.done)
+	defer releaseSlot()
+	if mcpErr := s.chargeQuota(ctx, params.Name); mcpErr != nil {
+		s.logToolEvent(ctx, LogWarning, "call_rejected", params.Name, map[string]interface{}{"error": mcpErr.Message})
+		return types.CallToolResult{}, mcpErr
+	}
+
+	start := time.Now()
+	toolCtx := withProgress(ctx, params.Meta)
+	if s.supportsPartialResults(ctx) {
+		toolCtx = withPartialResults(toolCtx, params.Meta)
+	}
+	var result interface{}
+	var err error
+	if raw != nil {
+		result, err = s.invokeTool(toolCtx, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+			return raw(ctx, params.RawArguments)
+		}, nil, slot)
+	}
+	if raw == nil || errors.Is(err, errArgumentsNotDecoded) {
+		if raw != nil {
+			if mcpErr := s.prepareArguments(ctx, &params); mcpErr != nil {
+				return types.CallToolResult{}, mcpErr
+			}
+		}
+		result, err = s.invokeTool(toolCtx, handler, params.Arguments, slot)
+	}
+	elapsed := time.Since(start)
+	s.metrics.Record(params.Name, elapsed, err)
+	if elapsed >= s.slowCallThreshold {
+		s.logToolEvent(ctx, LogWarning, "slow_calculation", params.Name, map[string]interface{}{
+			"duration_ms":  float64(elapsed.Microseconds()) / 1000,
+			"threshold_ms": float64(s.slowCallThreshold.Microseconds()) / 1000,
+		})
+	}
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return types.CallToolResult{}, &types.MCPError{
+			Code:    ErrorCodeRequestCancelled,
+			Message: "Request cancelled",
+			Data:    params.Name,
+		}
+	}
+	var panicErr *toolPanic
+	if errors.As(err, &panicErr) {
+		logf(ctx, "Tool %s panicked: %v", params.Name, panicErr.value)
+		s.logToolEvent(ctx, LogError, "tool_panicked", params.Name, nil)
+		return types.CallToolResult{}, &types.MCPError{
+			Code:    ErrorCodeInternalError,
+			Message: "Internal error",
+			Data:    err.Error(),
+		}
+	}
+	if err != nil {
+		// Handler errors are caused by the arguments, not by the server or
+		// the protocol, so they are results the model can read and act on
+		s.logToolEvent(ctx, LogWarning, "validation_failed", params.Name, map[string]interface{}{"error": err.Error()})
+		return toolErrorResult(err), nil
+	}
+
+	// Handlers that build their own content blocks (e.g. exports) produce
+	// documents rather than calculations and are not recorded in history
+	toolResult, passThrough := result.(types.CallToolResult)
+	if !passThrough {
+		if params.Arguments == nil && s.store != nil && SessionIDFromContext(ctx) != "" {
+			params.Arguments, _ = decodeArguments(params.RawArguments)
+		}
+		s.recordHistory(SessionIDFromContext(ctx), params.Name, params.Arguments, result)
+
 		resultJSON, _ := json.Marshal(result)
-		response.Result = types.CallToolResult{
+		toolResult = types.CallToolResult{
 			Content: []types.ContentBlock{
 				{
 					Type: "text",
@@ -176,77 +804,336 @@ func (s *Server) HandleRequest(req types.MCPRequest) types.MCPResponse {
 				},
 			},
 		}
-	default:
-		response.Error = &types.MCPError{
-			Code:    ErrorCodeMethodNotFound,
-			Message: "Method not found",
-			Data:    req.Method,
+		// The text block stays for clients that predate structured results
+		if schema.OutputSchema != nil {
+			toolResult.StructuredContent = resultJSON
+		}
+		toolResult.Meta = &types.ResultMeta{Provenance: provenance(schema, params.Arguments, fields)}
+
+		// The calculation is over, so the slot is given back before waiting
+		// on the client's model. A failed explanation leaves the result as
+		// it is.
+		releaseSlot()
+		explanation, err := s.explainResult(ctx, schema, resultJSON)
+		if err != nil {
+			s.logToolEvent(ctx, LogWarning, "explanation_failed", params.Name, map[string]interface{}{"error": err.Error()})
+		} else if explanation != "" {
+			toolResult.Content = append(toolResult.Content, types.ContentBlock{Type: "text", Text: explanation})
 		}
 	}
 
-	return response
+	if len(unknown) > 0 {
+		toolResult.Content = append(toolResult.Content, types.ContentBlock{
+			Type: "text",
+			Text: "Warning: ignored unknown arguments " + describeUnknownArguments(unknown),
+		})
+	}
+	return toolResult, nil
+}
+
+// prepareArguments fills in the normalized argument map of a call, decoding
+// its raw arguments when it has no map yet
+func (s *Server) prepareArguments(ctx context.Context, params *types.CallToolParams) *types.MCPError {
+	var arguments map[string]interface{}
+	var mcpErr *types.MCPError
+	if params.Arguments == nil {
+		arguments, mcpErr = decodeArguments(params.RawArguments)
+	} else {
+		arguments, mcpErr = normalizeArguments(params.Arguments)
+	}
+	if mcpErr != nil {
+		s.logToolEvent(ctx, LogWarning, "validation_failed", params.Name, map[string]interface{}{"error": mcpErr.Message})
+		return mcpErr
+	}
+	params.Arguments = arguments
+	return nil
+}
+
+// toolErrorResult reports a tool that rejected its arguments as a result
+// with isError set. Domain failures also carry their machine-readable code
+// and the offending value as structuredContent.
+func toolErrorResult(err error) types.CallToolResult {
+	result := types.CallToolResult{
+		Content: []types.ContentBlock{{Type: "text", Text: err.Error()}},
+		IsError: true,
+	}
+	var calcErr *types.CalculationError
+	if errors.As(err, &calcErr) {
+		result.StructuredContent, _ = json.Marshal(map[string]interface{}{"error": calcErr})
+	}
+	return result
 }
 
-// Run starts the stdio transport (maintained for backward compatibility)
-func (s *Server) Run() error {
-	transport := NewStdioTransport(s)
+// toolPanic is the error reported for a handler that panicked
+type toolPanic struct {
+	value interface{}
+}
+
+func (p *toolPanic) Error() string {
+	return fmt.Sprintf("tool panicked: %v", p.value)
+}
+
+// toolOutcome is the result of a handler run in its own goroutine
+type toolOutcome struct {
+	result interface{}
+	err    error
+}
+
+// maxDetachedHandlers bounds the handlers that keep running after their
+// call was answered as cancelled
+const maxDetachedHandlers = 64
+
+// Handler states, shared by invokeTool and the handler's goroutine
+const (
+	handlerRunning int32 = iota
+	handlerFinished
+	handlerDetached
+)
+
+// invokeTool runs the handler, converting a panic into a *toolPanic error so
+// a faulty tool cannot take down the server. When ctx is cancelled first,
+// invokeTool returns ctx.Err() at once; handlers that do not watch ctx run
+// to completion in the background and their result is discarded. Only
+// maxDetachedHandlers run so at a time, beyond which a cancelled call waits
+// for its handler. The slot, if any, is held until the handler returns.
+func (s *Server) invokeTool(ctx context.Context, handler ContextToolHandler, args map[string]interface{}, slot *callSlot) (interface{}, error) {
+	if ctx.Done() == nil {
+		return runHandler(ctx, handler, args)
+	}
+
+	outcome := make(chan toolOutcome, 1)
+	state := handlerRunning
+	slot.retain()
+	go func() {
+		defer slot.done()
+		result, err := runHandler(ctx, handler, args)
+		outcome <- toolOutcome{result: result, err: err}
+		if !atomic.CompareAndSwapInt32(&state, handlerRunning, handlerFinished) {
+			<-s.detached
+		}
+	}()
+	select {
+	case done := <-outcome:
+		return done.result, done.err
+	case <-ctx.Done():
+	}
+
+	select {
+	case s.detached <- struct{}{}:
+		if !atomic.CompareAndSwapInt32(&state, handlerRunning, handlerDetached) {
+			// The handler returned meanwhile
+			<-s.detached
+		}
+	case <-outcome:
+	}
+	return nil, ctx.Err()
+}
+
+// runHandler calls the handler, recovering from a panic
+func runHandler(ctx context.Context, handler ContextToolHandler, args map[string]interface{}) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, &toolPanic{value: r}
+		}
+	}()
+
+	return handler(ctx, args)
+}
+
+// Run serves the stdio protocol over in and out until in ends, then waits
+// for running tool calls to answer. A nil in or out stands for os.Stdin or
+// os.Stdout.
+func (s *Server) Run(in io.Reader, out io.Writer) error {
+	transport := NewStdioTransportWithIO(s, in, out)
 	return transport.Start()
 }
 
 // Start implements the Transport interface for stdio transport
 func (st *StdioTransport) Start() error {
-	scanner := bufio.NewScanner(os.Stdin)
+	reader := newMessageReader(st.in, st.config)
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
+	unsubscribe := st.server.SubscribeSession(StdioSessionID, st.writeNotification)
+	defer unsubscribe()
+
+	// Tool calls run in the background so that a cancellation sent while
+	// one is running can be read; input ends only once they have answered
+	defer st.calls.Wait()
+
+	for {
+		message, err := reader.next()
+		if err == io.EOF {
+			return nil
+		}
+		if st.isStopping() {
+			return nil
+		}
+		var tooLarge errMessageTooLarge
+		if errors.As(err, &tooLarge) {
+			st.writeResponse(errorResponse(nil, ErrorCodeInvalidRequest, "Invalid Request", tooLarge.Error()))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Notifications of a request, such as progress, reach stdout ahead
+		// of its response
+		ctx := WithNotifier(WithSessionID(context.Background(), StdioSessionID), st.writeNotification)
+		ctx = WithRequester(ctx, st.writeRequest)
+
+		if errResponse := st.server.checkMessage(message); errResponse != nil {
+			st.writeResponse(*errResponse)
 			continue
 		}
 
-		var req types.MCPRequest
-		if err := json.Unmarshal([]byte(line), &req); err != nil {
-			// Try to extract ID from the raw JSON for better error reporting
-			var rawMap map[string]interface{}
-			var responseID interface{}
-			if json.Unmarshal([]byte(line), &rawMap) == nil {
-				if id, exists := rawMap["id"]; exists {
-					responseID = id
+		// Answers to requests the server sent, such as elicitations,
+		// belong to the tool call waiting for them
+		if st.server.handleClientResponse(ctx, message) {
+			continue
+		}
+
+		// A JSON array is a batch, answered with an array of responses
+		if members, isBatch, errResponse := splitBatch(message); isBatch {
+			if errResponse != nil {
+				st.writeResponse(*errResponse)
+				continue
+			}
+			if !st.spawn(func() {
+				responses := answerBatch(members, func(req types.MCPRequest) (types.MCPResponse, bool) {
+					return st.respond(ctx, req)
+				})
+				if len(responses) > 0 {
+					st.writeLine(responses)
 				}
+			}) {
+				return nil
 			}
+			continue
+		}
 
-			response := types.MCPResponse{
-				JSONRPC: "2.0",
-				ID:      responseID, // Include ID if we could extract it
-				Error: &types.MCPError{
-					Code:    ErrorCodeInvalidRequest,
-					Message: "Parse error",
-					Data:    err.Error(),
-				},
+		req, errResponse := parseRequest(message)
+		if errResponse != nil {
+			st.writeResponse(*errResponse)
+			continue
+		}
+
+		if req.Method == "tools/call" {
+			if !st.spawn(func() { st.answer(ctx, req) }) {
+				return nil
 			}
-			st.writeResponse(response)
 			continue
 		}
+		st.answer(ctx, req)
+	}
+}
+
+// spawn runs a call in the background unless the transport is stopping,
+// reporting whether it was started. While MaxCalls calls run, it waits for
+// one of them to finish.
+func (st *StdioTransport) spawn(call func()) bool {
+	st.slots <- struct{}{}
+	st.callsMu.Lock()
+	defer st.callsMu.Unlock()
+	if st.stopping {
+		<-st.slots
+		return false
+	}
+	st.calls.Add(1)
+	go func() {
+		defer st.calls.Done()
+		defer func() { <-st.slots }()
+		call()
+	}()
+	return true
+}
+
+// isStopping reports whether Stop has been called
+func (st *StdioTransport) isStopping() bool {
+	st.callsMu.Lock()
+	defer st.callsMu.Unlock()
+	return st.stopping
+}
 
-		response := st.server.HandleRequest(req)
+// answer handles a request and writes its response, if it has one
+func (st *StdioTransport) answer(ctx context.Context, req types.MCPRequest) {
+	if response, ok := st.respond(ctx, req); ok {
 		st.writeResponse(response)
 	}
+}
 
-	return scanner.Err()
+// respond handles a request and reports whether its response should be
+// sent. Notifications have no response, and neither do cancelled requests.
+func (st *StdioTransport) respond(ctx context.Context, req types.MCPRequest) (types.MCPResponse, bool) {
+	response := st.server.HandleRequestContext(ctx, req)
+	if req.IsNotification() || (response.Error != nil && response.Error.Code == ErrorCodeRequestCancelled) {
+		return response, false
+	}
+	return response, true
 }
 
-// Stop implements the Transport interface for stdio transport
+// Stop stops reading further messages and waits until the running tool
+// calls have answered or ctx is done. A read already waiting for input is
+// not interrupted, so Start returns once the next message arrives or the
+// input ends.
 func (st *StdioTransport) Stop(ctx context.Context) error {
-	// Stdio transport doesn't need explicit stopping
-	return nil
+	st.callsMu.Lock()
+	st.stopping = true
+	st.callsMu.Unlock()
+
+	answered := make(chan struct{})
+	go func() {
+		st.calls.Wait()
+		close(answered)
+	}()
+	select {
+	case <-answered:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // writeResponse is now part of the StdioTransport
 func (st *StdioTransport) writeResponse(response types.MCPResponse) {
-	responseJSON, err := json.Marshal(response)
+	st.writeLine(response)
+}
+
+// writeNotification implements NotifyFunc
+func (st *StdioTransport) writeNotification(notification types.MCPNotification) {
+	st.writeLine(notification)
+}
+
+// writeRequest implements RequestFunc
+func (st *StdioTransport) writeRequest(request types.MCPRequest) {
+	st.writeLine(request)
+}
+
+// Send implements Transport by writing the notification to the output
+func (st *StdioTransport) Send(notification types.MCPNotification) error {
+	return st.write(notification)
+}
+
+// Addr implements Transport; a stdio transport has no network address
+func (st *StdioTransport) Addr() string {
+	return "stdio"
+}
+
+// writeLine writes one framed JSON message to the output
+func (st *StdioTransport) writeLine(message interface{}) {
+	if err := st.write(message); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+	}
+}
+
+// write writes one framed JSON message to the output, returning any error
+func (st *StdioTransport) write(message interface{}) error {
+	messageJSON, err := json.Marshal(message)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling response: %v\n", err)
-		return
+		return fmt.Errorf("marshaling message: %w", err)
 	}
 
-	fmt.Println(string(responseJSON))
+	st.writeMu.Lock()
+	defer st.writeMu.Unlock()
+	_, err = st.out.Write(frameMessage(st.config.Framing, messageJSON))
+	return err
 }
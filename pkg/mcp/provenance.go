@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"calculator-server/internal/types"
+)
+
+// ServerVersion is the version reported in serverInfo, and of every tool
+// that does not declare its own
+const ServerVersion = "1.0.0"
+
+// ProvenanceFunc describes how a call with the given arguments is
+// calculated. Tool and Version are filled in by the server, as is Precision
+// when left empty.
+type ProvenanceFunc func(arguments map[string]interface{}) types.Provenance
+
+// WithVersion sets the version reported in the provenance of the tool's
+// results, for tools versioned apart from the server
+func WithVersion(version string) ToolOption {
+	return func(schema *ToolSchema) {
+		schema.Version = version
+	}
+}
+
+// WithProvenance describes the algorithm, precision and seed behind each
+// result of the tool in its _meta provenance
+func WithProvenance(describe ProvenanceFunc) ToolOption {
+	return func(schema *ToolSchema) {
+		schema.Provenance = describe
+	}
+}
+
+// provenance returns the provenance of a result of the tool. Typed tools
+// pass their arguments as fields, which are decoded only when the tool
+// describes its calls.
+func provenance(schema ToolSchema, arguments map[string]interface{}, fields map[string]json.RawMessage) *types.Provenance {
+	var p types.Provenance
+	if schema.Provenance != nil {
+		if arguments == nil && fields != nil {
+			encoded, _ := json.Marshal(fields)
+			arguments, _ = decodeArguments(encoded)
+		}
+		p = schema.Provenance(arguments)
+	}
+	p.Tool = schema.Name
+	p.Version = schema.Version
+	if p.Version == "" {
+		p.Version = ServerVersion
+	}
+	if p.Precision == "" {
+		p.Precision = "float64"
+		if places, ok := precisionArgument(arguments, fields); ok {
+			p.Precision = fmt.Sprintf("%d decimal places", places)
+		}
+	}
+	return &p
+}
+
+// precisionArgument returns the decimal places asked for by a precision
+// argument, whether given as a map or as typed fields
+func precisionArgument(arguments map[string]interface{}, fields map[string]json.RawMessage) (int, bool) {
+	if value, ok := arguments["precision"].(float64); ok {
+		return int(value), true
+	}
+	var places int
+	if field, ok := fields["precision"]; ok && json.Unmarshal(field, &places) == nil {
+		return places, true
+	}
+	return 0, false
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges such as
+// "10.0.0.0/8" into networks
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// isTrustedProxy reports whether the address belongs to a trusted proxy
+func (t *StreamableHTTPTransport) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range t.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyMiddleware takes the client address and host of requests relayed by
+// a trusted proxy from its X-Forwarded-For and X-Forwarded-Host headers.
+// RemoteAddr becomes the client's IP, the right-most X-Forwarded-For entry
+// that is not itself a trusted proxy, so that rate limits apply to clients
+// rather than the proxy. X-Forwarded-Proto is not read, as origins are only
+// matched against the configured lists. The headers of other peers are
+// ignored, as any client can send them.
+func (t *StreamableHTTPTransport) proxyMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			peer = r.RemoteAddr
+		}
+		if !t.isTrustedProxy(peer) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if host := firstForwarded(r.Header.Get("X-Forwarded-Host")); host != "" {
+			r.Host = host
+		}
+
+		hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			r.RemoteAddr = hop
+			if !t.isTrustedProxy(hop) {
+				break
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// firstForwarded returns the first entry of a comma-separated forwarding
+// header, the one set by the proxy nearest the client
+func firstForwarded(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.TrimSpace(first)
+}
+
+// normalizeBasePath turns a base path such as "calc/" into "/calc"; the
+// root path becomes ""
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
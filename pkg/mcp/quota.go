@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+)
+
+const (
+	// usagePath serves the quota usage of the calling credential
+	usagePath = "/usage"
+	// usageBucket holds the call counts of credentials in the store, so
+	// that quotas survive restarts
+	usageBucket = "usage"
+	// usageRetention keeps stored counts past the end of the month they cover
+	usageRetention = 62 * 24 * time.Hour
+)
+
+// Quota limits the tool calls of a credential per calendar day and month,
+// in UTC. Zero leaves a period unlimited.
+type Quota struct {
+	Daily   int
+	Monthly int
+}
+
+// PeriodUsage is the usage of a credential in one quota period
+type PeriodUsage struct {
+	Used      int64     `json:"used"`
+	Limit     int       `json:"limit"`               // 0 is unlimited
+	Remaining *int64    `json:"remaining,omitempty"` // Absent when unlimited
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// CredentialUsage is the tool call usage of a credential in the current
+// day and month
+type CredentialUsage struct {
+	Credential string      `json:"credential"`
+	Daily      PeriodUsage `json:"daily"`
+	Monthly    PeriodUsage `json:"monthly"`
+}
+
+// usageBook holds call counts when no store is configured
+type usageBook struct {
+	once  sync.Once
+	store storage.Store
+}
+
+// usageStore returns the configured store, falling back to a private
+// in-memory store so quotas work without persistence
+func (s *Server) usageStore() storage.Store {
+	if s.store != nil {
+		return s.store
+	}
+	s.usage.once.Do(func() {
+		s.usage.store = storage.NewMemoryStore()
+	})
+	return s.usage.store
+}
+
+// usageKeys returns the keys counting the calls of the named credential in
+// the day and the month of now. Credentials sharing a name, such as an old
+// and a new key during a rotation, share their usage.
+func usageKeys(name string, now time.Time) (day, month string) {
+	return name + ":day:" + now.Format("2006-01-02"), name + ":month:" + now.Format("2006-01")
+}
+
+// usageCount reads a call count, which is zero before the first call of
+// its period
+func (s *Server) usageCount(key string) int64 {
+	data, found, err := s.usageStore().Get(usageBucket, key)
+	if err != nil {
+		log.Printf("Failed to load usage %s: %v", key, err)
+		return 0
+	}
+	if !found {
+		return 0
+	}
+	count, _ := strconv.ParseInt(string(data), 10, 64)
+	return count
+}
+
+// chargeQuota counts a tool call against the quota of the request's
+// credential, or rejects it when the daily or monthly quota is used up.
+// Requests without a credential are not counted. The counts are
+// incremented atomically in the store, so concurrent calls, also on other
+// replicas sharing it, neither wait for each other nor exceed a quota; a
+// rejected call gives back what it counted.
+func (s *Server) chargeQuota(ctx context.Context, tool string) *types.MCPError {
+	credential := CredentialFromContext(ctx)
+	if credential == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	store := s.usageStore()
+	day, month := usageKeys(credential.Name, now)
+	var charged []string
+	for _, period := range []struct {
+		name  string
+		key   string
+		limit int
+	}{{"Daily", day, credential.Quota.Daily}, {"Monthly", month, credential.Quota.Monthly}} {
+		used, err := store.Increment(usageBucket, period.key, 1, usageRetention)
+		if err != nil {
+			log.Printf("Failed to count usage of %s: %v", credential.Name, err)
+			continue
+		}
+		charged = append(charged, period.key)
+		if period.limit == 0 || used <= int64(period.limit) {
+			continue
+		}
+
+		for _, key := range charged {
+			if _, err := store.Increment(usageBucket, key, -1, usageRetention); err != nil {
+				log.Printf("Failed to give back usage of %s: %v", credential.Name, err)
+			}
+		}
+		usage := s.Usage(credential)
+		resetsAt := usage.Daily.ResetsAt
+		if period.key == month {
+			resetsAt = usage.Monthly.ResetsAt
+		}
+		return &types.MCPError{
+			Code:    ErrorCodeQuotaExceeded,
+			Message: fmt.Sprintf("%s call quota of %d exceeded", period.name, period.limit),
+			Data: map[string]interface{}{
+				"tool":       tool,
+				"credential": credential.Name,
+				"limit":      period.limit,
+				"used":       used - 1,
+				"resets_at":  resetsAt,
+			},
+		}
+	}
+	return nil
+}
+
+// Usage returns the tool calls made with the credential in the current day
+// and month, against its quota
+func (s *Server) Usage(credential *Credential) CredentialUsage {
+	now := time.Now().UTC()
+	day, month := usageKeys(credential.Name, now)
+	return credentialUsage(credential, s.usageCount(day), s.usageCount(month), now)
+}
+
+// credentialUsage reports counts against the credential's quota
+func credentialUsage(credential *Credential, dayCalls, monthCalls int64, now time.Time) CredentialUsage {
+	period := func(used int64, limit int, resetsAt time.Time) PeriodUsage {
+		usage := PeriodUsage{Used: used, Limit: limit, ResetsAt: resetsAt}
+		if limit > 0 {
+			remaining := int64(limit) - used
+			if remaining < 0 {
+				remaining = 0
+			}
+			usage.Remaining = &remaining
+		}
+		return usage
+	}
+	year, month, day := now.Date()
+	return CredentialUsage{
+		Credential: credential.Name,
+		Daily:      period(dayCalls, credential.Quota.Daily, time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)),
+		Monthly:    period(monthCalls, credential.Quota.Monthly, time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+}
+
+// handleUsage serves the usage of the credential the request authenticated
+// with
+func (t *StreamableHTTPTransport) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	credential := CredentialFromContext(r.Context())
+	if credential == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t.mcpServer.Usage(credential))
+}
+
+// handleAdminUsage serves the usage of every configured credential, once
+// per name, sorted by name
+func (t *StreamableHTTPTransport) handleAdminUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	usage := []CredentialUsage{}
+	if t.config.Auth != nil {
+		seen := make(map[string]bool)
+		for _, credential := range append(append([]*Credential{}, t.config.Auth.apiKeys...), t.config.Auth.bearerTokens...) {
+			if !seen[credential.Name] {
+				seen[credential.Name] = true
+				usage = append(usage, t.mcpServer.Usage(credential))
+			}
+		}
+		sort.Slice(usage, func(i, j int) bool { return usage[i].Credential < usage[j].Credential })
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"credentials": usage})
+}
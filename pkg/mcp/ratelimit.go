@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate limit keys, naming what a client's requests are counted by
+const (
+	RateLimitByIP      = "ip"      // The remote address of the connection
+	RateLimitBySession = "session" // The Mcp-Session-Id header
+	RateLimitByAPIKey  = "api_key" // The X-API-Key header or the bearer token
+)
+
+// RateLimitConfig limits how many requests each client of the HTTP
+// transport may send
+type RateLimitConfig struct {
+	RequestsPerMinute int    // Bucket size and refill rate per client
+	KeyBy             string // RateLimitByIP, RateLimitBySession or RateLimitByAPIKey; empty is by IP
+}
+
+// tokenBucket allows bursts up to its capacity and refills continuously
+type tokenBucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+// rateLimiter keeps one token bucket per key
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*tokenBucket)}
+}
+
+// allow takes cost tokens from the key's bucket, which holds up to
+// requestsPerMinute tokens. When the bucket holds fewer it returns false
+// and how long until enough are available.
+func (l *rateLimiter) allow(key string, cost, requestsPerMinute int) (bool, time.Duration) {
+	capacity := float64(requestsPerMinute)
+	perSecond := capacity / 60
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: capacity, lastFill: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.lastFill).Seconds()*perSecond)
+	bucket.lastFill = now
+
+	if bucket.tokens < float64(cost) {
+		wait := time.Duration((float64(cost) - bucket.tokens) / perSecond * float64(time.Second))
+		return false, wait
+	}
+
+	bucket.tokens -= float64(cost)
+	return true, 0
+}
+
+// prune drops the buckets untouched for a minute, at most once a minute.
+// Such a bucket has refilled completely and is no different from a new one,
+// so clients that come and go do not grow the map.
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastFill) >= time.Minute {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitKey returns the key the request is counted by. Requests without
+// a known session or credential fall back to their remote address, so made
+// up ones cannot be used to get fresh buckets.
+func (t *StreamableHTTPTransport) rateLimitKey(r *http.Request) string {
+	switch t.config.RateLimit.KeyBy {
+	case RateLimitBySession:
+		sessionID := r.Header.Get("Mcp-Session-Id")
+		t.sessionsMux.RLock()
+		_, known := t.sessions[sessionID]
+		t.sessionsMux.RUnlock()
+		if known {
+			return "session:" + sessionID
+		}
+	case RateLimitByAPIKey:
+		if secret := t.knownSecret(r); secret != "" {
+			// Only a digest is kept, so the limiter holds no credentials
+			digest := sha256.Sum256([]byte(secret))
+			return "key:" + hex.EncodeToString(digest[:])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// knownSecret returns the API key or bearer token of the request if it is
+// one of the configured credentials or tenant keys. Without them no key is
+// known, as clients could otherwise present a new one for each request.
+func (t *StreamableHTTPTransport) knownSecret(r *http.Request) string {
+	switch {
+	case t.config.Auth != nil:
+		if credential, _ := t.config.Auth.Authenticate(r); credential != nil {
+			return credential.Secret
+		}
+	case t.config.Tenants != nil:
+		apiKey := r.Header.Get(TenantAPIKeyHeader)
+		if _, ok := t.config.Tenants.Resolve(apiKey); ok {
+			return apiKey
+		}
+	}
+	return ""
+}
+
+// rateLimitMiddleware answers clients that exceed their request rate with
+// 429 Too Many Requests and a Retry-After header. It runs before
+// authentication, so guessing credentials is limited too.
+func (t *StreamableHTTPTransport) rateLimitMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Load balancer checks and the admin API are not client traffic
+		if strings.HasPrefix(r.URL.Path, adminPathPrefix) || r.URL.Path == healthPath || r.Method == http.MethodOptions {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if allowed, retryAfter := t.limiter.allow(t.rateLimitKey(r), 1, t.config.RateLimit.RequestsPerMinute); !allowed {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+			t.writeErrorResponse(w, nil, ErrorCodeRateLimitExceeded, "Rate limit exceeded", "")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// chargeBatch takes a token for every member of a batch beyond the first,
+// which the rate limit and tenant middlewares have charged, so a batch
+// costs as much as its requests sent one by one. It answers 429 Too Many
+// Requests and reports false when a bucket runs out.
+func (t *StreamableHTTPTransport) chargeBatch(w http.ResponseWriter, r *http.Request, members int) bool {
+	extra := members - 1
+	if extra < 1 {
+		return true
+	}
+	if t.limiter != nil {
+		if allowed, retryAfter := t.limiter.allow(t.rateLimitKey(r), extra, t.config.RateLimit.RequestsPerMinute); !allowed {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+			t.writeErrorResponse(w, nil, ErrorCodeRateLimitExceeded, "Rate limit exceeded", fmt.Sprintf("a batch of %d requests", members))
+			return false
+		}
+	}
+	if tenant := TenantFromContext(r.Context()); tenant != nil && tenant.RequestsPerMinute > 0 {
+		if allowed, retryAfter := t.config.Tenants.limiter.allow(tenant.ID, extra, tenant.RequestsPerMinute); !allowed {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+			t.writeErrorResponse(w, nil, ErrorCodeRateLimitExceeded, "Rate limit exceeded", "tenant "+tenant.ID)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// RequestIDHeader carries the ID of an HTTP request in both directions
+const RequestIDHeader = "X-Request-Id"
+
+// clientRequestIDPattern limits the client-supplied IDs that are reused, so
+// they are safe to echo in headers and log lines
+var clientRequestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDMiddleware assigns every HTTP request an ID, reusing a well-formed
+// X-Request-Id sent by the client or a proxy, echoes it in the response
+// header and makes it available to handlers through the request context
+func (t *StreamableHTTPTransport) requestIDMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !clientRequestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		handler.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), requestID)))
+	})
+}
+
+func newRequestID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
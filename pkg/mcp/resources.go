@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+
+	"calculator-server/internal/types"
+)
+
+// ResourceReader returns the current text of a registered resource. The
+// context carries the session of the reading client.
+type ResourceReader func(ctx context.Context) (string, error)
+
+// registeredResource is a static resource exposed by RegisterResource
+type registeredResource struct {
+	resource types.Resource
+	read     ResourceReader
+	admin    bool // Listed to and read by administrators only
+}
+
+// RegisterResource exposes a resource under a fixed URI in resources/list
+// and resources/read. Registered resources are listed before saved formulas.
+func (s *Server) RegisterResource(uri, name, description, mimeType string, read ResourceReader) {
+	s.resources[uri] = registeredResource{
+		resource: types.Resource{
+			URI:         uri,
+			Name:        name,
+			Description: description,
+			MimeType:    mimeType,
+		},
+		read: read,
+	}
+}
+
+// RegisterAdminResource exposes a resource like RegisterResource, but only
+// to administrators; over HTTP, requests presenting the admin token
+func (s *Server) RegisterAdminResource(uri, name, description, mimeType string, read ResourceReader) {
+	s.RegisterResource(uri, name, description, mimeType, read)
+	registered := s.resources[uri]
+	registered.admin = true
+	s.resources[uri] = registered
+}
+
+// registeredResourceList returns the registered resources ctx may read
+// sorted by URI
+func (s *Server) registeredResourceList(ctx context.Context) []types.Resource {
+	resources := make([]types.Resource, 0, len(s.resources))
+	for _, registered := range s.resources {
+		if registered.admin && !IsAdmin(ctx) {
+			continue
+		}
+		resources = append(resources, registered.resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].URI < resources[j].URI
+	})
+	return resources
+}
+
+// readRegisteredResource reads a registered resource, reporting whether the
+// URI names one
+func (s *Server) readRegisteredResource(ctx context.Context, uri string) (types.ReadResourceResult, bool, *types.MCPError) {
+	registered, ok := s.resources[uri]
+	if !ok {
+		return types.ReadResourceResult{}, false, nil
+	}
+	if registered.admin && !IsAdmin(ctx) {
+		return types.ReadResourceResult{}, true, &types.MCPError{Code: ErrorCodeAccessDenied, Message: "Access denied", Data: "reading " + uri + " requires the admin token"}
+	}
+
+	text, err := registered.read(ctx)
+	if err != nil {
+		return types.ReadResourceResult{}, true, &types.MCPError{Code: ErrorCodeInternalError, Message: "Internal error", Data: err.Error()}
+	}
+	return types.ReadResourceResult{
+		Contents: []types.EmbeddedResource{
+			{URI: uri, MimeType: registered.resource.MimeType, Text: text},
+		},
+	}, true, nil
+}
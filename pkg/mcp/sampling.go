@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MethodSamplingCreateMessage asks the client's language model for a completion
+const MethodSamplingCreateMessage = "sampling/createMessage"
+
+// explanationMaxTokens bounds the length of a result explanation
+const explanationMaxTokens = 400
+
+// explanationSettings records the tools whose results are explained
+type explanationSettings struct {
+	mu    sync.RWMutex
+	tools map[string]bool
+}
+
+// SetResultExplanation enables or disables explanations for a tool. After a
+// successful call to an enabled tool the server asks the client's model,
+// through sampling/createMessage, to explain the result in plain language,
+// and attaches the answer as an extra text content block. Clients that did
+// not declare the sampling capability get the result alone.
+func (s *Server) SetResultExplanation(tool string, enabled bool) {
+	s.explanations.mu.Lock()
+	defer s.explanations.mu.Unlock()
+	if s.explanations.tools == nil {
+		s.explanations.tools = make(map[string]bool)
+	}
+	if enabled {
+		s.explanations.tools[tool] = true
+	} else {
+		delete(s.explanations.tools, tool)
+	}
+}
+
+// explanationEnabled reports whether results of the tool are explained
+func (s *Server) explanationEnabled(tool string) bool {
+	s.explanations.mu.RLock()
+	defer s.explanations.mu.RUnlock()
+	return s.explanations.tools[tool]
+}
+
+// explainedTools lists the tools whose results are explained, sorted
+func (s *Server) explainedTools() []string {
+	s.explanations.mu.RLock()
+	defer s.explanations.mu.RUnlock()
+	tools := make([]string, 0, len(s.explanations.tools))
+	for tool := range s.explanations.tools {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// samplingCapability is advertised in initialize when results are
+// explained. MCP only defines sampling as a client capability, so the
+// server's use of it is announced under experimental.
+func (s *Server) samplingCapability() map[string]interface{} {
+	tools := s.explainedTools()
+	if len(tools) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"explainResults": tools}
+}
+
+// canSample reports whether the client of ctx can be sent sampling/createMessage
+func (s *Server) canSample(ctx context.Context) bool {
+	if RequesterFromContext(ctx) == nil {
+		return false
+	}
+	client, known := s.Client(SessionIDFromContext(ctx))
+	return known && client.Capabilities.Sampling != nil
+}
+
+// samplingResult is the client's answer to sampling/createMessage
+type samplingResult struct {
+	Role    string `json:"role"`
+	Content struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Model string `json:"model"`
+}
+
+// explainResult asks the client's model to explain a tool result. It
+// returns "" when explanations do not apply to the call.
+func (s *Server) explainResult(ctx context.Context, schema ToolSchema, resultJSON []byte) (string, error) {
+	if !s.explanationEnabled(schema.Name) || !s.canSample(ctx) {
+		return "", nil
+	}
+
+	prompt := fmt.Sprintf("The %s calculator tool (%s) returned this result:\n\n%s\n\nExplain in two or three plain sentences what the result means for someone who is not a specialist. Do not recalculate it.",
+		schema.Name, schema.Description, resultJSON)
+	resultJSON, err := s.requestClient(ctx, MethodSamplingCreateMessage, map[string]interface{}{
+		"messages": []map[string]interface{}{
+			{"role": "user", "content": map[string]interface{}{"type": "text", "text": prompt}},
+		},
+		"systemPrompt":   "You explain the results of calculations clearly and accurately.",
+		"includeContext": "none",
+		"maxTokens":      explanationMaxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result samplingResult
+	if err := json.Unmarshal(resultJSON, &result); err != nil {
+		return "", fmt.Errorf("invalid sampling result: %v", err)
+	}
+	if result.Content.Type != "text" || strings.TrimSpace(result.Content.Text) == "" {
+		return "", fmt.Errorf("sampling result has no text content")
+	}
+	return strings.TrimSpace(result.Content.Text), nil
+}
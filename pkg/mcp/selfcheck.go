@@ -0,0 +1,184 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+// selfCheckTolerance is the relative tolerance used when comparing numbers
+const selfCheckTolerance = 1e-9
+
+// SelfCheck is a known-answer test for a tool: calling it with Arguments
+// must produce a result containing every field of Expected
+type SelfCheck struct {
+	Arguments map[string]interface{}
+	Expected  map[string]interface{}
+}
+
+// WithSelfCheck attaches a known-answer test to the tool for the self-check diagnostics
+func WithSelfCheck(arguments, expected map[string]interface{}) ToolOption {
+	return func(schema *ToolSchema) {
+		schema.SelfCheck = &SelfCheck{Arguments: arguments, Expected: expected}
+	}
+}
+
+// RunSelfChecks runs the known-answer test of every tool available in ctx,
+// or only of the named tools. Tools without a test are reported as skipped.
+// Self-checks bypass history and metrics so they can run against live servers.
+func (s *Server) RunSelfChecks(ctx context.Context, tools []string) (types.SelfCheckReport, error) {
+	names := tools
+	if len(names) == 0 {
+		for _, schema := range s.toolSchemas() {
+			if s.toolAvailable(ctx, schema) {
+				names = append(names, schema.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	report := types.SelfCheckReport{
+		Passed:  true,
+		Counts:  map[string]int{types.SelfCheckPassed: 0, types.SelfCheckFailed: 0, types.SelfCheckSkipped: 0},
+		Results: make([]types.SelfCheckResult, 0, len(names)),
+	}
+	for i, name := range names {
+		handler, schema, exists := s.lookupTool(name)
+		if !exists || !s.toolAvailable(ctx, schema) {
+			return types.SelfCheckReport{}, fmt.Errorf("unknown tool: %s", name)
+		}
+
+		result := s.runSelfCheck(withoutStreams(ctx), handler, schema)
+		ReportProgress(ctx, float64(i+1), float64(len(names)), fmt.Sprintf("%s: %s", name, result.Status))
+		report.Counts[result.Status]++
+		if result.Status == types.SelfCheckFailed {
+			report.Passed = false
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+func (s *Server) runSelfCheck(ctx context.Context, handler ContextToolHandler, schema ToolSchema) types.SelfCheckResult {
+	result := types.SelfCheckResult{Tool: schema.Name, Status: types.SelfCheckSkipped}
+	check := schema.SelfCheck
+	if check == nil {
+		return result
+	}
+
+	// The test arguments must still be accepted by the published schema
+	if unknown := unknownArguments(schema.InputSchema, check.Arguments); len(unknown) > 0 {
+		result.Status = types.SelfCheckFailed
+		result.Error = "schema rejects arguments " + describeUnknownArguments(unknown)
+		return result
+	}
+
+	// Handlers may modify their arguments, so each run gets its own copy
+	arguments := make(map[string]interface{}, len(check.Arguments))
+	for key, value := range check.Arguments {
+		arguments[key] = value
+	}
+
+	start := time.Now()
+	output, err := s.invokeTool(ctx, handler, arguments, nil)
+	result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	if err == nil {
+		err = matchesExpected(output, check.Expected)
+	}
+
+	if err != nil {
+		result.Status = types.SelfCheckFailed
+		result.Error = err.Error()
+	} else {
+		result.Status = types.SelfCheckPassed
+	}
+	return result
+}
+
+// HandleSelfCheck is the handler of the self-check diagnostics tool.
+// The optional "tools" argument limits the run to the named tools.
+func (s *Server) HandleSelfCheck(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	var tools []string
+	if list, ok := params["tools"].([]interface{}); ok {
+		for _, item := range list {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("tools must be an array of tool names")
+			}
+			tools = append(tools, name)
+		}
+	}
+
+	return s.RunSelfChecks(ctx, tools)
+}
+
+// matchesExpected compares output with expected after converting both to
+// their JSON form, so typed results and generic maps compare alike
+func matchesExpected(output interface{}, expected map[string]interface{}) error {
+	var actual, want interface{}
+	if err := jsonRoundTrip(output, &actual); err != nil {
+		return fmt.Errorf("result is not JSON encodable: %v", err)
+	}
+	if err := jsonRoundTrip(expected, &want); err != nil {
+		return fmt.Errorf("expected value is not JSON encodable: %v", err)
+	}
+	return matchValue("result", want, actual)
+}
+
+func jsonRoundTrip(value interface{}, target *interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// matchValue reports the first difference between want and got. Objects
+// match when got has every field of want; arrays must match element-wise.
+func matchValue(path string, want, got interface{}) error {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %v", path, got)
+		}
+		for key, value := range w {
+			field, exists := g[key]
+			if !exists {
+				return fmt.Errorf("%s.%s: missing", path, key)
+			}
+			if err := matchValue(path+"."+key, value, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			return fmt.Errorf("%s: expected %v, got %v", path, w, got)
+		}
+		for i := range w {
+			if err := matchValue(fmt.Sprintf("%s[%d]", path, i), w[i], g[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case float64:
+		g, ok := got.(float64)
+		if !ok || math.Abs(g-w) > selfCheckTolerance*math.Max(1, math.Abs(w)) {
+			return fmt.Errorf("%s: expected %v, got %v", path, w, got)
+		}
+		return nil
+	default:
+		if want != got {
+			return fmt.Errorf("%s: expected %v, got %v", path, want, got)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"calculator-server/internal/types"
+)
+
+// SessionStatsMethod is the MCP method that returns the calling session's statistics
+const SessionStatsMethod = "session/stats"
+
+// recordSessionRequest updates the session's usage statistics for req.
+// They are kept in memory and written to the store periodically.
+func (t *StreamableHTTPTransport) recordSessionRequest(sessionID string, req types.MCPRequest) {
+	t.sessionsMux.Lock()
+	session, exists := t.sessions[sessionID]
+	if !exists {
+		t.sessionsMux.Unlock()
+		return
+	}
+
+	session.RequestCount++
+	switch req.Method {
+	case "initialize":
+		var params types.InitializeParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			if params.ClientInfo != nil {
+				session.ClientInfo = params.ClientInfo
+			}
+			session.ProtocolVersion = negotiateProtocolVersion(params.ProtocolVersion)
+			session.Capabilities = &params.Capabilities
+		}
+	case NotificationInitialized:
+		session.Initialized = true
+	case "tools/call":
+		// Only the name is needed; the arguments are left to the call
+		var params struct {
+			Name string `json:"name"`
+		}
+		if !req.IsNotification() && json.Unmarshal(req.Params, &params) == nil {
+			session.ToolCalls++
+			session.LastTool = params.Name
+		}
+	}
+
+	// What the client declared is written at once, as other replicas and a
+	// restarted server need it; the counters wait for the next flush
+	if req.Method != "initialize" && req.Method != NotificationInitialized {
+		t.unsaved[sessionID] = struct{}{}
+		t.sessionsMux.Unlock()
+		return
+	}
+	snapshot := *session
+	t.sessionsMux.Unlock()
+	t.persistSession(snapshot)
+}
+
+// sessionSnapshot returns a copy of the session with the given ID
+func (t *StreamableHTTPTransport) sessionSnapshot(sessionID string) (types.Session, bool) {
+	t.sessionsMux.RLock()
+	defer t.sessionsMux.RUnlock()
+
+	session, exists := t.sessions[sessionID]
+	if !exists {
+		return types.Session{}, false
+	}
+	return *session, true
+}
+
+// sessionStatsResponse answers a session/stats request for the calling session
+func (t *StreamableHTTPTransport) sessionStatsResponse(id interface{}, sessionID string) types.MCPResponse {
+	session, exists := t.sessionSnapshot(sessionID)
+	if sessionID == "" || !exists {
+		return errorResponse(id, ErrorCodeResourceNotFound, "No session", "send the Mcp-Session-Id header of an active session")
+	}
+	return types.MCPResponse{
+		JSONRPC: "2.0",
+		ID:      id,
+		Result:  session,
+	}
+}
+
+// handleAdminSessions lists all sessions (GET /admin/sessions) or returns
+// one of them (GET /admin/sessions/{id})
+func (t *StreamableHTTPTransport) handleAdminSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	sessionID := strings.Trim(strings.TrimPrefix(r.URL.Path, adminPathPrefix+"sessions"), "/")
+	if sessionID != "" {
+		session, exists := t.sessionSnapshot(sessionID)
+		if !exists {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(session)
+		return
+	}
+
+	t.sessionsMux.RLock()
+	sessions := make([]types.Session, 0, len(t.sessions))
+	for _, session := range t.sessions {
+		sessions = append(sessions, *session)
+	}
+	t.sessionsMux.RUnlock()
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": sessions,
+	})
+}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"sync"
+
+	"calculator-server/internal/types"
+)
+
+// Reasons given in the close event that ends a standalone SSE stream
+const (
+	// CloseReasonShutdown: the server is stopping; the client may reconnect,
+	// possibly to another replica, and keep its session
+	CloseReasonShutdown = "server_shutdown"
+	// CloseReasonSessionExpired: the session timed out; the client must
+	// initialize a new one
+	CloseReasonSessionExpired = "session_expired"
+	// CloseReasonSessionEnded: the session was removed by the server
+	CloseReasonSessionEnded = "session_ended"
+)
+
+// streamRegistry tracks the open standalone SSE streams of each session, so
+// they can be told why they are about to end, with the NotifyFunc that
+// writes to each
+type streamRegistry struct {
+	mu       sync.Mutex
+	streams  map[string]map[chan string]NotifyFunc
+	shutdown bool
+}
+
+// open registers a stream of the session. The returned channel receives
+// the close reason once the stream must end; the function unregisters it.
+func (r *streamRegistry) open(sessionID string, notify NotifyFunc) (<-chan string, func()) {
+	closing := make(chan string, 1)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.shutdown {
+		closing <- CloseReasonShutdown
+		return closing, func() {}
+	}
+	if r.streams == nil {
+		r.streams = make(map[string]map[chan string]NotifyFunc)
+	}
+	if r.streams[sessionID] == nil {
+		r.streams[sessionID] = make(map[chan string]NotifyFunc)
+	}
+	r.streams[sessionID][closing] = notify
+
+	return closing, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.streams[sessionID], closing)
+		if len(r.streams[sessionID]) == 0 {
+			delete(r.streams, sessionID)
+		}
+	}
+}
+
+// count returns the number of open streams
+func (r *streamRegistry) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, streams := range r.streams {
+		n += len(streams)
+	}
+	return n
+}
+
+// broadcast sends a notification to every open stream
+func (r *streamRegistry) broadcast(notification types.MCPNotification) {
+	r.mu.Lock()
+	var sinks []NotifyFunc
+	for _, streams := range r.streams {
+		for _, notify := range streams {
+			sinks = append(sinks, notify)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, notify := range sinks {
+		notify(notification)
+	}
+}
+
+// close ends the streams of a session with the given reason
+func (r *streamRegistry) close(sessionID, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for closing := range r.streams[sessionID] {
+		signalClose(closing, reason)
+	}
+	delete(r.streams, sessionID)
+}
+
+// closeAll ends every stream, and any opened from now on, as the server
+// shuts down
+func (r *streamRegistry) closeAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shutdown = true
+	for _, streams := range r.streams {
+		for closing := range streams {
+			signalClose(closing, CloseReasonShutdown)
+		}
+	}
+	r.streams = nil
+}
+
+// signalClose hands a stream its close reason unless it already has one
+func signalClose(closing chan string, reason string) {
+	select {
+	case closing <- reason:
+	default:
+	}
+}
+
+// closeEvent is the payload of the final event of a standalone SSE stream
+func closeEvent(reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":      "close",
+		"reason":    reason,
+		"reconnect": reason == CloseReasonShutdown,
+	}
+}
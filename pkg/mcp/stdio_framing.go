@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StdioFraming is how messages are delimited on the stdio transport
+type StdioFraming string
+
+const (
+	// StdioFramingLine sends one JSON message per line, the MCP default
+	StdioFramingLine StdioFraming = "line"
+	// StdioFramingContentLength precedes each message with a
+	// Content-Length header and a blank line, as LSP clients do
+	StdioFramingContentLength StdioFraming = "content-length"
+)
+
+// DefaultStdioMaxMessageSize is the largest stdio message read when none
+// is configured
+const DefaultStdioMaxMessageSize = 1 << 20
+
+// DefaultStdioMaxCalls is the number of tool calls and batches run at once
+// when none is configured
+const DefaultStdioMaxCalls = 64
+
+// StdioConfig holds the stdio transport settings
+type StdioConfig struct {
+	Framing        StdioFraming // Empty is StdioFramingLine
+	MaxMessageSize int          // Largest message read, in bytes; 0 is DefaultStdioMaxMessageSize
+	MaxCalls       int          // Tool calls and batches run at once; 0 is DefaultStdioMaxCalls
+}
+
+// errMessageTooLarge reports a message over the size limit. The message is
+// skipped, so reading can go on with the next one.
+type errMessageTooLarge struct {
+	limit int
+}
+
+func (e errMessageTooLarge) Error() string {
+	return fmt.Sprintf("message exceeds the %d byte limit", e.limit)
+}
+
+// messageReader reads framed messages from the stdio input
+type messageReader struct {
+	reader  *bufio.Reader
+	framing StdioFraming
+	limit   int
+}
+
+func newMessageReader(in io.Reader, config StdioConfig) *messageReader {
+	return &messageReader{reader: bufio.NewReader(in), framing: config.Framing, limit: config.MaxMessageSize}
+}
+
+// next returns the next message, io.EOF once the input ends, or
+// errMessageTooLarge for a message that was skipped
+func (r *messageReader) next() ([]byte, error) {
+	if r.framing == StdioFramingContentLength {
+		return r.nextFramed()
+	}
+	return r.nextLine()
+}
+
+// nextLine reads up to the next newline, skipping blank lines
+func (r *messageReader) nextLine() ([]byte, error) {
+	for {
+		var line []byte
+		tooLarge := false
+		for {
+			chunk, err := r.reader.ReadSlice('\n')
+			if !tooLarge {
+				line = append(line, chunk...)
+				if len(bytes.TrimRight(line, "\r\n")) > r.limit {
+					tooLarge, line = true, nil
+				}
+			}
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if err != nil && (err != io.EOF || (len(line) == 0 && !tooLarge)) {
+				return nil, err
+			}
+			break
+		}
+
+		if tooLarge {
+			return nil, errMessageTooLarge{limit: r.limit}
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+// nextFramed reads a header block ended by a blank line, then the number of
+// bytes its Content-Length gives. Other headers, such as Content-Type, are
+// ignored.
+func (r *messageReader) nextFramed() ([]byte, error) {
+	length := -1
+	for sawHeader := false; ; {
+		line, err := r.reader.ReadString('\n')
+		if err == io.EOF && line == "" && !sawHeader {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading message header: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if !sawHeader {
+				continue
+			}
+			break
+		}
+		sawHeader = true
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("malformed message header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", strings.TrimSpace(value))
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message header has no Content-Length")
+	}
+
+	if length > r.limit {
+		if _, err := io.CopyN(io.Discard, r.reader, int64(length)); err != nil {
+			return nil, fmt.Errorf("reading message body: %w", err)
+		}
+		return nil, errMessageTooLarge{limit: r.limit}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r.reader, body); err != nil {
+		return nil, fmt.Errorf("reading message body: %w", err)
+	}
+	return body, nil
+}
+
+// frameMessage returns an encoded message ready to be written
+func frameMessage(framing StdioFraming, message []byte) []byte {
+	if framing == StdioFramingContentLength {
+		header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(message))
+		return append([]byte(header), message...)
+	}
+	return append(message, '\n')
+}
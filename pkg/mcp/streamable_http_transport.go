@@ -11,11 +11,16 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"calculator-server/internal/export"
+	"calculator-server/internal/storage"
 	"calculator-server/internal/types"
 )
 
@@ -27,25 +32,87 @@ import (
 // - CORS support with origin validation
 // - Graceful shutdown capabilities
 type StreamableHTTPTransport struct {
-	server      *http.Server              // HTTP server instance
-	mcpServer   *Server                   // Reference to the MCP server
-	config      *StreamableHTTPConfig     // Transport configuration
-	sessions    map[string]*types.Session // Active session storage
-	sessionsMux sync.RWMutex              // Mutex for thread-safe session access
-	connections int32                     // Current connection count (unused but reserved for future use)
+	server         *http.Server              // HTTP server instance
+	mcpServer      *Server                   // Reference to the MCP server
+	config         *StreamableHTTPConfig     // Transport configuration
+	sessions       map[string]*types.Session // Active session storage
+	sessionsMux    sync.RWMutex              // Mutex for thread-safe session access
+	sessionChecks  map[string]time.Time      // When shared sessions were last read from the store
+	unsaved        map[string]struct{}       // Sessions changed since they were last written to the store
+	connections    connectionTracker         // Requests in progress, limited to MaxConnections
+	opsServer      *http.Server              // Separate listener for /health and /metrics, if configured
+	started        time.Time                 // When the transport was created, for /health
+	stopping       atomic.Bool               // Set once Stop is called, failing /health
+	streams        streamRegistry            // Open standalone SSE streams, sent a close event before they end
+	tokens         *tokenValidator           // Checks OAuth access tokens, if configured
+	limiter        *rateLimiter              // Per-client request buckets, if rate limiting is configured
+	idempotency    idempotencyCache          // Responses replayed to retried requests carrying an Idempotency-Key
+	chaos          *chaosInjector            // Injects faults for client testing, if configured
+	trustedProxies []*net.IPNet              // Peers whose X-Forwarded-For and X-Forwarded-Host headers are believed
+	allowedOrigins []allowedOrigin           // Origins browsers may send requests from
+	allOrigins     bool                      // The allow-list contains "*"
 }
 
 // StreamableHTTPConfig contains MCP-compliant HTTP transport configuration
 // All settings follow MCP specification requirements for streamable HTTP transport
 type StreamableHTTPConfig struct {
-	Host           string        // Server host (defaults to 127.0.0.1 for security)
-	Port           int           // Server port (e.g., 8080)
-	SessionTimeout time.Duration // How long sessions remain active without activity
-	MaxConnections int           // Maximum concurrent connections allowed
-	CORSEnabled    bool          // Whether to enable CORS headers
-	CORSOrigins    []string      // Allowed origins for CORS requests
+	Host               string           // Server host (defaults to 127.0.0.1 for security)
+	Port               int              // Server port (e.g., 8080)
+	SessionTimeout     time.Duration    // How long sessions remain active without activity
+	MaxConnections     int              // Maximum requests served at once, including open SSE streams; 0 is unlimited
+	CORSEnabled        bool             // Whether to enable CORS headers
+	CORSOrigins        []string         // Allowed origins for CORS requests
+	CORSMethods        []string         // Allowed methods; defaults to GET, POST, OPTIONS
+	CORSHeaders        []string         // Allowed request headers; defaults to the MCP headers
+	CORSExposedHeaders []string         // Response headers readable by browsers; defaults to Mcp-Session-Id, X-Request-Id and WWW-Authenticate
+	CORSCredentials    bool             // Send Access-Control-Allow-Credentials: true
+	CORSMaxAge         time.Duration    // How long browsers may cache preflights; defaults to 24 hours
+	MetricsEnabled     bool             // Whether to expose per-tool usage metrics on /metrics
+	HealthEnabled      bool             // Whether to answer load balancer checks on /health
+	OpsPort            int              // Serve /health and /metrics on this port instead of Port, with TLS when set; 0 keeps them on Port
+	Store              storage.Store    // Optional store so sessions survive server restarts
+	SharedSessions     bool             // Store is shared by replicas: check every session against it, not only on a miss
+	Jobs               *JobManager      // Optional async job manager served on /jobs
+	Tenants            *TenantRegistry  // Optional tenants; requests must then carry a tenant API key
+	Auth               *Authenticator   // Optional API keys and bearer tokens every request must carry
+	OAuth              *OAuthConfig     // Require OAuth access tokens from an authorization server; nil disables it
+	RateLimit          *RateLimitConfig // Per-client request limit; nil disables it
+	AdminToken         string           // Bearer token enabling the /admin/ API; empty disables it
+	DisableSSE         bool             // Answer every POST with plain JSON and reject GET streams
+	Stateless          bool             // Ignore Mcp-Session-Id and never create sessions
+	RequireSession     bool             // Reject requests other than initialize that carry no Mcp-Session-Id
+	RequestIDMeta      bool             // Also return the X-Request-Id as _meta.requestId of tool results
+	MaxRequestBytes    int64            // Largest request body read; 0 is DefaultMaxRequestBytes
+	SSEFlushInterval   time.Duration    // Batch SSE events and write them this often; 0 writes each event at once
+	SSEMaxPending      int              // Events a batched SSE stream queues before it is closed; 0 is DefaultSSEMaxPending
+	IdempotencyTTL     time.Duration    // How long responses to keyed job and admin requests are replayed; 0 is DefaultIdempotencyTTL
+	Chaos              *ChaosConfig     // Inject delays, transient errors and dropped SSE events; nil disables it
+	TrustedProxies     []string         // IPs and CIDR ranges of reverse proxies whose X-Forwarded-For and X-Forwarded-Host headers are honoured
+	BasePath           string           // Path prefix the routes are served under, e.g. "/calc" for /calc/mcp
+	AllowedOrigins     []string         // Origins browsers may send requests from, besides the CORS origins; defaults to DefaultAllowedOrigins
+	TLS                *TLSConfig       // Serve HTTPS, optionally requiring client certificates; nil serves plain HTTP
 }
 
+// sessionBucket is the store bucket holding persisted sessions
+const sessionBucket = "sessions"
+
+// sharedSessionTTL is how long a session read from a shared store is used
+// before the next request reads it again, so that sessions ended by another
+// replica are noticed soon without a store read per request
+const sharedSessionTTL = time.Second
+
+// sessionFlushInterval is how often sessions whose activity or statistics
+// changed are written to the store, unless the session timeout is shorter
+const sessionFlushInterval = 5 * time.Second
+
+// DefaultSSEMaxPending is how many events a batched SSE stream queues when
+// the transport sets no limit
+const DefaultSSEMaxPending = 256
+
+// sseWriteTimeout is how long a client may take to receive a batch of SSE
+// events before its stream is given up
+const sseWriteTimeout = 10 * time.Second
+
 // NewStreamableHTTPTransport creates a new MCP-compliant HTTP transport instance
 // This constructor sets up the HTTP server with MCP protocol compliance:
 // - Defaults to localhost binding for security per MCP specification
@@ -68,26 +135,114 @@ func NewStreamableHTTPTransport(mcpServer *Server, config *StreamableHTTPConfig)
 	if config.CORSEnabled && len(config.CORSOrigins) == 0 {
 		config.CORSOrigins = []string{"http://localhost:3000", "http://127.0.0.1:3000"}
 	}
+	if len(config.CORSMethods) == 0 {
+		config.CORSMethods = []string{"GET", "POST", "OPTIONS"}
+	}
+	if len(config.CORSHeaders) == 0 {
+		config.CORSHeaders = []string{"Content-Type", "Accept", "Authorization", "MCP-Protocol-Version", "Mcp-Session-Id", RequestIDHeader, TenantAPIKeyHeader, IdempotencyKeyHeader}
+	}
+	if len(config.CORSExposedHeaders) == 0 {
+		config.CORSExposedHeaders = []string{"Mcp-Session-Id", RequestIDHeader, "WWW-Authenticate"}
+	}
+	if config.CORSMaxAge == 0 {
+		config.CORSMaxAge = 24 * time.Hour
+	}
 
 	// Initialize the transport with thread-safe session storage
 	transport := &StreamableHTTPTransport{
-		mcpServer: mcpServer,
-		config:    config,
-		sessions:  make(map[string]*types.Session), // Thread-safe session map
+		mcpServer:     mcpServer,
+		config:        config,
+		sessions:      make(map[string]*types.Session), // Thread-safe session map
+		sessionChecks: make(map[string]time.Time),
+		unsaved:       make(map[string]struct{}),
+		started:       time.Now(),
 	}
+	if config.OAuth != nil {
+		transport.tokens = newTokenValidator(config.OAuth)
+	}
+	if config.RateLimit != nil {
+		transport.limiter = newRateLimiter()
+	}
+	config.BasePath = normalizeBasePath(config.BasePath)
+	if len(config.TrustedProxies) > 0 {
+		proxies, err := ParseTrustedProxies(config.TrustedProxies)
+		if err != nil {
+			log.Printf("Ignoring trusted proxies: %v", err)
+		}
+		transport.trustedProxies = proxies
+	}
+	origins := config.AllowedOrigins
+	if len(origins) == 0 {
+		origins = DefaultAllowedOrigins
+	}
+	if config.CORSEnabled {
+		origins = append(origins[:len(origins):len(origins)], config.CORSOrigins...)
+	}
+	all, allowed, err := parseAllowedOrigins(origins)
+	if err != nil {
+		log.Printf("Ignoring allowed origins: %v", err)
+		_, allowed, _ = parseAllowedOrigins(DefaultAllowedOrigins)
+	}
+	transport.allOrigins, transport.allowedOrigins = all, allowed
+	if config.Chaos != nil {
+		transport.chaos = newChaosInjector(*config.Chaos)
+		log.Printf("WARNING: fault injection is enabled; requests will be delayed, failed and SSE events dropped on purpose")
+	}
+	transport.opsServer = transport.newOpsServer()
 
 	// Setup HTTP routing with MCP-compliant endpoints
 	mux := http.NewServeMux()
 	transport.setupRoutes(mux)
 
-	// Create HTTP server with CORS middleware
+	// Resolve tenants ahead of routing when the server is multi-tenant
+	var handler http.Handler = mux
+	if config.Chaos != nil {
+		handler = transport.chaosMiddleware(handler)
+	}
+	if config.Tenants != nil {
+		handler = transport.tenantMiddleware(handler)
+	}
+	if config.Auth != nil {
+		handler = transport.authMiddleware(handler)
+	}
+	if config.OAuth != nil {
+		handler = transport.oauthMiddleware(handler)
+	}
+	if config.AdminToken != "" {
+		handler = transport.adminTokenMiddleware(handler)
+	}
+	if config.RateLimit != nil {
+		handler = transport.rateLimitMiddleware(handler)
+	}
+	handler = transport.bodyLimitMiddleware(handler)
+	handler = transport.connectionLimitMiddleware(handler)
+	if config.BasePath != "" {
+		// Routes and the middleware above see paths without the prefix
+		handler = http.StripPrefix(config.BasePath, handler)
+		if config.OAuth != nil {
+			// Clients look for the protected resource metadata at the root
+			// of the host, which the base path does not cover
+			root := http.NewServeMux()
+			root.HandleFunc(protectedResourcePath, transport.handleProtectedResourceMetadata)
+			root.HandleFunc(protectedResourcePath+"/", transport.handleProtectedResourceMetadata)
+			root.Handle("/", handler)
+			handler = root
+		}
+	}
+
+	// Create HTTP server with CORS middleware, assigning request IDs first so
+	// that even rejected requests can be correlated, and turning away
+	// disallowed origins before anything else is served
 	transport.server = &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", config.Host, config.Port),
-		Handler: transport.corsMiddleware(mux), // Wrap with CORS support
+		Handler: transport.proxyMiddleware(transport.requestIDMiddleware(transport.originMiddleware(transport.corsMiddleware(handler)))),
 	}
 
 	// Start background session cleanup goroutine to prevent memory leaks
 	go transport.cleanupExpiredSessions()
+	if config.Store != nil {
+		go transport.flushSessionsPeriodically()
+	}
 
 	return transport
 }
@@ -97,6 +252,182 @@ func NewStreamableHTTPTransport(mcpServer *Server, config *StreamableHTTPConfig)
 func (t *StreamableHTTPTransport) setupRoutes(mux *http.ServeMux) {
 	// Single MCP endpoint as per specification - handles both POST (JSON-RPC) and GET (SSE)
 	mux.HandleFunc("/mcp", t.handleMCP)
+
+	// Example invocations of the tools, for clients composing few-shot prompts
+	mux.HandleFunc(toolExamplesPath, t.handleToolExamples)
+
+	// Operational endpoints, disabled by default to keep the single-endpoint
+	// surface, unless they have a listener of their own
+	if t.config.OpsPort == 0 {
+		t.setupOpsRoutes(mux)
+	}
+
+	// History export is only meaningful when calculation history is persisted
+	if t.config.Store != nil {
+		mux.HandleFunc("/export", t.handleExport)
+	}
+
+	// OAuth clients discover the authorization server here, at the root
+	// and at the path of the resource; under a base path the metadata is
+	// served outside it
+	if t.config.OAuth != nil && t.config.BasePath == "" {
+		mux.HandleFunc(protectedResourcePath, t.handleProtectedResourceMetadata)
+		mux.HandleFunc(protectedResourcePath+"/", t.handleProtectedResourceMetadata)
+	}
+
+	// Authenticated clients can check their quota usage
+	if t.config.Auth != nil {
+		mux.HandleFunc(usagePath, t.handleUsage)
+	}
+
+	// Administrative API, guarded by its own bearer token
+	if t.config.AdminToken != "" {
+		mux.HandleFunc(adminPathPrefix+"usage", t.adminMiddleware(t.handleAdminUsage))
+		mux.HandleFunc(adminPathPrefix+"tool-groups", t.adminMiddleware(t.idempotent(t.handleAdminToolGroups)))
+		mux.HandleFunc(adminPathPrefix+"sessions", t.adminMiddleware(t.handleAdminSessions))
+		mux.HandleFunc(adminPathPrefix+"sessions/", t.adminMiddleware(t.handleAdminSessions))
+	}
+
+	// Asynchronous job submission and polling, disabled unless configured.
+	// Retried submissions carrying an Idempotency-Key start no second job.
+	if t.config.Jobs != nil {
+		mux.HandleFunc("/jobs", t.idempotent(t.handleJobs))
+		mux.HandleFunc("/jobs/", t.handleJobs)
+	}
+}
+
+// handleMetrics serves per-tool invocation counts, error counts and latency
+// percentiles, along with the statistics of the registry's other sources
+func (t *StreamableHTTPTransport) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	metrics := t.mcpServer.Metrics().Sources()
+	metrics["tools"] = t.mcpServer.Metrics().Snapshot()
+	metrics["connections"] = t.ConnectionStats()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
+
+// handleExport serves the calculation history of the session named in the
+// Mcp-Session-Id header as a CSV or JSON attachment (?format=csv|json)
+func (t *StreamableHTTPTransport) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if sessionID == "" || !t.isValidSession(sessionID, tenantID(r.Context())) {
+		http.Error(w, "Invalid or expired session", http.StatusNotFound)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	history, err := t.mcpServer.History(sessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	document, err := export.Encode(export.HistoryTable(history), format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.MimeType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"history.%s\"", format))
+	w.Write(document)
+}
+
+// handleJobs submits tool calls as background jobs (POST /jobs with a
+// tools/call params body) and reports their state (GET /jobs/{id})
+func (t *StreamableHTTPTransport) handleJobs(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get("Mcp-Session-Id")
+	if t.config.Stateless {
+		sessionID = ""
+	}
+	if sessionID != "" && !t.isValidSession(sessionID, tenantID(r.Context())) {
+		http.Error(w, "Invalid or expired session", http.StatusNotFound)
+		return
+	}
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/jobs"), "/")
+
+	switch {
+	case r.Method == http.MethodPost && id == "":
+		var params types.CallToolParams
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&params); err != nil {
+			if isBodyTooLarge(err) {
+				t.writeBodyTooLarge(w, err)
+				return
+			}
+			http.Error(w, "Invalid job parameters: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		job, mcpErr := t.config.Jobs.Submit(WithSessionID(r.Context(), sessionID), params)
+		if mcpErr != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(mapErrorCodeToHTTPStatus(mcpErr.Code))
+			json.NewEncoder(w).Encode(mcpErr)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", t.config.BasePath+"/jobs/"+job.ID)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+	case r.Method == http.MethodGet && id != "":
+		job, exists := t.config.Jobs.Get(id)
+		if !exists || job.TenantID != tenantID(r.Context()) {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// tenantMiddleware resolves the tenant from its API key and applies the
+// tenant's rate limit before the request reaches any endpoint
+func (t *StreamableHTTPTransport) tenantMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The admin API authenticates with its own token, and load
+		// balancers checking health have no tenant
+		if strings.HasPrefix(r.URL.Path, adminPathPrefix) || r.URL.Path == healthPath || IsAdmin(r.Context()) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		tenant, ok := t.config.Tenants.Resolve(r.Header.Get(TenantAPIKeyHeader))
+		if !ok {
+			t.writeErrorResponse(w, nil, ErrorCodeAuthenticationRequired, "Authentication required", "missing or unknown "+TenantAPIKeyHeader)
+			return
+		}
+
+		if tenant.RequestsPerMinute > 0 {
+			if allowed, retryAfter := t.config.Tenants.limiter.allow(tenant.ID, 1, tenant.RequestsPerMinute); !allowed {
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+				t.writeErrorResponse(w, nil, ErrorCodeRateLimitExceeded, "Rate limit exceeded", "tenant "+tenant.ID)
+				return
+			}
+		}
+
+		handler.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), tenant)))
+	})
 }
 
 // corsMiddleware adds CORS headers if enabled
@@ -107,14 +438,22 @@ func (t *StreamableHTTPTransport) corsMiddleware(handler http.Handler) http.Hand
 		// Apply CORS headers if enabled in configuration
 		if t.config.CORSEnabled {
 			origin := r.Header.Get("Origin")
-			// Only allow configured origins for security
+			// Only allow configured origins for security. The Host header
+			// names whatever a rebound DNS name resolved to, so the
+			// server's apparent own origin is not trusted. The allowed
+			// origin is echoed, so caches must key responses on it
+			w.Header().Add("Vary", "Origin")
 			if t.isOriginAllowed(origin) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if t.config.CORSCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
 			}
 			// Set required CORS headers for MCP protocol
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, MCP-Protocol-Version, Mcp-Session-Id")
-			w.Header().Set("Access-Control-Max-Age", "86400") // Cache preflight for 24 hours
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(t.config.CORSMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(t.config.CORSHeaders, ", "))
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(t.config.CORSExposedHeaders, ", "))
+			w.Header().Set("Access-Control-Max-Age", fmt.Sprintf("%d", int(t.config.CORSMaxAge.Seconds())))
 
 			// Handle CORS preflight requests
 			if r.Method == "OPTIONS" {
@@ -154,10 +493,14 @@ func (t *StreamableHTTPTransport) handleMCP(w http.ResponseWriter, r *http.Reque
 
 	// Step 2: Handle optional session management
 	// Sessions provide state continuity across multiple requests
+	// Stateless deployments (e.g. behind a load balancer) treat every request on its own
 	sessionID := r.Header.Get("Mcp-Session-Id")
+	if t.config.Stateless {
+		sessionID = ""
+	}
 	if sessionID != "" {
 		// Validate session exists and hasn't expired
-		if !t.isValidSession(sessionID) {
+		if !t.isValidSession(sessionID, tenantID(r.Context())) {
 			http.Error(w, "Invalid or expired session", http.StatusUnauthorized)
 			return
 		}
@@ -191,39 +534,168 @@ func (t *StreamableHTTPTransport) handlePOST(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Step 2: Read the JSON-RPC request from request body
+	// Step 2: Read the JSON-RPC request from request body, which the body
+	// limit keeps to MaxRequestBytes
 	body, err := io.ReadAll(r.Body)
+	if isBodyTooLarge(err) {
+		t.writeBodyTooLarge(w, err)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
+	if errResponse := t.mcpServer.checkMessage(body); errResponse != nil {
+		t.writeJSONResponse(w, *errResponse)
+		return
+	}
+
+	// Answers to requests the server sent on an event stream, such as
+	// elicitations, belong to the tool call waiting for them
+	if t.mcpServer.handleClientResponse(WithSessionID(r.Context(), sessionID), body) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 
 	// Step 3: Parse JSON-RPC request according to MCP specification
-	var mcpReq types.MCPRequest
-	if err := json.Unmarshal(body, &mcpReq); err != nil {
-		// Send proper JSON-RPC error response for invalid requests
-		t.writeErrorResponse(w, nil, ErrorCodeInvalidRequest, "Invalid JSON-RPC request", err.Error())
+	// A JSON array is a batch, answered with an array of responses
+	if members, isBatch, errResponse := splitBatch(body); isBatch {
+		if errResponse != nil {
+			t.writeJSONResponse(w, *errResponse)
+			return
+		}
+		// initialize cannot be batched, so batches belong to a session
+		if sessionID == "" && !t.requireSession(w) {
+			return
+		}
+		if !t.chargeBatch(w, r, len(members)) {
+			return
+		}
+		t.handleBatch(w, r, sessionID, members)
+		return
+	}
+	mcpReq, errResponse := parseRequest(body)
+	if errResponse != nil {
+		t.writeJSONResponse(w, *errResponse)
+		return
+	}
+
+	// Per the streamable HTTP specification the session is assigned in the
+	// response to initialize; the session ID then names it on later requests
+	initialize := mcpReq.Method == "initialize" && !mcpReq.IsNotification()
+	created := false
+	if initialize && sessionID == "" && !t.config.Stateless {
+		sessionID = t.createSession(tenantID(r.Context()))
+		created = true
+	}
+	if sessionID == "" && !initialize && !t.requireSession(w) {
 		return
 	}
 
 	// Step 4: Process the request through the MCP server
-	response := t.mcpServer.HandleRequest(mcpReq)
+	// SSE requests can receive notifications (e.g. partial results) ahead of the response
+	ctx := WithSessionID(r.Context(), sessionID)
+	streaming := !t.config.DisableSSE && strings.Contains(accept, "text/event-stream") && t.shouldStream(&mcpReq)
+	if !streaming {
+		// Step 5: Use standard JSON response for quick operations
+		response, ok := t.respond(ctx, sessionID, mcpReq)
+		if !ok {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if created {
+			// A failed initialize leaves no session behind
+			if response.Error != nil {
+				t.dropSession(sessionID)
+			} else {
+				w.Header().Set("Mcp-Session-Id", sessionID)
+				logf(r.Context(), "Created new session: %s", sessionID)
+			}
+		}
+		t.writeJSONResponse(w, response)
+		return
+	}
+
+	if sessionID != "" {
+		t.recordSessionRequest(sessionID, mcpReq)
+	}
+	stream := t.newSSEStream(ctx, w, sessionID)
+	ctx = WithRequester(WithNotifier(ctx, stream.notify), stream.request)
+	response := t.serve(ctx, mcpReq)
 
-	// Step 5: Choose response format based on client preferences and request type
-	if strings.Contains(accept, "text/event-stream") && t.shouldStream(&mcpReq) {
-		// Use SSE streaming for real-time responses (e.g., long-running operations)
-		t.writeSSEResponse(w, response, sessionID)
+	// Step 5: Stream the response for potentially long-running operations
+	if stream.opened() {
+		// Notifications already opened the event stream, so finish it with the response
+		stream.reply(response)
 	} else {
-		// Use standard JSON response for quick operations
-		t.writeJSONResponse(w, response)
+		t.writeSSEResponse(ctx, w, response, sessionID)
+	}
+}
+
+// handleBatch answers a JSON-RPC batch with an array of responses in request
+// order. Batches are never streamed; when every member is a notification
+// the batch is acknowledged with 202 Accepted.
+func (t *StreamableHTTPTransport) handleBatch(w http.ResponseWriter, r *http.Request, sessionID string, members []json.RawMessage) {
+	ctx := WithSessionID(r.Context(), sessionID)
+	responses := answerBatch(members, func(req types.MCPRequest) (types.MCPResponse, bool) {
+		return t.respond(ctx, sessionID, req)
+	})
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// respond records and answers a request that is not streamed. It returns
+// false for notifications, which get no response.
+func (t *StreamableHTTPTransport) respond(ctx context.Context, sessionID string, req types.MCPRequest) (types.MCPResponse, bool) {
+	if sessionID != "" {
+		t.recordSessionRequest(sessionID, req)
+	}
+	switch {
+	case req.IsNotification():
+		t.mcpServer.HandleRequestContext(ctx, req)
+		return types.MCPResponse{}, false
+	case req.Method == SessionStatsMethod:
+		// Session statistics live in the transport, so answer them here
+		return t.sessionStatsResponse(req.ID, sessionID), true
+	default:
+		return t.serve(ctx, req), true
+	}
+}
+
+// serve processes a request through the MCP server
+func (t *StreamableHTTPTransport) serve(ctx context.Context, req types.MCPRequest) types.MCPResponse {
+	response := t.mcpServer.HandleRequestContext(ctx, req)
+	if t.config.RequestIDMeta {
+		if result, ok := response.Result.(types.CallToolResult); ok {
+			meta := types.ResultMeta{}
+			if result.Meta != nil {
+				meta = *result.Meta
+			}
+			meta.RequestID = RequestIDFromContext(ctx)
+			result.Meta = &meta
+			response.Result = result
+		}
 	}
+	return response
 }
 
 // handleGET handles GET requests for SSE streams
 // This method establishes Server-Sent Event streams for real-time communication
 // Used when clients want to maintain persistent connections for streaming updates
 func (t *StreamableHTTPTransport) handleGET(w http.ResponseWriter, r *http.Request, sessionID string) {
+	// Standalone streams need SSE and a session to belong to; per the MCP
+	// specification servers without them answer 405
+	if t.config.DisableSSE || t.config.Stateless {
+		http.Error(w, "SSE streams are not offered by this server", http.StatusMethodNotAllowed)
+		return
+	}
+
 	// Step 1: Validate Accept header - GET requests must accept SSE
 	accept := r.Header.Get("Accept")
 	if !strings.Contains(accept, "text/event-stream") {
@@ -233,8 +705,11 @@ func (t *StreamableHTTPTransport) handleGET(w http.ResponseWriter, r *http.Reque
 
 	// Create new session if not provided
 	if sessionID == "" {
-		sessionID = t.createSession()
-		log.Printf("Created new session: %s", sessionID)
+		if !t.requireSession(w) {
+			return
+		}
+		sessionID = t.createSession(tenantID(r.Context()))
+		logf(r.Context(), "Created new session: %s", sessionID)
 	}
 
 	// Setup SSE stream
@@ -243,12 +718,13 @@ func (t *StreamableHTTPTransport) handleGET(w http.ResponseWriter, r *http.Reque
 
 // shouldStream determines if a request should use SSE streaming
 func (t *StreamableHTTPTransport) shouldStream(req *types.MCPRequest) bool {
-	// For now, we'll stream for tool calls that might take longer
-	return req.Method == "tools/call"
+	// For now, we'll stream for tool calls that might take longer;
+	// notifications have no response to stream
+	return req.Method == "tools/call" && !req.IsNotification()
 }
 
 // writeSSEResponse writes a response using Server-Sent Events
-func (t *StreamableHTTPTransport) writeSSEResponse(w http.ResponseWriter, response types.MCPResponse, sessionID string) {
+func (t *StreamableHTTPTransport) writeSSEResponse(ctx context.Context, w http.ResponseWriter, response types.MCPResponse, sessionID string) {
 	// Setup SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -268,12 +744,12 @@ func (t *StreamableHTTPTransport) writeSSEResponse(w http.ResponseWriter, respon
 	eventID := t.generateEventID()
 	responseJSON, err := json.Marshal(response)
 	if err != nil {
-		log.Printf("Failed to marshal response for session %s, event %s: %v", sessionID, eventID, err)
+		logf(ctx, "Failed to marshal response for session %s, event %s: %v", sessionID, eventID, err)
 		// Send error response to client
-		errorResponse := fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"error":{"code":-32603,"message":"Internal error: failed to serialize response"}}`, response.ID)
+		errorJSON, _ := json.Marshal(errorResponse(response.ID, ErrorCodeInternalError, "Internal error: failed to serialize response", nil))
 		fmt.Fprintf(w, "id: %s\n", eventID)
 		fmt.Fprintf(w, "event: error\n")
-		fmt.Fprintf(w, "data: %s\n\n", errorResponse)
+		fmt.Fprintf(w, "data: %s\n\n", errorJSON)
 		flusher.Flush()
 		return
 	}
@@ -284,40 +760,321 @@ func (t *StreamableHTTPTransport) writeSSEResponse(w http.ResponseWriter, respon
 	flusher.Flush()
 }
 
-// setupSSEStream establishes an SSE stream connection
-func (t *StreamableHTTPTransport) setupSSEStream(w http.ResponseWriter, r *http.Request, sessionID string) {
-	// Setup SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Mcp-Session-Id", sessionID)
+// sseStream writes events to an SSE response, opening it on first use.
+// With a flush interval, events are queued and written in batches; a
+// progress update still queued when the next one for the same token
+// arrives is replaced by it. Senders never wait for the client: a stream
+// whose queue overflows, or whose client does not take a batch within
+// sseWriteTimeout, is closed, so a slow client neither makes the stream
+// buffer without bound nor holds up the tool or other sessions.
+type sseStream struct {
+	transport *StreamableHTTPTransport
+	ctx       context.Context
+	w         http.ResponseWriter
+	sessionID string
+	mu        sync.Mutex
+	flusher   http.Flusher
+	failed    bool
 
-	flusher, ok := w.(http.Flusher)
+	interval   time.Duration
+	maxPending int
+	pending    []sseEvent
+	progress   map[string]int // Index in pending of the queued progress update of each token
+	flushing   bool           // Whether flushLoop runs
+	finished   bool
+	done       chan struct{} // Closed by finish to stop flushLoop
+	flushed    chan struct{} // Closed when flushLoop returns
+	broken     chan struct{} // Closed when the stream fails, so that its handler can end it
+}
+
+// sseEvent is an event waiting to be written
+type sseEvent struct {
+	name string
+	data []byte
+}
+
+func (t *StreamableHTTPTransport) newSSEStream(ctx context.Context, w http.ResponseWriter, sessionID string) *sseStream {
+	stream := &sseStream{
+		transport:  t,
+		ctx:        ctx,
+		w:          w,
+		sessionID:  sessionID,
+		interval:   t.config.SSEFlushInterval,
+		maxPending: t.config.SSEMaxPending,
+		done:       make(chan struct{}),
+		flushed:    make(chan struct{}),
+		broken:     make(chan struct{}),
+	}
+	if stream.maxPending <= 0 {
+		stream.maxPending = DefaultSSEMaxPending
+	}
+	return stream
+}
+
+// fail gives up on the stream, dropping whatever is queued; the caller
+// holds s.mu
+func (s *sseStream) fail() {
+	if s.failed {
+		return
+	}
+	s.failed = true
+	s.pending, s.progress = nil, nil
+	close(s.broken)
+}
+
+// opened reports whether any event has been written to the stream or
+// queued for it
+func (s *sseStream) opened() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flusher != nil || s.flushing
+}
+
+// notify implements NotifyFunc
+func (s *sseStream) notify(notification types.MCPNotification) {
+	if s.transport.chaos.dropEvent() {
+		return
+	}
+	s.send("message", notification)
+}
+
+// request implements RequestFunc
+func (s *sseStream) request(request types.MCPRequest) {
+	s.send("message", request)
+}
+
+// send writes one SSE event carrying payload as JSON, or queues it when
+// the stream is batched
+func (s *sseStream) send(event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logf(s.ctx, "Failed to marshal SSE event for session %s: %v", s.sessionID, err)
+		return
+	}
+	if s.interval > 0 {
+		s.enqueue(sseEvent{event, data}, progressToken(payload))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed || s.finished || !s.open() {
+		return
+	}
+	if err := s.writeEvents([]sseEvent{{event, data}}); err != nil {
+		logf(s.ctx, "Closing SSE stream of session %s: %v", s.sessionID, err)
+		s.fail()
+	}
+}
+
+// open sets the SSE headers before the first event; the caller holds s.mu.
+// It returns false when the response cannot be streamed.
+func (s *sseStream) open() bool {
+	if s.flusher != nil {
+		return true
+	}
+	flusher, ok := s.w.(http.Flusher)
 	if !ok {
+		s.fail()
+		logf(s.ctx, "Cannot stream events for session %s: response writer does not support flushing", s.sessionID)
+		return false
+	}
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("Connection", "keep-alive")
+	if s.sessionID != "" {
+		s.w.Header().Set("Mcp-Session-Id", s.sessionID)
+	}
+	s.flusher = flusher
+	return true
+}
+
+// write writes one event without flushing it
+func (s *sseStream) write(event sseEvent) error {
+	_, err := fmt.Fprintf(s.w, "id: %s\nevent: %s\ndata: %s\n\n", s.transport.generateEventID(), event.name, event.data)
+	return err
+}
+
+// writeEvents writes events with a single flush, failing when the client
+// does not take them within sseWriteTimeout
+func (s *sseStream) writeEvents(events []sseEvent) error {
+	controller := http.NewResponseController(s.w)
+	// Writers that cannot set deadlines, such as test recorders, go without
+	if err := controller.SetWriteDeadline(time.Now().Add(sseWriteTimeout)); err == nil {
+		defer controller.SetWriteDeadline(time.Time{})
+	}
+	for _, event := range events {
+		if err := s.write(event); err != nil {
+			return err
+		}
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// progressToken returns the token of a progress notification, keyed for
+// comparison, or "" for any other payload
+func progressToken(payload interface{}) string {
+	notification, ok := payload.(types.MCPNotification)
+	if !ok || notification.Method != NotificationProgress {
+		return ""
+	}
+	params, ok := notification.Params.(types.ProgressParams)
+	if !ok || params.ProgressToken == nil {
+		return ""
+	}
+	return fmt.Sprintf("%T:%v", params.ProgressToken, params.ProgressToken)
+}
+
+// enqueue queues an event for the next flush. A progress update replaces
+// the queued one of the same token, since only the latest counts. When the
+// queue is full the client is not keeping up, and the stream is closed
+// rather than making the sender wait.
+func (s *sseStream) enqueue(event sseEvent, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed || s.finished {
+		return
+	}
+	if i, ok := s.progress[token]; ok && token != "" {
+		s.pending[i] = event
+		return
+	}
+	if len(s.pending) >= s.maxPending {
+		logf(s.ctx, "Closing SSE stream of session %s: %d events are waiting for the client", s.sessionID, len(s.pending))
+		s.fail()
+		return
+	}
+
+	if token != "" {
+		if s.progress == nil {
+			s.progress = make(map[string]int)
+		}
+		s.progress[token] = len(s.pending)
+	}
+	s.pending = append(s.pending, event)
+	if !s.flushing {
+		s.flushing = true
+		go s.flushLoop()
+	}
+}
+
+// flushLoop writes the queued events every interval until the stream
+// finishes or the client goes away
+func (s *sseStream) flushLoop() {
+	defer close(s.flushed)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flushPending()
+		case <-s.done:
+			return
+		case <-s.ctx.Done():
+			s.mu.Lock()
+			s.fail()
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// flushPending writes the queued events with a single flush. Senders may
+// queue more while the batch is being written.
+func (s *sseStream) flushPending() {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	clear(s.progress)
+	if len(batch) == 0 || s.failed || !s.open() {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	if err := s.writeEvents(batch); err != nil {
+		logf(s.ctx, "Closing SSE stream of session %s: %v", s.sessionID, err)
+		s.mu.Lock()
+		s.fail()
+		s.mu.Unlock()
+	}
+}
+
+// reply sends the response that ends the stream and finishes it. The
+// response is queued even when the queue is full, as nothing follows it.
+func (s *sseStream) reply(response interface{}) {
+	s.mu.Lock()
+	s.maxPending++
+	s.mu.Unlock()
+	s.send("message", response)
+	s.finish()
+}
+
+// finish writes whatever is still queued and drops any later event. The
+// handler calls it before returning, as the response writer must not be
+// used afterwards.
+func (s *sseStream) finish() {
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+	flushing := s.flushing
+	s.mu.Unlock()
+
+	if flushing {
+		close(s.done)
+		<-s.flushed
+		s.flushPending()
+	}
+}
+
+// setupSSEStream establishes an SSE stream connection that carries
+// server-initiated notifications such as tools/list_changed
+func (t *StreamableHTTPTransport) setupSSEStream(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if _, ok := w.(http.Flusher); !ok {
 		http.Error(w, "Server does not support streaming", http.StatusInternalServerError)
 		return
 	}
 
-	// Send initial connection event
-	fmt.Fprintf(w, "id: %s\n", t.generateEventID())
-	fmt.Fprintf(w, "event: connection\n")
-	fmt.Fprintf(w, "data: {\"type\":\"connected\",\"session_id\":\"%s\"}\n\n", sessionID)
-	flusher.Flush()
+	// Subscribe before the connection event so that a client seeing it
+	// cannot miss a later notification
+	ctx := r.Context()
+	stream := t.newSSEStream(ctx, w, sessionID)
+	defer stream.finish()
+	unsubscribe := t.mcpServer.SubscribeSession(sessionID, stream.notify)
+	defer unsubscribe()
+
+	closing, unregister := t.streams.open(sessionID, stream.notify)
+	defer unregister()
+
+	stream.send("connection", map[string]string{"type": "connected", "session_id": sessionID})
 
 	// Keep connection alive with periodic heartbeats
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
-	ctx := r.Context()
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-stream.broken:
+			// The client fell behind; ending the stream lets it reconnect
+			return
+		case reason := <-closing:
+			// Tell the client why the stream ends, so that it can tell a
+			// clean close from a network failure
+			stream.send("close", closeEvent(reason))
+			return
 		case <-ticker.C:
-			fmt.Fprintf(w, "id: %s\n", t.generateEventID())
-			fmt.Fprintf(w, "event: heartbeat\n")
-			fmt.Fprintf(w, "data: {\"type\":\"ping\"}\n\n")
-			flusher.Flush()
+			if !t.chaos.dropEvent() {
+				stream.send("heartbeat", map[string]string{"type": "ping"})
+			}
 		}
 	}
 }
@@ -328,6 +1085,8 @@ func (t *StreamableHTTPTransport) setupSSEStream(w http.ResponseWriter, r *http.
 func mapErrorCodeToHTTPStatus(code int) int {
 	// Standard JSON-RPC 2.0 error codes
 	switch code {
+	case ErrorCodeParseError: // -32700
+		return http.StatusBadRequest
 	case ErrorCodeInvalidRequest: // -32600
 		return http.StatusBadRequest
 	case ErrorCodeMethodNotFound: // -32601
@@ -336,6 +1095,9 @@ func mapErrorCodeToHTTPStatus(code int) int {
 		return http.StatusBadRequest
 	case ErrorCodeInternalError: // -32603
 		return http.StatusInternalServerError
+	case ErrorCodeRequestCancelled: // -32800
+		// The client gave up on the request; the status is rarely read
+		return http.StatusBadRequest
 	}
 
 	// Application-specific error code ranges
@@ -397,17 +1159,7 @@ func (t *StreamableHTTPTransport) writeJSONResponse(w http.ResponseWriter, respo
 // writeErrorResponse writes a JSON-RPC error response
 // This helper function creates properly formatted MCP error responses
 func (t *StreamableHTTPTransport) writeErrorResponse(w http.ResponseWriter, id interface{}, code int, message, data string) {
-	// Create a proper JSON-RPC 2.0 error response
-	response := types.MCPResponse{
-		JSONRPC: "2.0", // Required JSON-RPC version
-		ID:      id,    // Request ID (may be nil for parse errors)
-		Error: &types.MCPError{
-			Code:    code,    // Standard JSON-RPC error codes
-			Message: message, // Human-readable error message
-			Data:    data,    // Additional error details
-		},
-	}
-	t.writeJSONResponse(w, response)
+	t.writeJSONResponse(w, errorResponse(id, code, message, data))
 }
 
 // ==========================================
@@ -419,37 +1171,157 @@ func (t *StreamableHTTPTransport) writeErrorResponse(w http.ResponseWriter, id i
 // createSession generates a new cryptographically secure session ID
 // Sessions are used to maintain state across multiple MCP requests
 // Per MCP specification, session IDs must be globally unique and secure
-func (t *StreamableHTTPTransport) createSession() string {
+func (t *StreamableHTTPTransport) createSession(tenantID string) string {
 	// Generate 16 random bytes for cryptographically secure session ID
 	bytes := make([]byte, 16)
 	rand.Read(bytes)
 	sessionID := hex.EncodeToString(bytes) // Convert to hex string (32 characters)
 
-	// Store session with thread-safe access
-	t.sessionsMux.Lock()
-	defer t.sessionsMux.Unlock()
-
 	// Create new session record
-	t.sessions[sessionID] = &types.Session{
+	session := &types.Session{
 		ID:        sessionID,
+		TenantID:  tenantID, // Sessions are only usable by the tenant that created them
 		CreatedAt: time.Now(),
 		LastSeen:  time.Now(), // Initialize activity timestamp
 		Active:    true,       // Mark session as active
+		Transport: "http",
 	}
+	// Store session with thread-safe access
+	t.sessionsMux.Lock()
+	t.sessions[sessionID] = session
+	snapshot := *session
+	t.sessionsMux.Unlock()
+	t.persistSession(snapshot)
 
 	return sessionID
 }
 
-// isValidSession checks if a session ID is valid and active
-// This validates both session existence and expiration status
-func (t *StreamableHTTPTransport) isValidSession(sessionID string) bool {
+// requireSession answers 400 Bad Request to a request without a session
+// when sessions are required, reporting whether the request may go on
+func (t *StreamableHTTPTransport) requireSession(w http.ResponseWriter) bool {
+	if !t.config.RequireSession {
+		return true
+	}
+	http.Error(w, "Mcp-Session-Id header required", http.StatusBadRequest)
+	return false
+}
+
+// dropSession removes a session along with its persisted copy and client state
+func (t *StreamableHTTPTransport) dropSession(sessionID string) {
+	t.sessionsMux.Lock()
+	delete(t.sessions, sessionID)
+	delete(t.sessionChecks, sessionID)
+	delete(t.unsaved, sessionID)
+	t.sessionsMux.Unlock()
+	t.streams.close(sessionID, CloseReasonSessionEnded)
+
+	if t.config.Store != nil {
+		if err := t.config.Store.Delete(sessionBucket, sessionID); err != nil {
+			log.Printf("Failed to delete session %s: %v", sessionID, err)
+		}
+	}
+	t.mcpServer.ForgetClient(sessionID)
+}
+
+// persistSession writes a snapshot of the session to the configured store,
+// if any. Callers must not hold sessionsMux, so store writes never block
+// other sessions.
+func (t *StreamableHTTPTransport) persistSession(session types.Session) {
+	if t.config.Store == nil {
+		return
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		log.Printf("Failed to encode session %s: %v", session.ID, err)
+		return
+	}
+	if err := t.config.Store.Put(sessionBucket, session.ID, data, t.config.SessionTimeout); err != nil {
+		log.Printf("Failed to persist session %s: %v", session.ID, err)
+	}
+}
+
+// restoreSession loads a session that is not in memory from the configured store
+// This lets clients keep their sessions across server restarts. A session the
+// store does not hold is reported as not found; failing to read the store is
+// an error, which says nothing about whether the session still exists.
+func (t *StreamableHTTPTransport) restoreSession(sessionID string) (*types.Session, bool, error) {
+	if t.config.Store == nil {
+		return nil, false, nil
+	}
+
+	data, found, err := t.config.Store.Get(sessionBucket, sessionID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	var session types.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		log.Printf("Failed to decode persisted session %s: %v", sessionID, err)
+		return nil, false, nil
+	}
+
+	// The server forgot the client's capabilities when it restarted
+	if session.Capabilities != nil {
+		t.mcpServer.RestoreClient(sessionID, ClientState{
+			ProtocolVersion: session.ProtocolVersion,
+			Capabilities:    *session.Capabilities,
+			ClientInfo:      session.ClientInfo,
+			Initialized:     session.Initialized,
+		})
+	}
+
+	t.sessionsMux.Lock()
+	defer t.sessionsMux.Unlock()
+	t.sessionChecks[sessionID] = time.Now()
+	if current, exists := t.sessions[sessionID]; exists {
+		// Keep this replica's statistics, which may not be written yet, but
+		// take in activity other replicas have seen
+		if session.LastSeen.After(current.LastSeen) {
+			current.LastSeen = session.LastSeen
+		}
+		return current, true, nil
+	}
+	t.sessions[sessionID] = &session
+	return &session, true, nil
+}
+
+// isValidSession checks if a session ID is valid and active for the tenant
+// This validates session existence, ownership and expiration status
+func (t *StreamableHTTPTransport) isValidSession(sessionID, tenantID string) bool {
+	// Fall back to the persistent store for sessions created before a restart.
+	// A shared store is authoritative, as other replicas update and end
+	// sessions there, but a session read within sharedSessionTTL is trusted
+	// to spare the store a read per request. When the store cannot be read
+	// the session in memory, if any, is kept.
+	t.sessionsMux.RLock()
+	_, inMemory := t.sessions[sessionID]
+	checked, wasChecked := t.sessionChecks[sessionID]
+	t.sessionsMux.RUnlock()
+	if t.config.SharedSessions && t.config.Store != nil {
+		if !inMemory || !wasChecked || time.Since(checked) > sharedSessionTTL {
+			if _, found, err := t.restoreSession(sessionID); err != nil {
+				log.Printf("Failed to read session %s from the store: %v", sessionID, err)
+			} else if !found && inMemory {
+				t.dropSession(sessionID)
+			}
+		}
+	} else if !inMemory {
+		if _, _, err := t.restoreSession(sessionID); err != nil {
+			log.Printf("Failed to read session %s from the store: %v", sessionID, err)
+		}
+	}
+
 	// Use read lock for thread-safe session access
 	t.sessionsMux.RLock()
 	defer t.sessionsMux.RUnlock()
 
 	// Check if session exists and is marked as active
 	session, exists := t.sessions[sessionID]
-	if !exists || !session.Active {
+	if !exists || !session.Active || session.TenantID != tenantID {
 		return false
 	}
 
@@ -468,6 +1340,42 @@ func (t *StreamableHTTPTransport) updateSessionActivity(sessionID string) {
 
 	if session, exists := t.sessions[sessionID]; exists {
 		session.LastSeen = time.Now()
+		t.unsaved[sessionID] = struct{}{}
+	}
+}
+
+// flushSessionsPeriodically writes changed sessions to the store until the
+// transport stops, keeping them from expiring there while in use
+func (t *StreamableHTTPTransport) flushSessionsPeriodically() {
+	interval := sessionFlushInterval
+	if t.config.SessionTimeout > 0 {
+		interval = min(interval, t.config.SessionTimeout/2)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if t.stopping.Load() {
+			return
+		}
+		t.flushSessions()
+	}
+}
+
+// flushSessions writes the sessions changed since they were last written
+func (t *StreamableHTTPTransport) flushSessions() {
+	t.sessionsMux.Lock()
+	snapshots := make([]types.Session, 0, len(t.unsaved))
+	for id := range t.unsaved {
+		if session, exists := t.sessions[id]; exists {
+			snapshots = append(snapshots, *session)
+		}
+	}
+	clear(t.unsaved)
+	t.sessionsMux.Unlock()
+
+	for _, session := range snapshots {
+		t.persistSession(session)
 	}
 }
 
@@ -486,14 +1394,31 @@ func (t *StreamableHTTPTransport) cleanupExpiredSessions() {
 		now := time.Now()
 
 		// Check each session for expiration
+		var expired []string
 		for id, session := range t.sessions {
 			// If session hasn't been active within timeout period, remove it
 			if now.Sub(session.LastSeen) > t.config.SessionTimeout {
 				delete(t.sessions, id)
-				log.Printf("Cleaned up expired session: %s", id)
+				delete(t.sessionChecks, id)
+				delete(t.unsaved, id)
+				expired = append(expired, id)
 			}
 		}
 		t.sessionsMux.Unlock()
+
+		// Forgetting a session deletes its formulas from the store, so it
+		// happens outside the lock. A shared store is left alone: the
+		// session is only idle here and may still be served by another
+		// replica, and its formulas expire with the store's TTL.
+		for _, id := range expired {
+			if t.config.SharedSessions && t.config.Store != nil {
+				t.mcpServer.forgetClientState(id)
+			} else {
+				t.mcpServer.ForgetClient(id)
+			}
+			t.streams.close(id, CloseReasonSessionExpired)
+			log.Printf("Cleaned up expired session: %s", id)
+		}
 	}
 }
 
@@ -515,21 +1440,92 @@ func (t *StreamableHTTPTransport) generateEventID() string {
 // Start starts the HTTP server
 // This method blocks until the server shuts down or encounters an error
 func (t *StreamableHTTPTransport) Start() error {
+	if t.config.TLS != nil {
+		tlsConfig, err := t.config.TLS.serverConfig()
+		if err != nil {
+			return err
+		}
+		t.server.TLSConfig = tlsConfig
+		// /metrics must not be readable in the clear when the MCP endpoint
+		// is not, so the ops listener shares the TLS settings
+		if t.opsServer != nil {
+			t.opsServer.TLSConfig = tlsConfig.Clone()
+		}
+	}
+	if t.opsServer != nil {
+		log.Printf("Serving operational endpoints on %s", t.opsServer.Addr)
+		go func() {
+			var err error
+			if t.opsServer.TLSConfig != nil {
+				err = t.opsServer.ListenAndServeTLS("", "")
+			} else {
+				err = t.opsServer.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				log.Printf("Operational endpoint server error: %v", err)
+			}
+		}()
+	}
+	if t.server.TLSConfig != nil {
+		log.Printf("Starting MCP streamable HTTPS server on %s", t.server.Addr)
+		// The certificates are already loaded into TLSConfig
+		return t.server.ListenAndServeTLS("", "")
+	}
 	log.Printf("Starting MCP streamable HTTP server on %s", t.server.Addr)
 	// ListenAndServe blocks until server shutdown
 	return t.server.ListenAndServe()
 }
 
-// Stop gracefully shuts down the HTTP server
-// Uses context for timeout control and ensures clean shutdown of all connections
+// Stop drains the HTTP server: it stops accepting connections, sends open
+// SSE streams their close event and waits for the requests being served,
+// such as running tool calls, and for running jobs. When ctx is done first,
+// the remaining connections are closed, cancelling their tool calls, and
+// ctx's error is returned.
 func (t *StreamableHTTPTransport) Stop(ctx context.Context) error {
 	log.Println("Shutting down MCP streamable HTTP server...")
-	// Graceful shutdown with context timeout
-	return t.server.Shutdown(ctx)
+	t.stopping.Store(true)
+	// End open SSE streams first, as Shutdown waits for their handlers
+	t.streams.closeAll()
+	err := t.server.Shutdown(ctx)
+	if err != nil {
+		log.Printf("Drain deadline passed with %d requests still being served; closing their connections", t.connections.active.Load())
+		t.server.Close()
+	}
+	if t.config.Store != nil {
+		t.flushSessions()
+	}
+	if t.config.Jobs != nil {
+		if jobsErr := t.config.Jobs.Shutdown(ctx); jobsErr != nil {
+			log.Printf("Drain deadline passed with jobs still running; cancelling them")
+			if err == nil {
+				err = jobsErr
+			}
+		}
+	}
+	if t.opsServer != nil {
+		if opsErr := t.opsServer.Shutdown(ctx); err == nil {
+			err = opsErr
+		}
+	}
+	return err
+}
+
+// Send implements Transport by delivering the notification to every open
+// GET /mcp stream of this transport
+func (t *StreamableHTTPTransport) Send(notification types.MCPNotification) error {
+	t.streams.broadcast(notification)
+	return nil
+}
+
+// Addr implements Transport, returning the host:port the server listens on
+func (t *StreamableHTTPTransport) Addr() string {
+	return t.server.Addr
 }
 
 // GetAddr returns the server address
 // Useful for testing and configuration verification
+//
+// Deprecated: use Addr
 func (t *StreamableHTTPTransport) GetAddr() string {
-	return t.server.Addr
+	return t.Addr()
 }
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"calculator-server/internal/types"
+)
+
+// ToolCatalogURI is the resource listing the tools available to the reader.
+// Subscribers are told when tools are registered or groups toggled.
+const ToolCatalogURI = "calculator://tools"
+
+// maxSessionSubscriptions is how many resources one session may subscribe
+// to. Any formula name can be subscribed to before it is saved, so without
+// a limit a session could grow the subscription table without end.
+const maxSessionSubscriptions = 100
+
+// resourceSubscriptions records the resources each session subscribed to
+type resourceSubscriptions struct {
+	mu       sync.Mutex
+	sessions map[string]*sessionSubscriptions // Session ID → its subscriptions
+}
+
+// sessionSubscriptions are the subscriptions of one session
+type sessionSubscriptions struct {
+	tenantID string          // Tenant of the session, which may read the tenant's formulas
+	uris     map[string]bool // Subscribed URIs
+}
+
+// subscribeResource handles resources/subscribe for the session of ctx
+func (s *Server) subscribeResource(ctx context.Context, uri string) *types.MCPError {
+	sessionID := SessionIDFromContext(ctx)
+	if sessionID == "" {
+		// Without a session there is nowhere to send updates
+		return &types.MCPError{Code: ErrorCodeInvalidRequest, Message: "Subscriptions require a session", Data: uri}
+	}
+	if !s.resourceExists(uri) {
+		return &types.MCPError{Code: ErrorCodeResourceNotFound, Message: "Resource not found", Data: uri}
+	}
+
+	s.resourceSubs.mu.Lock()
+	defer s.resourceSubs.mu.Unlock()
+	if s.resourceSubs.sessions == nil {
+		s.resourceSubs.sessions = make(map[string]*sessionSubscriptions)
+	}
+	subs := s.resourceSubs.sessions[sessionID]
+	if subs == nil {
+		subs = &sessionSubscriptions{tenantID: tenantID(ctx), uris: make(map[string]bool)}
+		s.resourceSubs.sessions[sessionID] = subs
+	}
+	if !subs.uris[uri] && len(subs.uris) >= maxSessionSubscriptions {
+		return &types.MCPError{
+			Code:    ErrorCodeTooManyRequests,
+			Message: fmt.Sprintf("Too many resource subscriptions in this session (limit %d)", maxSessionSubscriptions),
+			Data:    map[string]interface{}{"uri": uri, "limit": maxSessionSubscriptions},
+		}
+	}
+	subs.uris[uri] = true
+	return nil
+}
+
+// unsubscribeResource handles resources/unsubscribe. Unsubscribing from a
+// resource the session did not subscribe to is not an error.
+func (s *Server) unsubscribeResource(ctx context.Context, uri string) {
+	s.resourceSubs.mu.Lock()
+	defer s.resourceSubs.mu.Unlock()
+	sessionID := SessionIDFromContext(ctx)
+	subs := s.resourceSubs.sessions[sessionID]
+	if subs == nil {
+		return
+	}
+	delete(subs.uris, uri)
+	if len(subs.uris) == 0 {
+		delete(s.resourceSubs.sessions, sessionID)
+	}
+}
+
+// forgetSubscriptions drops the subscriptions of a session that has ended
+func (s *Server) forgetSubscriptions(sessionID string) {
+	s.resourceSubs.mu.Lock()
+	defer s.resourceSubs.mu.Unlock()
+	delete(s.resourceSubs.sessions, sessionID)
+}
+
+// resourceExists reports whether uri names a registered resource or has the
+// form of a saved formula, which may be saved after subscribing
+func (s *Server) resourceExists(uri string) bool {
+	if _, ok := s.resources[uri]; ok {
+		return true
+	}
+	name, found := strings.CutPrefix(uri, FormulaURIPrefix)
+	return found && name != ""
+}
+
+// NotifyResourceUpdated sends notifications/resources/updated to the
+// sessions subscribed to uri, which then read it again
+func (s *Server) NotifyResourceUpdated(uri string) {
+	s.notifySubscribers(uri, func(string, *sessionSubscriptions) bool { return true })
+}
+
+// notifyFormulaUpdated tells the subscribers that can read a saved formula
+// about it: only the saving session for a session formula, and sessions of
+// the saving tenant for a persistent one
+func (s *Server) notifyFormulaUpdated(ctx context.Context, formula types.Formula) {
+	sessionID, tenant := SessionIDFromContext(ctx), tenantID(ctx)
+	s.notifySubscribers(FormulaURIPrefix+formula.Name, func(id string, subs *sessionSubscriptions) bool {
+		if formula.Scope == types.FormulaScopeSession {
+			return id == sessionID
+		}
+		return subs.tenantID == tenant
+	})
+}
+
+// notifySubscribers sends notifications/resources/updated to the sessions
+// subscribed to uri that are selected
+func (s *Server) notifySubscribers(uri string, selected func(sessionID string, subs *sessionSubscriptions) bool) {
+	s.resourceSubs.mu.Lock()
+	sessions := make(map[string]bool)
+	for sessionID, subs := range s.resourceSubs.sessions {
+		if subs.uris[uri] && selected(sessionID, subs) {
+			sessions[sessionID] = true
+		}
+	}
+	s.resourceSubs.mu.Unlock()
+	if len(sessions) == 0 {
+		return
+	}
+
+	s.notifySessions(NotificationResourceUpdated, map[string]interface{}{"uri": uri}, func(sessionID string) bool {
+		return sessions[sessionID]
+	})
+}
+
+// ReadToolCatalog returns the tools available to the session in ctx, as
+// tools/list would list them
+func (s *Server) ReadToolCatalog(ctx context.Context) (string, error) {
+	data, err := json.MarshalIndent(s.listTools(ctx).Tools, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+)
+
+// TenantAPIKeyHeader carries the API key that identifies the calling tenant
+const TenantAPIKeyHeader = "X-API-Key"
+
+// Tenant is an isolated namespace of clients sharing one server. Each tenant
+// has its own sessions, request rate limit and set of callable tools.
+type Tenant struct {
+	ID                string
+	APIKeys           []string
+	RequestsPerMinute int      // Zero disables rate limiting for the tenant
+	AllowedTools      []string // Empty allows every registered tool
+	ToolGroups        []string // Empty allows every enabled tool group
+	// Context, when set, adds the tenant's own settings, such as its unit
+	// tables or exchange rates, to the context of each of its requests
+	Context func(ctx context.Context) context.Context
+}
+
+// allowsTool reports whether the tenant may list and call the named tool
+func (t *Tenant) allowsTool(name string) bool {
+	if len(t.AllowedTools) == 0 {
+		return true
+	}
+	for _, allowed := range t.AllowedTools {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsGroup reports whether the tenant may use tools of the group.
+// Ungrouped tools are available to every tenant.
+func (t *Tenant) allowsGroup(group string) bool {
+	if group == "" || len(t.ToolGroups) == 0 {
+		return true
+	}
+	for _, allowed := range t.ToolGroups {
+		if allowed == group {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantRegistry maps API keys to tenants
+type TenantRegistry struct {
+	tenants []*Tenant
+	limiter *rateLimiter
+}
+
+// NewTenantRegistry creates a registry of the given tenants
+func NewTenantRegistry(tenants []Tenant) *TenantRegistry {
+	registry := &TenantRegistry{limiter: newRateLimiter()}
+	for i := range tenants {
+		tenant := tenants[i]
+		registry.tenants = append(registry.tenants, &tenant)
+	}
+	return registry
+}
+
+// Resolve returns the tenant owning apiKey
+func (r *TenantRegistry) Resolve(apiKey string) (*Tenant, bool) {
+	if apiKey == "" {
+		return nil, false
+	}
+	for _, tenant := range r.tenants {
+		for _, key := range tenant.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+				return tenant, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// WithTenant returns a context carrying the tenant the request belongs to,
+// and the tenant's own settings
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	ctx = context.WithValue(ctx, tenantKey, tenant)
+	if tenant != nil && tenant.Context != nil {
+		ctx = tenant.Context(ctx)
+	}
+	return ctx
+}
+
+// TenantFromContext returns the tenant of the request, or nil when the
+// server is not multi-tenant
+func TenantFromContext(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantKey).(*Tenant)
+	return tenant
+}
+
+// tenantID returns the ID of the request's tenant, or "" when there is none
+func tenantID(ctx context.Context) string {
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		return tenant.ID
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig serves the streamable HTTP transport over HTTPS
+type TLSConfig struct {
+	CertFile string // PEM certificate chain of the server
+	KeyFile  string // PEM private key of the certificate
+	// ClientCAFile is a PEM bundle of CAs. When set, clients must present
+	// a certificate signed by one of them (mutual TLS).
+	ClientCAFile string
+	MinVersion   string   // "1.2" or "1.3"; empty is TLS 1.2
+	CipherSuites []string // Names as listed by crypto/tls, for TLS 1.2; empty keeps Go's defaults
+}
+
+// tlsVersions maps the accepted minimum versions to their crypto/tls values
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion returns the crypto/tls value of a minimum TLS version
+func parseTLSVersion(version string) (uint16, error) {
+	value, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q: use 1.2 or 1.3", version)
+	}
+	return value, nil
+}
+
+// parseCipherSuites returns the IDs of the named cipher suites. Suites Go
+// considers insecure are refused.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// serverConfig loads the certificates and builds the crypto/tls settings
+func (c *TLSConfig) serverConfig() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	minVersion, err := parseTLSVersion(c.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := parseCipherSuites(c.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+	}
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
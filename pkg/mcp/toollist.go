@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"calculator-server/internal/types"
+)
+
+// ToolOrdering is the order in which tools/list returns the tools
+type ToolOrdering string
+
+const (
+	// ToolOrderRegistration lists tools in the order they were registered
+	ToolOrderRegistration ToolOrdering = "registration"
+	// ToolOrderName lists tools sorted by name
+	ToolOrderName ToolOrdering = "name"
+)
+
+// SetToolOrdering sets the order of tools/list. Either way the order is
+// stable, so clients can diff the catalog between calls.
+func (s *Server) SetToolOrdering(ordering ToolOrdering) {
+	s.toolsMu.Lock()
+	s.toolOrdering = ordering
+	s.toolsMu.Unlock()
+
+	s.toolList.invalidate()
+}
+
+// toolListKey identifies the callers sharing one tool list
+type toolListKey struct {
+	tenant     string      // "" without a tenant
+	credential *Credential // nil without authentication
+}
+
+// toolListCache keeps the tools/list result of each tenant and credential,
+// marshaled once.
+// Tool registrations and group changes bump the generation and drop every
+// entry, so a list is never served after the tools it describes changed.
+type toolListCache struct {
+	mu         sync.RWMutex
+	generation uint64
+	lists      map[toolListKey]types.ListToolsResult
+}
+
+// invalidate drops the cached lists
+func (c *toolListCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.lists = nil
+}
+
+// listTools returns the tools available in ctx, marshaled ahead of time
+func (s *Server) listTools(ctx context.Context) types.ListToolsResult {
+	key := toolListKey{tenant: tenantID(ctx), credential: CredentialFromContext(ctx)}
+
+	s.toolList.mu.RLock()
+	list, cached := s.toolList.lists[key]
+	generation := s.toolList.generation
+	s.toolList.mu.RUnlock()
+	if cached {
+		return list
+	}
+
+	list = s.buildToolList(ctx)
+
+	// A list built while the tools changed is served but not kept
+	s.toolList.mu.Lock()
+	defer s.toolList.mu.Unlock()
+	if s.toolList.generation == generation {
+		if s.toolList.lists == nil {
+			s.toolList.lists = make(map[toolListKey]types.ListToolsResult)
+		}
+		s.toolList.lists[key] = list
+	}
+	return list
+}
+
+// buildToolList collects and marshals the tools available in ctx
+func (s *Server) buildToolList(ctx context.Context) types.ListToolsResult {
+	tools := []types.Tool{}
+	for _, schema := range s.toolSchemas() {
+		if !s.toolAvailable(ctx, schema) {
+			continue
+		}
+		tool := types.Tool{
+			Name:         schema.Name,
+			Description:  schema.Description,
+			InputSchema:  schema.InputSchema,
+			OutputSchema: schema.OutputSchema,
+		}
+		if len(schema.Examples) > 0 {
+			tool.Meta = &types.ToolMeta{Examples: schema.Examples}
+		}
+		tools = append(tools, tool)
+	}
+
+	list := types.ListToolsResult{Tools: tools}
+	if marshaled, err := json.Marshal(list); err == nil {
+		list.Marshaled = marshaled
+	}
+	return list
+}
+
+// PrewarmToolList builds and marshals the tool list of clients without a
+// tenant, so the first tools/list after startup is served from the cache
+func (s *Server) PrewarmToolList() {
+	s.listTools(context.Background())
+}
+
+// toolsChanged invalidates the cached tool lists and tells clients to fetch
+// tools/list, or the tool catalog resource, again
+func (s *Server) toolsChanged() {
+	s.toolList.invalidate()
+	s.notifyToolListChanged()
+	s.NotifyResourceUpdated(ToolCatalogURI)
+}
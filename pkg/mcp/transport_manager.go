@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TransportManager runs several transports against one Server at once,
+// e.g. stdio for a local client alongside HTTP for dashboards, and shuts
+// them down together
+type TransportManager struct {
+	names      []string
+	transports []Transport
+}
+
+// NewTransportManager creates a manager without transports
+func NewTransportManager() *TransportManager {
+	return &TransportManager{}
+}
+
+// Add adds a transport under a name used in logs. Transports are started
+// in the order they were added.
+func (m *TransportManager) Add(name string, transport Transport) {
+	m.names = append(m.names, name)
+	m.transports = append(m.transports, transport)
+}
+
+// Run starts every transport and serves until ctx is done or any transport
+// stops, whether it failed or its input ended, as stdio does when the local
+// client goes away. Every transport is then stopped, each given up to
+// shutdownTimeout to finish the requests it is serving. Run returns the
+// error of the first transport that failed, if any.
+func (m *TransportManager) Run(ctx context.Context, shutdownTimeout time.Duration) error {
+	if len(m.transports) == 0 {
+		return fmt.Errorf("no transports to run")
+	}
+
+	type ended struct {
+		name string
+		err  error
+	}
+	done := make(chan ended, len(m.transports))
+	for i, transport := range m.transports {
+		name, transport := m.names[i], transport
+		log.Printf("Starting %s transport", name)
+		go func() {
+			err := transport.Start()
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+			done <- ended{name, err}
+		}()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Println("Stopping all transports...")
+	case first := <-done:
+		if first.err != nil {
+			err = fmt.Errorf("%s transport: %w", first.name, first.err)
+			log.Printf("The %s transport failed, stopping the others: %v", first.name, first.err)
+		} else {
+			log.Printf("The %s transport ended, stopping the others", first.name)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	var stopping sync.WaitGroup
+	for i, transport := range m.transports {
+		name, transport := m.names[i], transport
+		stopping.Add(1)
+		go func() {
+			defer stopping.Done()
+			if stopErr := transport.Stop(shutdownCtx); stopErr != nil {
+				log.Printf("Error stopping the %s transport: %v", name, stopErr)
+			}
+		}()
+	}
+	stopping.Wait()
+	return err
+}
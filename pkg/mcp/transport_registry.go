@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TransportFactory creates a transport serving the server
+type TransportFactory func(server *Server) (Transport, error)
+
+// transports maps transport names to their factories. The built-in
+// transports are registered with their default settings.
+var transports = struct {
+	mu        sync.RWMutex
+	factories map[string]TransportFactory
+}{
+	factories: map[string]TransportFactory{
+		"stdio": func(server *Server) (Transport, error) {
+			return NewStdioTransport(server), nil
+		},
+		"http": func(server *Server) (Transport, error) {
+			return NewStreamableHTTPTransport(server, nil), nil
+		},
+	},
+}
+
+// RegisterTransport makes a transport available under a name, so that
+// code embedding the server can plug in transports of its own, such as a
+// WebSocket or a message queue, and create them with NewTransport.
+// Registering a name again replaces its factory, e.g. to configure a
+// built-in transport.
+func RegisterTransport(name string, factory TransportFactory) {
+	if name == "" || factory == nil {
+		panic("mcp: RegisterTransport needs a name and a factory")
+	}
+	transports.mu.Lock()
+	defer transports.mu.Unlock()
+	transports.factories[name] = factory
+}
+
+// NewTransport creates the transport registered under name
+func NewTransport(name string, server *Server) (Transport, error) {
+	transports.mu.RLock()
+	factory, ok := transports.factories[name]
+	transports.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown transport %q", name)
+	}
+	return factory(server)
+}
+
+// RegisteredTransports returns the names of the registered transports,
+// sorted
+func RegisteredTransports() []string {
+	transports.mu.RLock()
+	defer transports.mu.RUnlock()
+	names := make([]string, 0, len(transports.factories))
+	for name := range transports.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
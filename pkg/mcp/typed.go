@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"calculator-server/internal/types"
+)
+
+// RawToolHandler receives the arguments of a call as they arrived on the
+// wire, without the generic map decoding that ContextToolHandler needs
+type RawToolHandler func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+
+// errArgumentsNotDecoded is returned by a raw handler whose arguments do not
+// decode straight into its request type. The call is then retried through
+// the generic path, which reports out-of-range numbers by argument and
+// accepts integers written as 2.0.
+var errArgumentsNotDecoded = errors.New("arguments not decoded")
+
+// FastDecoder is implemented by request types that can decode the common
+// shapes of their arguments without reflection. DecodeFast reports false
+// when the arguments need the generic decoder, which then decodes them
+// into a fresh request.
+type FastDecoder interface {
+	DecodeFast(data []byte) bool
+}
+
+// emptyArguments stands for a call that sent no arguments
+var emptyArguments = json.RawMessage("{}")
+
+// RegisterTypedTool registers a tool whose handler takes its decoded request
+// type. Calls over the protocol decode their arguments once, straight into
+// Req, instead of through a map that the handler would marshal and decode
+// again. Jobs and self-checks, which hold their arguments as a map, still
+// reach the handler through one marshal and decode.
+func RegisterTypedTool[Req any](s *Server, name string, description string, inputSchema map[string]interface{}, handler func(req Req) (interface{}, error), opts ...ToolOption) {
+	RegisterTypedContextTool(s, name, description, inputSchema, func(_ context.Context, req Req) (interface{}, error) {
+		return handler(req)
+	}, opts...)
+}
+
+// RegisterTypedContextTool is RegisterTypedTool for handlers that also need
+// the context of the call
+func RegisterTypedContextTool[Req any](s *Server, name string, description string, inputSchema map[string]interface{}, handler func(ctx context.Context, req Req) (interface{}, error), opts ...ToolOption) {
+	raw := func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+		var req Req
+		if fast, ok := any(&req).(FastDecoder); ok && fast.DecodeFast(arguments) {
+			return handler(ctx, req)
+		}
+		req = *new(Req)
+		if err := json.Unmarshal(arguments, &req); err != nil {
+			return nil, errArgumentsNotDecoded
+		}
+		return handler(ctx, req)
+	}
+
+	decoded := func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal parameters: %v", err)
+		}
+		var req Req
+		if err := json.Unmarshal(paramsJSON, &req); err != nil {
+			return nil, fmt.Errorf("invalid parameters for %s: %v", name, err)
+		}
+		return handler(ctx, req)
+	}
+
+	schema := ToolSchema{
+		Name:        name,
+		Description: description,
+		InputSchema: inputSchema,
+	}
+	for _, opt := range opts {
+		opt(&schema)
+	}
+	s.registerTool(schema, decoded, raw)
+}
+
+// lookupRawTool returns the raw handler of a tool registered with
+// RegisterTypedTool, or nil
+func (s *Server) lookupRawTool(name string) RawToolHandler {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	return s.rawTools[name]
+}
+
+// decodeCallToolParams decodes tools/call parameters, keeping the arguments
+// as sent so that typed tools can decode them once into their own request
+func decodeCallToolParams(data json.RawMessage) (types.CallToolParams, error) {
+	var envelope struct {
+		Name      string             `json:"name"`
+		Arguments json.RawMessage    `json:"arguments,omitempty"`
+		Meta      *types.RequestMeta `json:"_meta,omitempty"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return types.CallToolParams{}, err
+	}
+	return types.CallToolParams{Name: envelope.Name, RawArguments: envelope.Arguments, Meta: envelope.Meta}, nil
+}
+
+// decodeArguments decodes raw arguments into the map handed to
+// ContextToolHandler, with numbers normalized as handlers expect
+func decodeArguments(raw json.RawMessage) (map[string]interface{}, *types.MCPError) {
+	var arguments map[string]interface{}
+	if len(raw) > 0 {
+		if err := decodeJSON(raw, &arguments); err != nil {
+			return nil, &types.MCPError{Code: ErrorCodeInvalidParams, Message: "Invalid parameters", Data: err.Error()}
+		}
+	}
+	return normalizeArguments(arguments)
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"calculator-server/internal/types"
+)
+
+const (
+	// WebhookSignatureHeader carries the HMAC-SHA256 signature of the
+	// timestamp and request body
+	WebhookSignatureHeader = "X-Calculator-Signature"
+	// WebhookTimestampHeader carries the Unix time at which a delivery was
+	// signed, so receivers can reject replayed deliveries
+	WebhookTimestampHeader = "X-Calculator-Timestamp"
+	// WebhookEventHeader names the event that triggered the webhook
+	WebhookEventHeader = "X-Calculator-Event"
+)
+
+// WebhookNotifier POSTs finished jobs as JSON to a configured URL
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier for the given URL. When secret is
+// non-empty every delivery is signed with it.
+func NewWebhookNotifier(url, secret string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify implements JobNotifier
+func (n *WebhookNotifier) Notify(job types.Job) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, "job."+job.Status)
+	if n.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(n.secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the signature header value for a delivery of
+// body signed at timestamp, the HMAC of "<timestamp>.<body>" formatted as
+// "sha256=<hex digest>"
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"errors"
 	"math"
 	"testing"
 
@@ -625,3 +626,71 @@ func TestAdvancedCalculator_Validation(t *testing.T) {
 		}
 	})
 }
+
+func TestAdvancedCalculator_DomainErrorCodes(t *testing.T) {
+	calc := calculator.NewAdvancedCalculator()
+
+	testCases := []struct {
+		name         string
+		request      types.AdvancedMathRequest
+		expectedCode string
+	}{
+		{
+			name:         "Log of zero",
+			request:      types.AdvancedMathRequest{Function: "log", Value: 0},
+			expectedCode: types.ErrCodeLogDomain,
+		},
+		{
+			name:         "Natural log of negative",
+			request:      types.AdvancedMathRequest{Function: "ln", Value: -2},
+			expectedCode: types.ErrCodeLogDomain,
+		},
+		{
+			name:         "Square root of negative",
+			request:      types.AdvancedMathRequest{Function: "sqrt", Value: -9},
+			expectedCode: types.ErrCodeSqrtDomain,
+		},
+		{
+			name:         "Asin out of range",
+			request:      types.AdvancedMathRequest{Function: "asin", Value: 1.5},
+			expectedCode: types.ErrCodeInverseTrigDomain,
+		},
+		{
+			name:         "Acos out of range",
+			request:      types.AdvancedMathRequest{Function: "acos", Value: -3},
+			expectedCode: types.ErrCodeInverseTrigDomain,
+		},
+		{
+			name:         "Zero to negative power",
+			request:      types.AdvancedMathRequest{Function: "pow", Value: 0, Exponent: -1},
+			expectedCode: types.ErrCodeDivisionByZero,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := calc.Calculate(tc.request)
+			if err == nil {
+				t.Fatalf("Expected error, but got none")
+			}
+
+			var calcErr *types.CalculationError
+			if !errors.As(err, &calcErr) {
+				t.Fatalf("Expected CalculationError, got %T: %v", err, err)
+			}
+			if calcErr.Code != tc.expectedCode {
+				t.Errorf("Expected code %s, got %s", tc.expectedCode, calcErr.Code)
+			}
+			expectedValue := tc.request.Value
+			if tc.request.Function == "pow" {
+				expectedValue = tc.request.Exponent
+				if calcErr.Field != "exponent" {
+					t.Errorf("Expected the exponent to be named, got %q", calcErr.Field)
+				}
+			}
+			if calcErr.Value != expectedValue {
+				t.Errorf("Expected offending value %f, got %f", expectedValue, calcErr.Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func callBasicMath(t *testing.T, schema map[string]interface{}, arguments string) types.MCPResponse {
+	t.Helper()
+
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", schema, handlers.NewMathHandler().HandleBasicMath)
+
+	return server.HandleRequestContext(context.Background(), types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"basic_math","arguments":` + arguments + `}`),
+	})
+}
+
+func TestUnknownArguments_RejectedWhenSchemaForbidsThem(t *testing.T) {
+	schema := getBasicMathSchema()
+	schema["additionalProperties"] = false
+
+	response := callBasicMath(t, schema, `{"operation":"add","operands":[1,2],"precison":3}`)
+	if response.Error == nil {
+		t.Fatal("Expected an error for the misspelled argument")
+	}
+	if response.Error.Code != mcp.ErrorCodeInvalidParams {
+		t.Errorf("Expected invalid params code, got %d", response.Error.Code)
+	}
+
+	unknown, ok := response.Error.Data.([]mcp.UnknownArgument)
+	if !ok || len(unknown) != 1 {
+		t.Fatalf("Expected one unknown argument, got %#v", response.Error.Data)
+	}
+	if unknown[0].Argument != "precison" || unknown[0].Suggestion != "precision" {
+		t.Errorf("Unexpected unknown argument: %+v", unknown[0])
+	}
+	if !strings.Contains(response.Error.Message, `did you mean "precision"?`) {
+		t.Errorf("Expected a suggestion in the message, got %q", response.Error.Message)
+	}
+}
+
+func TestUnknownArguments_WarnedWhenSchemaAllowsThem(t *testing.T) {
+	response := callBasicMath(t, getBasicMathSchema(), `{"operation":"add","operands":[1,2],"zzz":true}`)
+	if response.Error != nil {
+		t.Fatalf("Expected the call to succeed, got %v", response.Error)
+	}
+
+	result := response.Result.(types.CallToolResult)
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected the result and a warning, got %+v", result.Content)
+	}
+	if warning := result.Content[1].Text; !strings.Contains(warning, `"zzz"`) || strings.Contains(warning, "did you mean") {
+		t.Errorf("Expected a warning without a suggestion, got %q", warning)
+	}
+}
+
+func TestOutOfRangeLiterals_NameTheArgument(t *testing.T) {
+	response := callBasicMath(t, getBasicMathSchema(), `{"operation":"add","operands":[1,1e400]}`)
+	if response.Error == nil {
+		t.Fatal("Expected an error for a literal beyond the float64 range")
+	}
+	if response.Error.Code != mcp.ErrorCodeValueOutOfRange {
+		t.Errorf("Expected value out of range code, got %d", response.Error.Code)
+	}
+
+	data, ok := response.Error.Data.(map[string]interface{})
+	if !ok || data["argument"] != "operands[1]" || data["value"] != "1e400" {
+		t.Errorf("Expected the offending argument and literal, got %#v", response.Error.Data)
+	}
+
+	// Literals within range still work, however they are written
+	response = callBasicMath(t, getBasicMathSchema(), `{"operation":"add","operands":[1e300,2.5E-3]}`)
+	if response.Error != nil {
+		t.Errorf("Expected in-range literals to be accepted, got %v", response.Error)
+	}
+}
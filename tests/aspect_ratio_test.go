@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+)
+
+func TestAspectRatioCalculator_Reduce(t *testing.T) {
+	calc := calculator.NewAspectRatioCalculator()
+
+	testCases := []struct {
+		width, height int64
+		ratio         string
+		nearest       string
+		orientation   string
+	}{
+		{1920, 1080, "16:9", "16:9", "landscape"},
+		{1366, 768, "683:384", "16:9", "landscape"},
+		{1440, 900, "8:5", "16:10", "landscape"},
+		{2560, 1080, "64:27", "21:9", "landscape"},
+		{3440, 1440, "43:18", "21:9", "landscape"},
+		{1024, 768, "4:3", "4:3", "landscape"},
+		{1080, 1920, "9:16", "9:16", "portrait"},
+		{1080, 2560, "27:64", "9:21", "portrait"},
+		{800, 800, "1:1", "1:1", "square"},
+	}
+
+	for _, tc := range testCases {
+		result, err := calc.Calculate(types.AspectRatioRequest{Operation: "reduce", Width: tc.width, Height: tc.height})
+		if err != nil {
+			t.Fatalf("Unexpected error for %dx%d: %v", tc.width, tc.height, err)
+		}
+		if result.Ratio != tc.ratio || result.NearestStandard != tc.nearest || result.Orientation != tc.orientation {
+			t.Errorf("Expected %dx%d to be %s near %s (%s), got %+v", tc.width, tc.height, tc.ratio, tc.nearest, tc.orientation, result)
+		}
+	}
+
+	result, _ := calc.Calculate(types.AspectRatioRequest{Operation: "reduce", Width: 1920, Height: 1080})
+	if result.GCD != 120 || result.Pixels != 2073600 || result.DifferencePercent != 0 {
+		t.Errorf("Unexpected 1920x1080 details: %+v", result)
+	}
+}
+
+func TestAspectRatioCalculator_PPI(t *testing.T) {
+	calc := calculator.NewAspectRatioCalculator()
+
+	diagonal := 27.0
+	result, err := calc.Calculate(types.AspectRatioRequest{Operation: "ppi", Width: 2560, Height: 1440, Diagonal: &diagonal})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(*result.PPI-108.7855) > 1e-3 || math.Abs(*result.DotPitchMm-0.2335) > 1e-3 {
+		t.Errorf("Expected about 108.79 ppi with a 0.233 mm pitch, got %v and %v", *result.PPI, *result.DotPitchMm)
+	}
+	if math.Abs(math.Hypot(*result.WidthIn, *result.HeightIn)-diagonal) > 1e-9 {
+		t.Errorf("Expected the physical size to match the diagonal, got %v x %v", *result.WidthIn, *result.HeightIn)
+	}
+
+	centimetres := 27 * 2.54
+	metric, err := calc.Calculate(types.AspectRatioRequest{Operation: "ppi", Width: 2560, Height: 1440, Diagonal: &centimetres, DiagonalUnit: "cm"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(*metric.PPI-*result.PPI) > 1e-9 || math.Abs(*metric.WidthCm-*result.WidthIn*2.54) > 1e-9 {
+		t.Errorf("Expected a diagonal in cm to give the same density, got %v", *metric.PPI)
+	}
+}
+
+func TestAspectRatioCalculator_Scale(t *testing.T) {
+	calc := calculator.NewAspectRatioCalculator()
+
+	testCases := []struct {
+		name          string
+		request       types.AspectRatioRequest
+		width, height int64
+		exact         bool
+	}{
+		{"Target width", types.AspectRatioRequest{Operation: "scale", Width: 1920, Height: 1080, TargetWidth: 1280}, 1280, 720, true},
+		{"Target height", types.AspectRatioRequest{Operation: "scale", Width: 1920, Height: 1080, TargetHeight: 2160}, 3840, 2160, true},
+		{"Rounded", types.AspectRatioRequest{Operation: "scale", Width: 1920, Height: 1080, TargetWidth: 1000}, 1000, 563, false},
+		{"Even sides", types.AspectRatioRequest{Operation: "scale", Width: 1920, Height: 1080, TargetWidth: 1000, MultipleOf: 2}, 1000, 562, false},
+		{"At least one multiple", types.AspectRatioRequest{Operation: "scale", Width: 1000, Height: 10, TargetWidth: 10, MultipleOf: 4}, 10, 4, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Calculate(tc.request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Width != tc.width || result.Height != tc.height || *result.Exact != tc.exact {
+				t.Errorf("Expected %dx%d (exact %v), got %dx%d (exact %v)", tc.width, tc.height, tc.exact, result.Width, result.Height, *result.Exact)
+			}
+		})
+	}
+}
+
+func TestAspectRatioCalculator_Errors(t *testing.T) {
+	calc := calculator.NewAspectRatioCalculator()
+	diagonal, negative := 24.0, -1.0
+
+	testCases := []struct {
+		name    string
+		request types.AspectRatioRequest
+		message string
+	}{
+		{"Unknown operation", types.AspectRatioRequest{Operation: "crop", Width: 10, Height: 10}, "unsupported aspect ratio operation"},
+		{"Zero width", types.AspectRatioRequest{Operation: "reduce", Height: 10}, "width must be between"},
+		{"Huge height", types.AspectRatioRequest{Operation: "reduce", Width: 10, Height: 1000001}, "height must be between"},
+		{"Missing diagonal", types.AspectRatioRequest{Operation: "ppi", Width: 10, Height: 10}, "requires a diagonal"},
+		{"Negative diagonal", types.AspectRatioRequest{Operation: "ppi", Width: 10, Height: 10, Diagonal: &negative}, "diagonal must be positive"},
+		{"Unknown diagonal unit", types.AspectRatioRequest{Operation: "ppi", Width: 10, Height: 10, Diagonal: &diagonal, DiagonalUnit: "mm"}, "unsupported diagonal_unit"},
+		{"No target", types.AspectRatioRequest{Operation: "scale", Width: 10, Height: 10}, "exactly one of"},
+		{"Both targets", types.AspectRatioRequest{Operation: "scale", Width: 10, Height: 10, TargetWidth: 5, TargetHeight: 5}, "exactly one of"},
+		{"Scaled too large", types.AspectRatioRequest{Operation: "scale", Width: 1, Height: 1000, TargetWidth: 2000}, "exceeds"},
+		{"Bad multiple", types.AspectRatioRequest{Operation: "scale", Width: 10, Height: 10, TargetWidth: 5, MultipleOf: -2}, "multiple_of must be between"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := calc.Calculate(tc.request)
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Expected an error containing %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestMathHandler_AspectRatio(t *testing.T) {
+	handler := handlers.NewMathHandler()
+
+	result, err := handler.HandleAspectRatio(map[string]interface{}{"operation": "reduce", "width": 1280.0, "height": 1024.0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ratio, ok := result.(types.AspectRatioResult); !ok || ratio.Ratio != "5:4" {
+		t.Errorf("Unexpected aspect ratio result: %+v", result)
+	}
+
+	if _, err := handler.HandleAspectRatio(map[string]interface{}{"operation": "reduce", "width": 1280.5, "height": 1024.0}); err == nil {
+		t.Error("Expected an error for a fractional width")
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_Authentication(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	statsHandler := handlers.NewStatsHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+	server.RegisterTool("statistics", "Statistical analysis", getStatisticsSchema(), statsHandler.HandleStatistics)
+
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8107,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		Stateless:      true,
+		Auth: mcp.NewAuthenticator(
+			[]mcp.Credential{{Name: "dashboard", Secret: "dashboard-key", AllowedTools: []string{"basic_math"}}},
+			[]mcp.Credential{{Name: "agents", Secret: "agent-token"}},
+		),
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	post := func(header, value, body string) (int, http.Header, types.MCPResponse) {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var response types.MCPResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, resp.Header, response
+	}
+	list := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+	callStatistics := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"statistics","arguments":{"data":[1,2],"operation":"mean"}}}`
+
+	// Missing and wrong credentials are told apart
+	status, header, response := post("", "", list)
+	if status != http.StatusUnauthorized || response.Error == nil || response.Error.Code != mcp.ErrorCodeAuthenticationRequired {
+		t.Errorf("Expected 401 authentication required, got %d %+v", status, response.Error)
+	}
+	if !strings.HasPrefix(header.Get("WWW-Authenticate"), "Bearer") {
+		t.Errorf("Expected a WWW-Authenticate challenge, got %q", header.Get("WWW-Authenticate"))
+	}
+	for _, credential := range [][2]string{{mcp.TenantAPIKeyHeader, "agent-token"}, {"Authorization", "Bearer dashboard-key"}} {
+		status, _, response = post(credential[0], credential[1], list)
+		if status != http.StatusUnauthorized || response.Error == nil || response.Error.Code != mcp.ErrorCodeInvalidCredentials {
+			t.Errorf("Expected 401 invalid credentials for %v, got %d %+v", credential, status, response.Error)
+		}
+	}
+
+	// The bearer token may use every tool
+	status, _, response = post("Authorization", "Bearer agent-token", callStatistics)
+	if status != http.StatusOK || response.Error != nil {
+		t.Errorf("Expected the bearer token to call statistics, got %d %+v", status, response.Error)
+	}
+
+	// The API key only sees and calls its allowed tools, even after the
+	// unrestricted list was cached
+	post("Authorization", "Bearer agent-token", list)
+	_, _, response = post(mcp.TenantAPIKeyHeader, "dashboard-key", list)
+	resultJSON, _ := json.Marshal(response.Result)
+	if !strings.Contains(string(resultJSON), "basic_math") || strings.Contains(string(resultJSON), "statistics") {
+		t.Errorf("Expected only basic_math to be listed for the API key, got %s", resultJSON)
+	}
+	status, _, response = post(mcp.TenantAPIKeyHeader, "dashboard-key", callStatistics)
+	if status != http.StatusForbidden || response.Error == nil || response.Error.Code != mcp.ErrorCodeAccessDenied {
+		t.Errorf("Expected 403 access denied for statistics, got %d %+v", status, response.Error)
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"errors"
 	"math"
 	"testing"
 
@@ -381,8 +382,18 @@ func TestBasicCalculator_ValidateOperation(t *testing.T) {
 			shouldErr: false,
 		},
 		{
-			name:      "Invalid operation",
+			name:      "Valid operation - power",
 			operation: "power",
+			shouldErr: false,
+		},
+		{
+			name:      "Valid operation - mod",
+			operation: "mod",
+			shouldErr: false,
+		},
+		{
+			name:      "Invalid operation",
+			operation: "sqrt",
 			shouldErr: true,
 		},
 		{
@@ -457,3 +468,153 @@ func TestBasicCalculator_Precision(t *testing.T) {
 		})
 	}
 }
+
+func TestBasicCalculator_DivisionByZeroError(t *testing.T) {
+	calc := calculator.NewBasicCalculator()
+
+	_, err := calc.Calculate(types.BasicMathRequest{
+		Operation: "divide",
+		Operands:  []float64{10, 2, 0},
+	})
+	if err == nil {
+		t.Fatal("Expected division by zero error, but got none")
+	}
+
+	var calcErr *types.CalculationError
+	if !errors.As(err, &calcErr) {
+		t.Fatalf("Expected CalculationError, got %T: %v", err, err)
+	}
+	if calcErr.Code != types.ErrCodeDivisionByZero {
+		t.Errorf("Expected code %s, got %s", types.ErrCodeDivisionByZero, calcErr.Code)
+	}
+	if calcErr.Field != "operands[2]" {
+		t.Errorf("Expected field operands[2], got %s", calcErr.Field)
+	}
+}
+
+func TestBasicCalculator_OperandCount(t *testing.T) {
+	calc := calculator.NewBasicCalculator()
+
+	t.Run("Too few operands reports constraint", func(t *testing.T) {
+		err := calc.ValidateOperandCount("subtract", []float64{5})
+		var calcErr *types.CalculationError
+		if !errors.As(err, &calcErr) {
+			t.Fatalf("Expected CalculationError, got %T: %v", err, err)
+		}
+		if calcErr.Code != types.ErrCodeOperandCount {
+			t.Errorf("Expected code %s, got %s", types.ErrCodeOperandCount, calcErr.Code)
+		}
+		if calcErr.Value != 1 {
+			t.Errorf("Expected operand count 1, got %f", calcErr.Value)
+		}
+	})
+
+	t.Run("Non-associative fold is documented", func(t *testing.T) {
+		result, err := calc.Calculate(types.BasicMathRequest{
+			Operation: "divide",
+			Operands:  []float64{100, 5, 2},
+			Precision: 2,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Result != 10 {
+			t.Errorf("Expected 10, got %f", result.Result)
+		}
+		if result.Semantics == "" {
+			t.Error("Expected semantics to be documented for 3-operand divide")
+		}
+	})
+
+	t.Run("Two operands need no semantics", func(t *testing.T) {
+		result, err := calc.Calculate(types.BasicMathRequest{
+			Operation: "subtract",
+			Operands:  []float64{10, 4},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Semantics != "" {
+			t.Errorf("Expected no semantics, got %s", result.Semantics)
+		}
+	})
+}
+
+func TestBasicCalculator_TwoOperandOperations(t *testing.T) {
+	calc := calculator.NewBasicCalculator()
+
+	testCases := []struct {
+		name      string
+		request   types.BasicMathRequest
+		expected  float64
+		shouldErr bool
+	}{
+		{
+			name:     "Modulo",
+			request:  types.BasicMathRequest{Operation: "mod", Operands: []float64{17, 5}, Precision: 2},
+			expected: 2,
+		},
+		{
+			name:     "Modulo with negative dividend",
+			request:  types.BasicMathRequest{Operation: "mod", Operands: []float64{-7, 3}, Precision: 2},
+			expected: -1,
+		},
+		{
+			name:      "Modulo by zero",
+			request:   types.BasicMathRequest{Operation: "mod", Operands: []float64{7, 0}},
+			shouldErr: true,
+		},
+		{
+			name:      "Modulo with three operands",
+			request:   types.BasicMathRequest{Operation: "mod", Operands: []float64{7, 3, 2}},
+			shouldErr: true,
+		},
+		{
+			name:     "Integer division floors",
+			request:  types.BasicMathRequest{Operation: "int_divide", Operands: []float64{-7, 2}, Precision: 2},
+			expected: -4,
+		},
+		{
+			name:     "Power",
+			request:  types.BasicMathRequest{Operation: "power", Operands: []float64{2, 10}, Precision: 2},
+			expected: 1024,
+		},
+		{
+			name:     "Cube root",
+			request:  types.BasicMathRequest{Operation: "nth_root", Operands: []float64{27, 3}, Precision: 4},
+			expected: 3,
+		},
+		{
+			name:     "Odd root of negative",
+			request:  types.BasicMathRequest{Operation: "nth_root", Operands: []float64{-8, 3}, Precision: 4},
+			expected: -2,
+		},
+		{
+			name:      "Even root of negative",
+			request:   types.BasicMathRequest{Operation: "nth_root", Operands: []float64{-16, 4}},
+			shouldErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Calculate(tc.request)
+
+			if tc.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error, but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if math.Abs(result.Result-tc.expected) > 0.0001 {
+				t.Errorf("Expected %f, got %f", tc.expected, result.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_BatchRequests(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8094,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	post := func(body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := post(`[
+		{"jsonrpc":"2.0","id":"sum","method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[2,3]}}},
+		{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"other"}},
+		{"jsonrpc":"2.0","id":2,"method":7},
+		{"jsonrpc":"2.0","id":3,"method":"tools/list"}
+	]`)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+		t.Fatalf("Expected a JSON array response, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var responses []types.MCPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		t.Fatalf("Failed to decode batch response: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses (the notification has none), got %d: %+v", len(responses), responses)
+	}
+	if responses[0].ID != "sum" || responses[0].Error != nil {
+		t.Errorf("Expected the tool call first, got %+v", responses[0])
+	}
+	if responses[1].ID != 2.0 || responses[1].Error == nil || responses[1].Error.Code != mcp.ErrorCodeInvalidRequest {
+		t.Errorf("Expected the invalid member to get its own error, got %+v", responses[1])
+	}
+	if responses[2].ID != 3.0 || responses[2].Error != nil {
+		t.Errorf("Expected tools/list last, got %+v", responses[2])
+	}
+
+	// A batch of notifications has no response body
+	notifications := post(`[{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}]`)
+	notifications.Body.Close()
+	if notifications.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for a batch of notifications, got %d", notifications.StatusCode)
+	}
+
+	// An empty batch is a single invalid request
+	empty := post(`[]`)
+	defer empty.Body.Close()
+	var response types.MCPResponse
+	if err := json.NewDecoder(empty.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode empty batch response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeInvalidRequest {
+		t.Errorf("Expected an invalid request error for an empty batch, got %+v", response)
+	}
+}
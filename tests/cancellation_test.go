@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+// callUntilCancelled starts a tools/call of a tool that blocks until release
+// is closed, cancels it with the given notification, and returns the response
+func callUntilCancelled(t *testing.T, method, params string) types.MCPResponse {
+	t.Helper()
+	server := mcp.NewServer()
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	server.RegisterTool("slow", "Slow", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return map[string]interface{}{"result": 1}, nil
+	})
+
+	ctx := mcp.WithSessionID(context.Background(), "session-a")
+	responses := make(chan types.MCPResponse, 1)
+	go func() {
+		responses <- server.HandleRequestContext(ctx, types.MCPRequest{
+			JSONRPC: "2.0",
+			ID:      json.Number("7"),
+			Method:  "tools/call",
+			Params:  json.RawMessage(`{"name":"slow","arguments":{}}`),
+		})
+	}()
+	<-started
+
+	// Another session cannot cancel the request
+	server.HandleRequestContext(mcp.WithSessionID(context.Background(), "session-b"), types.MCPRequest{JSONRPC: "2.0", Method: method, Params: json.RawMessage(params)})
+	select {
+	case response := <-responses:
+		t.Fatalf("Request finished after another session's cancellation: %+v", response)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", Method: method, Params: json.RawMessage(params)})
+	select {
+	case response := <-responses:
+		if server.InFlightRequests() != 0 {
+			t.Errorf("Expected no requests in flight, got %d", server.InFlightRequests())
+		}
+		return response
+	case <-time.After(2 * time.Second):
+		t.Fatal("Cancelled request did not return")
+		return types.MCPResponse{}
+	}
+}
+
+func TestServer_CancelledNotificationStopsToolCall(t *testing.T) {
+	response := callUntilCancelled(t, "notifications/cancelled", `{"requestId":7,"reason":"user aborted"}`)
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeRequestCancelled {
+		t.Errorf("Expected a request cancelled error, got %+v", response)
+	}
+}
+
+func TestServer_CancelRequestStopsToolCall(t *testing.T) {
+	response := callUntilCancelled(t, "$/cancelRequest", `{"id":7}`)
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeRequestCancelled {
+		t.Errorf("Expected a request cancelled error, got %+v", response)
+	}
+}
+
+func TestServer_CancellationOfUnknownRequestIsIgnored(t *testing.T) {
+	server := mcp.NewServer()
+	response := server.HandleRequestContext(context.Background(), types.MCPRequest{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params:  json.RawMessage(`{"requestId":"missing"}`),
+	})
+	if response.Error != nil {
+		t.Errorf("Expected an unknown request to be ignored, got %+v", response.Error)
+	}
+
+	// The string ID "7" does not cancel the numeric ID 7
+	ctx := mcp.WithSessionID(context.Background(), "s")
+	release := make(chan struct{})
+	server.RegisterContextTool("wait", "Wait", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+		select {
+		case <-release:
+			return map[string]interface{}{"done": true}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	responses := make(chan types.MCPResponse, 1)
+	go func() {
+		responses <- server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: json.Number("7"), Method: "tools/call", Params: json.RawMessage(`{"name":"wait","arguments":{}}`)})
+	}()
+	for server.InFlightRequests() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", Method: "notifications/cancelled", Params: json.RawMessage(`{"requestId":"7"}`)})
+	close(release)
+	if response := <-responses; response.Error != nil {
+		t.Errorf("Expected the request to complete, got %+v", response.Error)
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func startChaosTransport(t *testing.T, port int, chaos *mcp.ChaosConfig) (*mcp.StreamableHTTPTransport, func()) {
+	server := mcp.NewServer()
+	server.RegisterContextTool("work", "Reports progress", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+		mcp.ReportProgress(ctx, 1, 2, "")
+		mcp.ReportProgress(ctx, 2, 2, "")
+		return "done", nil
+	})
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           port,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		HealthEnabled:  true,
+		Chaos:          chaos,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	return httpTransport, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}
+}
+
+func postWork(t *testing.T, url, accept string) *http.Response {
+	req, _ := http.NewRequest("POST", url, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"work","arguments":{},"_meta":{"progressToken":"w"}}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp
+}
+
+func TestChaos_InjectsTransientErrors(t *testing.T) {
+	_, stop := startChaosTransport(t, 8120, &mcp.ChaosConfig{ErrorRate: 1})
+	defer stop()
+
+	resp := postWork(t, "http://127.0.0.1:8120/mcp", "application/json")
+	defer resp.Body.Close()
+	var response types.MCPResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "1" ||
+		response.Error == nil || response.Error.Code != mcp.ErrorCodeServiceUnavailable {
+		t.Errorf("Expected a transient 503, got %d %+v", resp.StatusCode, response.Error)
+	}
+
+	// Operational endpoints are never affected
+	health, err := http.Get("http://127.0.0.1:8120/health")
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("Expected health checks to be served, got %d", health.StatusCode)
+	}
+}
+
+func TestChaos_DropsNotificationsButNotResponses(t *testing.T) {
+	_, stop := startChaosTransport(t, 8121, &mcp.ChaosConfig{DropRate: 1, Delay: 50 * time.Millisecond, DelayRate: 1, Seed: 7})
+	defer stop()
+
+	resp := postWork(t, "http://127.0.0.1:8121/mcp", "application/json, text/event-stream")
+	defer resp.Body.Close()
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	if len(events) != 1 || !strings.Contains(events[0], `"result"`) {
+		t.Errorf("Expected only the response after dropping the progress, got %v", events)
+	}
+}
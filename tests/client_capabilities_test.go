@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func initializeClient(t *testing.T, server *mcp.Server, ctx context.Context, params string) map[string]interface{} {
+	t.Helper()
+	response := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "initialize", Params: json.RawMessage(params)})
+	if response.Error != nil {
+		t.Fatalf("Unexpected initialize error: %+v", response.Error)
+	}
+	return response.Result.(map[string]interface{})
+}
+
+func TestServer_RecordsClientCapabilities(t *testing.T) {
+	server := mcp.NewServer()
+	ctx := mcp.WithSessionID(context.Background(), "client-1")
+
+	result := initializeClient(t, server, ctx, `{"protocolVersion":"2024-11-05",`+
+		`"capabilities":{"sampling":{},"roots":{"listChanged":true}},"clientInfo":{"name":"test-agent","version":"2.0"}}`)
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("Expected the client's protocol version to be echoed, got %v", result["protocolVersion"])
+	}
+
+	client, known := server.Client("client-1")
+	if !known {
+		t.Fatal("Expected the client to be recorded")
+	}
+	if client.Capabilities.Sampling == nil || client.Capabilities.Roots == nil || !client.Capabilities.Roots.ListChanged {
+		t.Errorf("Expected sampling and roots capabilities, got %+v", client.Capabilities)
+	}
+	if client.Capabilities.Elicitation != nil || client.Initialized {
+		t.Errorf("Expected no elicitation and no initialized notification yet, got %+v", client)
+	}
+	if client.ClientInfo == nil || client.ClientInfo.Name != "test-agent" {
+		t.Errorf("Expected the client info, got %+v", client.ClientInfo)
+	}
+
+	server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", Method: mcp.NotificationInitialized})
+	if client, _ := server.Client("client-1"); !client.Initialized {
+		t.Error("Expected notifications/initialized to be recorded")
+	}
+
+	// Unknown versions are answered with the latest, and clients outside a
+	// session are not recorded
+	result = initializeClient(t, server, context.Background(), `{"protocolVersion":"1999-01-01","capabilities":{}}`)
+	if result["protocolVersion"] != "2025-03-26" {
+		t.Errorf("Expected the latest protocol version, got %v", result["protocolVersion"])
+	}
+	if _, known := server.Client(""); known {
+		t.Error("Expected no client to be recorded outside a session")
+	}
+
+	server.ForgetClient("client-1")
+	if _, known := server.Client("client-1"); known {
+		t.Error("Expected the client to be forgotten")
+	}
+
+	response := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "initialize", Params: json.RawMessage(`{"capabilities":[]}`)})
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeInvalidParams {
+		t.Errorf("Expected malformed capabilities to be rejected, got %+v", response)
+	}
+}
+
+func TestServer_HoldsNotificationsUntilInitialized(t *testing.T) {
+	server := mcp.NewServer()
+	ctx := mcp.WithSessionID(context.Background(), "client-1")
+
+	// Broadcasts call the sinks concurrently
+	var mu sync.Mutex
+	received := map[string]int{}
+	count := func(sessionID string) func(types.MCPNotification) {
+		return func(types.MCPNotification) {
+			mu.Lock()
+			defer mu.Unlock()
+			received[sessionID]++
+		}
+	}
+	defer server.SubscribeSession("client-1", count("client-1"))()
+	defer server.SubscribeSession("client-2", count("client-2"))()
+
+	initializeClient(t, server, ctx, `{"protocolVersion":"2025-03-26","capabilities":{}}`)
+	server.RegisterTool("echo", "Echo", map[string]interface{}{"type": "object"}, func(params map[string]interface{}) (interface{}, error) {
+		return params, nil
+	})
+	if received["client-1"] != 0 || received["client-2"] != 1 {
+		t.Errorf("Expected only the session that never initialized to be notified, got %v", received)
+	}
+
+	server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", Method: mcp.NotificationInitialized})
+	server.UnregisterTool("echo")
+	if received["client-1"] != 1 || received["client-2"] != 2 {
+		t.Errorf("Expected both sessions to be notified once initialized, got %v", received)
+	}
+}
+
+func TestServer_PartialResultsFollowClientCapabilities(t *testing.T) {
+	testCases := []struct {
+		name       string
+		initialize string
+		streams    bool
+	}{
+		{"No initialize", "", true},
+		{"Not declared", `{"protocolVersion":"2025-03-26","capabilities":{}}`, false},
+		{"Declared", `{"protocolVersion":"2025-03-26","capabilities":{"experimental":{"partialResults":{}}}}`, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := mcp.NewServer()
+			server.RegisterContextTool("probe", "Reports whether partial results stream", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+				return map[string]bool{"streams": mcp.StreamsPartialResults(ctx), "progress": mcp.ReportsProgress(ctx)}, nil
+			})
+
+			ctx := mcp.WithNotifier(mcp.WithSessionID(context.Background(), "client-1"), func(types.MCPNotification) {})
+			if tc.initialize != "" {
+				initializeClient(t, server, ctx, tc.initialize)
+			}
+			response := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "tools/call",
+				Params: json.RawMessage(`{"name":"probe","arguments":{},"_meta":{"progressToken":"p"}}`)})
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %+v", response.Error)
+			}
+
+			var probe map[string]bool
+			json.Unmarshal([]byte(response.Result.(types.CallToolResult).Content[0].Text), &probe)
+			if probe["streams"] != tc.streams || !probe["progress"] {
+				t.Errorf("Expected streams=%v with progress, got %v", tc.streams, probe)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func newCompletionServer() *mcp.Server {
+	server := mcp.NewServer()
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"value":    map[string]interface{}{"type": "number"},
+			"category": map[string]interface{}{"type": "string", "enum": calculator.DefaultConversionRegistry().Categories()},
+			"fromUnit": map[string]interface{}{"type": "string"},
+		},
+	}
+	completeUnits := func(_ context.Context, arguments map[string]string) ([]string, error) {
+		units, ok := calculator.DefaultConversionRegistry().Units(arguments["category"])
+		if !ok {
+			return nil, fmt.Errorf("unsupported category: %s", arguments["category"])
+		}
+		return units, nil
+	}
+	server.RegisterTool("unit_conversion", "Convert units", schema, func(map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	}, mcp.WithCompletion("fromUnit", completeUnits))
+
+	many := make([]string, 150)
+	for i := range many {
+		many[i] = fmt.Sprintf("item%03d", i)
+	}
+	server.RegisterTool("many", "Many choices", map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"choice": map[string]interface{}{"type": "string", "enum": many}},
+	}, func(map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	server.RegisterPrompt("summarize_data", "Summarize a data set", []types.PromptArgument{{Name: "data", Required: true}}, nil)
+	return server
+}
+
+func complete(server *mcp.Server, params string) types.MCPResponse {
+	return server.HandleRequestContext(context.Background(), types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "completion/complete",
+		Params:  json.RawMessage(params),
+	})
+}
+
+func TestCompletion_ToolArguments(t *testing.T) {
+	server := newCompletionServer()
+
+	initialized := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	capabilities := initialized.Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+	if _, ok := capabilities["completions"]; !ok {
+		t.Errorf("Expected the completions capability to be advertised, got %v", capabilities)
+	}
+
+	tests := []struct {
+		params   string
+		expected []string
+	}{
+		// Schema enums, matched by prefix ignoring case
+		{`{"ref":{"type":"ref/tool","name":"unit_conversion"},"argument":{"name":"category","value":"V"}}`, []string{"volume"}},
+		{`{"ref":{"type":"ref/tool","name":"unit_conversion"},"argument":{"name":"category","value":""}}`, []string{"length", "weight", "temperature", "volume", "area"}},
+		// Completion handlers see the arguments already filled in
+		{`{"ref":{"type":"ref/tool","name":"unit_conversion"},"argument":{"name":"fromUnit","value":"k"},"context":{"arguments":{"category":"length"}}}`, []string{"km"}},
+		{`{"ref":{"type":"ref/tool","name":"unit_conversion"},"argument":{"name":"fromUnit","value":"t"},"context":{"arguments":{"category":"volume"}}}`, []string{"tsp", "tbsp"}},
+		// Free-form arguments have no candidates
+		{`{"ref":{"type":"ref/tool","name":"unit_conversion"},"argument":{"name":"value","value":"1"}}`, []string{}},
+		{`{"ref":{"type":"ref/prompt","name":"summarize_data"},"argument":{"name":"data","value":"1,"}}`, []string{}},
+		{`{"ref":{"type":"ref/resource","uri":"formula://{name}"},"argument":{"name":"name","value":""}}`, []string{}},
+	}
+	for _, test := range tests {
+		response := complete(server, test.params)
+		if response.Error != nil {
+			t.Errorf("%s: completion failed: %+v", test.params, response.Error)
+			continue
+		}
+		completion := response.Result.(types.CompleteResult).Completion
+		if !reflect.DeepEqual(completion.Values, test.expected) || completion.Total != len(test.expected) || completion.HasMore {
+			t.Errorf("%s: expected %v, got %+v", test.params, test.expected, completion)
+		}
+	}
+
+	// Answers are capped at 100 values
+	response := complete(server, `{"ref":{"type":"ref/tool","name":"many"},"argument":{"name":"choice","value":"item"}}`)
+	completion := response.Result.(types.CompleteResult).Completion
+	if len(completion.Values) != 100 || completion.Total != 150 || !completion.HasMore {
+		t.Errorf("Expected 100 of 150 values, got %d of %d (hasMore %v)", len(completion.Values), completion.Total, completion.HasMore)
+	}
+}
+
+func TestCompletion_Errors(t *testing.T) {
+	server := newCompletionServer()
+
+	invalid := []string{
+		`{"ref":{"type":"ref/tool","name":"missing"},"argument":{"name":"x","value":""}}`,
+		`{"ref":{"type":"ref/tool","name":"unit_conversion"},"argument":{"name":"missing","value":""}}`,
+		`{"ref":{"type":"ref/tool","name":"unit_conversion"},"argument":{"name":"fromUnit","value":""},"context":{"arguments":{"category":"speed"}}}`,
+		`{"ref":{"type":"ref/prompt","name":"missing"},"argument":{"name":"data","value":""}}`,
+		`{"ref":{"type":"ref/prompt","name":"summarize_data"},"argument":{"name":"missing","value":""}}`,
+		`{"ref":{"type":"ref/resource","uri":"calculator://missing"},"argument":{"name":"name","value":""}}`,
+		`{"ref":{"type":"ref/unknown"},"argument":{"name":"x","value":""}}`,
+		`{"ref":`,
+	}
+	for _, params := range invalid {
+		if response := complete(server, params); response.Error == nil {
+			t.Errorf("Expected %s to be rejected, got %+v", params, response.Result)
+		}
+	}
+}
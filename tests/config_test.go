@@ -72,6 +72,24 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Several transports",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.Transport = "stdio, http"
+				return cfg
+			},
+			wantErr: false,
+		},
+		{
+			name: "Repeated transport",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.Transport = "http,http"
+				return cfg
+			},
+			wantErr: true,
+		},
 		{
 			name: "Invalid port - too low",
 			config: func() *config.Config {
@@ -108,6 +126,283 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Invalid storage backend",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Storage.Enabled = true
+				cfg.Storage.Backend = "sqlite"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Relative job webhook URL",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.Jobs.WebhookURL = "/hooks/calculator"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "CORS credentials for any origin",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.CORS.Origins = []string{"*"}
+				cfg.Server.HTTP.CORS.AllowCredentials = true
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative statistics workers",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Tools.Statistics.Workers = -1
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative slow call threshold",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Logging.SlowCallThreshold = -time.Second
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Stateless server requiring sessions",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.Stateless = true
+				cfg.Server.HTTP.RequireSession = true
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative session call limit",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Security.SessionConcurrency.MaxCalls = -1
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Ops port equal to the MCP port",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.OpsPort = cfg.Server.HTTP.Port
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Redis backend without a URL",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Storage.Enabled = true
+				cfg.Storage.Backend = "redis"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid stdio framing",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.Stdio.Framing = "xml"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "TLS without a certificate",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.TLS.Enabled = true
+				cfg.Server.HTTP.TLS.KeyFile = "server-key.pem"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "API keys alongside tenants",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.Auth.APIKeys = []config.CredentialConfig{{Name: "dashboard", Key: "dashboard-key"}}
+				cfg.Tenants = []config.TenantConfig{{ID: "team-a", APIKeys: []string{"key-a"}}}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Tenant exchange rate that is not positive",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Tenants = []config.TenantConfig{{ID: "team-a", APIKeys: []string{"key-a"}, ExchangeRates: &config.ExchangeRatesConfig{Base: "EUR", Rates: map[string]float64{"USD": 0}}}}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Duplicate bearer token",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.Auth.BearerTokens = []config.CredentialConfig{{Name: "a", Key: "token"}, {Name: "b", Key: "token"}}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "OAuth without an issuer",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.OAuth.Enabled = true
+				cfg.Server.HTTP.OAuth.ResourceURL = "https://calc.example.com/mcp"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown rate limit key",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Security.RateLimiting.KeyBy = "user_agent"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown tool strictness",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Tools.Strictness = "loose"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unreadable request size limit",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Security.RequestSizeLimit = "lots"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Zero max JSON depth",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Security.MaxJSONDepth = 0
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative max connections",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.MaxConnections = -1
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative credential quota",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.Auth.BearerTokens = []config.CredentialConfig{{Name: "agents", Key: "token", DailyQuota: -1}}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative SSE flush interval",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.SSEFlushInterval = -time.Second
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative idempotency TTL",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.IdempotencyTTL = -time.Hour
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Chaos rate above one",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.Chaos = config.ChaosConfig{Enabled: true, ErrorRate: 1.5}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid trusted proxy",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.TrustedProxies = []string{"10.0.0.0/8", "proxy.internal"}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Allowed origin with a path",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.AllowedOrigins = []string{"https://app.example.com/calc"}
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid stdio max calls",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.Stdio.MaxCalls = 0
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Relative base path",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.HTTP.BasePath = "calc"
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Zero shutdown timeout",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Server.ShutdownTimeout = 0
+				return cfg
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid tool ordering",
+			config: func() *config.Config {
+				cfg := config.Default()
+				cfg.Tools.Ordering = "random"
+				return cfg
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -199,6 +494,34 @@ tools:
 	}
 }
 
+func TestConfigLoaderRateLimitingEnabled(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "config-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A file that leaves enabled out keeps the default rather than
+	// turning rate limiting off
+	for file, enabled := range map[string]bool{
+		"security:\n  rate_limiting:\n    requests_per_minute: 50\n": true,
+		"security:\n  rate_limiting:\n    enabled: false\n":          false,
+		"logging:\n  level: debug\n":                                 true,
+	} {
+		path := filepath.Join(tempDir, "config.yaml")
+		if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatalf("Failed to write YAML config: %v", err)
+		}
+		cfg, err := config.NewLoader().Load(path)
+		if err != nil {
+			t.Fatalf("Failed to load %q: %v", file, err)
+		}
+		if cfg.Security.RateLimiting.IsEnabled() != enabled {
+			t.Errorf("Loading %q: expected rate limiting enabled %v", file, enabled)
+		}
+	}
+}
+
 func TestConfigLoaderJSON(t *testing.T) {
 	// Create temporary directory for test configs
 	tempDir, err := ioutil.TempDir("", "config-test")
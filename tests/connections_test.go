@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_MaxConnections(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	server := mcp.NewServer()
+	server.RegisterTool("wait", "Waits to be released", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		close(started)
+		<-release
+		return "done", nil
+	})
+
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8112,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 1,
+		Stateless:      true,
+		HealthEnabled:  true,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	post := func(method string) *http.Response {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8112/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":{"name":"wait"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Errorf("Request failed: %v", err)
+			return nil
+		}
+		return resp
+	}
+
+	// A call in progress holds the only connection
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if resp := post("tools/call"); resp != nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	resp := post("tools/list")
+	var response types.MCPResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "1" || response.Error == nil || response.Error.Code != mcp.ErrorCodeServiceUnavailable {
+		t.Errorf("Expected 503 with Retry-After beyond the limit, got %d %+v", resp.StatusCode, response.Error)
+	}
+
+	// Health checks still answer, reporting the load
+	health, err := http.Get("http://127.0.0.1:8112/health")
+	if err != nil {
+		t.Fatalf("Health request failed: %v", err)
+	}
+	var body struct {
+		Connections mcp.ConnectionStats `json:"connections"`
+	}
+	json.NewDecoder(health.Body).Decode(&body)
+	health.Body.Close()
+	if want := (mcp.ConnectionStats{Active: 1, Max: 1, Rejected: 1}); body.Connections != want {
+		t.Errorf("Expected %+v, got %+v", want, body.Connections)
+	}
+
+	// The connection is released once the handler returns, which can be
+	// just after the client read the response
+	close(release)
+	<-done
+	for deadline := time.Now().Add(time.Second); httpTransport.ConnectionStats().Active != 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resp := post("tools/list"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the connection to be free again, got %d", resp.StatusCode)
+	} else {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestConversionRegistry_Builtin(t *testing.T) {
+	registry := calculator.DefaultConversionRegistry()
+
+	expected := []string{"length", "weight", "temperature", "volume", "area"}
+	if categories := registry.Categories(); !reflect.DeepEqual(categories, expected) {
+		t.Errorf("Expected categories %v, got %v", expected, categories)
+	}
+	if units, ok := registry.Units("temperature"); !ok || !reflect.DeepEqual(units, []string{"C", "F", "K", "R"}) {
+		t.Errorf("Expected the temperature units, got %v", units)
+	}
+	if factor, ok := registry.Factor("length", "mi"); !ok || factor != 1609.344 {
+		t.Errorf("Expected a mile to be 1609.344 m, got %v", factor)
+	}
+	if _, ok := registry.Factor("temperature", "C"); ok {
+		t.Error("Expected temperatures to have no factor")
+	}
+	if pi, ok := registry.Constant("pi"); !ok || pi != math.Pi {
+		t.Errorf("Expected pi, got %v", pi)
+	}
+
+	// Callers get copies and cannot change the shared tables
+	registry.Categories()[0] = "changed"
+	registry.Constants()["pi"] = 3
+	if registry.Categories()[0] != "length" {
+		t.Error("Expected the categories to be unchanged")
+	}
+	if pi, _ := registry.Constant("pi"); pi != math.Pi {
+		t.Error("Expected the constants to be unchanged")
+	}
+}
+
+func TestConversionRegistry_DataFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.yaml")
+	data := `
+units:
+  length:
+    furlong: 201.168
+  energy:
+    kJ: 1000
+    J: 1
+constants:
+  g: 9.80665
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := calculator.LoadConversionRegistry(path)
+	if err != nil {
+		t.Fatalf("Failed to load conversion data: %v", err)
+	}
+
+	if categories := registry.Categories(); categories[len(categories)-1] != "energy" {
+		t.Errorf("Expected energy after the built-in categories, got %v", categories)
+	}
+	if units, _ := registry.Units("energy"); !reflect.DeepEqual(units, []string{"J", "kJ"}) {
+		t.Errorf("Expected the energy units in name order, got %v", units)
+	}
+	if units, _ := registry.Units("length"); units[len(units)-1] != "furlong" {
+		t.Errorf("Expected furlong after the built-in length units, got %v", units)
+	}
+
+	// Calculators created from the registry see the added units and constants
+	previous := calculator.DefaultConversionRegistry()
+	calculator.SetDefaultConversionRegistry(registry)
+	defer calculator.SetDefaultConversionRegistry(previous)
+
+	result, err := calculator.NewUnitConverter().Convert(types.UnitConversionRequest{Value: 8, FromUnit: "furlong", ToUnit: "mi", Category: "length"})
+	if err != nil || math.Abs(result.Result-1) > 1e-12 {
+		t.Errorf("Expected 8 furlongs to be a mile, got %v (%v)", result.Result, err)
+	}
+	result, err = calculator.NewUnitConverter().Convert(types.UnitConversionRequest{Value: 2.5, FromUnit: "kJ", ToUnit: "J", Category: "energy"})
+	if err != nil || result.Result != 2500 {
+		t.Errorf("Expected 2.5 kJ to be 2500 J, got %v (%v)", result.Result, err)
+	}
+	evaluated, err := calculator.NewExpressionCalculator().Evaluate(types.ExpressionRequest{Expression: "g * 2"})
+	if err != nil || evaluated.Result != 2*9.80665 {
+		t.Errorf("Expected g in expressions, got %v (%v)", evaluated.Result, err)
+	}
+	if _, err := calculator.NewExpressionCalculator().Evaluate(types.ExpressionRequest{Expression: "G + 1", Variables: map[string]float64{"G": 1}}); err == nil {
+		t.Error("Expected constants to be reserved as variable names")
+	}
+}
+
+func TestConversionRegistry_InvalidData(t *testing.T) {
+	invalid := []calculator.ConversionData{
+		{Units: map[string]map[string]float64{"temperature": {"De": 1}}},
+		{Units: map[string]map[string]float64{"length": {"league": 0}}},
+		{Units: map[string]map[string]float64{"length": {"league": -4828}}},
+		{Units: map[string]map[string]float64{"length": {"league": math.Inf(1)}}},
+		{Units: map[string]map[string]float64{"": {"x": 1}}},
+		{Constants: map[string]float64{"sin": 1}},
+		{Constants: map[string]float64{"2pi": 1}},
+		{Constants: map[string]float64{"big": math.NaN()}},
+	}
+	for _, data := range invalid {
+		if _, err := calculator.NewConversionRegistry(data); err == nil {
+			t.Errorf("Expected %+v to be rejected", data)
+		}
+	}
+
+	if _, err := calculator.LoadConversionRegistry(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected a missing data file to be rejected")
+	}
+	path := filepath.Join(t.TempDir(), "units.json")
+	if err := os.WriteFile(path, []byte(`{"units": {"length": {"league": "far"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calculator.LoadConversionRegistry(path); err == nil {
+		t.Error("Expected a malformed data file to be rejected")
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestCookingCalculator_Scale(t *testing.T) {
+	cc := calculator.NewCookingCalculator()
+
+	result, err := cc.Calculate(types.CookingRequest{
+		Operation:      "scale",
+		Servings:       4,
+		TargetServings: 6,
+		Ingredients: []types.CookingIngredient{
+			{Name: "flour", Quantity: 2, Unit: "cup"},
+			{Name: "eggs", Quantity: 3, Unit: "eggs"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("scale failed: %v", err)
+	}
+	if *result.Factor != 1.5 || result.Ingredients[0].Quantity != 3 || result.Ingredients[1].Quantity != 4.5 || result.Ingredients[1].Unit != "eggs" {
+		t.Errorf("Expected the recipe scaled by 1.5, got %+v", result)
+	}
+
+	invalid := []types.CookingRequest{
+		{Operation: "scale", Factor: floatPtr(2)},
+		{Operation: "scale", Factor: floatPtr(0), Ingredients: []types.CookingIngredient{{Quantity: 1}}},
+		{Operation: "scale", Factor: floatPtr(2), Servings: 4, TargetServings: 8, Ingredients: []types.CookingIngredient{{Quantity: 1}}},
+		{Operation: "scale", Servings: 4, Ingredients: []types.CookingIngredient{{Quantity: 1}}},
+		{Operation: "scale", Factor: floatPtr(2), Ingredients: []types.CookingIngredient{{Name: "milk", Quantity: -1}}},
+		{Operation: "bake"},
+	}
+	for _, req := range invalid {
+		if _, err := cc.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestCookingCalculator_Convert(t *testing.T) {
+	cc := calculator.NewCookingCalculator()
+
+	tests := []struct {
+		name     string
+		req      types.CookingRequest
+		expected float64
+	}{
+		{"cup of flour to grams", types.CookingRequest{Quantity: floatPtr(1), FromUnit: "cup", ToUnit: "g", Ingredient: "All-Purpose Flour"}, 125},
+		{"grams of sugar to cups", types.CookingRequest{Quantity: floatPtr(100), FromUnit: "g", ToUnit: "cup", Ingredient: "sugar"}, 0.5},
+		{"tablespoons of butter to ounces", types.CookingRequest{Quantity: floatPtr(16), FromUnit: "tbsp", ToUnit: "oz", Ingredient: "butter"}, 227 / 28.3495},
+		{"cups to millilitres", types.CookingRequest{Quantity: floatPtr(2), FromUnit: "cup", ToUnit: "ml"}, 473.176},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Operation = "convert"
+			result, err := cc.Calculate(tt.req)
+			if err != nil {
+				t.Fatalf("convert failed: %v", err)
+			}
+			if math.Abs(*result.Quantity-tt.expected) > 1e-2 {
+				t.Errorf("Expected %v %s, got %v", tt.expected, tt.req.ToUnit, *result.Quantity)
+			}
+		})
+	}
+
+	invalid := []types.CookingRequest{
+		{Operation: "convert", Quantity: floatPtr(1), FromUnit: "cup", ToUnit: "g"},
+		{Operation: "convert", Quantity: floatPtr(1), FromUnit: "cup", ToUnit: "g", Ingredient: "unobtainium"},
+		{Operation: "convert", Quantity: floatPtr(1), FromUnit: "cup", ToUnit: "m"},
+		{Operation: "convert", FromUnit: "cup", ToUnit: "ml"},
+	}
+	for _, req := range invalid {
+		if _, err := cc.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestCookingCalculator_OvenTemperature(t *testing.T) {
+	cc := calculator.NewCookingCalculator()
+
+	result, err := cc.Calculate(types.CookingRequest{Operation: "oven_temperature", Temperature: floatPtr(180), FromUnit: "C"})
+	if err != nil {
+		t.Fatalf("oven_temperature failed: %v", err)
+	}
+	if *result.Fahrenheit != 356 || result.GasMark == nil || *result.GasMark != 4 {
+		t.Errorf("Expected 180 °C to be 356 °F and gas mark 4, got %+v", result)
+	}
+
+	mark, err := cc.Calculate(types.CookingRequest{Operation: "oven_temperature", Temperature: floatPtr(0.5), FromUnit: "gas_mark"})
+	if err != nil {
+		t.Fatalf("gas mark conversion failed: %v", err)
+	}
+	if *mark.Fahrenheit != 250 || math.Abs(*mark.Celsius-121.11) > 0.01 {
+		t.Errorf("Expected gas mark 1/2 to be 250 °F, got %+v", mark)
+	}
+
+	// A grill temperature is beyond the gas mark scale
+	hot, err := cc.Calculate(types.CookingRequest{Operation: "oven_temperature", Temperature: floatPtr(300), FromUnit: "C"})
+	if err != nil || hot.GasMark != nil {
+		t.Errorf("Expected no gas mark for 300 °C, got %+v (%v)", hot, err)
+	}
+
+	invalid := []types.CookingRequest{
+		{Operation: "oven_temperature", Temperature: floatPtr(10), FromUnit: "gas_mark"},
+		{Operation: "oven_temperature", Temperature: floatPtr(450), FromUnit: "K"},
+		{Operation: "oven_temperature", FromUnit: "C"},
+	}
+	for _, req := range invalid {
+		if _, err := cc.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
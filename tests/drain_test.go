@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+// startDrainTransport serves a tool that takes hold to return, or is
+// cancelled; entered is signalled once the tool runs
+func startDrainTransport(t *testing.T, port int, hold time.Duration) (*mcp.StreamableHTTPTransport, chan struct{}, chan error) {
+	entered := make(chan struct{}, 1)
+	ended := make(chan error, 1)
+	server := mcp.NewServer()
+	server.RegisterContextTool("slow", "Takes its time", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+		entered <- struct{}{}
+		select {
+		case <-time.After(hold):
+			ended <- nil
+			return "done", nil
+		case <-ctx.Done():
+			ended <- ctx.Err()
+			return nil, ctx.Err()
+		}
+	})
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           port,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	return httpTransport, entered, ended
+}
+
+func callSlow(port int) (*http.Response, error) {
+	req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", port),
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{}}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+	return http.DefaultClient.Do(req)
+}
+
+func TestStreamableHTTP_DrainsRunningCalls(t *testing.T) {
+	httpTransport, entered, _ := startDrainTransport(t, 8123, 300*time.Millisecond)
+
+	answered := make(chan *types.MCPResponse, 1)
+	go func() {
+		resp, err := callSlow(8123)
+		if err != nil {
+			answered <- nil
+			return
+		}
+		defer resp.Body.Close()
+		var response types.MCPResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		answered <- &response
+	}()
+	<-entered
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpTransport.Stop(shutdownCtx); err != nil {
+		t.Errorf("Expected the call to finish within the deadline, got %v", err)
+	}
+	if response := <-answered; response == nil || response.Result == nil {
+		t.Errorf("Expected the running call to be answered, got %+v", response)
+	}
+}
+
+func TestStreamableHTTP_CancelsCallsAfterDrainDeadline(t *testing.T) {
+	httpTransport, entered, ended := startDrainTransport(t, 8124, time.Minute)
+
+	go func() {
+		if resp, err := callSlow(8124); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-entered
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := httpTransport.Stop(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to be reported, got %v", err)
+	}
+	select {
+	case err := <-ended:
+		if err == nil {
+			t.Error("Expected the call to be cancelled")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the call to be cancelled once the deadline passed")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Stop to return at the deadline, took %v", elapsed)
+	}
+}
+
+func TestJobManager_Shutdown(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterContextTool("wait", "Waits until cancelled", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	jobs := mcp.NewJobManager(server, nil)
+	job, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "wait", Arguments: map[string]interface{}{}})
+	if mcpErr != nil {
+		t.Fatalf("Submit failed: %+v", mcpErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := jobs.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to be reported, got %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		current, _ := jobs.Get(job.ID)
+		if current.Status == types.JobStatusFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the cancelled job to fail, got %s", current.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "wait"}); mcpErr == nil || mcpErr.Code != mcp.ErrorCodeServiceUnavailable {
+		t.Errorf("Expected jobs to be refused after shutdown, got %+v", mcpErr)
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestElectronicsCalculator_OhmsLaw(t *testing.T) {
+	ec := calculator.NewElectronicsCalculator()
+
+	// Every pair of 12 V, 2 A, 6 Ω and 24 W recovers the other two
+	expected := map[string]float64{"voltage": 12, "current": 2, "resistance": 6, "power": 24}
+	names := []string{"voltage", "current", "resistance", "power"}
+	for i, first := range names {
+		for _, second := range names[i+1:] {
+			req := types.ElectronicsRequest{Operation: "ohms_law"}
+			for _, name := range []string{first, second} {
+				value := expected[name]
+				switch name {
+				case "voltage":
+					req.Voltage = &value
+				case "current":
+					req.Current = &value
+				case "resistance":
+					req.Resistance = &value
+				case "power":
+					req.Power = &value
+				}
+			}
+
+			result, err := ec.Calculate(req)
+			if err != nil {
+				t.Fatalf("ohms_law from %s and %s failed: %v", first, second, err)
+			}
+			for name, value := range expected {
+				if math.Abs(result.Values[name]-value) > 1e-9 {
+					t.Errorf("From %s and %s expected %s = %v, got %v", first, second, name, value, result.Values[name])
+				}
+			}
+		}
+	}
+
+	voltage := 12.0
+	if _, err := ec.Calculate(types.ElectronicsRequest{Operation: "ohms_law", Voltage: &voltage}); err == nil {
+		t.Error("Expected ohms_law with one value to be rejected")
+	}
+	zero := 0.0
+	if _, err := ec.Calculate(types.ElectronicsRequest{Operation: "ohms_law", Voltage: &voltage, Resistance: &zero}); err == nil {
+		t.Error("Expected a zero resistance to be rejected")
+	}
+}
+
+func TestElectronicsCalculator_Circuits(t *testing.T) {
+	ec := calculator.NewElectronicsCalculator()
+
+	tests := []struct {
+		operation string
+		component string
+		values    []float64
+		total     float64
+		formatted string
+	}{
+		{"series", "resistor", []float64{1000, 2200}, 3200, "3.2 kΩ"},
+		{"parallel", "resistor", []float64{1000, 1000}, 500, "500 Ω"},
+		{"parallel", "capacitor", []float64{1e-6, 2.2e-6}, 3.2e-6, "3.2 μF"},
+		{"series", "capacitor", []float64{2e-6, 2e-6}, 1e-6, "1 μF"},
+		{"series", "inductor", []float64{0.01, 0.0047}, 0.0147, "14.7 mH"},
+	}
+	for _, tt := range tests {
+		result, err := ec.Calculate(types.ElectronicsRequest{Operation: tt.operation, Component: tt.component, Values: tt.values})
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", tt.operation, tt.component, err)
+		}
+		if math.Abs(result.Values["total"]-tt.total) > 1e-12 || result.Formatted["total"] != tt.formatted {
+			t.Errorf("%s %s of %v: expected %v (%s), got %v (%s)", tt.operation, tt.component, tt.values, tt.total, tt.formatted, result.Values["total"], result.Formatted["total"])
+		}
+	}
+
+	vin, r1, r2, load := 12.0, 10000.0, 10000.0, 10000.0
+	divider, err := ec.Calculate(types.ElectronicsRequest{Operation: "voltage_divider", InputVoltage: &vin, R1: &r1, R2: &r2})
+	if err != nil {
+		t.Fatalf("voltage_divider failed: %v", err)
+	}
+	if divider.Values["output_voltage"] != 6 || divider.Formatted["current"] != "600 μA" {
+		t.Errorf("Expected 6 V at 600 μA, got %+v", divider)
+	}
+	loaded, err := ec.Calculate(types.ElectronicsRequest{Operation: "voltage_divider", InputVoltage: &vin, R1: &r1, R2: &r2, Load: &load})
+	if err != nil {
+		t.Fatalf("loaded voltage_divider failed: %v", err)
+	}
+	if math.Abs(loaded.Values["output_voltage"]-4) > 1e-9 {
+		t.Errorf("Expected a loaded output of 4 V, got %v", loaded.Values["output_voltage"])
+	}
+
+	resistance, capacitance, elapsed := 4700.0, 10e-6, 0.047
+	rc, err := ec.Calculate(types.ElectronicsRequest{Operation: "rc_time_constant", Resistance: &resistance, Capacitance: &capacitance, Time: &elapsed})
+	if err != nil {
+		t.Fatalf("rc_time_constant failed: %v", err)
+	}
+	if rc.Formatted["time_constant"] != "47 ms" || math.Abs(rc.Values["charge_percent"]-63.212) > 0.001 {
+		t.Errorf("Expected τ = 47 ms and 63.2%% charged after τ, got %+v", rc)
+	}
+
+	if _, err := ec.Calculate(types.ElectronicsRequest{Operation: "series", Component: "diode", Values: []float64{1, 2}}); err == nil {
+		t.Error("Expected an unknown component to be rejected")
+	}
+	if _, err := ec.Calculate(types.ElectronicsRequest{Operation: "parallel", Component: "resistor", Values: []float64{100, -1}}); err == nil {
+		t.Error("Expected a negative resistance to be rejected")
+	}
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+// newElicitingServer registers an "interest" tool whose schema requires
+// principal and whose rate is required through WithRequiredArguments
+func newElicitingServer() *mcp.Server {
+	server := mcp.NewServer()
+	server.RegisterTool("interest", "Simple interest for one year", map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"principal": map[string]interface{}{"type": "number", "minimum": 0, "description": "Amount invested"},
+			"rate":      map[string]interface{}{"type": "number", "description": "Annual rate in percent"},
+		},
+		"required": []string{"principal"},
+	}, func(params map[string]interface{}) (interface{}, error) {
+		principal, _ := params["principal"].(float64)
+		rate, ok := params["rate"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("rate is required")
+		}
+		return map[string]interface{}{"interest": principal * rate / 100}, nil
+	}, mcp.WithRequiredArguments(func(map[string]interface{}) []string { return []string{"rate"} }))
+	server.SetElicitation("interest", true)
+	return server
+}
+
+// stdioClient drives a stdio server one message at a time
+type stdioClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	lines  chan string
+	server chan error
+}
+
+func startStdioClient(t *testing.T, server *mcp.Server) *stdioClient {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	client := &stdioClient{t: t, in: inWriter, lines: make(chan string, 10), server: make(chan error, 1)}
+	go func() {
+		client.server <- server.Run(inReader, outWriter)
+		outWriter.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			client.lines <- scanner.Text()
+		}
+		close(client.lines)
+	}()
+	return client
+}
+
+func (c *stdioClient) send(message string) {
+	if _, err := io.WriteString(c.in, message+"\n"); err != nil {
+		c.t.Fatalf("Failed to write %s: %v", message, err)
+	}
+}
+
+func (c *stdioClient) receive() map[string]interface{} {
+	c.t.Helper()
+	select {
+	case line := <-c.lines:
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			c.t.Fatalf("Failed to decode %s: %v", line, err)
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("Timed out waiting for the server")
+		return nil
+	}
+}
+
+func (c *stdioClient) close() {
+	c.in.Close()
+	if err := <-c.server; err != nil {
+		c.t.Errorf("Run failed: %v", err)
+	}
+}
+
+func (c *stdioClient) initialize(capabilities string) {
+	c.send(`{"jsonrpc":"2.0","id":"init","method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":` + capabilities + `}}`)
+	c.receive()
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+}
+
+func TestElicitation_Accept(t *testing.T) {
+	client := startStdioClient(t, newElicitingServer())
+	defer client.close()
+	client.initialize(`{"elicitation":{}}`)
+
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"interest","arguments":{}}}`)
+	request := client.receive()
+	if request["method"] != mcp.MethodElicitationCreate {
+		t.Fatalf("Expected an elicitation request, got %v", request)
+	}
+	params := request["params"].(map[string]interface{})
+	schema := params["requestedSchema"].(map[string]interface{})
+	properties := schema["properties"].(map[string]interface{})
+	if len(properties) != 2 || properties["principal"] == nil || properties["rate"] == nil {
+		t.Errorf("Expected principal and rate to be requested, got %v", schema)
+	}
+	if fmt.Sprint(schema["required"]) != "[principal rate]" {
+		t.Errorf("Expected both fields to be required, got %v", schema["required"])
+	}
+
+	id, _ := json.Marshal(request["id"])
+	client.send(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"action":"accept","content":{"principal":1000,"rate":5,"extra":1}}}`)
+
+	var response types.MCPResponse
+	responseJSON, _ := json.Marshal(client.receive())
+	json.Unmarshal(responseJSON, &response)
+	if response.Error != nil || !strings.Contains(string(responseJSON), `\"interest\":50`) {
+		t.Errorf("Expected the call to run with the elicited arguments, got %s", responseJSON)
+	}
+}
+
+func TestElicitation_Decline(t *testing.T) {
+	client := startStdioClient(t, newElicitingServer())
+	defer client.close()
+	client.initialize(`{"elicitation":{}}`)
+
+	// Arguments that are present are not asked for
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"interest","arguments":{"principal":1000}}}`)
+	request := client.receive()
+	schema := request["params"].(map[string]interface{})["requestedSchema"].(map[string]interface{})
+	if fmt.Sprint(schema["required"]) != "[rate]" {
+		t.Errorf("Expected only rate to be requested, got %v", schema["required"])
+	}
+
+	id, _ := json.Marshal(request["id"])
+	client.send(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"action":"decline"}}`)
+	response := client.receive()
+	result, _ := response["result"].(map[string]interface{})
+	if result["isError"] != true || !strings.Contains(fmt.Sprint(result["content"]), "decline") {
+		t.Errorf("Expected an error result saying the user declined, got %v", response)
+	}
+}
+
+func TestElicitation_NotUsed(t *testing.T) {
+	// Clients without the capability get the tool's own error
+	client := startStdioClient(t, newElicitingServer())
+	client.initialize(`{}`)
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"interest","arguments":{"principal":1000}}}`)
+	response := client.receive()
+	if result, _ := response["result"].(map[string]interface{}); result["isError"] != true || !strings.Contains(fmt.Sprint(result["content"]), "rate is required") {
+		t.Errorf("Expected the call to fail without elicitation, got %v", response)
+	}
+	client.close()
+
+	// Tools without elicitation enabled are called as they are
+	server := newElicitingServer()
+	server.SetElicitation("interest", false)
+	client = startStdioClient(t, server)
+	client.initialize(`{"elicitation":{}}`)
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"interest","arguments":{"principal":1000}}}`)
+	if response := client.receive(); response["method"] != nil {
+		t.Errorf("Expected no elicitation for a tool without it, got %v", response)
+	}
+	client.close()
+}
+
+func TestElicitation_StreamableHTTP(t *testing.T) {
+	httpConfig := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8103,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(newElicitingServer(), httpConfig)
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	post := func(sessionID, accept, body string) *http.Response {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", httpConfig.Port), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", accept)
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := post("", "application/json", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"elicitation":{}}}}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	post(sessionID, "application/json", `{"jsonrpc":"2.0","method":"notifications/initialized"}`).Body.Close()
+
+	// The elicitation arrives on the call's event stream and is answered
+	// with a POST of its own
+	resp = post(sessionID, "application/json, text/event-stream", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"interest","arguments":{"principal":200}}}`)
+	defer resp.Body.Close()
+	scanner := bufio.NewScanner(resp.Body)
+	answered := false
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data: ")
+		if !found {
+			continue
+		}
+		var message map[string]interface{}
+		json.Unmarshal([]byte(data), &message)
+		if message["method"] == mcp.MethodElicitationCreate {
+			id, _ := json.Marshal(message["id"])
+			answer := post(sessionID, "application/json", `{"jsonrpc":"2.0","id":`+string(id)+`,"result":{"action":"accept","content":{"rate":10}}}`)
+			answer.Body.Close()
+			if answer.StatusCode != http.StatusAccepted {
+				t.Errorf("Expected the answer to be accepted with 202, got %d", answer.StatusCode)
+			}
+			answered = true
+			continue
+		}
+		if !answered || !strings.Contains(data, `\"interest\":20`) {
+			t.Errorf("Expected the call to finish with the elicited rate, got %s", data)
+		}
+		return
+	}
+	t.Fatal("Stream ended without a response")
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestLinearSolver_Solve(t *testing.T) {
+	solver := calculator.NewLinearSolver()
+
+	tests := []struct {
+		name      string
+		equations []string
+		exact     map[string]string
+	}{
+		{"two variables", []string{"2x + 3y = 7", "x - y = 1"}, map[string]string{"x": "2", "y": "1"}},
+		{"rational solution", []string{"3a + b = 2", "a - b = 1"}, map[string]string{"a": "3/4", "b": "-1/4"}},
+		{"fractions and variables on both sides", []string{"x/2 + 3/4y = 2", "y = x - 1"}, map[string]string{"x": "11/5", "y": "6/5"}},
+		{"decimal coefficients", []string{"0.5*rate + 2 = 3"}, map[string]string{"rate": "2"}},
+		{"redundant equation", []string{"x + y = 3", "2x + 2y = 6", "x - y = 1"}, map[string]string{"x": "2", "y": "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := solver.Solve(types.LinearSystemRequest{Equations: tt.equations})
+			if err != nil {
+				t.Fatalf("Solve failed: %v", err)
+			}
+			for name, expected := range tt.exact {
+				if result.Exact[name] != expected {
+					t.Errorf("Expected %s = %s, got %s", name, expected, result.Exact[name])
+				}
+			}
+		})
+	}
+}
+
+func TestLinearSolver_Errors(t *testing.T) {
+	solver := calculator.NewLinearSolver()
+
+	tests := []struct {
+		name      string
+		equations []string
+		contains  string
+	}{
+		{"inconsistent", []string{"x + y = 1", "x + y = 2"}, "inconsistent"},
+		{"underdetermined", []string{"x + y = 1"}, "infinitely many"},
+		{"missing equals", []string{"x + y"}, "'='"},
+		{"non-linear", []string{"x*y = 1"}, `at "*y"`},
+		{"variable divisor", []string{"4/x = 1"}, "invalid divisor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := solver.Solve(types.LinearSystemRequest{Equations: tt.equations})
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Expected an error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_ErrorResponses(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+	server.RegisterTool("broken", "Always panics", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		panic("boom")
+	})
+
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8087,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	tests := []struct {
+		name         string
+		body         string
+		expected     int
+		expectedCode int
+		expectedID   interface{}
+	}{
+		{"handler panic", toolCallBody(`{"name":"broken","arguments":{}}`), http.StatusInternalServerError, mcp.ErrorCodeInternalError, 1.0},
+		{"malformed JSON", `{"jsonrpc":"2.0","id":"req-7","method":"tools/list"`, http.StatusBadRequest, mcp.ErrorCodeParseError, "req-7"},
+		{"not a request object", `{"jsonrpc":"2.0","id":42,"method":7}`, http.StatusBadRequest, mcp.ErrorCodeInvalidRequest, 42.0},
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+			req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+
+			var response types.MCPResponse
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Error == nil || response.Error.Code != tt.expectedCode {
+				t.Errorf("Expected error code %d, got %+v", tt.expectedCode, response.Error)
+			}
+			if response.ID != tt.expectedID {
+				t.Errorf("Expected the request ID %v to be echoed, got %v", tt.expectedID, response.ID)
+			}
+		})
+	}
+
+	// Tools that reject their arguments answer 200 with an isError result,
+	// leaving JSON-RPC errors to protocol problems
+	failures := []struct {
+		name     string
+		body     string
+		text     string
+		calcCode string
+	}{
+		{"division by zero", toolCallBody(`{"name":"basic_math","arguments":{"operation":"divide","operands":[1,0]}}`), "division by zero", types.ErrCodeDivisionByZero},
+		{"unsupported operation", toolCallBody(`{"name":"basic_math","arguments":{"operation":"median","operands":[1,2]}}`), "invalid operation: median", ""},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+			req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", resp.StatusCode)
+			}
+
+			var response struct {
+				Error  *types.MCPError `json:"error"`
+				Result struct {
+					Content           []types.ContentBlock `json:"content"`
+					StructuredContent struct {
+						Error *types.CalculationError `json:"error"`
+					} `json:"structuredContent"`
+					IsError bool `json:"isError"`
+				} `json:"result"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Error != nil || !response.Result.IsError {
+				t.Fatalf("Expected an isError result, got %+v", response)
+			}
+			if len(response.Result.Content) != 1 || !strings.Contains(strings.ToLower(response.Result.Content[0].Text), tt.text) {
+				t.Errorf("Expected the failure to be described, got %+v", response.Result.Content)
+			}
+			calcErr := response.Result.StructuredContent.Error
+			if (tt.calcCode == "") != (calcErr == nil) || (calcErr != nil && calcErr.Code != tt.calcCode) {
+				t.Errorf("Expected calculation error code %q, got %+v", tt.calcCode, calcErr)
+			}
+		})
+	}
+}
+
+func toolCallBody(params string) string {
+	return `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":` + params + `}`
+}
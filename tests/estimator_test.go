@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestEstimatorCalculator_Paint(t *testing.T) {
+	ec := calculator.NewEstimatorCalculator()
+
+	// Four walls of a 4 m × 3 m room, 2.5 m high, less a door and a window
+	result, err := ec.Calculate(types.EstimatorRequest{Operation: "paint", Length: floatPtr(4), Width: floatPtr(3), Height: floatPtr(2.5), Openings: 5})
+	if err != nil {
+		t.Fatalf("paint failed: %v", err)
+	}
+	if result.AreaM2 != 30 || math.Abs(*result.Litres-6) > 1e-9 || math.Abs(*result.Gallons-6/3.785411784) > 1e-9 {
+		t.Errorf("Expected 30 m² needing 6 litres for two coats, got %+v", result)
+	}
+
+	// 400 ft² at one coat and 10 m²/l
+	feet, err := ec.Calculate(types.EstimatorRequest{Operation: "paint", Unit: "ft", Area: floatPtr(400), Coats: 1})
+	if err != nil {
+		t.Fatalf("paint in feet failed: %v", err)
+	}
+	if math.Abs(feet.AreaFt2-400) > 1e-9 || math.Abs(*feet.Litres-3.716) > 1e-3 {
+		t.Errorf("Expected 400 ft² to need about 3.716 litres, got %+v", feet)
+	}
+}
+
+func TestEstimatorCalculator_ConcreteAndTiles(t *testing.T) {
+	ec := calculator.NewEstimatorCalculator()
+
+	// A 10 ft × 10 ft slab, 6 in thick, is 50 ft³ before waste
+	concrete, err := ec.Calculate(types.EstimatorRequest{Operation: "concrete", Unit: "ft", Length: floatPtr(10), Width: floatPtr(10), Thickness: floatPtr(0.5)})
+	if err != nil {
+		t.Fatalf("concrete failed: %v", err)
+	}
+	if *concrete.WastePercent != 5 || math.Abs(*concrete.VolumeFt3-52.5) > 1e-9 || math.Abs(*concrete.VolumeYd3-52.5/27) > 1e-9 {
+		t.Errorf("Expected 52.5 ft³ with 5%% waste, got %+v", concrete)
+	}
+
+	tiles, err := ec.Calculate(types.EstimatorRequest{
+		Operation:   "tiles",
+		Area:        floatPtr(6),
+		TileLength:  floatPtr(30),
+		TileWidth:   floatPtr(30),
+		TileUnit:    "cm",
+		TilesPerBox: 10,
+	})
+	if err != nil {
+		t.Fatalf("tiles failed: %v", err)
+	}
+	if *tiles.Tiles != 74 || *tiles.Boxes != 8 || *tiles.WastePercent != 10 {
+		t.Errorf("Expected 74 tiles in 8 boxes, got %+v", tiles)
+	}
+
+	// An exact fit is not rounded up by float noise
+	exact, err := ec.Calculate(types.EstimatorRequest{Operation: "tiles", Length: floatPtr(0.9), Width: floatPtr(0.3), TileLength: floatPtr(0.3), TileWidth: floatPtr(0.3), WastePercent: floatPtr(0)})
+	if err != nil || *exact.Tiles != 3 {
+		t.Errorf("Expected 3 tiles, got %+v (%v)", exact, err)
+	}
+}
+
+func TestEstimatorCalculator_Wallpaper(t *testing.T) {
+	ec := calculator.NewEstimatorCalculator()
+
+	result, err := ec.Calculate(types.EstimatorRequest{Operation: "wallpaper", Length: floatPtr(4), Width: floatPtr(3), Height: floatPtr(2.4)})
+	if err != nil {
+		t.Fatalf("wallpaper failed: %v", err)
+	}
+	if *result.Strips != 27 || *result.StripsPerRoll != 4 || *result.Rolls != 7 {
+		t.Errorf("Expected 27 strips from 7 rolls, got %+v", result)
+	}
+
+	// A 64 cm pattern repeat leaves room for only three strips per roll
+	repeat, err := ec.Calculate(types.EstimatorRequest{Operation: "wallpaper", Length: floatPtr(4), Width: floatPtr(3), Height: floatPtr(2.4), PatternRepeat: 0.64})
+	if err != nil || *repeat.StripsPerRoll != 3 || *repeat.Rolls != 9 {
+		t.Errorf("Expected 9 rolls with the pattern repeat, got %+v (%v)", repeat, err)
+	}
+}
+
+func TestEstimatorCalculator_Invalid(t *testing.T) {
+	ec := calculator.NewEstimatorCalculator()
+
+	invalid := []types.EstimatorRequest{
+		{Operation: "paint"},
+		{Operation: "paint", Area: floatPtr(10), Length: floatPtr(2)},
+		{Operation: "paint", Area: floatPtr(10), Openings: 10},
+		{Operation: "paint", Area: floatPtr(10), Coverage: floatPtr(0)},
+		{Operation: "paint", Area: floatPtr(10), Unit: "furlong"},
+		{Operation: "concrete", Area: floatPtr(10)},
+		{Operation: "concrete", Area: floatPtr(10), Thickness: floatPtr(0.1), WastePercent: floatPtr(150)},
+		{Operation: "tiles", Area: floatPtr(10), TileLength: floatPtr(30)},
+		{Operation: "tiles", Area: floatPtr(1e6), TileLength: floatPtr(1), TileWidth: floatPtr(1), TileUnit: "nm"},
+		{Operation: "wallpaper", Length: floatPtr(4)},
+		{Operation: "wallpaper", Length: floatPtr(4), Height: floatPtr(12)},
+		{Operation: "fence"},
+	}
+	for _, req := range invalid {
+		if _, err := ec.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
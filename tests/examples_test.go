@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func newExamplesServer() *mcp.Server {
+	server := mcp.NewServer()
+	server.RegisterTool("double", "Doubles a number", map[string]interface{}{"type": "object"}, func(params map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"result": params["value"].(float64) * 2}, nil
+	},
+		mcp.WithExample("Double a whole number", map[string]interface{}{"value": 2.0}, map[string]interface{}{"result": 4}),
+		mcp.WithExample("", map[string]interface{}{"value": -1.5}, map[string]interface{}{"result": -3}),
+	)
+	server.RegisterTool("noop", "Does nothing", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	return server
+}
+
+func TestToolExamples_ToolsList(t *testing.T) {
+	response := newExamplesServer().HandleRequestContext(context.Background(), types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	encoded, _ := json.Marshal(response.Result)
+	var list struct {
+		Tools []struct {
+			Name string          `json:"name"`
+			Meta *types.ToolMeta `json:"_meta"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(encoded, &list); err != nil {
+		t.Fatalf("Failed to decode tools/list: %v", err)
+	}
+
+	for _, tool := range list.Tools {
+		switch tool.Name {
+		case "double":
+			if tool.Meta == nil || len(tool.Meta.Examples) != 2 || tool.Meta.Examples[0].Description != "Double a whole number" || tool.Meta.Examples[1].Arguments["value"] != -1.5 {
+				t.Errorf("Expected both examples in order, got %+v", tool.Meta)
+			}
+		case "noop":
+			if tool.Meta != nil {
+				t.Errorf("Expected no _meta for a tool without examples, got %+v", tool.Meta)
+			}
+		}
+	}
+}
+
+func TestStreamableHTTP_ToolExamples(t *testing.T) {
+	httpTransport := mcp.NewStreamableHTTPTransport(newExamplesServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8110,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	get := func(query string) (int, map[string][]types.ToolExample) {
+		resp, err := http.Get("http://127.0.0.1:8110/tools/examples" + query)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Tools map[string][]types.ToolExample `json:"tools"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.Tools
+	}
+
+	if status, tools := get(""); status != http.StatusOK || len(tools) != 1 || len(tools["double"]) != 2 {
+		t.Errorf("Expected the examples of double only, got %d %v", status, tools)
+	}
+	if status, tools := get("?tool=noop"); status != http.StatusOK || tools["noop"] == nil || len(tools["noop"]) != 0 {
+		t.Errorf("Expected an empty list for noop, got %d %v", status, tools)
+	}
+	if status, _ := get("?tool=missing"); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown tool, got %d", status)
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+)
+
+func TestBasicCalculator_ExplainSteps(t *testing.T) {
+	calc := calculator.NewBasicCalculator()
+
+	result, err := calc.Calculate(types.BasicMathRequest{
+		Operation: "divide",
+		Operands:  []float64{10, 4, 3},
+		Precision: 2,
+		Explain:   true,
+	})
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	expected := []string{
+		"10 ÷ 4 = 2.5",
+		"2.5 ÷ 3 = 0.8333333333",
+		"Round to 2 decimal places: 0.83",
+	}
+	if !reflect.DeepEqual(result.Steps, expected) {
+		t.Errorf("Expected steps %q, got %q", expected, result.Steps)
+	}
+
+	plain, err := calc.Calculate(types.BasicMathRequest{Operation: "add", Operands: []float64{1, 2}})
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if plain.Steps != nil {
+		t.Errorf("Expected no steps unless requested, got %q", plain.Steps)
+	}
+}
+
+func TestExpressionCalculator_ExplainSubstitution(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	result, err := calc.Evaluate(types.ExpressionRequest{
+		Expression: "pow(x, 2) + xy",
+		Variables:  map[string]float64{"x": 3, "xy": 1},
+		Explain:    true,
+	})
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+
+	expected := []string{
+		"Evaluate pow(x, 2) + xy",
+		"Substitute x = 3, xy = 1: pow(3, 2) + 1",
+		"Result: 10",
+	}
+	if !reflect.DeepEqual(result.Steps, expected) {
+		t.Errorf("Expected steps %q, got %q", expected, result.Steps)
+	}
+}
+
+func TestFinanceHandler_ExplainSteps(t *testing.T) {
+	handler := handlers.NewFinanceHandler()
+
+	response, err := handler.HandleFinancialCalculation(map[string]interface{}{
+		"operation": "simple_interest",
+		"principal": 1000.0,
+		"rate":      5.0,
+		"time":      2.0,
+		"explain":   true,
+	})
+	if err != nil {
+		t.Fatalf("HandleFinancialCalculation failed: %v", err)
+	}
+
+	steps, ok := response.(map[string]interface{})["steps"].([]string)
+	if !ok || len(steps) != 3 {
+		t.Fatalf("Expected 3 steps, got %#v", response.(map[string]interface{})["steps"])
+	}
+	if steps[1] != "I = 1000 × 0.05 × 2 = 100" {
+		t.Errorf("Unexpected substitution step: %q", steps[1])
+	}
+
+	for _, operation := range []string{"compound_interest", "loan_payment", "roi", "present_value", "future_value"} {
+		response, err := handler.HandleFinancialCalculation(map[string]interface{}{
+			"operation":   operation,
+			"principal":   1000.0,
+			"rate":        6.0,
+			"time":        3.0,
+			"futureValue": 1500.0,
+			"explain":     true,
+		})
+		if err != nil {
+			t.Fatalf("%s failed: %v", operation, err)
+		}
+		steps, _ := response.(map[string]interface{})["steps"].([]string)
+		if len(steps) == 0 || !strings.HasPrefix(steps[0], "Formula: ") {
+			t.Errorf("Expected %s to start with its formula, got %q", operation, steps)
+		}
+	}
+}
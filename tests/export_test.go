@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStatisticsCalculator_Histogram(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+
+	bins, err := calc.Histogram([]float64{1, 2, 2, 3, 4, 5}, 2)
+	if err != nil {
+		t.Fatalf("Histogram failed: %v", err)
+	}
+	if len(bins) != 2 {
+		t.Fatalf("Expected 2 bins, got %d", len(bins))
+	}
+	if bins[0].Lower != 1 || bins[0].Upper != 3 || bins[0].Count != 3 {
+		t.Errorf("Unexpected first bin: %+v", bins[0])
+	}
+	if bins[1].Lower != 3 || bins[1].Upper != 5 || bins[1].Count != 3 {
+		t.Errorf("Unexpected last bin (should include the maximum): %+v", bins[1])
+	}
+
+	if _, err := calc.Histogram([]float64{1, 2}, 0); err == nil {
+		t.Error("Expected error for zero bins")
+	}
+}
+
+func TestFinancialCalculator_AmortizationSchedule(t *testing.T) {
+	calc := calculator.NewFinancialCalculator()
+
+	schedule, err := calc.AmortizationSchedule(types.FinancialRequest{
+		Principal: 1000,
+		Rate:      12,
+		Time:      1,
+		Periods:   12,
+	})
+	if err != nil {
+		t.Fatalf("AmortizationSchedule failed: %v", err)
+	}
+	if len(schedule) != 12 {
+		t.Fatalf("Expected 12 payments, got %d", len(schedule))
+	}
+	if schedule[0].Interest != 10 {
+		t.Errorf("Expected first interest of 10.00, got %f", schedule[0].Interest)
+	}
+	if last := schedule[len(schedule)-1]; last.Balance != 0 {
+		t.Errorf("Expected the loan to be repaid, final balance %f", last.Balance)
+	}
+}
+
+func TestFinancialCalculator_AmortizationScheduleWithoutPayments(t *testing.T) {
+	// 0.01 years of monthly payments rounds to none
+	req := types.FinancialRequest{Principal: 1000, Rate: 12, Time: 0.01, Periods: 12}
+	if _, err := calculator.NewFinancialCalculator().AmortizationSchedule(req); err == nil {
+		t.Error("Expected a term shorter than one payment to be rejected")
+	}
+
+	_, err := handlers.NewFinanceHandler().HandleAmortizationSchedule(context.Background(), map[string]interface{}{
+		"principal": 1000.0,
+		"rate":      12.0,
+		"time":      0.01,
+	})
+	if err == nil || !strings.Contains(err.Error(), "at least one payment") {
+		t.Errorf("Expected the handler to report the short term, got %v", err)
+	}
+}
+
+func TestExportTables_SizeLimits(t *testing.T) {
+	// A century of daily payments is too many rows
+	req := types.FinancialRequest{Principal: 1000, Rate: 5, Time: 100, Periods: 365}
+	if _, err := calculator.NewFinancialCalculator().AmortizationSchedule(req); err == nil || !strings.Contains(err.Error(), "too long") {
+		t.Errorf("Expected an overlong schedule to be rejected, got %v", err)
+	}
+	req = types.FinancialRequest{Principal: 1000, Rate: 5, Time: 50, Periods: 52}
+	if schedule, err := calculator.NewFinancialCalculator().AmortizationSchedule(req); err != nil || len(schedule) != calculator.MaxAmortizationPayments {
+		t.Errorf("Expected the longest schedule to be listed, got %d rows, %v", len(schedule), err)
+	}
+
+	stats := calculator.NewStatisticsCalculator()
+	if _, err := stats.Histogram([]float64{1, 2, 3}, calculator.MaxHistogramBins+1); err == nil {
+		t.Error("Expected too many bins to be rejected")
+	}
+	if histogram, err := stats.Histogram([]float64{1, 2, 3}, calculator.MaxHistogramBins); err != nil || len(histogram) != calculator.MaxHistogramBins {
+		t.Errorf("Expected %d bins, got %d, %v", calculator.MaxHistogramBins, len(histogram), err)
+	}
+}
+
+func TestExportHandler_EmbedsResource(t *testing.T) {
+	handler := handlers.NewExportHandler(nil)
+
+	result, err := handler.HandleExport(context.Background(), map[string]interface{}{
+		"source": "histogram",
+		"data":   []interface{}{1.0, 2.0, 3.0, 4.0},
+		"bins":   2.0,
+	})
+	if err != nil {
+		t.Fatalf("HandleExport failed: %v", err)
+	}
+
+	toolResult, ok := result.(types.CallToolResult)
+	if !ok || len(toolResult.Content) != 2 {
+		t.Fatalf("Expected a tool result with 2 content blocks, got %#v", result)
+	}
+	resource := toolResult.Content[1].Resource
+	if resource == nil || resource.MimeType != "text/csv" || resource.URI != "export://histogram.csv" {
+		t.Fatalf("Unexpected resource block: %+v", resource)
+	}
+	if !strings.HasPrefix(resource.Text, "lower,upper,count\n1,2.5,2\n") {
+		t.Errorf("Unexpected CSV document:\n%s", resource.Text)
+	}
+
+	if _, err := handler.HandleExport(context.Background(), map[string]interface{}{"source": "history"}); err == nil {
+		t.Error("Expected error exporting history when it is not recorded")
+	}
+}
+
+func TestServer_ExportsSessionHistory(t *testing.T) {
+	server := mcp.NewServer()
+	server.SetStore(storage.NewMemoryStore(), time.Hour)
+	mathHandler := handlers.NewMathHandler()
+	exportHandler := handlers.NewExportHandler(func(ctx context.Context) ([]types.HistoryEntry, error) {
+		return server.History(mcp.SessionIDFromContext(ctx))
+	})
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+	server.RegisterContextTool("export", "Export documents", map[string]interface{}{"type": "object"}, exportHandler.HandleExport)
+
+	ctx := mcp.WithSessionID(context.Background(), "session-1")
+	calls := []string{
+		`{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}`,
+		`{"name":"export","arguments":{"source":"history","format":"json"}}`,
+	}
+	var response types.MCPResponse
+	for i, params := range calls {
+		response = server.HandleRequestContext(ctx, types.MCPRequest{
+			JSONRPC: "2.0",
+			ID:      i,
+			Method:  "tools/call",
+			Params:  json.RawMessage(params),
+		})
+	}
+	if response.Error != nil {
+		t.Fatalf("Export failed: %v", response.Error)
+	}
+
+	toolResult := response.Result.(types.CallToolResult)
+	var records []map[string]interface{}
+	if err := json.Unmarshal([]byte(toolResult.Content[1].Resource.Text), &records); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+	if len(records) != 1 || records[0]["tool"] != "basic_math" {
+		t.Errorf("Expected exactly the basic_math call in the export, got %v", records)
+	}
+}
@@ -1,10 +1,13 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"math"
 	"testing"
 
 	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
 	"calculator-server/internal/types"
 )
 
@@ -1185,3 +1188,98 @@ func TestExpressionCalculator_ComplexBusinessLogic(t *testing.T) {
 		})
 	}
 }
+
+func TestExpressionCalculator_DomainErrorCodes(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	testCases := []struct {
+		expression   string
+		expectedCode string
+	}{
+		{"sqrt(-4)", types.ErrCodeSqrtDomain},
+		{"ln(0)", types.ErrCodeLogDomain},
+		{"asin(2)", types.ErrCodeInverseTrigDomain},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := calc.Evaluate(types.ExpressionRequest{Expression: tc.expression})
+			var calcErr *types.CalculationError
+			if !errors.As(err, &calcErr) {
+				t.Fatalf("Expected CalculationError, got %T: %v", err, err)
+			}
+			if calcErr.Code != tc.expectedCode {
+				t.Errorf("Expected code %s, got %s", tc.expectedCode, calcErr.Code)
+			}
+		})
+	}
+}
+
+func TestExpressionCalculator_EvaluateArray(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	results, err := calc.EvaluateArray(types.ExpressionRequest{
+		Expression:     "a*x + y + b",
+		Variables:      map[string]float64{"a": 2, "b": 1},
+		ArrayVariables: map[string][]float64{"x": {1, 2, 3}, "y": {10, 20, 30}},
+	})
+	if err != nil {
+		t.Fatalf("EvaluateArray failed: %v", err)
+	}
+	expected := []float64{13, 25, 37}
+	for i, value := range expected {
+		if results[i] != value {
+			t.Errorf("Expected %v, got %v", expected, results)
+			break
+		}
+	}
+
+	// Domain errors keep their code and name the failing element
+	_, err = calc.EvaluateArray(types.ExpressionRequest{Expression: "sqrt(x)", ArrayVariables: map[string][]float64{"x": {4, -1}}})
+	var calcErr *types.CalculationError
+	if !errors.As(err, &calcErr) || calcErr.Code != types.ErrCodeSqrtDomain {
+		t.Errorf("Expected a square root domain error, got %v", err)
+	}
+
+	invalid := []types.ExpressionRequest{
+		{Expression: "x + y", ArrayVariables: map[string][]float64{"x": {1, 2}, "y": {1}}},
+		{Expression: "x", ArrayVariables: map[string][]float64{"x": {}}},
+		{Expression: "x", ArrayVariables: map[string][]float64{"x": make([]float64, calculator.MaxArrayLength+1)}},
+		{Expression: "x", Variables: map[string]float64{"x": 1}, ArrayVariables: map[string][]float64{"x": {1}}},
+		{Expression: "x", ArrayVariables: map[string][]float64{"x": {math.NaN()}}},
+		{Expression: "x", ArrayVariables: map[string][]float64{"x": {1}}, Explain: true},
+		{Expression: "x"},
+	}
+	for _, req := range invalid {
+		if _, err := calc.EvaluateArray(req); err == nil {
+			t.Errorf("Expected %q with %v to be rejected", req.Expression, req.ArrayVariables)
+		}
+	}
+}
+
+func TestMathHandler_ExpressionEvalWithArrayVariables(t *testing.T) {
+	mh := handlers.NewMathHandler()
+
+	result, err := mh.HandleExpressionEval(context.Background(), map[string]interface{}{
+		"expression": "2*x + b",
+		"variables":  map[string]interface{}{"x": []interface{}{1.0, 2.0, 3.0}, "b": 1.0},
+	})
+	if err != nil {
+		t.Fatalf("HandleExpressionEval failed: %v", err)
+	}
+	response := result.(map[string]interface{})
+	results := response["results"].([]float64)
+	if response["count"] != 3 || results[0] != 3 || results[2] != 7 {
+		t.Errorf("Expected results [3 5 7], got %v", response)
+	}
+
+	// Scalar variables keep the single-result shape
+	scalar, err := mh.HandleExpressionEval(context.Background(), map[string]interface{}{"expression": "x + 1", "variables": map[string]interface{}{"x": 2.0}})
+	if err != nil || scalar.(map[string]interface{})["result"] != 3.0 {
+		t.Errorf("Expected result 3, got %v (%v)", scalar, err)
+	}
+
+	if _, err := mh.HandleExpressionEval(context.Background(), map[string]interface{}{"expression": "x", "variables": map[string]interface{}{"x": []interface{}{"one"}}}); err == nil {
+		t.Error("Expected an array of non-numbers to be rejected")
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+func TestBasicMathRequest_DecodeFast(t *testing.T) {
+	tests := []struct {
+		arguments string
+		fast      bool
+	}{
+		{`{"operation":"add","operands":[2,3]}`, true},
+		{` { "operation" : "multiply" , "operands" : [ -1.5e2 , 0.25, 4, 0 ] , "precision" : 3 } `, true},
+		{`{"operation":"add","operands":[1,2],"format":"engineering","symbol":"F","explain":true}`, true},
+		{`{"operands":[],"explain":false}`, true},
+		{`{}`, true},
+		{`{"operation":"add","operands":[1,2,3,4,5]}`, false},
+		{`{"operation":"add","operands":[1,1e400]}`, false},
+		{`{"operation":"add","operands":[1,2],"precision":2.0}`, false},
+		{`{"operation":"a\u0064d","operands":[1,2]}`, false},
+		{`{"operation":"add","operands":null}`, false},
+		{`{"operation":"add","operation":"subtract","operands":[1,2]}`, false},
+		{`{"Operation":"add","operands":[1,2]}`, false},
+		{`{"operation":"add","operands":[1,2],"precison":2}`, false},
+		{`{"operation":"add","operands":[01,2]}`, false},
+		{`{"operation":"add","operands":[1,2]} x`, false},
+		{`{"operation":"add","operands":[1,2],}`, false},
+		{`{"operation":"add","operands":"2,3"}`, false},
+	}
+
+	for _, tt := range tests {
+		var fast types.BasicMathRequest
+		if got := fast.DecodeFast([]byte(tt.arguments)); got != tt.fast {
+			t.Errorf("DecodeFast(%s) = %v, expected %v", tt.arguments, got, tt.fast)
+			continue
+		}
+		if !tt.fast {
+			continue
+		}
+		var generic types.BasicMathRequest
+		if err := json.Unmarshal([]byte(tt.arguments), &generic); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.arguments, err)
+		}
+		if !reflect.DeepEqual(fast, generic) {
+			t.Errorf("DecodeFast(%s) = %+v, Unmarshal gave %+v", tt.arguments, fast, generic)
+		}
+	}
+}
+
+// decimalResult computes an operation the way the generic path does
+func decimalResult(operation string, operands []float64) float64 {
+	result := decimal.NewFromFloat(operands[0])
+	for _, operand := range operands[1:] {
+		switch operation {
+		case "add":
+			result = result.Add(decimal.NewFromFloat(operand))
+		case "subtract":
+			result = result.Sub(decimal.NewFromFloat(operand))
+		case "multiply":
+			result = result.Mul(decimal.NewFromFloat(operand))
+		}
+	}
+	value, _ := result.Float64()
+	return value
+}
+
+func TestBasicCalculator_FastPathMatchesDecimal(t *testing.T) {
+	calc := calculator.NewBasicCalculator()
+	rng := rand.New(rand.NewSource(1))
+	operand := func() float64 {
+		switch rng.Intn(4) {
+		case 0:
+			return float64(rng.Intn(2001) - 1000)
+		case 1:
+			return float64(rng.Intn(1<<20)) / 16
+		case 2:
+			return float64(rng.Int63n(1<<40) - 1<<39)
+		default:
+			return math.Round(rng.NormFloat64()*1e4) / 100
+		}
+	}
+
+	for i := 0; i < 20000; i++ {
+		operation := []string{"add", "subtract", "multiply"}[rng.Intn(3)]
+		operands := make([]float64, 2+rng.Intn(3))
+		for j := range operands {
+			operands[j] = operand()
+		}
+		result, err := calc.Calculate(types.BasicMathRequest{Operation: operation, Operands: operands, Precision: 6})
+		if err != nil {
+			t.Fatalf("%s %v failed: %v", operation, operands, err)
+		}
+		expected := math.Round(decimalResult(operation, operands)*1e6) / 1e6
+		if math.Float64bits(result.Result) != math.Float64bits(expected) {
+			t.Fatalf("%s %v = %v, expected %v", operation, operands, result.Result, expected)
+		}
+	}
+
+	// Negative zero, which decimals cannot hold, is not produced either
+	result, _ := calc.Calculate(types.BasicMathRequest{Operation: "multiply", Operands: []float64{0, -5}})
+	if math.Signbit(result.Result) {
+		t.Errorf("Expected 0, got %v", result.Result)
+	}
+}
+
+var basicMathArguments = []byte(`{"operation":"add","operands":[1.5,2.25,3.125],"precision":2}`)
+
+func BenchmarkBasicMathRequest_DecodeFast(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var req types.BasicMathRequest
+		if !req.DecodeFast(basicMathArguments) {
+			b.Fatal("DecodeFast failed")
+		}
+	}
+}
+
+func BenchmarkBasicMathRequest_Unmarshal(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var req types.BasicMathRequest
+		if err := json.Unmarshal(basicMathArguments, &req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkBasicCalculator(b *testing.B, operands []float64) {
+	calc := calculator.NewBasicCalculator()
+	req := types.BasicMathRequest{Operation: "add", Operands: operands, Precision: 2}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := calc.Calculate(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Operands that are multiples of 1/16 take the fast path
+func BenchmarkBasicCalculator_FastPath(b *testing.B) {
+	benchmarkBasicCalculator(b, []float64{1.5, 2.25, 3.125})
+}
+
+// Other fractions are added as decimals
+func BenchmarkBasicCalculator_DecimalPath(b *testing.B) {
+	benchmarkBasicCalculator(b, []float64{1.1, 2.2, 3.3})
+}
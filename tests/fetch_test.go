@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calculator-server/internal/fetch"
+)
+
+func TestFetcher_CachesAllowlistedHosts(t *testing.T) {
+	var hits int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&hits, 1)
+		fmt.Fprintf(w, "response %d", n)
+	}))
+	defer upstream.Close()
+
+	fetcher := fetch.New(fetch.Config{
+		AllowedHosts: []string{"127.0.0.1"},
+		Timeout:      time.Second,
+		CacheTTL:     time.Minute,
+	})
+
+	for i := 0; i < 3; i++ {
+		body, err := fetcher.Get(context.Background(), upstream.URL+"/rates")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if string(body) != "response 1" {
+			t.Errorf("Expected the cached first response, got %q", body)
+		}
+	}
+
+	stats := fetcher.Stats()
+	if hits != 1 || stats.Requests != 1 || stats.CacheHits != 2 {
+		t.Errorf("Expected one upstream request and two cache hits, got %d hits and %+v", hits, stats)
+	}
+
+	if _, err := fetcher.Get(context.Background(), "http://example.com/rates"); !errors.Is(err, fetch.ErrHostNotAllowed) {
+		t.Errorf("Expected ErrHostNotAllowed, got %v", err)
+	}
+	if _, err := fetcher.Get(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Error("Expected error for a non-HTTP URL")
+	}
+}
+
+func TestFetcher_RateLimitAndFailures(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(strings.Repeat("x", 64)))
+	}))
+	defer upstream.Close()
+
+	fetcher := fetch.New(fetch.Config{
+		AllowedHosts:      []string{"127.0.0.1"},
+		RequestsPerMinute: 2,
+		MaxBodyBytes:      32,
+	})
+
+	if _, err := fetcher.Get(context.Background(), upstream.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+	if _, err := fetcher.Get(context.Background(), upstream.URL+"/large"); !errors.Is(err, fetch.ErrBodyTooLarge) {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	if _, err := fetcher.Get(context.Background(), upstream.URL+"/large"); !errors.Is(err, fetch.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited after two requests, got %v", err)
+	}
+
+	stats := fetcher.Stats()
+	if stats.Errors != 2 || stats.RateLimited != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestFetcher_RechecksRedirectsAndAddresses(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/away":
+			http.Redirect(w, r, "http://example.com/rates", http.StatusFound)
+		case "/local":
+			http.Redirect(w, r, "/rates", http.StatusFound)
+		default:
+			w.Write([]byte("rates"))
+		}
+	}))
+	defer upstream.Close()
+
+	fetcher := fetch.New(fetch.Config{AllowedHosts: []string{"127.0.0.1"}, Timeout: time.Second})
+	if body, err := fetcher.Get(context.Background(), upstream.URL+"/local"); err != nil || string(body) != "rates" {
+		t.Errorf("Expected a redirect within the allowlist to be followed, got %q, %v", body, err)
+	}
+	if _, err := fetcher.Get(context.Background(), upstream.URL+"/away"); !errors.Is(err, fetch.ErrHostNotAllowed) {
+		t.Errorf("Expected ErrHostNotAllowed for a redirect off the allowlist, got %v", err)
+	}
+
+	// An allowlisted name that resolves to a loopback address is refused
+	port := upstream.URL[strings.LastIndex(upstream.URL, ":")+1:]
+	byName := fetch.New(fetch.Config{AllowedHosts: []string{"localhost"}, Timeout: time.Second})
+	if _, err := byName.Get(context.Background(), "http://localhost:"+port+"/rates"); !errors.Is(err, fetch.ErrAddressNotAllowed) {
+		t.Errorf("Expected ErrAddressNotAllowed, got %v", err)
+	}
+	if stats := byName.Stats(); stats.Denied == 0 {
+		t.Errorf("Expected the refused address to be counted, got %+v", stats)
+	}
+}
@@ -0,0 +1,351 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/currency"
+	"calculator-server/internal/fetch"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+)
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestFinancialCalculator_SolveTVM(t *testing.T) {
+	calc := calculator.NewFinancialCalculator()
+
+	// A 200,000 loan at 6% over 30 years, paid monthly
+	loan := types.TVMRequest{Principal: floatPtr(200000), Rate: floatPtr(6), Time: floatPtr(30), FutureValue: floatPtr(0)}
+	solved, err := calc.SolveTVM(loan)
+	if err != nil {
+		t.Fatalf("SolveTVM failed: %v", err)
+	}
+	payment := solved.Result
+	if math.Abs(payment-(-1199.10)) > 0.01 || solved.Breakdown["solved_for"] != "payment" {
+		t.Fatalf("Expected a payment of -1199.10, got %+v", solved)
+	}
+
+	// Solving back for each other value recovers the original
+	tests := []struct {
+		name     string
+		req      types.TVMRequest
+		expected float64
+	}{
+		{"rate", types.TVMRequest{Principal: floatPtr(200000), Time: floatPtr(30), Payment: floatPtr(payment), FutureValue: floatPtr(0)}, 6},
+		{"time", types.TVMRequest{Principal: floatPtr(200000), Rate: floatPtr(6), Payment: floatPtr(payment), FutureValue: floatPtr(0)}, 30},
+		{"principal", types.TVMRequest{Rate: floatPtr(6), Time: floatPtr(30), Payment: floatPtr(payment), FutureValue: floatPtr(0)}, 200000},
+		{"futureValue", types.TVMRequest{Principal: floatPtr(-1000), Rate: floatPtr(0), Time: floatPtr(1), Payment: floatPtr(-100)}, 2200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.SolveTVM(tt.req)
+			if err != nil {
+				t.Fatalf("SolveTVM failed: %v", err)
+			}
+			if math.Abs(result.Result-tt.expected) > 1e-6*math.Max(1, math.Abs(tt.expected)) {
+				t.Errorf("Expected %s = %v, got %v", tt.name, tt.expected, result.Result)
+			}
+		})
+	}
+
+	if _, err := calc.SolveTVM(types.TVMRequest{Principal: floatPtr(100), Rate: floatPtr(5)}); err == nil {
+		t.Error("Expected an error with two unknowns")
+	}
+	if _, err := calc.SolveTVM(types.TVMRequest{Principal: floatPtr(100), Time: floatPtr(1), Payment: floatPtr(10), FutureValue: floatPtr(10)}); err == nil {
+		t.Error("Expected an error when no rate balances same-signed cash flows")
+	}
+}
+
+func TestFinanceHandler_SolveTVMDetectsOmittedValue(t *testing.T) {
+	handler := handlers.NewFinanceHandler()
+
+	// Zero is a given value, not an omitted one
+	result, err := handler.HandleFinancialCalculation(map[string]interface{}{
+		"operation":   "solve_tvm",
+		"principal":   -10000.0,
+		"rate":        5.0,
+		"periods":     1.0,
+		"futureValue": 16288.95,
+		"payment":     0.0,
+	})
+	if err != nil {
+		t.Fatalf("solve_tvm failed: %v", err)
+	}
+	response := result.(map[string]interface{})
+	if math.Abs(response["result"].(float64)-10) > 1e-4 || response["breakdown"].(map[string]interface{})["solved_for"] != "time" {
+		t.Errorf("Expected time = 10 years, got %+v", response)
+	}
+}
+
+func TestFinancialCalculator_DayCountConventions(t *testing.T) {
+	calc := calculator.NewFinancialCalculator()
+
+	tests := []struct {
+		dayCount string
+		interest float64
+		days     int
+	}{
+		{"actual/360", 10000 * 0.05 * 182 / 360, 182},
+		{"actual/365", 10000 * 0.05 * 182 / 365, 182},
+		{"30/360", 250, 180},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dayCount, func(t *testing.T) {
+			result, err := calc.Calculate(types.FinancialRequest{
+				Operation: "simple_interest",
+				Principal: 10000,
+				Rate:      5,
+				StartDate: "2024-01-01",
+				EndDate:   "2024-07-01",
+				DayCount:  tt.dayCount,
+			})
+			if err != nil {
+				t.Fatalf("Calculate failed: %v", err)
+			}
+			if math.Abs(result.Result-tt.interest) > 1e-9 || result.Breakdown["accrual_days"] != tt.days {
+				t.Errorf("Expected %v interest over %d days, got %v over %v", tt.interest, tt.days, result.Result, result.Breakdown["accrual_days"])
+			}
+		})
+	}
+
+	// 30/360 treats the 31st as the 30th
+	if days, _, _ := calculator.YearFraction(mustDate(t, "2024-01-31"), mustDate(t, "2024-03-31"), calculator.DayCount30360); days != 60 {
+		t.Errorf("Expected 60 days under 30/360, got %d", days)
+	}
+
+	invalid := []types.FinancialRequest{
+		{Operation: "simple_interest", Principal: 100, Rate: 5, Time: 1, StartDate: "2024-01-01", EndDate: "2024-02-01"},
+		{Operation: "simple_interest", Principal: 100, Rate: 5, StartDate: "2024-02-01", EndDate: "2024-01-01"},
+		{Operation: "simple_interest", Principal: 100, Rate: 5, StartDate: "2024-01-01"},
+		{Operation: "simple_interest", Principal: 100, Rate: 5, StartDate: "01/01/2024", EndDate: "2024-02-01"},
+		{Operation: "simple_interest", Principal: 100, Rate: 5, Time: 1, DayCount: "30/360"},
+		{Operation: "roi", Principal: 100, FutureValue: 110, StartDate: "2024-01-01", EndDate: "2024-02-01"},
+	}
+	for i, req := range invalid {
+		if _, err := calc.Calculate(req); err == nil {
+			t.Errorf("Request %d: expected an error", i)
+		}
+	}
+}
+
+func TestFinancialCalculator_RulesOfThumb(t *testing.T) {
+	calc := calculator.NewFinancialCalculator()
+
+	doubling, err := calc.RuleOfThumb(types.RuleOfThumbRequest{Operation: "rule_of_72", Rate: 8})
+	if err != nil {
+		t.Fatalf("rule_of_72 failed: %v", err)
+	}
+	if doubling.Result != 9 || math.Abs(doubling.Breakdown["exact_years"].(float64)-9.006) > 0.001 {
+		t.Errorf("Expected 9 years (exactly 9.006), got %+v", doubling)
+	}
+
+	budget, err := calc.RuleOfThumb(types.RuleOfThumbRequest{Operation: "budget_50_30_20", Income: 4000, Expenses: 2200})
+	if err != nil {
+		t.Fatalf("budget_50_30_20 failed: %v", err)
+	}
+	if budget.Result != 800 || budget.Breakdown["needs"] != 2000.0 || budget.Breakdown["wants"] != 1200.0 || budget.Breakdown["within_needs"] != false {
+		t.Errorf("Unexpected budget: %+v", budget)
+	}
+
+	tests := []struct {
+		payments   float64
+		assessment string
+	}{
+		{1000, "healthy"},
+		{1600, "manageable"},
+		{2000, "high"},
+	}
+	for _, tt := range tests {
+		ratio, err := calc.RuleOfThumb(types.RuleOfThumbRequest{Operation: "debt_to_income", Income: 4000, DebtPayments: tt.payments})
+		if err != nil {
+			t.Fatalf("debt_to_income failed: %v", err)
+		}
+		if ratio.Result != tt.payments/40 || ratio.Breakdown["assessment"] != tt.assessment {
+			t.Errorf("Expected %v%% to be %s, got %+v", tt.payments/40, tt.assessment, ratio)
+		}
+	}
+
+	fund, err := calc.RuleOfThumb(types.RuleOfThumbRequest{Operation: "emergency_fund", Expenses: 2500, Savings: 5000})
+	if err != nil {
+		t.Fatalf("emergency_fund failed: %v", err)
+	}
+	if fund.Result != 15000 || fund.Breakdown["shortfall"] != 10000.0 || fund.Breakdown["months_covered"] != 2.0 {
+		t.Errorf("Unexpected emergency fund: %+v", fund)
+	}
+
+	invalid := []types.RuleOfThumbRequest{
+		{Operation: "rule_of_72"},
+		{Operation: "debt_to_income", DebtPayments: 100},
+		{Operation: "emergency_fund", Expenses: -1},
+		{Operation: "rule_of_100"},
+	}
+	for _, req := range invalid {
+		if _, err := calc.RuleOfThumb(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestFinancialCalculator_Allocate(t *testing.T) {
+	calc := calculator.NewFinancialCalculator()
+
+	tests := []struct {
+		name     string
+		req      types.AllocationRequest
+		expected []float64
+	}{
+		{"three ways", types.AllocationRequest{Total: 100, Shares: []types.AllocationShare{{Weight: floatPtr(1)}, {Weight: floatPtr(1)}, {Weight: floatPtr(1)}}}, []float64{33.34, 33.33, 33.33}},
+		{"largest remainder", types.AllocationRequest{Total: 10, Shares: []types.AllocationShare{{Percentage: floatPtr(12.345)}, {Percentage: floatPtr(37.655)}, {Percentage: floatPtr(50)}}}, []float64{1.23, 3.77, 5}},
+		{"negative total", types.AllocationRequest{Total: -0.05, Shares: []types.AllocationShare{{Weight: floatPtr(1)}, {Weight: floatPtr(1)}}}, []float64{-0.03, -0.02}},
+		{"whole units", types.AllocationRequest{Total: 1000, Currency: "jpy", Shares: []types.AllocationShare{{Weight: floatPtr(2)}, {Weight: floatPtr(1)}}}, []float64{667, 333}},
+		{"decimals", types.AllocationRequest{Total: 1, Decimals: intPtr(3), Shares: []types.AllocationShare{{Weight: floatPtr(1)}, {Weight: floatPtr(0)}, {Weight: floatPtr(2)}}}, []float64{0.333, 0, 0.667}},
+	}
+	for _, tt := range tests {
+		result, err := calc.Allocate(tt.req)
+		if err != nil {
+			t.Fatalf("%s: allocate failed: %v", tt.name, err)
+		}
+		sum := 0.0
+		for i, part := range result.Parts {
+			if math.Abs(part.Amount-tt.expected[i]) > 1e-12 {
+				t.Errorf("%s: expected part %d to be %v, got %v", tt.name, i, tt.expected[i], part.Amount)
+			}
+			sum += part.Amount
+		}
+		if math.Abs(sum-tt.req.Total) > 1e-9 {
+			t.Errorf("%s: expected the parts to sum to %v, got %v", tt.name, tt.req.Total, sum)
+		}
+	}
+
+	yen, _ := calc.Allocate(types.AllocationRequest{Total: 1000, Currency: "JPY", Shares: []types.AllocationShare{{Weight: floatPtr(1)}}})
+	if yen.Unit != 1 || yen.Parts[0].Formatted != "¥1,000" {
+		t.Errorf("Expected whole-yen units and formatting, got %+v", yen)
+	}
+
+	invalid := []types.AllocationRequest{
+		{Total: 100},
+		{Total: 10.005, Shares: []types.AllocationShare{{Weight: floatPtr(1)}}},
+		{Total: 100, Shares: []types.AllocationShare{{Percentage: floatPtr(50)}, {Percentage: floatPtr(40)}}},
+		{Total: 100, Shares: []types.AllocationShare{{Percentage: floatPtr(50)}, {Weight: floatPtr(50)}}},
+		{Total: 100, Shares: []types.AllocationShare{{Weight: floatPtr(0)}, {Weight: floatPtr(0)}}},
+		{Total: 100, Shares: []types.AllocationShare{{Weight: floatPtr(-1)}, {Weight: floatPtr(2)}}},
+		{Total: 100, Currency: "USD", Decimals: intPtr(2), Shares: []types.AllocationShare{{Weight: floatPtr(1)}}},
+		{Total: 1e15, Shares: []types.AllocationShare{{Weight: floatPtr(1)}}},
+	}
+	for _, req := range invalid {
+		if _, err := calc.Allocate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
+
+func mustDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return date
+}
+
+func TestCurrency_Format(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		code     string
+		expected string
+	}{
+		{1234.567, "USD", "$1,234.57"},
+		{-1199.1, "EUR", "-€1,199.10"},
+		{162889.46, "JPY", "¥162,889"},
+		{999.999, "GBP", "£1,000.00"},
+		{-0.001, "USD", "$0.00"},
+		{1234567, "XYZ", "XYZ 1,234,567.00"},
+	}
+	for _, tt := range tests {
+		if formatted := currency.Format(tt.amount, tt.code); formatted != tt.expected {
+			t.Errorf("Format(%v, %s) = %q, expected %q", tt.amount, tt.code, formatted, tt.expected)
+		}
+	}
+
+	if code, err := currency.Normalize(" usd "); err != nil || code != "USD" {
+		t.Errorf("Expected usd to normalize to USD, got %q, %v", code, err)
+	}
+	for _, code := range []string{"US", "US1", "DOLLAR"} {
+		if _, err := currency.Normalize(code); err == nil {
+			t.Errorf("Expected %q to be rejected", code)
+		}
+	}
+}
+
+func TestFinanceHandler_CurrencyConversion(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("from") != "USD" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"base":"USD","rates":{"EUR":0.9,"JPY":150}}`))
+	}))
+	defer upstream.Close()
+
+	handler := handlers.NewFinanceHandler()
+	params := func() map[string]interface{} {
+		return map[string]interface{}{
+			"operation": "simple_interest", "principal": 10000.0, "rate": 5.0, "time": 1.0,
+			"currency": "usd", "reportingCurrency": "EUR",
+		}
+	}
+
+	if _, err := handler.HandleFinancialCalculation(params()); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Expected conversion to need a rate provider, got %v", err)
+	}
+
+	fetcher := fetch.New(fetch.Config{AllowedHosts: []string{"127.0.0.1"}, CacheTTL: time.Minute})
+	handler.SetRateProvider(currency.NewHTTPRateProvider(fetcher, upstream.URL+"/latest?from={base}"))
+
+	result, err := handler.HandleFinancialCalculation(params())
+	if err != nil {
+		t.Fatalf("HandleFinancialCalculation failed: %v", err)
+	}
+	response := result.(map[string]interface{})
+	if response["currency"] != "USD" || response["formatted"] != "$500.00" {
+		t.Errorf("Expected a USD-tagged $500.00, got %v and %v", response["currency"], response["formatted"])
+	}
+	converted := response["converted"].(map[string]interface{})
+	if converted["currency"] != "EUR" || math.Abs(converted["amount"].(float64)-450) > 1e-9 || converted["formatted"] != "€450.00" {
+		t.Errorf("Unexpected conversion: %+v", converted)
+	}
+
+	// ROI is a percentage, so it is tagged but not converted
+	roi := map[string]interface{}{"operation": "roi", "principal": 100.0, "futureValue": 150.0, "currency": "USD"}
+	if result, err := handler.HandleFinancialCalculation(roi); err != nil || result.(map[string]interface{})["formatted"] != nil {
+		t.Errorf("Expected an unformatted ROI, got %+v, %v", result, err)
+	}
+	roi["reportingCurrency"] = "EUR"
+	if _, err := handler.HandleFinancialCalculation(roi); err == nil {
+		t.Error("Expected converting an ROI percentage to fail")
+	}
+
+	missing := params()
+	missing["reportingCurrency"] = "GBP"
+	if _, err := handler.HandleFinancialCalculation(missing); err == nil || !strings.Contains(err.Error(), "USD to GBP") {
+		t.Errorf("Expected a missing rate error, got %v", err)
+	}
+
+	provider := currency.NewHTTPRateProvider(fetcher, upstream.URL+"/latest?from={base}")
+	if _, err := provider.Rate(context.Background(), "EUR", "USD"); err == nil {
+		t.Error("Expected an unknown base currency to fail")
+	}
+}
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func newFormulaServer() *mcp.Server {
+	server := mcp.NewServer()
+	formulaHandler := handlers.NewFormulaHandler(server)
+	permissive := map[string]interface{}{"type": "object"}
+	server.RegisterContextTool("save_formula", "Save a formula", permissive, formulaHandler.HandleSaveFormula)
+	server.RegisterContextTool("run_formula", "Run a formula", permissive, formulaHandler.HandleRunFormula)
+	return server
+}
+
+func callFormulaTool(t *testing.T, server *mcp.Server, ctx context.Context, params string) types.MCPResponse {
+	t.Helper()
+	return server.HandleRequestContext(ctx, types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(params),
+	})
+}
+
+// toolFailed reports whether a tool call ran and failed with isError
+func toolFailed(response types.MCPResponse) bool {
+	result, ok := response.Result.(types.CallToolResult)
+	return response.Error == nil && ok && result.IsError
+}
+
+func TestFormulas_SaveRunAndList(t *testing.T) {
+	server := newFormulaServer()
+	ctx := mcp.WithSessionID(context.Background(), "session-1")
+
+	response := callFormulaTool(t, server, ctx, `{"name":"save_formula","arguments":{"name":"bmi","expression":"weight / pow(height, 2)","variables":{"height":2}}}`)
+	if response.Error != nil {
+		t.Fatalf("save_formula failed: %v", response.Error)
+	}
+
+	response = callFormulaTool(t, server, ctx, `{"name":"run_formula","arguments":{"name":"bmi","variables":{"weight":80}}}`)
+	if response.Error != nil {
+		t.Fatalf("run_formula failed: %v", response.Error)
+	}
+	var run map[string]interface{}
+	json.Unmarshal([]byte(response.Result.(types.CallToolResult).Content[0].Text), &run)
+	if run["result"] != 20.0 {
+		t.Errorf("Expected 80 / 2² = 20, got %v", run["result"])
+	}
+
+	listed := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "resources/list"})
+	resources := listed.Result.(types.ListResourcesResult).Resources
+	if len(resources) != 1 || resources[0].URI != "formula://bmi" {
+		t.Fatalf("Expected the formula to be listed as a resource, got %+v", resources)
+	}
+
+	read := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 3, Method: "resources/read", Params: json.RawMessage(`{"uri":"formula://bmi"}`)})
+	if read.Error != nil {
+		t.Fatalf("resources/read failed: %v", read.Error)
+	}
+	if contents := read.Result.(types.ReadResourceResult).Contents; !strings.Contains(contents[0].Text, `"expression":"weight / pow(height, 2)"`) {
+		t.Errorf("Unexpected resource contents: %+v", contents)
+	}
+
+	// Session formulas are private to their session
+	other := mcp.WithSessionID(context.Background(), "session-2")
+	if response := callFormulaTool(t, server, other, `{"name":"run_formula","arguments":{"name":"bmi"}}`); !toolFailed(response) {
+		t.Error("Expected another session not to see the formula")
+	}
+	missing := server.HandleRequestContext(other, types.MCPRequest{JSONRPC: "2.0", ID: 4, Method: "resources/read", Params: json.RawMessage(`{"uri":"formula://bmi"}`)})
+	if missing.Error == nil || missing.Error.Code != mcp.ErrorCodeResourceNotFound {
+		t.Errorf("Expected resource not found, got %+v", missing.Error)
+	}
+}
+
+func TestFormulas_PersistentScope(t *testing.T) {
+	server := newFormulaServer()
+	ctx := mcp.WithSessionID(context.Background(), "session-1")
+	save := `{"name":"save_formula","arguments":{"name":"area","expression":"pi * pow(r, 2)","scope":"persistent"}}`
+
+	if response := callFormulaTool(t, server, ctx, save); !toolFailed(response) {
+		t.Fatal("Expected persistent formulas to require storage")
+	}
+
+	server.SetStore(storage.NewMemoryStore(), time.Hour)
+	if response := callFormulaTool(t, server, ctx, save); response.Error != nil {
+		t.Fatalf("save_formula failed: %v", response.Error)
+	}
+
+	other := mcp.WithSessionID(context.Background(), "session-2")
+	response := callFormulaTool(t, server, other, `{"name":"run_formula","arguments":{"name":"area","variables":{"r":1}}}`)
+	if response.Error != nil {
+		t.Fatalf("Expected the persistent formula in another session, got %v", response.Error)
+	}
+
+	if response := callFormulaTool(t, server, ctx, `{"name":"save_formula","arguments":{"name":"1bad","expression":"x"}}`); !toolFailed(response) {
+		t.Error("Expected an invalid formula name to be rejected")
+	}
+}
+
+func TestFormulas_UpdatesReachOnlyReaders(t *testing.T) {
+	server := newFormulaServer()
+	server.SetStore(storage.NewMemoryStore(), time.Hour)
+
+	var mu sync.Mutex
+	updated := make(map[string]int)
+	contexts := make(map[string]context.Context)
+	for session, tenant := range map[string]string{"a-1": "team-a", "a-2": "team-a", "b-1": "team-b"} {
+		session := session
+		ctx := mcp.WithTenant(mcp.WithSessionID(context.Background(), session), &mcp.Tenant{ID: tenant})
+		contexts[session] = ctx
+		defer server.SubscribeSession(session, func(notification types.MCPNotification) {
+			mu.Lock()
+			defer mu.Unlock()
+			if notification.Method == mcp.NotificationResourceUpdated {
+				updated[session]++
+			}
+		})()
+		subscribe := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "resources/subscribe", Params: json.RawMessage(`{"uri":"formula://rate"}`)})
+		if subscribe.Error != nil {
+			t.Fatalf("Subscribe failed: %v", subscribe.Error)
+		}
+	}
+
+	// A session formula is only readable by its session, a persistent one
+	// by its tenant
+	callFormulaTool(t, server, contexts["a-1"], `{"name":"save_formula","arguments":{"name":"rate","expression":"x / 2"}}`)
+	callFormulaTool(t, server, contexts["a-1"], `{"name":"save_formula","arguments":{"name":"rate","expression":"x / 3","scope":"persistent"}}`)
+	mu.Lock()
+	if updated["a-1"] != 2 || updated["a-2"] != 1 || updated["b-1"] != 0 {
+		t.Errorf("Expected updates only for sessions that can read the formula, got %v", updated)
+	}
+	mu.Unlock()
+
+	// Sessions cannot subscribe to names without end
+	var failed *types.MCPError
+	for i := 0; i < 200 && failed == nil; i++ {
+		params := fmt.Sprintf(`{"uri":"formula://f%d"}`, i)
+		failed = server.HandleRequestContext(contexts["b-1"], types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "resources/subscribe", Params: json.RawMessage(params)}).Error
+	}
+	if failed == nil || failed.Code != mcp.ErrorCodeTooManyRequests {
+		t.Errorf("Expected subscriptions to be limited, got %+v", failed)
+	}
+}
+
+func TestFormulas_SessionLifetimeAndCap(t *testing.T) {
+	server := newFormulaServer()
+	ctx := mcp.WithSessionID(context.Background(), "session-1")
+
+	for i := 0; i < 100; i++ {
+		save := fmt.Sprintf(`{"name":"save_formula","arguments":{"name":"f%d","expression":"x + %d"}}`, i, i)
+		if response := callFormulaTool(t, server, ctx, save); response.Error != nil || toolFailed(response) {
+			t.Fatalf("save_formula %d failed: %+v", i, response)
+		}
+	}
+	if response := callFormulaTool(t, server, ctx, `{"name":"save_formula","arguments":{"name":"extra","expression":"x"}}`); !toolFailed(response) {
+		t.Error("Expected a formula beyond the session's cap to be rejected")
+	}
+	if response := callFormulaTool(t, server, ctx, `{"name":"save_formula","arguments":{"name":"f0","expression":"x * 2"}}`); toolFailed(response) {
+		t.Error("Expected an existing formula to be replaceable at the cap")
+	}
+
+	// The formulas go with their session
+	server.ForgetClient("session-1")
+	if formulas, _ := server.Formulas(ctx); len(formulas) != 0 {
+		t.Errorf("Expected the session's formulas to be deleted, got %d", len(formulas))
+	}
+}
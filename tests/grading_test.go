@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestGradingCalculator_WeightedGradeAndFinal(t *testing.T) {
+	gc := calculator.NewGradingCalculator()
+	homework, exams := 95.0, 80.0
+	categories := []types.GradeCategory{
+		{Name: "homework", Score: &homework, Weight: 2},
+		{Name: "exams", Score: &exams, Weight: 5},
+		{Name: "labs", Earned: 27, Possible: 30, Weight: 3},
+	}
+
+	result, err := gc.Calculate(types.GradingRequest{Operation: "weighted_grade", Categories: categories})
+	if err != nil {
+		t.Fatalf("weighted_grade failed: %v", err)
+	}
+	// (95·2 + 80·5 + 90·3) / 10 = 86
+	if math.Abs(*result.Grade-86) > 1e-9 || result.Letter != "B" {
+		t.Errorf("Expected 86 (B), got %v (%s)", *result.Grade, result.Letter)
+	}
+	if len(result.Contributions) != 3 || result.Contributions[1].Weight != 50 || result.Contributions[1].Contribution != 40 {
+		t.Errorf("Unexpected contributions: %+v", result.Contributions)
+	}
+
+	// Reaching 90 from 86 with a 20% final needs 106
+	needed, err := gc.Calculate(types.GradingRequest{Operation: "final_needed", Categories: categories, FinalWeight: 20, TargetGrade: 90})
+	if err != nil {
+		t.Fatalf("final_needed failed: %v", err)
+	}
+	if math.Abs(*needed.Needed-106) > 1e-9 || *needed.Achievable {
+		t.Errorf("Expected an unachievable 106, got %v (%v)", *needed.Needed, *needed.Achievable)
+	}
+	current := 86.0
+	needed, err = gc.Calculate(types.GradingRequest{Operation: "final_needed", CurrentGrade: &current, FinalWeight: 40, TargetGrade: 80})
+	if err != nil || math.Abs(*needed.Needed-71) > 1e-9 || !*needed.Achievable {
+		t.Errorf("Expected an achievable 71, got %+v, %v", needed, err)
+	}
+
+	invalid := []types.GradingRequest{
+		{Operation: "weighted_grade"},
+		{Operation: "weighted_grade", Categories: []types.GradeCategory{{Name: "quiz", Weight: 1}}},
+		{Operation: "final_needed", CurrentGrade: &current, FinalWeight: 0, TargetGrade: 90},
+		{Operation: "final_needed", CurrentGrade: &current, Categories: categories, FinalWeight: 20, TargetGrade: 90},
+	}
+	for _, req := range invalid {
+		if _, err := gc.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestGradingCalculator_GPA(t *testing.T) {
+	gc := calculator.NewGradingCalculator()
+	credits := func(value float64) *float64 { return &value }
+	courses := []types.CourseGrade{
+		{Grade: "A+", Credits: credits(4)},
+		{Grade: "b+", Credits: credits(3)},
+		{Grade: "C"},
+	}
+
+	result, err := gc.Calculate(types.GradingRequest{Operation: "gpa", Courses: courses})
+	if err != nil {
+		t.Fatalf("gpa failed: %v", err)
+	}
+	// (4·4 + 3.3·3 + 2·1) / 8
+	if math.Abs(*result.GPA-27.9/8) > 1e-9 || *result.Credits != 8 {
+		t.Errorf("Expected a GPA of %v over 8 credits, got %v over %v", 27.9/8, *result.GPA, *result.Credits)
+	}
+
+	result, err = gc.Calculate(types.GradingRequest{Operation: "gpa", Courses: courses, Scale: "4.3"})
+	if err != nil || math.Abs(*result.GPA-29.1/8) > 1e-9 {
+		t.Errorf("Expected an A+ to count 4.3 on the 4.3 scale, got %+v, %v", result, err)
+	}
+
+	if _, err := gc.Calculate(types.GradingRequest{Operation: "gpa", Courses: []types.CourseGrade{{Grade: "E"}}}); err == nil {
+		t.Error("Expected an unknown letter grade to be rejected")
+	}
+
+	// Zero credits are given, not left out, so they do not count as 1
+	if _, err := gc.Calculate(types.GradingRequest{Operation: "gpa", Courses: []types.CourseGrade{{Grade: "A", Credits: credits(0)}}}); err == nil {
+		t.Error("Expected a course with zero credits to be rejected")
+	}
+	var decoded types.GradingRequest
+	json.Unmarshal([]byte(`{"operation":"gpa","courses":[{"grade":"A","credits":0},{"grade":"B"}]}`), &decoded)
+	if decoded.Courses[0].Credits == nil || *decoded.Courses[0].Credits != 0 || decoded.Courses[1].Credits != nil {
+		t.Errorf("Expected explicit and omitted credits to decode apart, got %+v", decoded.Courses)
+	}
+}
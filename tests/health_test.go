@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/pkg/mcp"
+)
+
+func startHealthTransport(t *testing.T, config *mcp.StreamableHTTPConfig) *mcp.StreamableHTTPTransport {
+	t.Helper()
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil && err != http.ErrServerClosed {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	return httpTransport
+}
+
+func getStatus(t *testing.T, url string) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Request to %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func TestStreamableHTTP_Health(t *testing.T) {
+	// Tenants need an API key everywhere but on /health
+	registry := mcp.NewTenantRegistry([]mcp.Tenant{{ID: "team-a", APIKeys: []string{"key-a"}}})
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8097,
+		SessionTimeout: 5 * time.Minute,
+		HealthEnabled:  true,
+		Tenants:        registry,
+	}
+	httpTransport := startHealthTransport(t, config)
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", config.Port)
+
+	status, body := getStatus(t, baseURL+"/health")
+	if status != http.StatusOK || body["status"] != "ok" || body["tools"] != float64(1) {
+		t.Errorf("Expected a healthy transport, got %d %v", status, body)
+	}
+	if status, _ := getStatus(t, baseURL+"/metrics"); status == http.StatusOK {
+		t.Error("Expected /metrics to stay disabled")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	httpTransport.Stop(shutdownCtx)
+}
+
+func TestStreamableHTTP_OpsPort(t *testing.T) {
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8098,
+		SessionTimeout: 5 * time.Minute,
+		HealthEnabled:  true,
+		MetricsEnabled: true,
+		OpsPort:        8099,
+	}
+	httpTransport := startHealthTransport(t, config)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	opsURL := fmt.Sprintf("http://127.0.0.1:%d", config.OpsPort)
+	if status, body := getStatus(t, opsURL+"/health"); status != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("Expected /health on the ops port, got %d %v", status, body)
+	}
+	if status, body := getStatus(t, opsURL+"/metrics"); status != http.StatusOK || body["tools"] == nil {
+		t.Errorf("Expected /metrics on the ops port, got %d %v", status, body)
+	}
+
+	mcpURL := fmt.Sprintf("http://127.0.0.1:%d", config.Port)
+	for _, path := range []string{"/health", "/metrics"} {
+		if status, _ := getStatus(t, mcpURL+path); status != http.StatusNotFound {
+			t.Errorf("Expected %s to be off the MCP port, got %d", path, status)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_IdempotentJobSubmission(t *testing.T) {
+	var runs atomic.Int32
+	server := mcp.NewServer()
+	server.RegisterTool("count", "Counts its runs", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return runs.Add(1), nil
+	})
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8118,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		Jobs:           mcp.NewJobManager(server, nil),
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	submit := func(key, body string) (*http.Response, types.Job) {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8118/jobs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(mcp.IdempotencyKeyHeader, key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var job types.Job
+		json.NewDecoder(resp.Body).Decode(&job)
+		return resp, job
+	}
+
+	first, job := submit("retry-1", `{"name":"count","arguments":{}}`)
+	if first.StatusCode != http.StatusAccepted || job.ID == "" || first.Header.Get(mcp.IdempotentReplayedHeader) != "" {
+		t.Fatalf("Expected the job to be accepted, got %d %+v", first.StatusCode, job)
+	}
+
+	// A retry gets the original job back rather than starting another
+	retry, retried := submit("retry-1", `{"name":"count","arguments":{}}`)
+	if retry.StatusCode != http.StatusAccepted || retried.ID != job.ID || retry.Header.Get("Location") != "/jobs/"+job.ID ||
+		retry.Header.Get(mcp.IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected the first response to be replayed, got %d %+v", retry.StatusCode, retried)
+	}
+	if reused, _ := submit("retry-1", `{"name":"count","arguments":{"x":1}}`); reused.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a reused key to be rejected, got %d", reused.StatusCode)
+	}
+
+	// Requests without a key, or with another one, are separate jobs
+	if _, other := submit("", `{"name":"count","arguments":{}}`); other.ID == "" || other.ID == job.ID {
+		t.Errorf("Expected a new job without a key, got %+v", other)
+	}
+	if _, other := submit("retry-2", `{"name":"count","arguments":{}}`); other.ID == "" || other.ID == job.ID {
+		t.Errorf("Expected a new job for another key, got %+v", other)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runs.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := runs.Load(); got != 3 {
+		t.Errorf("Expected 3 tool runs, got %d", got)
+	}
+}
+
+func TestStreamableHTTP_IdempotentResponsesAreCappedPerScope(t *testing.T) {
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8134,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		AdminToken:     "admin-token",
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	toggle := func(key string) bool {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8134/admin/tool-groups", strings.NewReader(`{"group":"stats","enabled":true}`))
+		req.Header.Set("Authorization", "Bearer admin-token")
+		req.Header.Set(mcp.IdempotencyKeyHeader, key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.Header.Get(mcp.IdempotentReplayedHeader) == "true"
+	}
+
+	// One scope keeps its latest 1000 responses and forgets older ones
+	for i := 0; i <= 1000; i++ {
+		toggle(fmt.Sprintf("key-%d", i))
+	}
+	if toggle("key-0") {
+		t.Error("Expected the oldest response of the scope to be forgotten")
+	}
+	if !toggle("key-1000") {
+		t.Error("Expected the latest response of the scope to be replayed")
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestInequalitySolver_Solve(t *testing.T) {
+	solver := calculator.NewInequalitySolver()
+
+	tests := []struct {
+		inequality string
+		expected   string
+	}{
+		{"2x + 3 < 7", "(-∞, 2)"},
+		{"-2x >= 4", "(-∞, -2]"},
+		{"x^2 - 5x + 6 >= 0", "(-∞, 2] ∪ [3, ∞)"},
+		{"x^2 - 5x + 6 < 0", "(2, 3)"},
+		{"t^2 <= 0", "{0}"},
+		{"x^2 + 1 > 0", "(-∞, ∞)"},
+		{"x^2 + 1 < 0", "∅"},
+		{"x^2 - 2x + 1 > 0", "(-∞, 1) ∪ (1, ∞)"},
+		{"x^2 ≤ 2", "[-1.41421356237, 1.41421356237]"},
+		{"3x > 3x - 1", "(-∞, ∞)"},
+		{"x^2 + x >= 0", "(-∞, -1] ∪ [0, ∞)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.inequality, func(t *testing.T) {
+			result, err := solver.Solve(types.InequalityRequest{Inequality: tt.inequality})
+			if err != nil {
+				t.Fatalf("Solve failed: %v", err)
+			}
+			if result.Notation != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result.Notation)
+			}
+		})
+	}
+}
+
+func TestInequalitySolver_StructuredIntervals(t *testing.T) {
+	result, err := calculator.NewInequalitySolver().Solve(types.InequalityRequest{Inequality: "x^2 - 5x + 6 >= 0"})
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if result.Variable != "x" || len(result.Intervals) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	first, second := result.Intervals[0], result.Intervals[1]
+	if first.Lower != nil || first.Upper == nil || *first.Upper != 2 || !first.UpperClosed {
+		t.Errorf("Expected (-∞, 2], got %+v", first)
+	}
+	if second.Lower == nil || *second.Lower != 3 || !second.LowerClosed || second.Upper != nil {
+		t.Errorf("Expected [3, ∞), got %+v", second)
+	}
+}
+
+func TestInequalitySolver_Errors(t *testing.T) {
+	solver := calculator.NewInequalitySolver()
+
+	tests := []struct {
+		inequality string
+		contains   string
+	}{
+		{"x + 1 = 2", "expected one of"},
+		{"x^3 > 1", "linear and quadratic"},
+		{"x + y > 1", "one variable"},
+		{"0 < x < 1", "chained"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.inequality, func(t *testing.T) {
+			_, err := solver.Solve(types.InequalityRequest{Inequality: tt.inequality})
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Expected an error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -207,16 +208,40 @@ func TestStdioTransportBackwardCompatibility(t *testing.T) {
 		t.Error("Failed to create stdio transport")
 	}
 
-	// Test that server.Run() still works (backwards compatibility)
-	// Note: We can't actually test stdio input/output in unit tests easily,
-	// but we can verify that the method exists and returns the right type
-	go func() {
-		// This would block waiting for stdin, so we run it in a goroutine
-		// and immediately stop it
-		server.Run()
-	}()
+	// Run serves the same protocol over any pair of byte streams and
+	// returns once the input ends and every call has been answered
+	input := strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}` + "\n" +
+			`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+			"\n" +
+			`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[2,3]}}}` + "\n" +
+			`not json` + "\n")
+	var output bytes.Buffer
+	if err := server.Run(input, &output); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
 
-	time.Sleep(10 * time.Millisecond) // Give it a moment to start
+	responses := map[float64]types.MCPResponse{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var response types.MCPResponse
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("Expected one JSON message per line, got %q", line)
+		}
+		id, _ := response.ID.(float64)
+		responses[id] = response
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected three responses, got %s", output.String())
+	}
+	if responses[1].Error != nil || responses[2].Error != nil {
+		t.Errorf("Expected initialize and the tool call to succeed, got %s", output.String())
+	}
+	if !strings.Contains(output.String(), `\"result\":5`) {
+		t.Errorf("Expected 2 + 3 = 5, got %s", output.String())
+	}
+	if responses[0].Error == nil || responses[0].Error.Code != mcp.ErrorCodeParseError {
+		t.Errorf("Expected a parse error for the malformed line, got %+v", responses[0])
+	}
 }
 
 // Helper schemas for integration tests
@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestJobManager_SignedWebhookOnCompletion(t *testing.T) {
+	const secret = "test-secret"
+
+	type delivery struct {
+		event     string
+		timestamp string
+		signature string
+		body      []byte
+	}
+	deliveries := make(chan delivery, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{
+			event:     r.Header.Get(mcp.WebhookEventHeader),
+			timestamp: r.Header.Get(mcp.WebhookTimestampHeader),
+			signature: r.Header.Get(mcp.WebhookSignatureHeader),
+			body:      body,
+		}
+	}))
+	defer webhook.Close()
+
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+	jobs := mcp.NewJobManager(server, mcp.NewWebhookNotifier(webhook.URL, secret, 5*time.Second))
+
+	job, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{
+		Name:      "basic_math",
+		Arguments: map[string]interface{}{"operation": "add", "operands": []interface{}{2.0, 3.0}},
+	})
+	if mcpErr != nil {
+		t.Fatalf("Submit failed: %v", mcpErr)
+	}
+
+	var got delivery
+	select {
+	case got = <-deliveries:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Webhook was not called")
+	}
+
+	if got.event != "job.completed" {
+		t.Errorf("Expected job.completed event, got %q", got.event)
+	}
+	if sent, err := strconv.ParseInt(got.timestamp, 10, 64); err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Errorf("Expected the current time as timestamp, got %q", got.timestamp)
+	}
+	if got.signature != mcp.SignWebhookPayload(secret, got.timestamp, got.body) {
+		t.Errorf("Signature %q does not match payload", got.signature)
+	}
+	if got.signature == mcp.SignWebhookPayload(secret, "0", got.body) {
+		t.Error("Expected the signature to cover the timestamp")
+	}
+
+	var delivered types.Job
+	if err := json.Unmarshal(got.body, &delivered); err != nil {
+		t.Fatalf("Webhook body is not a job: %v", err)
+	}
+	if delivered.ID != job.ID || delivered.Result == nil {
+		t.Errorf("Expected completed job %s with a result, got %+v", job.ID, delivered)
+	}
+
+	polled, exists := jobs.Get(job.ID)
+	if !exists || polled.Status != types.JobStatusCompleted {
+		t.Errorf("Expected job to be completed when polled, got %+v", polled)
+	}
+}
+
+func TestJobManager_UnknownTool(t *testing.T) {
+	jobs := mcp.NewJobManager(mcp.NewServer(), nil)
+
+	_, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "missing"})
+	if mcpErr == nil || mcpErr.Code != mcp.ErrorCodeToolNotFound {
+		t.Errorf("Expected tool not found error, got %v", mcpErr)
+	}
+}
+
+func TestJobManager_UnavailableTool(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("double", "Doubles a number", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return 0, nil
+	}, mcp.WithGroup("math"))
+	jobs := mcp.NewJobManager(server, nil)
+
+	// Tools of disabled groups or outside the tenant's set cannot be
+	// submitted
+	server.SetToolGroupEnabled("math", false)
+	if _, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "double"}); mcpErr == nil || mcpErr.Code != mcp.ErrorCodeAccessDenied {
+		t.Errorf("Expected a tool of a disabled group to be refused, got %v", mcpErr)
+	}
+	server.SetToolGroupEnabled("math", true)
+	ctx := mcp.WithTenant(context.Background(), &mcp.Tenant{ID: "team-a", AllowedTools: []string{"other"}})
+	if _, mcpErr := jobs.Submit(ctx, types.CallToolParams{Name: "double"}); mcpErr == nil || mcpErr.Code != mcp.ErrorCodeAccessDenied {
+		t.Errorf("Expected a tool outside the tenant's set to be refused, got %v", mcpErr)
+	}
+	if _, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "double"}); mcpErr != nil {
+		t.Errorf("Expected the enabled tool to be accepted, got %v", mcpErr)
+	}
+}
+
+func TestJobManager_Limits(t *testing.T) {
+	release := make(chan struct{})
+	server := mcp.NewServer()
+	server.RegisterTool("wait", "Waits to be released", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		<-release
+		return 0, nil
+	})
+	server.RegisterTool("quick", "Returns at once", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return 0, nil
+	})
+	jobs := mcp.NewJobManager(server, nil)
+	jobs.SetLimits(3, 2)
+
+	var waiting []types.Job
+	for i := 0; i < 2; i++ {
+		job, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "wait"})
+		if mcpErr != nil {
+			t.Fatalf("Submit failed: %v", mcpErr)
+		}
+		waiting = append(waiting, job)
+	}
+	if _, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "quick"}); mcpErr == nil || mcpErr.Code != mcp.ErrorCodeTooManyRequests {
+		t.Fatalf("Expected a job beyond the running cap to be refused, got %v", mcpErr)
+	}
+	close(release)
+	awaitJob(t, jobs, waiting[0].ID)
+	awaitJob(t, jobs, waiting[1].ID)
+
+	// Further jobs evict the oldest finished ones
+	var quick []types.Job
+	for i := 0; i < 3; i++ {
+		job, mcpErr := jobs.Submit(context.Background(), types.CallToolParams{Name: "quick"})
+		if mcpErr != nil {
+			t.Fatalf("Submit failed: %v", mcpErr)
+		}
+		awaitJob(t, jobs, job.ID)
+		quick = append(quick, job)
+	}
+	for _, job := range waiting {
+		if _, exists := jobs.Get(job.ID); exists {
+			t.Errorf("Expected the older job %s to be evicted", job.ID)
+		}
+	}
+	for _, job := range quick {
+		if _, exists := jobs.Get(job.ID); !exists {
+			t.Errorf("Expected the recent job %s to be kept", job.ID)
+		}
+	}
+}
+
+// awaitJob waits for the job to finish
+func awaitJob(t *testing.T, jobs *mcp.JobManager, id string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ := jobs.Get(id); job.CompletedAt != nil {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+}
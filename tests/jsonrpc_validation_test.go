@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestJSONRPCValidation(t *testing.T) {
+	server := mcp.NewServer()
+	tests := []struct {
+		request string
+		code    int
+		id      interface{}
+	}{
+		{`{"jsonrpc":"1.0","id":1,"method":"tools/list"}`, mcp.ErrorCodeInvalidRequest, float64(1)},
+		{`{"id":2,"method":"tools/list"}`, mcp.ErrorCodeInvalidRequest, float64(2)},
+		{`{"jsonrpc":"2.0","id":true,"method":"tools/list"}`, mcp.ErrorCodeInvalidRequest, nil},
+		{`{"jsonrpc":"2.0","id":{"a":1},"method":"tools/list"}`, mcp.ErrorCodeInvalidRequest, nil},
+		{`{"jsonrpc":"2.0","id":"three","method":"tools/list","extra":1}`, mcp.ErrorCodeInvalidRequest, "three"},
+		{`{"jsonrpc":"2.0","id":4}`, mcp.ErrorCodeInvalidRequest, float64(4)},
+		{`{"jsonrpc":"2.0","id":5,"method":"tools/list","params":"all"}`, mcp.ErrorCodeInvalidRequest, float64(5)},
+		{`{"jsonrpc":"2.0","method":"notifications/initialized","params":5}`, mcp.ErrorCodeInvalidRequest, nil},
+		{`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":6}}`, mcp.ErrorCodeInvalidParams, float64(6)},
+	}
+
+	input := make([]string, len(tests))
+	for i, test := range tests {
+		input[i] = test.request
+	}
+	var output bytes.Buffer
+	if err := server.Run(strings.NewReader(strings.Join(input, "\n")+"\n"), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != len(tests) {
+		t.Fatalf("Expected %d responses, got %d: %s", len(tests), len(lines), output.String())
+	}
+	for i, test := range tests {
+		var response types.MCPResponse
+		if err := json.Unmarshal([]byte(lines[i]), &response); err != nil {
+			t.Fatalf("Invalid response %s: %v", lines[i], err)
+		}
+		if response.Error == nil || response.Error.Code != test.code || response.ID != test.id {
+			t.Errorf("%s: expected error %d with id %v, got %s", test.request, test.code, test.id, lines[i])
+		}
+	}
+
+	// Embedding code gets the same checks
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "1.0", ID: 7, Method: "tools/list"})
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeInvalidRequest {
+		t.Errorf("Expected an invalid version to be rejected, got %+v", response)
+	}
+	if response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 8, Method: "tools/list"}); response.Error != nil {
+		t.Errorf("Expected a valid request to be answered, got %+v", response.Error)
+	}
+}
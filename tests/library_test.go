@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/calculator"
+)
+
+func TestLibrary_Basic(t *testing.T) {
+	result, err := calculator.Basic("subtract", []float64{10, 3, 2}, 2)
+	if err != nil {
+		t.Fatalf("Basic failed: %v", err)
+	}
+	if result.Value != 5 || result.Semantics == "" {
+		t.Errorf("Expected 5 with left-to-right semantics, got %+v", result)
+	}
+
+	_, err = calculator.Basic("divide", []float64{1, 0}, 2)
+	var calcErr *calculator.CalculationError
+	if !errors.As(err, &calcErr) || calcErr.Code != calculator.ErrCodeDivisionByZero {
+		t.Errorf("Expected a division by zero CalculationError, got %v", err)
+	}
+
+	if _, err := calculator.Basic("power", []float64{2}, 2); err == nil {
+		t.Error("Expected operand count error for power with one operand")
+	}
+}
+
+func TestLibrary_ValidatesLikeTheTools(t *testing.T) {
+	handler := handlers.NewMathHandler()
+	basic := []struct {
+		operation string
+		operands  []float64
+	}{
+		{"square", []float64{2, 3}},
+		{"add", []float64{1, math.NaN()}},
+		{"add", []float64{1}},
+	}
+	for _, tc := range basic {
+		_, libraryErr := calculator.Basic(tc.operation, tc.operands, 2)
+		_, toolErr := handler.BasicMath(types.BasicMathRequest{Operation: tc.operation, Operands: tc.operands})
+		if libraryErr == nil || toolErr == nil || libraryErr.Error() != toolErr.Error() {
+			t.Errorf("%s %v: expected the library and the tool to fail alike, got %v and %v", tc.operation, tc.operands, libraryErr, toolErr)
+		}
+	}
+
+	_, libraryErr := calculator.Advanced("sin", 90, calculator.AdvancedOptions{Unit: "gradians"})
+	_, toolErr := handler.AdvancedMath(types.AdvancedMathRequest{Function: "sin", Value: 90, Unit: "gradians"})
+	if libraryErr == nil || toolErr == nil || libraryErr.Error() != toolErr.Error() {
+		t.Errorf("Expected an invalid unit to fail alike, got %v and %v", libraryErr, toolErr)
+	}
+
+	if _, err := calculator.WeightedPercentile([]float64{1, 2, 3}, []float64{1, 1, 1}, 50, "p2"); err == nil {
+		t.Error("Expected p2 to reject weights")
+	}
+	if median, err := calculator.WeightedPercentile([]float64{1, 2, 3}, []float64{1, 1, 3}, 50, "exact"); err != nil || median != 3 {
+		t.Errorf("Expected the weighted median 3, got %v (err %v)", median, err)
+	}
+}
+
+func TestLibrary_EvaluateAndConvert(t *testing.T) {
+	value, err := calculator.Evaluate("x * 2 + 1", map[string]float64{"x": 4})
+	if err != nil || value != 9 {
+		t.Errorf("Expected 9, got %v (err %v)", value, err)
+	}
+
+	km, err := calculator.Convert(1, "mi", "km", "length")
+	if err != nil || math.Abs(km-1.609344) > 1e-9 {
+		t.Errorf("Expected 1.609344 km, got %v (err %v)", km, err)
+	}
+}
+
+func TestLibrary_CompareLoans(t *testing.T) {
+	comparison := calculator.CompareLoans([]calculator.Loan{
+		{Principal: 100000, Rate: 5, Years: 15},
+		{Principal: 100000, Rate: 5, Years: 30},
+		{Principal: -1, Rate: 5, Years: 30},
+	})
+
+	if comparison.Best != 1 {
+		t.Errorf("Expected the 30 year loan to have the lowest payment, got index %d", comparison.Best)
+	}
+	if comparison.Scenarios[2].Err == nil {
+		t.Error("Expected an error for the invalid loan")
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/config"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_RequestLimits(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+	server.SetDecodeLimits(mcp.DecodeLimits{MaxDepth: 8, MaxArrayLength: 3})
+
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:            "127.0.0.1",
+		Port:            8111,
+		SessionTimeout:  5 * time.Minute,
+		MaxConnections:  100,
+		Stateless:       true,
+		MaxRequestBytes: 512,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	post := func(body string) (int, *types.MCPError) {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8111/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var response types.MCPResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response.Error
+	}
+	call := func(arguments string) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"basic_math","arguments":` + arguments + `}}`
+	}
+
+	if status, mcpErr := post(call(`{"operation":"add","operands":[1,2,3]}`)); status != http.StatusOK || mcpErr != nil {
+		t.Fatalf("Expected a request within the limits to succeed, got %d %+v", status, mcpErr)
+	}
+	// Brackets and commas inside strings are not structure
+	if status, mcpErr := post(call(`{"operation":"add","operands":[1,2],"note":"[[[[[[[[[[,,,,]"}`)); status != http.StatusOK || mcpErr != nil {
+		t.Errorf("Expected strings to be ignored by the limits, got %d %+v", status, mcpErr)
+	}
+
+	rejected := map[string]struct {
+		body   string
+		status int
+	}{
+		"long array":   {call(`{"operation":"add","operands":[1,2,3,4]}`), http.StatusBadRequest},
+		"deep nesting": {call(`{"operation":"add","operands":[1,2],"x":[[[[[[1]]]]]]}`), http.StatusBadRequest},
+		"large body":   {call(`{"operation":"add","operands":[1,2],"padding":"` + strings.Repeat("x", 1024) + `"}`), http.StatusRequestEntityTooLarge},
+	}
+	for name, test := range rejected {
+		status, mcpErr := post(test.body)
+		if status != test.status || mcpErr == nil || mcpErr.Code != mcp.ErrorCodeInvalidRequest {
+			t.Errorf("%s: expected %d with an invalid request error, got %d %+v", name, test.status, status, mcpErr)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	sizes := map[string]int64{"1MB": 1 << 20, "512kb": 512 << 10, "2 GB": 2 << 30, "100B": 100, "4096": 4096}
+	for size, want := range sizes {
+		if got, err := config.ParseByteSize(size); err != nil || got != want {
+			t.Errorf("ParseByteSize(%q): expected %d, got %d %v", size, want, got, err)
+		}
+	}
+	for _, size := range []string{"", "MB", "-1KB", "1.5MB", "1TB", "99999999999GB"} {
+		if _, err := config.ParseByteSize(size); err == nil {
+			t.Errorf("Expected ParseByteSize(%q) to fail", size)
+		}
+	}
+}
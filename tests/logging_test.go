@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+// logSink collects the notifications sent to one session
+type logSink struct {
+	mu       sync.Mutex
+	messages []types.LoggingMessageParams
+}
+
+func (s *logSink) notify(notification types.MCPNotification) {
+	if notification.Method != mcp.NotificationMessage {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, notification.Params.(types.LoggingMessageParams))
+}
+
+func (s *logSink) events() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]string, len(s.messages))
+	for i, message := range s.messages {
+		events[i] = message.Level + " " + message.Data.(map[string]interface{})["event"].(string)
+	}
+	return events
+}
+
+func newLoggingServer() *mcp.Server {
+	server := mcp.NewServer()
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           map[string]interface{}{"fail": map[string]interface{}{"type": "boolean"}},
+		"additionalProperties": false,
+	}
+	server.RegisterTool("check", "Fails on request", schema, func(params map[string]interface{}) (interface{}, error) {
+		if params["fail"] == true {
+			return nil, errors.New("asked to fail")
+		}
+		return map[string]interface{}{"ok": true}, nil
+	})
+	return server
+}
+
+func sessionRequest(server *mcp.Server, sessionID string, sink *logSink, method, params string) types.MCPResponse {
+	ctx := mcp.WithNotifier(mcp.WithSessionID(context.Background(), sessionID), sink.notify)
+	return server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: json.RawMessage(params)})
+}
+
+func TestLogging_PerSessionLevels(t *testing.T) {
+	server := newLoggingServer()
+
+	initialized := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	capabilities := initialized.Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+	if _, ok := capabilities["logging"]; !ok {
+		t.Errorf("Expected the logging capability to be advertised, got %v", capabilities)
+	}
+
+	verbose, quiet, silent := &logSink{}, &logSink{}, &logSink{}
+	if response := sessionRequest(server, "verbose", verbose, "logging/setLevel", `{"level":"debug"}`); response.Error != nil {
+		t.Fatalf("logging/setLevel failed: %+v", response.Error)
+	}
+	sessionRequest(server, "quiet", quiet, "logging/setLevel", `{"level":"warning"}`)
+
+	for _, session := range []struct {
+		id   string
+		sink *logSink
+	}{{"verbose", verbose}, {"quiet", quiet}, {"silent", silent}} {
+		sessionRequest(server, session.id, session.sink, "tools/call", `{"name":"check","arguments":{}}`)
+		sessionRequest(server, session.id, session.sink, "tools/call", `{"name":"check","arguments":{"fail":true}}`)
+		sessionRequest(server, session.id, session.sink, "tools/call", `{"name":"check","arguments":{"typo":1}}`)
+	}
+
+	expected := []string{
+		"debug tool_invoked",
+		"debug tool_invoked", "warning validation_failed",
+		"debug tool_invoked", "warning validation_failed",
+	}
+	if events := verbose.events(); len(events) != len(expected) {
+		t.Errorf("Expected %v at debug level, got %v", expected, events)
+	} else {
+		for i := range events {
+			if events[i] != expected[i] {
+				t.Errorf("Expected %v at debug level, got %v", expected, events)
+				break
+			}
+		}
+	}
+	if events := quiet.events(); len(events) != 2 || events[0] != "warning validation_failed" {
+		t.Errorf("Expected only the warnings at warning level, got %v", events)
+	}
+	if events := silent.events(); len(events) != 0 {
+		t.Errorf("Expected no messages without logging/setLevel, got %v", events)
+	}
+	if message := quiet.messages[0]; message.Logger != "tools" || message.Data.(map[string]interface{})["tool"] != "check" {
+		t.Errorf("Expected the tool and logger in the message, got %+v", message)
+	}
+
+	// Levels end with their session
+	server.ForgetClient("verbose")
+	if _, enabled := server.LogLevel("verbose"); enabled {
+		t.Error("Expected the level of a forgotten session to be dropped")
+	}
+}
+
+func TestLogging_SlowCalculation(t *testing.T) {
+	server := newLoggingServer()
+	server.RegisterTool("slow", "Sleeps", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		return 1, nil
+	})
+	server.SetSlowCallThreshold(time.Millisecond)
+
+	sink := &logSink{}
+	sessionRequest(server, "session", sink, "logging/setLevel", `{"level":"warning"}`)
+	sessionRequest(server, "session", sink, "tools/call", `{"name":"slow","arguments":{}}`)
+
+	if events := sink.events(); len(events) != 1 || events[0] != "warning slow_calculation" {
+		t.Fatalf("Expected a slow calculation warning, got %v", events)
+	}
+	if duration := sink.messages[0].Data.(map[string]interface{})["duration_ms"].(float64); duration < 5 {
+		t.Errorf("Expected the duration of the call, got %v ms", duration)
+	}
+}
+
+func TestLogging_SetLevelErrors(t *testing.T) {
+	server := newLoggingServer()
+	sink := &logSink{}
+
+	for _, params := range []string{`{"level":"verbose"}`, `{"level":3}`, `{}`} {
+		if response := sessionRequest(server, "session", sink, "logging/setLevel", params); response.Error == nil || response.Error.Code != mcp.ErrorCodeInvalidParams {
+			t.Errorf("Expected %s to be rejected, got %+v", params, response)
+		}
+	}
+	if _, enabled := server.LogLevel("session"); enabled {
+		t.Error("Expected rejected levels to leave logging off")
+	}
+
+	for _, name := range []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"} {
+		level, err := mcp.ParseLogLevel(name)
+		if err != nil || level.String() != name {
+			t.Errorf("Expected %s to round-trip, got %v (%v)", name, level, err)
+		}
+	}
+}
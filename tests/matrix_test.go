@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func multiplyRows(a, b [][]float64) [][]float64 {
+	product := make([][]float64, len(a))
+	for i := range a {
+		product[i] = make([]float64, len(b[0]))
+		for j := range b[0] {
+			for k := range b {
+				product[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return product
+}
+
+func assertRowsEqual(t *testing.T, expected, actual [][]float64) {
+	t.Helper()
+	for i := range expected {
+		for j := range expected[i] {
+			if math.Abs(expected[i][j]-actual[i][j]) > 1e-9 {
+				t.Fatalf("Expected %v, got %v", expected, actual)
+			}
+		}
+	}
+}
+
+func TestMatrixCalculator_Decompositions(t *testing.T) {
+	mc := calculator.NewMatrixCalculator()
+	a := [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 10}}
+
+	lu, err := mc.Calculate(types.MatrixRequest{Operation: "lu", Matrix: a})
+	if err != nil {
+		t.Fatalf("lu failed: %v", err)
+	}
+	assertRowsEqual(t, a, multiplyRows(lu.Factors["P"], multiplyRows(lu.Factors["L"], lu.Factors["U"])))
+	if math.Abs(*lu.Determinant-(-3)) > 1e-9 {
+		t.Errorf("Expected determinant -3, got %v", *lu.Determinant)
+	}
+
+	qr, err := mc.Calculate(types.MatrixRequest{Operation: "qr", Matrix: a})
+	if err != nil {
+		t.Fatalf("qr failed: %v", err)
+	}
+	assertRowsEqual(t, a, multiplyRows(qr.Factors["Q"], qr.Factors["R"]))
+
+	spd := [][]float64{{4, 12, -16}, {12, 37, -43}, {-16, -43, 98}}
+	cholesky, err := mc.Calculate(types.MatrixRequest{Operation: "cholesky", Matrix: spd})
+	if err != nil {
+		t.Fatalf("cholesky failed: %v", err)
+	}
+	assertRowsEqual(t, [][]float64{{2, 0, 0}, {6, 1, 0}, {-8, 5, 3}}, cholesky.Factors["L"])
+	if cholesky.ConditionNumber == nil || *cholesky.ConditionNumber < 1 {
+		t.Errorf("Expected a condition number, got %v", cholesky.ConditionNumber)
+	}
+
+	if _, err := mc.Calculate(types.MatrixRequest{Operation: "cholesky", Matrix: [][]float64{{1, 2}, {2, 1}}}); err == nil || !strings.Contains(err.Error(), "positive definite") {
+		t.Errorf("Expected a positive definite error, got %v", err)
+	}
+}
+
+func TestMatrixCalculator_LeastSquaresAndSingular(t *testing.T) {
+	mc := calculator.NewMatrixCalculator()
+
+	// Fit y = c + m·x through (1, 1), (2, 2), (3, 2)
+	fit, err := mc.Calculate(types.MatrixRequest{
+		Operation: "least_squares",
+		Matrix:    [][]float64{{1, 1}, {1, 2}, {1, 3}},
+		Vector:    []float64{1, 2, 2},
+	})
+	if err != nil {
+		t.Fatalf("least_squares failed: %v", err)
+	}
+	if math.Abs(fit.Solution[0]-2.0/3) > 1e-9 || math.Abs(fit.Solution[1]-0.5) > 1e-9 {
+		t.Errorf("Expected [2/3, 1/2], got %v", fit.Solution)
+	}
+	if math.Abs(*fit.ResidualNorm-math.Sqrt(1.0/6)) > 1e-9 {
+		t.Errorf("Expected residual norm √(1/6), got %v", *fit.ResidualNorm)
+	}
+
+	singular := [][]float64{{1, 2}, {2, 4}}
+	if _, err := mc.Calculate(types.MatrixRequest{Operation: "inverse", Matrix: singular}); err == nil {
+		t.Error("Expected an error inverting a singular matrix")
+	}
+	determinant, err := mc.Calculate(types.MatrixRequest{Operation: "determinant", Matrix: singular})
+	if err != nil {
+		t.Fatalf("determinant failed: %v", err)
+	}
+	if *determinant.Determinant != 0 || len(determinant.Warnings) == 0 || !strings.Contains(determinant.Warnings[0], "singular") {
+		t.Errorf("Expected a zero determinant with a singularity warning, got %+v", determinant)
+	}
+
+	if _, err := mc.Calculate(types.MatrixRequest{Operation: "inverse", Matrix: [][]float64{{1, 2}, {3}}}); err == nil {
+		t.Error("Expected an error for a ragged matrix")
+	}
+}
+
+func TestEigenSolver_SymmetricAndGeneral(t *testing.T) {
+	es := calculator.NewEigenSolver()
+
+	symmetric, err := es.Solve(types.EigenRequest{Matrix: [][]float64{{4, 1, 2}, {1, 3, 0}, {2, 0, 5}}})
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if symmetric.Method != "jacobi" || !symmetric.Converged || symmetric.Iterations == 0 {
+		t.Errorf("Expected a converged Jacobi iteration, got %+v", symmetric)
+	}
+	trace := 0.0
+	for _, pair := range symmetric.Eigenvalues {
+		trace += pair.Real
+		if len(pair.Vector) != 3 {
+			t.Errorf("Expected an eigenvector for %v", pair.Real)
+		}
+	}
+	if math.Abs(trace-12) > 1e-9 || symmetric.Residual > 1e-9 {
+		t.Errorf("Expected eigenvalues summing to the trace 12 with a small residual, got %+v", symmetric)
+	}
+
+	// A rotation of 120° has eigenvalues 1 and -1/2 ± (√3/2)i
+	rotation, err := es.Solve(types.EigenRequest{Matrix: [][]float64{{0, 0, 1}, {1, 0, 0}, {0, 1, 0}}})
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if rotation.Method != "qr" || !rotation.Converged {
+		t.Fatalf("Expected a converged QR iteration, got %+v", rotation)
+	}
+	expected := []types.Eigenpair{{Real: 1}, {Real: -0.5, Imaginary: math.Sqrt(3) / 2}, {Real: -0.5, Imaginary: -math.Sqrt(3) / 2}}
+	for i, pair := range rotation.Eigenvalues {
+		if math.Abs(pair.Real-expected[i].Real) > 1e-9 || math.Abs(pair.Imaginary-expected[i].Imaginary) > 1e-9 {
+			t.Errorf("Eigenvalue %d: expected %+v, got %+v", i, expected[i], pair)
+		}
+	}
+	if rotation.Eigenvalues[0].Vector == nil || rotation.Eigenvalues[1].Vector != nil {
+		t.Errorf("Expected vectors for real eigenvalues only, got %+v", rotation.Eigenvalues)
+	}
+}
+
+func TestEigenSolver_IterationLimit(t *testing.T) {
+	es := calculator.NewEigenSolver()
+	matrix := [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 10}}
+
+	limited, err := es.Solve(types.EigenRequest{Matrix: matrix, MaxIterations: 1})
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if limited.Converged || limited.Iterations != 1 || len(limited.Warnings) == 0 {
+		t.Errorf("Expected an unconverged result after one step, got %+v", limited)
+	}
+
+	if _, err := es.Solve(types.EigenRequest{Matrix: matrix, Method: "jacobi"}); err == nil {
+		t.Error("Expected jacobi to reject a non-symmetric matrix")
+	}
+	if _, err := es.Solve(types.EigenRequest{Matrix: [][]float64{{1, 2}}}); err == nil {
+		t.Error("Expected an error for a non-square matrix")
+	}
+}
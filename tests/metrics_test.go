@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestMetricsRegistry_RecordAndSnapshot(t *testing.T) {
+	registry := mcp.NewMetricsRegistry()
+
+	for i := 1; i <= 100; i++ {
+		registry.Record("basic_math", time.Duration(i)*time.Millisecond, nil)
+	}
+	registry.Record("statistics", 5*time.Millisecond, errors.New("data cannot be empty"))
+
+	snapshot := registry.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("Expected metrics for 2 tools, got %d", len(snapshot))
+	}
+
+	basic := snapshot[0]
+	if basic.Tool != "basic_math" {
+		t.Fatalf("Expected snapshot sorted by tool name, got %s first", basic.Tool)
+	}
+	if basic.Invocations != 100 || basic.Errors != 0 {
+		t.Errorf("Expected 100 invocations and 0 errors, got %d and %d", basic.Invocations, basic.Errors)
+	}
+	if basic.LatencyP50Ms != 50 {
+		t.Errorf("Expected p50 of 50ms, got %f", basic.LatencyP50Ms)
+	}
+	if basic.LatencyP99Ms != 99 {
+		t.Errorf("Expected p99 of 99ms, got %f", basic.LatencyP99Ms)
+	}
+
+	stats := snapshot[1]
+	if stats.Invocations != 1 || stats.Errors != 1 {
+		t.Errorf("Expected 1 invocation and 1 error, got %d and %d", stats.Invocations, stats.Errors)
+	}
+}
+
+func TestMetricsRegistry_Sources(t *testing.T) {
+	registry := mcp.NewMetricsRegistry()
+	calls := 0
+	registry.AddSource("fetcher", func() interface{} {
+		calls++
+		return map[string]int{"requests": calls}
+	})
+
+	sources := registry.Sources()
+	if stats, ok := sources["fetcher"].(map[string]int); !ok || stats["requests"] != 1 {
+		t.Errorf("Expected the fetcher statistics, got %v", sources)
+	}
+	if registry.Sources()["fetcher"].(map[string]int)["requests"] != 2 {
+		t.Error("Expected the source to be read again on every call")
+	}
+}
+
+func TestServer_RecordsToolMetrics(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+
+	calls := []string{
+		`{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}`,
+		`{"name":"basic_math","arguments":{"operation":"divide","operands":[1,0]}}`,
+	}
+	for i, params := range calls {
+		server.HandleRequest(types.MCPRequest{
+			JSONRPC: "2.0",
+			ID:      i,
+			Method:  "tools/call",
+			Params:  json.RawMessage(params),
+		})
+	}
+
+	snapshot := server.Metrics().Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("Expected metrics for 1 tool, got %d", len(snapshot))
+	}
+	if snapshot[0].Invocations != 2 {
+		t.Errorf("Expected 2 invocations, got %d", snapshot[0].Invocations)
+	}
+	if snapshot[0].Errors != 1 {
+		t.Errorf("Expected 1 error, got %d", snapshot[0].Errors)
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func callTool(server *mcp.Server, name, arguments string) types.MCPResponse {
+	return server.HandleRequestContext(context.Background(), types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"` + name + `","arguments":` + arguments + `}`),
+	})
+}
+
+func TestStrictness_CanonicalizesArguments(t *testing.T) {
+	var received map[string]interface{}
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"operation": map[string]interface{}{"type": "string", "enum": []string{"add", "mean"}},
+			"value":     map[string]interface{}{"type": "number"},
+			"data":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}},
+			"unit":      map[string]interface{}{"type": "string"},
+			"label":     map[string]interface{}{"type": "string"},
+		},
+	}
+	server := mcp.NewServer()
+	server.RegisterTool("echo", "Echoes its arguments", schema, func(arguments map[string]interface{}) (interface{}, error) {
+		received = arguments
+		return nil, nil
+	}, mcp.WithValueAliases(calculator.DefaultConversionRegistry().CanonicalUnit, "unit"))
+
+	arguments := `{"operation":" ADD ","value":"2.5","data":["1", 2, " 3e2 "],"unit":"Kilometers","label":"  total "}`
+	if response := callTool(server, "echo", arguments); response.Error != nil {
+		t.Fatalf("Call failed: %v", response.Error)
+	}
+	want := map[string]interface{}{"operation": "add", "value": 2.5, "data": "[1 2 300]", "unit": "km", "label": "total"}
+	for name, value := range want {
+		got := received[name]
+		if name == "data" {
+			got = fmt.Sprint(got)
+		}
+		if got != value {
+			t.Errorf("%s: expected %v, got %#v", name, value, received[name])
+		}
+	}
+
+	// Values that match nothing are only trimmed
+	callTool(server, "echo", `{"operation":"median","value":"two","unit":"furlongs "}`)
+	if received["operation"] != "median" || received["value"] != "two" || received["unit"] != "furlongs" {
+		t.Errorf("Expected unknown values to be kept, got %v", received)
+	}
+
+	server.SetStrictness(mcp.StrictnessStrict)
+	callTool(server, "echo", arguments)
+	if received["operation"] != " ADD " || received["value"] != "2.5" || received["unit"] != "Kilometers" {
+		t.Errorf("Expected strict mode to pass arguments as sent, got %v", received)
+	}
+}
+
+func TestStrictness_TypedTools(t *testing.T) {
+	server := mcp.NewServer()
+	mcp.RegisterTypedTool(server, "basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().BasicMath)
+
+	response := callTool(server, "basic_math", `{"operation":"Multiply","operands":["12", 3.5]}`)
+	if response.Error != nil {
+		t.Fatalf("Call failed: %v", response.Error)
+	}
+	result := response.Result.(types.CallToolResult)
+	if result.IsError || result.Content[0].Text != `{"result":42}` {
+		t.Errorf("Expected 42, got %+v", result)
+	}
+
+	server.SetStrictness(mcp.StrictnessStrict)
+	response = callTool(server, "basic_math", `{"operation":"Multiply","operands":[12, 3.5]}`)
+	if response.Error == nil && !response.Result.(types.CallToolResult).IsError {
+		t.Error("Expected strict mode to reject a misspelled operation")
+	}
+}
+
+func TestConversionRegistry_CanonicalUnit(t *testing.T) {
+	registry := calculator.DefaultConversionRegistry()
+	tests := map[string]string{
+		"km":         "km",
+		"KM":         "km",
+		"Kilometres": "km",
+		" miles ":    "mi",
+		"°C":         "C",
+		"celsius":    "C",
+		"f":          "F",
+		"lbs":        "lb",
+		"sq ft":      "ft2",
+		"um":         "μm",
+	}
+	for unit, want := range tests {
+		if got, ok := registry.CanonicalUnit(unit); !ok || got != want {
+			t.Errorf("CanonicalUnit(%q): expected %q, got %q %v", unit, want, got, ok)
+		}
+	}
+	if got, ok := registry.CanonicalUnit("furlong"); ok {
+		t.Errorf("Expected an unknown unit to be reported, got %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+)
+
+func TestFormatEngineering(t *testing.T) {
+	tests := []struct {
+		value    float64
+		symbol   string
+		expected string
+	}{
+		{0.000047, "F", "47 μF"},
+		{4700, "Ω", "4.7 kΩ"},
+		{2.2e9, "Hz", "2.2 GHz"},
+		{-0.0015, "A", "-1.5 mA"},
+		{999.99, "V", "1 kV"},
+		{12.345678, "", "12.35"},
+		{0, "F", "0 F"},
+		{1e30, "m", "1e30 m"},
+	}
+
+	for _, tt := range tests {
+		if got := calculator.FormatEngineering(tt.value, tt.symbol); got != tt.expected {
+			t.Errorf("FormatEngineering(%g, %q) = %q, expected %q", tt.value, tt.symbol, got, tt.expected)
+		}
+	}
+}
+
+func TestMathHandler_EngineeringOutput(t *testing.T) {
+	handler := handlers.NewMathHandler()
+
+	result, err := handler.HandleBasicMath(map[string]interface{}{
+		"operation": "divide",
+		"operands":  []interface{}{1.0, 21276.6},
+		"precision": 9.0,
+		"format":    "engineering",
+		"symbol":    "F",
+	})
+	if err != nil {
+		t.Fatalf("HandleBasicMath failed: %v", err)
+	}
+	if formatted := result.(types.CalculationResult).Formatted; formatted != "47 μF" {
+		t.Errorf("Expected 47 μF, got %q", formatted)
+	}
+
+	conversion, err := handler.HandleUnitConversion(map[string]interface{}{
+		"value":    0.047,
+		"fromUnit": "km",
+		"toUnit":   "km",
+		"category": "length",
+		"format":   "engineering",
+	})
+	if err != nil {
+		t.Fatalf("HandleUnitConversion failed: %v", err)
+	}
+	if formatted := conversion.(map[string]interface{})["formatted"]; formatted != "47 m" {
+		t.Errorf("Expected prefixed units to be reduced to 47 m, got %v", formatted)
+	}
+
+	if _, err := handler.HandleBasicMath(map[string]interface{}{
+		"operation": "add",
+		"operands":  []interface{}{1.0, 2.0},
+		"format":    "scientific",
+	}); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestServer_NotifiesToolListChanges(t *testing.T) {
+	server := mcp.NewServer()
+
+	var mu sync.Mutex
+	var received []types.MCPNotification
+	unsubscribe := server.Subscribe(func(notification types.MCPNotification) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, notification)
+	})
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received)
+	}
+
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath, mcp.WithGroup("math"))
+	if count() != 1 || received[0].Method != mcp.NotificationToolListChanged || received[0].JSONRPC != "2.0" {
+		t.Fatalf("Expected one tools/list_changed notification on registration, got %+v", received)
+	}
+
+	server.SetToolGroupEnabled("math", false)
+	server.SetToolGroupEnabled("math", false)
+	if count() != 2 {
+		t.Errorf("Expected disabling a group to notify once, got %d notifications", count())
+	}
+	server.SetToolGroupEnabled("math", true)
+
+	if !server.UnregisterTool("basic_math") || server.UnregisterTool("basic_math") {
+		t.Error("Expected only the first unregistration to find the tool")
+	}
+	if count() != 4 {
+		t.Errorf("Expected enabling and unregistering to notify, got %d notifications", count())
+	}
+	response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: []byte(`{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}`)})
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeMethodNotFound {
+		t.Errorf("Expected the unregistered tool to be gone, got %+v", response)
+	}
+
+	unsubscribe()
+	server.Notify("notifications/message", map[string]string{"level": "info"})
+	if count() != 4 {
+		t.Errorf("Expected no notifications after unsubscribing, got %d", count())
+	}
+}
+
+func TestStreamableHTTPTransport_BroadcastsNotificationsOnGETStream(t *testing.T) {
+	server := mcp.NewServer()
+	httpConfig := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8093,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, httpConfig)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("http://127.0.0.1:%d/mcp", httpConfig.Port), nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	waitForLine := func(want string) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("Stream closed before %q", want)
+				}
+				if strings.Contains(line, want) {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("Timed out waiting for %q", want)
+			}
+		}
+	}
+	// The stream is subscribed once the connection event arrives
+	waitForLine(`"type":"connected"`)
+
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+	waitForLine(`"method":"notifications/tools/list_changed"`)
+}
+
+func TestServer_NotificationsGetNoResponse(t *testing.T) {
+	server := mcp.NewServer()
+	calls := 0
+	server.RegisterTool("counter", "Counts calls", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		calls++
+		return map[string]interface{}{"result": calls}, nil
+	})
+
+	for _, req := range []types.MCPRequest{
+		{JSONRPC: "2.0", Method: mcp.NotificationInitialized},
+		{JSONRPC: "2.0", Method: "notifications/unknown"},
+		{JSONRPC: "2.0", Method: "tools/call", Params: []byte(`{"name":"counter","arguments":{}}`)},
+	} {
+		if !req.IsNotification() {
+			t.Fatalf("Expected %s without an ID to be a notification", req.Method)
+		}
+		if response := server.HandleRequestContext(context.Background(), req); response.JSONRPC != "" || response.Result != nil || response.Error != nil {
+			t.Errorf("Expected no response to %s, got %+v", req.Method, response)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("Expected a tools/call notification not to run the tool, ran %d times", calls)
+	}
+}
+
+func TestStreamableHTTPTransport_AcceptsNotificationsWithoutResponse(t *testing.T) {
+	server := mcp.NewServer()
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8095,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	endpoint := fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port)
+
+	// Opening an SSE stream creates the session
+	req, _ := http.NewRequest("GET", endpoint, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open SSE stream: %v", err)
+	}
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	resp.Body.Close()
+	if sessionID == "" {
+		t.Fatal("Expected a session ID")
+	}
+
+	post := func(body string) (*http.Response, string) {
+		req, _ := http.NewRequest("POST", endpoint, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var text strings.Builder
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			text.WriteString(scanner.Text())
+		}
+		return resp, text.String()
+	}
+
+	for _, notification := range []string{
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"missing","arguments":{}}}`,
+	} {
+		resp, body := post(notification)
+		if resp.StatusCode != http.StatusAccepted || body != "" {
+			t.Errorf("Expected 202 with no body for %s, got %d %q", notification, resp.StatusCode, body)
+		}
+	}
+
+	_, body := post(`{"jsonrpc":"2.0","id":2,"method":"session/stats"}`)
+	if !strings.Contains(body, `"initialized":true`) {
+		t.Errorf("Expected the session to be initialized, got %s", body)
+	}
+}
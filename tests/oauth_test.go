@@ -0,0 +1,345 @@
+package tests
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+// testAuthorizationServer publishes its metadata and the public keys of an
+// RSA and a P-256 signing key, and issues tokens signed with them
+type testAuthorizationServer struct {
+	*httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+
+	jwksFetches atomic.Int32 // Requests for the keys
+	jwksDelay   time.Duration
+	jwksDown    atomic.Bool // Answer requests for the keys with 503
+}
+
+func newTestAuthorizationServer(t *testing.T) *testAuthorizationServer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	as := &testAuthorizationServer{rsaKey: rsaKey, ecKey: ecKey}
+
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": as.URL, "jwks_uri": as.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		as.jwksFetches.Add(1)
+		time.Sleep(as.jwksDelay)
+		if as.jwksDown.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+		}})
+	})
+	as.Server = httptest.NewServer(mux)
+	return as
+}
+
+// token signs claims with the RSA key (RS256) or the EC key (ES256)
+func (as *testAuthorizationServer) token(t *testing.T, algorithm string, claims map[string]interface{}) string {
+	return as.tokenWithKeyID(t, algorithm, map[string]string{"RS256": "rsa-1", "ES256": "ec-1"}[algorithm], claims)
+}
+
+// tokenWithKeyID signs claims like token, naming any key ID in the header
+func (as *testAuthorizationServer) tokenWithKeyID(t *testing.T, algorithm, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	if algorithm == "RS256" {
+		signature, err = rsa.SignPKCS1v15(rand.Reader, as.rsaKey, crypto.SHA256, digest[:])
+	} else {
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, as.ecKey, digest[:])
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestStreamableHTTP_OAuth(t *testing.T) {
+	as := newTestAuthorizationServer(t)
+	defer as.Close()
+
+	resourceURL := "http://127.0.0.1:8108/mcp"
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8108,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		OAuth:          &mcp.OAuthConfig{ResourceURL: resourceURL, Issuer: as.URL},
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	// The metadata names the authorization server, without a token
+	resp, err := http.Get("http://127.0.0.1:8108/.well-known/oauth-protected-resource/mcp")
+	if err != nil {
+		t.Fatalf("Metadata request failed: %v", err)
+	}
+	var metadata map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&metadata)
+	resp.Body.Close()
+	if metadata["resource"] != resourceURL || fmt.Sprint(metadata["authorization_servers"]) != "["+as.URL+"]" {
+		t.Errorf("Unexpected protected resource metadata %v", metadata)
+	}
+
+	post := func(token string) (int, string, *types.MCPError) {
+		req, _ := http.NewRequest("POST", resourceURL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var response types.MCPResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, resp.Header.Get("WWW-Authenticate"), response.Error
+	}
+
+	status, challenge, mcpErr := post("")
+	if status != http.StatusUnauthorized || mcpErr == nil || mcpErr.Code != mcp.ErrorCodeAuthenticationRequired {
+		t.Errorf("Expected 401 authentication required without a token, got %d %+v", status, mcpErr)
+	}
+	if challenge != `Bearer resource_metadata="http://127.0.0.1:8108/.well-known/oauth-protected-resource/mcp"` {
+		t.Errorf("Unexpected challenge %q", challenge)
+	}
+
+	now := time.Now().Unix()
+	valid := map[string]interface{}{"iss": as.URL, "sub": "user-1", "aud": resourceURL, "exp": now + 300}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := make(map[string]interface{})
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	for _, algorithm := range []string{"RS256", "ES256"} {
+		if status, _, mcpErr := post(as.token(t, algorithm, valid)); status != http.StatusOK || mcpErr != nil {
+			t.Errorf("Expected a valid %s token to be accepted, got %d %+v", algorithm, status, mcpErr)
+		}
+	}
+	if status, _, _ := post(as.token(t, "RS256", with("aud", []string{"other", resourceURL}))); status != http.StatusOK {
+		t.Errorf("Expected an audience list naming the resource to be accepted, got %d", status)
+	}
+
+	// Tampered copies of a valid token
+	parts := strings.Split(as.token(t, "RS256", valid), ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"`+as.URL+`","aud":"`+resourceURL+`","exp":9999999999}`)) + "." + parts[2]
+	symmetric := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","kid":"rsa-1"}`)) + "." + parts[1] + "." + parts[2]
+
+	rejected := map[string]struct {
+		token string
+		code  int
+	}{
+		"expired":             {as.token(t, "RS256", with("exp", now-600)), mcp.ErrorCodeTokenExpired},
+		"wrong audience":      {as.token(t, "RS256", with("aud", "https://other.example.com/mcp")), mcp.ErrorCodeTokenInvalid},
+		"wrong issuer":        {as.token(t, "ES256", with("iss", "https://evil.example.com")), mcp.ErrorCodeTokenInvalid},
+		"not yet valid":       {as.token(t, "RS256", with("nbf", now+600)), mcp.ErrorCodeTokenInvalid},
+		"bad signature":       {forged, mcp.ErrorCodeTokenInvalid},
+		"not a JWT":           {"opaque-token", mcp.ErrorCodeTokenInvalid},
+		"symmetric algorithm": {symmetric, mcp.ErrorCodeTokenInvalid},
+	}
+	for name, test := range rejected {
+		status, challenge, mcpErr := post(test.token)
+		if status != http.StatusUnauthorized || mcpErr == nil || mcpErr.Code != test.code {
+			t.Errorf("%s: expected 401 with code %d, got %d %+v", name, test.code, status, mcpErr)
+		}
+		if !strings.Contains(challenge, `error="invalid_token"`) {
+			t.Errorf("%s: expected an invalid_token challenge, got %q", name, challenge)
+		}
+	}
+}
+
+func TestStreamableHTTP_OAuthKeyFetching(t *testing.T) {
+	as := newTestAuthorizationServer(t)
+	defer as.Close()
+	as.jwksDelay = 200 * time.Millisecond
+
+	start := func(port int) *mcp.StreamableHTTPTransport {
+		httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+			Host:           "127.0.0.1",
+			Port:           port,
+			SessionTimeout: 5 * time.Minute,
+			MaxConnections: 100,
+			OAuth:          &mcp.OAuthConfig{ResourceURL: fmt.Sprintf("http://127.0.0.1:%d/mcp", port), Issuer: as.URL, JWKSURL: as.URL + "/jwks"},
+		})
+		go httpTransport.Start()
+		return httpTransport
+	}
+	transports := []*mcp.StreamableHTTPTransport{start(8129), start(8130)}
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, httpTransport := range transports {
+			httpTransport.Stop(shutdownCtx)
+		}
+	}()
+
+	post := func(port int, token string) int {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", port), strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	claims := func(port int) map[string]interface{} {
+		return map[string]interface{}{"iss": as.URL, "aud": fmt.Sprintf("http://127.0.0.1:%d/mcp", port), "exp": time.Now().Unix() + 300}
+	}
+
+	// Requests arriving while the keys are fetched wait for that fetch
+	token := as.token(t, "RS256", claims(8129))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status := post(8129, token); status != http.StatusOK {
+				t.Errorf("Expected the token to be accepted, got %d", status)
+			}
+		}()
+	}
+	wg.Wait()
+	if fetches := as.jwksFetches.Load(); fetches != 1 {
+		t.Errorf("Expected one fetch of the keys, got %d", fetches)
+	}
+
+	// Unknown key IDs do not refetch the keys on every request, and the
+	// known keys stay in use
+	for i := 0; i < 3; i++ {
+		if status := post(8129, as.tokenWithKeyID(t, "RS256", fmt.Sprintf("made-up-%d", i), claims(8129))); status != http.StatusUnauthorized {
+			t.Errorf("Expected a token signed with an unknown key to be rejected, got %d", status)
+		}
+	}
+	if status := post(8129, token); status != http.StatusOK {
+		t.Errorf("Expected the known key to stay in use, got %d", status)
+	}
+	if fetches := as.jwksFetches.Load(); fetches != 1 {
+		t.Errorf("Expected no refetch right after a fetch, got %d fetches", fetches)
+	}
+
+	// A failed first fetch is not retried by every request either
+	as.jwksDown.Store(true)
+	for i := 0; i < 3; i++ {
+		if status := post(8130, as.token(t, "RS256", claims(8130))); status != http.StatusUnauthorized {
+			t.Errorf("Expected a token to be refused without keys, got %d", status)
+		}
+	}
+	if fetches := as.jwksFetches.Load(); fetches != 2 {
+		t.Errorf("Expected one failed fetch, got %d", fetches-1)
+	}
+}
+
+func TestStreamableHTTP_OAuthMetadataUnderBasePath(t *testing.T) {
+	as := newTestAuthorizationServer(t)
+	defer as.Close()
+
+	resourceURL := "http://127.0.0.1:8133/calc/mcp"
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8133,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		BasePath:       "/calc",
+		OAuth:          &mcp.OAuthConfig{ResourceURL: resourceURL, Issuer: as.URL},
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	// The challenge points outside the base path, where the metadata is served
+	resp, err := http.Post(resourceURL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	challenge := resp.Header.Get("WWW-Authenticate")
+	metadataURL := "http://127.0.0.1:8133/.well-known/oauth-protected-resource/calc/mcp"
+	if resp.StatusCode != http.StatusUnauthorized || challenge != `Bearer resource_metadata="`+metadataURL+`"` {
+		t.Fatalf("Expected a challenge naming %s, got %d %q", metadataURL, resp.StatusCode, challenge)
+	}
+
+	resp, err = http.Get(metadataURL)
+	if err != nil {
+		t.Fatalf("Metadata request failed: %v", err)
+	}
+	var metadata map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&metadata)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || metadata["resource"] != resourceURL {
+		t.Errorf("Expected the metadata at %s, got %d %v", metadataURL, resp.StatusCode, metadata)
+	}
+
+	// Other paths outside the base path stay unknown
+	resp, err = http.Get("http://127.0.0.1:8133/mcp")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected /mcp outside the base path to be unknown, got %d", resp.StatusCode)
+	}
+}
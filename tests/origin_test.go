@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_RejectsDisallowedOrigins(t *testing.T) {
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8125,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		CORSEnabled:    true,
+		CORSOrigins:    []string{"https://cors.example.com"},
+		AllowedOrigins: []string{"https://app.example.com", "http://localhost"},
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	initialize := func(origin string) int {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8125/mcp", strings.NewReader(
+			`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		origin string
+		status int
+	}{
+		{"", http.StatusOK},                         // Not a browser
+		{"https://app.example.com", http.StatusOK},  // Listed
+		{"https://APP.example.com", http.StatusOK},  // Host names ignore case
+		{"http://localhost:5173", http.StatusOK},    // Any port of a host listed without one
+		{"https://cors.example.com", http.StatusOK}, // A CORS origin
+		{"http://app.example.com", http.StatusForbidden},
+		{"https://app.example.com.evil.test", http.StatusForbidden},
+		{"http://evil.example.com", http.StatusForbidden},
+		{"http://127.0.0.1:8125", http.StatusForbidden}, // A rebound host looks same-origin, so it is not trusted
+		{"null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if status := initialize(tt.origin); status != tt.status {
+			t.Errorf("Origin %q: expected %d, got %d", tt.origin, tt.status, status)
+		}
+	}
+
+	// Preflights from disallowed origins are rejected too
+	req, _ := http.NewRequest("OPTIONS", "http://127.0.0.1:8125/mcp", nil)
+	req.Header.Set("Origin", "http://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Preflight failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("Expected the preflight to be forbidden, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTP_DefaultAllowedOrigins(t *testing.T) {
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8126,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	for origin, forbidden := range map[string]bool{
+		"http://localhost:3000":   false,
+		"https://127.0.0.1":       false,
+		"http://[::1]:8080":       false,
+		"https://app.example.com": true,
+	} {
+		req, _ := http.NewRequest("GET", "http://127.0.0.1:8126/jobs", nil)
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if (resp.StatusCode == http.StatusForbidden) != forbidden {
+			t.Errorf("Origin %q: expected forbidden %v, got %d", origin, forbidden, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestServer_StreamsAmortizationScheduleAsPartialResults(t *testing.T) {
+	server := mcp.NewServer()
+	financeHandler := handlers.NewFinanceHandler()
+	server.RegisterContextTool("amortization_schedule", "Amortization schedule", map[string]interface{}{"type": "object"}, financeHandler.HandleAmortizationSchedule)
+
+	var notifications []types.MCPNotification
+	ctx := mcp.WithNotifier(context.Background(), func(n types.MCPNotification) {
+		notifications = append(notifications, n)
+	})
+
+	response := server.HandleRequestContext(ctx, types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: json.RawMessage(`{"name":"amortization_schedule","arguments":{"principal":10000,"rate":6,"time":3},` +
+			`"_meta":{"progressToken":"loan-1"}}`),
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+
+	// Every chunk is followed by a progress report counting the rows sent
+	if len(notifications) != 6 {
+		t.Fatalf("Expected 3 yearly chunks and their progress, got %d", len(notifications))
+	}
+	for i := 0; i < len(notifications); i += 2 {
+		chunk, progress := notifications[i], notifications[i+1]
+		params := chunk.Params.(types.PartialResultParams)
+		if chunk.Method != "notifications/partial_result" || params.ProgressToken != "loan-1" || params.Index != i/2 {
+			t.Errorf("Unexpected notification %d: %s %+v", i, chunk.Method, params)
+		}
+		reported, ok := progress.Params.(types.ProgressParams)
+		if !ok || reported.Progress != float64(12*(i/2+1)) || reported.Total == nil || *reported.Total != 36 {
+			t.Errorf("Unexpected progress %d: %s %+v", i+1, progress.Method, progress.Params)
+		}
+	}
+
+	var result map[string]interface{}
+	text := response.Result.(types.CallToolResult).Content[0].Text
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Invalid result: %v", err)
+	}
+	if _, hasSchedule := result["schedule"]; hasSchedule {
+		t.Error("Expected the streamed schedule to be left out of the final result")
+	}
+	if result["payments"] != 36.0 {
+		t.Errorf("Expected 36 payments, got %v", result["payments"])
+	}
+}
+
+func TestServer_AmortizationScheduleWithoutProgressToken(t *testing.T) {
+	server := mcp.NewServer()
+	financeHandler := handlers.NewFinanceHandler()
+	server.RegisterContextTool("amortization_schedule", "Amortization schedule", map[string]interface{}{"type": "object"}, financeHandler.HandleAmortizationSchedule)
+
+	notified := false
+	ctx := mcp.WithNotifier(context.Background(), func(types.MCPNotification) { notified = true })
+
+	response := server.HandleRequestContext(ctx, types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"amortization_schedule","arguments":{"principal":1200,"rate":5,"time":1}}`),
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+	if notified {
+		t.Error("Expected no partial results without a progress token")
+	}
+
+	var result map[string]interface{}
+	json.Unmarshal([]byte(response.Result.(types.CallToolResult).Content[0].Text), &result)
+	if schedule, ok := result["schedule"].([]interface{}); !ok || len(schedule) != 12 {
+		t.Errorf("Expected the full 12 row schedule in the result, got %v", result["schedule"])
+	}
+}
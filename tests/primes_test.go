@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+)
+
+func TestPrimeCalculator_Prime(t *testing.T) {
+	calc := calculator.NewPrimeCalculator()
+
+	testCases := []struct {
+		name     string
+		request  types.PrimeRequest
+		expected int64
+	}{
+		{"First prime", types.PrimeRequest{Operation: "nth", N: 1}, 2},
+		{"Second prime", types.PrimeRequest{Operation: "nth", N: 2}, 3},
+		{"Sixth prime", types.PrimeRequest{Operation: "nth", N: 6}, 13},
+		{"Hundredth prime", types.PrimeRequest{Operation: "nth", N: 100}, 541},
+		{"Millionth prime", types.PrimeRequest{Operation: "nth", N: 1000000}, 15485863},
+		{"Next after zero", types.PrimeRequest{Operation: "next", Value: 0}, 2},
+		{"Next after a prime", types.PrimeRequest{Operation: "next", Value: 13}, 17},
+		{"Next across a gap", types.PrimeRequest{Operation: "next", Value: 1327}, 1361},
+		{"Next beyond the value limit", types.PrimeRequest{Operation: "next", Value: 1000000000000}, 1000000000039},
+		{"Previous of three", types.PrimeRequest{Operation: "previous", Value: 3}, 2},
+		{"Previous of a prime", types.PrimeRequest{Operation: "previous", Value: 17}, 13},
+		{"Previous below the value limit", types.PrimeRequest{Operation: "previous", Value: 1000000000000}, 999999999989},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Calculate(context.Background(), tc.request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Prime == nil || *result.Prime != tc.expected {
+				t.Errorf("Expected %d, got %+v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestPrimeCalculator_Count(t *testing.T) {
+	calc := calculator.NewPrimeCalculator()
+
+	testCases := []struct {
+		n        int64
+		expected int64
+	}{
+		{0, 0}, {2, 0}, {3, 1}, {10, 4}, {11, 4}, {100, 25}, {1000000, 78498}, {10000000, 664579},
+	}
+
+	for _, tc := range testCases {
+		result, err := calc.Calculate(context.Background(), types.PrimeRequest{Operation: "count", N: tc.n})
+		if err != nil {
+			t.Fatalf("Unexpected error for n=%d: %v", tc.n, err)
+		}
+		if result.Count == nil || *result.Count != tc.expected {
+			t.Errorf("Expected %d primes below %d, got %+v", tc.expected, tc.n, result)
+		}
+	}
+}
+
+func TestPrimeCalculator_Range(t *testing.T) {
+	calc := calculator.NewPrimeCalculator()
+
+	result, err := calc.Calculate(context.Background(), types.PrimeRequest{Operation: "range", From: 0, To: 30})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}
+	if !reflect.DeepEqual(result.Primes, expected) || *result.Count != 10 || result.Truncated {
+		t.Errorf("Expected %v, got %+v", expected, result)
+	}
+
+	// A range straddling several segments far from zero
+	result, err = calc.Calculate(context.Background(), types.PrimeRequest{Operation: "range", From: 1000000000, To: 1000200000, Limit: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = []int64{1000000007, 1000000009, 1000000021}
+	if !reflect.DeepEqual(result.Primes, expected) || !result.Truncated {
+		t.Errorf("Expected %v truncated, got %+v", expected, result)
+	}
+	all, err := calc.Calculate(context.Background(), types.PrimeRequest{Operation: "range", From: 1000000000, To: 1000200000, Limit: 100000})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if int64(len(all.Primes)) != *all.Count || *all.Count != *result.Count || all.Truncated {
+		t.Errorf("Expected every prime listed, got %d of %d", len(all.Primes), *all.Count)
+	}
+	for i := 1; i < len(all.Primes); i++ {
+		if all.Primes[i] <= all.Primes[i-1] {
+			t.Fatalf("Primes out of order at %d", i)
+		}
+	}
+
+	// A prime square is composite
+	result, err = calc.Calculate(context.Background(), types.PrimeRequest{Operation: "range", From: 24, To: 28})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Primes) != 0 || *result.Count != 0 {
+		t.Errorf("Expected no primes between 24 and 28, got %+v", result)
+	}
+}
+
+func TestPrimeCalculator_Errors(t *testing.T) {
+	calc := calculator.NewPrimeCalculator()
+
+	testCases := []struct {
+		name    string
+		request types.PrimeRequest
+		message string
+	}{
+		{"Unknown operation", types.PrimeRequest{Operation: "factor"}, "unsupported prime operation"},
+		{"Zeroth prime", types.PrimeRequest{Operation: "nth", N: 0}, "n must be between 1"},
+		{"Prime index too large", types.PrimeRequest{Operation: "nth", N: 50000001}, "n must be between 1"},
+		{"Count too large", types.PrimeRequest{Operation: "count", N: 1000000001}, "n must be between 0"},
+		{"Reversed range", types.PrimeRequest{Operation: "range", From: 10, To: 5}, "must not be less than"},
+		{"Range too wide", types.PrimeRequest{Operation: "range", From: 0, To: 100000001}, "span at most"},
+		{"Limit too large", types.PrimeRequest{Operation: "range", From: 0, To: 10, Limit: 100001}, "limit must be between"},
+		{"Value too large", types.PrimeRequest{Operation: "next", Value: 1000000000001}, "value must be between"},
+		{"Nothing before two", types.PrimeRequest{Operation: "previous", Value: 2}, "no prime less than 2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := calc.Calculate(context.Background(), tc.request)
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Expected an error containing %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestMathHandler_Primes(t *testing.T) {
+	handler := handlers.NewMathHandler()
+
+	result, err := handler.HandlePrimes(context.Background(), map[string]interface{}{"operation": "next", "value": 100.0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	primes, ok := result.(types.PrimeResult)
+	if !ok || primes.Prime == nil || *primes.Prime != 101 {
+		t.Errorf("Unexpected primes result: %+v", result)
+	}
+
+	if _, err := handler.HandlePrimes(context.Background(), map[string]interface{}{"operation": "nth", "n": 2.5}); err == nil {
+		t.Error("Expected an error for a fractional n")
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestProbabilityCalculator_Events(t *testing.T) {
+	pc := calculator.NewProbabilityCalculator()
+
+	a, b := 0.5, 0.4
+	independent, err := pc.Calculate(types.ProbabilityRequest{Operation: "independent_events", ProbabilityA: &a, ProbabilityB: &b})
+	if err != nil {
+		t.Fatalf("independent_events failed: %v", err)
+	}
+	expected := map[string]float64{"a_and_b": 0.2, "a_or_b": 0.7, "neither": 0.3, "exactly_one": 0.5, "a_given_b": 0.5}
+	for name, value := range expected {
+		if math.Abs(independent.Values[name]-value) > 1e-12 {
+			t.Errorf("Expected %s = %v, got %v", name, value, independent.Values[name])
+		}
+	}
+
+	// A test with 99% sensitivity for a 1% condition that is positive 5.94% of the time
+	prevalence, positive, sensitivity := 0.01, 0.0594, 0.99
+	conditional, err := pc.Calculate(types.ProbabilityRequest{Operation: "conditional", ProbabilityA: &prevalence, ProbabilityB: &positive, ProbabilityBGivenA: &sensitivity})
+	if err != nil {
+		t.Fatalf("conditional failed: %v", err)
+	}
+	if math.Abs(conditional.Values["a_given_b"]-1.0/6) > 1e-12 {
+		t.Errorf("Expected P(condition | positive) = 1/6, got %v", conditional.Values["a_given_b"])
+	}
+
+	impossible := 0.001
+	if _, err := pc.Calculate(types.ProbabilityRequest{Operation: "conditional", ProbabilityA: &prevalence, ProbabilityB: &impossible, ProbabilityBGivenA: &sensitivity}); err == nil {
+		t.Error("Expected P(A and B) > P(B) to be rejected")
+	}
+	invalid := 1.5
+	if _, err := pc.Calculate(types.ProbabilityRequest{Operation: "independent_events", ProbabilityA: &invalid, ProbabilityB: &b}); err == nil {
+		t.Error("Expected a probability above 1 to be rejected")
+	}
+}
+
+func TestProbabilityCalculator_Distributions(t *testing.T) {
+	pc := calculator.NewProbabilityCalculator()
+
+	tests := []struct {
+		trials, successes int
+		probability       float64
+		atLeast           float64
+	}{
+		{3, 2, 0.5, 0.5},
+		{10, 0, 0.3, 1},
+		{4, 4, 1, 1},
+		{4, 1, 0, 0},
+		{100, 90, 0.5, 1.5316450877189926e-17},
+	}
+	for _, tt := range tests {
+		p := tt.probability
+		result, err := pc.Calculate(types.ProbabilityRequest{Operation: "binomial", Trials: tt.trials, Successes: tt.successes, Probability: &p})
+		if err != nil {
+			t.Fatalf("binomial failed: %v", err)
+		}
+		if math.Abs(result.Result-tt.atLeast) > 1e-9*tt.atLeast {
+			t.Errorf("P(X ≥ %d) for n=%d, p=%v: expected %v, got %v", tt.successes, tt.trials, tt.probability, tt.atLeast, result.Result)
+		}
+	}
+
+	birthday, err := pc.Calculate(types.ProbabilityRequest{Operation: "birthday", People: 23})
+	if err != nil {
+		t.Fatalf("birthday failed: %v", err)
+	}
+	if math.Abs(birthday.Result-0.507297) > 1e-6 || birthday.Values["people_for_even_odds"] != 23 {
+		t.Errorf("Expected 50.73%% for 23 people, got %+v", birthday)
+	}
+	crowded, err := pc.Calculate(types.ProbabilityRequest{Operation: "birthday", People: 5, Days: 4})
+	if err != nil || crowded.Result != 1 {
+		t.Errorf("Expected a certain match with more people than days, got %+v, %v", crowded, err)
+	}
+
+	die, err := pc.Calculate(types.ProbabilityRequest{
+		Operation:     "expected_value",
+		Outcomes:      []float64{1, 2, 3, 4, 5, 6},
+		Probabilities: []float64{1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6},
+	})
+	if err != nil {
+		t.Fatalf("expected_value failed: %v", err)
+	}
+	if math.Abs(die.Result-3.5) > 1e-12 || math.Abs(die.Values["variance"]-35.0/12) > 1e-12 {
+		t.Errorf("Expected a fair die to have mean 3.5 and variance 35/12, got %+v", die)
+	}
+	if _, err := pc.Calculate(types.ProbabilityRequest{Operation: "expected_value", Outcomes: []float64{0, 1}, Probabilities: []float64{0.5, 0.4}}); err == nil {
+		t.Error("Expected probabilities not summing to 1 to be rejected")
+	}
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestServer_ReportsSelfCheckProgress(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	financeHandler := handlers.NewFinanceHandler()
+	server.RegisterTool("basic_math", "Basic math", getBasicMathSchema(), mathHandler.HandleBasicMath,
+		mcp.WithSelfCheck(map[string]interface{}{"operation": "add", "operands": []interface{}{2.0, 3.0}}, map[string]interface{}{"result": 5}))
+	server.RegisterContextTool("amortization_schedule", "Amortization schedule", map[string]interface{}{"type": "object"}, financeHandler.HandleAmortizationSchedule,
+		mcp.WithSelfCheck(map[string]interface{}{"principal": 1200.0, "rate": 6.0, "time": 1.0}, map[string]interface{}{"payments": 12}))
+	server.RegisterContextTool("self_check", "Self-check", map[string]interface{}{"type": "object"}, server.HandleSelfCheck)
+
+	var notifications []types.MCPNotification
+	ctx := mcp.WithNotifier(context.Background(), func(n types.MCPNotification) {
+		notifications = append(notifications, n)
+	})
+
+	response := server.HandleRequestContext(ctx, types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: json.RawMessage(`{"name":"self_check","arguments":{"tools":["basic_math","amortization_schedule"]},` +
+			`"_meta":{"progressToken":"check-1"}}`),
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+
+	// The amortization schedule run by the check must not stream to the client
+	if len(notifications) != 2 {
+		t.Fatalf("Expected one progress notification per tool, got %d: %+v", len(notifications), notifications)
+	}
+	for i, n := range notifications {
+		params, ok := n.Params.(types.ProgressParams)
+		if !ok || n.Method != "notifications/progress" || params.ProgressToken != "check-1" {
+			t.Fatalf("Unexpected notification %d: %s %+v", i, n.Method, n.Params)
+		}
+		if params.Progress != float64(i+1) || params.Total == nil || *params.Total != 2 {
+			t.Errorf("Expected progress %d of 2, got %+v", i+1, params)
+		}
+	}
+	if message := notifications[0].Params.(types.ProgressParams).Message; message != "amortization_schedule: pass" {
+		t.Errorf("Expected the first tool's status in the message, got %q", message)
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	// Without a progress token reports are dropped
+	mcp.ReportProgress(context.Background(), 1, 2, "ignored")
+	if mcp.ReportsProgress(context.Background()) {
+		t.Error("Expected no progress reporting without a progress token")
+	}
+
+	server := mcp.NewServer()
+	var reported []types.ProgressParams
+	server.RegisterContextTool("work", "Work", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+		if !mcp.ReportsProgress(ctx) {
+			t.Error("Expected progress reporting to be enabled")
+		}
+		mcp.ReportProgress(ctx, 0.5, 0, "halfway")
+		mcp.ReportProgress(ctx, 0.5, 0, "repeated")
+		mcp.ReportProgress(ctx, 0.25, 0, "backwards")
+		mcp.ReportProgress(ctx, 1, 0, "")
+		return map[string]interface{}{"done": true}, nil
+	})
+	ctx := mcp.WithNotifier(context.Background(), func(n types.MCPNotification) {
+		reported = append(reported, n.Params.(types.ProgressParams))
+	})
+
+	response := server.HandleRequestContext(ctx, types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"work","arguments":{},"_meta":{"progressToken":"w"}}`),
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+	if len(reported) != 2 || reported[0].Message != "halfway" || reported[1].Progress != 1 || reported[1].Total != nil {
+		t.Errorf("Expected only increasing progress without a total, got %+v", reported)
+	}
+}
+
+func TestServer_SummationReportsProgress(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	server.RegisterContextTool("summation", "Summation", map[string]interface{}{"type": "object"}, mathHandler.HandleSummation)
+
+	var reported []types.ProgressParams
+	ctx := mcp.WithNotifier(context.Background(), func(n types.MCPNotification) {
+		reported = append(reported, n.Params.(types.ProgressParams))
+	})
+
+	response := server.HandleRequestContext(ctx, types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params: json.RawMessage(`{"name":"summation","arguments":{"operation":"sum","expression":"k","from":1,"to":10000,"closed_form":false},` +
+			`"_meta":{"progressToken":"sum-1"}}`),
+	})
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error)
+	}
+
+	// Terms are evaluated one by one and reported every 4096 terms
+	if len(reported) != 2 {
+		t.Fatalf("Expected two progress notifications, got %+v", reported)
+	}
+	for i, params := range reported {
+		if params.ProgressToken != "sum-1" || params.Progress != float64(4096*(i+1)) || params.Total == nil || *params.Total != 10000 {
+			t.Errorf("Unexpected progress %d: %+v", i, params)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func newPromptServer() *mcp.Server {
+	server := mcp.NewServer()
+	promptHandler := handlers.NewPromptHandler()
+	server.RegisterPrompt("summarize_data", "Summarize a data set", []types.PromptArgument{
+		{Name: "data", Required: true},
+		{Name: "context"},
+	}, promptHandler.HandleSummarizeData)
+	server.RegisterPrompt("loan_amortization", "Amortize a loan", []types.PromptArgument{
+		{Name: "principal", Required: true},
+		{Name: "rate", Required: true},
+		{Name: "years", Required: true},
+		{Name: "periods"},
+	}, promptHandler.HandleLoanAmortization)
+	return server
+}
+
+func getPrompt(server *mcp.Server, params string) types.MCPResponse {
+	return server.HandleRequestContext(context.Background(), types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "prompts/get",
+		Params:  json.RawMessage(params),
+	})
+}
+
+func TestPrompts_ListAndCapability(t *testing.T) {
+	server := newPromptServer()
+
+	initialized := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "initialize"})
+	capabilities := initialized.Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+	if _, ok := capabilities["prompts"]; !ok {
+		t.Errorf("Expected the prompts capability to be advertised, got %v", capabilities)
+	}
+
+	listed := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "prompts/list"})
+	prompts := listed.Result.(types.ListPromptsResult).Prompts
+	if len(prompts) != 2 || prompts[0].Name != "loan_amortization" || prompts[1].Name != "summarize_data" {
+		t.Fatalf("Expected both prompts sorted by name, got %+v", prompts)
+	}
+	if len(prompts[0].Arguments) != 4 || !prompts[0].Arguments[0].Required {
+		t.Errorf("Expected the loan prompt arguments to be listed, got %+v", prompts[0].Arguments)
+	}
+}
+
+func TestPrompts_Get(t *testing.T) {
+	server := newPromptServer()
+
+	response := getPrompt(server, `{"name":"loan_amortization","arguments":{"principal":"200000","rate":"5","years":"30","periods":"12"}}`)
+	if response.Error != nil {
+		t.Fatalf("prompts/get failed: %v", response.Error)
+	}
+	messages := response.Result.(types.GetPromptResult).Messages
+	if len(messages) != 1 || messages[0].Role != "user" {
+		t.Fatalf("Expected one user message, got %+v", messages)
+	}
+	if !strings.Contains(messages[0].Content.Text, `{"periods":12,"principal":200000,"rate":5,"time":30}`) {
+		t.Errorf("Expected the amortization_schedule arguments in the prompt, got %s", messages[0].Content.Text)
+	}
+
+	response = getPrompt(server, `{"name":"summarize_data","arguments":{"data":"3, -1.5 4","context":"temperature changes"}}`)
+	if response.Error != nil {
+		t.Fatalf("prompts/get failed: %v", response.Error)
+	}
+	text := response.Result.(types.GetPromptResult).Messages[0].Content.Text
+	if !strings.Contains(text, `{"data":[3,-1.5,4]}`) || !strings.Contains(text, "temperature changes") {
+		t.Errorf("Expected the parsed data and context in the prompt, got %s", text)
+	}
+
+	invalid := []string{
+		`{"name":"unknown"}`,
+		`{"name":"loan_amortization","arguments":{"principal":"1000","rate":"5"}}`,
+		`{"name":"loan_amortization","arguments":{"principal":"lots","rate":"5","years":"1"}}`,
+		`{"name":"summarize_data","arguments":{"data":" , "}}`,
+	}
+	for _, params := range invalid {
+		if response := getPrompt(server, params); response.Error == nil || response.Error.Code != mcp.ErrorCodeInvalidParams {
+			t.Errorf("Expected %s to be rejected as invalid params, got %+v", params, response)
+		}
+	}
+}
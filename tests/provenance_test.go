@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestProvenance_AttachedToResults(t *testing.T) {
+	seed := int64(42)
+	server := mcp.NewServer()
+	schema := map[string]interface{}{"type": "object"}
+	server.RegisterTool("plain", "Returns a number", schema, func(map[string]interface{}) (interface{}, error) {
+		return 1, nil
+	})
+	server.RegisterTool("sampled", "Returns a sample", schema, func(map[string]interface{}) (interface{}, error) {
+		return 2, nil
+	}, mcp.WithVersion("2.1.0"), mcp.WithProvenance(func(arguments map[string]interface{}) types.Provenance {
+		return types.Provenance{Algorithm: "reservoir sampling of " + arguments["method"].(string), Seed: &seed}
+	}))
+	server.RegisterTool("fails", "Always fails", schema, func(map[string]interface{}) (interface{}, error) {
+		return nil, types.NewCalculationError(types.ErrCodeDivisionByZero, 0, "division by zero")
+	})
+
+	provenanceOf := func(name, arguments string) *types.Provenance {
+		response := callTool(server, name, arguments)
+		result, ok := response.Result.(types.CallToolResult)
+		if !ok {
+			t.Fatalf("%s: expected a tool result, got %+v", name, response)
+		}
+		if result.Meta == nil {
+			return nil
+		}
+		return result.Meta.Provenance
+	}
+
+	if p := provenanceOf("plain", `{}`); p == nil || *p != (types.Provenance{Tool: "plain", Version: mcp.ServerVersion, Precision: "float64"}) {
+		t.Errorf("Expected the default provenance, got %+v", p)
+	}
+	if p := provenanceOf("plain", `{"precision":3}`); p == nil || p.Precision != "3 decimal places" {
+		t.Errorf("Expected the precision argument to be reported, got %+v", p)
+	}
+	p := provenanceOf("sampled", `{"method":"algorithm R"}`)
+	if p == nil || p.Tool != "sampled" || p.Version != "2.1.0" || p.Algorithm != "reservoir sampling of algorithm R" || p.Seed == nil || *p.Seed != 42 {
+		t.Errorf("Expected the tool's own provenance, got %+v", p)
+	}
+	if p := provenanceOf("fails", `{}`); p != nil {
+		t.Errorf("Expected no provenance on failed calls, got %+v", p)
+	}
+}
+
+func TestProvenance_TypedTools(t *testing.T) {
+	server := mcp.NewServer()
+	mcp.RegisterTypedTool(server, "basic_math", "Basic math", getBasicMathSchema(), handlers.NewMathHandler().BasicMath,
+		mcp.WithProvenance(func(arguments map[string]interface{}) types.Provenance {
+			operation, _ := arguments["operation"].(string)
+			return types.Provenance{Algorithm: operation}
+		}))
+
+	response := callTool(server, "basic_math", `{"operation":"divide","operands":[1,3],"precision":4}`)
+	result, ok := response.Result.(types.CallToolResult)
+	if !ok || result.Meta == nil || result.Meta.Provenance == nil {
+		t.Fatalf("Expected a result with provenance, got %+v", response)
+	}
+	if p := result.Meta.Provenance; p.Algorithm != "divide" || p.Precision != "4 decimal places" {
+		t.Errorf("Expected the typed arguments to reach the provenance, got %+v", p)
+	}
+}
+
+func TestStatisticsCalculator_Method(t *testing.T) {
+	sc := calculator.NewStatisticsCalculator()
+	tests := map[string]struct {
+		operation, varianceType string
+		weighted                bool
+		want                    string
+	}{
+		"sample std_dev":     {"std_dev", "", false, "square root of sample variance (divides by n − 1)"},
+		"population":         {"variance", "population", false, "population variance (divides by n)"},
+		"weighted mean":      {"mean", "", true, "arithmetic mean, frequency weighted"},
+		"unknown operations": {"kurtosis", "", false, ""},
+	}
+	for name, test := range tests {
+		if got := sc.Method(test.operation, test.varianceType, test.weighted); got != test.want {
+			t.Errorf("%s: expected %q, got %q", name, test.want, got)
+		}
+	}
+}
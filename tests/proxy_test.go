@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_BehindReverseProxy(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("one", "Returns one", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return 1, nil
+	})
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8122,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		CORSEnabled:    true,
+		CORSOrigins:    []string{"http://localhost:3000", "https://calc.example.com"},
+		RateLimit:      &mcp.RateLimitConfig{RequestsPerMinute: 1},
+		Jobs:           mcp.NewJobManager(server, nil),
+		TrustedProxies: []string{"10.0.0.0/8", "127.0.0.1"},
+		BasePath:       "/calc/",
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	submit := func(path, forwardedFor string) *http.Response {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8122"+path, strings.NewReader(`{"name":"one","arguments":{}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// Routes live under the base path, and links keep it
+	resp := submit("/calc/jobs", "203.0.113.5, 10.1.2.3")
+	if resp.StatusCode != http.StatusAccepted || !strings.HasPrefix(resp.Header.Get("Location"), "/calc/jobs/") {
+		t.Fatalf("Expected the job to be accepted under the base path, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	if resp := submit("/jobs", "198.51.100.1"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected paths outside the base path to be unknown, got %d", resp.StatusCode)
+	}
+
+	// Each forwarded client has its own rate limit, not the proxy's
+	if resp := submit("/calc/jobs", "203.0.113.5"); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected the same client to be limited, got %d", resp.StatusCode)
+	}
+	if resp := submit("/calc/jobs", "203.0.113.6, 10.1.2.3"); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected another client behind the proxy to be served, got %d", resp.StatusCode)
+	}
+
+	// The server's own origin, as the client addressed it, passes CORS
+	// when it is listed, and only then
+	preflight := func(host string) *http.Response {
+		req, _ := http.NewRequest("OPTIONS", "http://127.0.0.1:8122/calc/mcp", nil)
+		req.Header.Set("Origin", "https://"+host)
+		req.Header.Set("X-Forwarded-Host", host)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Preflight failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if got := preflight("calc.example.com").Header.Get("Access-Control-Allow-Origin"); got != "https://calc.example.com" {
+		t.Errorf("Expected the listed origin to be allowed, got %q", got)
+	}
+	if resp := preflight("rebound.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected an unlisted origin to be forbidden although it matches the host, got %d", resp.StatusCode)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := mcp.ParseTrustedProxies([]string{"192.168.1.10", "10.0.0.0/8", "::1"})
+	if err != nil || len(networks) != 3 {
+		t.Fatalf("Expected three networks, got %v, %v", networks, err)
+	}
+	if networks[0].String() != "192.168.1.10/32" || networks[2].String() != "::1/128" {
+		t.Errorf("Expected single addresses as host networks, got %v", networks)
+	}
+	if _, err := mcp.ParseTrustedProxies([]string{"nginx"}); err == nil {
+		t.Error("Expected a host name to be rejected")
+	}
+}
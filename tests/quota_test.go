@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_Quotas(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("one", "Returns one", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return 1, nil
+	})
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8113,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		AdminToken:     "admin-token",
+		Auth: mcp.NewAuthenticator(nil, []mcp.Credential{
+			{Name: "limited", Secret: "limited-token", Quota: mcp.Quota{Daily: 2, Monthly: 10}},
+			{Name: "unlimited", Secret: "unlimited-token"},
+		}),
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	call := func(token string) (int, *types.MCPError) {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8113/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"one","arguments":{}}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var response types.MCPResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response.Error
+	}
+	get := func(path, token string, target interface{}) {
+		req, _ := http.NewRequest("GET", "http://127.0.0.1:8113"+path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		json.NewDecoder(resp.Body).Decode(target)
+	}
+
+	for i := 0; i < 3; i++ {
+		if status, mcpErr := call("unlimited-token"); status != http.StatusOK || mcpErr != nil {
+			t.Fatalf("Expected unlimited calls to succeed, got %d %+v", status, mcpErr)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if status, mcpErr := call("limited-token"); status != http.StatusOK || mcpErr != nil {
+			t.Fatalf("Call %d: expected to be within the quota, got %d %+v", i, status, mcpErr)
+		}
+	}
+	status, mcpErr := call("limited-token")
+	if status != http.StatusTooManyRequests || mcpErr == nil || mcpErr.Code != mcp.ErrorCodeQuotaExceeded {
+		t.Fatalf("Expected 429 quota exceeded, got %d %+v", status, mcpErr)
+	}
+	if data, _ := mcpErr.Data.(map[string]interface{}); data["credential"] != "limited" || data["limit"] != float64(2) || data["resets_at"] == nil {
+		t.Errorf("Expected the quota in the error data, got %v", mcpErr.Data)
+	}
+
+	var usage mcp.CredentialUsage
+	get("/usage", "limited-token", &usage)
+	if usage.Credential != "limited" || usage.Daily.Used != 2 || usage.Daily.Remaining == nil || *usage.Daily.Remaining != 0 ||
+		usage.Monthly.Used != 2 || *usage.Monthly.Remaining != 8 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+	if !usage.Daily.ResetsAt.After(time.Now()) || usage.Monthly.ResetsAt.Before(usage.Daily.ResetsAt) {
+		t.Errorf("Unexpected reset times %+v", usage)
+	}
+
+	// The admin token is no client credential, so it has no usage of its own
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:8113/usage", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for the admin token on /usage, got %d", resp.StatusCode)
+	}
+
+	var all struct {
+		Credentials []mcp.CredentialUsage `json:"credentials"`
+	}
+	get("/admin/usage", "admin-token", &all)
+	if len(all.Credentials) != 2 || all.Credentials[1].Credential != "unlimited" || all.Credentials[1].Daily.Used != 3 || all.Credentials[1].Daily.Remaining != nil {
+		t.Errorf("Unexpected usage of all credentials %+v", all.Credentials)
+	}
+}
+
+func TestQuotas_PersistInStore(t *testing.T) {
+	store := storage.NewMemoryStore()
+	credential := &mcp.Credential{Name: "agent", Secret: "token", Quota: mcp.Quota{Monthly: 1}}
+	ctx := mcp.WithCredential(context.Background(), credential)
+
+	newServer := func() *mcp.Server {
+		server := mcp.NewServer()
+		server.SetStore(store, 0)
+		server.RegisterTool("one", "Returns one", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+			return 1, nil
+		})
+		return server
+	}
+	call := func(server *mcp.Server) *types.MCPError {
+		return server.HandleRequestContext(ctx, types.MCPRequest{
+			JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: json.RawMessage(`{"name":"one","arguments":{}}`),
+		}).Error
+	}
+
+	if mcpErr := call(newServer()); mcpErr != nil {
+		t.Fatalf("Expected the first call to succeed, got %+v", mcpErr)
+	}
+	// A restarted server picks up the usage from the store
+	restarted := newServer()
+	if usage := restarted.Usage(credential); usage.Monthly.Used != 1 {
+		t.Errorf("Expected the stored usage, got %+v", usage)
+	}
+	if mcpErr := call(restarted); mcpErr == nil || mcpErr.Code != mcp.ErrorCodeQuotaExceeded {
+		t.Errorf("Expected the monthly quota to be exceeded, got %+v", mcpErr)
+	}
+}
+
+func TestQuotas_ConcurrentCallsAcrossReplicas(t *testing.T) {
+	store := storage.NewMemoryStore()
+	credential := &mcp.Credential{Name: "agent", Secret: "token", Quota: mcp.Quota{Daily: 10}}
+	ctx := mcp.WithCredential(context.Background(), credential)
+
+	var replicas []*mcp.Server
+	for i := 0; i < 2; i++ {
+		server := mcp.NewServer()
+		server.SetStore(store, 0)
+		server.RegisterTool("one", "Returns one", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+			return 1, nil
+		})
+		replicas = append(replicas, server)
+	}
+
+	// Replicas sharing a store never let more calls through than the quota
+	var wg sync.WaitGroup
+	var served atomic.Int32
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(server *mcp.Server) {
+			defer wg.Done()
+			response := server.HandleRequestContext(ctx, types.MCPRequest{
+				JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: json.RawMessage(`{"name":"one","arguments":{}}`),
+			})
+			if response.Error == nil {
+				served.Add(1)
+			}
+		}(replicas[i%2])
+	}
+	wg.Wait()
+	if served.Load() != 10 {
+		t.Errorf("Expected 10 calls within the quota, got %d", served.Load())
+	}
+	if usage := replicas[0].Usage(credential); usage.Daily.Used != 10 || usage.Monthly.Used != 10 {
+		t.Errorf("Expected rejected calls not to be counted, got %+v", usage)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_RateLimit(t *testing.T) {
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8109,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		HealthEnabled:  true,
+		RateLimit:      &mcp.RateLimitConfig{RequestsPerMinute: 2, KeyBy: mcp.RateLimitByAPIKey},
+		Tenants: mcp.NewTenantRegistry([]mcp.Tenant{
+			{ID: "team-a", APIKeys: []string{"client-a"}},
+			{ID: "team-b", APIKeys: []string{"client-b"}},
+		}),
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	// Each key has a bucket of its own
+	for _, key := range []string{"client-a", "client-a", "client-b", "client-b"} {
+		resp := postRateLimited(t, 8109, key, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected %s to be within its limit, got %d", key, resp.StatusCode)
+		}
+	}
+
+	resp := postRateLimited(t, 8109, "client-a", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	var response types.MCPResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || response.Error == nil || response.Error.Code != mcp.ErrorCodeRateLimitExceeded {
+		t.Errorf("Expected 429 rate limit exceeded, got %d %+v", resp.StatusCode, response.Error)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "30" {
+		t.Errorf("Expected a Retry-After of 30 seconds, got %q", retryAfter)
+	}
+
+	// Health checks are not limited
+	health, err := http.Get("http://127.0.0.1:8109/health")
+	if err != nil {
+		t.Fatalf("Health request failed: %v", err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("Expected /health to bypass the limit, got %d", health.StatusCode)
+	}
+}
+
+func TestStreamableHTTP_RateLimitUnknownKeys(t *testing.T) {
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8127,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		RateLimit:      &mcp.RateLimitConfig{RequestsPerMinute: 4, KeyBy: mcp.RateLimitByAPIKey},
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	// Without credentials to check keys against, a fresh key per request
+	// would escape the limit, so requests share the bucket of their address.
+	// A batch costs a token per member.
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"tools/list"},{"jsonrpc":"2.0","id":2,"method":"tools/list"}]`
+	single := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+	for i, step := range []struct {
+		key, body string
+		status    int
+	}{
+		{"", batch, http.StatusOK},
+		{"made-up-1", single, http.StatusOK},
+		{"made-up-2", batch, http.StatusTooManyRequests},
+	} {
+		resp := postRateLimited(t, 8127, step.key, step.body)
+		resp.Body.Close()
+		if resp.StatusCode != step.status {
+			t.Errorf("Request %d: expected %d, got %d", i, step.status, resp.StatusCode)
+		}
+	}
+}
+
+func postRateLimited(t *testing.T, port int, apiKey, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", port), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+	if apiKey != "" {
+		req.Header.Set(mcp.TenantAPIKeyHeader, apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp
+}
@@ -0,0 +1,304 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calculator-server/internal/storage"
+	"calculator-server/pkg/mcp"
+)
+
+// fakeRedis serves the handful of commands RedisStore sends, so the store
+// can be tested without a Redis server
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	commands []string
+}
+
+func startFakeRedis(t *testing.T) (*fakeRedis, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	fake := &fakeRedis{data: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go fake.serve(conn)
+		}
+	}()
+	return fake, listener.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		io.WriteString(conn, f.execute(args))
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:length])
+	}
+	return args, nil
+}
+
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func (f *fakeRedis) execute(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, strings.Join(args, " "))
+
+	switch args[0] {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "SET":
+		f.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "GET":
+		if value, ok := f.data[args[1]]; ok {
+			return bulk(value)
+		}
+		return "$-1\r\n"
+	case "DEL":
+		delete(f.data, args[1])
+		return ":1\r\n"
+	case "EVAL":
+		// The only script is the counter increment: KEYS[1] by ARGV[1]
+		value, _ := strconv.ParseInt(f.data[args[3]], 10, 64)
+		delta, _ := strconv.ParseInt(args[4], 10, 64)
+		value += delta
+		f.data[args[3]] = strconv.FormatInt(value, 10)
+		return fmt.Sprintf(":%d\r\n", value)
+	case "SCAN":
+		var keys []string
+		for key := range f.data {
+			if matched, _ := path.Match(args[3], key); matched {
+				keys = append(keys, bulk(key))
+			}
+		}
+		return "*2\r\n" + bulk("0") + fmt.Sprintf("*%d\r\n", len(keys)) + strings.Join(keys, "")
+	case "MGET":
+		reply := fmt.Sprintf("*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			if value, ok := f.data[key]; ok {
+				reply += bulk(value)
+			} else {
+				reply += "$-1\r\n"
+			}
+		}
+		return reply
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func TestRedisStore_RoundTrip(t *testing.T) {
+	fake, address := startFakeRedis(t)
+
+	store, err := storage.OpenRedisStore("redis://:secret@"+address+"/2", "calc:")
+	if err != nil {
+		t.Fatalf("OpenRedisStore failed: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.Put("sessions", "a", []byte("one"), time.Minute); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Put("sessions", "b", []byte("two"), 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Put("history:s1", "1", []byte("other"), 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if value, ok, err := store.Get("sessions", "a"); err != nil || !ok || string(value) != "one" {
+		t.Errorf("Expected entry 'a', got %q (found=%v, err=%v)", value, ok, err)
+	}
+	if _, ok, err := store.Get("sessions", "missing"); err != nil || ok {
+		t.Errorf("Expected a missing entry to be absent, got found=%v err=%v", ok, err)
+	}
+
+	entries, err := store.List("sessions")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || string(entries["a"]) != "one" || string(entries["b"]) != "two" {
+		t.Errorf("Expected the bucket's two entries, got %v", entries)
+	}
+
+	if err := store.Delete("sessions", "a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok, _ := store.Get("sessions", "a"); ok {
+		t.Error("Expected deleted entry to be absent")
+	}
+
+	for want := int64(1); want <= 2; want++ {
+		if value, err := store.Increment("usage", "agent", 1, time.Hour); err != nil || value != want {
+			t.Errorf("Expected the counter to reach %d, got %d, %v", want, value, err)
+		}
+	}
+	if value, ok, _ := store.Get("usage", "agent"); !ok || string(value) != "2" {
+		t.Errorf("Expected the counter to read as 2, got %q", value)
+	}
+
+	fake.mu.Lock()
+	commands := fake.commands
+	fake.mu.Unlock()
+	if len(commands) < 3 || commands[0] != "AUTH secret" || commands[1] != "SELECT 2" || commands[2] != "SET calc:sessions:a one PX 60000" {
+		t.Errorf("Unexpected commands sent: %v", commands)
+	}
+}
+
+func TestRedisStore_InvalidURL(t *testing.T) {
+	for _, rawURL := range []string{"", "http://localhost:6379", "redis://", "redis://localhost:6379/db"} {
+		if _, err := storage.OpenRedisStore(rawURL, ""); err == nil {
+			t.Errorf("Expected %q to be rejected", rawURL)
+		}
+	}
+}
+
+// flakyStore counts reads and fails them while down, as a store behind a
+// broken network would
+type flakyStore struct {
+	storage.Store
+	down  atomic.Bool
+	reads atomic.Int32
+}
+
+func (s *flakyStore) Get(bucket, key string) ([]byte, bool, error) {
+	s.reads.Add(1)
+	if s.down.Load() {
+		return nil, false, errors.New("connection refused")
+	}
+	return s.Store.Get(bucket, key)
+}
+
+func TestStreamableHTTP_SharedSessions(t *testing.T) {
+	memory := storage.NewMemoryStore()
+	defer memory.Close()
+	store := &flakyStore{Store: memory}
+
+	var transports []*mcp.StreamableHTTPTransport
+	for _, port := range []int{8100, 8101} {
+		transport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+			Host:           "127.0.0.1",
+			Port:           port,
+			SessionTimeout: 5 * time.Minute,
+			MaxConnections: 100,
+			Store:          store,
+			SharedSessions: true,
+		})
+		go transport.Start()
+		transports = append(transports, transport)
+	}
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, transport := range transports {
+			transport.Stop(shutdownCtx)
+		}
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	post := func(port int, sessionID, body string) *http.Response {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", port), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := post(8100, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		t.Fatal("Expected the first replica to assign a session")
+	}
+
+	// The other replica accepts the session it never saw
+	if resp := post(8101, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the second replica to accept the session, got %d", resp.StatusCode)
+	}
+
+	// A session read just now is not read again
+	reads := store.reads.Load()
+	if resp := post(8101, sessionID, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the second replica to accept the session, got %d", resp.StatusCode)
+	}
+	if store.reads.Load() != reads {
+		t.Error("Expected the session to be used without reading the store again")
+	}
+
+	// A store that cannot be read does not end the session
+	store.down.Store(true)
+	time.Sleep(1100 * time.Millisecond)
+	if resp := post(8101, sessionID, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the session to survive a store outage, got %d", resp.StatusCode)
+	}
+	store.down.Store(false)
+
+	// A session that ends in the shared store, say when another replica
+	// expires it, ends on every replica once its cached copy is stale
+	if resp := post(8100, sessionID, `{"jsonrpc":"2.0","id":5,"method":"tools/list"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the first replica to accept the session, got %d", resp.StatusCode)
+	}
+	if err := store.Delete("sessions", sessionID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if resp := post(8100, sessionID, `{"jsonrpc":"2.0","id":6,"method":"tools/list"}`); resp.StatusCode == http.StatusOK {
+		t.Error("Expected the first replica to reject the ended session")
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_PreservesRequestIDs(t *testing.T) {
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8090,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	ids := []string{`1`, `1.0`, `12345678901234567890`, `-7e3`, `"req-1"`}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	for _, id := range ids {
+		t.Run(id, func(t *testing.T) {
+			body := `{"jsonrpc":"2.0","id":` + id + `,"method":"tools/list"}`
+			req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+			req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			raw, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(raw), `"id":`+id+`,`) {
+				t.Errorf("Expected the ID to be echoed as %s, got %s", id, raw)
+			}
+		})
+	}
+}
+
+func TestStreamableHTTP_AssignsHTTPRequestIDs(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8091,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		RequestIDMeta:  true,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	post := func(requestID string) (*http.Response, string) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}}`
+		req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		if requestID != "" {
+			req.Header.Set(mcp.RequestIDHeader, requestID)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(resp.Body)
+		return resp, string(raw)
+	}
+
+	resp, body := post("")
+	generated := resp.Header.Get(mcp.RequestIDHeader)
+	if len(generated) != 32 {
+		t.Fatalf("Expected a generated request ID, got %q", generated)
+	}
+	if !strings.Contains(body, `"_meta":{"requestId":"`+generated+`"`) {
+		t.Errorf("Expected the request ID in the result metadata, got %s", body)
+	}
+
+	if resp, _ := post("trace-42"); resp.Header.Get(mcp.RequestIDHeader) != "trace-42" {
+		t.Errorf("Expected the client request ID to be reused, got %q", resp.Header.Get(mcp.RequestIDHeader))
+	}
+	if resp, _ := post("bad id\twith spaces"); len(resp.Header.Get(mcp.RequestIDHeader)) != 32 {
+		t.Errorf("Expected a malformed request ID to be replaced, got %q", resp.Header.Get(mcp.RequestIDHeader))
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestResources_RegisteredListAndRead(t *testing.T) {
+	server := mcp.NewServer()
+	resourceHandler := handlers.NewResourceHandler(nil)
+	server.RegisterResource("calculator://units", "Supported units", "Units by category", "application/json", resourceHandler.ReadUnitTables)
+	server.RegisterResource("calculator://constants", "Constants", "Named constants", "application/json", resourceHandler.ReadConstants)
+	server.RegisterResource("calculator://session", "Session", "The reading session", "text/plain", func(ctx context.Context) (string, error) {
+		return mcp.SessionIDFromContext(ctx), nil
+	})
+	server.RegisterResource("calculator://broken", "Broken", "Always fails", "text/plain", func(context.Context) (string, error) {
+		return "", fmt.Errorf("unavailable")
+	})
+	ctx := mcp.WithSessionID(context.Background(), "session-1")
+
+	listed := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "resources/list"})
+	if listed.Error != nil {
+		t.Fatalf("resources/list failed: %v", listed.Error)
+	}
+	resources := listed.Result.(types.ListResourcesResult).Resources
+	expected := []string{"calculator://broken", "calculator://constants", "calculator://session", "calculator://units"}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %d resources, got %+v", len(expected), resources)
+	}
+	for i, uri := range expected {
+		if resources[i].URI != uri {
+			t.Errorf("Expected resource %d to be %s, got %s", i, uri, resources[i].URI)
+		}
+	}
+
+	read := func(uri string) types.MCPResponse {
+		return server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "resources/read", Params: json.RawMessage(fmt.Sprintf(`{"uri":%q}`, uri))})
+	}
+
+	units := read("calculator://units")
+	if units.Error != nil {
+		t.Fatalf("Reading units failed: %v", units.Error)
+	}
+	var tables map[string][]string
+	if err := json.Unmarshal([]byte(units.Result.(types.ReadResourceResult).Contents[0].Text), &tables); err != nil {
+		t.Fatalf("Units are not JSON: %v", err)
+	}
+	if len(tables["temperature"]) != 4 || len(tables["length"]) == 0 {
+		t.Errorf("Expected unit tables by category, got %v", tables)
+	}
+
+	constants := read("calculator://constants")
+	var values map[string]float64
+	json.Unmarshal([]byte(constants.Result.(types.ReadResourceResult).Contents[0].Text), &values)
+	if values["pi"] != 3.141592653589793 {
+		t.Errorf("Expected pi among the constants, got %v", values)
+	}
+
+	session := read("calculator://session")
+	contents := session.Result.(types.ReadResourceResult).Contents[0]
+	if contents.Text != "session-1" || contents.MimeType != "text/plain" {
+		t.Errorf("Expected the reader to see the session, got %+v", contents)
+	}
+
+	if broken := read("calculator://broken"); broken.Error == nil || broken.Error.Code != mcp.ErrorCodeInternalError {
+		t.Errorf("Expected a failing reader to return an internal error, got %+v", broken)
+	}
+	if missing := read("calculator://missing"); missing.Error == nil || missing.Error.Code != mcp.ErrorCodeResourceNotFound {
+		t.Errorf("Expected an unknown URI to be not found, got %+v", missing)
+	}
+}
+
+func TestResources_HistoryRequiresStorage(t *testing.T) {
+	resourceHandler := handlers.NewResourceHandler(nil)
+	if _, err := resourceHandler.ReadHistory(context.Background()); err == nil {
+		t.Error("Expected reading history without storage to fail")
+	}
+
+	resourceHandler = handlers.NewResourceHandler(func(context.Context) ([]types.HistoryEntry, error) {
+		return []types.HistoryEntry{{Tool: "basic_math", Result: 3.0}}, nil
+	})
+	text, err := resourceHandler.ReadHistory(context.Background())
+	if err != nil {
+		t.Fatalf("ReadHistory failed: %v", err)
+	}
+	var entries []types.HistoryEntry
+	if err := json.Unmarshal([]byte(text), &entries); err != nil || len(entries) != 1 || entries[0].Tool != "basic_math" {
+		t.Errorf("Expected one basic_math entry, got %s (%v)", text, err)
+	}
+}
+
+func TestResources_AdminResourceNeedsAdmin(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterAdminResource(mcp.MetricsURI, "Tool metrics", "Per-tool metrics", "application/json", server.ReadMetrics)
+	server.Metrics().Record("basic_math", time.Millisecond, nil)
+
+	list := func(ctx context.Context) []types.Resource {
+		listed := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "resources/list"})
+		if listed.Error != nil {
+			t.Fatalf("resources/list failed: %v", listed.Error)
+		}
+		return listed.Result.(types.ListResourcesResult).Resources
+	}
+	read := func(ctx context.Context) types.MCPResponse {
+		return server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "resources/read", Params: json.RawMessage(fmt.Sprintf(`{"uri":%q}`, mcp.MetricsURI))})
+	}
+
+	client := mcp.WithSessionID(context.Background(), "session-1")
+	if resources := list(client); len(resources) != 0 {
+		t.Errorf("Expected the metrics to be hidden from clients, got %+v", resources)
+	}
+	if denied := read(client); denied.Error == nil || denied.Error.Code != mcp.ErrorCodeAccessDenied {
+		t.Errorf("Expected reading the metrics without the admin token to be denied, got %+v", denied)
+	}
+
+	admin := mcp.WithAdmin(client)
+	if resources := list(admin); len(resources) != 1 || resources[0].URI != mcp.MetricsURI {
+		t.Errorf("Expected administrators to see the metrics, got %+v", resources)
+	}
+	metrics := read(admin)
+	if metrics.Error != nil {
+		t.Fatalf("Reading the metrics failed: %v", metrics.Error)
+	}
+	var document struct {
+		Tools []types.ToolMetrics `json:"tools"`
+	}
+	if err := json.Unmarshal([]byte(metrics.Result.(types.ReadResourceResult).Contents[0].Text), &document); err != nil {
+		t.Fatalf("Metrics are not JSON: %v", err)
+	}
+	if len(document.Tools) != 1 || document.Tools[0].Tool != "basic_math" || document.Tools[0].Invocations != 1 {
+		t.Errorf("Expected one basic_math invocation, got %+v", document.Tools)
+	}
+}
+
+func TestStreamableHTTP_MetricsResourceWithAdminToken(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterAdminResource(mcp.MetricsURI, "Tool metrics", "Per-tool metrics", "application/json", server.ReadMetrics)
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8132,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		AdminToken:     "admin-token",
+		Auth:           mcp.NewAuthenticator(nil, []mcp.Credential{{Name: "client", Secret: "client-token"}}),
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	read := func(token string) (int, types.MCPResponse) {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8132/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"`+mcp.MetricsURI+`"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var response types.MCPResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response
+	}
+
+	if status, response := read("client-token"); response.Error == nil || response.Error.Code != mcp.ErrorCodeAccessDenied {
+		t.Errorf("Expected a client to be denied the metrics, got %d %+v", status, response)
+	}
+	if status, response := read("wrong-token"); status != http.StatusUnauthorized {
+		t.Errorf("Expected an unknown token to be refused, got %d %+v", status, response)
+	}
+	if status, response := read("admin-token"); status != http.StatusOK || response.Error != nil {
+		t.Errorf("Expected the admin token to read the metrics, got %d %+v", status, response)
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"calculator-server/pkg/mcp"
+)
+
+// newExplainingServer registers a "double" tool whose results are explained
+func newExplainingServer() *mcp.Server {
+	server := mcp.NewServer()
+	server.RegisterTool("double", "Doubles a number", map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"value": map[string]interface{}{"type": "number"}},
+	}, func(params map[string]interface{}) (interface{}, error) {
+		value, _ := params["value"].(float64)
+		return map[string]interface{}{"result": value * 2}, nil
+	})
+	server.SetResultExplanation("double", true)
+	return server
+}
+
+func TestSampling_ExplainResult(t *testing.T) {
+	client := startStdioClient(t, newExplainingServer())
+	defer client.close()
+
+	client.send(`{"jsonrpc":"2.0","id":"init","method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"sampling":{}}}}`)
+	initialize, _ := json.Marshal(client.receive())
+	if !strings.Contains(string(initialize), `"experimental":{"sampling":{"explainResults":["double"]}}`) {
+		t.Errorf("Expected the explained tools to be advertised, got %s", initialize)
+	}
+	client.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"double","arguments":{"value":21}}}`)
+	request := client.receive()
+	if request["method"] != mcp.MethodSamplingCreateMessage {
+		t.Fatalf("Expected a sampling request, got %v", request)
+	}
+	if prompt := fmt.Sprint(request["params"]); !strings.Contains(prompt, `{"result":42}`) {
+		t.Errorf("Expected the result in the prompt, got %s", prompt)
+	}
+
+	id, _ := json.Marshal(request["id"])
+	client.send(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"role":"assistant","content":{"type":"text","text":"Twice 21 is 42."},"model":"test"}}`)
+	result := client.receive()["result"].(map[string]interface{})
+	content := result["content"].([]interface{})
+	if len(content) != 2 || content[0].(map[string]interface{})["text"] != `{"result":42}` || content[1].(map[string]interface{})["text"] != "Twice 21 is 42." {
+		t.Errorf("Expected the result followed by the explanation, got %v", content)
+	}
+
+	// A refused request leaves the result without an explanation
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"double","arguments":{"value":1}}}`)
+	request = client.receive()
+	id, _ = json.Marshal(request["id"])
+	client.send(`{"jsonrpc":"2.0","id":` + string(id) + `,"error":{"code":-1,"message":"User rejected sampling request"}}`)
+	for {
+		message := client.receive()
+		if message["method"] != nil {
+			continue // The explanation_failed log notification
+		}
+		result = message["result"].(map[string]interface{})
+		if content := result["content"].([]interface{}); len(content) != 1 || result["isError"] == true {
+			t.Errorf("Expected the plain result, got %v", result)
+		}
+		break
+	}
+}
+
+func TestSampling_NotUsed(t *testing.T) {
+	// Clients without the capability get the result alone
+	client := startStdioClient(t, newExplainingServer())
+	defer client.close()
+	client.initialize(`{}`)
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"double","arguments":{"value":2}}}`)
+	response := client.receive()
+	if result, _ := response["result"].(map[string]interface{}); result == nil || len(result["content"].([]interface{})) != 1 {
+		t.Errorf("Expected the result without an explanation, got %v", response)
+	}
+}
+
+func TestSampling_ExplanationReleasesSessionSlot(t *testing.T) {
+	server := newExplainingServer()
+	server.SetSessionCallLimit(1, 0)
+	client := startStdioClient(t, server)
+	defer client.close()
+	client.initialize(`{"sampling":{}}`)
+
+	// While the first call waits on the client's model, the session's only
+	// slot is free for another call
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"double","arguments":{"value":21}}}`)
+	request := client.receive()
+	if request["method"] != mcp.MethodSamplingCreateMessage {
+		t.Fatalf("Expected a sampling request, got %v", request)
+	}
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"double","arguments":{"value":1}}}`)
+	second := client.receive()
+	pending := []map[string]interface{}{request}
+	if second["method"] == mcp.MethodSamplingCreateMessage {
+		pending = append(pending, second)
+	} else {
+		t.Errorf("Expected the second call to run and ask for its explanation, got %v", second)
+	}
+
+	for _, request := range pending {
+		id, _ := json.Marshal(request["id"])
+		client.send(`{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"role":"assistant","content":{"type":"text","text":"Explained."},"model":"test"}}`)
+	}
+	for range pending {
+		if response := client.receive(); response["error"] != nil {
+			t.Errorf("Expected the calls to succeed, got %v", response)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestServer_RunSelfChecks(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath,
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "multiply", "operands": []interface{}{6.0, 7.0}},
+			map[string]interface{}{"result": 42},
+		))
+	server.RegisterTool("wrong_answer", "Basic math with a wrong known answer", getBasicMathSchema(), mathHandler.HandleBasicMath,
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "add", "operands": []interface{}{2.0, 2.0}},
+			map[string]interface{}{"result": 5},
+		))
+	server.RegisterTool("bad_arguments", "Basic math with a misspelled argument", getBasicMathSchema(), mathHandler.HandleBasicMath,
+		mcp.WithSelfCheck(
+			map[string]interface{}{"operation": "add", "operand": []interface{}{2.0, 2.0}},
+			map[string]interface{}{"result": 4},
+		))
+	server.RegisterTool("unchecked", "Basic math without a self-check", getBasicMathSchema(), mathHandler.HandleBasicMath)
+
+	report, err := server.RunSelfChecks(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("RunSelfChecks failed: %v", err)
+	}
+	if report.Passed {
+		t.Error("Expected the report to fail")
+	}
+
+	statuses := make(map[string]types.SelfCheckResult)
+	for _, result := range report.Results {
+		statuses[result.Tool] = result
+	}
+	expected := map[string]string{
+		"basic_math":    types.SelfCheckPassed,
+		"wrong_answer":  types.SelfCheckFailed,
+		"bad_arguments": types.SelfCheckFailed,
+		"unchecked":     types.SelfCheckSkipped,
+	}
+	for tool, status := range expected {
+		if statuses[tool].Status != status {
+			t.Errorf("Expected %s to be %s, got %+v", tool, status, statuses[tool])
+		}
+	}
+	if statuses["wrong_answer"].Error != "result.result: expected 5, got 4" {
+		t.Errorf("Unexpected failure message: %q", statuses["wrong_answer"].Error)
+	}
+
+	report, err = server.RunSelfChecks(context.Background(), []string{"basic_math"})
+	if err != nil || !report.Passed || len(report.Results) != 1 {
+		t.Errorf("Expected only basic_math to run and pass, got %+v (%v)", report, err)
+	}
+
+	if _, err := server.RunSelfChecks(context.Background(), []string{"missing"}); err == nil {
+		t.Error("Expected error for an unknown tool")
+	}
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+// newBlockingServer registers a "block" tool that runs until release is
+// closed, signalling started as each call begins
+func newBlockingServer() (server *mcp.Server, started chan struct{}, release chan struct{}) {
+	server = mcp.NewServer()
+	started = make(chan struct{}, 10)
+	release = make(chan struct{})
+	server.RegisterTool("block", "Blocks", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return 1, nil
+	})
+	server.RegisterTool("quick", "Returns at once", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return 1, nil
+	})
+	return server, started, release
+}
+
+func callInSession(server *mcp.Server, sessionID, tool string) types.MCPResponse {
+	ctx := context.Background()
+	if sessionID != "" {
+		ctx = mcp.WithSessionID(ctx, sessionID)
+	}
+	return server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: []byte(`{"name":"` + tool + `"}`)})
+}
+
+func TestSessionCallLimit_Rejects(t *testing.T) {
+	server, started, release := newBlockingServer()
+	server.SetSessionCallLimit(1, 0)
+
+	done := make(chan types.MCPResponse)
+	go func() { done <- callInSession(server, "busy", "block") }()
+	<-started
+
+	response := callInSession(server, "busy", "quick")
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeTooManyRequests {
+		t.Errorf("Expected the second call of the session to be rejected, got %+v", response)
+	}
+	if response := callInSession(server, "other", "quick"); response.Error != nil {
+		t.Errorf("Expected other sessions to be unaffected, got %+v", response.Error)
+	}
+	if response := callInSession(server, "", "quick"); response.Error != nil {
+		t.Errorf("Expected requests outside a session to be unaffected, got %+v", response.Error)
+	}
+
+	close(release)
+	if response := <-done; response.Error != nil {
+		t.Fatalf("Expected the running call to finish, got %+v", response.Error)
+	}
+	if response := callInSession(server, "busy", "quick"); response.Error != nil {
+		t.Errorf("Expected the slot to be given back, got %+v", response.Error)
+	}
+}
+
+func TestSessionCallLimit_Queues(t *testing.T) {
+	server, started, release := newBlockingServer()
+	server.SetSessionCallLimit(1, 5*time.Second)
+
+	first := make(chan types.MCPResponse)
+	go func() { first <- callInSession(server, "busy", "block") }()
+	<-started
+
+	queued := make(chan types.MCPResponse)
+	go func() { queued <- callInSession(server, "busy", "quick") }()
+	select {
+	case response := <-queued:
+		t.Fatalf("Expected the extra call to wait for a slot, got %+v", response)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-first
+	if response := <-queued; response.Error != nil {
+		t.Errorf("Expected the queued call to run once the slot was free, got %+v", response.Error)
+	}
+
+	// Calls that wait longer than the queue timeout are rejected
+	server, started, release = newBlockingServer()
+	server.SetSessionCallLimit(1, 20*time.Millisecond)
+	go callInSession(server, "busy", "block")
+	<-started
+	if response := callInSession(server, "busy", "quick"); response.Error == nil || response.Error.Code != mcp.ErrorCodeTooManyRequests {
+		t.Errorf("Expected a call to time out in the queue, got %+v", response)
+	}
+	close(release)
+}
+
+func TestSessionCallLimit_HoldsSlotUntilHandlerReturns(t *testing.T) {
+	server, started, release := newBlockingServer()
+	server.SetSessionCallLimit(1, 0)
+
+	// The cancelled call answers at once, but its handler keeps running
+	ctx, cancel := context.WithCancel(mcp.WithSessionID(context.Background(), "busy"))
+	done := make(chan types.MCPResponse)
+	go func() {
+		done <- server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: []byte(`{"name":"block"}`)})
+	}()
+	<-started
+	cancel()
+	if response := <-done; response.Error == nil || response.Error.Code != mcp.ErrorCodeRequestCancelled {
+		t.Fatalf("Expected the call to be cancelled, got %+v", response)
+	}
+
+	if response := callInSession(server, "busy", "quick"); response.Error == nil || response.Error.Code != mcp.ErrorCodeTooManyRequests {
+		t.Errorf("Expected the running handler to keep the slot, got %+v", response)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		response := callInSession(server, "busy", "quick")
+		if response.Error == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the slot to be given back once the handler returned, got %+v", response.Error)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_SessionOnInitialize(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8096,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		RequireSession: true,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", config.Port)
+
+	post := func(sessionID, body string) *http.Response {
+		req, _ := http.NewRequest("POST", baseURL+"/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	// Requests outside a session are refused
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"tools/list"}]`,
+	} {
+		resp := post("", body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s without a session, got %d", body, resp.StatusCode)
+		}
+	}
+	req, _ := http.NewRequest("GET", baseURL+"/mcp", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open SSE stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a stream without a session, got %d", resp.StatusCode)
+	}
+
+	// A failed initialize creates no session
+	resp = post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":"invalid"}`)
+	resp.Body.Close()
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		t.Errorf("Expected no session for a failed initialize, got %s", sessionID)
+	}
+
+	// initialize assigns the session used from then on
+	resp = post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"strict-client","version":"1.0"}}}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("Expected initialize to assign a session, got %d %q", resp.StatusCode, sessionID)
+	}
+
+	resp = post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}}`)
+	defer resp.Body.Close()
+	var response types.MCPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || response.Error != nil {
+		t.Fatalf("Expected the call to succeed in the session, got %+v (%v)", response, err)
+	}
+	if client, ok := server.Client(sessionID); !ok || client.ClientInfo == nil || client.ClientInfo.Name != "strict-client" {
+		t.Errorf("Expected the initialize to belong to the new session, got %+v", client)
+	}
+}
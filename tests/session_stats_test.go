@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_SessionStats(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8089,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		AdminToken:     "admin-secret",
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", config.Port)
+
+	// Opening an SSE stream creates the session
+	req, _ := http.NewRequest("GET", baseURL+"/mcp", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open SSE stream: %v", err)
+	}
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	resp.Body.Close()
+	if sessionID == "" {
+		t.Fatal("Expected a session ID")
+	}
+
+	post := func(body string) types.MCPResponse {
+		req, _ := http.NewRequest("POST", baseURL+"/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var response types.MCPResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"clientInfo":{"name":"test-agent","version":"0.1"}}}`)
+	post(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}}`)
+	response := post(`{"jsonrpc":"2.0","id":3,"method":"session/stats"}`)
+	if response.Error != nil {
+		t.Fatalf("session/stats failed: %v", response.Error)
+	}
+
+	var session types.Session
+	data, _ := json.Marshal(response.Result)
+	json.Unmarshal(data, &session)
+	if session.ID != sessionID || session.Transport != "http" {
+		t.Errorf("Unexpected session identity: %+v", session)
+	}
+	if session.RequestCount != 3 || session.ToolCalls != 1 || session.LastTool != "basic_math" {
+		t.Errorf("Unexpected session statistics: %+v", session)
+	}
+	if session.ClientInfo == nil || session.ClientInfo.Name != "test-agent" {
+		t.Errorf("Expected the client info from initialize, got %+v", session.ClientInfo)
+	}
+	if session.ProtocolVersion != "2025-03-26" || session.Capabilities == nil {
+		t.Errorf("Expected the negotiated version and capabilities, got %q and %+v", session.ProtocolVersion, session.Capabilities)
+	}
+	if client, known := server.Client(sessionID); !known || client.ClientInfo == nil || client.ClientInfo.Name != "test-agent" {
+		t.Errorf("Expected the server to hold the session's client, got %+v", client)
+	}
+
+	req, _ = http.NewRequest("GET", baseURL+"/admin/sessions/"+sessionID, nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Admin request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 from the admin sessions endpoint, got %d", resp.StatusCode)
+	}
+	var adminView types.Session
+	if err := json.NewDecoder(resp.Body).Decode(&adminView); err != nil || adminView.RequestCount != 3 {
+		t.Errorf("Unexpected admin view of the session: %+v (%v)", adminView, err)
+	}
+}
+
+// writeCountingStore counts the writes made to each bucket
+type writeCountingStore struct {
+	storage.Store
+	mu     sync.Mutex
+	writes map[string]int
+}
+
+func (s *writeCountingStore) Put(bucket, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	s.writes[bucket]++
+	s.mu.Unlock()
+	return s.Store.Put(bucket, key, value, ttl)
+}
+
+func (s *writeCountingStore) sessionWrites() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writes["sessions"]
+}
+
+func TestStreamableHTTP_SessionStatsFlushedPeriodically(t *testing.T) {
+	memory := storage.NewMemoryStore()
+	defer memory.Close()
+	store := &writeCountingStore{Store: memory, writes: make(map[string]int)}
+
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8131,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		Store:          store,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	post := func(sessionID, body string) string {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8131/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.Header.Get("Mcp-Session-Id")
+	}
+
+	sessionID := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	written := store.sessionWrites()
+	for i := 0; i < 20; i++ {
+		post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}}`)
+	}
+	if writes := store.sessionWrites() - written; writes > 2 {
+		t.Errorf("Expected the calls not to be written one by one, got %d session writes", writes)
+	}
+
+	// Stopping writes what is left
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	httpTransport.Stop(shutdownCtx)
+
+	data, found, _ := memory.Get("sessions", sessionID)
+	var session types.Session
+	json.Unmarshal(data, &session)
+	if !found || session.ToolCalls != 20 {
+		t.Errorf("Expected the stored session to count 20 tool calls, got %+v", session)
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+)
+
+func TestExpressionCalculator_Simplify(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	testCases := []struct {
+		name       string
+		expression string
+		simplified string
+		canonical  string
+		factored   string
+	}{
+		{"Like terms", "2x + 3x - x", "4*x", "4*x", ""},
+		{"Expand square", "(x + 1)^2", "(x + 1)^2", "x^2 + 2*x + 1", "(x + 1)^2"},
+		{"Terms cancel", "(x + 1)*(x - 1) - x^2", "-1", "-1", ""},
+		{"Cancel univariate factor", "(x^2 - 1)/(x - 1)", "x + 1", "x + 1", ""},
+		{"Cancel multivariate factor", "(x^2 - y^2)/(x^2 + 2*x*y + y^2)", "(x - y)/(x + y)", "(x - y)/(x + y)", ""},
+		{"Exact multivariate division", "(x^3 - y^3)/(x - y)", "x^2 + x*y + y^2", "x^2 + x*y + y^2", ""},
+		{"Numeric factors", "6x^2/(4x)", "3*x/2", "3*x/2", ""},
+		{"Common denominator", "1/x + 1/y", "(x + y)/(x*y)", "(x + y)/(x*y)", ""},
+		{"Rational roots", "x^3 - 6x^2 + 11x - 6", "x^3 - 6*x^2 + 11*x - 6", "x^3 - 6*x^2 + 11*x - 6", "(x - 1)*(x - 2)*(x - 3)"},
+		{"Common monomial", "x^2*y - x*y^2", "x*y*(x - y)", "x^2*y - x*y^2", "x*y*(x - y)"},
+		{"Homogeneous", "4x^2 - 9y^2", "4*x^2 - 9*y^2", "4*x^2 - 9*y^2", "(2*x + 3*y)*(2*x - 3*y)"},
+		{"Repeated irreducible factor", "x^4 + 2x^2 + 1", "(x^2 + 1)^2", "x^4 + 2*x^2 + 1", "(x^2 + 1)^2"},
+		{"Functions are symbols", "sin(2*x) + sin(x + x)", "2*sin(2*x)", "2*sin(2*x)", ""},
+		{"Negative power", "x^-2 * x^3", "x", "x", ""},
+		{"Exact decimals", "0.1 + 0.2", "3/10", "3/10", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Simplify(context.Background(), types.ExpressionRequest{Expression: tc.expression})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Simplified != tc.simplified || result.Canonical != tc.canonical || result.Factored != tc.factored {
+				t.Errorf("Expected simplified %q, canonical %q, factored %q; got %q, %q, %q",
+					tc.simplified, tc.canonical, tc.factored, result.Simplified, result.Canonical, result.Factored)
+			}
+		})
+	}
+}
+
+func TestExpressionCalculator_SimplifyCanonicalFormIsStable(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+	var canonical string
+	for _, expression := range []string{"(a + b)^2", "b^2 + 2*a*b + a^2", "(b + a)*(a + b)", "a*(a + 2b) + b*b"} {
+		result, err := calc.Simplify(context.Background(), types.ExpressionRequest{Expression: expression})
+		if err != nil {
+			t.Fatalf("Simplify(%q) failed: %v", expression, err)
+		}
+		if canonical == "" {
+			canonical = result.Canonical
+		}
+		if result.Canonical != canonical {
+			t.Errorf("Expected %q to have canonical form %q, got %q", expression, canonical, result.Canonical)
+		}
+	}
+}
+
+func TestExpressionCalculator_SimplifyVariablesAndValue(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	result, err := calc.Simplify(context.Background(), types.ExpressionRequest{Expression: "x*y + x*y + z", Variables: map[string]float64{"y": 0.5}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Canonical != "x + z" || result.Value != nil || len(result.Variables) != 2 || result.Variables[0] != "x" || result.Variables[1] != "z" {
+		t.Errorf("Unexpected result after substitution: %+v", result)
+	}
+
+	result, err = calc.Simplify(context.Background(), types.ExpressionRequest{Expression: "(x^2 - 4)/(x + 2) - x"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Value == nil || *result.Value != -2 {
+		t.Errorf("Expected the value -2, got %+v", result)
+	}
+}
+
+func TestExpressionCalculator_SimplifyErrors(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	testCases := []struct {
+		expression string
+		message    string
+	}{
+		{"x / (y - y)", "division by zero"},
+		{"foo(x)", "unknown function foo"},
+		{"x % 2", "unexpected"},
+		{"(x + 1", "expected ')'"},
+		{"x^1000", "too large"},
+		{"(a + b + c + d + f)^10", "too large"},
+		{"sin", "needs arguments"},
+		{"(x + 1)^100*(x + 2)^100*(x + 3)", "maximum degree 200"},
+		{"(x + 1)^100*(x + 2)^100/((x + 3)^100*(x + 4)^100)", "too costly"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := calc.Simplify(context.Background(), types.ExpressionRequest{Expression: tc.expression})
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Expected an error containing %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestExpressionCalculator_SimplifyLimits(t *testing.T) {
+	calc := calculator.NewExpressionCalculator()
+
+	// Common factors of high degree are still cancelled quickly
+	start := time.Now()
+	result, err := calc.Simplify(context.Background(), types.ExpressionRequest{Expression: "((x+1)^100*(x+2)^100)/((x+1)^99*(x+3)^100)"})
+	if err != nil {
+		t.Fatalf("Simplify failed: %v", err)
+	}
+	if result.Simplified != "(x + 1)*(x + 2)^100/(x + 3)^100" {
+		t.Errorf("Expected the common factor cancelled, got %s", result.Simplified)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Simplify took %v", elapsed)
+	}
+
+	// A cancelled request stops the work
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := calc.Simplify(ctx, types.ExpressionRequest{Expression: "(x^2 - 1)/(x - 1)"}); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMathHandler_ExpressionEvalSimplify(t *testing.T) {
+	handler := handlers.NewMathHandler()
+
+	result, err := handler.HandleExpressionEval(context.Background(), map[string]interface{}{
+		"expression": "(x^2 + 2x + 1)/(x + 1)",
+		"operation":  "simplify",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	simplified, ok := result.(types.SimplifyResult)
+	if !ok || simplified.Simplified != "x + 1" {
+		t.Errorf("Expected x + 1, got %+v", result)
+	}
+
+	if _, err := handler.HandleExpressionEval(context.Background(), map[string]interface{}{
+		"expression": "2x",
+		"operation":  "simplify",
+		"variables":  map[string]interface{}{"x": []interface{}{1.0, 2.0}},
+	}); err == nil {
+		t.Error("Expected an error for array variables with simplify")
+	}
+	if _, err := handler.HandleExpressionEval(context.Background(), map[string]interface{}{"expression": "x", "operation": "differentiate"}); err == nil {
+		t.Error("Expected an error for an unknown operation")
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_BatchesSSEProgress(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterContextTool("count", "Counts to 200", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+		for i := 1; i <= 200; i++ {
+			mcp.ReportProgress(ctx, float64(i), 200, "")
+		}
+		return map[string]interface{}{"counted": 200}, nil
+	})
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:             "127.0.0.1",
+		Port:             8117,
+		SessionTimeout:   5 * time.Minute,
+		MaxConnections:   100,
+		SSEFlushInterval: 50 * time.Millisecond,
+		SSEMaxPending:    4,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	req, _ := http.NewRequest("POST", "http://127.0.0.1:8117/mcp", strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"count","arguments":{},"_meta":{"progressToken":"c"}}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var messages []map[string]interface{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			var message map[string]interface{}
+			if err := json.Unmarshal([]byte(data), &message); err != nil {
+				t.Fatalf("Invalid event data %q: %v", data, err)
+			}
+			messages = append(messages, message)
+		}
+	}
+
+	// Updates reported faster than the flush interval are coalesced, and
+	// the latest one is never lost
+	if len(messages) < 2 || len(messages) > 20 {
+		t.Fatalf("Expected a few coalesced progress events and the response, got %d events", len(messages))
+	}
+	progress := messages[len(messages)-2]
+	if params, _ := progress["params"].(map[string]interface{}); progress["method"] != "notifications/progress" || params["progress"] != float64(200) {
+		t.Errorf("Expected the final progress before the response, got %v", progress)
+	}
+	if response := messages[len(messages)-1]; response["id"] != float64(1) || response["result"] == nil {
+		t.Errorf("Expected the response to end the stream, got %v", response)
+	}
+	for _, message := range messages[:len(messages)-1] {
+		if message["method"] != "notifications/progress" {
+			t.Errorf("Unexpected event ahead of the response: %v", message)
+		}
+	}
+}
+
+func TestStreamableHTTP_ClosesOverflowingSSEStream(t *testing.T) {
+	returned := make(chan struct{})
+	server := mcp.NewServer()
+	server.RegisterContextTool("chatter", "Sends many notifications", map[string]interface{}{"type": "object"}, func(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
+		defer close(returned)
+		notify := mcp.NotifierFromContext(ctx)
+		for i := 0; i < 20; i++ {
+			notify(types.MCPNotification{JSONRPC: "2.0", Method: "notifications/message", Params: map[string]interface{}{"i": i}})
+		}
+		return "done", nil
+	})
+	httpTransport := mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:             "127.0.0.1",
+		Port:             8128,
+		SessionTimeout:   5 * time.Minute,
+		MaxConnections:   100,
+		SSEFlushInterval: time.Hour, // Nothing is written before the response
+		SSEMaxPending:    4,
+	})
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	req, _ := http.NewRequest("POST", "http://127.0.0.1:8128/mcp", strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"chatter","arguments":{}}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+	go func() {
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+
+	// A full queue closes the stream instead of making the tool wait for
+	// the next flush
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the tool to return without waiting for the client")
+	}
+}
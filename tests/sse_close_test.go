@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/pkg/mcp"
+)
+
+func TestStreamableHTTP_CloseEventOnShutdown(t *testing.T) {
+	httpConfig := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8102,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), httpConfig)
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("http://127.0.0.1:%d/mcp", httpConfig.Port), nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	for line := range lines {
+		if strings.Contains(line, `"type":"connected"`) {
+			break
+		}
+	}
+
+	// Stop returns once the stream has been closed, well before its deadline
+	stopped := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		stopped <- httpTransport.Stop(shutdownCtx)
+	}()
+
+	var received []string
+	for line := range lines {
+		received = append(received, line)
+	}
+	if len(received) < 3 || received[len(received)-3] != "event: close" ||
+		received[len(received)-2] != `data: {"reason":"server_shutdown","reconnect":true,"type":"close"}` {
+		t.Errorf("Expected the stream to end with a close event, got %q", received)
+	}
+
+	if err := <-stopped; err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Stop not to wait for the stream, took %v", elapsed)
+	}
+}
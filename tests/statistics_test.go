@@ -0,0 +1,439 @@
+package tests
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+
+	"gonum.org/v1/gonum/floats"
+)
+
+func TestStatisticsCalculator_WeightsMatchRepeatedData(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	data, weights := []float64{1, 2, 5}, []float64{3, 1, 2}
+	expanded := []float64{1, 1, 1, 2, 5, 5}
+
+	for _, operation := range []string{"mean", "median", "variance", "std_dev"} {
+		weighted, err := calc.Calculate(types.StatisticsRequest{Data: data, Weights: weights, Operation: operation})
+		if err != nil {
+			t.Fatalf("%s failed: %v", operation, err)
+		}
+		repeated, _ := calc.Calculate(types.StatisticsRequest{Data: expanded, Operation: operation})
+		if math.Abs(weighted.Result.(float64)-repeated.Result.(float64)) > 1e-12 {
+			t.Errorf("%s: expected %v as for the repeated data, got %v", operation, repeated.Result, weighted.Result)
+		}
+	}
+
+	percentile, err := calc.CalculateWeightedPercentile(data, weights, 75)
+	if err != nil {
+		t.Fatalf("CalculateWeightedPercentile failed: %v", err)
+	}
+	if expected, _ := calc.CalculatePercentile(expanded, 75); percentile != expected {
+		t.Errorf("Expected P75 %v, got %v", expected, percentile)
+	}
+
+	summary, err := calc.WeightedSummary([]float64{0, 1, 2, 9}, []float64{0, 2, 2, 0})
+	if err != nil {
+		t.Fatalf("WeightedSummary failed: %v", err)
+	}
+	if summary["min"] != 1.0 || summary["max"] != 2.0 || summary["total_weight"] != 4.0 {
+		t.Errorf("Expected zero-weight points to be ignored, got %+v", summary)
+	}
+}
+
+func TestStatisticsCalculator_WeightValidation(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	data := []float64{1, 2, 3}
+
+	invalid := map[string][]float64{
+		"wrong length":  {1, 2},
+		"negative":      {1, -1, 1},
+		"all zero":      {0, 0, 0},
+		"infinite":      {1, math.Inf(1), 1},
+		"variance <= 1": {0.2, 0.3, 0.5},
+	}
+	for name, weights := range invalid {
+		if _, err := calc.Calculate(types.StatisticsRequest{Data: data, Weights: weights, Operation: "variance"}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := calc.Calculate(types.StatisticsRequest{Data: data, Weights: []float64{1, 1, 1}, Operation: "mode"}); err == nil {
+		t.Error("Expected weights to be rejected for mode")
+	}
+}
+
+func TestStatisticsCalculator_WeightedRegression(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	x := []float64{0, 1, 2, 3}
+	y := []float64{1, 3, 5, 100}
+
+	// A zero weight drops the outlier, leaving the exact line y = 1 + 2x
+	result, err := calc.Calculate(types.StatisticsRequest{Data: y, X: x, Weights: []float64{1, 1, 1, 0}, Operation: "regression"})
+	if err != nil {
+		t.Fatalf("regression failed: %v", err)
+	}
+	fit := result.Result.(types.RegressionResult)
+	if math.Abs(fit.Slope-2) > 1e-9 || math.Abs(fit.Intercept-1) > 1e-9 || math.Abs(fit.RSquared-1) > 1e-9 || !fit.Weighted {
+		t.Errorf("Expected y = 1 + 2x with R² = 1, got %+v", fit)
+	}
+
+	if _, err := calc.Calculate(types.StatisticsRequest{Data: y, X: x[:3], Operation: "regression"}); err == nil {
+		t.Error("Expected an error for mismatched x and data")
+	}
+	if _, err := calc.Calculate(types.StatisticsRequest{Data: y, X: []float64{1, 1, 1, 1}, Operation: "regression"}); err == nil {
+		t.Error("Expected an error for constant x")
+	}
+}
+
+func TestForecaster_Methods(t *testing.T) {
+	forecaster := calculator.NewForecaster()
+	series := []float64{10, 20, 30, 12, 22, 32, 14, 24, 34}
+
+	naive, err := forecaster.Forecast(types.ForecastRequest{Series: series, Method: "naive", Horizon: 4, SeasonLength: 3})
+	if err != nil {
+		t.Fatalf("seasonal naive failed: %v", err)
+	}
+	for i, expected := range []float64{14, 24, 34, 14} {
+		if naive.Forecasts[i].Value != expected {
+			t.Errorf("Step %d: expected %v, got %v", i+1, expected, naive.Forecasts[i].Value)
+		}
+	}
+	// Every seasonal difference is 2, so the intervals have width 2·z·2·√h
+	if math.Abs(naive.RMSE-2) > 1e-12 || naive.Forecasts[3].Upper-naive.Forecasts[3].Lower <= naive.Forecasts[0].Upper-naive.Forecasts[0].Lower {
+		t.Errorf("Expected RMSE 2 and widening intervals, got %+v", naive)
+	}
+
+	average, err := forecaster.Forecast(types.ForecastRequest{Series: []float64{1, 2, 3, 4}, Method: "moving_average", Window: 2, Horizon: 2})
+	if err != nil {
+		t.Fatalf("moving_average failed: %v", err)
+	}
+	if average.Forecasts[0].Value != 3.5 || average.Forecasts[1].Value != 3.5 || average.Parameters["window"] != 2 {
+		t.Errorf("Expected a flat forecast of 3.5, got %+v", average)
+	}
+
+	// A seasonal series with a trend of 2 per season is projected exactly
+	holt, err := forecaster.Forecast(types.ForecastRequest{Series: series, Method: "holt_winters", Horizon: 3, SeasonLength: 3})
+	if err != nil {
+		t.Fatalf("holt_winters failed: %v", err)
+	}
+	for i, expected := range []float64{16, 26, 36} {
+		if math.Abs(holt.Forecasts[i].Value-expected) > 0.5 {
+			t.Errorf("Step %d: expected about %v, got %v", i+1, expected, holt.Forecasts[i].Value)
+		}
+	}
+	if _, fitted := holt.Parameters["gamma"]; !fitted || holt.Confidence != 95 {
+		t.Errorf("Expected fitted smoothing parameters and 95%% intervals, got %+v", holt)
+	}
+}
+
+func TestForecaster_Validation(t *testing.T) {
+	forecaster := calculator.NewForecaster()
+	invalid := 1.5
+
+	requests := map[string]types.ForecastRequest{
+		"zero horizon":         {Series: []float64{1, 2, 3}, Method: "naive"},
+		"short series":         {Series: []float64{1}, Method: "naive", Horizon: 1},
+		"window too large":     {Series: []float64{1, 2, 3}, Method: "moving_average", Window: 3, Horizon: 1},
+		"one season of data":   {Series: []float64{1, 2, 3, 4}, Method: "holt_winters", SeasonLength: 3, Horizon: 1},
+		"alpha out of range":   {Series: []float64{1, 2, 3}, Method: "holt_winters", Alpha: &invalid, Horizon: 1},
+		"unsupported method":   {Series: []float64{1, 2, 3}, Method: "arima", Horizon: 1},
+		"confidence too large": {Series: []float64{1, 2, 3}, Method: "naive", Horizon: 1, Confidence: 100},
+	}
+	for name, req := range requests {
+		if _, err := forecaster.Forecast(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestStatisticsCalculator_Clean(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	nan := math.NaN()
+
+	// Dropping a missing point drops its x value with it
+	req := types.StatisticsRequest{Data: []float64{2, nan, 4, 6}, X: []float64{1, 2, 3, 4}, Operation: "regression", Missing: "drop"}
+	report, err := calc.Clean(&req)
+	if err != nil {
+		t.Fatalf("drop failed: %v", err)
+	}
+	if report.Missing != 1 || report.Dropped != 1 || len(req.Data) != 3 || req.X[1] != 3 {
+		t.Errorf("Expected the second point dropped with its x value, got %+v, data %v, x %v", report, req.Data, req.X)
+	}
+
+	// Imputation uses the weighted median of the present points
+	req = types.StatisticsRequest{Data: []float64{1, nan, 10}, Weights: []float64{3, 1, 1}, Operation: "mean", Missing: "median"}
+	report, err = calc.Clean(&req)
+	if err != nil {
+		t.Fatalf("median imputation failed: %v", err)
+	}
+	if report.Imputed != 1 || report.FillValue == nil || *report.FillValue != 1 || req.Data[1] != 1 {
+		t.Errorf("Expected the weighted median 1 imputed, got %+v, data %v", report, req.Data)
+	}
+
+	// Repeats are removed, then the rest are clipped
+	clipMax := 5.0
+	req = types.StatisticsRequest{Data: []float64{1, 3, 3, 9, 1}, Operation: "mean", Deduplicate: true, ClipMax: &clipMax}
+	report, err = calc.Clean(&req)
+	if err != nil {
+		t.Fatalf("deduplicate and clip failed: %v", err)
+	}
+	if report.DuplicatesRemoved != 2 || report.Clipped != 1 || len(req.Data) != 3 || req.Data[2] != 5 {
+		t.Errorf("Expected [1 3 5] after removing 2 repeats and clipping 9, got %+v, data %v", report, req.Data)
+	}
+
+	// Without options the data is unchanged and there is no report
+	req = types.StatisticsRequest{Data: []float64{1, 2}, Operation: "mean"}
+	if report, err := calc.Clean(&req); err != nil || report != nil {
+		t.Errorf("Expected no report without options, got %+v, %v", report, err)
+	}
+}
+
+func TestStatisticsCalculator_CleanValidation(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	nan := math.NaN()
+	low, high := 5.0, 1.0
+
+	requests := map[string]types.StatisticsRequest{
+		"missing by default":       {Data: []float64{1, nan}},
+		"unsupported missing":      {Data: []float64{1, 2}, Missing: "zero"},
+		"fill_value without value": {Data: []float64{1, 2}, Missing: "mean", FillValue: &low},
+		"value without fill_value": {Data: []float64{1, nan}, Missing: "value"},
+		"clip bounds reversed":     {Data: []float64{1, 2}, ClipMin: &low, ClipMax: &high},
+		"deduplicate with weights": {Data: []float64{1, 2}, Weights: []float64{1, 1}, Deduplicate: true},
+		"everything dropped":       {Data: []float64{nan, nan}, Missing: "drop"},
+		"nothing to impute from":   {Data: []float64{nan}, Missing: "mean"},
+	}
+	for name, req := range requests {
+		req.Operation = "mean"
+		if _, err := calc.Clean(&req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestStatsHandler_StatisticsMissingData(t *testing.T) {
+	handler := handlers.NewStatsHandler()
+
+	result, err := handler.HandleStatistics(map[string]interface{}{
+		"data":       []interface{}{1.0, nil, "NaN", 3.0},
+		"operation":  "mean",
+		"missing":    "value",
+		"fill_value": 2.0,
+	})
+	if err != nil {
+		t.Fatalf("HandleStatistics failed: %v", err)
+	}
+	response := result.(map[string]interface{})
+	if response["result"] != 2.0 {
+		t.Errorf("Expected a mean of 2, got %v", response["result"])
+	}
+	if report, ok := response["cleaning"].(*types.CleaningReport); !ok || report.Imputed != 2 {
+		t.Errorf("Expected two imputed points in the cleaning report, got %+v", response["cleaning"])
+	}
+
+	// Missing points are an error unless an option handles them
+	if _, err := handler.HandleStatistics(map[string]interface{}{"data": []interface{}{1.0, nil}, "operation": "mean"}); err == nil {
+		t.Error("Expected an error for a missing point by default")
+	}
+}
+
+func TestStatsHandler_MaxDataPoints(t *testing.T) {
+	handler := handlers.NewStatsHandler()
+	handler.SetMaxDataPoints(3)
+
+	data := []interface{}{1.0, 2.0, 3.0, 4.0}
+	if _, err := handler.HandleStatistics(map[string]interface{}{"data": data, "operation": "mean"}); err == nil || !strings.Contains(err.Error(), "maximum 3") {
+		t.Errorf("Expected statistics to reject 4 points, got %v", err)
+	}
+	if _, err := handler.HandleStatsSummary(map[string]interface{}{"data": data}); err == nil {
+		t.Error("Expected the summary to reject 4 points")
+	}
+	if _, err := handler.HandlePercentileCalculation(map[string]interface{}{"data": data, "percentile": 50.0}); err == nil {
+		t.Error("Expected the percentile to reject 4 points")
+	}
+	if _, err := handler.HandleForecast(map[string]interface{}{"series": data, "method": "naive", "horizon": 1.0}); err == nil || !strings.Contains(err.Error(), "series") {
+		t.Errorf("Expected the forecast to reject a series of 4 points, got %v", err)
+	}
+
+	// Datasets up to the limit are computed
+	if _, err := handler.HandleStatistics(map[string]interface{}{"data": data[:3], "operation": "mean"}); err != nil {
+		t.Errorf("Expected 3 points to be accepted, got %v", err)
+	}
+}
+
+func TestStatisticsCalculator_VarianceType(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	data := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+
+	// Σ(x − mean)² = 32 over 8 points
+	expected := map[string]float64{"": 32.0 / 7, "sample": 32.0 / 7, "population": 4}
+	for varianceType, want := range expected {
+		result, err := calc.Calculate(types.StatisticsRequest{Data: data, Operation: "variance", Type: varianceType})
+		if err != nil {
+			t.Fatalf("%q variance failed: %v", varianceType, err)
+		}
+		if math.Abs(result.Result.(float64)-want) > 1e-12 {
+			t.Errorf("%q variance: expected %v, got %v", varianceType, want, result.Result)
+		}
+	}
+
+	// Weighted population variance divides by the total weight
+	result, err := calc.Calculate(types.StatisticsRequest{Data: []float64{1, 2}, Weights: []float64{3, 1}, Operation: "std_dev", Type: "population"})
+	if err != nil || math.Abs(result.Result.(float64)-math.Sqrt(0.1875)) > 1e-12 {
+		t.Errorf("Expected a population std_dev of √0.1875, got %v (%v)", result.Result, err)
+	}
+
+	// A single point has a population variance of 0 but no sample variance
+	if result, err := calc.Calculate(types.StatisticsRequest{Data: []float64{5}, Operation: "variance", Type: "population"}); err != nil || result.Result != 0.0 {
+		t.Errorf("Expected a population variance of 0, got %v (%v)", result.Result, err)
+	}
+	invalid := map[string]types.StatisticsRequest{
+		"single point sample": {Data: []float64{5}, Operation: "std_dev"},
+		"unsupported type":    {Data: data, Operation: "variance", Type: "unbiased"},
+		"type on mean":        {Data: data, Operation: "mean", Type: "population"},
+	}
+	for name, req := range invalid {
+		if _, err := calc.Calculate(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestStatisticsCalculator_PercentileSelection(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	random := rand.New(rand.NewSource(1))
+
+	// Selection must pick the same data point as sorting, repeated values included
+	for _, size := range []int{1, 2, 7, 1000, 4096} {
+		data := make([]float64, size)
+		for i := range data {
+			data[i] = float64(random.Intn(size/3 + 1))
+		}
+		sorted := append([]float64(nil), data...)
+		sort.Float64s(sorted)
+		for _, p := range []float64{0, 1, 25, 50, 90, 99.9, 100} {
+			got, err := calc.CalculatePercentile(data, p)
+			if err != nil {
+				t.Fatalf("P%v of %d points failed: %v", p, size, err)
+			}
+			k := int(math.Ceil(p/100*float64(size))) - 1
+			if k < 0 {
+				k = 0
+			}
+			if got != sorted[k] {
+				t.Errorf("P%v of %d points: expected %v, got %v", p, size, sorted[k], got)
+			}
+		}
+	}
+}
+
+func TestStatisticsCalculator_ApproximatePercentile(t *testing.T) {
+	calc := calculator.NewStatisticsCalculator()
+	random := rand.New(rand.NewSource(2))
+	data := make([]float64, 100000)
+	for i := range data {
+		data[i] = random.Float64() * 1000
+	}
+
+	for _, p := range []float64{5, 50, 95, 99} {
+		exact, _ := calc.CalculatePercentile(data, p)
+		estimate, err := calc.ApproximatePercentile(data, p)
+		if err != nil {
+			t.Fatalf("P%v failed: %v", p, err)
+		}
+		if math.Abs(estimate-exact) > 10 {
+			t.Errorf("P%v: estimate %v is more than 1%% of the range from %v", p, estimate, exact)
+		}
+	}
+
+	// The extremes and tiny datasets are exact
+	if minimum, _ := calc.ApproximatePercentile(data, 0); minimum != floats.Min(data) {
+		t.Errorf("Expected P0 to be the minimum, got %v", minimum)
+	}
+	if median, _ := calc.ApproximatePercentile([]float64{9, 1, 5}, 50); median != 5 {
+		t.Errorf("Expected the median of three points to be 5, got %v", median)
+	}
+}
+
+func BenchmarkStatisticsCalculator_Percentile(b *testing.B) {
+	calc := calculator.NewStatisticsCalculator()
+	random := rand.New(rand.NewSource(3))
+	data := make([]float64, 1000000)
+	for i := range data {
+		data[i] = random.NormFloat64()
+	}
+
+	b.Run("exact", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			calc.CalculatePercentile(data, 95)
+		}
+	})
+	b.Run("p2", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			calc.ApproximatePercentile(data, 95)
+		}
+	})
+}
+
+func TestStatisticsCalculator_Parallel(t *testing.T) {
+	serial := calculator.NewStatisticsCalculator()
+	serial.SetParallelism(1, 0)
+	parallel := calculator.NewStatisticsCalculator()
+	parallel.SetParallelism(4, 100)
+
+	random := rand.New(rand.NewSource(4))
+	data := make([]float64, 10007)
+	weights := make([]float64, len(data))
+	for i := range data {
+		// Clustered values with repeats exercise the bucket search
+		data[i] = math.Round(random.ExpFloat64()*1000) / 10
+		weights[i] = float64(random.Intn(5))
+	}
+
+	for _, req := range []types.StatisticsRequest{
+		{Data: data, Operation: "mean"},
+		{Data: data, Weights: weights, Operation: "mean"},
+		{Data: data, Operation: "variance"},
+		{Data: data, Weights: weights, Operation: "std_dev", Type: "population"},
+		{Data: data, Operation: "median"},
+		{Data: data, Operation: "percentile"},
+	} {
+		want, err := serial.Calculate(req)
+		if err != nil {
+			t.Fatalf("%s: %v", req.Operation, err)
+		}
+		got, err := parallel.Calculate(req)
+		if err != nil {
+			t.Fatalf("parallel %s: %v", req.Operation, err)
+		}
+		switch expected := want.Result.(type) {
+		case float64:
+			if math.Abs(got.Result.(float64)-expected) > 1e-9*math.Abs(expected) {
+				t.Errorf("%s: expected %v, got %v", req.Operation, expected, got.Result)
+			}
+		case map[string]float64:
+			// Percentiles are data points and must match exactly
+			for name, value := range expected {
+				if got.Result.(map[string]float64)[name] != value {
+					t.Errorf("%s %s: expected %v, got %v", req.Operation, name, value, got.Result)
+				}
+			}
+		}
+	}
+
+	// A dataset of one repeated value has a single bucket
+	constant := make([]float64, 500)
+	for i := range constant {
+		constant[i] = 7
+	}
+	if median, err := parallel.CalculatePercentile(constant, 50); err != nil || median != 7 {
+		t.Errorf("Expected a median of 7, got %v (%v)", median, err)
+	}
+}
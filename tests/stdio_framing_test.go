@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func newFramingServer() *mcp.Server {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+	return server
+}
+
+// readFramedResponses splits Content-Length framed output into responses
+func readFramedResponses(t *testing.T, output []byte) []types.MCPResponse {
+	reader := bufio.NewReader(bytes.NewReader(output))
+	var responses []types.MCPResponse
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return responses
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		if err != nil {
+			t.Fatalf("Expected a Content-Length header, got %q", header)
+		}
+		if blank, _ := reader.ReadString('\n'); blank != "\r\n" {
+			t.Fatalf("Expected a blank line after the header, got %q", blank)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			t.Fatalf("Failed to read body: %v", err)
+		}
+		var response types.MCPResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			t.Fatalf("Failed to decode %s: %v", body, err)
+		}
+		responses = append(responses, response)
+	}
+}
+
+func frame(message string, headers ...string) string {
+	return strings.Join(append(headers, fmt.Sprintf("Content-Length: %d", len(message))), "\r\n") + "\r\n\r\n" + message
+}
+
+func TestStdio_ContentLengthFraming(t *testing.T) {
+	input := frame(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`) +
+		frame(`{"jsonrpc":"2.0","id":2,
+"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[2,3]}}}`, "Content-Type: application/vscode-jsonrpc; charset=utf-8")
+
+	var output bytes.Buffer
+	transport := mcp.NewStdioTransportWithConfig(newFramingServer(), strings.NewReader(input), &output, &mcp.StdioConfig{Framing: mcp.StdioFramingContentLength})
+	if err := transport.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	responses := readFramedResponses(t, output.Bytes())
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 framed responses, got %d: %s", len(responses), output.String())
+	}
+	for _, response := range responses {
+		if response.Error != nil {
+			t.Errorf("Expected success, got %+v", response.Error)
+		}
+	}
+	if !strings.Contains(output.String(), `5`) {
+		t.Errorf("Expected the sum in the output, got %s", output.String())
+	}
+
+	// A header block without Content-Length cannot be framed
+	transport = mcp.NewStdioTransportWithConfig(newFramingServer(), strings.NewReader("Content-Type: text/plain\r\n\r\n{}"), io.Discard, &mcp.StdioConfig{Framing: mcp.StdioFramingContentLength})
+	if err := transport.Start(); err == nil {
+		t.Error("Expected an error for a message without Content-Length")
+	}
+}
+
+func TestStdio_MaxMessageSize(t *testing.T) {
+	// Messages over the default 64KB scanner limit are read by default
+	operands := strings.Repeat("1,", 40000) + "1"
+	large := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[` + operands + `]}}}`
+	var output bytes.Buffer
+	if err := newFramingServer().Run(strings.NewReader(large+"\n"), &output); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(output.String(), "40001") {
+		t.Errorf("Expected the large message to be answered, got %.200s", output.String())
+	}
+
+	small := `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+	for _, framing := range []mcp.StdioFraming{mcp.StdioFramingLine, mcp.StdioFramingContentLength} {
+		input := large + "\n" + small + "\n"
+		if framing == mcp.StdioFramingContentLength {
+			input = frame(large) + frame(small)
+		}
+
+		output.Reset()
+		transport := mcp.NewStdioTransportWithConfig(newFramingServer(), strings.NewReader(input), &output, &mcp.StdioConfig{Framing: framing, MaxMessageSize: 1024})
+		if err := transport.Start(); err != nil {
+			t.Fatalf("Start failed with %s framing: %v", framing, err)
+		}
+
+		var responses []types.MCPResponse
+		if framing == mcp.StdioFramingContentLength {
+			responses = readFramedResponses(t, output.Bytes())
+		} else {
+			for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+				var response types.MCPResponse
+				json.Unmarshal([]byte(line), &response)
+				responses = append(responses, response)
+			}
+		}
+		if len(responses) != 2 {
+			t.Fatalf("Expected 2 responses with %s framing, got %s", framing, output.String())
+		}
+		if responses[0].Error == nil || responses[0].Error.Code != mcp.ErrorCodeInvalidRequest || responses[0].ID != nil {
+			t.Errorf("Expected the oversized message to be rejected with %s framing, got %+v", framing, responses[0])
+		}
+		if responses[1].Error != nil {
+			t.Errorf("Expected the next message to be answered with %s framing, got %+v", framing, responses[1].Error)
+		}
+	}
+}
+
+func TestStdio_MaxCalls(t *testing.T) {
+	server, started, release := newBlockingServer()
+	call := `{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"block"}}` + "\n"
+	input := fmt.Sprintf(call, 1) + fmt.Sprintf(call, 2)
+	var output bytes.Buffer
+	transport := mcp.NewStdioTransportWithConfig(server, strings.NewReader(input), &output, &mcp.StdioConfig{MaxCalls: 1})
+
+	finished := make(chan error)
+	go func() { finished <- transport.Start() }()
+	<-started
+	select {
+	case <-started:
+		t.Fatal("Expected the second call to wait until the first answered")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-finished; err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if lines := strings.Count(output.String(), "\n"); lines != 2 {
+		t.Errorf("Expected both calls to be answered, got %s", output.String())
+	}
+}
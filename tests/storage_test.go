@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/storage"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestMemoryStore_PutGetExpire(t *testing.T) {
+	store := storage.NewMemoryStore()
+	defer store.Close()
+
+	if err := store.Put("sessions", "a", []byte("alive"), 0); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Put("sessions", "b", []byte("stale"), time.Millisecond); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if value, ok, _ := store.Get("sessions", "a"); !ok || string(value) != "alive" {
+		t.Errorf("Expected entry 'a' to be present, got %q (found=%v)", value, ok)
+	}
+	if _, ok, _ := store.Get("sessions", "b"); ok {
+		t.Error("Expected expired entry 'b' to be hidden")
+	}
+
+	removed, err := store.CollectGarbage()
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 expired entry to be removed, got %d", removed)
+	}
+}
+
+func TestStores_Increment(t *testing.T) {
+	fileStore, err := storage.OpenFileStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	for name, store := range map[string]storage.Store{"memory": storage.NewMemoryStore(), "file": fileStore} {
+		for i, want := range []int64{5, 3} {
+			if value, err := store.Increment("usage", "agent", []int64{5, -2}[i], time.Hour); err != nil || value != want {
+				t.Errorf("%s: expected %d, got %d, %v", name, want, value, err)
+			}
+		}
+		if value, ok, _ := store.Get("usage", "agent"); !ok || string(value) != "3" {
+			t.Errorf("%s: expected the counter to read as 3, got %q", name, value)
+		}
+
+		// An expired counter starts again from zero
+		store.Increment("usage", "brief", 7, time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		if value, _ := store.Increment("usage", "brief", 1, time.Hour); value != 1 {
+			t.Errorf("%s: expected an expired counter to restart, got %d", name, value)
+		}
+
+		store.Put("usage", "text", []byte("not a number"), 0)
+		if _, err := store.Increment("usage", "text", 1, 0); err == nil {
+			t.Errorf("%s: expected a value that is not a counter to be refused", name)
+		}
+		store.Close()
+	}
+}
+
+func TestFileStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	store, err := storage.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	if err := store.Put("history:s1", "1", []byte(`{"tool":"basic_math"}`), time.Hour); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := storage.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	entries, err := reopened.List("history:s1")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if string(entries["1"]) != `{"tool":"basic_math"}` {
+		t.Errorf("Expected persisted entry, got %v", entries)
+	}
+}
+
+func TestFileStore_ReplaysLogAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	store, err := storage.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	snapshot, _ := os.Stat(path)
+	store.Put("sessions", "a", []byte("one"), 0)
+	store.Put("sessions", "b", []byte("two"), 0)
+	store.Delete("sessions", "a")
+	store.Increment("usage", "agent", 3, 0)
+
+	// Writes are appended to the log rather than rewriting the snapshot
+	if after, _ := os.Stat(path); after.Size() != snapshot.Size() {
+		t.Errorf("Expected the snapshot to be left alone, it grew from %d to %d bytes", snapshot.Size(), after.Size())
+	}
+
+	// The server dies without closing the store, halfway through a write
+	logFile, _ := os.OpenFile(path+".log", os.O_WRONLY|os.O_APPEND, 0o600)
+	logFile.WriteString(`{"bucket":"sessions","key":"c","entry":{"val`)
+	logFile.Close()
+
+	reopened, err := storage.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	entries, _ := reopened.List("sessions")
+	if len(entries) != 1 || string(entries["b"]) != "two" {
+		t.Errorf("Expected only entry b to survive, got %v", entries)
+	}
+	if value, _, _ := reopened.Get("usage", "agent"); string(value) != "3" {
+		t.Errorf("Expected the counter to survive, got %q", value)
+	}
+
+	// Enough writes compact the log into the snapshot
+	for i := 0; i < 2000; i++ {
+		reopened.Put("history:s1", "latest", []byte(strconv.Itoa(i)), 0)
+	}
+	if info, _ := os.Stat(path + ".log"); info.Size() > 100*1024 {
+		t.Errorf("Expected the log to be compacted, it holds %d bytes", info.Size())
+	}
+}
+
+func TestServer_RecordsHistoryPerSession(t *testing.T) {
+	server := mcp.NewServer()
+	server.SetStore(storage.NewMemoryStore(), time.Hour)
+	mathHandler := handlers.NewMathHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+
+	calls := []string{
+		`{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}`,
+		`{"name":"basic_math","arguments":{"operation":"divide","operands":[1,0]}}`,
+		`{"name":"basic_math","arguments":{"operation":"multiply","operands":[2,3]}}`,
+	}
+	ctx := mcp.WithSessionID(context.Background(), "session-1")
+	for i, params := range calls {
+		server.HandleRequestContext(ctx, types.MCPRequest{
+			JSONRPC: "2.0",
+			ID:      i,
+			Method:  "tools/call",
+			Params:  json.RawMessage(params),
+		})
+	}
+
+	history, err := server.History("session-1")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 successful calls in history, got %d", len(history))
+	}
+	if history[0].Arguments["operation"] != "add" || history[1].Arguments["operation"] != "multiply" {
+		t.Errorf("Expected history in chronological order, got %v then %v",
+			history[0].Arguments["operation"], history[1].Arguments["operation"])
+	}
+
+	other, err := server.History("session-2")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("Expected no history for another session, got %d entries", len(other))
+	}
+}
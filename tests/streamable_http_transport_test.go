@@ -242,6 +242,128 @@ func TestMCPProtocolCompliance(t *testing.T) {
 }
 
 // Helper function to get basic math schema
+func TestStreamableHTTPTransport_StatelessWithoutSSE(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), handlers.NewMathHandler().HandleBasicMath)
+
+	httpConfig := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8088,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		DisableSSE:     true,
+		Stateless:      true,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, httpConfig)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	url := fmt.Sprintf("http://127.0.0.1:%d/mcp", httpConfig.Port)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"basic_math","arguments":{"operation":"add","operands":[1,2]}}}`
+	req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	req.Header.Set("Mcp-Session-Id", "unknown-session")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the unknown session to be ignored, got status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a plain JSON response, got %s", contentType)
+	}
+
+	req, _ = http.NewRequest("GET", url, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET streams, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTPTransport_ConfigurableCORS(t *testing.T) {
+	httpConfig := &mcp.StreamableHTTPConfig{
+		Host:               "127.0.0.1",
+		Port:               8092,
+		SessionTimeout:     5 * time.Minute,
+		MaxConnections:     100,
+		CORSEnabled:        true,
+		CORSOrigins:        []string{"https://app.example.com"},
+		CORSMethods:        []string{"POST", "OPTIONS"},
+		CORSExposedHeaders: []string{"Mcp-Session-Id"},
+		CORSCredentials:    true,
+		CORSMaxAge:         10 * time.Minute,
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), httpConfig)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	preflight := func(origin string) http.Header {
+		req, _ := http.NewRequest("OPTIONS", fmt.Sprintf("http://127.0.0.1:%d/mcp", httpConfig.Port), nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Preflight failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.Header
+	}
+
+	headers := preflight("https://app.example.com")
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "POST, OPTIONS",
+		"Access-Control-Expose-Headers":    "Mcp-Session-Id",
+		"Access-Control-Max-Age":           "600",
+	}
+	for header, value := range expected {
+		if got := headers.Get(header); got != value {
+			t.Errorf("Expected %s: %q, got %q", header, value, got)
+		}
+	}
+	if !strings.Contains(headers.Get("Access-Control-Allow-Headers"), "Mcp-Session-Id") {
+		t.Errorf("Expected the default allowed headers, got %q", headers.Get("Access-Control-Allow-Headers"))
+	}
+
+	headers = preflight("https://evil.example.com")
+	if headers.Get("Access-Control-Allow-Origin") != "" || headers.Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("Expected no CORS grant for an unknown origin, got %v", headers)
+	}
+}
+
 func getBasicMathSchema() map[string]interface{} {
 	return map[string]interface{}{
 		"type": "object",
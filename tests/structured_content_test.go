@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestServer_StructuredContent(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	outputSchema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"result": map[string]interface{}{"type": "number"}},
+		"required":   []string{"result"},
+	}
+	server.RegisterTool("basic_math", "Basic math", map[string]interface{}{"type": "object"}, mathHandler.HandleBasicMath, mcp.WithOutputSchema(outputSchema))
+	server.RegisterTool("plain", "No output schema", map[string]interface{}{"type": "object"}, mathHandler.HandleBasicMath)
+
+	list := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	for _, tool := range list.Result.(types.ListToolsResult).Tools {
+		if declared := tool.OutputSchema != nil; declared != (tool.Name == "basic_math") {
+			t.Errorf("%s: unexpected output schema %v", tool.Name, tool.OutputSchema)
+		}
+	}
+
+	call := func(name string) types.CallToolResult {
+		t.Helper()
+		params, _ := json.Marshal(types.CallToolParams{Name: name, Arguments: map[string]interface{}{"operation": "add", "operands": []interface{}{2.0, 3.0}}})
+		response := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "tools/call", Params: params})
+		if response.Error != nil {
+			t.Fatalf("%s: unexpected error %+v", name, response.Error)
+		}
+		return response.Result.(types.CallToolResult)
+	}
+
+	// The structured result matches the text block, which is kept for older clients
+	result := call("basic_math")
+	var structured map[string]interface{}
+	if err := json.Unmarshal(result.StructuredContent, &structured); err != nil || structured["result"] != 5.0 {
+		t.Errorf("Expected structuredContent {result: 5}, got %s (%v)", result.StructuredContent, err)
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != string(result.StructuredContent) {
+		t.Errorf("Expected the text block to carry the same JSON, got %+v", result.Content)
+	}
+
+	if result := call("plain"); result.StructuredContent != nil {
+		t.Errorf("Expected no structuredContent without an output schema, got %s", result.StructuredContent)
+	}
+}
+
+func TestServer_OutputSchemaAllowsToolErrors(t *testing.T) {
+	server := mcp.NewServer()
+	outputSchema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"result": map[string]interface{}{"type": "number"}},
+		"required":   []string{"result"},
+	}
+	server.RegisterTool("basic_math", "Basic math", map[string]interface{}{"type": "object"}, handlers.NewMathHandler().HandleBasicMath, mcp.WithOutputSchema(outputSchema))
+
+	// A result or an error object satisfies the declared schema
+	list := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	declared, _ := json.Marshal(list.Result.(types.ListToolsResult).Tools[0].OutputSchema)
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+		AnyOf      []struct {
+			Required []string `json:"required"`
+		} `json:"anyOf"`
+	}
+	json.Unmarshal(declared, &schema)
+	if schema.Properties["result"] == nil || schema.Properties["error"] == nil || schema.Required != nil ||
+		len(schema.AnyOf) != 2 || schema.AnyOf[0].Required[0] != "result" || schema.AnyOf[1].Required[0] != "error" {
+		t.Errorf("Expected the schema to require a result or an error, got %s", declared)
+	}
+	if _, stillRequired := outputSchema["required"]; !stillRequired {
+		t.Error("Expected the registered schema to be left unchanged")
+	}
+
+	params, _ := json.Marshal(types.CallToolParams{Name: "basic_math", Arguments: map[string]interface{}{"operation": "divide", "operands": []interface{}{1.0, 0.0}}})
+	result := server.HandleRequest(types.MCPRequest{JSONRPC: "2.0", ID: 2, Method: "tools/call", Params: params}).Result.(types.CallToolResult)
+	var structured struct {
+		Error map[string]interface{} `json:"error"`
+	}
+	if err := json.Unmarshal(result.StructuredContent, &structured); err != nil || !result.IsError || structured.Error["code"] != "DIVISION_BY_ZERO" {
+		t.Errorf("Expected a DIVISION_BY_ZERO error object, got %s (%v)", result.StructuredContent, err)
+	}
+}
+
+func TestFinanceHandler_ComparisonTable(t *testing.T) {
+	financeHandler := handlers.NewFinanceHandler()
+	result, err := financeHandler.HandleLoanComparison(map[string]interface{}{
+		"loans": []interface{}{
+			map[string]interface{}{"principal": 1200.0, "rate": 12.0, "time": 1.0},
+			map[string]interface{}{"principal": -5.0, "rate": 1.0, "time": 1.0},
+			map[string]interface{}{"principal": 1200.0, "rate": 6.0, "time": 1.0},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	table, ok := result.(types.TableResult)
+	if !ok {
+		t.Fatalf("Expected a TableResult, got %T", result)
+	}
+	if len(table.Rows) != 3 || table.BestIndex != 2 || table.BestValue == nil || *table.BestValue != *table.Rows[2].Value {
+		t.Errorf("Expected the third loan to be best, got %+v", table)
+	}
+	if row := table.Rows[1]; row.Index != 1 || row.Value != nil || row.Error == "" {
+		t.Errorf("Expected the invalid loan to carry an error, got %+v", row)
+	}
+
+	result, _ = financeHandler.HandleInvestmentScenarios(map[string]interface{}{
+		"scenarios": []interface{}{map[string]interface{}{"principal": -1.0, "rate": 10.0, "time": 2.0}},
+	})
+	if table := result.(types.TableResult); table.BestIndex != -1 || table.BestValue != nil {
+		t.Errorf("Expected no best scenario when none could be calculated, got %+v", table)
+	}
+}
+
+func TestStatsHandler_ConversionSeries(t *testing.T) {
+	result, err := handlers.NewStatsHandler().HandleMultipleConversions(map[string]interface{}{
+		"values": []interface{}{0.0, 100.0}, "fromUnit": "C", "toUnit": "F", "category": "temperature",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	series, ok := result.(types.SeriesResult)
+	if !ok {
+		t.Fatalf("Expected a SeriesResult, got %T", result)
+	}
+	if series.Count != 2 || len(series.ConvertedValues) != 2 || series.ConvertedValues[1] != 212 || series.ToUnit != "F" {
+		t.Errorf("Unexpected series %+v", series)
+	}
+
+	encoded, err := json.Marshal(series)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatalf("Invalid series: %v", err)
+	}
+	if fields["from_unit"] != "C" || fields["to_unit"] != "F" {
+		t.Errorf("Expected snake_case unit fields, got %s", encoded)
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/currency"
+	"calculator-server/internal/fetch"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestResourceSubscriptions(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("double", "Doubles a number", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return 0, nil
+	}, mcp.WithGroup("math"))
+	server.RegisterResource(mcp.ToolCatalogURI, "Tool catalog", "Available tools", "application/json", server.ReadToolCatalog)
+
+	client := startStdioClient(t, server)
+	defer client.close()
+	client.initialize(`{}`)
+
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"calculator://missing"}}`)
+	if response := client.receive(); response["error"] == nil {
+		t.Errorf("Expected subscribing to an unknown resource to fail, got %v", response)
+	}
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"` + mcp.ToolCatalogURI + `"}}`)
+	if response := client.receive(); response["error"] != nil {
+		t.Fatalf("Subscribe failed: %v", response)
+	}
+
+	// Toggling a group updates the catalog, after the list_changed notification
+	server.SetToolGroupEnabled("math", false)
+	if notification := client.receive(); notification["method"] != mcp.NotificationToolListChanged {
+		t.Errorf("Expected tools/list_changed first, got %v", notification)
+	}
+	notification := client.receive()
+	if notification["method"] != mcp.NotificationResourceUpdated || notification["params"].(map[string]interface{})["uri"] != mcp.ToolCatalogURI {
+		t.Errorf("Expected the tool catalog to be updated, got %v", notification)
+	}
+	client.send(`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"` + mcp.ToolCatalogURI + `"}}`)
+	read, _ := json.Marshal(client.receive())
+	if strings.Contains(string(read), "double") {
+		t.Errorf("Expected the catalog to drop the disabled tool, got %s", read)
+	}
+
+	// After unsubscribing only the list_changed notification arrives
+	client.send(`{"jsonrpc":"2.0","id":4,"method":"resources/unsubscribe","params":{"uri":"` + mcp.ToolCatalogURI + `"}}`)
+	client.receive()
+	server.SetToolGroupEnabled("math", true)
+	client.receive()
+	client.send(`{"jsonrpc":"2.0","id":5,"method":"ping"}`)
+	if response := client.receive(); response["id"] != float64(5) {
+		t.Errorf("Expected no update after unsubscribing, got %v", response)
+	}
+
+	// Requests without a session have nowhere to receive updates
+	response := server.HandleRequestContext(context.Background(), types.MCPRequest{
+		JSONRPC: "2.0", ID: 6, Method: "resources/subscribe", Params: json.RawMessage(`{"uri":"` + mcp.ToolCatalogURI + `"}`),
+	})
+	if response.Error == nil {
+		t.Error("Expected subscribing without a session to fail")
+	}
+}
+
+func TestRateTable_Refresh(t *testing.T) {
+	eur := 0.9
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"rates": map[string]float64{"EUR": eur, "GBP": 0.8, "BAD": -1}})
+	}))
+	defer upstream.Close()
+
+	fetcher := fetch.New(fetch.Config{AllowedHosts: []string{"127.0.0.1"}, Timeout: 5 * time.Second})
+	table := currency.NewRateTable(currency.NewHTTPRateProvider(fetcher, upstream.URL+"/latest?from={base}"), "USD")
+	if snapshot := table.Snapshot(); len(snapshot.Rates) != 0 || !snapshot.Updated.IsZero() {
+		t.Errorf("Expected an empty table before the first refresh, got %+v", snapshot)
+	}
+
+	for i, want := range []bool{true, false} {
+		if changed, err := table.Refresh(context.Background()); err != nil || changed != want {
+			t.Errorf("Refresh %d: expected changed=%v, got %v, %v", i, want, changed, err)
+		}
+	}
+	eur = 0.95
+	if changed, _ := table.Refresh(context.Background()); !changed {
+		t.Error("Expected a new EUR rate to be reported as a change")
+	}
+	snapshot := table.Snapshot()
+	if snapshot.Base != "USD" || snapshot.Rates["EUR"] != 0.95 || len(snapshot.Rates) != 2 {
+		t.Errorf("Expected the valid rates of USD, got %+v", snapshot)
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+)
+
+func TestSummationCalculator_Calculate(t *testing.T) {
+	calc := calculator.NewSummationCalculator()
+	direct := false
+
+	testCases := []struct {
+		name     string
+		request  types.SummationRequest
+		expected float64
+		method   string
+		exact    string
+	}{
+		{"Arithmetic sum", types.SummationRequest{Operation: "sum", Expression: "k", From: 1, To: 100}, 5050, "arithmetic", "5050"},
+		{"Arithmetic sum with fractions", types.SummationRequest{Operation: "sum", Expression: "k/2 + 1/3", From: 0, To: 2}, 2.5, "arithmetic", "5/2"},
+		{"Huge arithmetic sum", types.SummationRequest{Operation: "sum", Expression: "2k + 1", From: 0, To: 999999999999}, 1e24, "arithmetic", "1000000000000000000000000"},
+		{"Variables", types.SummationRequest{Operation: "sum", Expression: "a*i", Index: "i", From: 1, To: 4, Variables: map[string]float64{"a": 0.5}}, 5, "arithmetic", "5"},
+		{"Geometric sum", types.SummationRequest{Operation: "sum", Expression: "3*2^k", From: 0, To: 10}, 6141, "geometric", ""},
+		{"Convergent geometric sum", types.SummationRequest{Operation: "sum", Expression: "pow(1/2, k)", From: 1, To: 60}, 1 - math.Pow(2, -60), "geometric", ""},
+		{"Geometric product", types.SummationRequest{Operation: "product", Expression: "2^k", From: 1, To: 10}, math.Pow(2, 55), "geometric", ""},
+		{"Constant product", types.SummationRequest{Operation: "product", Expression: "3", From: 1, To: 4}, 81, "geometric", ""},
+		{"Direct sum", types.SummationRequest{Operation: "sum", Expression: "1/k^2", From: 1, To: 1000}, 1.6439345666815615, "direct", ""},
+		{"Direct product", types.SummationRequest{Operation: "product", Expression: "k", From: 1, To: 10}, 3628800, "direct", ""},
+		{"Closed form disabled", types.SummationRequest{Operation: "sum", Expression: "k", From: 1, To: 100, ClosedForm: &direct}, 5050, "direct", ""},
+		{"Empty sum", types.SummationRequest{Operation: "sum", Expression: "k", From: 5, To: 1}, 0, "direct", ""},
+		{"Empty product", types.SummationRequest{Operation: "product", Expression: "k", From: 5, To: 1}, 1, "direct", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := calc.Calculate(context.Background(), tc.request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(result.Result-tc.expected) > 1e-12*math.Max(1, math.Abs(tc.expected)) {
+				t.Errorf("Expected %v, got %v", tc.expected, result.Result)
+			}
+			if result.Method != tc.method || result.Exact != tc.exact {
+				t.Errorf("Expected method %q and exact %q, got %q and %q", tc.method, tc.exact, result.Method, result.Exact)
+			}
+		})
+	}
+}
+
+func TestSummationCalculator_Errors(t *testing.T) {
+	calc := calculator.NewSummationCalculator()
+
+	testCases := []struct {
+		name    string
+		request types.SummationRequest
+		message string
+	}{
+		{"Unknown operation", types.SummationRequest{Operation: "integral", Expression: "k", From: 1, To: 2}, "unsupported summation operation"},
+		{"Invalid index", types.SummationRequest{Operation: "sum", Expression: "k", Index: "pi", From: 1, To: 2}, "invalid index variable"},
+		{"Index given as variable", types.SummationRequest{Operation: "sum", Expression: "k", From: 1, To: 2, Variables: map[string]float64{"k": 1}}, "cannot also be given"},
+		{"Bound too large", types.SummationRequest{Operation: "sum", Expression: "k", From: 1, To: 1e16}, "to must be between"},
+		{"Too many direct terms", types.SummationRequest{Operation: "sum", Expression: "1/k", From: 1, To: 2000000}, "at most 1000000"},
+		{"Failing term", types.SummationRequest{Operation: "sum", Expression: "1/k", From: -1, To: 1}, "term k=0"},
+		{"Cancelled zero division", types.SummationRequest{Operation: "sum", Expression: "(k^2 - 1)/(k - 1)", From: 1, To: 3}, "term k=1"},
+		{"Product overflow", types.SummationRequest{Operation: "product", Expression: "k", From: 1, To: 200}, "overflows at k=171"},
+		{"Geometric overflow", types.SummationRequest{Operation: "sum", Expression: "exp(k)", From: 0, To: 1000}, "overflows"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := calc.Calculate(context.Background(), tc.request)
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Expected an error containing %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestSummationCalculator_PathsAgree(t *testing.T) {
+	calc := calculator.NewSummationCalculator()
+	direct := false
+
+	// Both paths read ^ as a power binding tighter than a sign
+	testCases := []struct {
+		expression string
+		expected   float64
+	}{
+		{"-2^k", -14},
+		{"2^-k", 0.875},
+		{"(-2)^k", -6},
+		{"3*2^k", 42},
+		{"2^k^2", 2 + 16 + 512},
+		{"2k + 1", 15},
+		{"1.5e1*k", 90},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			for _, closedForm := range []*bool{nil, &direct} {
+				result, err := calc.Calculate(context.Background(), types.SummationRequest{Operation: "sum", Expression: tc.expression, From: 1, To: 3, ClosedForm: closedForm})
+				if err != nil {
+					t.Fatalf("Unexpected error (closed form %v): %v", closedForm == nil, err)
+				}
+				if math.Abs(result.Result-tc.expected) > 1e-12 {
+					t.Errorf("Expected %v, got %v with method %s", tc.expected, result.Result, result.Method)
+				}
+			}
+		})
+	}
+
+	if _, err := calc.Calculate(context.Background(), types.SummationRequest{Operation: "sum", Expression: "k % 2", From: 1, To: 3}); err == nil {
+		t.Error("Expected an operator outside the grammar to be rejected")
+	}
+}
+
+func TestMathHandler_Summation(t *testing.T) {
+	handler := handlers.NewMathHandler()
+
+	result, err := handler.HandleSummation(context.Background(), map[string]interface{}{
+		"operation":  "sum",
+		"expression": "n^2",
+		"index":      "n",
+		"from":       1.0,
+		"to":         3.0,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	summation, ok := result.(types.SummationResult)
+	if !ok || summation.Result != 14 || summation.Terms != 3 || summation.Method != "direct" {
+		t.Errorf("Unexpected summation result: %+v", result)
+	}
+
+	if _, err := handler.HandleSummation(context.Background(), map[string]interface{}{"operation": "sum", "expression": "k", "from": 1.5, "to": 3.0}); err == nil {
+		t.Error("Expected an error for a fractional bound")
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/currency"
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func TestServer_TenantAllowedTools(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	statsHandler := handlers.NewStatsHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+	server.RegisterTool("statistics", "Statistical analysis", getStatisticsSchema(), statsHandler.HandleStatistics)
+
+	registry := mcp.NewTenantRegistry([]mcp.Tenant{{ID: "team-a", APIKeys: []string{"key-a"}, AllowedTools: []string{"basic_math"}}})
+	tenant, ok := registry.Resolve("key-a")
+	if !ok {
+		t.Fatal("Expected key-a to resolve to team-a")
+	}
+	ctx := mcp.WithTenant(context.Background(), tenant)
+
+	listResponse := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	tools := listResponse.Result.(types.ListToolsResult).Tools
+	if len(tools) != 1 || tools[0].Name != "basic_math" {
+		t.Errorf("Expected only basic_math to be listed for the tenant, got %v", tools)
+	}
+
+	callResponse := server.HandleRequestContext(ctx, types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"statistics","arguments":{"data":[1,2],"operation":"mean"}}`),
+	})
+	if callResponse.Error == nil || callResponse.Error.Code != mcp.ErrorCodeAccessDenied {
+		t.Errorf("Expected access denied for a tool outside the tenant's set, got %+v", callResponse.Error)
+	}
+
+	if _, ok := registry.Resolve("unknown"); ok {
+		t.Error("Expected unknown key not to resolve")
+	}
+}
+
+func TestStreamableHTTPTransport_TenantAuthenticationAndRateLimit(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+
+	config := &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8086,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		Tenants: mcp.NewTenantRegistry([]mcp.Tenant{
+			{ID: "team-a", APIKeys: []string{"key-a"}, RequestsPerMinute: 2},
+		}),
+	}
+	httpTransport := mcp.NewStreamableHTTPTransport(server, config)
+	go func() {
+		if err := httpTransport.Start(); err != nil {
+			t.Logf("HTTP server error: %v", err)
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	post := func(apiKey string) *http.Response {
+		req, _ := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/mcp", config.Port), strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+		if apiKey != "" {
+			req.Header.Set(mcp.TenantAPIKeyHeader, apiKey)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := post("wrong-key"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown API key, got %d", resp.StatusCode)
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp := post("key-a"); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected request %d within the limit to succeed, got %d", i+1, resp.StatusCode)
+		}
+	}
+
+	resp := post("key-a")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the tenant's limit is used up, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header on 429 responses")
+	}
+}
+
+func TestServer_TenantUnitsAndExchangeRates(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	financeHandler := handlers.NewFinanceHandler()
+	mcp.RegisterTypedContextTool(server, "unit_conversion", "Convert units", map[string]interface{}{"type": "object"}, mathHandler.UnitConversionContext)
+	server.RegisterContextTool("financial", "Financial calculations", map[string]interface{}{"type": "object"}, financeHandler.HandleFinancialCalculationContext)
+
+	units, err := calculator.NewConversionRegistry(calculator.ConversionData{Units: map[string]map[string]float64{"length": {"furlong": 201.168}}})
+	if err != nil {
+		t.Fatalf("NewConversionRegistry failed: %v", err)
+	}
+	rates, err := currency.NewStaticRateProvider("eur", map[string]float64{"USD": 1.25, "GBP": 0.8})
+	if err != nil {
+		t.Fatalf("NewStaticRateProvider failed: %v", err)
+	}
+	registry := mcp.NewTenantRegistry([]mcp.Tenant{
+		{ID: "team-a", APIKeys: []string{"key-a"}, Context: func(ctx context.Context) context.Context {
+			return currency.WithRateProvider(calculator.WithConversionRegistry(ctx, units), rates)
+		}},
+		{ID: "team-b", APIKeys: []string{"key-b"}},
+	})
+	teamA, _ := registry.Resolve("key-a")
+	teamB, _ := registry.Resolve("key-b")
+
+	call := func(tenant *mcp.Tenant, params string) (string, bool) {
+		response := server.HandleRequestContext(mcp.WithTenant(context.Background(), tenant), types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: json.RawMessage(params)})
+		return toolText(t, response)
+	}
+
+	conversion := `{"name":"unit_conversion","arguments":{"value":1,"fromUnit":"furlong","toUnit":"m","category":"length"}}`
+	if text, isError := call(teamA, conversion); isError || !strings.Contains(text, `"converted_value":201.168`) {
+		t.Errorf("Expected team-a to convert its own unit, got %s", text)
+	}
+	if text, isError := call(teamB, conversion); !isError || !strings.Contains(text, "unsupported from unit: furlong") {
+		t.Errorf("Expected team-b not to know team-a's unit, got %s", text)
+	}
+
+	financial := `{"name":"financial","arguments":{"operation":"simple_interest","principal":1000,"rate":5,"time":2,"currency":"USD","reportingCurrency":"GBP"}}`
+	text, isError := call(teamA, financial)
+	var result struct {
+		Converted struct {
+			Rate float64 `json:"rate"`
+		} `json:"converted"`
+	}
+	json.Unmarshal([]byte(text), &result)
+	if isError || math.Abs(result.Converted.Rate-0.64) > 1e-12 {
+		t.Errorf("Expected team-a's rates to cross USD to GBP at 0.64, got %s", text)
+	}
+	if text, isError := call(teamB, financial); !isError || !strings.Contains(text, "not configured") {
+		t.Errorf("Expected team-b to have no exchange rates, got %s", text)
+	}
+}
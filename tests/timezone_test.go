@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func TestTimezoneCalculator_Convert(t *testing.T) {
+	tc := calculator.NewTimezoneCalculator()
+
+	result, err := tc.Calculate(types.TimezoneRequest{Operation: "convert", Timestamp: "2024-07-01 09:00", FromZone: "America/New_York", ToZone: "Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	if result.To.Time != "2024-07-01T15:00:00+02:00" || result.To.Abbreviation != "CEST" || !result.To.IsDST || result.From.UTCOffset != "-04:00" {
+		t.Errorf("Expected 09:00 EDT to be 15:00 CEST, got %+v -> %+v", *result.From, *result.To)
+	}
+
+	offset, err := tc.Calculate(types.TimezoneRequest{Operation: "convert", Timestamp: "2024-01-15T23:30:00+05:30", ToZone: "UTC"})
+	if err != nil {
+		t.Fatalf("convert from an offset failed: %v", err)
+	}
+	if offset.To.Time != "2024-01-15T18:00:00Z" || offset.From.UTCOffset != "+05:30" {
+		t.Errorf("Expected 18:00 UTC, got %+v -> %+v", *offset.From, *offset.To)
+	}
+
+	// 01:30 happens twice in New York when clocks go back; the EDT reading comes first
+	ambiguous, err := tc.Calculate(types.TimezoneRequest{Operation: "convert", Timestamp: "2024-11-03 01:30", FromZone: "America/New_York", ToZone: "UTC"})
+	if err != nil {
+		t.Fatalf("convert of an ambiguous time failed: %v", err)
+	}
+	if !ambiguous.Ambiguous || ambiguous.To.Time != "2024-11-03T05:30:00Z" {
+		t.Errorf("Expected the earlier of the two instants to be flagged, got %+v", ambiguous)
+	}
+
+	invalid := []types.TimezoneRequest{
+		{Operation: "convert", Timestamp: "2024-03-10 02:30", FromZone: "America/New_York", ToZone: "UTC"},
+		{Operation: "convert", Timestamp: "2024-03-10 12:00", FromZone: "Mars/Olympus_Mons", ToZone: "UTC"},
+		{Operation: "convert", Timestamp: "2024-03-10 12:00", ToZone: "Local"},
+		{Operation: "convert", Timestamp: "yesterday", ToZone: "UTC"},
+		{Operation: "convert", Timestamp: "2024-03-10 12:00"},
+	}
+	for _, req := range invalid {
+		if _, err := tc.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestTimezoneCalculator_Durations(t *testing.T) {
+	tc := calculator.NewTimezoneCalculator()
+
+	// Noon to noon across the spring-forward transition is only 23 hours
+	spring, err := tc.Calculate(types.TimezoneRequest{Operation: "duration", Start: "2024-03-09 12:00", End: "2024-03-10 12:00", FromZone: "America/New_York"})
+	if err != nil {
+		t.Fatalf("duration failed: %v", err)
+	}
+	if *spring.Seconds != 82800 || *spring.WallClockSeconds != 86400 || *spring.DSTShiftSeconds != 3600 || spring.Formatted != "23h" {
+		t.Errorf("Expected 23 elapsed hours with a one hour shift, got %+v", spring)
+	}
+
+	// A flight leaving New York at 18:00 and landing in London at 06:10
+	flight, err := tc.Calculate(types.TimezoneRequest{Operation: "duration", Start: "2024-06-01 18:00", End: "2024-06-02 06:10", FromZone: "America/New_York", ToZone: "Europe/London"})
+	if err != nil {
+		t.Fatalf("cross-zone duration failed: %v", err)
+	}
+	if *flight.Seconds != 25800 || flight.Formatted != "7h10m" || flight.WallClockSeconds != nil {
+		t.Errorf("Expected a 7h10m flight without a wall-clock difference, got %+v", flight)
+	}
+
+	tests := []struct {
+		req       types.TimezoneRequest
+		value     float64
+		formatted string
+	}{
+		{types.TimezoneRequest{Operation: "convert_duration", Value: floatPtr(5400)}, 5400, "1h30m"},
+		{types.TimezoneRequest{Operation: "convert_duration", Duration: "1h30m", ToUnit: "min"}, 90, "1h30m"},
+		{types.TimezoneRequest{Operation: "convert_duration", Duration: "2d12h", ToUnit: "d"}, 2.5, "2d12h"},
+		{types.TimezoneRequest{Operation: "convert_duration", Value: floatPtr(1.5), FromUnit: "wk", ToUnit: "h"}, 252, "10d12h"},
+		{types.TimezoneRequest{Operation: "convert_duration", Duration: "-90.25s", ToUnit: "ms"}, -90250, "-1m30.25s"},
+		{types.TimezoneRequest{Operation: "convert_duration", Value: floatPtr(0), FromUnit: "h"}, 0, "0s"},
+	}
+	for _, tt := range tests {
+		result, err := tc.Calculate(tt.req)
+		if err != nil {
+			t.Fatalf("convert_duration %+v failed: %v", tt.req, err)
+		}
+		if *result.Value != tt.value || result.Formatted != tt.formatted {
+			t.Errorf("Expected %+v to give %v (%s), got %v (%s)", tt.req, tt.value, tt.formatted, *result.Value, result.Formatted)
+		}
+	}
+
+	invalid := []types.TimezoneRequest{
+		{Operation: "convert_duration"},
+		{Operation: "convert_duration", Duration: "1 hour"},
+		{Operation: "convert_duration", Duration: "1h", Value: floatPtr(1)},
+		{Operation: "convert_duration", Value: floatPtr(1), FromUnit: "fortnight"},
+		{Operation: "duration", Start: "2024-01-01"},
+	}
+	for _, req := range invalid {
+		if _, err := tc.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
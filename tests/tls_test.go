@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/pkg/mcp"
+)
+
+// testCertificate is a generated certificate with its key
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// issueCertificate creates a certificate for usage signed by parent, or a
+// self-signed CA when parent is nil
+func issueCertificate(t *testing.T, name string, parent *testCertificate, usage x509.ExtKeyUsage) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		// Leave the CA unrestricted, as key usages are checked along the chain
+		template.ExtKeyUsage = nil
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCertificate{cert: cert, key: key, der: der}
+}
+
+// writePEM writes the certificate and key to dir, returning their paths
+func (c *testCertificate) writePEM(t *testing.T, dir, name string) (string, string) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	certPath, keyPath := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certPath, keyPath
+}
+
+func (c *testCertificate) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func startTLSTransport(t *testing.T, port int, tlsConfig *mcp.TLSConfig) func() {
+	transport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           port,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+		TLS:            tlsConfig,
+	})
+	go transport.Start()
+	time.Sleep(100 * time.Millisecond)
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		transport.Stop(shutdownCtx)
+	}
+}
+
+func postTools(client *http.Client, port int) (*http.Response, error) {
+	req, _ := http.NewRequest("POST", fmt.Sprintf("https://127.0.0.1:%d/mcp", port), strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("MCP-Protocol-Version", "2024-11-05")
+	return client.Do(req)
+}
+
+func TestStreamableHTTP_TLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issueCertificate(t, "test-ca", nil, x509.ExtKeyUsageAny)
+	caPath, _ := ca.writePEM(t, dir, "ca")
+	certPath, keyPath := issueCertificate(t, "127.0.0.1", ca, x509.ExtKeyUsageServerAuth).writePEM(t, dir, "server")
+	clientCert := issueCertificate(t, "client", ca, x509.ExtKeyUsageClientAuth)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	newClient := func(certificates ...tls.Certificate) *http.Client {
+		return &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certificates},
+		}}
+	}
+
+	// HTTPS with the minimum version enforced
+	stop := startTLSTransport(t, 8104, &mcp.TLSConfig{CertFile: certPath, KeyFile: keyPath, MinVersion: "1.3"})
+	defer stop()
+	resp, err := postTools(newClient(), 8104)
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil || resp.TLS.Version != tls.VersionTLS13 {
+		t.Errorf("Expected a TLS 1.3 response, got %d %+v", resp.StatusCode, resp.TLS)
+	}
+	tls12 := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: roots, MaxVersion: tls.VersionTLS12},
+	}}
+	if _, err := postTools(tls12, 8104); err == nil {
+		t.Error("Expected a TLS 1.2 client to be refused")
+	}
+
+	// Mutual TLS requires a client certificate signed by the CA
+	stop = startTLSTransport(t, 8105, &mcp.TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCAFile: caPath})
+	defer stop()
+	if _, err := postTools(newClient(), 8105); err == nil {
+		t.Error("Expected a client without a certificate to be refused")
+	}
+	stranger := issueCertificate(t, "stranger", issueCertificate(t, "other-ca", nil, x509.ExtKeyUsageAny), x509.ExtKeyUsageClientAuth)
+	if _, err := postTools(newClient(stranger.tlsCertificate()), 8105); err == nil {
+		t.Error("Expected a certificate from another CA to be refused")
+	}
+	resp, err = postTools(newClient(clientCert.tlsCertificate()), 8105)
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the client certificate to be accepted, got %d", resp.StatusCode)
+	}
+
+	// Unusable settings are reported by Start
+	for _, tlsConfig := range []*mcp.TLSConfig{
+		{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyPath},
+		{CertFile: certPath, KeyFile: keyPath, MinVersion: "1.0"},
+		{CertFile: certPath, KeyFile: keyPath, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		{CertFile: certPath, KeyFile: keyPath, ClientCAFile: keyPath},
+	} {
+		transport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{Host: "127.0.0.1", Port: 8106, SessionTimeout: time.Minute, TLS: tlsConfig})
+		if err := transport.Start(); err == nil {
+			t.Errorf("Expected Start to fail for %+v", tlsConfig)
+		}
+	}
+}
+
+func TestStreamableHTTP_OpsPortUsesTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issueCertificate(t, "test-ca", nil, x509.ExtKeyUsageAny)
+	certPath, keyPath := issueCertificate(t, "127.0.0.1", ca, x509.ExtKeyUsageServerAuth).writePEM(t, dir, "server")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	transport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8135,
+		SessionTimeout: 5 * time.Minute,
+		MetricsEnabled: true,
+		OpsPort:        8136,
+		TLS:            &mcp.TLSConfig{CertFile: certPath, KeyFile: keyPath},
+	})
+	go transport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		transport.Stop(shutdownCtx)
+	}()
+
+	// /metrics is only served over HTTPS, like the MCP endpoint
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	resp, err := client.Get("https://127.0.0.1:8136/metrics")
+	if err != nil {
+		t.Fatalf("HTTPS request to the ops port failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+		t.Errorf("Expected /metrics over TLS, got %d %+v", resp.StatusCode, resp.TLS)
+	}
+	resp, err = (&http.Client{Timeout: 5 * time.Second}).Get("http://127.0.0.1:8136/metrics")
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("Expected /metrics not to be served over plain HTTP")
+		}
+	}
+}
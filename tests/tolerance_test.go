@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"calculator-server/internal/calculator"
+	"calculator-server/internal/types"
+)
+
+func assertRange(t *testing.T, name string, expected, actual *types.ToleranceRange) {
+	t.Helper()
+	if actual == nil || math.Abs(expected.Lower-actual.Lower) > 1e-9 || math.Abs(expected.Upper-actual.Upper) > 1e-9 {
+		t.Errorf("Expected %s %+v, got %+v", name, *expected, actual)
+	}
+}
+
+func TestToleranceCalculator_StackUp(t *testing.T) {
+	tc := calculator.NewToleranceCalculator()
+
+	// A 50 +0.2/-0.1 housing holding a 20 ±0.1 and a 29.5 ±0.05 part leaves a gap
+	result, err := tc.Calculate(types.ToleranceRequest{
+		Operation: "stack_up",
+		Dimensions: []types.ToleranceDimension{
+			{Nominal: 50, Upper: 0.2, Lower: -0.1},
+			{Nominal: 20, Tolerance: 0.1, Subtract: true},
+			{Nominal: 29.5, Tolerance: 0.05, Subtract: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("stack_up failed: %v", err)
+	}
+	if math.Abs(*result.Nominal-0.5) > 1e-9 {
+		t.Errorf("Expected a nominal gap of 0.5, got %v", *result.Nominal)
+	}
+	assertRange(t, "worst case", &types.ToleranceRange{Lower: 0.25, Upper: 0.85}, result.WorstCase)
+	rss := math.Sqrt(0.15*0.15 + 0.1*0.1 + 0.05*0.05)
+	assertRange(t, "rss", &types.ToleranceRange{Lower: 0.55 - rss, Upper: 0.55 + rss}, result.RSS)
+
+	invalid := []types.ToleranceRequest{
+		{Operation: "stack_up"},
+		{Operation: "stack_up", Dimensions: []types.ToleranceDimension{{Nominal: 1, Upper: -0.1, Lower: 0.1}}},
+		{Operation: "stack_up", Dimensions: []types.ToleranceDimension{{Nominal: 1, Upper: 0.1, Tolerance: 0.1}}},
+	}
+	for _, req := range invalid {
+		if _, err := tc.Calculate(req); err == nil {
+			t.Errorf("Expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestToleranceCalculator_MeasurementsAndFits(t *testing.T) {
+	tc := calculator.NewToleranceCalculator()
+
+	measured, expected := 9.8, 10.0
+	errorResult, err := tc.Calculate(types.ToleranceRequest{Operation: "percent_error", Measured: &measured, Expected: &expected})
+	if err != nil {
+		t.Fatalf("percent_error failed: %v", err)
+	}
+	if math.Abs(*errorResult.PercentError-2) > 1e-9 || math.Abs(*errorResult.AbsoluteError+0.2) > 1e-9 {
+		t.Errorf("Expected a 2%% error of -0.2, got %+v", errorResult)
+	}
+	zero := 0.0
+	if _, err := tc.Calculate(types.ToleranceRequest{Operation: "percent_error", Measured: &measured, Expected: &zero}); err == nil {
+		t.Error("Expected percent error against 0 to be rejected")
+	}
+
+	hole := types.ToleranceDimension{Nominal: 25, Upper: 0.021}
+	tests := []struct {
+		measured float64
+		within   bool
+	}{
+		{25.021, true},
+		{25, true},
+		{25.03, false},
+		{24.99, false},
+	}
+	for _, tt := range tests {
+		measured := tt.measured
+		result, err := tc.Calculate(types.ToleranceRequest{Operation: "within_tolerance", Measured: &measured, Dimension: &hole})
+		if err != nil {
+			t.Fatalf("within_tolerance failed: %v", err)
+		}
+		if *result.WithinTolerance != tt.within {
+			t.Errorf("Expected %v within %+v to be %v, got %+v", tt.measured, hole, tt.within, result)
+		}
+	}
+
+	fits := []struct {
+		shaft     types.ToleranceDimension
+		fit       string
+		clearance types.ToleranceRange
+	}{
+		{types.ToleranceDimension{Nominal: 25, Upper: -0.007, Lower: -0.02}, "clearance", types.ToleranceRange{Lower: 0.007, Upper: 0.041}},
+		{types.ToleranceDimension{Nominal: 25, Upper: 0.015, Lower: 0.002}, "transition", types.ToleranceRange{Lower: -0.015, Upper: 0.019}},
+		{types.ToleranceDimension{Nominal: 25, Upper: 0.035, Lower: 0.022}, "interference", types.ToleranceRange{Lower: -0.035, Upper: -0.001}},
+	}
+	for _, tt := range fits {
+		shaft := tt.shaft
+		result, err := tc.Calculate(types.ToleranceRequest{Operation: "fit", Hole: &hole, Shaft: &shaft})
+		if err != nil {
+			t.Fatalf("fit failed: %v", err)
+		}
+		if result.Fit != tt.fit {
+			t.Errorf("Expected a %s fit, got %s", tt.fit, result.Fit)
+		}
+		assertRange(t, "clearance", &tt.clearance, result.Clearance)
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func newGroupedServer() *mcp.Server {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	statsHandler := handlers.NewStatsHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath, mcp.WithGroup("math"))
+	server.RegisterTool("statistics", "Statistical analysis", getStatisticsSchema(), statsHandler.HandleStatistics, mcp.WithGroup("stats"))
+	return server
+}
+
+func listToolNames(server *mcp.Server, ctx context.Context) map[string]bool {
+	response := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	names := make(map[string]bool)
+	for _, tool := range response.Result.(types.ListToolsResult).Tools {
+		names[tool.Name] = true
+	}
+	return names
+}
+
+func TestServer_DisabledToolGroup(t *testing.T) {
+	server := newGroupedServer()
+	server.SetToolGroupEnabled("stats", false)
+
+	names := listToolNames(server, context.Background())
+	if !names["basic_math"] || names["statistics"] {
+		t.Errorf("Expected only basic_math to be listed, got %v", names)
+	}
+
+	response := server.HandleRequest(types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      2,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"statistics","arguments":{"data":[1,2],"operation":"mean"}}`),
+	})
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeAccessDenied {
+		t.Errorf("Expected access denied for a disabled group, got %+v", response.Error)
+	}
+
+	groups := server.ToolGroups()
+	if groups["stats"] || !groups["math"] {
+		t.Errorf("Unexpected group states: %v", groups)
+	}
+
+	server.SetToolGroupEnabled("stats", true)
+	if names := listToolNames(server, context.Background()); !names["statistics"] {
+		t.Error("Expected statistics to be listed after re-enabling its group")
+	}
+}
+
+func TestServer_TenantToolGroups(t *testing.T) {
+	server := newGroupedServer()
+	ctx := mcp.WithTenant(context.Background(), &mcp.Tenant{ID: "team-a", ToolGroups: []string{"stats"}})
+
+	names := listToolNames(server, ctx)
+	if names["basic_math"] || !names["statistics"] {
+		t.Errorf("Expected only the tenant's stats group to be listed, got %v", names)
+	}
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func listTools(server *mcp.Server, ctx context.Context) types.ListToolsResult {
+	response := server.HandleRequestContext(ctx, types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	return response.Result.(types.ListToolsResult)
+}
+
+func toolNames(list types.ListToolsResult) map[string]bool {
+	names := make(map[string]bool, len(list.Tools))
+	for _, tool := range list.Tools {
+		names[tool.Name] = true
+	}
+	return names
+}
+
+func TestToolListCache(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath, mcp.WithGroup("math"))
+	server.PrewarmToolList()
+
+	first := listTools(server, context.Background())
+	second := listTools(server, context.Background())
+	if len(first.Marshaled) == 0 || &first.Marshaled[0] != &second.Marshaled[0] {
+		t.Fatal("Expected repeated tools/list calls to reuse one marshaled list")
+	}
+
+	// The cached encoding is what goes on the wire
+	encoded, err := json.Marshal(types.MCPResponse{JSONRPC: "2.0", ID: 1, Result: first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Result struct {
+			Tools []types.Tool `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(encoded, &decoded); err != nil || len(decoded.Result.Tools) != 1 || decoded.Result.Tools[0].Name != "basic_math" {
+		t.Errorf("Expected the marshaled list in the response, got %s", encoded)
+	}
+
+	// Registrations and group changes are seen at once
+	server.RegisterTool("unit_conversion", "Convert units", map[string]interface{}{"type": "object"}, mathHandler.HandleUnitConversion)
+	if names := toolNames(listTools(server, context.Background())); !names["unit_conversion"] || !names["basic_math"] {
+		t.Errorf("Expected a registered tool to be listed, got %v", names)
+	}
+	server.SetToolGroupEnabled("math", false)
+	if names := toolNames(listTools(server, context.Background())); names["basic_math"] || !names["unit_conversion"] {
+		t.Errorf("Expected a disabled group to be hidden, got %v", names)
+	}
+	server.SetToolGroupEnabled("math", true)
+	server.UnregisterTool("unit_conversion")
+	if names := toolNames(listTools(server, context.Background())); names["unit_conversion"] || !names["basic_math"] {
+		t.Errorf("Expected an unregistered tool to be dropped, got %v", names)
+	}
+
+	// Each tenant is cached apart from the others
+	registry := mcp.NewTenantRegistry([]mcp.Tenant{{ID: "team-a", APIKeys: []string{"key-a"}, AllowedTools: []string{"none"}}})
+	tenant, _ := registry.Resolve("key-a")
+	if tools := listTools(server, mcp.WithTenant(context.Background(), tenant)).Tools; len(tools) != 0 {
+		t.Errorf("Expected no tools for the tenant, got %v", tools)
+	}
+	if tools := listTools(server, context.Background()).Tools; len(tools) != 1 {
+		t.Errorf("Expected the untenanted list to be unaffected, got %v", tools)
+	}
+}
+
+func TestToolListOrdering(t *testing.T) {
+	server := mcp.NewServer()
+	handler := func(map[string]interface{}) (interface{}, error) { return nil, nil }
+	for _, name := range []string{"statistics", "basic_math", "unit_conversion", "amortization"} {
+		server.RegisterTool(name, name, map[string]interface{}{"type": "object"}, handler)
+	}
+	names := func() []string {
+		list := listTools(server, context.Background())
+		names := make([]string, len(list.Tools))
+		for i, tool := range list.Tools {
+			names[i] = tool.Name
+		}
+		return names
+	}
+
+	// Re-registering keeps the place; removing and adding moves to the end
+	server.RegisterTool("basic_math", "Basic math", map[string]interface{}{"type": "object"}, handler)
+	server.UnregisterTool("statistics")
+	server.RegisterTool("statistics", "statistics", map[string]interface{}{"type": "object"}, handler)
+	expected := []string{"basic_math", "unit_conversion", "amortization", "statistics"}
+	for i := 0; i < 3; i++ {
+		if got := names(); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Expected registration order %v, got %v", expected, got)
+		}
+	}
+
+	server.SetToolOrdering(mcp.ToolOrderName)
+	expected = []string{"amortization", "basic_math", "statistics", "unit_conversion"}
+	if got := names(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected name order %v, got %v", expected, got)
+	}
+}
+
+func BenchmarkToolsList(b *testing.B) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	statsHandler := handlers.NewStatsHandler()
+	server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+	server.RegisterTool("statistics", "Statistical analysis", getStatisticsSchema(), statsHandler.HandleStatistics)
+	server.RegisterTool("unit_conversion", "Convert units", map[string]interface{}{"type": "object"}, mathHandler.HandleUnitConversion)
+	request := types.MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(server.HandleRequest(request)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/pkg/mcp"
+)
+
+func TestTransportManager_ServesAndStopsTogether(t *testing.T) {
+	server := mcp.NewServer()
+	server.RegisterTool("one", "Returns one", map[string]interface{}{"type": "object"}, func(map[string]interface{}) (interface{}, error) {
+		return 1, nil
+	})
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	manager := mcp.NewTransportManager()
+	manager.Add("stdio", mcp.NewStdioTransportWithIO(server, stdinReader, stdoutWriter))
+	manager.Add("http", mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8114,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}))
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- manager.Run(context.Background(), 5*time.Second)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// The same server answers on both transports
+	go stdinWriter.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"one","arguments":{}}}` + "\n"))
+	line, err := bufio.NewReader(stdoutReader).ReadString('\n')
+	if err != nil || !strings.Contains(line, `"id":1`) || !strings.Contains(line, `"result"`) {
+		t.Fatalf("Expected a stdio answer, got %q, %v", line, err)
+	}
+	post := func() (*http.Response, error) {
+		req, _ := http.NewRequest("POST", "http://127.0.0.1:8114/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"one","arguments":{}}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+		return http.DefaultClient.Do(req)
+	}
+	resp, err := post()
+	if err != nil {
+		t.Fatalf("HTTP request failed: %v", err)
+	}
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	resp.Body.Close()
+	if response["result"] == nil {
+		t.Errorf("Expected an HTTP answer, got %v", response)
+	}
+
+	// The local client going away stops the HTTP transport too
+	stdinWriter.Close()
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Run to return once stdin ended")
+	}
+	if resp, err := post(); err == nil {
+		resp.Body.Close()
+		t.Error("Expected the HTTP transport to be stopped")
+	}
+}
+
+func TestTransportManager_StopsOnCancel(t *testing.T) {
+	server := mcp.NewServer()
+	stdinReader, stdinWriter := io.Pipe()
+	defer stdinWriter.Close()
+
+	manager := mcp.NewTransportManager()
+	manager.Add("stdio", mcp.NewStdioTransportWithIO(server, stdinReader, io.Discard))
+	manager.Add("http", mcp.NewStreamableHTTPTransport(server, &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8115,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan error, 1)
+	go func() {
+		finished <- manager.Run(ctx, 5*time.Second)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Run to return once cancelled, even with stdin open")
+	}
+}
+
+func TestTransportManager_ReportsFailedTransport(t *testing.T) {
+	server := mcp.NewServer()
+	config := &mcp.StreamableHTTPConfig{Host: "127.0.0.1", Port: 8116, SessionTimeout: time.Minute, MaxConnections: 100}
+	first := mcp.NewStreamableHTTPTransport(server, config)
+	go first.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer first.Stop(context.Background())
+
+	// A second listener on the same port cannot start
+	manager := mcp.NewTransportManager()
+	manager.Add("http", mcp.NewStreamableHTTPTransport(server, config))
+	if err := manager.Run(context.Background(), time.Second); err == nil || !strings.Contains(err.Error(), "http transport") {
+		t.Errorf("Expected the failed transport to be reported, got %v", err)
+	}
+}
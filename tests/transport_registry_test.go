@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+// The built-in transports implement the full interface
+var (
+	_ mcp.Transport = (*mcp.StdioTransport)(nil)
+	_ mcp.Transport = (*mcp.StreamableHTTPTransport)(nil)
+)
+
+// loopbackTransport keeps the notifications sent to it
+type loopbackTransport struct {
+	server  *mcp.Server
+	sent    []types.MCPNotification
+	stopped chan struct{}
+}
+
+func (l *loopbackTransport) Start() error {
+	<-l.stopped
+	return nil
+}
+
+func (l *loopbackTransport) Stop(context.Context) error {
+	close(l.stopped)
+	return nil
+}
+
+func (l *loopbackTransport) Send(notification types.MCPNotification) error {
+	l.sent = append(l.sent, notification)
+	return nil
+}
+
+func (l *loopbackTransport) Addr() string {
+	return "loopback"
+}
+
+func TestTransportRegistry_PluggableTransports(t *testing.T) {
+	mcp.RegisterTransport("loopback", func(server *mcp.Server) (mcp.Transport, error) {
+		return &loopbackTransport{server: server, stopped: make(chan struct{})}, nil
+	})
+	if names := mcp.RegisteredTransports(); !slices.Equal(names, []string{"http", "loopback", "stdio"}) {
+		t.Errorf("Expected the built-in and plugged-in transports, got %v", names)
+	}
+
+	server := mcp.NewServer()
+	transport, err := mcp.NewTransport("loopback", server)
+	if err != nil {
+		t.Fatalf("Failed to create the plugged-in transport: %v", err)
+	}
+	loopback, ok := transport.(*loopbackTransport)
+	if !ok || loopback.server != server || transport.Addr() != "loopback" {
+		t.Fatalf("Expected the registered factory to create the transport, got %#v", transport)
+	}
+
+	// A plugged-in transport runs alongside the others
+	manager := mcp.NewTransportManager()
+	manager.Add("loopback", transport)
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan error, 1)
+	go func() { finished <- manager.Run(ctx, time.Second) }()
+	transport.Send(types.MCPNotification{JSONRPC: "2.0", Method: "notifications/message"})
+	cancel()
+	if err := <-finished; err != nil || len(loopback.sent) != 1 {
+		t.Errorf("Expected a clean run with one notification sent, got %v, %+v", err, loopback.sent)
+	}
+
+	if _, err := mcp.NewTransport("carrier-pigeon", server); err == nil {
+		t.Error("Expected an unknown transport to be rejected")
+	}
+	if stdio, err := mcp.NewTransport("stdio", server); err != nil || stdio.Addr() != "stdio" {
+		t.Errorf("Expected the built-in stdio transport, got %v, %v", stdio, err)
+	}
+}
+
+func TestStdioTransport_Send(t *testing.T) {
+	var out bytes.Buffer
+	transport := mcp.NewStdioTransportWithIO(mcp.NewServer(), strings.NewReader(""), &out)
+	if err := transport.Send(types.MCPNotification{JSONRPC: "2.0", Method: "notifications/tools/list_changed"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got := out.String(); got != `{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`+"\n" {
+		t.Errorf("Expected one framed notification, got %q", got)
+	}
+}
+
+func TestStreamableHTTP_SendReachesStreams(t *testing.T) {
+	httpTransport := mcp.NewStreamableHTTPTransport(mcp.NewServer(), &mcp.StreamableHTTPConfig{
+		Host:           "127.0.0.1",
+		Port:           8119,
+		SessionTimeout: 5 * time.Minute,
+		MaxConnections: 100,
+	})
+	if httpTransport.Addr() != "127.0.0.1:8119" {
+		t.Errorf("Unexpected address %s", httpTransport.Addr())
+	}
+	go httpTransport.Start()
+	time.Sleep(100 * time.Millisecond)
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpTransport.Stop(shutdownCtx)
+	}()
+
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:8119/mcp", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("MCP-Protocol-Version", "2025-03-26")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	for line := range lines {
+		if strings.Contains(line, `"type":"connected"`) {
+			break
+		}
+	}
+
+	httpTransport.Send(types.MCPNotification{JSONRPC: "2.0", Method: "notifications/resources/list_changed"})
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if strings.Contains(line, "notifications/resources/list_changed") {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Expected the notification on the open stream")
+		}
+	}
+}
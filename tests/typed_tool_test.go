@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"calculator-server/internal/handlers"
+	"calculator-server/internal/types"
+	"calculator-server/pkg/mcp"
+)
+
+func callToolJSON(server *mcp.Server, params string) types.MCPResponse {
+	return server.HandleRequestContext(context.Background(), types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(params),
+	})
+}
+
+func toolText(t *testing.T, response types.MCPResponse) (string, bool) {
+	t.Helper()
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %+v", response.Error)
+	}
+	result := response.Result.(types.CallToolResult)
+	return result.Content[0].Text, result.IsError
+}
+
+func TestTypedTool(t *testing.T) {
+	server := mcp.NewServer()
+	mathHandler := handlers.NewMathHandler()
+	schema := getBasicMathSchema()
+	schema["additionalProperties"] = false
+	mcp.RegisterTypedTool(server, "basic_math", "Basic math operations", schema, mathHandler.BasicMath, mcp.WithSelfCheck(
+		map[string]interface{}{"operation": "multiply", "operands": []interface{}{4.0, 5.0}},
+		map[string]interface{}{"result": 20},
+	))
+
+	text, isError := toolText(t, callToolJSON(server, `{"name":"basic_math","arguments":{"operation":"add","operands":[2,3]}}`))
+	if isError || !strings.Contains(text, `"result":5`) {
+		t.Errorf("Expected 5, got %s", text)
+	}
+
+	// Integers written with a fraction still reach the handler
+	text, isError = toolText(t, callToolJSON(server, `{"name":"basic_math","arguments":{"operation":"divide","operands":[1,3],"precision":2.0}}`))
+	if isError || !strings.Contains(text, `"result":0.33`) {
+		t.Errorf("Expected 0.33, got %s", text)
+	}
+
+	// Numbers beyond float64 are reported by argument
+	response := callToolJSON(server, `{"name":"basic_math","arguments":{"operation":"add","operands":[1,1e400]}}`)
+	if response.Error == nil || response.Error.Code != mcp.ErrorCodeValueOutOfRange || !strings.Contains(response.Error.Message, "operands[1]") {
+		t.Errorf("Expected a value-out-of-range error, got %+v", response)
+	}
+
+	// Arguments of the wrong type are tool errors
+	text, isError = toolText(t, callToolJSON(server, `{"name":"basic_math","arguments":{"operation":"add","operands":"2,3"}}`))
+	if !isError || !strings.Contains(text, "invalid parameters for basic_math") {
+		t.Errorf("Expected a tool error, got %s", text)
+	}
+
+	for _, params := range []string{
+		`{"name":"basic_math","arguments":{"operation":"add","operands":[2,3],"precison":2}}`,
+		`{"name":"basic_math","arguments":[2,3]}`,
+	} {
+		if response := callToolJSON(server, params); response.Error == nil || response.Error.Code != mcp.ErrorCodeInvalidParams {
+			t.Errorf("Expected %s to be rejected, got %+v", params, response)
+		}
+	}
+
+	// Self-checks hold their arguments as a map and take the generic path
+	report, err := server.RunSelfChecks(context.Background(), nil)
+	if err != nil || !report.Passed {
+		t.Errorf("Expected the self-check to pass, got %+v (%v)", report, err)
+	}
+
+	server.UnregisterTool("basic_math")
+	if response := callToolJSON(server, `{"name":"basic_math","arguments":{"operation":"add","operands":[2,3]}}`); response.Error == nil {
+		t.Error("Expected an unregistered typed tool to be gone")
+	}
+}
+
+func benchmarkToolsCall(b *testing.B, register func(server *mcp.Server, mathHandler *handlers.MathHandler)) {
+	server := mcp.NewServer()
+	register(server, handlers.NewMathHandler())
+	request := types.MCPRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"basic_math","arguments":{"operation":"add","operands":[1.5,2.25,3.125],"precision":2}}`),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(server.HandleRequest(request)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkToolsCall_Map(b *testing.B) {
+	benchmarkToolsCall(b, func(server *mcp.Server, mathHandler *handlers.MathHandler) {
+		server.RegisterTool("basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.HandleBasicMath)
+	})
+}
+
+func BenchmarkToolsCall_Typed(b *testing.B) {
+	benchmarkToolsCall(b, func(server *mcp.Server, mathHandler *handlers.MathHandler) {
+		mcp.RegisterTypedTool(server, "basic_math", "Basic math operations", getBasicMathSchema(), mathHandler.BasicMath)
+	})
+}